@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newAdoptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adopt [path]",
+		Short: "Register an existing worktree as a session",
+		Long: `Register a worktree created outside ccswitch, e.g. with 'git worktree add',
+as a session so list, switch, rebase and fanout treat it like any other.
+
+The worktree is the one containing path, or the current directory. If the
+current directory is not such a worktree, you pick one of the repository's
+worktrees that are not sessions yet. The session is named after the
+worktree's branch unless --name is given.
+
+With --move, the worktree is also moved to where ccswitch puts new sessions
+(see worktree.root in the configuration).
+
+To register many worktrees at once, use 'ccswitch import'.
+
+Examples:
+  ccswitch adopt                          # The worktree you are in, or pick one
+  ccswitch adopt ../project-hotfix
+  ccswitch adopt ../project-hotfix --name hotfix --move`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeDirectory,
+		Run:               adoptWorktree,
+	}
+
+	cmd.Flags().String("name", "", "Session name (default: derived from the branch)")
+	cmd.Flags().Bool("move", false, "Move the worktree to the configured worktree root")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func adoptWorktree(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString("name")
+	move, _ := cmd.Flags().GetBool("move")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	var wt *git.Worktree
+	if len(args) > 0 {
+		path, err := filepath.Abs(expandHome(args[0]))
+		if err != nil {
+			ui.Errorf("✗ Invalid path: %v", err)
+			return
+		}
+		if wt, err = manager.UnmanagedWorktreeAt(path); err != nil {
+			ui.Errorf("✗ Cannot adopt: %v", err)
+			return
+		}
+	} else if wt, err = manager.UnmanagedWorktreeAt(currentDir); err != nil {
+		if wt = pickUnmanagedWorktree(cmd, manager); wt == nil {
+			return
+		}
+	}
+
+	if name == "" {
+		name = session.SessionNameForBranch(wt.Branch)
+	} else {
+		name = utils.Slugify(name)
+	}
+
+	if err := manager.AdoptWorktree(*wt, name); err != nil {
+		ui.Errorf("✗ Failed to adopt %s: %v", wt.Path, err)
+		return
+	}
+	ui.Successf("✓ Adopted session: %s", name)
+	ui.Infof("Branch: %s", wt.Branch)
+
+	target := manager.GetSessionPath(name)
+	if !move || target == wt.Path {
+		ui.Infof("Location: %s", abbreviateHome(wt.Path))
+		return
+	}
+
+	fixed, err := manager.MoveSession(git.SessionInfo{Name: name, Branch: wt.Branch, Path: wt.Path}, target)
+	if err != nil {
+		ui.Errorf("✗ Adopted in place, but failed to move it: %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		ui.Infof("Location: %s", abbreviateHome(wt.Path))
+		return
+	}
+	ui.Infof("Location: %s (moved from %s)", abbreviateHome(target), abbreviateHome(wt.Path))
+	if fixed > 0 {
+		ui.Infof("Updated %d symlink(s)", fixed)
+	}
+
+	// If we were inside the moved worktree, follow it
+	if cwd, err := os.Getwd(); err == nil && isWithinDir(cwd, wt.Path) {
+		writeCd(target + strings.TrimPrefix(cwd, wt.Path))
+	}
+}
+
+// pickUnmanagedWorktree lets the user choose one of the repository's
+// worktrees that are not sessions yet. Returns nil if there are none or the
+// user quit.
+func pickUnmanagedWorktree(cmd *cobra.Command, manager *session.Manager) *git.Worktree {
+	unmanaged, err := manager.UnmanagedWorktrees()
+	if err != nil {
+		ui.Errorf("✗ Failed to list worktrees: %v", err)
+		return nil
+	}
+	if len(unmanaged) == 0 {
+		ui.Info("No worktrees to adopt: every worktree with a branch is already a session")
+		return nil
+	}
+
+	candidates := make([]git.SessionInfo, len(unmanaged))
+	for i, wt := range unmanaged {
+		candidates[i] = git.SessionInfo{Name: session.SessionNameForBranch(wt.Branch), Branch: wt.Branch, Path: wt.Path}
+	}
+	selected := pickSession(cmd, manager, candidates, "📥 Select worktree to adopt:")
+	if selected == nil {
+		return nil
+	}
+	for i := range unmanaged {
+		if unmanaged[i].Path == selected.Path {
+			return &unmanaged[i]
+		}
+	}
+	return nil
+}
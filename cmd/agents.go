@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/agents"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newAgentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agents",
+		Short: "Run one agent per session from a task list",
+		Long: `Run several coding agents at once, each in its own session.
+
+spawn creates a session for each task in a task file and launches the agent
+command in it, either in the background with its output in a log file, or in
+a tmux pane. The task prompt is passed in $CCSWITCH_TASK, next to the usual
+CCSWITCH_SESSION, CCSWITCH_BRANCH and CCSWITCH_WORKTREE variables.
+
+The agent command is, in order: --command, the task file's command, agents.command
+from the configuration, or Claude Code with the task as its prompt.
+
+A task file lists the tasks, either as plain prompts or with a name:
+
+  command: claude -p "$CCSWITCH_TASK"
+  tasks:
+    - name: fix login redirect
+      prompt: The login page redirects to / instead of the page ...
+    - Add a dark mode toggle to the settings page
+
+Examples:
+  ccswitch agents spawn --task-file tasks.yaml     # One agent per task
+  ccswitch agents spawn 2 --task-file tasks.yaml   # The first two tasks only
+  ccswitch agents spawn --task-file tasks.yaml --tmux
+  ccswitch agents status
+  ccswitch agents stop fix-login-redirect
+  ccswitch agents stop --all`,
+	}
+
+	spawnCmd := &cobra.Command{
+		Use:   "spawn [N]",
+		Short: "Create sessions for tasks and launch an agent in each",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   spawnAgents,
+	}
+	spawnCmd.Flags().String("task-file", "", "YAML file listing the tasks (required)")
+	spawnCmd.Flags().String("command", "", "Shell command that runs the agent")
+	spawnCmd.Flags().Bool("tmux", false, "Run the agents in tmux panes instead of the background")
+	_ = spawnCmd.MarkFlagRequired("task-file")
+	_ = spawnCmd.MarkFlagFilename("task-file", "yaml", "yml")
+	addSetupFlag(spawnCmd)
+	addWaitFlag(spawnCmd)
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the agents launched in sessions",
+		Args:  cobra.NoArgs,
+		Run:   agentsStatus,
+	}
+
+	stopCmd := &cobra.Command{
+		Use:               "stop [session...]",
+		Short:             "Stop running agents",
+		ValidArgsFunction: completeAgentSession,
+		Run:               stopAgents,
+	}
+	stopCmd.Flags().Bool("all", false, "Stop every running agent")
+
+	cmd.AddCommand(spawnCmd, statusCmd, stopCmd)
+	return cmd
+}
+
+func spawnAgents(cmd *cobra.Command, args []string) {
+	taskFile, _ := cmd.Flags().GetString("task-file")
+	command, _ := cmd.Flags().GetString("command")
+	useTmux, _ := cmd.Flags().GetBool("tmux")
+
+	file, err := agents.LoadTasks(taskFile)
+	if err != nil {
+		ui.Errorf("✗ Failed to load tasks: %v", err)
+		return
+	}
+
+	tasks := file.Tasks
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			ui.Errorf("✗ Invalid number of agents: %s", args[0])
+			return
+		}
+		if n > len(tasks) {
+			ui.Errorf("✗ %s has only %d task(s)", taskFile, len(tasks))
+			return
+		}
+		tasks = tasks[:n]
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	if command == "" {
+		command = file.Command
+	}
+	if command == "" {
+		command = manager.Config().Agents.Command
+	}
+	if command == "" {
+		command = agents.DefaultCommand(useTmux)
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	mainRepoPath, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		mainRepoPath = currentDir
+	}
+	tmux := &agents.Tmux{Session: "ccswitch-" + filepath.Base(mainRepoPath)}
+
+	started := 0
+	for _, task := range tasks {
+		progress := ui.StartProgress("Creating session for " + task.Name)
+		meta, err := manager.NewSession(task.Name)
+		progress.Stop()
+		if err != nil {
+			ui.Errorf("✗ Failed to create session for %q: %v", task.Name, err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			continue
+		}
+		setupWorktree(cmd, manager, meta.Path)
+
+		info := git.SessionInfo{Name: meta.Name, Branch: meta.Branch, Path: meta.Path}
+		agent := &session.AgentInfo{
+			Command:   command,
+			Task:      task.Prompt,
+			StartedAt: time.Now(),
+		}
+		if useTmux {
+			vars := append(manager.Env(nil, info), agents.TaskEnv+"="+task.Prompt)
+			agent.TmuxPane, agent.PID, err = tmux.Start(meta.Path, command, vars)
+		} else {
+			env := append(manager.Env(os.Environ(), info), agents.TaskEnv+"="+task.Prompt)
+			agent.Log = manager.AgentLogPath(meta.Name)
+			agent.PID, err = agents.StartBackground(meta.Path, command, env, agent.Log)
+		}
+		if err != nil {
+			ui.Errorf("✗ Created session %s but failed to start its agent: %v", meta.Name, err)
+			continue
+		}
+
+		if err := manager.RecordAgent(meta.Name, agent); err != nil {
+			ui.Warningf("⚠ Failed to record agent of %s: %v", meta.Name, err)
+		}
+		if agent.TmuxPane != "" {
+			ui.Successf("✓ Started agent in %s (pane %s, pid %d)", meta.Name, agent.TmuxPane, agent.PID)
+		} else {
+			ui.Successf("✓ Started agent in %s (pid %d)", meta.Name, agent.PID)
+		}
+		started++
+	}
+
+	if started == 0 {
+		return
+	}
+	fmt.Println()
+	ui.Infof("Started %d of %d agent(s)", started, len(tasks))
+	ui.Info("  Follow them with 'ccswitch agents status' or 'ccswitch watch'")
+	if useTmux && os.Getenv("TMUX") == "" {
+		ui.Infof("  Attach with 'tmux attach -t %s'", tmux.Session)
+	}
+}
+
+func agentsStatus(cmd *cobra.Command, args []string) {
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	sessions, err := manager.AgentSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to load agents: %v", err)
+		return
+	}
+	if len(sessions) == 0 {
+		ui.Info("No agents have been spawned")
+		ui.Info("  Start some with 'ccswitch agents spawn --task-file tasks.yaml'")
+		return
+	}
+
+	heartbeats := manager.Heartbeats()
+	now := time.Now()
+
+	ui.Title("🤖 Agents")
+	for _, meta := range sessions {
+		agent := meta.Agent
+		state := "exited"
+		switch {
+		case agent.StoppedAt != nil:
+			state = "stopped"
+		case agent.Running():
+			state = "running"
+		}
+
+		fmt.Printf("%-24s %-8s pid %-7d started %s\n", meta.Name, state, agent.PID, utils.FormatRelative(agent.StartedAt, now))
+		fmt.Printf("  Task: %s\n", truncateTask(agent.Task))
+		if hb, ok := heartbeats[meta.Path]; ok {
+			activity := hb.Status
+			if hb.Quiet(now) {
+				activity = "quiet, last: " + activity
+			}
+			fmt.Printf("  Activity: %s (%s)\n", activity, utils.FormatRelative(hb.At, now))
+		}
+		if agent.TmuxPane != "" {
+			fmt.Printf("  Pane: %s\n", agent.TmuxPane)
+		} else if agent.Log != "" {
+			fmt.Printf("  Log: %s\n", agent.Log)
+		}
+	}
+}
+
+// taskWidth is how much of a task prompt agents status shows
+const taskWidth = 72
+
+// truncateTask shortens a task prompt to one line of taskWidth characters
+func truncateTask(task string) string {
+	task = strings.Join(strings.Fields(task), " ")
+	if runes := []rune(task); len(runes) > taskWidth {
+		return string(runes[:taskWidth-1]) + "…"
+	}
+	return task
+}
+
+func stopAgents(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		ui.Error("✗ Name the sessions whose agents to stop, or pass --all")
+		return
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	sessions, err := manager.AgentSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to load agents: %v", err)
+		return
+	}
+
+	byName := make(map[string]*session.Metadata, len(sessions))
+	for _, meta := range sessions {
+		byName[meta.Name] = meta
+	}
+
+	var targets []*session.Metadata
+	if all {
+		for _, meta := range sessions {
+			if meta.Agent.Running() {
+				targets = append(targets, meta)
+			}
+		}
+		if len(targets) == 0 {
+			ui.Info("No agents are running")
+			return
+		}
+	} else {
+		for _, name := range args {
+			meta, ok := byName[name]
+			if !ok {
+				ui.Errorf("✗ No agent was spawned in session: %s", name)
+				continue
+			}
+			if !meta.Agent.Running() {
+				ui.Infof("Agent in %s is not running", name)
+				continue
+			}
+			targets = append(targets, meta)
+		}
+	}
+
+	for _, meta := range targets {
+		if err := agents.Stop(meta.Agent.PID, meta.Agent.TmuxPane, proc.DefaultGracePeriod); err != nil {
+			ui.Errorf("✗ Failed to stop agent in %s: %v", meta.Name, err)
+			continue
+		}
+		if err := manager.MarkAgentStopped(meta.Name); err != nil {
+			ui.Warningf("⚠ Failed to record that the agent of %s stopped: %v", meta.Name, err)
+		}
+		ui.Successf("✓ Stopped agent in %s", meta.Name)
+	}
+}
+
+// completeAgentSession completes the names of sessions with running agents
+func completeAgentSession(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sessions, err := session.NewManager(currentDir).AgentSessions()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, meta := range sessions {
+		if meta.Agent.Running() {
+			names = append(names, meta.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// resolveAlias expands the alias args start with after any global flags,
+// as defined by alias in the configuration of the repository the command
+// runs in. A shell alias is run instead, and ccswitch exits with its exit
+// code.
+func resolveAlias(root *cobra.Command, args []string) []string {
+	i := commandIndex(root, args)
+	// Aliases can't shadow commands, so these need no configuration
+	if i < 0 || isCommand(root, args[i]) {
+		return args
+	}
+
+	// Global flags given before the alias apply to the command it runs,
+	// and --repo chooses the configuration it comes from
+	if err := root.ParseFlags(args[:i]); err != nil {
+		return args
+	}
+	dir, err := workingDir(root)
+	if err != nil {
+		return args
+	}
+	repoRoot, _ := git.GetMainRepoPath(dir)
+	aliases, refused, err := config.LoadAliases(repoRoot)
+	if err != nil {
+		ui.Warningf("⚠ Failed to load config, so aliases are unavailable: %v", err)
+		return args
+	}
+
+	name := args[i]
+	if _, ok := aliases[name]; !ok && refused[name] {
+		ui.Errorf("✗ Alias %s runs a shell command, which %s can't define", name, config.RepoConfigPath(repoRoot))
+		ui.Infof("  Tip: Define it in %s or %s to run it", config.LocalConfigPath(repoRoot), config.GetConfigPath())
+		os.Exit(1)
+	}
+	expanded, shell, err := expandAlias(root, aliases, args[i:])
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		os.Exit(1)
+	}
+	if shell != "" {
+		runShellAlias(dir, shell, expanded)
+	}
+	return append(slices.Clip(args[:i]), expanded...)
+}
+
+// commandIndex returns the index of the first of args that is neither a
+// global flag nor its value, or -1 if there is none or an unknown flag
+// comes first
+func commandIndex(root *cobra.Command, args []string) int {
+	flags := root.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		flag := flags.Lookup(name)
+		if !strings.HasPrefix(arg, "--") {
+			flag = nil
+			if len(name) == 1 {
+				flag = flags.ShorthandLookup(name)
+			}
+		}
+		if flag == nil {
+			return -1
+		}
+		// The value of a flag that needs one is the next argument
+		if !hasValue && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return -1
+}
+
+// expandAlias replaces the alias args start with by its definition, until
+// they start with a command. For a shell alias, it returns the shell
+// command and the arguments that follow the alias.
+func expandAlias(root *cobra.Command, aliases map[string]string, args []string) ([]string, string, error) {
+	seen := make(map[string]bool)
+	for len(args) > 0 && !isCommand(root, args[0]) {
+		name := args[0]
+		definition, ok := aliases[name]
+		if !ok {
+			break
+		}
+		if seen[name] {
+			return nil, "", fmt.Errorf("alias %s expands to itself", name)
+		}
+		seen[name] = true
+
+		if shell, ok := strings.CutPrefix(definition, "!"); ok {
+			return args[1:], shell, nil
+		}
+		words, err := splitArgs(definition)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid alias %s: %w", name, err)
+		}
+		if len(words) == 0 {
+			return nil, "", fmt.Errorf("alias %s is empty", name)
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, "", nil
+}
+
+// splitArgs splits an alias definition into arguments at spaces outside
+// single or double quotes; a backslash escapes the next character outside
+// single quotes
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// runShellAlias runs the shell command of an alias in dir, passing it args
+// as git does, with the environment plugins get, and exits with its exit
+// code
+func runShellAlias(dir, command string, args []string) {
+	c := shellAliasCommand(command, args)
+	c.Dir = dir
+	c.Env = pluginEnv(dir)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := proc.Run(c, proc.DefaultGracePeriod); err != nil {
+		os.Exit(proc.ExitCode(err))
+	}
+	os.Exit(0)
+}
+
+// shellAliasCommand returns the command running command with the system
+// shell; args follow it as "$@" does
+func shellAliasCommand(command string, args []string) *exec.Cmd {
+	if len(args) == 0 {
+		return proc.ShellCommand(command)
+	}
+	if runtime.GOOS == "windows" {
+		return proc.ShellCommand(command + " " + strings.Join(args, " "))
+	}
+	return exec.Command("/bin/sh", append([]string{"-c", command + ` "$@"`, command}, args...)...)
+}
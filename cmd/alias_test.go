@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"fo":     "fanout --stack --skip-unsafe",
+		"fos":    "fo --select",
+		"t":      "!make test",
+		"status": "list",
+		"loop":   "again",
+		"again":  "loop",
+		"quoted": `exec auth -- sh -c 'echo "$CCSWITCH_SESSION"'`,
+	}
+	root := NewRootCmd()
+
+	tests := []struct {
+		args     []string
+		expected []string
+		shell    string
+	}{
+		{[]string{"fo", "--select"}, []string{"fanout", "--stack", "--skip-unsafe", "--select"}, ""},
+		{[]string{"fos"}, []string{"fanout", "--stack", "--skip-unsafe", "--select"}, ""},
+		{[]string{"t", "-k"}, []string{"-k"}, "make test"},
+		{[]string{"status"}, []string{"status"}, ""},
+		{[]string{"deploy"}, []string{"deploy"}, ""},
+		{[]string{"quoted"}, []string{"exec", "auth", "--", "sh", "-c", `echo "$CCSWITCH_SESSION"`}, ""},
+	}
+	for _, tt := range tests {
+		expanded, shell, err := expandAlias(root, aliases, tt.args)
+		if err != nil {
+			t.Errorf("expandAlias(%v) failed: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(expanded, tt.expected) || shell != tt.shell {
+			t.Errorf("expandAlias(%v) = %q, %q, expected %q, %q", tt.args, expanded, shell, tt.expected, tt.shell)
+		}
+	}
+
+	if _, _, err := expandAlias(root, aliases, []string{"loop"}); err == nil {
+		t.Error("expandAlias(loop) succeeded, expected an error for an alias expanding to itself")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fanout  --yes", []string{"fanout", "--yes"}},
+		{`note auth "two words" 'it''s'`, []string{"note", "auth", "two words", "its"}},
+		{`a\ b "c\"d" ''`, []string{"a b", `c"d`, ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got, err := splitArgs(tt.input); err != nil || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("splitArgs(%q) = %q, %v, expected %q", tt.input, got, err, tt.expected)
+		}
+	}
+
+	if _, err := splitArgs(`note "open`); err == nil {
+		t.Error("splitArgs() of an unterminated quote succeeded, expected an error")
+	}
+}
+
+func TestCommandIndex(t *testing.T) {
+	root := NewRootCmd()
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"st"}, 0},
+		{[]string{"--quiet", "st"}, 1},
+		{[]string{"-q", "st", "-x"}, 1},
+		{[]string{"--repo", "work", "st"}, 2},
+		{[]string{"--repo=work", "--no-tui", "st"}, 2},
+		{[]string{"--repo"}, -1},
+		{[]string{"--unknown", "st"}, -1},
+		{nil, -1},
+	}
+	for _, tt := range tests {
+		if got := commandIndex(root, tt.args); got != tt.expected {
+			t.Errorf("commandIndex(%q) = %d, expected %d", tt.args, got, tt.expected)
+		}
+	}
+}
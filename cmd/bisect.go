@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newBisectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the commit that broke something in a throwaway session",
+		Long: `Find the commit that introduced a problem with an automated git bisect,
+run in a session of its own so your worktrees are left untouched.
+
+Examples:
+  ccswitch bisect start v1.4.0 main --cmd "make test"
+  ccswitch bisect start HEAD~20 HEAD --cmd "go test ./auth/..."`,
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start <good> <bad>",
+		Short: "Bisect between a good and a bad commit with a test command",
+		Long: `Bisect between good, a commit without the problem, and bad, one with it.
+
+A detached session is created at bad, and 'git bisect run' tests commits
+there with the --cmd shell command, whose output is shown as it runs. The
+command exits with 0 for good commits, 125 for commits that cannot be
+tested and another code below 128 for bad ones. The first bad commit is
+reported, and the session is removed unless --keep is given.
+
+Examples:
+  ccswitch bisect start v1.4.0 main --cmd "make test"
+  ccswitch bisect start HEAD~20 HEAD --cmd "go test ./auth/..." --keep`,
+		Args: cobra.ExactArgs(2),
+		Run:  runBisect,
+	}
+	startCmd.Flags().String("cmd", "", "Shell command telling good commits (exit 0) from bad ones (required)")
+	_ = startCmd.MarkFlagRequired("cmd")
+	startCmd.Flags().Bool("keep", false, "Keep the bisect session instead of removing it afterwards")
+	addWaitFlag(startCmd)
+	cmd.AddCommand(startCmd)
+
+	return cmd
+}
+
+func runBisect(cmd *cobra.Command, args []string) {
+	good, bad := args[0], args[1]
+	command, _ := cmd.Flags().GetString("cmd")
+	keep, _ := cmd.Flags().GetBool("keep")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	badCommit, err := git.ResolveRef(currentDir, bad)
+	if err != nil {
+		ui.Errorf("✗ Unknown bad commit %q", bad)
+		return
+	}
+	if _, err := git.ResolveRef(currentDir, good); err != nil {
+		ui.Errorf("✗ Unknown good commit %q", good)
+		return
+	}
+
+	// The repository is only locked while the session is created and
+	// removed, so bisecting doesn't hold up other commands
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	progress := ui.StartProgress("Creating bisect session")
+	meta, err := manager.CreateDetachedSession("bisect "+badCommit[:min(7, len(badCommit))], badCommit, nil)
+	progress.Stop()
+	lock.Release()
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+	ui.Infof("Bisecting in session %s (%s)", meta.Name, abbreviateHome(meta.Path))
+	fmt.Println()
+
+	culprit, err := bisectSession(meta.Path, good, badCommit, command)
+	fmt.Println()
+	switch {
+	case err != nil:
+		ui.Errorf("✗ %v", err)
+	case culprit == "":
+		ui.Warning("⚠ Bisect found no first bad commit; commits that could not be tested may hide it")
+	default:
+		reportCulprit(meta.Path, culprit)
+	}
+
+	if keep {
+		ui.Infof("Session kept at %s", meta.Path)
+		writeCd(meta.Path)
+		return
+	}
+	removeBisectSession(cmd, manager, meta)
+	if err != nil || culprit == "" {
+		os.Exit(1)
+	}
+}
+
+// bisectSession bisects in the worktree at path, streaming the progress to
+// stdout, and returns the first bad commit it found, if any. Bisecting is
+// reset before returning.
+func bisectSession(path, good, bad, command string) (string, error) {
+	if err := git.BisectStart(path, good, bad); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := git.BisectReset(path); err != nil {
+			ui.Warningf("⚠ %v", err)
+		}
+	}()
+
+	start := time.Now()
+	if err := git.BisectRun(path, command, os.Stdout); err != nil {
+		return "", fmt.Errorf("bisect run failed after %s: %w", time.Since(start).Round(time.Second), err)
+	}
+	return git.BisectCulprit(path)
+}
+
+// reportCulprit prints the first bad commit found in the worktree at path
+func reportCulprit(path, culprit string) {
+	ui.Successf("✓ First bad commit: %s", culprit)
+	commits, err := git.GetCommits(path, culprit+"^!", time.Time{})
+	if err != nil || len(commits) == 0 {
+		return
+	}
+	c := commits[0]
+	ui.Infof("  %s", c.Subject)
+	ui.Infof("  %s, %s", c.Author, utils.FormatRelative(c.Date, time.Now()))
+}
+
+// removeBisectSession removes the throwaway session a bisect ran in
+func removeBisectSession(cmd *cobra.Command, manager *session.Manager, meta *session.Metadata) {
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		ui.Infof("  Tip: Remove the session later with 'ccswitch cleanup %s'", meta.Name)
+		return
+	}
+	defer lock.Release()
+
+	if err := manager.RemoveSession(meta.Path, false, ""); err != nil {
+		ui.Warningf("⚠ Failed to remove session %s: %v", meta.Name, err)
+		return
+	}
+	ui.Infof("Removed session %s", meta.Name)
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ksred/ccswitch/internal/errors"
 	"github.com/ksred/ccswitch/internal/session"
@@ -14,29 +13,50 @@ import (
 )
 
 func newCheckoutCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "checkout <branch>",
-		Short: "Checkout an existing branch into a new worktree",
-		Args:  cobra.ExactArgs(1),
-		Run:   checkoutSession,
+	cmd := &cobra.Command{
+		Use:               "checkout <branch>",
+		Short:             "Checkout an existing branch into a new worktree",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeCheckoutBranch,
+		Run:               checkoutSession,
 	}
+
+	addSetupFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
 }
 
 func checkoutSession(cmd *cobra.Command, args []string) {
 	branchName := strings.TrimSpace(args[0])
 
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
 
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	strategy, ok := creationStrategy(manager)
+	if !ok {
+		return
+	}
+
 	// Checkout the session
-	if err := manager.CheckoutSession(branchName); err != nil {
+	start := time.Now()
+	progress := ui.StartProgress("Creating worktree")
+	err = manager.CheckoutSession(branchName)
+	progress.Stop()
+	if err != nil {
 		ui.Errorf("✗ %s", err)
 
 		// Provide helpful tips based on error
@@ -50,18 +70,18 @@ func checkoutSession(cmd *cobra.Command, args []string) {
 
 	// Success!
 	sessionName := utils.Slugify(branchName)
-	repoName := filepath.Base(currentDir)
 
 	// Get the full worktree path
-	homeDir, _ := os.UserHomeDir()
-	worktreePath := filepath.Join(homeDir, ".ccswitch", "worktrees", repoName, sessionName)
+	worktreePath := manager.GetSessionPath(sessionName)
+	reportCheckout(strategy, "", nil, time.Since(start))
+	setupWorktree(cmd, manager, worktreePath)
 
 	ui.Successf("✓ Checked out session: %s", sessionName)
 	ui.Infof("Branch: %s", branchName)
-	ui.Infof("Location: ~/.ccswitch/worktrees/%s/%s", repoName, sessionName)
+	ui.Infof("Location: %s", abbreviateHome(worktreePath))
 
 	// Output the cd command for the shell wrapper to execute on a separate line
-	fmt.Printf("\ncd %s\n", worktreePath)
+	printCd(cmd, worktreePath)
 
 	// If shell integration is not active, show a helpful message
 	if !utils.IsShellIntegrationActive() {
@@ -6,47 +6,92 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/ksred/ccswitch/internal/errors"
 	"github.com/ksred/ccswitch/internal/git"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 func newCleanupCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "cleanup [session-name]",
-		Short: "Remove worktree and optionally delete branch",
+		Use:     "cleanup [session-name]",
+		Aliases: []string{"delete"},
+		Short:   "Remove worktree and optionally delete branch",
 		Long: `Remove a worktree session and optionally delete its associated branch.
 
-Without arguments: Shows an interactive list of sessions to cleanup
+Without arguments: Shows an interactive list to select one or more sessions
 With session name: Removes the specified session
-With --all flag: Removes all worktrees except main/master (bulk cleanup)
+With --all flag: Removes all worktrees except main/master and pinned ones
+With --expired: Removes the sessions whose --ttl ran out
+
+Pinned sessions (see 'ccswitch pin') are never removed, not even with
+--force or --all.
+
+Sessions with uncommitted changes are not removed: their changes are listed
+and, with --force, deleted along with the worktree. --expired never removes
+them, so expired sessions that still hold work wait for you to look at them. You are asked whether to
+delete the sessions' branches unless --keep-branch is given; protected
+branches are always kept. --delete-remote deletes the branches and their
+upstream branches on the remote as well. A summary of everything removed is
+printed at the end.
 
 Examples:
-  ccswitch cleanup                  # Interactive selection
-  ccswitch cleanup my-feature       # Remove specific session
-  ccswitch cleanup --all            # Remove all worktrees (with confirmation)`,
-		Args: cobra.MaximumNArgs(1),
-		Run:  cleanupSession,
+  ccswitch cleanup                          # Select sessions interactively
+  ccswitch cleanup my-feature               # Remove specific session
+  ccswitch delete my-feature --keep-branch  # Remove the worktree only
+  ccswitch cleanup my-feature --force       # Discard uncommitted changes
+  ccswitch cleanup my-feature --delete-remote
+  ccswitch cleanup --all                    # Remove all worktrees (with confirmation)
+  ccswitch cleanup --expired --keep-branch  # Remove expired sessions`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               cleanupSession,
 	}
 
-	cmd.Flags().Bool("all", false, "Remove ALL worktrees except main/master (bulk cleanup)")
+	cmd.Flags().Bool("all", false, "Remove ALL worktrees except main/master and pinned ones (bulk cleanup)")
+	cmd.Flags().Bool("expired", false, "Remove the sessions whose TTL ran out, except those with uncommitted changes")
+	cmd.Flags().Bool("force", false, "Remove sessions even if they have uncommitted changes")
+	cmd.Flags().Bool("keep-branch", false, "Keep the sessions' branches without asking")
+	cmd.Flags().Bool("delete-remote", false, "Delete the sessions' branches locally and on their remote without asking")
+	cmd.MarkFlagsMutuallyExclusive("keep-branch", "delete-remote")
+	cmd.MarkFlagsMutuallyExclusive("expired", "all")
+	cmd.MarkFlagsMutuallyExclusive("expired", "force")
+	addWaitFlag(cmd)
 
 	return cmd
 }
 
+// cleanupSummary records what a cleanup removed
+type cleanupSummary struct {
+	removed        []string
+	branches       []string
+	remoteBranches []string
+	kept           []string
+	skipped        []string
+	failed         []string
+}
+
 func cleanupSession(cmd *cobra.Command, args []string) {
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
 
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
 	// Get sessions
 	sessions, err := manager.ListSessions()
 	if err != nil {
@@ -63,85 +108,245 @@ func cleanupSession(cmd *cobra.Command, args []string) {
 	cleanupAll, _ := cmd.Flags().GetBool("all")
 
 	if cleanupAll {
-		cleanupAllSessions(manager, sessions)
+		cleanupAllSessions(cmd, manager, currentDir, sessions)
 		return
 	}
 
-	var sessionName string
+	if expired, _ := cmd.Flags().GetBool("expired"); expired {
+		if len(args) > 0 {
+			ui.Error("✗ --expired cannot be used with a session name")
+			return
+		}
+		cleanupExpiredSessions(cmd, manager, currentDir)
+		return
+	}
+
+	var targets []git.SessionInfo
 	if len(args) > 0 {
-		sessionName = args[0]
+		targetSession := findSession(sessions, args[0])
+		if targetSession == nil {
+			ui.Errorf("✗ Session not found: %s", args[0])
+			return
+		}
+		targets = []git.SessionInfo{*targetSession}
 	} else {
-		// Show numbered list for selection
-		ui.Title("🗑️  Select session to cleanup:")
-		fmt.Println()
-
-		for i, session := range sessions {
-			ui.Infof("  %d. %s (%s)", i+1, session.Name, session.Branch)
+		targets = selectSessionsToCleanup(cmd, manager, sessions)
+		if len(targets) == 0 {
+			return
 		}
+	}
 
-		fmt.Println()
-		fmt.Print("Enter number (or q to quit): ")
+	summary := removeSessions(cmd, manager, currentDir, targets, bufio.NewScanner(os.Stdin))
+	summary.print()
+}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		if !scanner.Scan() {
-			return
+// selectSessionsToCleanup lets the user check the sessions to remove.
+// Pinned sessions can't be checked, nor can sessions with uncommitted
+// changes without --force.
+func selectSessionsToCleanup(cmd *cobra.Command, manager *session.Manager, sessions []git.SessionInfo) []git.SessionInfo {
+	force, _ := cmd.Flags().GetBool("force")
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+
+	var candidates []git.SessionInfo
+	opts := ui.PickOptions{
+		Title:    "🗑️  Select sessions to cleanup:",
+		Action:   "Remove",
+		Disabled: make(map[string]string),
+		Warnings: make(map[string]string),
+		Pinned:   manager.Pinned(),
+		NoTUI:    noTUI,
+	}
+	for _, s := range sessions {
+		if s.Name == "main" {
+			continue
 		}
+		if opts.Pinned[s.Path] {
+			opts.Disabled[s.Path] = "pinned, run 'ccswitch unpin' to remove"
+		} else if git.HasUncommittedChanges(s.Path) {
+			if force {
+				opts.Warnings[s.Path] = "uncommitted changes will be lost"
+			} else {
+				opts.Disabled[s.Path] = "uncommitted changes, pass --force to remove"
+			}
+		}
+		candidates = append(candidates, s)
+	}
+	if len(candidates) == 0 {
+		ui.Info("No worktree sessions to cleanup")
+		return nil
+	}
 
-		input := strings.TrimSpace(scanner.Text())
-		if input == "q" || input == "" {
-			return
+	selected, err := ui.PickSessions(candidates, opts)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
 		}
+		return nil
+	}
+	if len(selected) == 0 {
+		ui.Info("No sessions selected")
+	}
+	return selected
+}
 
-		// Parse number
-		var choice int
-		if _, err := fmt.Sscanf(input, "%d", &choice); err != nil || choice < 1 || choice > len(sessions) {
-			ui.Error("✗ Invalid selection")
-			return
+// removeSessions removes targets of the repository at dir following the
+// --force, --keep-branch and --delete-remote flags, asking on scanner whether
+// to delete branches
+func removeSessions(cmd *cobra.Command, manager *session.Manager, dir string, targets []git.SessionInfo, scanner *bufio.Scanner) cleanupSummary {
+	force, _ := cmd.Flags().GetBool("force")
+	keepBranch, _ := cmd.Flags().GetBool("keep-branch")
+	deleteRemote, _ := cmd.Flags().GetBool("delete-remote")
+	cfg := manager.Config()
+
+	var summary cleanupSummary
+
+	// Pinned sessions are never removed, not even with --force
+	pinned := manager.Pinned()
+	var unpinned []git.SessionInfo
+	for _, s := range targets {
+		if !pinned[s.Path] {
+			unpinned = append(unpinned, s)
+			continue
 		}
+		ui.Errorf("✗ %s is pinned", s.Name)
+		ui.Infof("  Tip: Run 'ccswitch unpin %s' to allow removing it", s.Name)
+		summary.skipped = append(summary.skipped, s.Name+" (pinned)")
+	}
 
-		sessionName = sessions[choice-1].Name
+	// Never lose uncommitted work without --force
+	var clean []git.SessionInfo
+	dirty := false
+	for _, s := range unpinned {
+		if !git.HasUncommittedChanges(s.Path) {
+			clean = append(clean, s)
+			continue
+		}
+		files, _ := git.GetChangedFiles(s.Path)
+		if force {
+			ui.Warningf("⚠ Discarding uncommitted changes in %s:", s.Name)
+		} else {
+			ui.Errorf("✗ %s has uncommitted changes:", s.Name)
+		}
+		for _, f := range files {
+			fmt.Printf("    %s\n", f)
+		}
+		if force {
+			clean = append(clean, s)
+		} else {
+			summary.skipped = append(summary.skipped, s.Name+" (uncommitted changes)")
+			dirty = true
+		}
+	}
+	if dirty {
+		ui.Info("  Tip: Commit or stash the changes, or pass --force to delete them")
 	}
 
-	// Find the session
-	var targetSession *git.SessionInfo
-	for _, s := range sessions {
-		if s.Name == sessionName {
-			s := s // Create a copy to take address of
-			targetSession = &s
-			break
+	// Decide the branch policy, never deleting protected branches
+	var deletable []string
+	for _, s := range clean {
+		if !cfg.IsProtectedBranch(s.Branch) {
+			deletable = append(deletable, s.Branch)
 		}
 	}
+	deleteBranches := deleteRemote
+	if !keepBranch && !deleteRemote && len(deletable) > 0 {
+		if len(deletable) == 1 {
+			fmt.Printf("Delete branch %s? (y/N): ", deletable[0])
+		} else {
+			fmt.Printf("Delete %d associated branches as well? (y/N): ", len(deletable))
+		}
+		deleteBranches = scanner.Scan() && strings.ToLower(scanner.Text()) == "y"
+	}
 
-	if targetSession == nil {
-		ui.Errorf("✗ Session not found: %s", sessionName)
-		return
+	mainRepo, err := git.GetMainRepoPath(dir)
+	if err != nil {
+		mainRepo = dir
 	}
 
-	// Ask about branch deletion
-	fmt.Printf("Delete branch %s? (y/N): ", targetSession.Branch)
-	scanner := bufio.NewScanner(os.Stdin)
-	deleteBranch := false
-	if scanner.Scan() && strings.ToLower(scanner.Text()) == "y" {
-		deleteBranch = true
+	for _, s := range clean {
+		deleteBranch := deleteBranches && !cfg.IsProtectedBranch(s.Branch)
+		if deleteBranches && !deleteBranch {
+			ui.Infof("Keeping protected branch %s", s.Branch)
+		}
+
+		// Look up the upstream before the branch and its config are gone
+		var upstream git.Upstream
+		hasUpstream := false
+		if deleteRemote && deleteBranch {
+			upstream, err = git.GetUpstream(mainRepo, s.Branch)
+			hasUpstream = err == nil
+			if !hasUpstream {
+				ui.Infof("%s has no upstream branch to delete", s.Branch)
+			}
+		}
+
+		if err := manager.RemoveSession(s.Path, deleteBranch, s.Branch); err != nil {
+			ui.Errorf("✗ Failed to remove %s: %v", s.Name, err)
+			summary.failed = append(summary.failed, s.Name)
+			continue
+		}
+		ui.Successf("✓ Cleaned up session: %s", s.Name)
+		summary.removed = append(summary.removed, s.Name)
+		if deleteBranch {
+			summary.branches = append(summary.branches, s.Branch)
+		} else {
+			summary.kept = append(summary.kept, s.Branch)
+		}
+
+		if hasUpstream {
+			if err := git.DeleteRemoteBranch(mainRepo, upstream); err != nil {
+				ui.Errorf("✗ %v", err)
+				summary.failed = append(summary.failed, upstream.String())
+			} else {
+				summary.remoteBranches = append(summary.remoteBranches, upstream.String())
+			}
+		}
 	}
 
-	// Remove the session
-	if err := manager.RemoveSession(targetSession.Path, deleteBranch, targetSession.Branch); err != nil {
-		ui.Errorf("✗ Failed to cleanup session: %v", err)
+	return summary
+}
+
+// print reports what was removed, kept and skipped
+func (s cleanupSummary) print() {
+	if len(s.removed)+len(s.skipped)+len(s.failed) == 0 {
 		return
 	}
+	fmt.Println()
+	ui.Title("Summary")
+	printSummaryLine("Removed sessions", s.removed)
+	printSummaryLine("Deleted branches", s.branches)
+	printSummaryLine("Deleted remote branches", s.remoteBranches)
+	printSummaryLine("Kept branches", s.kept)
+	printSummaryLine("Skipped", s.skipped)
+	printSummaryLine("Failed", s.failed)
+}
 
-	ui.Successf("✓ Cleaned up session: %s", sessionName)
+func printSummaryLine(label string, names []string) {
+	if len(names) > 0 {
+		ui.Infof("  %s: %s", label, strings.Join(names, ", "))
+	}
 }
 
-func cleanupAllSessions(manager *session.Manager, sessions []git.SessionInfo) {
-	// Filter out the main session and any session on main/master branch
+func cleanupAllSessions(cmd *cobra.Command, manager *session.Manager, dir string, sessions []git.SessionInfo) {
+	// Filter out the main session, any session on main/master branch and
+	// pinned sessions
+	pinned := manager.Pinned()
 	var worktreeSessions []git.SessionInfo
+	var kept []string
 	for _, s := range sessions {
 		// Skip the main session (primary repository) and any worktree on main/master branch
-		if s.Name != "main" && s.Branch != "main" && s.Branch != "master" {
-			worktreeSessions = append(worktreeSessions, s)
+		if s.Name == "main" || s.Branch == "main" || s.Branch == "master" {
+			continue
+		}
+		if pinned[s.Path] {
+			kept = append(kept, s.Name)
+			continue
 		}
+		worktreeSessions = append(worktreeSessions, s)
+	}
+	if len(kept) > 0 {
+		ui.Infof("📌 Keeping pinned sessions: %s", strings.Join(kept, ", "))
 	}
 
 	if len(worktreeSessions) == 0 {
@@ -161,38 +366,37 @@ func cleanupAllSessions(manager *session.Manager, sessions []git.SessionInfo) {
 	fmt.Print("Press Enter to continue or Ctrl+C to cancel...")
 	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Scan()
-
-	// Ask about branch deletion
 	fmt.Println()
-	fmt.Print("Delete associated branches as well? (y/N): ")
-	deleteBranches := false
-	if scanner.Scan() && strings.ToLower(scanner.Text()) == "y" {
-		deleteBranches = true
-	}
 
-	fmt.Println()
+	summary := removeSessions(cmd, manager, dir, worktreeSessions, scanner)
+	summary.print()
 
-	// Remove each session
-	successCount := 0
-	for _, session := range worktreeSessions {
-		if err := manager.RemoveSession(session.Path, deleteBranches, session.Branch); err != nil {
-			ui.Errorf("✗ Failed to remove %s: %v", session.Name, err)
-		} else {
-			ui.Successf("✓ Successfully removed: %s", session.Name)
-			successCount++
-		}
+	// Switch to main/master branch
+	switchToMainBranch()
+}
+
+// cleanupExpiredSessions removes the sessions whose TTL ran out; those with
+// uncommitted changes are refused, since --force is not allowed
+func cleanupExpiredSessions(cmd *cobra.Command, manager *session.Manager, dir string) {
+	expired, err := manager.ExpiredSessions(time.Now())
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+	if len(expired) == 0 {
+		ui.Info("No expired sessions to cleanup")
+		return
 	}
 
-	// Summary
+	ui.Title("⌛ Expired sessions:")
 	fmt.Println()
-	if successCount == len(worktreeSessions) {
-		ui.Successf("✅ All %d worktrees removed successfully!", successCount)
-	} else {
-		ui.Infof("Removed %d out of %d worktrees", successCount, len(worktreeSessions))
+	for _, s := range expired {
+		ui.Infof("  • %s (%s), expired %s", s.Name, s.BranchLabel(), utils.FormatRelative(*manager.ExpiresAt(s), time.Now()))
 	}
+	fmt.Println()
 
-	// Switch to main/master branch
-	switchToMainBranch()
+	summary := removeSessions(cmd, manager, dir, expired, bufio.NewScanner(os.Stdin))
+	summary.print()
 }
 
 func switchToMainBranch() {
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addCommitMessageFlags registers the flags used to build a commit message
+func addCommitMessageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("message", "m", "", "Commit message (conventional subject when --type is set)")
+	cmd.Flags().String("type", "", "Conventional commit type (e.g. feat, fix, chore)")
+	cmd.Flags().String("scope", "", "Conventional commit scope (e.g. api)")
+	_ = cmd.RegisterFlagCompletionFunc("type", fixedCompletion(git.ConventionalTypes...))
+}
+
+// addAIMessageFlag registers --ai-message on commands that commit a
+// worktree's changes with resolveWorktreeCommitMessage
+func addAIMessageFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("ai-message", false, "Have git.message_command write the commit message from the diff")
+	cmd.MarkFlagsMutuallyExclusive("ai-message", "message")
+	cmd.MarkFlagsMutuallyExclusive("ai-message", "type")
+}
+
+// addAuthorshipFlags registers the flags that credit the authors of the
+// commits rebase creates
+func addAuthorshipFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("co-author", nil, "Credit \"Name <email>\" with a Co-authored-by trailer, besides git.co_authors (repeatable)")
+	cmd.Flags().Bool("keep-author", false, "With --squash, keep the authors of the squashed commits; default: git.keep_author")
+}
+
+// applyAuthorshipFlags adds --co-author to the co_authors setting of
+// manager and overrides keep_author with --keep-author when it is given.
+// Co-authors that git would not take for a person are refused.
+func applyAuthorshipFlags(cmd *cobra.Command, manager *session.Manager) error {
+	cfg := manager.Config()
+	coAuthors, _ := cmd.Flags().GetStringArray("co-author")
+	cfg.Git.CoAuthors = append(slices.Clip(cfg.Git.CoAuthors), coAuthors...)
+	for _, coAuthor := range cfg.Git.CoAuthors {
+		if !git.IsIdent(coAuthor) {
+			return fmt.Errorf("invalid co-author %q: expected \"Name <email>\"", coAuthor)
+		}
+	}
+	if cmd.Flags().Changed("keep-author") {
+		cfg.Git.KeepAuthor, _ = cmd.Flags().GetBool("keep-author")
+	}
+	return nil
+}
+
+// addNoVerifyFlag registers --no-verify on commands that create commits
+func addNoVerifyFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-verify", false, "Skip the pre-commit and commit-msg hooks (see git.commit_hooks)")
+}
+
+// applyNoVerifyFlag makes manager skip commit hooks when --no-verify is
+// given, unless git.commit_hooks enforces them
+func applyNoVerifyFlag(cmd *cobra.Command, manager *session.Manager) error {
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	cfg := manager.Config()
+	skip, err := cfg.SkipCommitHooks(noVerify)
+	if err != nil {
+		return err
+	}
+	if skip {
+		cfg.Git.CommitHooks = config.CommitHooksSkip
+	}
+	return nil
+}
+
+// resolveCommitMessage builds the commit message from flags, config and prompts.
+// With --type the message is built non-interactively as a conventional commit;
+// with commit_style: conventional the interactive wizard is used instead.
+func resolveCommitMessage(cmd *cobra.Command) (string, error) {
+	message, _ := cmd.Flags().GetString("message")
+	commitType, _ := cmd.Flags().GetString("type")
+	scope, _ := cmd.Flags().GetString("scope")
+
+	if commitType != "" {
+		if message == "" {
+			message = promptForCommitMessage()
+		}
+		commit := git.ConventionalCommit{Type: commitType, Scope: scope, Subject: message}
+		if err := commit.Validate(); err != nil {
+			return "", err
+		}
+		return commit.String(), nil
+	}
+
+	cfg := loadConfig(cmd)
+	if cfg.Git.CommitStyle == git.CommitStyleConventional {
+		if message != "" {
+			commit, err := git.ParseConventionalCommit(message)
+			if err != nil {
+				return "", err
+			}
+			return commit.String(), nil
+		}
+		return runConventionalCommitWizard(scope)
+	}
+
+	if message == "" {
+		message = promptForCommitMessage()
+	}
+	if message == "" {
+		return "", fmt.Errorf("commit message cannot be empty")
+	}
+	return message, nil
+}
+
+// resolveWorktreeCommitMessage builds the message for a commit of the
+// changes in the worktree at path since base: with --ai-message it is
+// written by git.message_command, otherwise as resolveCommitMessage does
+func resolveWorktreeCommitMessage(cmd *cobra.Command, manager *session.Manager, path, base string) (string, error) {
+	if ai, _ := cmd.Flags().GetBool("ai-message"); !ai {
+		return resolveCommitMessage(cmd)
+	}
+
+	progress := ui.StartProgress("Writing commit message")
+	message, err := manager.GenerateCommitMessage(path, base)
+	progress.Stop()
+	if err != nil {
+		return "", err
+	}
+
+	// Nothing is committed before the message is confirmed
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		ui.Info("Suggested commit message:")
+		for _, line := range strings.Split(message, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Print("Use this message? (Y/n, e to edit): ")
+		if !scanner.Scan() {
+			return "", fmt.Errorf("commit cancelled")
+		}
+
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "", "y", "yes":
+			if loadConfig(cmd).Git.CommitStyle == git.CommitStyleConventional {
+				commit, err := git.ParseConventionalCommit(message)
+				if err != nil {
+					return "", err
+				}
+				return commit.String(), nil
+			}
+			return message, nil
+		case "e":
+			edited, err := editText(message, "commit-message-*.txt")
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(edited) == "" {
+				return "", fmt.Errorf("commit message cannot be empty")
+			}
+			message = strings.TrimSpace(edited)
+			fmt.Println()
+		case "n", "no":
+			return "", fmt.Errorf("commit cancelled")
+		}
+	}
+}
+
+// runConventionalCommitWizard prompts for each part of a conventional commit,
+// re-asking until every answer is valid
+func runConventionalCommitWizard(defaultScope string) (string, error) {
+	scanner := bufio.NewScanner(os.Stdin)
+	ask := func(prompt string) (string, bool) {
+		fmt.Print(prompt)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	ui.Title("📝 Conventional commit")
+	ui.Infof("Types: %s", strings.Join(git.ConventionalTypes, ", "))
+
+	var commit git.ConventionalCommit
+	for {
+		commitType, ok := ask("Type: ")
+		if !ok {
+			return "", fmt.Errorf("commit cancelled")
+		}
+		if git.IsConventionalType(commitType) {
+			commit.Type = commitType
+			break
+		}
+		ui.Errorf("✗ Unknown type %q", commitType)
+	}
+
+	for {
+		prompt := "Scope (optional): "
+		if defaultScope != "" {
+			prompt = fmt.Sprintf("Scope [%s]: ", defaultScope)
+		}
+		scope, ok := ask(prompt)
+		if !ok {
+			return "", fmt.Errorf("commit cancelled")
+		}
+		if scope == "" {
+			scope = defaultScope
+		}
+		commit.Scope = scope
+		if err := (git.ConventionalCommit{Type: commit.Type, Scope: scope, Subject: "x"}).Validate(); err != nil {
+			ui.Errorf("✗ %v", err)
+			continue
+		}
+		break
+	}
+
+	for {
+		subject, ok := ask("Subject: ")
+		if !ok {
+			return "", fmt.Errorf("commit cancelled")
+		}
+		commit.Subject = subject
+		if err := commit.Validate(); err != nil {
+			ui.Errorf("✗ %v", err)
+			continue
+		}
+		break
+	}
+
+	body, ok := ask("Body (optional): ")
+	if !ok {
+		return "", fmt.Errorf("commit cancelled")
+	}
+	commit.Body = body
+
+	breaking, ok := ask("Breaking change? (y/N): ")
+	if !ok {
+		return "", fmt.Errorf("commit cancelled")
+	}
+	commit.Breaking = strings.ToLower(breaking) == "y"
+
+	if err := commit.Validate(); err != nil {
+		return "", err
+	}
+
+	message := commit.String()
+	fmt.Println()
+	ui.Infof("Commit message: %s", commit.Header())
+	return message, nil
+}
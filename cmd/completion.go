@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// Completion functions run on every <TAB>, so they stay silent and return no
+// candidates rather than reporting errors.
+
+// sessionNames returns the names of the sessions of the repository cmd
+// operates on
+func sessionNames(cmd *cobra.Command) []string {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return nil
+	}
+	sessions, err := session.NewManager(currentDir).ListSessions()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// worktreeBranches returns the branches checked out in the repository's
+// worktrees
+func worktreeBranches(cmd *cobra.Command) []string {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return nil
+	}
+	worktrees, err := git.NewWorktreeManager(currentDir).List()
+	if err != nil {
+		return nil
+	}
+
+	var branches []string
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	return branches
+}
+
+// localBranches returns all local branches of the repository
+func localBranches(cmd *cobra.Command) []string {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return nil
+	}
+	branches, _ := git.NewBranchManager(currentDir).List()
+	return branches
+}
+
+// completeBaseBranch completes a branch to start a session from, local or
+// remote
+func completeBaseBranch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	branches, _ := git.NewBranchManager(currentDir).ListWithRemotes()
+	return branches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSession completes a single session name argument
+func completeSession(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sessionNames(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktreeBranch completes a single branch checked out in a worktree
+func completeWorktreeBranch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return worktreeBranches(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCheckoutBranch completes a branch that has no worktree yet
+func completeCheckoutBranch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	checkedOut := make(map[string]bool)
+	for _, branch := range worktreeBranches(cmd) {
+		checkedOut[branch] = true
+	}
+
+	var branches []string
+	for _, branch := range localBranches(cmd) {
+		if !checkedOut[branch] {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeExec completes the session of exec, then falls back to the
+// shell's own completion for the command to run
+func completeExec(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return sessionNames(cmd), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+// completeDirectory completes a single directory argument
+func completeDirectory(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completeMove completes the session and then the destination directory
+func completeMove(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return sessionNames(cmd), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completePick completes the session and then its commits that are not on
+// the current branch
+func completePick(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return sessionNames(cmd), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		currentDir, err := workingDir(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		manager := session.NewManager(currentDir)
+		currentBranch, err := manager.GetCurrentBranch()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		sessions, err := manager.ListSessions()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		s := findSession(sessions, args[0])
+		if s == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		commits, _ := git.GetCommits(s.Path, currentBranch+".."+s.Branch, time.Time{})
+
+		var completions []string
+		for _, c := range commits {
+			completions = append(completions, shortHashes([]string{c.Hash})[0]+"\t"+c.Subject)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// repoNames returns the names of the known repositories
+func repoNames() []string {
+	repos, err := session.Repos()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// completeSparseProfile completes the sparse-checkout profiles in the config
+func completeSparseProfile(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return loadConfig(cmd).SparseProfiles(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBranchType completes --type with branch.types
+func completeBranchType(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return loadConfig(cmd).BranchTypes(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeIdentity completes --identity with the identities in the config
+func completeIdentity(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return loadConfig(cmd).IdentityNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// fixedCompletion completes a flag from a fixed set of values
+func fixedCompletion(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// registerFlagCompletions adds completions for flags shared by all commands
+func registerFlagCompletions(rootCmd *cobra.Command) {
+	_ = rootCmd.RegisterFlagCompletionFunc("repo", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Offer known repositories by name, and directories once a path is typed
+		if isRepoName(toComplete) || toComplete == "" {
+			if names := repoNames(); len(names) > 0 {
+				return names, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+}
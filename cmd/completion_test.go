@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteCheckoutBranch(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main", repo},
+		{"-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Initial commit"},
+		{"-C", repo, "branch", "feature/free"},
+		{"-C", repo, "worktree", "add", "-b", "feature/taken", filepath.Join(t.TempDir(), "taken")},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	branches, directive := completeCheckoutBranch(newRepoFlagCmd(repo), nil, "")
+	if expected := []string{"feature/free"}; !reflect.DeepEqual(branches, expected) {
+		t.Errorf("completeCheckoutBranch() = %v, expected %v", branches, expected)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("completeCheckoutBranch() directive = %v, expected NoFileComp", directive)
+	}
+
+	if branches, _ := completeCheckoutBranch(newRepoFlagCmd(repo), []string{"feature/free"}, ""); len(branches) != 0 {
+		t.Errorf("completeCheckoutBranch() after the branch argument = %v, expected none", branches)
+	}
+}
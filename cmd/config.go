@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
 
 	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/i18n"
+	"github.com/ksred/ccswitch/internal/integrations"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -31,7 +37,16 @@ func newConfigCmd() *cobra.Command {
 }
 
 func showConfig(cmd *cobra.Command, args []string) {
-	cfg, err := config.Load()
+	var repoRoot string
+	if dir, err := workingDir(cmd); err == nil {
+		repoRoot, _ = git.GetMainRepoPath(dir)
+	}
+
+	load := config.Load
+	if repoRoot != "" {
+		load = func() (*config.Config, error) { return config.LoadForRepo(repoRoot) }
+	}
+	cfg, err := load()
 	if err != nil {
 		ui.Errorf("✗ Failed to load config: %v", err)
 		return
@@ -42,24 +57,182 @@ func showConfig(cmd *cobra.Command, args []string) {
 
 	ui.Success("Branch:")
 	ui.Infof("  Prefix: %s", cfg.Branch.Prefix)
+	if cfg.Branch.Template != "" {
+		ui.Infof("  Template: %s", cfg.Branch.Template)
+	} else {
+		ui.Info("  Template: {prefix}{name} (default)")
+	}
+	ui.Infof("  Types: %s", strings.Join(cfg.BranchTypes(), ", "))
+	if cfg.Branch.User != "" {
+		ui.Infof("  User: %s", cfg.Branch.User)
+	}
+	if cfg.Branch.MaxLength > 0 {
+		ui.Infof("  Max length: %d", cfg.Branch.MaxLength)
+	}
 	fmt.Println()
 
 	ui.Success("Worktree:")
 	ui.Infof("  Relative path: %s", cfg.Worktree.RelativePath)
+	if cfg.Worktree.Root != "" {
+		ui.Infof("  Root: %s", cfg.Worktree.Root)
+	} else {
+		ui.Info("  Root: ~/.ccswitch/worktrees (default)")
+	}
+	if cfg.Worktree.CreationStrategy != "" {
+		ui.Infof("  Creation strategy: %s", cfg.Worktree.CreationStrategy)
+	} else {
+		ui.Info("  Creation strategy: full (default)")
+	}
+	if len(cfg.Worktree.Links) > 0 {
+		ui.Infof("  Links: %s", strings.Join(cfg.Worktree.Links, ", "))
+	}
 	fmt.Println()
 
 	ui.Success("UI:")
 	ui.Infof("  Show emoji: %v", cfg.UI.ShowEmoji)
 	ui.Infof("  Color scheme: %s", cfg.UI.ColorScheme)
+	ui.Infof("  Language: %s", i18n.Language())
+	if cfg.Prompt.CacheTTL != "" {
+		ui.Infof("  Prompt cache: %s", cfg.Prompt.CacheTTL)
+	} else {
+		ui.Infof("  Prompt cache: %s (default)", config.DefaultPromptCacheTTL)
+	}
 	fmt.Println()
 
 	ui.Success("Git:")
 	ui.Infof("  Default branch: %s", cfg.Git.DefaultBranch)
 	ui.Infof("  Auto fetch: %v", cfg.Git.AutoFetch)
+	ui.Infof("  Auto push: %v", cfg.Git.AutoPush)
+	ui.Infof("  Sign commits: %v", cfg.Git.SignCommits)
+	if cfg.Git.CommitHooks != "" {
+		ui.Infof("  Commit hooks: %s", cfg.Git.CommitHooks)
+	} else {
+		ui.Info("  Commit hooks: run unless --no-verify (default)")
+	}
+	if len(cfg.Git.ProtectedBranches) > 0 {
+		ui.Infof("  Protected branches: %s", strings.Join(cfg.Git.ProtectedBranches, ", "))
+	} else {
+		ui.Info("  Protected branches: none")
+	}
+	ui.Infof("  Commit style: %s", commitStyleLabel(cfg.Git.CommitStyle))
+	if cfg.Git.Timeout != "" {
+		ui.Infof("  Timeout: %s", cfg.Git.Timeout)
+	} else {
+		ui.Info("  Timeout: none")
+	}
+	if cfg.Git.StatusCache != "" {
+		ui.Infof("  Status cache: on disk, dirty state kept for %s", cfg.Git.StatusCache)
+	} else {
+		ui.Info("  Status cache: in memory")
+	}
+	if cfg.Git.VerifyCommand != "" {
+		ui.Infof("  Verify command: %s", cfg.Git.VerifyCommand)
+	} else {
+		ui.Info("  Verify command: none")
+	}
+	if cfg.Git.MessageCommand != "" {
+		ui.Infof("  Message command: %s", cfg.Git.MessageCommand)
+	} else {
+		ui.Info("  Message command: none")
+	}
+	if len(cfg.Git.CoAuthors) > 0 {
+		ui.Infof("  Co-authors: %s", strings.Join(cfg.Git.CoAuthors, ", "))
+	} else {
+		ui.Info("  Co-authors: none")
+	}
+	ui.Infof("  Keep author when squashing: %v", cfg.Git.KeepAuthor)
+	fmt.Println()
+
+	ui.Success("Prune:")
+	ui.Infof("  Artifact dirs: %s", strings.Join(cfg.Prune.ArtifactDirs, ", "))
+	fmt.Println()
+
+	ui.Success("Nag:")
+	ui.Infof("  Dirty after: %s", cfg.Nag.DirtyAfter)
+	ui.Infof("  Check interval: %s", cfg.Nag.CheckInterval)
+	fmt.Println()
+
+	ui.Success("Issues:")
+	if cfg.Issues.URL != "" {
+		ui.Infof("  URL: %s", cfg.Issues.URL)
+	} else {
+		ui.Info("  URL: none")
+	}
+	fmt.Println()
+
+	ui.Success("Identities:")
+	if len(cfg.Identities) == 0 {
+		ui.Info("  None")
+	}
+	for _, name := range cfg.IdentityNames() {
+		identity := cfg.Identities[name]
+		line := fmt.Sprintf("  %s: %s", name, identity)
+		if name == cfg.Identity {
+			line += " (default)"
+		}
+		if len(identity.Types) > 0 {
+			line += fmt.Sprintf(" for %s", strings.Join(identity.Types, ", "))
+		}
+		ui.Info(line)
+	}
+	fmt.Println()
+
+	ui.Success("Notifications:")
+	if cfg.Notifications.Command != "" {
+		ui.Infof("  Command: %s", cfg.Notifications.Command)
+	} else {
+		ui.Info("  Command: none")
+	}
+	if cfg.Notifications.Webhook != "" {
+		ui.Infof("  Webhook: %s", webhookLabel(cfg.Notifications.Webhook))
+	} else {
+		ui.Info("  Webhook: none")
+	}
+	ui.Infof("  Desktop (work): %v", cfg.Notify)
+	fmt.Println()
+
+	ui.Success("Integrations:")
+	if len(cfg.Integrations) > 0 {
+		ui.Infof("  Enabled: %s", strings.Join(cfg.Integrations, ", "))
+	} else {
+		ui.Infof("  Enabled: none (available: %s)", strings.Join(integrations.Names(), ", "))
+	}
 	fmt.Println()
 
+	if len(cfg.Sparse) > 0 {
+		ui.Success("Sparse profiles:")
+		for _, name := range cfg.SparseProfiles() {
+			ui.Infof("  %s: %s", name, strings.Join(cfg.Sparse[name], ", "))
+		}
+		fmt.Println()
+	}
+
+	if len(cfg.Alias) > 0 {
+		aliases, _, _ := config.LoadAliases(repoRoot)
+		ui.Success("Aliases:")
+		for _, name := range cfg.AliasNames() {
+			definition, ok := aliases[name]
+			line := fmt.Sprintf("  %s: %s", name, definition)
+			if !ok {
+				line = fmt.Sprintf("  %s: %s (ignored: shell aliases aren't taken from the repository's config.yaml)", name, cfg.Alias[name])
+			}
+			if isCommand(cmd.Root(), name) {
+				line += " (unused: a command has this name)"
+			}
+			ui.Info(line)
+		}
+		fmt.Println()
+	}
+
 	configPath := config.GetConfigPath()
 	ui.Infof("Config file: %s", configPath)
+	if repoRoot != "" {
+		for _, path := range []string{config.RepoConfigPath(repoRoot), config.LocalConfigPath(repoRoot)} {
+			if _, err := os.Stat(path); err == nil {
+				ui.Infof("Repository config: %s", path)
+			}
+		}
+	}
 }
 
 func showConfigPath(cmd *cobra.Command, args []string) {
@@ -78,3 +251,21 @@ func initConfig(cmd *cobra.Command, args []string) {
 	fmt.Println()
 	fmt.Println("You can now edit this file to customize ccswitch behavior.")
 }
+
+// commitStyleLabel returns a display label for the configured commit style
+func commitStyleLabel(style string) string {
+	if style == "" {
+		return "free-form"
+	}
+	return style
+}
+
+// webhookLabel shows a webhook URL without its path, which usually holds
+// the secret
+func webhookLabel(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newContainerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "container",
+		Short: "Run a dev container for each session",
+		Long: `Give each session an isolated runtime: a dev container with the session's
+worktree mounted, started and stopped with the session.
+
+The container is defined by the worktree's .devcontainer/devcontainer.json
+and run with the devcontainer CLI, or by a compose file set in the config
+and run with docker compose from the worktree, so that "." mounts it:
+
+  container:
+    compose: docker-compose.dev.yml  # Relative to the worktree
+    service: app                     # Where exec runs (default: the first)
+
+Each session's containers are named after it, and the tools see the
+session's variables (see 'ccswitch env'), so a compose file can publish
+"${PORT}:3000" to keep sessions off each other's ports. The container is
+recorded with the session, and deleting the session stops it.
+
+Examples:
+  ccswitch container up fix-login
+  ccswitch container exec fix-login -- npm test
+  ccswitch container exec fix-login           # Open a shell
+  ccswitch container down fix-login`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "up [session]",
+		Short:             "Build and start the dev container of a session",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               containerUp,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "exec <session> [-- command [args...]]",
+		Short: "Run a command in the dev container of a session",
+		Long: `Run a command in the dev container of a session, started with
+'ccswitch container up', or open a shell if no command is given. The
+command's exit code becomes ccswitch's exit code.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               containerExec,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:               "down [session]",
+		Short:             "Stop and remove the dev container of a session",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               containerDown,
+	})
+
+	return cmd
+}
+
+func containerUp(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to start the container of:")
+	if selected == nil {
+		return
+	}
+
+	ui.Infof("Starting the container of %s...", selected.Name)
+	c, started, err := manager.ContainerUp(*selected, os.Stderr)
+	if err != nil {
+		ui.Errorf("✗ Failed to start the container: %v", err)
+		return
+	}
+	if !started {
+		ui.Successf("✓ The container of %s is already running", selected.Name)
+	} else {
+		ui.Successf("✓ Started the container of %s", selected.Name)
+	}
+	ui.Infof("Container: %s (%s)", shortID(c.ID), c.Kind)
+	ui.Infof("Run commands in it with: ccswitch container exec %s -- <command>", selected.Name)
+}
+
+func containerExec(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		os.Exit(1)
+	}
+
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		os.Exit(1)
+	}
+
+	c := manager.Container(*selected)
+	if c == nil {
+		ui.Errorf("✗ %s has no container", selected.Name)
+		ui.Infof("  Tip: Start it with 'ccswitch container up %s'", selected.Name)
+		os.Exit(1)
+	}
+
+	command := args[1:]
+	if len(command) == 0 {
+		command = []string{"sh"}
+	}
+	argv := c.ExecArgs(selected.Path, command, ui.Interactive())
+
+	_ = manager.MarkUsed(*selected)
+	if err := executeInDir(selected.Path, argv[0], argv[1:], manager.Env(os.Environ(), *selected)); err != nil {
+		code := proc.ExitCode(err)
+		if code == 127 {
+			ui.Errorf("✗ %v", err)
+		}
+		os.Exit(code)
+	}
+}
+
+func containerDown(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to stop the container of:")
+	if selected == nil {
+		return
+	}
+
+	stopped, err := manager.ContainerDown(*selected, os.Stderr)
+	switch {
+	case err != nil:
+		ui.Errorf("✗ Failed to stop the container: %v", err)
+	case !stopped:
+		ui.Infof("%s has no container", selected.Name)
+	default:
+		ui.Successf("✓ Stopped the container of %s", selected.Name)
+	}
+}
+
+// shortID abbreviates a container ID as docker does
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -3,12 +3,16 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ksred/ccswitch/internal/config"
 	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/github"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/ksred/ccswitch/internal/utils"
@@ -16,40 +20,298 @@ import (
 )
 
 func newCreateCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new session",
-		Run:   createSession,
+		Long: `Create a new session: a branch and a worktree for what you are working on.
+
+In repositories that use Git LFS or submodules, the new worktree gets its LFS
+objects with 'git lfs pull' and its submodules with
+'git submodule update --init --recursive'. Pass --skip-lfs-submodules to
+leave them out, e.g. when you only need the source files.
+
+In large repositories, --sparse checks out only the paths of a profile
+defined in the configuration, which makes the worktree faster to create and
+smaller on disk:
+
+  sparse:
+    backend:
+      - services/api
+      - libs/common
+
+Files at the top level of the repository are always checked out.
+
+worktree.creation_strategy in the configuration sets how every new worktree
+is checked out, and the time it took is reported:
+  full     Every file (the default). In a partial clone (git clone
+           --filter=blob:none), only the file contents the worktree needs
+           are fetched.
+  sparse   Only the files at the top level, with a sparse index; add
+           directories with 'git sparse-checkout add <dir>'
+  partial  Deprecated; the same as full
+
+The branch of the session is named by the branch section of the
+configuration. By default it is the prefix followed by the slugified
+description; a template can add the kind of work and who it belongs to,
+and max_length keeps names short by shortening the description part:
+
+  branch:
+    template: "{user}/{type}/{name}"
+    types: [feat, fix, chore]   # --type picks one; the first is the default
+    max_length: 40
+
+{user} is branch.user, or git's user.name. Names the policy does not allow
+are refused with the rule they break.
+
+Sessions start from the current branch unless --base names another local or
+remote branch, such as origin/develop; --choose-base lists them to pick
+from. The base is recorded with the session, and 'ccswitch sync' rebases
+the session onto it.
+
+--at pins the session to a tag or commit instead: its worktree has a
+detached HEAD and no branch is created, e.g. to bisect or reproduce a
+release. Listings show such sessions as detached.
+
+--issue ties the session to a ticket, and --link to the address of its
+task. Both are shown by list and status, and 'ccswitch open-issue' opens the
+task in a browser. With issues.url set, e.g. to
+"https://example.atlassian.net/browse/{id}", the issue alone is enough.
+
+--tag labels the session, e.g. with the area it touches, so that
+'ccswitch list --tag' can filter on it; it can be repeated. 'ccswitch tag'
+changes the tags of existing sessions.
+
+--ttl makes a throwaway session, e.g. for a one-shot experiment, expire after
+a while, such as 2d or 12h. Expired sessions are flagged by list and status,
+and 'ccswitch cleanup --expired' removes them.
+
+--from-issue starts work on a GitHub issue of the repository's origin: the
+session is named after the issue's title instead of asking what you are
+working on, it is tied to the issue, and 'ccswitch pr' adds "Closes #<n>"
+to the pull request. The issue is read with the gh CLI, or else the GitHub
+API, authenticated with GITHUB_TOKEN if it is set.
+
+Each session can commit as its own git identity, such as a work or an
+open-source one. Identities are defined in the configuration and set in the
+new worktree alone, with 'git config --worktree':
+
+  identities:
+    work:
+      name: Jo Doe
+      email: jo@example.com
+      signing_key: ABCD1234
+    oss:
+      email: jo@users.noreply.github.com
+      types: [oss]      # Sessions created with --type oss use it
+  identity: work        # The identity of other sessions
+
+--identity picks one for the new session.
+
+Examples:
+  ccswitch create
+  ccswitch create --identity oss
+  ccswitch create --type fix
+  ccswitch create --base origin/release/2.0
+  ccswitch create --choose-base
+  ccswitch create --at v1.4.2         # Detached at a release tag
+  ccswitch create --issue JIRA-123
+  ccswitch create --link https://github.com/org/repo/issues/42
+  ccswitch create --from-issue 123
+  ccswitch create --tag frontend --tag urgent
+  ccswitch create --ttl 2d              # Expires in two days
+  ccswitch create --sparse backend`,
+		Run: createSession,
+	}
+
+	cmd.Flags().String("sparse", "", "Check out only the paths of this sparse-checkout profile")
+	_ = cmd.RegisterFlagCompletionFunc("sparse", completeSparseProfile)
+	cmd.Flags().String("base", "", "Start the session from this local or remote branch instead of the current branch")
+	_ = cmd.RegisterFlagCompletionFunc("base", completeBaseBranch)
+	cmd.Flags().Bool("choose-base", false, "Pick the branch to start from among local and remote branches")
+	cmd.Flags().String("at", "", "Pin the session to this tag or commit in a detached worktree, without a branch")
+	cmd.Flags().String("issue", "", "Issue the session works on, e.g. JIRA-123")
+	cmd.Flags().String("link", "", "Address of the session's task, e.g. an issue or ticket URL")
+	cmd.Flags().StringSlice("tag", nil, "Label the session with this tag (repeatable)")
+	cmd.Flags().String("ttl", "", "Let the session expire after this long, e.g. 2d or 12h")
+	cmd.Flags().Int("from-issue", 0, "Name the session after this GitHub issue and tie it to the issue")
+	cmd.Flags().String("identity", "", "Commit as this identity from the config in the new session")
+	_ = cmd.RegisterFlagCompletionFunc("identity", completeIdentity)
+	addBranchTypeFlag(cmd)
+	addSetupFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+// applyBaseFlags sets where the new session starts from --base or
+// --choose-base. Errors are reported to the user and false is returned.
+func applyBaseFlags(cmd *cobra.Command, manager *session.Manager, dir string) bool {
+	base, _ := cmd.Flags().GetString("base")
+	if choose, _ := cmd.Flags().GetBool("choose-base"); choose {
+		if base != "" {
+			ui.Error("✗ --base and --choose-base cannot be used together")
+			return false
+		}
+		branches, err := git.NewBranchManager(dir).ListWithRemotes()
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return false
+		}
+		if base, err = ui.PickBranch(branches, "🌱 Start the session from:"); err != nil {
+			ui.Errorf("✗ %v", err)
+			return false
+		}
+		if base == "" {
+			return false // User quit
+		}
+		fmt.Println()
 	}
+
+	if err := manager.SetBase(base); err != nil {
+		ui.Errorf("✗ %v", err)
+		ui.Infof("  Tip: %s", errors.ErrorHint(err))
+		return false
+	}
+	return true
+}
+
+// addBranchTypeFlag registers --type on commands that name branches after
+// branch.template
+func addBranchTypeFlag(cmd *cobra.Command) {
+	cmd.Flags().String("type", "", "Kind of work filling {type} in branch.template, one of branch.types")
+	_ = cmd.RegisterFlagCompletionFunc("type", completeBranchType)
+}
+
+// applyBranchType passes --type to the manager
+func applyBranchType(cmd *cobra.Command, manager *session.Manager) {
+	branchType, _ := cmd.Flags().GetString("type")
+	manager.SetBranchType(branchType)
 }
 
 func createSession(cmd *cobra.Command, args []string) {
+	sparse, _ := cmd.Flags().GetString("sparse")
+
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
+	applyBranchType(cmd, manager)
+	identity, _ := cmd.Flags().GetString("identity")
+	if err := manager.SetIdentity(identity); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
 
-	// Get description from user
-	fmt.Print(ui.TitleStyle.Render("🚀 What are you working on? "))
+	strategy, ok := creationStrategy(manager)
+	if !ok {
+		return
+	}
+	at, _ := cmd.Flags().GetString("at")
+	if at != "" && (cmd.Flags().Changed("base") || cmd.Flags().Changed("choose-base")) {
+		ui.Error("✗ --at cannot be used with --base or --choose-base")
+		return
+	}
+	if !applyBaseFlags(cmd, manager, currentDir) {
+		return
+	}
+	issue, _ := cmd.Flags().GetString("issue")
+	link, _ := cmd.Flags().GetString("link")
 
-	scanner := bufio.NewScanner(os.Stdin)
-	if !scanner.Scan() {
+	// Name the session after a GitHub issue instead of asking
+	var description string
+	if number, _ := cmd.Flags().GetInt("from-issue"); number != 0 {
+		if issue != "" || link != "" {
+			ui.Error("✗ --from-issue cannot be used with --issue or --link")
+			return
+		}
+		progress := ui.StartProgress(fmt.Sprintf("Fetching issue #%d", number))
+		ghIssue, err := github.FetchIssue(currentDir, number)
+		progress.Stop()
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			ui.Info("  Tip: Install and log in to the GitHub CLI (gh), or set GITHUB_TOKEN")
+			return
+		}
+		description = strings.TrimSpace(ghIssue.Title)
+		if utils.Slugify(description) == "" {
+			description = "issue-" + strconv.Itoa(ghIssue.Number)
+		}
+		issue, link = ghIssue.Ref(), ghIssue.URL
+		ui.Infof("Issue %s: %s", issue, ghIssue.Title)
+	}
+
+	if err := manager.SetIssue(strings.TrimSpace(issue), strings.TrimSpace(link)); err != nil {
+		ui.Errorf("✗ %v", err)
 		return
 	}
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	if err := manager.SetTags(tags); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if ttl, _ := cmd.Flags().GetString("ttl"); ttl != "" {
+		d, err := utils.ParseDuration(ttl)
+		if err == nil {
+			err = manager.SetTTL(d)
+		}
+		if err != nil {
+			ui.Errorf("✗ Invalid --ttl: %v", err)
+			return
+		}
+	}
+
+	// Catch a mistyped profile before asking for the description
+	var sparsePaths []string
+	if sparse != "" {
+		if sparsePaths, err = manager.Config().SparseProfile(sparse); err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+	}
 
-	description := strings.TrimSpace(scanner.Text())
+	// Get description from user
 	if description == "" {
-		ui.Error("✗ Description cannot be empty")
+		fmt.Print(ui.TitleStyle.Render("🚀 What are you working on? "))
+
+		scanner := bufio.NewScanner(os.Stdin)
+		if !scanner.Scan() {
+			return
+		}
+
+		description = strings.TrimSpace(scanner.Text())
+		if description == "" {
+			ui.Error("✗ Description cannot be empty")
+			return
+		}
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
 		return
 	}
+	defer lock.Release()
 
 	// Create the session
-	if err := manager.CreateSession(description); err != nil {
+	start := time.Now()
+	create := manager.CreateSession
+	switch {
+	case at != "":
+		create = func(description string) error {
+			_, err := manager.CreateDetachedSession(description, at, sparsePaths)
+			return err
+		}
+	case sparse != "":
+		create = func(description string) error { return manager.CreateSparseSession(description, sparse) }
+	}
+	progress := ui.StartProgress("Creating worktree")
+	err = create(description)
+	progress.Stop()
+	if err != nil {
 		ui.Errorf("✗ %s", err)
 
 		// Provide helpful tips based on error
@@ -60,29 +322,137 @@ func createSession(cmd *cobra.Command, args []string) {
 
 		// Special handling for branch exists error
 		if errors.IsBranchExists(err) {
-			cfg, _ := config.Load()
-			branchName := cfg.Branch.Prefix + utils.Slugify(description)
+			branchName, _ := manager.BranchName(description)
 			ui.Infof("  Branch: %s", branchName)
 		}
 		return
 	}
 
-	// Success!
+	reportCheckout(strategy, sparse, sparsePaths, time.Since(start))
+	setupWorktree(cmd, manager, manager.GetSessionPath(utils.Slugify(description)))
+	reportCreatedSession(cmd, manager, description)
+}
+
+// addSetupFlag registers --skip-lfs-submodules on commands that create
+// worktrees
+func addSetupFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("skip-lfs-submodules", false, "Don't pull Git LFS objects or initialize submodules in the new worktree")
+}
+
+// setupWorktree fetches the content a plain 'git worktree add' leaves out:
+// LFS objects and submodules, when the worktree uses them, and the paths of
+// worktree.links. Failures are reported as warnings, since the worktree
+// itself is usable.
+func setupWorktree(cmd *cobra.Command, manager *session.Manager, path string) {
+	linkShared(manager, path)
+
+	if skip, _ := cmd.Flags().GetBool("skip-lfs-submodules"); skip {
+		return
+	}
+
+	if git.UsesLFS(path) {
+		if !git.LFSInstalled(path) {
+			ui.Warning("⚠ This repository uses Git LFS but git-lfs is not installed; large files are left as pointers")
+		} else {
+			err := ui.WithProgress("Pulling Git LFS objects", func(out io.Writer) error {
+				return git.PullLFS(path, out)
+			})
+			if err != nil {
+				ui.Warningf("⚠ %v", err)
+				ui.Infof("  Tip: Run 'git lfs pull' in %s", path)
+			}
+		}
+	}
+
+	if git.HasSubmodules(path) {
+		err := ui.WithProgress("Initializing submodules", func(out io.Writer) error {
+			return git.UpdateSubmodules(path, out)
+		})
+		if err != nil {
+			ui.Warningf("⚠ %v", err)
+			ui.Infof("  Tip: Run 'git submodule update --init --recursive' in %s", path)
+		}
+	}
+}
+
+// linkShared links the paths of worktree.links into the worktree at path,
+// saying which had to be copied because symlinks are not available
+func linkShared(manager *session.Manager, path string) {
+	for _, link := range manager.LinkShared(path) {
+		switch {
+		case link.Err != nil:
+			ui.Warningf("⚠ Failed to link %s: %v", link.Path, link.Err)
+		case link.Kind == utils.LinkCopy:
+			ui.Warningf("⚠ Copied %s instead of linking it, since symlinks are not available", link.Path)
+			ui.Info("  Tip: Turn on Developer Mode in Windows settings to let ccswitch create symlinks")
+		default:
+			ui.Infof("Linked %s (%s)", link.Path, link.Kind)
+		}
+	}
+}
+
+// creationStrategy returns the configured worktree creation strategy,
+// warning about the deprecated partial strategy. An invalid strategy is
+// reported and false is returned.
+func creationStrategy(manager *session.Manager) (string, bool) {
+	strategy, err := manager.Config().CreationStrategy()
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return "", false
+	}
+	if manager.Config().Worktree.CreationStrategy == config.StrategyPartial {
+		ui.Warning("⚠ creation_strategy: partial is deprecated and checks out like full")
+		ui.Info("  Tip: Set creation_strategy to full; a partial clone (git clone --filter=blob:none) fetches only the file contents worktrees need either way")
+	}
+	return strategy, true
+}
+
+// reportCheckout prints how a new worktree was checked out and how long it
+// took
+func reportCheckout(strategy, profile string, paths []string, elapsed time.Duration) {
+	elapsed = elapsed.Round(time.Millisecond)
+	switch {
+	case profile != "":
+		ui.Infof("Checkout: sparse profile %s (%s) in %s", profile, strings.Join(paths, ", "), elapsed)
+	case strategy == config.StrategySparse:
+		ui.Infof("Checkout: sparse, top-level files only, in %s", elapsed)
+	default:
+		ui.Infof("Checkout: %s in %s", strategy, elapsed)
+	}
+}
+
+// reportCreatedSession prints where a newly created session lives and the cd
+// line for the shell wrapper
+func reportCreatedSession(cmd *cobra.Command, manager *session.Manager, description string) {
 	sessionName := utils.Slugify(description)
-	cfg, _ := config.Load()
-	branchName := cfg.Branch.Prefix + sessionName
-	repoName := filepath.Base(currentDir)
+	branchName, _ := manager.BranchName(description)
 
 	// Get the full worktree path
-	homeDir, _ := os.UserHomeDir()
-	worktreePath := filepath.Join(homeDir, ".ccswitch", "worktrees", repoName, sessionName)
+	worktreePath := manager.GetSessionPath(sessionName)
 
 	ui.Successf("✓ Created session: %s", sessionName)
-	ui.Infof("Branch: %s", branchName)
-	ui.Infof("Location: ~/.ccswitch/worktrees/%s/%s", repoName, sessionName)
+	created := git.SessionInfo{Path: worktreePath}
+	if ref := manager.Ref(created); ref != "" {
+		ui.Infof("Branch: none, %s at %s", git.DetachedLabel, ref)
+	} else {
+		ui.Infof("Branch: %s", branchName)
+	}
+	if issue := issueLabel(manager.Issue(created)); issue != "" {
+		ui.Infof("Issue: %s", issue)
+	}
+	if tags := manager.Tags(created); len(tags) > 0 {
+		ui.Infof("Tags: %s", strings.Join(tags, ", "))
+	}
+	if expires := manager.ExpiresAt(created); expires != nil {
+		ui.Infof("Expires: %s (%s)", utils.FormatRelative(*expires, time.Now()), utils.FormatTimestamp(*expires))
+	}
+	if name, identity := manager.Identity(created); name != "" {
+		ui.Infof("Identity: %s (%s)", name, identity)
+	}
+	ui.Infof("Location: %s", abbreviateHome(worktreePath))
 
 	// Output the cd command for the shell wrapper to execute on a separate line
-	fmt.Printf("\ncd %s\n", worktreePath)
+	printCd(cmd, worktreePath)
 
 	// If shell integration is not active, show a helpful message
 	if !utils.IsShellIntegrationActive() {
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/fanout"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Fetch and check sessions against their base in the background",
+		Long: `Periodically fetch the base branch and find the sessions that have fallen
+behind it, so you hear about upstream changes before a rebase gets painful.
+
+The base is --base, or else the current branch of the main repository; when
+it tracks a remote branch, that is fetched and sessions are compared to it,
+e.g. origin/main. With --fanout, sessions that are clean, not ahead of the
+base and not protected are rebased onto it, with the same safety checks as
+'ccswitch fanout' (git.verify_command included). Sessions with work of their
+own are only reported.
+
+Each pass writes its results to a status file, shown by 'ccswitch status'.
+The daemon runs until interrupted; 'ccswitch daemon unit' prints a systemd
+user service or, on macOS, a launchd agent that keeps it running:
+  ccswitch daemon unit > ~/.config/systemd/user/ccswitch-myrepo.service
+  systemctl --user enable --now ccswitch-myrepo
+
+Examples:
+  ccswitch daemon                     # Check every 15 minutes
+  ccswitch daemon --interval 5m --fanout
+  ccswitch daemon --once              # A single pass, e.g. from cron
+  ccswitch daemon unit --fanout       # Print a service running 'daemon --fanout'`,
+		Args: cobra.NoArgs,
+		Run:  runDaemon,
+	}
+
+	addDaemonFlags(cmd)
+	cmd.Flags().Bool("once", false, "Run a single pass and exit")
+	cmd.AddCommand(newDaemonUnitCmd())
+
+	return cmd
+}
+
+// addDaemonFlags registers the flags that daemon and daemon unit share
+func addDaemonFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("interval", 15*time.Minute, "How often to fetch and check sessions")
+	cmd.Flags().String("base", "", "Branch to compare sessions to (default: current branch of the main repository)")
+	cmd.Flags().Bool("fanout", false, "Rebase clean sessions that are behind onto the base")
+	_ = cmd.RegisterFlagCompletionFunc("base", completeBaseBranch)
+}
+
+func newDaemonUnitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unit",
+		Short: "Print a systemd or launchd service running the daemon",
+		Long: `Print a service definition that runs 'ccswitch daemon' for this repository
+with the given flags: a systemd user service, or a launchd agent on macOS.
+
+Examples:
+  ccswitch daemon unit > ~/.config/systemd/user/ccswitch-myrepo.service
+  ccswitch daemon unit --launchd > ~/Library/LaunchAgents/com.ccswitch.myrepo.plist`,
+		Args: cobra.NoArgs,
+		Run:  printDaemonUnit,
+	}
+
+	addDaemonFlags(cmd)
+	cmd.Flags().Bool("systemd", false, "Print a systemd user service (default except on macOS)")
+	cmd.Flags().Bool("launchd", false, "Print a launchd agent (default on macOS)")
+	cmd.MarkFlagsMutuallyExclusive("systemd", "launchd")
+
+	return cmd
+}
+
+func runDaemon(cmd *cobra.Command, args []string) {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	once, _ := cmd.Flags().GetBool("once")
+	if interval < time.Minute {
+		ui.Error("✗ --interval must be at least 1m")
+		return
+	}
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	// Stop between passes, or abort the rebase in progress, when interrupted
+	done := make(chan struct{})
+	var closeDone sync.Once
+	var running atomic.Pointer[fanout.Engine]
+	stop := proc.OnInterrupt(func() {
+		closeDone.Do(func() { close(done) })
+		if engine := running.Load(); engine != nil {
+			engine.Interrupt()
+		}
+	})
+	defer stop()
+
+	for {
+		report := daemonPass(cmd, manager, currentDir, &running)
+		report.Next = report.At.Add(interval)
+		if err := manager.SaveSyncReport(report); err != nil {
+			ui.Errorf("✗ Failed to write the sync report: %v", err)
+		}
+		printSyncReport(report)
+		if once {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Until(report.Next)):
+		}
+	}
+}
+
+// daemonPass fetches the base, finds the sessions behind it and, with
+// --fanout, rebases those that are safe to. The engine rebasing them is
+// published in running so that an interrupt can abort it.
+func daemonPass(cmd *cobra.Command, manager *session.Manager, dir string, running *atomic.Pointer[fanout.Engine]) session.SyncReport {
+	report := session.SyncReport{At: time.Now()}
+
+	base, _ := cmd.Flags().GetString("base")
+	if base == "" {
+		var err error
+		if base, err = manager.GetCurrentBranch(); err != nil || base == "" {
+			report.Errors = append(report.Errors, "failed to get the current branch of the main repository")
+			return report
+		}
+	}
+
+	// Compare to the remote branch the base tracks, freshly fetched
+	report.Base = base
+	if upstream, err := git.GetUpstream(dir, base); err == nil {
+		report.Base = upstream.String()
+		if err := git.Fetch(dir, upstream.Remote, nil); err != nil {
+			report.FetchError = err.Error()
+		}
+	}
+
+	behind, err := manager.BehindSessions(report.Base)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+
+	if fanoutEnabled, _ := cmd.Flags().GetBool("fanout"); fanoutEnabled {
+		rebased, errs := daemonFanout(cmd, manager, report.Base, behind, running)
+		report.Rebased, report.Errors = rebased, append(report.Errors, errs...)
+		if len(rebased) > 0 {
+			if behind, err = manager.BehindSessions(report.Base); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		}
+	}
+	report.Behind = behind
+	return report
+}
+
+// daemonFanout rebases the sessions of behind that are clean, not ahead of
+// base and not protected onto base. It returns the names of the sessions it
+// rebased and what went wrong.
+func daemonFanout(cmd *cobra.Command, manager *session.Manager, base string, behind []session.BehindSession, running *atomic.Pointer[fanout.Engine]) ([]string, []string) {
+	cfg := manager.Config()
+	names := make(map[string]string)
+	var targets []git.Worktree
+	for _, s := range behind {
+		if s.Dirty || s.Ahead > 0 || cfg.IsProtectedBranch(s.Branch) {
+			continue
+		}
+		names[s.Branch] = s.Name
+		targets = append(targets, git.Worktree{Path: s.Path, Branch: s.Branch})
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	// Leave the repository to interactive commands rather than wait
+	lock, err := manager.Lock(cmd.CommandPath())
+	if err != nil {
+		return nil, []string{fmt.Sprintf("skipped fanout: %v", err)}
+	}
+	defer lock.Release()
+
+	engine := fanout.New(base, nil)
+	engine.Sign(cfg.Git.SignCommits)
+	engine.Verify(cfg.Git.VerifyCommand)
+
+	// Check again under the lock: sessions may have changed since
+	var safe []git.Worktree
+	for _, check := range engine.Check(targets) {
+		if check.Safe() {
+			safe = append(safe, check.Worktree)
+		}
+	}
+
+	// Rebase sessions one at a time, so a conflict in one does not hold
+	// back the others as it would stop a fanout
+	running.Store(engine)
+	defer running.Store(nil)
+	var rebased, errs []string
+	for _, wt := range safe {
+		if engine.Interrupted() {
+			break
+		}
+		r := engine.Run([]git.Worktree{wt})[0]
+		manager.Record(schema.OpRebase, names[wt.Branch], []string{wt.Branch}, r.Err)
+		switch r.Status {
+		case fanout.StatusSucceeded:
+			rebased = append(rebased, names[wt.Branch])
+		default:
+			errs = append(errs, fmt.Sprintf("%s: %s, left as it was", names[wt.Branch], r.Status))
+		}
+	}
+	return rebased, errs
+}
+
+// printSyncReport prints one line per finding of a daemon pass
+func printSyncReport(report session.SyncReport) {
+	now := report.At.Format("15:04:05")
+	if report.FetchError != "" {
+		ui.Warningf("%s ⚠ %s", now, report.FetchError)
+	}
+	for _, e := range report.Errors {
+		ui.Errorf("%s ✗ %s", now, e)
+	}
+	if len(report.Rebased) > 0 {
+		ui.Successf("%s ✓ Rebased onto %s: %s", now, report.Base, strings.Join(report.Rebased, ", "))
+	}
+	if len(report.Behind) == 0 {
+		fmt.Printf("%s all sessions are up to date with %s\n", now, report.Base)
+		return
+	}
+	fmt.Printf("%s %d session(s) behind %s: %s\n", now, len(report.Behind), report.Base, behindSummary(report.Behind))
+}
+
+// behindSummary lists sessions with how far behind they are
+func behindSummary(behind []session.BehindSession) string {
+	parts := make([]string, len(behind))
+	for i, s := range behind {
+		parts[i] = fmt.Sprintf("%s (↓%d)", s.Name, s.Behind)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printDaemonUnit(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	repoPath, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		ui.Errorf("✗ Failed to find the ccswitch executable: %v", err)
+		return
+	}
+
+	command := []string{executable, "--repo", repoPath, "daemon"}
+	for _, name := range []string{"interval", "base", "fanout"} {
+		if flag := cmd.Flags().Lookup(name); flag.Changed {
+			command = append(command, "--"+name+"="+flag.Value.String())
+		}
+	}
+
+	launchd, _ := cmd.Flags().GetBool("launchd")
+	systemd, _ := cmd.Flags().GetBool("systemd")
+	if launchd || (!systemd && runtime.GOOS == "darwin") {
+		repoName, err := git.GetRepoName(repoPath)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		fmt.Print(launchdAgent(repoName, command))
+	} else {
+		fmt.Print(systemdService(repoPath, command))
+	}
+}
+
+// systemdService returns a systemd user service running command
+func systemdService(repoPath string, command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = systemdQuote(arg)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=ccswitch daemon for %s
+
+[Service]
+ExecStart=%s
+Restart=on-failure
+RestartSec=1min
+
+[Install]
+WantedBy=default.target
+`, repoPath, strings.Join(quoted, " "))
+}
+
+// systemdQuote quotes arg for an ExecStart line if it needs to be
+func systemdQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"'\\%$") {
+		return arg
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	return `"` + r.Replace(arg) + `"`
+}
+
+// launchdAgent returns a launchd agent keeping command running
+func launchdAgent(repoName string, command []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>com.ccswitch.`)
+	b.WriteString(xmlEscape(repoName))
+	b.WriteString(`</string>
+  <key>ProgramArguments</key>
+  <array>
+`)
+	for _, arg := range command {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString(`  </array>
+  <key>RunAtLoad</key>
+  <true/>
+  <key>KeepAlive</key>
+  <dict>
+    <key>SuccessfulExit</key>
+    <false/>
+  </dict>
+</dict>
+</plist>
+`)
+	return b.String()
+}
+
+// xmlEscape escapes s for XML text
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [session]",
+		Short: "Show what a session changed relative to the current branch",
+		Long: `Show what a session has changed relative to the current branch.
+
+By default a summary of files changed, insertions and deletions is shown.
+Changes are measured from the merge base, so commits added to the current
+branch since the session was created are not counted.
+
+Examples:
+  ccswitch diff                      # Select a session interactively
+  ccswitch diff my-feature           # Summary of committed changes
+  ccswitch diff my-feature --stat    # Per-file summary
+  ccswitch diff my-feature --patch   # Full patch
+  ccswitch diff my-feature --stat --json --working-tree`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               diffSession,
+	}
+
+	cmd.Flags().Bool("patch", false, "Show the full patch")
+	cmd.Flags().Bool("stat", false, "Show per-file changes")
+	cmd.Flags().Bool("json", false, "Output the per-file summary as JSON")
+	cmd.Flags().Bool("working-tree", false, "Include uncommitted changes to tracked files")
+	cmd.Flags().String("base", "", "Branch to compare against (default: current branch)")
+	_ = cmd.RegisterFlagCompletionFunc("base", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return localBranches(cmd), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func diffSession(cmd *cobra.Command, args []string) {
+	showPatch, _ := cmd.Flags().GetBool("patch")
+	showStat, _ := cmd.Flags().GetBool("stat")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	workingTree, _ := cmd.Flags().GetBool("working-tree")
+	base, _ := cmd.Flags().GetString("base")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	if base == "" {
+		base, err = manager.GetCurrentBranch()
+		if err != nil {
+			ui.Errorf("✗ Failed to get current branch: %v", err)
+			return
+		}
+	}
+
+	selected := resolveSession(cmd, manager, args, "Select session to diff:")
+	if selected == nil {
+		return
+	}
+
+	if showPatch {
+		if err := git.WriteDiffPatch(selected.Path, base, workingTree, os.Stdout); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	stat, err := git.GetDiffStat(selected.Path, base, workingTree)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	if asJSON {
+		out := schema.Diff{
+			Header:      schema.NewHeader(),
+			Session:     selected.Name,
+			Branch:      selected.Branch,
+			Base:        base,
+			WorkingTree: workingTree,
+			Files:       []schema.DiffFile{},
+			Insertions:  stat.Insertions,
+			Deletions:   stat.Deletions,
+		}
+		for _, f := range stat.Files {
+			out.Files = append(out.Files, schema.DiffFile(f))
+		}
+		if err := schema.Write(os.Stdout, out); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	ui.Titlef("Changes in %s (%s) relative to %s", selected.Name, selected.BranchLabel(), base)
+	if showStat {
+		fmt.Println()
+		for _, f := range stat.Files {
+			if f.Binary {
+				fmt.Printf("  %-50s binary\n", f.Path)
+				continue
+			}
+			fmt.Printf("  %-50s +%d -%d\n", f.Path, f.Insertions, f.Deletions)
+		}
+		fmt.Println()
+	}
+
+	if len(stat.Files) == 0 {
+		ui.Info("No changes")
+		return
+	}
+	ui.Infof("%d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)", len(stat.Files), stat.Insertions, stat.Deletions)
+}
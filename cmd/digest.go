@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newDigestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Summarize recent session activity as Markdown or HTML",
+		Long: `Compose a summary of session activity over a recent time window.
+
+The digest lists new sessions, commits made in each session, stale sessions
+with no recent activity, and sessions that would conflict with the current
+branch. Output is plain Markdown or HTML with no colors, so it can be piped
+into email or chat tools from cron.
+
+Examples:
+  ccswitch digest                           # Markdown digest of the last day
+  ccswitch digest --since 7d --stale 14d    # Weekly digest
+  ccswitch digest --format html -o out.html # HTML digest written to a file`,
+		Run: showDigest,
+	}
+
+	cmd.Flags().String("since", "24h", "Time window to summarize (e.g. 24h, 7d)")
+	cmd.Flags().String("stale", "7d", "Inactivity after which a session is considered stale")
+	cmd.Flags().String("format", "markdown", "Output format: markdown or html")
+	cmd.Flags().StringP("output", "o", "", "Write the digest to a file instead of stdout")
+	_ = cmd.RegisterFlagCompletionFunc("format", fixedCompletion("markdown", "html"))
+
+	return cmd
+}
+
+func showDigest(cmd *cobra.Command, args []string) {
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	staleFlag, _ := cmd.Flags().GetString("stale")
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	window, err := utils.ParseDuration(sinceFlag)
+	if err != nil {
+		ui.Errorf("✗ Invalid --since: %v", err)
+		return
+	}
+	staleAfter, err := utils.ParseDuration(staleFlag)
+	if err != nil {
+		ui.Errorf("✗ Invalid --stale: %v", err)
+		return
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	digest, err := manager.BuildDigest(time.Now().Add(-window), staleAfter)
+	if err != nil {
+		ui.Errorf("✗ Failed to build digest: %v", err)
+		return
+	}
+
+	var rendered string
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		rendered = renderDigestMarkdown(digest)
+	case "html":
+		rendered, err = renderDigestHTML(digest)
+		if err != nil {
+			ui.Errorf("✗ Failed to render digest: %v", err)
+			return
+		}
+	default:
+		ui.Errorf("✗ Unknown format: %s (expected markdown or html)", format)
+		return
+	}
+
+	if output == "" {
+		fmt.Print(rendered)
+		return
+	}
+
+	if err := os.WriteFile(output, []byte(rendered), 0600); err != nil {
+		ui.Errorf("✗ Failed to write digest: %v", err)
+		return
+	}
+	ui.Successf("✓ Digest written to %s", output)
+}
+
+func renderDigestMarkdown(d *session.Digest) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# ccswitch digest: %s\n\n", d.Repo)
+	fmt.Fprintf(&b, "_%s – %s, base branch `%s`_\n\n",
+		utils.FormatTimestamp(d.Since), utils.FormatTimestamp(d.GeneratedAt), d.Base)
+	fmt.Fprintf(&b, "**%d** session(s), **%d** new, **%d** commit(s), **%d** stale, **%d** with conflicts\n\n",
+		len(d.Sessions), len(d.NewSessions()), d.TotalCommits(), len(d.StaleSessions()), len(d.ConflictedSessions()))
+
+	b.WriteString("## New sessions\n\n")
+	writeMarkdownList(&b, d.NewSessions(), func(s session.DigestSession) string {
+		return fmt.Sprintf("`%s` (%s), created %s", s.Name, s.Branch, utils.FormatTimestamp(s.CreatedAt))
+	})
+
+	b.WriteString("## Commits\n\n")
+	active := d.ActiveSessions()
+	if len(active) == 0 {
+		b.WriteString("_None_\n\n")
+	}
+	for _, s := range active {
+		fmt.Fprintf(&b, "### %s (%s)\n\n", s.Name, s.Branch)
+		for _, c := range s.Commits {
+			fmt.Fprintf(&b, "- `%s` %s — %s\n", shortHash(c.Hash), c.Subject, c.Author)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Stale sessions\n\n")
+	writeMarkdownList(&b, d.StaleSessions(), func(s session.DigestSession) string {
+		return fmt.Sprintf("`%s` (%s), last activity %s", s.Name, s.Branch, s.LastActivity.Local().Format("2006-01-02"))
+	})
+
+	b.WriteString("## Conflicts with base\n\n")
+	writeMarkdownList(&b, d.ConflictedSessions(), func(s session.DigestSession) string {
+		return fmt.Sprintf("`%s` (%s) would conflict with `%s`", s.Name, s.Branch, d.Base)
+	})
+
+	return b.String()
+}
+
+func writeMarkdownList(b *strings.Builder, sessions []session.DigestSession, line func(session.DigestSession) string) {
+	if len(sessions) == 0 {
+		b.WriteString("_None_\n\n")
+		return
+	}
+	for _, s := range sessions {
+		b.WriteString("- " + line(s) + "\n")
+	}
+	b.WriteString("\n")
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"short": shortHash,
+	"when":  utils.FormatTimestamp,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>ccswitch digest: {{.Repo}}</title></head>
+<body>
+<h1>ccswitch digest: {{.Repo}}</h1>
+<p><em>{{when .Since}} – {{when .GeneratedAt}}, base branch <code>{{.Base}}</code></em></p>
+<p><strong>{{len .Sessions}}</strong> session(s), <strong>{{len .NewSessions}}</strong> new,
+<strong>{{.TotalCommits}}</strong> commit(s), <strong>{{len .StaleSessions}}</strong> stale,
+<strong>{{len .ConflictedSessions}}</strong> with conflicts</p>
+<h2>New sessions</h2>
+{{with .NewSessions}}<ul>{{range .}}<li><code>{{.Name}}</code> ({{.Branch}}), created {{when .CreatedAt}}</li>{{end}}</ul>{{else}}<p><em>None</em></p>{{end}}
+<h2>Commits</h2>
+{{range .ActiveSessions}}<h3>{{.Name}} ({{.Branch}})</h3>
+<ul>{{range .Commits}}<li><code>{{short .Hash}}</code> {{.Subject}} — {{.Author}}</li>{{end}}</ul>
+{{else}}<p><em>None</em></p>{{end}}
+<h2>Stale sessions</h2>
+{{with .StaleSessions}}<ul>{{range .}}<li><code>{{.Name}}</code> ({{.Branch}}), last activity {{when .LastActivity}}</li>{{end}}</ul>{{else}}<p><em>None</em></p>{{end}}
+<h2>Conflicts with base</h2>
+{{with .ConflictedSessions}}<ul>{{range .}}<li><code>{{.Name}}</code> ({{.Branch}})</li>{{end}}</ul>{{else}}<p><em>None</em></p>{{end}}
+</body>
+</html>
+`))
+
+func renderDigestHTML(d *session.Digest) (string, error) {
+	var b strings.Builder
+	if err := digestHTMLTemplate.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
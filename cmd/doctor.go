@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ksred/ccswitch/internal/doctor"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and fix worktrees out of sync with ccswitch",
+		Long: `Check that the environment ccswitch runs in is usable, and reconcile git's
+worktree registrations, the worktree root on disk and the session metadata,
+for example after a worktree directory was deleted by hand.
+
+Environment checks:
+  git version        git is installed and new enough
+  worktree support   git worktree works in this repository
+  PATH               git, and on Windows ccswitch itself, can be run by name
+  long paths         core.longpaths is enabled (Windows only)
+  worktree root      worktrees can be created in the worktree root
+  config files       configuration files parse and only use known settings
+  hooks              git hook scripts are executable
+
+Worktree problems:
+  missing-dir      A worktree git lists whose directory no longer exists
+                   (fixed with git worktree prune)
+  locked           A worktree locked with git worktree lock (unlocked)
+  stale-metadata   A session git no longer has a worktree for (forgotten)
+  orphan-dir       A directory in the worktree root that is not a worktree
+                   (deleted, so it is only selected if you check it)
+
+Each problem comes with a suggestion for fixing it. You review worktree
+problems in a checklist and choose which ones to fix; with --fix, every
+problem that can be fixed automatically is, without asking, except that
+orphan directories are left alone.
+
+Examples:
+  ccswitch doctor             # Check, then review and fix worktree problems
+  ccswitch doctor --dry-run   # Only report problems
+  ccswitch doctor --fix       # Fix what can be fixed without asking`,
+		Args: cobra.NoArgs,
+		Run:  runDoctor,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Only report problems")
+	cmd.Flags().Bool("fix", false, "Fix every problem that can be fixed automatically without asking")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	fix, _ := cmd.Flags().GetBool("fix")
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	repoRoot, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		repoRoot = currentDir
+	}
+	checkEnvironment(repoRoot, manager, fix && !dryRun)
+	fmt.Println()
+
+	problems, err := manager.Diagnose()
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if len(problems) == 0 {
+		ui.Success("✓ Worktrees and sessions are in sync")
+		return
+	}
+
+	ui.Titlef("🩺 Found %d worktree problem(s):", len(problems))
+	fmt.Println()
+	for _, p := range problems {
+		ui.Errorf("✗ %s: %s", p.Name, p.Detail)
+		fmt.Printf("    %s\n", abbreviateHome(p.Path))
+	}
+	fmt.Println()
+
+	if dryRun {
+		return
+	}
+
+	items := make([]ui.ChecklistItem, len(problems))
+	selected := make([]bool, len(problems))
+	for i, p := range problems {
+		// Deleting a directory may lose work, so it must be chosen
+		selected[i] = p.Kind != session.ProblemOrphanDir
+		items[i] = ui.ChecklistItem{
+			Label:   fmt.Sprintf("%s: %s", p.Name, p.Fix()),
+			Note:    abbreviateHome(p.Path),
+			Checked: selected[i],
+		}
+	}
+	if !fix {
+		selected, err = ui.Checklist("Select the problems to fix:", items, noTUI)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			return
+		}
+		if selected == nil {
+			ui.Info("Nothing fixed")
+			return
+		}
+	}
+
+	fixed, failed := 0, 0
+	for i, p := range problems {
+		if !selected[i] {
+			continue
+		}
+		if err := manager.Fix(p); err != nil {
+			ui.Errorf("✗ Failed to fix %s: %v", p.Name, err)
+			failed++
+			continue
+		}
+		ui.Successf("✓ %s: %s", p.Name, p.Fix())
+		fixed++
+	}
+
+	if fixed+failed == 0 {
+		ui.Info("Nothing fixed")
+	} else if failed > 0 {
+		ui.Infof("Fixed %d of %d problem(s)", fixed, fixed+failed)
+	}
+}
+
+// checkEnvironment reports the environment checks, applying the available
+// fixes if fix is set
+func checkEnvironment(repoRoot string, manager *session.Manager, fix bool) {
+	ui.Title("🩺 Environment")
+	fmt.Println()
+
+	fixable := 0
+	for _, check := range doctor.Run(repoRoot, manager.Config()) {
+		switch check.Status {
+		case doctor.OK:
+			ui.Successf("✓ %s: %s", check.Name, check.Message)
+			continue
+		case doctor.Warning:
+			ui.Warningf("⚠ %s: %s", check.Name, check.Message)
+		case doctor.Failed:
+			ui.Errorf("✗ %s: %s", check.Name, check.Message)
+		}
+
+		if check.Fix == nil {
+			ui.Infof("  Tip: %s", check.Suggestion)
+			continue
+		}
+		if !fix {
+			ui.Infof("  Tip: %s", check.Suggestion)
+			fixable++
+			continue
+		}
+		if err := check.Fix(); err != nil {
+			ui.Errorf("  ✗ Failed to fix %s: %v", check.Name, err)
+			ui.Infof("  Tip: %s", check.Suggestion)
+		} else {
+			ui.Successf("  ✓ Fixed %s", check.Name)
+		}
+	}
+
+	if fixable > 0 {
+		fmt.Println()
+		ui.Infof("%d problem(s) can be fixed with: ccswitch doctor --fix", fixable)
+	}
+}
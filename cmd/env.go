@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newEnvCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env [session]",
+		Short: "Show or set the ports and variables of a session",
+		Long: `Show the variables work, exec and agents spawn set for a session's
+commands, so that dev servers in different sessions don't fight over ports
+or databases.
+
+With env.port_base set, each session gets its own block of ports, recorded
+with the session: CCSWITCH_PORT is the first and CCSWITCH_PORT_END the last.
+env.vars sets variables for every session, filling in {port}, {port+N},
+{session} and {branch}:
+
+  env:
+    port_base: 3000     # Sessions get 3000-3009, 3010-3019, ...
+    port_block: 10      # Ports per session (default 10)
+    vars:
+      PORT: "{port}"
+      API_PORT: "{port+1}"
+      DATABASE_URL: "postgres://localhost/app_{session}"
+    file: true          # Also write them to .env.ccswitch in each worktree
+
+--set and --unset change variables of one session alone, overriding
+env.vars. --write writes the variables to .env.ccswitch in the worktree,
+which git is told to ignore; with env.file it is written when sessions are
+created. --export prints them for a shell to eval.
+
+Without a session, the session is picked from a list.
+
+Examples:
+  ccswitch env fix-login
+  ccswitch env fix-login --set DATABASE_URL=postgres://localhost/login
+  ccswitch env fix-login --unset DATABASE_URL
+  ccswitch env fix-login --write
+  eval "$(ccswitch env fix-login --export)"`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               showEnv,
+	}
+
+	cmd.Flags().StringArray("set", nil, "Set NAME=VALUE for the session's commands (repeatable)")
+	cmd.Flags().StringArray("unset", nil, "Remove a variable set with --set (repeatable)")
+	cmd.Flags().Bool("write", false, "Write the variables to "+session.EnvFile+" in the worktree")
+	cmd.Flags().Bool("export", false, "Print the variables as shell export statements")
+
+	return cmd
+}
+
+func showEnv(cmd *cobra.Command, args []string) {
+	set, _ := cmd.Flags().GetStringArray("set")
+	unset, _ := cmd.Flags().GetStringArray("unset")
+	write, _ := cmd.Flags().GetBool("write")
+	export, _ := cmd.Flags().GetBool("export")
+
+	vars := make(map[string]string, len(set))
+	for _, kv := range set {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			ui.Errorf("✗ Invalid --set %q: expected NAME=VALUE", kv)
+			return
+		}
+		vars[name] = value
+	}
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to show the variables of:")
+	if selected == nil {
+		return
+	}
+
+	if len(vars) > 0 || len(unset) > 0 {
+		if err := manager.SetSessionEnv(*selected, vars, unset); err != nil {
+			ui.Errorf("✗ Failed to set variables: %v", err)
+			return
+		}
+	}
+
+	env, err := manager.SessionVars(*selected)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		ui.Info("  Tip: Raise env.port_base, or delete sessions you no longer need")
+		return
+	}
+
+	if write {
+		path, err := manager.WriteEnvFile(*selected)
+		if err != nil {
+			ui.Errorf("✗ Failed to write %s: %v", session.EnvFile, err)
+			return
+		}
+		ui.Successf("✓ Wrote %s", abbreviateHome(path))
+	}
+
+	if len(env) == 0 {
+		ui.Infof("No variables for %s", selected.Name)
+		ui.Info("  Tip: Set env.port_base or env.vars in the config, or pass --set")
+		return
+	}
+	for _, kv := range env {
+		if export {
+			name, value, _ := strings.Cut(kv, "=")
+			fmt.Printf("export %s=%s\n", name, shellQuote(value))
+			continue
+		}
+		fmt.Println(kv)
+	}
+}
+
+// shellQuote quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellWord returns value as one word for POSIX shells, quoting it only if
+// it holds characters the shell would split or interpret, such as spaces
+func shellWord(value string) string {
+	if value != "" && !strings.ContainsFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+=%", r))
+	}) {
+		return value
+	}
+	return shellQuote(value)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newExecCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exec <session> -- <command> [args...]",
+		Short: "Run a command in a named session without prompting",
+		Long: `Run a command in a named session's worktree without any prompts or
+output of its own, for use in scripts and Makefiles.
+
+The session is matched by session name or branch. The command's exit code
+becomes ccswitch's exit code; if the session does not exist ccswitch exits
+with 1, and with 127 if the command cannot be found. As with work, the
+command sees CCSWITCH_SESSION, CCSWITCH_BRANCH, CCSWITCH_WORKTREE and
+CCSWITCH_BASE_BRANCH describing the session, and its ports and variables
+(see 'ccswitch env').
+
+The command runs in its own process group and is stopped with
+everything it started if ccswitch is interrupted.
+
+Examples:
+  ccswitch exec auth -- make test
+  ccswitch exec feature/auth -- go test ./...
+  ccswitch exec auth -- sh -c 'git log --oneline | head'`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeExec,
+		Run:               execInSession,
+	}
+}
+
+func execInSession(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		os.Exit(1)
+	}
+
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		os.Exit(1)
+	}
+
+	_ = manager.MarkUsed(*selected)
+	env := manager.Env(os.Environ(), *selected)
+	if err := executeInDir(selected.Path, args[1], args[2:], env); err != nil {
+		code := proc.ExitCode(err)
+		if code == 127 {
+			ui.Errorf("✗ %v", err)
+		}
+		os.Exit(code)
+	}
+}
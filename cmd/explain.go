@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// explanation is the long-form account of a conflict or failed safety check
+// printed with --explain, for users who are new to worktrees
+type explanation struct {
+	// What describes what happened
+	What string
+	// State describes the state the worktree and branches are left in
+	State string
+	// Recover lists the commands that get the user going again, in order.
+	// Lines starting with "#" are printed as comments.
+	Recover []string
+}
+
+// printExplanation prints e if --explain is set
+func printExplanation(cmd *cobra.Command, e explanation) {
+	if explain, _ := cmd.Flags().GetBool("explain"); !explain {
+		return
+	}
+
+	gray := color.New(color.FgHiBlack)
+
+	fmt.Println()
+	ui.Title("What happened")
+	fmt.Println(indentText(e.What))
+	fmt.Println()
+	ui.Title("Where things are now")
+	fmt.Println(indentText(e.State))
+	fmt.Println()
+	ui.Title("How to recover")
+	for _, line := range e.Recover {
+		if strings.HasPrefix(line, "#") {
+			gray.Printf("  %s\n", line)
+		} else {
+			fmt.Printf("  $ %s\n", line)
+		}
+	}
+	fmt.Println()
+}
+
+// indentText indents every line of text by two spaces
+func indentText(text string) string {
+	return "  " + strings.ReplaceAll(text, "\n", "\n  ")
+}
+
+// conflictPreviewLines is how much of the conflicting hunks printConflict
+// shows
+const conflictPreviewLines = 40
+
+// printConflict shows what conflicted in the rebase or cherry-pick err
+// reports: the commit that failed to apply, the conflicting files and a
+// preview of the conflicting hunks
+func printConflict(err error) {
+	conflict := git.Conflict(err)
+	if conflict == nil || ui.IsQuiet() {
+		return
+	}
+
+	gray := color.New(color.FgHiBlack)
+
+	if conflict.Commit != "" {
+		ui.Infof("  Stopped at %s", conflict.Commit)
+	}
+	if len(conflict.Files) > 0 {
+		ui.Info("  Conflicting files:")
+		for _, file := range conflict.Files {
+			ui.Warningf("    %s", file)
+		}
+	}
+	if conflict.Diff != "" {
+		fmt.Println()
+		for _, line := range strings.Split(utils.FirstLines(conflict.Diff, conflictPreviewLines), "\n") {
+			gray.Printf("    %s\n", line)
+		}
+	}
+}
+
+// rebaseConflictExplanation explains a rebase of branch in dir that stopped
+// on a conflict and was aborted. rebaseArgs are the arguments to git rebase
+// that reproduce it.
+func rebaseConflictExplanation(dir, branch, rebaseArgs, rerun string) explanation {
+	return explanation{
+		What: fmt.Sprintf(`Rebasing replays the commits of %s one by one on top of another commit.
+One of them changed the same lines as a commit it was replayed onto, so git
+could not decide which version to keep.`, branch),
+		State: fmt.Sprintf(`ccswitch aborted the rebase, so %s is exactly as it was before and no work
+was lost. The worktree at %s is not in the middle of a rebase.`, branch, dir),
+		Recover: withRerun([]string{
+			"# Redo the rebase by hand and resolve the conflicts as they come up",
+			"cd " + shellWord(dir),
+			"git rebase " + rebaseArgs,
+			"git status                    # lists the conflicted files",
+			"# Edit each file, keeping the right parts between <<<<<<< and >>>>>>>",
+			"git add <file>",
+			"git rebase --continue         # repeat until the rebase finishes",
+			"# To give up and go back to where you started",
+			"git rebase --abort",
+		}, rerun),
+	}
+}
+
+// cherryPickConflictExplanation explains a cherry-pick from the worktree at
+// worktreeDir onto branch in dir that stopped on a conflict and was aborted.
+// logArgs are the arguments to git log that list the picked commits.
+func cherryPickConflictExplanation(dir, branch, worktreeDir, logArgs string) explanation {
+	return explanation{
+		What: fmt.Sprintf(`Cherry-picking copies the chosen commits one by one onto %s. One of them
+changed the same lines as a commit already on %s, so git could not decide
+which version to keep.`, branch, branch),
+		State: fmt.Sprintf(`ccswitch aborted the cherry-pick, so %s is exactly as it was before. The
+worktree at %s was not touched.`, branch, worktreeDir),
+		Recover: []string{
+			"# List the commits that were selected",
+			fmt.Sprintf("git -C %s log --oneline --reverse %s", worktreeDir, logArgs),
+			"# Cherry-pick them by hand and resolve the conflicts as they come up",
+			"cd " + shellWord(dir),
+			"git cherry-pick <commit>...",
+			"git status                    # lists the conflicted files",
+			"git add <file>",
+			"git cherry-pick --continue    # repeat until it finishes",
+			"# To give up and go back to where you started",
+			"git cherry-pick --abort",
+		},
+	}
+}
+
+// withRerun appends the ccswitch command to run once the problem is fixed
+func withRerun(steps []string, rerun string) []string {
+	if rerun == "" {
+		return steps
+	}
+	return append(steps, "# Then carry on with", rerun)
+}
+
+// uncommittedExplanation explains why a worktree with uncommitted changes
+// was not touched
+func uncommittedExplanation(dir, branch, rerun string) explanation {
+	return explanation{
+		What: fmt.Sprintf(`The worktree for %s has changes that are not committed. Rebasing rewrites
+the files in a worktree, so ccswitch refuses to rebase it rather than risk
+losing those changes.`, branch),
+		State: fmt.Sprintf(`Nothing was changed. The uncommitted changes are still in %s.`, dir),
+		Recover: withRerun([]string{
+			"cd " + shellWord(dir),
+			"git status                    # see what is uncommitted",
+			"# Either commit the changes",
+			`git add -A && git commit -m "Work in progress"`,
+			"# or set them aside and bring them back later with 'git stash pop'",
+			"git stash",
+		}, rerun),
+	}
+}
+
+// aheadExplanation explains why fanout refuses a branch with commits that
+// are not on the source branch
+func aheadExplanation(dir, branch, source string, ahead int) explanation {
+	return explanation{
+		What: fmt.Sprintf(`%s has %d commit(s) that %s does not have. Fanout only moves branches
+forward to %s, and rebasing this one would mix its own work into the
+result, so it was left alone.`, branch, ahead, source, source),
+		State: "Nothing was changed. No worktree was rebased.",
+		Recover: []string{
+			"# See the commits only this branch has",
+			fmt.Sprintf("git -C %s log --oneline %s..%s", dir, source, branch),
+			"# Bring them into the source branch first, e.g. with",
+			fmt.Sprintf("ccswitch rebase %s", branch),
+			"# or, if the branch is stacked on another session",
+			"ccswitch fanout --stack",
+		},
+	}
+}
+
+// protectedExplanation explains why ccswitch refused to rewrite a protected
+// branch
+func protectedExplanation(branch string) explanation {
+	return explanation{
+		What: fmt.Sprintf(`%s matches git.protected_branches in ~/.ccswitch/config.yaml. Rewriting
+a shared branch changes history other people have already pulled.`, branch),
+		State: "Nothing was changed.",
+		Recover: []string{
+			"# Check which branches are protected",
+			"ccswitch config",
+			"# If you really mean to rewrite it, repeat the command with --force and",
+			"# type the branch name when asked",
+		},
+	}
+}
+
+// lockedExplanation explains why a command could not take the repository lock
+func lockedExplanation() explanation {
+	return explanation{
+		What: `Another ccswitch command is changing worktrees in this repository. Running
+two at once can leave worktrees half-rebased, so only one may run at a time.`,
+		State: "Nothing was changed.",
+		Recover: []string{
+			"# Wait for the other command to finish and try again, or repeat the",
+			"# command with --wait to start as soon as the lock is free",
+			"# A lock left by a crashed command is cleared once its process is gone",
+		},
+	}
+}
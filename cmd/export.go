@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [session...]",
+		Short: "Write session definitions to a YAML file to share them",
+		Long: `Describe sessions in YAML so a teammate can recreate them in their clone of
+the repository with 'ccswitch import <file>'.
+
+Each session is written with its branch (or the tag or commit of a detached
+session), the branch it builds on, its stack parent, issue, link, tags,
+variables set with 'ccswitch env --set' and notes. Working trees are not
+exported: push the branches for others to get their commits. Personal
+settings, such as the identity a session commits as, are left out.
+
+Without sessions, all of them are exported. The YAML goes to standard output
+unless --output names a file.
+
+Examples:
+  ccswitch export > sessions.yaml
+  ccswitch export auth billing -o sessions.yaml
+  ccswitch import sessions.yaml       # On the teammate's machine`,
+		ValidArgsFunction: completeSession,
+		Run:               exportSessions,
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Write the YAML to this file instead of standard output")
+	_ = cmd.MarkFlagFilename("output", "yaml", "yml")
+
+	return cmd
+}
+
+func exportSessions(cmd *cobra.Command, args []string) {
+	output, _ := cmd.Flags().GetString("output")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+	if len(args) > 0 {
+		var selected []git.SessionInfo
+		for _, name := range args {
+			s := findSession(sessions, name)
+			if s == nil {
+				ui.Errorf("✗ Session '%s' not found", name)
+				return
+			}
+			selected = append(selected, *s)
+		}
+		sessions = selected
+	}
+
+	set := manager.ExportSessions(sessions)
+	if len(set.Sessions) == 0 {
+		ui.Info("No sessions to export")
+		return
+	}
+	data, err := session.MarshalSessionSet(set)
+	if err != nil {
+		ui.Errorf("✗ Failed to encode the sessions: %v", err)
+		return
+	}
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(expandHome(output), data, 0644); err != nil {
+		ui.Errorf("✗ Failed to write %s: %v", output, err)
+		return
+	}
+	ui.Successf("✓ Exported %d session(s) to %s", len(set.Sessions), output)
+}
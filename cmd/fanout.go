@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/fanout"
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/schema"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
@@ -24,27 +27,114 @@ This is useful for synchronizing all feature branches with a core business branc
 Safety checks before fanout:
   1. No other worktree has uncommitted changes
   2. No other worktree is ahead of current branch
-  3. Auto-abort on any conflict
+  3. No target is a protected branch (git.protected_branches) unless --force
+  4. Auto-abort on any conflict
+  5. With git.verify_command set, e.g. "make test", the command runs in
+     each worktree after it is rebased; if it fails, the branch is reset
+     to where it was (saved as refs/ccswitch/backup/<branch>) and the
+     fanout stops
+
+If worktrees fail the first two checks, you choose what to do in a
+checklist: worktrees with uncommitted changes are skipped, worktrees ahead
+of the current branch can be checked to rebase them anyway (their commits
+are replayed on top), and the rest proceed. --skip-unsafe skips every
+worktree that failed the checks without asking.
+
+Branches stacked on other branches (feature-b created from feature-a) are
+detected from their history and rebased after their parent. With --stack,
+each branch is rebased onto its parent instead of the current branch, and
+branches may have commits of their own.
+
+With --remote-branches, branches that exist only on the remote, such as a
+teammate's, are rebased too. The remote (origin unless named, as in
+--remote-branches=upstream) is fetched, each of its branches without a local
+branch gets a temporary worktree and branch (fanout/<remote>/<branch>), and
+those that rebase cleanly are force-pushed back with --force-with-lease,
+whether or not --push is given. The temporary worktrees and branches are
+removed afterwards. Protected branches are left out, and remote-only
+branches may be ahead of the current branch: their commits are replayed.
+
+With --select, you first choose the worktrees to fanout to from the session
+picker: all of them start checked, space toggles one, and the rest are left
+alone.
+
+Ctrl-C stops the fanout: the rebase in progress is aborted, leaving that
+worktree as it was, and the branches rebased so far are listed.
+
+With --porcelain, progress is printed as one JSON event per line for other
+tools to consume, and nothing is asked: unsafe worktrees are skipped as with
+--skip-unsafe. Each event has the worktree's branch, path and the branch it
+is rebased onto:
+  started        A worktree is being rebased
+  verifying      git.verify_command is checking the rebased worktree
+  rebased        It was rebased (and verified)
+  verify_failed  The verify command failed and the rebase was rolled back;
+                 fanout stops
+  conflicted     The rebase hit a conflict and was aborted; fanout stops.
+                 Its conflict has the commit that failed to apply, the
+                 conflicting files and hunks
+  failed         The rebase failed; fanout stops
+  skipped        It failed the safety checks, with the reason
+  interrupted    Ctrl-C aborted its rebase
+  done           The last event, counting worktrees by outcome
+Errors are printed on stderr.
+
+When the fanout finishes, the notifications section of the config can run
+a command and post a summary to a webhook, e.g. Slack's, so you know how a
+long fanout went without watching it:
+  notifications:
+    command: notify-send ccswitch "$CCSWITCH_SUMMARY"
+    webhook: https://hooks.slack.com/services/...
+The command gets the summary in CCSWITCH_SUMMARY, CCSWITCH_RESULT
+(succeeded, failed or interrupted) and CCSWITCH_OPERATION, and the JSON
+document posted to the webhook on stdin. Set these per repository in
+.ccswitch/config.yaml, or .ccswitch/config.local.yaml for webhook URLs.
 
 Examples:
-  ccswitch fanout    # Interactive confirmation and fanout`,
+  ccswitch fanout            # Interactive confirmation and fanout
+  ccswitch fanout --stack    # Rebase stacked branches onto their parents
+  ccswitch fanout --skip-unsafe  # Leave dirty and diverged worktrees alone
+  ccswitch fanout --select   # Choose the worktrees to fanout to
+  ccswitch fanout --push     # Force-push every rebased branch upstream
+  ccswitch fanout --remote-branches  # Include branches only on origin
+  ccswitch fanout --porcelain  # Stream progress as JSON events`,
 		Run: fanoutBranches,
 	}
 
+	cmd.Flags().Bool("stack", false, "Rebase each branch onto its parent branch instead of the current branch")
+	cmd.Flags().Bool("skip-unsafe", false, "Skip worktrees that fail safety checks instead of asking")
+	cmd.Flags().String("remote-branches", "", "Also rebase and force-push the branches of this remote without a local branch")
+	cmd.Flags().Lookup("remote-branches").NoOptDefVal = "origin"
+	cmd.Flags().Bool("select", false, "Choose the worktrees to fanout to in a list first")
+	addPorcelainFlag(cmd)
+	cmd.MarkFlagsMutuallyExclusive("select", "porcelain")
+	addPushFlag(cmd)
+	addSignFlag(cmd)
+	addForceFlag(cmd)
+	addWaitFlag(cmd)
+
 	return cmd
 }
 
 func fanoutBranches(cmd *cobra.Command, args []string) {
+	events := porcelainEvents(cmd, "fanout")
+
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
 
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
 	// Get current branch (source branch)
 	currentBranch, err := manager.GetCurrentBranch()
 	if err != nil {
@@ -60,23 +150,181 @@ func fanoutBranches(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	stack, _ := cmd.Flags().GetBool("stack")
+
+	observer := &cliFanoutObserver{cmd: cmd}
+	porcelainObserver := &porcelainFanoutObserver{events: events}
+	var engine *fanout.Engine
+	if events != nil {
+		engine = fanout.New(currentBranch, porcelainObserver)
+	} else {
+		engine = fanout.New(currentBranch, observer)
+	}
+	observer.engine, porcelainObserver.engine = engine, engine
+	engine.Sign(applySignFlag(cmd, manager))
+	engine.Verify(manager.Config().Git.VerifyCommand)
+
 	// Filter out current directory and find target worktrees
-	var targetWorktrees []git.Worktree
-	for _, wt := range worktrees {
-		// Skip current directory and empty branches
-		if wt.Path != currentDir && wt.Branch != "" && wt.Branch != currentBranch {
-			targetWorktrees = append(targetWorktrees, wt)
+	targetWorktrees := engine.Targets(worktrees, currentDir)
+
+	// Remote-only branches get temporary worktrees for the fanout
+	var remote *remoteTargets
+	if name, _ := cmd.Flags().GetString("remote-branches"); name != "" {
+		var ok bool
+		if remote, ok = addRemoteTargets(cmd, currentDir, name, currentBranch); !ok {
+			return
+		}
+		defer remote.cleanup()
+		targetWorktrees = append(targetWorktrees, remote.worktrees...)
+	}
+
+	if sel, _ := cmd.Flags().GetBool("select"); sel && len(targetWorktrees) > 0 {
+		if targetWorktrees = selectFanoutTargets(cmd, targetWorktrees, currentDir); len(targetWorktrees) == 0 {
+			return
 		}
 	}
 
 	if len(targetWorktrees) == 0 {
 		ui.Info("No other worktrees found to fanout to")
+		if events != nil {
+			events.done(nil)
+		}
+		return
+	}
+
+	// Rebase parents before the branches stacked on them
+	parents, err := engine.Parents(currentDir, targetWorktrees)
+	if err != nil {
+		ui.Errorf("✗ Failed to detect stacked branches: %v", err)
+		return
+	}
+	targetWorktrees = fanout.Order(targetWorktrees, parents)
+	if stack {
+		engine.Stack(parents)
+	}
+
+	checks := engine.Check(targetWorktrees)
+	for i := range checks {
+		_, checks[i].Remote = remote.upstream(checks[i].Worktree.Branch)
+	}
+	var safeWorktrees []git.Worktree
+	if events != nil {
+		safeWorktrees = skipUnsafeTargets(events, engine, checks, currentBranch)
+	} else {
+		var ok bool
+		if safeWorktrees, ok = planFanout(cmd, engine, checks, parents, currentBranch, currentDir); !ok {
+			return
+		}
+	}
+
+	if len(safeWorktrees) == 0 {
+		ui.Info("No worktrees to fanout to")
+		if events != nil {
+			events.done(fanoutCounts(nil, len(checks)))
+		}
+		return
+	}
+
+	branches := make([]string, len(safeWorktrees))
+	for i, wt := range safeWorktrees {
+		branches[i] = wt.Branch
+	}
+	if !guardProtected(cmd, branches) {
+		return
+	}
+
+	// Refuse before rewriting anything if the results can't be pushed
+	var upstreams map[string]git.Upstream
+	push := pushEnabled(cmd)
+	if push {
+		var local []string
+		for _, branch := range branches {
+			if _, ok := remote.upstream(branch); !ok {
+				local = append(local, branch)
+			}
+		}
+		var ok bool
+		if upstreams, ok = resolveUpstreams(currentDir, local); !ok {
+			return
+		}
+	}
+	upstreams = remote.addUpstreams(upstreams)
+
+	if events == nil {
+		// Confirm with user
+		if !confirmFanout(len(safeWorktrees), currentBranch, stack && len(parents) > 0, push, remote.count()) {
+			ui.Info("Fanout cancelled")
+			return
+		}
+
+		// Perform fanout
+		ui.Title("Fanout Progress")
+		fmt.Println()
+	}
+
+	// Ctrl-C stops the fanout once the rebase in progress is aborted,
+	// instead of leaving it halfway
+	started := time.Now()
+	stop := proc.OnInterrupt(engine.Interrupt)
+	results := engine.Run(safeWorktrees)
+	stop()
+	recordRebases(manager, results, nil)
+	successCount := fanout.Count(results, fanout.StatusSucceeded)
+
+	// Notify once the fanout is over, pushing included
+	counts := fanoutCounts(results, len(checks)-len(safeWorktrees))
+	result := schema.ResultSucceeded
+	if engine.Interrupted() {
+		result = schema.ResultInterrupted
+	} else if successCount < len(results) {
+		result = schema.ResultFailed
+	}
+	defer notifyDone(cmd, "fanout", result, counts, started)
+	manager.RecordOperation(schema.Operation{Op: schema.OpFanout, Branches: branches, Result: result})
+
+	if events != nil {
+		// Push whatever was rebased, unless Ctrl-C asked to stop
+		if !engine.Interrupted() {
+			pushBranches(currentDir, remote.pushed(rebasedBranches(results), push), upstreams)
+		}
+		events.done(counts)
+		return
+	}
+
+	if engine.Interrupted() {
+		printFanoutInterrupted(results, push)
 		return
 	}
 
+	// Push whatever was rebased, even if the fanout stopped early
+	if toPush := remote.pushed(rebasedBranches(results), push); len(toPush) > 0 {
+		fmt.Println()
+		pushBranches(currentDir, toPush, upstreams)
+	}
+
+	if successCount < len(results) {
+		return
+	}
+
+	// Summary
+	fmt.Println()
+	ui.Title("Fanout Complete")
+	ui.Successf("✓ Successfully fanned out to %d worktree(s)", successCount)
+	if successCount > 0 {
+		ui.Infof("All worktrees are now synchronized with %s", currentBranch)
+	}
+}
+
+// planFanout prints the safety checks of the targets and returns the
+// worktrees to rebase, letting the user decide about those that failed.
+// Returns false if the user quit.
+func planFanout(cmd *cobra.Command, engine *fanout.Engine, checks []fanout.Check, parents map[string]string, currentBranch, currentDir string) ([]git.Worktree, bool) {
 	ui.Title("Fanout Plan")
 	ui.Infof("Source: %s (current branch)", currentBranch)
-	ui.Infof("Targets: %d worktree(s)", len(targetWorktrees))
+	ui.Infof("Targets: %d worktree(s)", len(checks))
+	if verify := loadConfig(cmd).Git.VerifyCommand; verify != "" {
+		ui.Infof("Verify: %s, after each rebase", verify)
+	}
 	fmt.Println()
 
 	// Color definitions
@@ -85,137 +333,303 @@ func fanoutBranches(cmd *cobra.Command, args []string) {
 	green := color.New(color.FgGreen)
 
 	// Safety checks
-	var unsafeWorktrees []string
-	var safeWorktrees []git.Worktree
-
-	for _, wt := range targetWorktrees {
-		// Check 1: Uncommitted changes
-		if git.HasUncommittedChanges(wt.Path) {
+	unsafeCount := 0
+	explanations := make(map[string]explanation)
+
+	for _, check := range checks {
+		wt := check.Worktree
+		switch {
+		case check.Dirty:
+			// Check 1: Uncommitted changes
 			yellow.Printf("  ● %s (%s)\n", wt.Branch, wt.Path)
 			fmt.Println("     ⚠ Has uncommitted changes - cannot fanout")
-			unsafeWorktrees = append(unsafeWorktrees, wt.Branch)
+			explanations[wt.Branch] = uncommittedExplanation(wt.Path, wt.Branch, "ccswitch fanout")
+		case check.Err != nil:
+			ui.Errorf("  ✗ %s: failed to check status - %v", wt.Branch, check.Err)
+		case !check.Safe():
+			// Check 2: Branch is ahead of current
+			red.Printf("  ↑ %s (%s)\n", wt.Branch, wt.Path)
+			fmt.Printf("     ⚠ Ahead of %s by %d commit(s)\n", currentBranch, check.Ahead)
+			if parent, ok := parents[wt.Branch]; ok {
+				fmt.Printf("     Stacked on %s - use --stack to rebase it onto its parent\n", parent)
+			}
+			explanations[wt.Branch] = aheadExplanation(currentDir, wt.Branch, currentBranch, check.Ahead)
+		default:
+			// Safe to fanout
+			green.Printf("  ○ %s (%s)\n", wt.Branch, wt.Path)
+			if onto := engine.Onto(wt); onto != currentBranch {
+				fmt.Printf("     Stacked on %s\n", onto)
+			}
+			if check.Behind > 0 {
+				fmt.Printf("     Behind by %d commit(s)\n", check.Behind)
+			} else {
+				fmt.Println("     Up to date")
+			}
 			continue
 		}
+		unsafeCount++
+	}
 
-		// Check 2: Branch is ahead of current
-		diff, err := git.GetCommitCountDifference(wt.Path, currentBranch)
-		if err != nil {
-			ui.Errorf("  ✗ %s: failed to check status - %v", wt.Branch, err)
-			unsafeWorktrees = append(unsafeWorktrees, wt.Branch)
-			continue
-		}
+	fmt.Println()
 
-		if diff > 0 {
-			red.Printf("  ↑ %s (%s)\n", wt.Branch, wt.Path)
-			fmt.Printf("     ⚠ Ahead of %s by %d commit(s) - cannot fanout\n", currentBranch, diff)
-			unsafeWorktrees = append(unsafeWorktrees, wt.Branch)
-			continue
+	// Let the user decide what happens to worktrees that failed the checks
+	safeWorktrees, ok := chooseFanoutTargets(cmd, checks, unsafeCount, currentBranch)
+	if !ok {
+		ui.Info("Fanout cancelled")
+		return nil, false
+	}
+	for _, check := range checks {
+		if e, unsafe := explanations[check.Worktree.Branch]; unsafe && !containsWorktree(safeWorktrees, check.Worktree) {
+			printExplanation(cmd, e)
 		}
+	}
+	return safeWorktrees, true
+}
 
-		// Safe to fanout
-		safeWorktrees = append(safeWorktrees, wt)
-		green.Printf("  ○ %s (%s)\n", wt.Branch, wt.Path)
-		if diff < 0 {
-			fmt.Printf("     Behind by %d commit(s)\n", -diff)
-		} else {
-			fmt.Println("     Up to date")
-		}
+// selectFanoutTargets lets the user check the targets to fanout to, all
+// checked at first. It returns nil if the user quit or checked none.
+func selectFanoutTargets(cmd *cobra.Command, targets []git.Worktree, currentDir string) []git.Worktree {
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+
+	sessions := make([]git.SessionInfo, len(targets))
+	checked := make(map[string]bool, len(targets))
+	for i, wt := range targets {
+		sessions[i] = git.SessionInfo{Name: getWorktreeDisplayName(wt, currentDir), Branch: wt.Branch, Path: wt.Path}
+		checked[wt.Path] = true
 	}
 
-	fmt.Println()
+	selected, err := ui.PickSessions(sessions, ui.PickOptions{
+		Title:   "Select the worktrees to fanout to:",
+		Action:  "Fanout to",
+		Checked: checked,
+		NoTUI:   noTUI,
+	})
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return nil
+	}
+	if len(selected) == 0 {
+		ui.Info("No worktrees selected")
+		return nil
+	}
 
-	// Check if any worktree is unsafe
-	if len(unsafeWorktrees) > 0 {
-		ui.Errorf("✗ Cannot fanout: %d worktree(s) failed safety checks", len(unsafeWorktrees))
-		ui.Info("Please fix the issues above before running fanout")
-		return
+	chosen := make(map[string]bool, len(selected))
+	for _, s := range selected {
+		chosen[s.Path] = true
 	}
+	var filtered []git.Worktree
+	for _, wt := range targets {
+		if chosen[wt.Path] {
+			filtered = append(filtered, wt)
+		}
+	}
+	return filtered
+}
 
-	if len(safeWorktrees) == 0 {
-		ui.Info("No worktrees to fanout to")
-		return
+// skipUnsafeTargets returns the worktrees that passed the safety checks,
+// reporting the others as skipped. Tools reading --porcelain events can't
+// answer the checklist, so this is --skip-unsafe.
+func skipUnsafeTargets(events *eventStream, engine *fanout.Engine, checks []fanout.Check, currentBranch string) []git.Worktree {
+	var targets []git.Worktree
+	for _, check := range checks {
+		var reason string
+		switch {
+		case check.Dirty:
+			reason = "uncommitted changes"
+		case check.Err != nil:
+			reason = fmt.Sprintf("failed to check status: %v", check.Err)
+		case !check.Safe():
+			reason = fmt.Sprintf("ahead of %s by %d commit(s)", currentBranch, check.Ahead)
+		default:
+			targets = append(targets, check.Worktree)
+			continue
+		}
+		events.worktree(schema.EventSkipped, check.Worktree, engine.Onto(check.Worktree), reason)
 	}
+	return targets
+}
 
-	// Confirm with user
+// confirmFanout asks the user to go ahead with rebasing count worktrees,
+// remoteCount of them temporary ones for remote-only branches
+func confirmFanout(count int, currentBranch string, stacked, push bool, remoteCount int) bool {
 	ui.Title("Ready to Fanout")
-	ui.Warningf("This will rebase %d worktree(s) onto %s", len(safeWorktrees), currentBranch)
+	if stacked {
+		ui.Warningf("This will rebase %d worktree(s) onto %s or their parent branch", count, currentBranch)
+	} else {
+		ui.Warningf("This will rebase %d worktree(s) onto %s", count, currentBranch)
+	}
 	ui.Info("Worktrees will be preserved after successful fanout")
+	if push {
+		ui.Info("Rebased branches will be force-pushed to their upstream")
+	} else if remoteCount > 0 {
+		ui.Info("Rebased remote-only branches will be force-pushed back to the remote")
+	}
 	fmt.Println()
 	fmt.Print("Continue? (yes/no): ")
 
 	var confirm string
 	fmt.Scanln(&confirm)
-	if strings.ToLower(confirm) != "yes" {
-		ui.Info("Fanout cancelled")
-		return
+	return strings.ToLower(confirm) == "yes"
+}
+
+// recordRebases records the outcome of each rebase started in the operation
+// history, naming the sessions of branches found in sessions
+func recordRebases(manager *session.Manager, results []fanout.Result, sessions map[string]string) {
+	for _, r := range results {
+		if r.Status != fanout.StatusPending {
+			manager.Record(schema.OpRebase, sessions[r.Worktree.Branch], []string{r.Worktree.Branch}, r.Err)
+		}
 	}
+}
 
-	// Perform fanout
-	ui.Title("Fanout Progress")
-	fmt.Println()
+// rebasedBranches returns the branches the fanout rebased
+func rebasedBranches(results []fanout.Result) []string {
+	var rebased []string
+	for _, r := range results {
+		if r.Status == fanout.StatusSucceeded {
+			rebased = append(rebased, r.Worktree.Branch)
+		}
+	}
+	return rebased
+}
 
-	successCount := 0
-	for _, wt := range safeWorktrees {
-		ui.Infof("Rebasing %s onto %s...", wt.Branch, currentBranch)
+// printFanoutInterrupted reports what an interrupted fanout completed
+func printFanoutInterrupted(results []fanout.Result, push bool) {
+	var rebased, aborted, pending []string
+	for _, r := range results {
+		switch r.Status {
+		case fanout.StatusSucceeded:
+			rebased = append(rebased, r.Worktree.Branch)
+		case fanout.StatusInterrupted:
+			aborted = append(aborted, r.Worktree.Branch)
+		case fanout.StatusPending:
+			pending = append(pending, r.Worktree.Branch)
+		}
+	}
+
+	fmt.Println()
+	ui.Warning("⚠ Fanout interrupted")
+	printSummaryLine("Rebased", rebased)
+	printSummaryLine("Aborted and left as they were", aborted)
+	printSummaryLine("Not started", pending)
+	if push && len(rebased) > 0 {
+		ui.Info("  Rebased branches were not pushed; run 'ccswitch fanout --push' again to push them")
+	}
+}
 
-		// Perform rebase directly in the worktree
-		success, hasConflict, errMsg := rebaseWorktree(wt.Path, currentBranch)
+// chooseFanoutTargets returns the worktrees to rebase. Without unsafe
+// worktrees, those are all targets; with --skip-unsafe, the safe ones.
+// Otherwise the user reviews a checklist where dirty worktrees are skipped
+// and worktrees ahead of the source can be included to rebase them anyway.
+// Returns false if the user quit.
+func chooseFanoutTargets(cmd *cobra.Command, checks []fanout.Check, unsafeCount int, source string) ([]git.Worktree, bool) {
+	var targets []git.Worktree
+	if unsafeCount == 0 {
+		for _, check := range checks {
+			targets = append(targets, check.Worktree)
+		}
+		return targets, true
+	}
 
-		if errMsg != nil {
-			if hasConflict {
-				ui.Errorf("  ✗ Conflict detected, auto-aborted")
-				ui.Errorf("✗ Fanout stopped at %s due to conflict", wt.Branch)
-				ui.Info("Please resolve conflicts manually before continuing")
-				return
+	if skip, _ := cmd.Flags().GetBool("skip-unsafe"); skip {
+		ui.Warningf("⚠ Skipping %d worktree(s) that failed safety checks", unsafeCount)
+		for _, check := range checks {
+			if check.Safe() {
+				targets = append(targets, check.Worktree)
 			}
-			ui.Errorf("  ✗ Failed: %v", errMsg)
-			ui.Errorf("✗ Fanout stopped at %s", wt.Branch)
-			return
 		}
+		return targets, true
+	}
 
-		if !success {
-			ui.Errorf("  ✗ Rebase failed")
-			return
+	items := make([]ui.ChecklistItem, len(checks))
+	for i, check := range checks {
+		items[i] = ui.ChecklistItem{Label: check.Worktree.Branch, Checked: check.Safe()}
+		switch {
+		case check.Dirty:
+			items[i].Note = "uncommitted changes - skipped"
+			items[i].Locked = true
+		case check.Err != nil:
+			items[i].Note = "status check failed - skipped"
+			items[i].Locked = true
+		case !check.Safe():
+			items[i].Note = fmt.Sprintf("ahead of %s by %d - check to rebase anyway", source, check.Ahead)
 		}
+	}
 
-		ui.Successf("  ✓ Success")
-		successCount++
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+	checked, err := ui.Checklist(fmt.Sprintf("%d worktree(s) failed safety checks - choose what to rebase:", unsafeCount), items, noTUI)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return nil, false
+	}
+	if checked == nil {
+		return nil, false
 	}
 
-	// Summary
-	fmt.Println()
-	ui.Title("Fanout Complete")
-	ui.Successf("✓ Successfully fanned out to %d worktree(s)", successCount)
-	if successCount > 0 {
-		ui.Infof("All worktrees are now synchronized with %s", currentBranch)
+	for i, check := range checks {
+		if checked[i] {
+			targets = append(targets, check.Worktree)
+		}
 	}
+	return targets, true
 }
 
-// rebaseWorktree rebases a worktree onto the specified branch
-func rebaseWorktree(worktreePath, branch string) (success, conflict bool, err error) {
-	// Perform rebase
-	rebaseCmd := exec.Command("git", "rebase", branch)
-	rebaseCmd.Dir = worktreePath
-	output, e := rebaseCmd.CombinedOutput()
-
-	if e != nil {
-		outputStr := string(output)
-		// Check if it's a conflict error
-		if strings.Contains(outputStr, "conflict") || strings.Contains(outputStr, "CONFLICT") ||
-			strings.Contains(outputStr, "Failed to merge") {
-			// Auto-abort on conflict
-			abortRebaseInWorktree(worktreePath)
-			return false, true, fmt.Errorf("rebase conflict detected, auto-aborted")
+// containsWorktree reports whether worktrees includes wt
+func containsWorktree(worktrees []git.Worktree, wt git.Worktree) bool {
+	for _, w := range worktrees {
+		if w.Path == wt.Path {
+			return true
 		}
-		return false, false, fmt.Errorf("rebase failed: %w, output: %s", e, outputStr)
 	}
+	return false
+}
 
-	return true, false, nil
+// cliFanoutObserver renders fanout progress to the terminal
+type cliFanoutObserver struct {
+	cmd    *cobra.Command
+	engine *fanout.Engine
+	// progress spins while a target is rebased
+	progress *ui.Progress
 }
 
-// abortRebaseInWorktree aborts an ongoing rebase in a worktree
-func abortRebaseInWorktree(worktreePath string) {
-	cmd := exec.Command("git", "rebase", "--abort")
-	cmd.Dir = worktreePath
-	_ = cmd.Run()
+func (o *cliFanoutObserver) OnTargetStart(wt git.Worktree) {
+	o.progress = ui.StartProgress(fmt.Sprintf("Rebasing %s onto %s", wt.Branch, o.engine.Onto(wt)))
+}
+
+func (o *cliFanoutObserver) OnVerify(wt git.Worktree) {
+	o.progress.SetLabel("Verifying " + wt.Branch)
+}
+
+func (o *cliFanoutObserver) OnConflict(result fanout.Result) {
+	o.progress.Stop()
+	ui.Errorf("  ✗ Conflict detected in %s, auto-aborted", result.Worktree.Branch)
+	printConflict(result.Err)
+}
+
+func (o *cliFanoutObserver) OnTargetDone(result fanout.Result) {
+	o.progress.Stop()
+	switch result.Status {
+	case fanout.StatusSucceeded:
+		ui.Successf("  ✓ Rebased %s onto %s", result.Worktree.Branch, o.engine.Onto(result.Worktree))
+	case fanout.StatusConflicted:
+		ui.Errorf("✗ Fanout stopped at %s due to conflict", result.Worktree.Branch)
+		ui.Info("Please resolve conflicts manually before continuing")
+		wt := result.Worktree
+		printExplanation(o.cmd, rebaseConflictExplanation(wt.Path, wt.Branch, o.engine.RebaseArgs(wt), "ccswitch fanout"))
+	case fanout.StatusInterrupted:
+		ui.Warningf("  ⚠ Interrupted while rebasing %s, auto-aborted", result.Worktree.Branch)
+	case fanout.StatusVerifyFailed:
+		ui.Errorf("  ✗ %s: %v", result.Worktree.Branch, result.Err)
+		ui.Errorf("✗ Fanout stopped at %s", result.Worktree.Branch)
+		ui.Infof("  Tip: %s", errors.ErrorHint(result.Err))
+	default:
+		ui.Errorf("  ✗ Failed: %v", result.Err)
+		ui.Errorf("✗ Fanout stopped at %s", result.Worktree.Branch)
+	}
 }
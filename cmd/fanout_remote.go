@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// remoteBranchPrefix prefixes the temporary local branches remote-only
+// branches are rebased on, e.g. fanout/origin/feature-x
+const remoteBranchPrefix = "fanout/"
+
+// remoteTargets are the temporary worktrees a fanout --remote rebases
+// remote-only branches in. Each has a temporary local branch, pushed back
+// to the remote branch it was created from.
+type remoteTargets struct {
+	dir       string
+	root      string
+	worktrees []git.Worktree
+	upstreams map[string]git.Upstream
+}
+
+// addRemoteTargets fetches remote and creates a temporary worktree for each
+// of its branches without a local branch, leaving out the source branch and
+// protected branches. Failures are reported and return false, after
+// removing whatever was created.
+func addRemoteTargets(cmd *cobra.Command, dir, remote, source string) (*remoteTargets, bool) {
+	err := ui.WithProgress("Fetching "+remote, func(out io.Writer) error {
+		return git.Fetch(dir, remote, out)
+	})
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return nil, false
+	}
+
+	branches, err := git.RemoteOnlyBranches(dir, remote)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return nil, false
+	}
+
+	cfg := loadConfig(cmd)
+	targets := &remoteTargets{dir: dir, upstreams: make(map[string]git.Upstream)}
+	for _, branch := range branches {
+		if branch == source || cfg.IsProtectedBranch(branch) {
+			continue
+		}
+		if targets.root == "" {
+			if targets.root, err = os.MkdirTemp("", "ccswitch-fanout-"); err != nil {
+				ui.Errorf("✗ Failed to create a directory for remote branches: %v", err)
+				return nil, false
+			}
+		}
+
+		upstream := git.Upstream{Remote: remote, Branch: branch}
+		local := remoteBranchPrefix + upstream.String()
+		path := filepath.Join(targets.root, filepath.FromSlash(upstream.String()))
+		if err := git.NewBranchManager(dir).CreateFrom(local, upstream.String()); err != nil {
+			ui.Errorf("✗ %v", err)
+			targets.cleanup()
+			return nil, false
+		}
+		targets.upstreams[local] = upstream
+		if err := git.NewWorktreeManager(dir).Create(path, local); err != nil {
+			ui.Errorf("✗ %v", err)
+			targets.cleanup()
+			return nil, false
+		}
+		targets.worktrees = append(targets.worktrees, git.Worktree{Path: path, Branch: local})
+	}
+	return targets, true
+}
+
+// upstream returns the remote branch a temporary branch was created from
+func (r *remoteTargets) upstream(branch string) (git.Upstream, bool) {
+	if r == nil {
+		return git.Upstream{}, false
+	}
+	upstream, ok := r.upstreams[branch]
+	return upstream, ok
+}
+
+// count returns the number of remote-only branches
+func (r *remoteTargets) count() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.worktrees)
+}
+
+// addUpstreams adds the remote branches of the temporary branches to
+// upstreams, allocating it if needed
+func (r *remoteTargets) addUpstreams(upstreams map[string]git.Upstream) map[string]git.Upstream {
+	if r.count() == 0 {
+		return upstreams
+	}
+	if upstreams == nil {
+		upstreams = make(map[string]git.Upstream, len(r.upstreams))
+	}
+	for local, upstream := range r.upstreams {
+		upstreams[local] = upstream
+	}
+	return upstreams
+}
+
+// pushed returns the rebased branches to push: all of them with push, and
+// otherwise the temporary branches, whose rebase is lost unless pushed
+func (r *remoteTargets) pushed(rebased []string, push bool) []string {
+	if push {
+		return rebased
+	}
+	var branches []string
+	for _, branch := range rebased {
+		if _, ok := r.upstream(branch); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
+
+// cleanup removes the temporary worktrees and branches. The remote
+// branches keep whatever was pushed to them.
+func (r *remoteTargets) cleanup() {
+	worktrees := git.NewWorktreeManager(r.dir)
+	for _, wt := range r.worktrees {
+		if err := worktrees.Remove(wt.Path); err != nil {
+			ui.Warningf("⚠ Failed to remove %s: %v", wt.Path, err)
+		}
+	}
+	branches := git.NewBranchManager(r.dir)
+	for local := range r.upstreams {
+		if err := branches.Delete(local, true); err != nil {
+			ui.Warningf("⚠ %v", err)
+		}
+		// Backups of rebases that verify_command rolled back are of no use
+		// once the branch is gone
+		_ = git.DeleteRef(r.dir, git.BackupRef(local))
+	}
+	if r.root != "" {
+		os.RemoveAll(r.root)
+	}
+}
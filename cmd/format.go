@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/ksred/ccswitch/internal/format"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// formatAnnotation marks commands that render their output with --format
+const formatAnnotation = "ccswitch/format"
+
+// supportsFormat marks cmd as rendering its output with --format
+func supportsFormat(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[formatAnnotation] = "true"
+}
+
+// newFormatHelpCmd documents --format as 'ccswitch help format'
+func newFormatHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "format",
+		Short: "Fields and functions of --format templates",
+		Long:  format.Help,
+	}
+}
+
+// outputFormat returns the template given with --format, or nil if there
+// is none
+func outputFormat(cmd *cobra.Command) (*template.Template, error) {
+	text, _ := cmd.Flags().GetString("format")
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := format.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// warnUnformatted warns when --format is given to a command that ignores
+// it. Commands with a --format of their own, such as digest, shadow it.
+func warnUnformatted(cmd *cobra.Command) {
+	if cmd.LocalNonPersistentFlags().Lookup("format") != nil {
+		return
+	}
+	if cmd.Flags().Changed("format") && cmd.Annotations[formatAnnotation] == "" {
+		ui.Warningf("⚠ --format is ignored by '%s'; see 'ccswitch help format'", cmd.CommandPath())
+	}
+}
+
+// renderFormatted renders tmpl once for each item
+func renderFormatted[T any](w io.Writer, tmpl *template.Template, items []T) error {
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// graphColors tell the branch columns of the graph apart
+var graphColors = []*color.Color{
+	color.New(color.FgGreen),
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgBlue),
+	color.New(color.FgRed),
+}
+
+func newGraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Show which session branches contain which commits",
+		Long: `Show the commits of every session branch that are not on the base branch,
+with a column per branch marking the branches that contain each commit.
+
+The commits are drawn newest first, children before their parents as in
+'git log --graph', down to the base branch. A commit marked in several
+columns is shared, as when a session was stacked on another, and the
+branches built on another session are listed under the graph. Along with
+how far each branch is ahead of and behind the base, this shows the order
+to fanout or rebase them in.
+
+Detached sessions and sessions on the base branch itself are left out.
+
+Examples:
+  ccswitch graph                   # Relative to the current branch
+  ccswitch graph --base release/2.0
+  ccswitch graph --limit 10        # Show only the 10 newest commits`,
+		Args: cobra.NoArgs,
+		Run:  showGraph,
+	}
+
+	cmd.Flags().String("base", "", "Branch to draw the sessions against (default: current branch)")
+	_ = cmd.RegisterFlagCompletionFunc("base", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return localBranches(cmd), cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Int("limit", 50, "Show at most this many commits (0 for all)")
+
+	return cmd
+}
+
+func showGraph(cmd *cobra.Command, args []string) {
+	base, _ := cmd.Flags().GetString("base")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	if base == "" {
+		base, err = manager.GetCurrentBranch()
+		if err != nil {
+			ui.Errorf("✗ Failed to get current branch: %v", err)
+			return
+		}
+	}
+
+	graph, err := manager.Graph(base, limit)
+	if err != nil {
+		ui.Errorf("✗ Failed to build the graph: %v", err)
+		return
+	}
+	if len(graph.Branches) == 0 {
+		ui.Info("No session branches to show")
+		ui.Info("  Tip: Create one with 'ccswitch create'")
+		return
+	}
+
+	renderGraph(graph)
+}
+
+// renderGraph draws a column per branch, opened by a header line naming
+// it, and a row per commit marking the branches that contain it
+func renderGraph(g *session.Graph) {
+	gray := color.New(color.FgHiBlack)
+	columnColor := func(i int) *color.Color {
+		return graphColors[i%len(graphColors)]
+	}
+	// rails draws the columns left of column n as vertical lines
+	rails := func(n int) string {
+		var b strings.Builder
+		for i := range n {
+			b.WriteString(columnColor(i).Sprint("│ "))
+		}
+		return b.String()
+	}
+
+	ui.Titlef("🌳 Sessions relative to %s (%s)", g.Base, shortHash(g.BaseHash))
+	fmt.Println()
+
+	for i, branch := range g.Branches {
+		summary := fmt.Sprintf("%d ahead", branch.Ahead)
+		if branch.Behind > 0 {
+			summary += fmt.Sprintf(", %d behind", branch.Behind)
+		}
+		fmt.Printf("  %s%s %s\n", rails(i), columnColor(i).Sprintf("┌ %s (%s)", branch.Session, branch.Branch), gray.Sprint(summary))
+	}
+	fmt.Printf("  %s\n", rails(len(g.Branches)))
+
+	for _, c := range g.Commits {
+		var tips []string
+		var b strings.Builder
+		for i, in := range c.In {
+			switch {
+			case g.Branches[i].Tip == c.Hash:
+				b.WriteString(columnColor(i).Sprint("◉ "))
+				tips = append(tips, g.Branches[i].Session)
+			case in:
+				b.WriteString(columnColor(i).Sprint("● "))
+			default:
+				b.WriteString(gray.Sprint("· "))
+			}
+		}
+		line := fmt.Sprintf("  %s %s %s", b.String(), gray.Sprint(shortHash(c.Hash)), c.Subject)
+		if len(tips) > 0 {
+			line += " " + color.New(color.FgYellow).Sprintf("← %s", strings.Join(tips, ", "))
+		}
+		fmt.Println(line)
+	}
+	if g.Omitted > 0 {
+		gray.Printf("  %s… %d older commits (see --limit)\n", rails(len(g.Branches)), g.Omitted)
+	}
+
+	var bottom strings.Builder
+	for i := range g.Branches {
+		bottom.WriteString(columnColor(i).Sprint("┴─"))
+	}
+	fmt.Printf("  %s %s %s\n", bottom.String(), gray.Sprint(shortHash(g.BaseHash)), g.Base)
+
+	var stacked []string
+	for _, branch := range g.Branches {
+		if len(branch.BuildsOn) > 0 {
+			stacked = append(stacked, fmt.Sprintf("%s builds on %s", branch.Session, strings.Join(branch.BuildsOn, ", ")))
+		}
+	}
+	if len(stacked) > 0 {
+		fmt.Println()
+		for _, line := range stacked {
+			ui.Infof("  %s", line)
+		}
+	}
+}
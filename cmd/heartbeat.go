@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newHeartbeatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "heartbeat [session]",
+		Short: "Publish what an agent is doing in a session",
+		Long: `Publish the current activity of the agent working in a session. It is shown
+next to the session in 'ccswitch status' and, live, in the interactive
+session list, so you can tell working agents from stuck ones.
+
+Agents should send a heartbeat whenever they start something new, and at
+least every few minutes while busy. A session without a heartbeat for 5
+minutes is shown as quiet.
+
+The session defaults to $CCSWITCH_SESSION, set by 'ccswitch work' and
+'ccswitch exec', or else the session containing the current directory.
+
+Examples:
+  ccswitch heartbeat --status "running tests"
+  ccswitch heartbeat auth-fix --status "waiting for review"
+  ccswitch heartbeat --clear                  # The agent has finished`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               sendHeartbeat,
+	}
+
+	cmd.Flags().String("status", "", "What the agent is doing now")
+	cmd.Flags().Bool("clear", false, "Remove the session's activity")
+
+	return cmd
+}
+
+func sendHeartbeat(cmd *cobra.Command, args []string) {
+	status, _ := cmd.Flags().GetString("status")
+	clear, _ := cmd.Flags().GetBool("clear")
+	status = strings.TrimSpace(status)
+
+	if status == "" && !clear {
+		ui.Error("✗ Pass --status with the current activity, or --clear")
+		return
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	name := heartbeatSession(manager, currentDir, args)
+	if name == "" {
+		ui.Error("✗ Not in a session; name the session to report for")
+		return
+	}
+
+	if clear {
+		if err := manager.ClearHeartbeat(name); err != nil {
+			ui.Errorf("✗ Failed to clear activity: %v", err)
+			return
+		}
+		ui.Successf("✓ Cleared activity of %s", name)
+		return
+	}
+
+	if err := manager.Heartbeat(name, status); err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+	ui.Successf("✓ %s: %s", name, status)
+}
+
+// heartbeatSession returns the session a heartbeat is for: the one named in
+// args, $CCSWITCH_SESSION, or the session containing the working directory
+func heartbeatSession(manager *session.Manager, currentDir string, args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	if name := os.Getenv(session.EnvPrefix + "SESSION"); name != "" {
+		return name
+	}
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		return ""
+	}
+	if s := currentSession(sessions, currentDir); s != nil {
+		return s.Name
+	}
+	return ""
+}
+
+// activitySummary describes a session's latest agent activity, e.g.
+// "running tests (2m ago)"
+func activitySummary(a *schema.Activity, now time.Time) string {
+	summary := a.Status + " (" + utils.FormatRelative(a.UpdatedAt, now) + ")"
+	if a.Quiet {
+		return "quiet, last: " + summary
+	}
+	return summary
+}
+
+// activitySummaries returns the activity summary of every session with a
+// heartbeat, keyed by worktree path
+func activitySummaries(manager *session.Manager) map[string]string {
+	now := time.Now()
+	summaries := make(map[string]string)
+	for path, h := range manager.Heartbeats() {
+		summaries[path] = activitySummary(&schema.Activity{Status: h.Status, UpdatedAt: h.At, Quiet: h.Quiet(now)}, now)
+	}
+	return summaries
+}
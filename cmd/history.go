@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/format"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show what ccswitch has done in the repository",
+		Long: `Show the operations ccswitch has performed in the repository, oldest first:
+sessions created, deleted, renamed and moved, branches rebased by rebase,
+sync, fanout, stack restack and daemon, fanouts and cherry-picks. Each entry
+has its time, the user and command that ran it, the session and branches
+it affected, and its result.
+
+The history is kept in ~/.ccswitch/state/<repo>/history.jsonl, one JSON
+object per line, so it can be audited with other tools too. --format
+renders each operation with a template instead; see 'ccswitch help format'.
+
+Examples:
+  ccswitch history
+  ccswitch history --since 7d
+  ccswitch history --session fix-login
+  ccswitch history --op rebase --json
+  ccswitch history --format '{{.At.Format "2006-01-02"}} {{.User}} {{.Op}} {{.Session}}'`,
+		Args: cobra.NoArgs,
+		Run:  showHistory,
+	}
+
+	cmd.Flags().String("session", "", "Only show operations on this session or branch")
+	cmd.Flags().String("since", "", "Only show operations in this recent window (e.g. 24h, 7d)")
+	cmd.Flags().String("op", "", "Only show this operation: create, delete, rename, move, rebase, fanout or pick")
+	cmd.Flags().Bool("json", false, "Output the history as JSON")
+	cmd.Flags().Bool("absolute", false, "Show times as local timestamps")
+	supportsFormat(cmd)
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSession)
+	_ = cmd.RegisterFlagCompletionFunc("op", fixedCompletion(schema.OpCreate, schema.OpDelete, schema.OpRename, schema.OpMove, schema.OpRebase, schema.OpFanout, schema.OpPick))
+
+	return cmd
+}
+
+func showHistory(cmd *cobra.Command, args []string) {
+	sessionFilter, _ := cmd.Flags().GetString("session")
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	opFilter, _ := cmd.Flags().GetString("op")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	absolute, _ := cmd.Flags().GetBool("absolute")
+
+	tmpl, err := outputFormat(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if tmpl != nil && asJSON {
+		ui.Error("✗ --format cannot be used with --json")
+		return
+	}
+
+	var since time.Time
+	if sinceFlag != "" {
+		window, err := utils.ParseDuration(sinceFlag)
+		if err != nil {
+			ui.Errorf("✗ Invalid --since: %v", err)
+			return
+		}
+		since = time.Now().Add(-window)
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	ops, err := manager.Operations(since)
+	if err != nil {
+		ui.Errorf("✗ Failed to read the history: %v", err)
+		return
+	}
+
+	doc := &schema.History{Header: schema.NewHeader(), Repo: manager.RepoName(), Operations: []schema.Operation{}}
+	for _, op := range ops {
+		if opFilter != "" && op.Op != opFilter {
+			continue
+		}
+		if sessionFilter != "" && op.Session != sessionFilter && !slices.Contains(op.Branches, sessionFilter) {
+			continue
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+
+	if asJSON {
+		if err := schema.Write(os.Stdout, doc); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	if tmpl != nil {
+		if err := renderFormatted(os.Stdout, tmpl, format.Operations(doc)); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	if len(doc.Operations) == 0 {
+		ui.Info("No operations recorded")
+		return
+	}
+
+	ui.Titlef("📜 History of %s", doc.Repo)
+	fmt.Println()
+
+	gray := color.New(color.FgHiBlack)
+	for _, op := range doc.Operations {
+		line := fmt.Sprintf("  %s %-7s", resultGlyph(op.Result), op.Op)
+		if op.Session != "" {
+			line += " " + op.Session
+		}
+		if len(op.Branches) > 0 {
+			line += " (" + strings.Join(op.Branches, ", ") + ")"
+		}
+		switch op.Result {
+		case schema.ResultSucceeded:
+			fmt.Println(line)
+		case schema.ResultConflicted, schema.ResultInterrupted:
+			ui.Warning(line + " - " + op.Result)
+		default:
+			ui.Error(line + " - " + op.Result)
+		}
+
+		details := []string{utils.FormatTime(op.At, absolute)}
+		if op.User != "" {
+			details = append(details, "by "+op.User)
+		}
+		if op.Command != "" {
+			details = append(details, "ccswitch "+op.Command)
+		}
+		gray.Printf("      %s\n", strings.Join(details, " · "))
+		if op.Error != "" {
+			message, _, _ := strings.Cut(op.Error, "\n")
+			gray.Printf("      %s\n", message)
+		}
+	}
+}
+
+// resultGlyph marks the result of an operation
+func resultGlyph(result string) string {
+	switch result {
+	case schema.ResultSucceeded:
+		return "✓"
+	case schema.ResultConflicted, schema.ResultInterrupted:
+		return "⚠"
+	default:
+		return "✗"
+	}
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// importSource describes where another worktree manager places its worktrees
+type importSource struct {
+	description string
+	matches     func(mainRepo, worktreePath string) bool
+}
+
+var importSources = map[string]importSource{
+	"git-worktree": {
+		description: "any worktree created with plain 'git worktree add'",
+		matches:     func(string, string) bool { return true },
+	},
+	"wt": {
+		description: "sibling directories named <repo>.<branch>",
+		matches: func(mainRepo, worktreePath string) bool {
+			prefix := filepath.Base(mainRepo) + "."
+			return filepath.Dir(worktreePath) == filepath.Dir(mainRepo) &&
+				strings.HasPrefix(filepath.Base(worktreePath), prefix)
+		},
+	},
+	"gwq": {
+		description: "worktrees under ~/worktrees",
+		matches: func(_, worktreePath string) bool {
+			homeDir, _ := os.UserHomeDir()
+			return isWithinDir(worktreePath, filepath.Join(homeDir, "worktrees"))
+		},
+	},
+}
+
+// under returns the source matching worktrees under root instead of where
+// the tool places them by default, as --root asks
+func (s importSource) under(root string) (importSource, error) {
+	absRoot, err := filepath.Abs(expandHome(root))
+	if err != nil {
+		return s, err
+	}
+	s.matches = func(_, worktreePath string) bool {
+		return isWithinDir(worktreePath, absRoot)
+	}
+	return s, nil
+}
+
+// importSourceNames returns the accepted values of --from, sorted
+func importSourceNames() []string {
+	names := make([]string, 0, len(importSources))
+	for name := range importSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file> | --from git-worktree|wt|gwq",
+		Short: "Recreate exported sessions, or import worktrees created by other tools",
+		Long: `With a file written by 'ccswitch export', recreate the sessions it describes,
+such as a teammate's. Each session gets a worktree for its branch: an
+existing local branch is checked out, and a missing one is created from the
+branch of the same name on origin if there is one, or else from the
+session's base branch. Its issue, tags, variables and notes are recorded
+with it. Sessions whose worktree already exists are skipped. "-" reads the
+file from standard input.
+
+With --from, scan worktrees created by other worktree managers (or plain git) and
+register them as ccswitch sessions. Worktrees stay where they are; only
+session metadata is written, so list, switch, rebase and fanout pick them up.
+
+Sources:
+  git-worktree  any worktree created with plain 'git worktree add'
+  wt            sibling directories named <repo>.<branch>
+  gwq           worktrees under ~/worktrees
+
+Use --root if your tool is configured to place worktrees elsewhere.
+
+Examples:
+  ccswitch import sessions.yaml
+  ccswitch import sessions.yaml --dry-run
+  ccswitch import --from git-worktree
+  ccswitch import --from gwq --root ~/src/worktrees --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  importSessions,
+	}
+
+	cmd.Flags().String("from", "", "Tool that created the worktrees: git-worktree, wt or gwq")
+	cmd.Flags().String("root", "", "Only import worktrees under this directory")
+	_ = cmd.RegisterFlagCompletionFunc("from", fixedCompletion(importSourceNames()...))
+	_ = cmd.MarkFlagDirname("root")
+	cmd.Flags().Bool("dry-run", false, "Only show what would be imported")
+	cmd.Flags().BoolP("yes", "y", false, "Import without asking for confirmation")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func importSessions(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	root, _ := cmd.Flags().GetString("root")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
+
+	if len(args) == 1 {
+		if from != "" || root != "" {
+			ui.Error("✗ --from and --root cannot be used with a file")
+			return
+		}
+		importSessionFile(cmd, args[0], dryRun, skipConfirm)
+		return
+	}
+	if from == "" {
+		ui.Error("✗ Give a file written by 'ccswitch export', or --from with the tool that created the worktrees")
+		return
+	}
+
+	source, ok := importSources[from]
+	if !ok {
+		ui.Errorf("✗ Unknown source: %s (expected one of: %s)", from, strings.Join(importSourceNames(), ", "))
+		return
+	}
+
+	if root != "" {
+		var err error
+		if source, err = source.under(root); err != nil {
+			ui.Errorf("✗ Invalid --root: %v", err)
+			return
+		}
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	mainRepo, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		ui.Error("✗ Not in a git repository")
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	unmanaged, err := manager.UnmanagedWorktrees()
+	if err != nil {
+		ui.Errorf("✗ Failed to list worktrees: %v", err)
+		return
+	}
+
+	var candidates []git.Worktree
+	for _, wt := range unmanaged {
+		if source.matches(mainRepo, wt.Path) {
+			candidates = append(candidates, wt)
+		}
+	}
+
+	if len(candidates) == 0 {
+		ui.Infof("No unmanaged worktrees found for %s (%s)", from, source.description)
+		return
+	}
+
+	ui.Titlef("📥 Worktrees to import from %s:", from)
+	fmt.Println()
+	for _, wt := range candidates {
+		ui.Infof("  • %s (%s)", session.SessionNameForBranch(wt.Branch), wt.Branch)
+		fmt.Printf("     Path: %s\n", wt.Path)
+	}
+	fmt.Println()
+
+	if dryRun {
+		return
+	}
+
+	if !skipConfirm {
+		fmt.Print("Import these worktrees as sessions? (yes/no): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if strings.ToLower(confirm) != "yes" {
+			ui.Info("Import cancelled")
+			return
+		}
+	}
+
+	imported := 0
+	for _, wt := range candidates {
+		name := session.SessionNameForBranch(wt.Branch)
+		if err := manager.AdoptWorktree(wt, name); err != nil {
+			ui.Errorf("✗ Failed to import %s: %v", wt.Path, err)
+			continue
+		}
+		ui.Successf("✓ Imported session: %s", name)
+		imported++
+	}
+
+	fmt.Println()
+	if imported == len(candidates) {
+		ui.Successf("✅ All %d worktrees imported successfully!", imported)
+	} else {
+		ui.Infof("Imported %d out of %d worktrees", imported, len(candidates))
+	}
+}
+
+// importSessionFile recreates the sessions described in the export file at
+// path, or on stdin for "-"
+func importSessionFile(cmd *cobra.Command, path string, dryRun, skipConfirm bool) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(expandHome(path))
+	}
+	if err != nil {
+		ui.Errorf("✗ Failed to read %s: %v", path, err)
+		return
+	}
+	set, err := session.ParseSessionSet(data)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if len(set.Sessions) == 0 {
+		ui.Infof("No sessions in %s", path)
+		return
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	if set.Repo != "" && set.Repo != manager.RepoName() {
+		ui.Warningf("⚠ These sessions were exported from %s, not %s", set.Repo, manager.RepoName())
+	}
+
+	ui.Titlef("📥 Sessions to import from %s:", path)
+	fmt.Println()
+	for _, def := range set.Sessions {
+		if def.Branch == "" {
+			ui.Infof("  • %s (%s at %s)", def.Name, git.DetachedLabel, def.Ref)
+		} else {
+			ui.Infof("  • %s (%s)", def.Name, def.Branch)
+		}
+	}
+	fmt.Println()
+
+	if dryRun {
+		return
+	}
+
+	if !skipConfirm {
+		fmt.Print("Create these sessions? (yes/no): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if strings.ToLower(confirm) != "yes" {
+			ui.Info("Import cancelled")
+			return
+		}
+	}
+
+	imported, skipped := 0, 0
+	for _, def := range set.Sessions {
+		entry, err := manager.ImportSession(def)
+		if errors.IsWorktreeExists(err) {
+			ui.Infof("Skipping %s: its worktree already exists", def.Name)
+			skipped++
+			continue
+		}
+		if err != nil {
+			ui.Errorf("✗ Failed to import %s: %v", def.Name, err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			continue
+		}
+		ui.Successf("✓ Imported session: %s", entry.Name)
+		imported++
+	}
+
+	fmt.Println()
+	if imported+skipped == len(set.Sessions) {
+		ui.Successf("✅ Imported %d sessions, %d already existed", imported, skipped)
+	} else {
+		ui.Infof("Imported %d out of %d sessions", imported, len(set.Sessions))
+	}
+}
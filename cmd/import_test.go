@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestImportSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	src := filepath.Join(home, "src")
+	repo := filepath.Join(src, "app")
+
+	custom, err := importSources["gwq"].under("~/elsewhere")
+	if err != nil {
+		t.Fatalf("under(~/elsewhere) failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		source   importSource
+		path     string
+		expected bool
+	}{
+		{"wt sibling", importSources["wt"], filepath.Join(src, "app.feature-x"), true},
+		{"wt other repo", importSources["wt"], filepath.Join(src, "api.feature-x"), false},
+		{"wt repo without dot", importSources["wt"], filepath.Join(src, "app-feature-x"), false},
+		{"wt nested", importSources["wt"], filepath.Join(src, "nested", "app.feature-x"), false},
+		{"gwq under worktrees", importSources["gwq"], filepath.Join(home, "worktrees", "github.com", "app", "feature-x"), true},
+		{"gwq worktrees prefix", importSources["gwq"], filepath.Join(home, "worktrees-old", "feature-x"), false},
+		{"gwq sibling", importSources["gwq"], filepath.Join(src, "app.feature-x"), false},
+		{"root under", custom, filepath.Join(home, "elsewhere", "feature-x"), true},
+		{"root replaces default", custom, filepath.Join(home, "worktrees", "feature-x"), false},
+		{"git-worktree anywhere", importSources["git-worktree"], filepath.Join(home, "tmp", "x"), true},
+	}
+	for _, tt := range tests {
+		if got := tt.source.matches(repo, tt.path); got != tt.expected {
+			t.Errorf("%s: matches(%s) = %v, expected %v", tt.name, tt.path, got, tt.expected)
+		}
+	}
+}
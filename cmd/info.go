@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/ksred/ccswitch/internal/git"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -32,11 +33,14 @@ func showInfo(cmd *cobra.Command, args []string) {
 	fmt.Println()
 
 	// Current repository
-	currentDir, _ := os.Getwd()
+	currentDir, _ := workingDir(cmd)
 	currentRepo := filepath.Base(currentDir)
 	ui.Success("Current Repository:")
 	ui.Infof("  Name: %s", currentRepo)
 	ui.Infof("  Path: %s", currentDir)
+	if root, err := git.GetMainRepoPath(currentDir); err == nil {
+		ui.Infof("  New sessions go to: %s", loadConfig(cmd).WorktreePath(root, "<session>"))
+	}
 	fmt.Println()
 
 	// Statistics
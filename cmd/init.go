@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// sharedHooksDir is where teams commit git hooks shared through ccswitch,
+// relative to the repository root
+const sharedHooksDir = ".ccswitch/hooks"
+
+// gitignoreEntries are added to .gitignore by init so personal ccswitch
+// files are never committed
+var gitignoreEntries = []string{".ccswitch/*.local.yaml"}
+
+func newInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up ccswitch for a repository",
+		Long: `Set up ccswitch for the current repository in one step:
+
+  1. Create .ccswitch/ with a starter config.yaml shared by the team
+  2. Add .gitignore entries for personal files such as .ccswitch/config.local.yaml
+  3. Install shared git hooks from .ccswitch/hooks by pointing core.hooksPath
+     at it (only when the directory contains hooks and core.hooksPath is unset)
+  4. Optionally create the first session
+
+Existing files are left unchanged, so init is safe to run again, e.g. after
+pulling hooks a teammate added.
+
+Examples:
+  ccswitch init
+  ccswitch init --session "add login page"`,
+		Args: cobra.NoArgs,
+		Run:  initRepo,
+	}
+
+	cmd.Flags().String("session", "", "Create a first session with this description")
+	addSetupFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func initRepo(cmd *cobra.Command, args []string) {
+	description, _ := cmd.Flags().GetString("session")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	root, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		ui.Error("✗ Not in a git repository")
+		return
+	}
+
+	ui.Titlef("🧰 Setting up ccswitch in %s", root)
+	fmt.Println()
+
+	// 1. Starter config
+	if err := os.MkdirAll(filepath.Join(root, sharedHooksDir), 0755); err != nil {
+		ui.Errorf("✗ Failed to create %s: %v", config.RepoDir(root), err)
+		return
+	}
+
+	configPath := config.RepoConfigPath(root)
+	if _, err := os.Stat(configPath); err == nil {
+		ui.Infof("  Config %s already exists, left unchanged", configPath)
+	} else {
+		if err := os.WriteFile(configPath, []byte(config.StarterRepoConfig()), 0644); err != nil {
+			ui.Errorf("✗ Failed to write config: %v", err)
+			return
+		}
+		ui.Successf("✓ Wrote starter config to %s", configPath)
+	}
+
+	// 2. .gitignore
+	added, err := ensureGitignore(filepath.Join(root, ".gitignore"), gitignoreEntries)
+	switch {
+	case err != nil:
+		ui.Errorf("✗ Failed to update .gitignore: %v", err)
+		return
+	case len(added) > 0:
+		ui.Successf("✓ Added %s to .gitignore", strings.Join(added, ", "))
+	default:
+		ui.Info("  .gitignore already has the ccswitch entries")
+	}
+
+	// 3. Shared hooks
+	hooks, err := installSharedHooks(root)
+	switch {
+	case err != nil:
+		ui.Errorf("✗ Failed to install shared hooks: %v", err)
+	case len(hooks) == 0:
+		ui.Infof("  No shared hooks yet. Commit git hooks to %s and run 'ccswitch init' again.", sharedHooksDir)
+	default:
+		ui.Successf("✓ Installed shared hooks: %s", strings.Join(hooks, ", "))
+	}
+
+	fmt.Println()
+	ui.Info("Commit .ccswitch/ and .gitignore to share the setup with your team.")
+
+	// 4. First session
+	if description == "" {
+		ui.Info("  Tip: Create your first session with 'ccswitch create'")
+		return
+	}
+
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	fmt.Println()
+	strategy, ok := creationStrategy(manager)
+	if !ok {
+		return
+	}
+	start := time.Now()
+	progress := ui.StartProgress("Creating worktree")
+	err = manager.CreateSession(description)
+	progress.Stop()
+	if err != nil {
+		ui.Errorf("✗ %s", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+	reportCheckout(strategy, "", nil, time.Since(start))
+	setupWorktree(cmd, manager, manager.GetSessionPath(utils.Slugify(description)))
+	reportCreatedSession(cmd, manager, description)
+}
+
+// ensureGitignore appends the entries missing from the .gitignore at path,
+// creating it if needed, and returns the entries it added
+func ensureGitignore(path string, entries []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, entry := range entries {
+		if !existing[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.Write(data)
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		b.WriteString("\n")
+	}
+	if len(data) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("# ccswitch\n")
+	for _, entry := range missing {
+		b.WriteString(entry + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// installSharedHooks makes the hooks in .ccswitch/hooks executable and
+// points core.hooksPath at the directory. It returns the installed hooks, or
+// none if the directory is empty. A core.hooksPath already pointing elsewhere
+// is never overwritten.
+func installSharedHooks(root string) ([]string, error) {
+	dir := filepath.Join(root, sharedHooksDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".sample") {
+			continue
+		}
+		if err := os.Chmod(filepath.Join(dir, name), 0755); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, name)
+	}
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	current, err := git.GetConfig(root, "core.hooksPath")
+	if err != nil {
+		return nil, err
+	}
+	switch current {
+	case sharedHooksDir:
+		return hooks, nil
+	case "":
+		// A relative hooksPath resolves in every worktree, each of which
+		// has its own checkout of .ccswitch/hooks
+		return hooks, git.SetConfig(root, "core.hooksPath", sharedHooksDir)
+	default:
+		return nil, fmt.Errorf("core.hooksPath is already set to %s; add the hooks there or unset it", current)
+	}
+}
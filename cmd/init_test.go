@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureGitignore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitignore")
+	if err := os.WriteFile(path, []byte("node_modules"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := ensureGitignore(path, []string{".ccswitch/*.local.yaml"})
+	if err != nil || len(added) != 1 {
+		t.Fatalf("ensureGitignore() = %v, %v; expected one entry added", added, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "node_modules\n\n# ccswitch\n.ccswitch/*.local.yaml\n"
+	if string(data) != expected {
+		t.Errorf(".gitignore = %q, expected %q", data, expected)
+	}
+
+	added, err = ensureGitignore(path, []string{".ccswitch/*.local.yaml"})
+	if err != nil || len(added) != 0 {
+		t.Errorf("second ensureGitignore() = %v, %v; expected nothing added", added, err)
+	}
+}
+
+func TestInstallSharedHooks(t *testing.T) {
+	root := t.TempDir()
+	if err := exec.Command("git", "init", root).Run(); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+	hooksDir := filepath.Join(root, sharedHooksDir)
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := installSharedHooks(root)
+	if err != nil || len(hooks) != 0 {
+		t.Fatalf("installSharedHooks() with no hooks = %v, %v", hooks, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err = installSharedHooks(root)
+	if err != nil || len(hooks) != 1 || hooks[0] != "pre-commit" {
+		t.Fatalf("installSharedHooks() = %v, %v; expected [pre-commit]", hooks, err)
+	}
+
+	out, _ := exec.Command("git", "-C", root, "config", "core.hooksPath").Output()
+	if string(out) != sharedHooksDir+"\n" {
+		t.Errorf("core.hooksPath = %q, expected %q", out, sharedHooksDir)
+	}
+
+	if err := exec.Command("git", "-C", root, "config", "core.hooksPath", ".githooks").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installSharedHooks(root); err == nil {
+		t.Error("installSharedHooks() should refuse to replace another core.hooksPath")
+	}
+}
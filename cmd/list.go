@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/format"
+	"github.com/ksred/ccswitch/internal/schema"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/ksred/ccswitch/internal/utils"
@@ -12,24 +18,228 @@ import (
 )
 
 func newListCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List and switch to sessions interactively",
-		Run:   listSessions,
+		Long: `List sessions and switch to the one you pick.
+
+Filtering, sorting or formatting the sessions turns the list into a report
+that is printed instead of picked from:
+
+  --dirty, --ahead, --behind  Only sessions with uncommitted changes, or
+                              commits ahead of or behind the current branch
+  --stale <duration>          Only sessions without commits or use in that
+                              long, e.g. 7d
+  --tag <tag>                 Only sessions tagged so (see 'ccswitch tag')
+  --sort <order>              name, age (oldest first), activity (most
+                              recent first) or ahead (most commits first)
+  --format <template>         A Go template rendered for each session; see
+                              'ccswitch help format' for its fields
+
+With --remote, the sessions of another machine running 'ccswitch serve' are
+listed instead. They are read-only, so nothing is picked or switched to.
+
+Examples:
+  ccswitch list
+  ccswitch list --dirty
+  ccswitch list --stale 14d --sort age
+  ccswitch list --tag frontend --sort activity
+  ccswitch list --ahead --format '{{.Name}} {{.Branch}} +{{.Ahead}}'
+  ccswitch list --remote http://buildbox:7777`,
+		Run: listSessions,
 	}
+
+	cmd.Flags().Bool("dirty", false, "Only list sessions with uncommitted changes")
+	cmd.Flags().Bool("ahead", false, "Only list sessions with commits ahead of the current branch")
+	cmd.Flags().Bool("behind", false, "Only list sessions behind the current branch")
+	cmd.Flags().String("stale", "", "Only list sessions without commits or use in this long (e.g. 7d)")
+	cmd.Flags().String("tag", "", "Only list sessions with this tag")
+	cmd.Flags().String("sort", "", "Sort sessions by name, age, activity or ahead")
+	_ = cmd.RegisterFlagCompletionFunc("sort", fixedCompletion(listSortOrders...))
+	addRemoteFlag(cmd)
+	supportsFormat(cmd)
+
+	return cmd
+}
+
+// listSortOrders are the orders list --sort accepts
+var listSortOrders = []string{"name", "age", "activity", "ahead"}
+
+// listReport is how list filters, sorts and formats sessions when it
+// prints them instead of picking one
+type listReport struct {
+	dirty, ahead, behind bool
+	// stale is how long sessions must have gone without commits or use
+	stale  time.Duration
+	tag    string
+	sort   string
+	format *template.Template
+}
+
+// listReportFlags returns the report the flags ask for, or nil if list
+// should pick a session
+func listReportFlags(cmd *cobra.Command) (*listReport, error) {
+	changed := false
+	for _, name := range []string{"dirty", "ahead", "behind", "stale", "tag", "sort", "format"} {
+		changed = changed || cmd.Flags().Changed(name)
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	report := &listReport{}
+	report.dirty, _ = cmd.Flags().GetBool("dirty")
+	report.ahead, _ = cmd.Flags().GetBool("ahead")
+	report.behind, _ = cmd.Flags().GetBool("behind")
+	report.tag, _ = cmd.Flags().GetString("tag")
+	report.sort, _ = cmd.Flags().GetString("sort")
+
+	if stale, _ := cmd.Flags().GetString("stale"); stale != "" {
+		window, err := utils.ParseDuration(stale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --stale: %w", err)
+		}
+		report.stale = window
+	}
+	if report.sort != "" && !slices.Contains(listSortOrders, report.sort) {
+		return nil, fmt.Errorf("invalid --sort %q: expected one of %s", report.sort, strings.Join(listSortOrders, ", "))
+	}
+	tmpl, err := outputFormat(cmd)
+	if err != nil {
+		return nil, err
+	}
+	report.format = tmpl
+	return report, nil
+}
+
+// apply returns the sessions the report keeps, in its order
+func (r *listReport) apply(sessions []schema.StatusSession, now time.Time) []schema.StatusSession {
+	var kept []schema.StatusSession
+	for _, s := range sessions {
+		switch {
+		case r.dirty && !s.Dirty,
+			r.ahead && s.Ahead == 0,
+			r.behind && s.Behind == 0,
+			r.tag != "" && !slices.Contains(s.Tags, r.tag):
+			continue
+		}
+		if r.stale > 0 {
+			if last := lastActivity(s); last != nil && now.Sub(*last) < r.stale {
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+
+	switch r.sort {
+	case "name":
+		slices.SortStableFunc(kept, func(a, b schema.StatusSession) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	case "age":
+		slices.SortStableFunc(kept, func(a, b schema.StatusSession) int {
+			return compareTimes(a.CreatedAt, b.CreatedAt)
+		})
+	case "activity":
+		slices.SortStableFunc(kept, func(a, b schema.StatusSession) int {
+			x, y := lastActivity(a), lastActivity(b)
+			if x == nil || y == nil {
+				return compareTimes(x, y)
+			}
+			return y.Compare(*x)
+		})
+	case "ahead":
+		slices.SortStableFunc(kept, func(a, b schema.StatusSession) int {
+			return cmp.Compare(b.Ahead, a.Ahead)
+		})
+	}
+	return kept
+}
+
+// lastActivity returns when a session last had a commit or was used, or
+// else when it was created, or nil if none is known
+func lastActivity(s schema.StatusSession) *time.Time {
+	last := s.CreatedAt
+	for _, t := range []*time.Time{s.LastActive, s.LastUsed} {
+		if t != nil && (last == nil || t.After(*last)) {
+			last = t
+		}
+	}
+	return last
+}
+
+// compareTimes orders earlier times first, and unknown ones last
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return a.Compare(*b)
+	}
+}
+
+// print prints the sessions of doc that the report keeps, titled
+// with where they are
+func (r *listReport) print(doc *schema.Status, title string) {
+	doc.Sessions = r.apply(doc.Sessions, time.Now())
+
+	if r.format != nil {
+		if err := renderFormatted(os.Stdout, r.format, format.Sessions(doc)); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	if len(doc.Sessions) == 0 {
+		ui.Info("No matching sessions")
+		return
+	}
+	ui.Titlef("📂 %s", title)
+	fmt.Println()
+	renderStatus(doc, nil, false)
 }
 
 func listSessions(cmd *cobra.Command, args []string) {
+	report, err := listReportFlags(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	if cmd.Flags().Changed("remote") {
+		listRemoteSessions(cmd, report)
+		return
+	}
+
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
 
+	if report != nil {
+		currentBranch, err := manager.GetCurrentBranch()
+		if err != nil {
+			ui.Errorf("✗ Failed to get current branch: %v", err)
+			return
+		}
+		doc, err := manager.Status(currentBranch)
+		if err != nil {
+			ui.Errorf("✗ Failed to list sessions: %v", err)
+			return
+		}
+		report.print(doc, fmt.Sprintf("Sessions of %s (compared to %s)", doc.Repo, doc.Base))
+		return
+	}
+
 	// Get sessions
 	sessions, err := manager.ListSessions()
 	if err != nil {
@@ -42,31 +252,19 @@ func listSessions(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Use interactive selector
-	selector := ui.NewSessionSelector(sessions)
-	p := tea.NewProgram(selector)
-
-	if _, err := p.Run(); err != nil {
-		ui.Errorf("✗ Failed to run selector: %v", err)
-		return
-	}
-
-	if selector.IsQuit() {
-		return
-	}
-
-	selected := selector.GetSelected()
+	selected := pickSession(cmd, manager, sessions, "📂 Select session to switch to:")
 	if selected == nil {
 		return
 	}
+	_ = manager.MarkUsed(*selected)
 
 	// Output success message with consistent formatting
 	ui.Successf("✓ Switched to session: %s", selected.Name)
-	fmt.Printf("Branch: %s\n", selected.Branch)
+	fmt.Printf("Branch: %s\n", selected.BranchLabel())
 	fmt.Printf("Location: %s\n", selected.Path)
 
 	// Output the cd command for shell evaluation
-	fmt.Printf("\ncd %s\n", selected.Path)
+	printCd(cmd, selected.Path)
 
 	// If shell integration is not active, show a helpful message
 	if !utils.IsShellIntegrationActive() {
@@ -75,3 +273,25 @@ func listSessions(cmd *cobra.Command, args []string) {
 		fmt.Println(utils.GetShellIntegrationInstructions())
 	}
 }
+
+// listRemoteSessions prints the sessions of a ccswitch serve instance,
+// filtered and formatted by report if it isn't nil
+func listRemoteSessions(cmd *cobra.Command, report *listReport) {
+	doc, host := fetchRemoteStatus(cmd)
+	if doc == nil {
+		return
+	}
+	if report != nil {
+		report.print(doc, fmt.Sprintf("Sessions of %s on %s", doc.Repo, host))
+		return
+	}
+
+	if len(doc.Sessions) == 0 {
+		ui.Infof("No active sessions on %s", host)
+		return
+	}
+
+	ui.Titlef("📂 Sessions of %s on %s", doc.Repo, host)
+	fmt.Println()
+	renderStatus(doc, nil, false)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+func TestListReport(t *testing.T) {
+	now := time.Date(2024, 5, 30, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	sessions := []schema.StatusSession{
+		{Name: "docs", Branch: "feature/docs", CreatedAt: at(40), LastActive: at(30)},
+		{Name: "auth", Branch: "feature/auth", Dirty: true, Ahead: 2, CreatedAt: at(10), LastUsed: at(1), Tags: []string{"backend"}},
+		{Name: "ui", Branch: "feature/ui", Ahead: 5, Behind: 1, CreatedAt: at(20), LastActive: at(3), Tags: []string{"frontend"}},
+		{Name: "main", Branch: "main"},
+	}
+
+	tests := []struct {
+		name     string
+		report   listReport
+		expected []string
+	}{
+		{"all", listReport{}, []string{"docs", "auth", "ui", "main"}},
+		{"dirty", listReport{dirty: true}, []string{"auth"}},
+		{"ahead by most", listReport{ahead: true, sort: "ahead"}, []string{"ui", "auth"}},
+		{"behind", listReport{behind: true}, []string{"ui"}},
+		{"tag", listReport{tag: "frontend"}, []string{"ui"}},
+		// Sessions without any known activity count as stale
+		{"stale", listReport{stale: 7 * 24 * time.Hour}, []string{"docs", "main"}},
+		{"name", listReport{sort: "name"}, []string{"auth", "docs", "main", "ui"}},
+		{"age", listReport{sort: "age"}, []string{"docs", "ui", "auth", "main"}},
+		{"activity", listReport{sort: "activity"}, []string{"auth", "ui", "docs", "main"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range tt.report.apply(sessions, now) {
+				got = append(got, s.Name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("apply() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addWaitFlag registers --wait on commands that take the repository lock
+func addWaitFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "Wait for other running ccswitch commands instead of failing")
+}
+
+// lockRepo takes the repository lock so that commands changing worktrees or
+// branches cannot run at the same time. With --wait it waits for the current
+// holder. It returns nil after printing the error if the lock is not taken.
+func lockRepo(cmd *cobra.Command, manager *session.Manager) *session.Lock {
+	command := cmd.CommandPath()
+
+	lock, err := manager.Lock(command)
+	if errors.IsLocked(err) {
+		if wait, _ := cmd.Flags().GetBool("wait"); wait {
+			ui.Infof("⏳ Waiting for lock: %v", err)
+			lock, err = manager.WaitLock(command)
+		}
+	}
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		if errors.IsLocked(err) {
+			printExplanation(cmd, lockedExplanation())
+		}
+		return nil
+	}
+	return lock
+}
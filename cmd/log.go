@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log [session]",
+		Short: "Show the commits of a session",
+		Long: `Show the commits on a session's branch that are not on its base branch:
+its stack parent, the branch it was created from, or else the current
+branch of the main repository. Use --base to compare with another branch.
+
+The commits are shown newest first in a scrollable view with their author,
+date and subject. Press enter to open the selected commit with 'git show'
+and y to copy its SHA. With --no-tui, or when output is not a terminal, the
+commits are printed one per line instead.
+
+Examples:
+  ccswitch log                    # Pick a session
+  ccswitch log fix-login
+  ccswitch log fix-login --base release/2.0`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               showSessionLog,
+	}
+
+	cmd.Flags().String("base", "", "Show commits not on this branch instead of the session's base branch")
+	_ = cmd.RegisterFlagCompletionFunc("base", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return localBranches(cmd), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func showSessionLog(cmd *cobra.Command, args []string) {
+	base, _ := cmd.Flags().GetString("base")
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "📜 Select session to show:")
+	if selected == nil {
+		return
+	}
+	if base == "" {
+		base = manager.BaseBranch(*selected)
+	}
+	if base == selected.Branch {
+		ui.Errorf("✗ %s is its own base branch; pass --base to compare with another branch", selected.Branch)
+		return
+	}
+
+	commits, err := git.GetCommits(selected.Path, base+".."+selected.Branch, time.Time{})
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	if noTUI || !ui.Interactive() {
+		if len(commits) == 0 {
+			ui.Infof("No commits on %s that are not on %s", selected.Branch, base)
+			return
+		}
+		ui.Infof("%d commit(s) on %s not on %s:", len(commits), selected.Branch, base)
+		ui.PrintLog(commits)
+		return
+	}
+
+	title := fmt.Sprintf("📜 %s: %d commit(s) not on %s", selected.Name, len(commits), base)
+	if err := ui.ShowLog(title, selected.Path, commits); err != nil {
+		ui.Errorf("✗ %v", err)
+	}
+}
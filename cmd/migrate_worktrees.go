@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateWorktreesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-worktrees",
+		Short: "Move existing sessions to the configured worktree root",
+		Long: `Move the worktrees of existing sessions to where worktree.root in the
+configuration says new sessions go, using 'git worktree move'. Session
+metadata and absolute symlinks inside the worktrees are updated, as with
+'ccswitch move'.
+
+worktree.root can be:
+  (empty)              ~/.ccswitch/worktrees/<repo>/<session> (the default)
+  sibling              <repo>-<session> next to the repository
+  /mnt/fast/worktrees  <dir>/<repo>/<session>; ~ and paths relative to the
+                       repository work too
+
+Stop programs running in a session, such as agents or dev servers, before
+moving it.
+
+Examples:
+  ccswitch migrate-worktrees --dry-run   # Show what would move
+  ccswitch migrate-worktrees`,
+		Args: cobra.NoArgs,
+		Run:  migrateWorktrees,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show which sessions would move without moving them")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func migrateWorktrees(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	mainRepoPath, err := git.GetMainRepoPath(currentDir)
+	if err != nil {
+		ui.Errorf("✗ Failed to find the main repository: %v", err)
+		return
+	}
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+
+	var toMove []git.SessionInfo
+	for _, s := range sessions {
+		if s.Path != mainRepoPath && s.Path != manager.GetSessionPath(s.Name) {
+			toMove = append(toMove, s)
+		}
+	}
+	if len(toMove) == 0 {
+		ui.Success("✓ All sessions are already in the configured worktree root")
+		return
+	}
+
+	if dryRun {
+		ui.Infof("Would move %d session(s):", len(toMove))
+		for _, s := range toMove {
+			fmt.Printf("  %s: %s → %s\n", s.Name, abbreviateHome(s.Path), abbreviateHome(manager.GetSessionPath(s.Name)))
+		}
+		return
+	}
+
+	moved := 0
+	cdPath := ""
+	for _, s := range toMove {
+		target := manager.GetSessionPath(s.Name)
+		if _, err := os.Stat(target); err == nil {
+			ui.Warningf("⚠ Skipped %s: %s already exists", s.Name, target)
+			continue
+		}
+
+		fixed, err := manager.MoveSession(s, target)
+		if err != nil {
+			ui.Errorf("✗ Failed to move %s: %v", s.Name, err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			continue
+		}
+
+		ui.Successf("✓ Moved %s → %s", s.Name, abbreviateHome(target))
+		if fixed > 0 {
+			ui.Infof("  Updated %d symlink(s)", fixed)
+		}
+		if isWithinDir(currentDir, s.Path) {
+			cdPath = target + strings.TrimPrefix(currentDir, s.Path)
+		}
+		moved++
+	}
+
+	fmt.Println()
+	ui.Infof("Moved %d of %d session(s)", moved, len(toMove))
+
+	// If we were inside a moved worktree, follow it
+	if cdPath != "" {
+		writeCd(cdPath)
+	}
+}
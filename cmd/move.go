@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newMoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move <session> <new-path>",
+		Short: "Move a session's worktree to a new location",
+		Long: `Move a session's worktree to a new location using 'git worktree move'.
+
+The session keeps its name and remains tracked by ccswitch after the move.
+Absolute symlinks inside the worktree that pointed into the old location are
+updated to point into the new one. The destination must not already exist and
+must not be inside another git repository.
+
+Examples:
+  ccswitch move my-feature /mnt/fast/my-feature
+  ccswitch move my-feature ~/scratch/my-feature`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeMove,
+		Run:               moveSession,
+	}
+
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func moveSession(cmd *cobra.Command, args []string) {
+	sessionName := args[0]
+	newPath := expandHome(args[1])
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	// Get sessions
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+
+	// Find the session
+	selected := findSession(sessions, sessionName)
+	if selected == nil {
+		ui.Errorf("✗ Session '%s' not found", sessionName)
+		ui.Infof("  Tip: %s", errors.ErrorHint(errors.ErrSessionNotFound))
+		return
+	}
+
+	if selected.Name == "main" {
+		ui.Error("✗ Cannot move the main repository")
+		return
+	}
+
+	ui.Infof("Moving %s → %s", selected.Path, newPath)
+
+	fixed, err := manager.MoveSession(*selected, newPath)
+	if err != nil {
+		ui.Errorf("✗ %s", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+
+	absPath, _ := filepath.Abs(newPath)
+	ui.Successf("✓ Moved session: %s", selected.Name)
+	ui.Infof("Location: %s", absPath)
+	if fixed > 0 {
+		ui.Infof("Updated %d symlink(s)", fixed)
+	}
+
+	// If we were inside the moved worktree, follow it
+	if isWithinDir(currentDir, selected.Path) {
+		writeCd(absPath + strings.TrimPrefix(currentDir, selected.Path))
+	}
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// abbreviateHome replaces the user's home directory at the start of path
+// with ~, for display
+func abbreviateHome(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" {
+		return path
+	}
+	if path == homeDir || strings.HasPrefix(path, homeDir+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(path, homeDir)
+	}
+	return path
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newNagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nag",
+		Short: "Warn about sessions with long-abandoned uncommitted changes",
+		Long: `Print a one-line warning when any session has had uncommitted changes for
+longer than a configurable duration. Prints nothing otherwise, and nothing
+outside a git repository, so it is safe to call from a shell prompt hook.
+
+Results are cached for nag.check_interval (default 5m) to keep the command
+fast. The threshold is nag.dirty_after (default 24h).
+
+To enable it, add to your ~/.bashrc or ~/.zshrc:
+  PROMPT_COMMAND="ccswitch nag; $PROMPT_COMMAND"   # bash
+  precmd() { ccswitch nag }                         # zsh
+
+Examples:
+  ccswitch nag
+  ccswitch nag --after 2h --no-cache`,
+		Args: cobra.NoArgs,
+		Run:  nagDirtySessions,
+	}
+
+	cmd.Flags().String("after", "", "Warn when changes are older than this (default: nag.dirty_after)")
+	cmd.Flags().Bool("no-cache", false, "Always scan sessions instead of using cached results")
+
+	return cmd
+}
+
+func nagDirtySessions(cmd *cobra.Command, args []string) {
+	afterFlag, _ := cmd.Flags().GetString("after")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+
+	// Prompt hooks run everywhere: stay silent outside repositories and on errors
+	currentDir, err := workingDir(cmd)
+	if err != nil || !git.IsGitRepository(currentDir) {
+		return
+	}
+
+	cfg := loadConfig(cmd)
+	if afterFlag == "" {
+		afterFlag = cfg.Nag.DirtyAfter
+	}
+	after, err := utils.ParseDuration(afterFlag)
+	if err != nil {
+		ui.Errorf("✗ Invalid dirty-after duration: %v", err)
+		return
+	}
+
+	var cacheFor time.Duration
+	if !noCache {
+		cacheFor, _ = utils.ParseDuration(cfg.Nag.CheckInterval)
+	}
+
+	manager := session.NewManager(currentDir)
+	dirty, err := manager.FindDirtySessions(after, cacheFor)
+	if err != nil || len(dirty) == 0 {
+		return
+	}
+
+	parts := make([]string, len(dirty))
+	for i, d := range dirty {
+		parts[i] = fmt.Sprintf("%s (%s)", d.Name, utils.FormatDurationShort(time.Since(d.DirtySince)))
+	}
+	ui.Warningf("⚠ ccswitch: uncommitted changes left in %s", strings.Join(parts, ", "))
+}
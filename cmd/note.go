@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newNoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "note <session> [text...]",
+		Short: "Add to or show the notes of a session",
+		Long: `Keep free-form notes with a session, such as what its agent is working on
+or what blocks it. Notes are shown by 'ccswitch status' and below the
+session picker for the session under the cursor.
+
+With text, it is added to the session's notes on a line of its own.
+Without text, the notes are printed. --edit opens them in $VISUAL or
+$EDITOR (vi if neither is set) to rewrite them, and --clear removes them.
+
+Examples:
+  ccswitch note auth "waiting on the API review"
+  ccswitch note auth
+  ccswitch note auth --edit
+  ccswitch note auth --clear`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               noteSession,
+	}
+
+	cmd.Flags().Bool("edit", false, "Edit the notes in $VISUAL or $EDITOR")
+	cmd.Flags().Bool("clear", false, "Remove the notes")
+	cmd.MarkFlagsMutuallyExclusive("edit", "clear")
+
+	return cmd
+}
+
+func noteSession(cmd *cobra.Command, args []string) {
+	edit, _ := cmd.Flags().GetBool("edit")
+	clear, _ := cmd.Flags().GetBool("clear")
+	if (edit || clear) && len(args) > 1 {
+		ui.Error("✗ --edit and --clear don't take text")
+		return
+	}
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		return
+	}
+
+	switch {
+	case clear:
+		if err := manager.SetNotes(*selected, ""); err != nil {
+			ui.Errorf("✗ Failed to clear the notes of %s: %v", selected.Name, err)
+			return
+		}
+		ui.Successf("✓ Cleared the notes of %s", selected.Name)
+
+	case edit:
+		notes, err := editText(manager.Notes(*selected), selected.Name+"-notes-*.txt")
+		if err != nil {
+			ui.Errorf("✗ Failed to edit the notes of %s: %v", selected.Name, err)
+			return
+		}
+		if err := manager.SetNotes(*selected, notes); err != nil {
+			ui.Errorf("✗ Failed to save the notes of %s: %v", selected.Name, err)
+			return
+		}
+		ui.Successf("✓ Saved the notes of %s", selected.Name)
+
+	case len(args) > 1:
+		if _, err := manager.AppendNote(*selected, strings.Join(args[1:], " ")); err != nil {
+			ui.Errorf("✗ Failed to add to the notes of %s: %v", selected.Name, err)
+			return
+		}
+		ui.Successf("✓ Added a note to %s", selected.Name)
+
+	default:
+		notes := manager.Notes(*selected)
+		if notes == "" {
+			ui.Infof("%s has no notes", selected.Name)
+			return
+		}
+		fmt.Print(indentNotes(notes))
+	}
+}
+
+// indentNotes indents each line of notes as status does. Notes come from
+// agents and imported sessions, so no line may start with "cd ": the shell
+// integration would run it.
+func indentNotes(notes string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(notes, "\n"), "\n") {
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
+
+// editText opens text in the user's editor in a temporary file named after
+// pattern and returns what the file holds once the editor exits
+func editText(text, pattern string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Editors are often set with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndentNotes(t *testing.T) {
+	notes := "cd /tmp && echo PWNED\nchecked the login flow\ncd ..\n"
+	output := indentNotes(notes)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "cd ") {
+			t.Errorf("indentNotes() printed %q, which the shell integration would run", line)
+		}
+	}
+	if !strings.Contains(output, "  checked the login flow\n") {
+		t.Errorf("indentNotes() = %q, expected every note line indented", output)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/notify"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// notifyDone sends the notifications set up in the config that operation,
+// started at started, finished with result, counting worktrees by outcome
+func notifyDone(cmd *cobra.Command, operation, result string, counts map[string]int, started time.Time) {
+	cfg := loadConfig(cmd)
+	notifier := notify.Notifier{Command: cfg.Notifications.Command, Webhook: cfg.Notifications.Webhook}
+	if !notifier.Enabled() {
+		return
+	}
+
+	var repo string
+	if dir, err := workingDir(cmd); err == nil {
+		if root, err := git.GetMainRepoPath(dir); err == nil {
+			repo = filepath.Base(root)
+		}
+	}
+
+	duration := time.Since(started)
+	doc := schema.Notification{
+		Header:          schema.NewHeader(),
+		Text:            notificationText(operation, repo, result, counts, duration),
+		Operation:       operation,
+		Repo:            repo,
+		Result:          result,
+		Counts:          counts,
+		DurationSeconds: duration.Seconds(),
+		Time:            time.Now().UTC(),
+	}
+	if err := notifier.Send(doc); err != nil {
+		ui.Warningf("⚠ Notification failed: %v", err)
+	}
+}
+
+// notificationText summarises an operation in a sentence, such as
+// "ccswitch fanout in project succeeded: 3 rebased, 1 skipped (2m10s)"
+func notificationText(operation, repo, result string, counts map[string]int, duration time.Duration) string {
+	text := "ccswitch " + operation
+	if repo != "" {
+		text += " in " + repo
+	}
+	text += " " + result
+
+	outcomes := make([]string, 0, len(counts))
+	for outcome := range counts {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Strings(outcomes)
+	for i, outcome := range outcomes {
+		outcomes[i] = fmt.Sprintf("%d %s", counts[outcome], strings.ReplaceAll(outcome, "_", " "))
+	}
+	if len(outcomes) > 0 {
+		text += ": " + strings.Join(outcomes, ", ")
+	}
+	return fmt.Sprintf("%s (%s)", text, duration.Round(time.Second))
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotificationText(t *testing.T) {
+	tests := []struct {
+		repo     string
+		result   string
+		counts   map[string]int
+		expected string
+	}{
+		{"project", "succeeded", map[string]int{"rebased": 3, "skipped": 1}, "ccswitch fanout in project succeeded: 3 rebased, 1 skipped (2m10s)"},
+		{"project", "interrupted", map[string]int{"rebased": 1, "interrupted": 1, "not_started": 2},
+			"ccswitch fanout in project interrupted: 1 interrupted, 2 not started, 1 rebased (2m10s)"},
+		{"", "failed", nil, "ccswitch fanout failed (2m10s)"},
+	}
+
+	for _, tt := range tests {
+		got := notificationText("fanout", tt.repo, tt.result, tt.counts, 130*time.Second+300*time.Millisecond)
+		if got != tt.expected {
+			t.Errorf("notificationText() = %q, expected %q", got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newOpenIssueCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "open-issue [session]",
+		Short: "Open a session's issue in the browser",
+		Long: `Open the task a session is tied to in the browser: the link given to
+'ccswitch create --link', or else the issue given to --issue at the address
+issues.url in the configuration gives it.
+
+Without a session, the session to open is picked from a list.
+
+Examples:
+  ccswitch open-issue
+  ccswitch open-issue fix-login`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               openIssue,
+	}
+}
+
+func openIssue(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to open the issue of:")
+	if selected == nil {
+		return
+	}
+
+	issue, url := manager.Issue(*selected)
+	switch {
+	case url != "":
+	case issue != "":
+		ui.Errorf("✗ %s works on %s, but there is no address for it", selected.Name, issue)
+		ui.Info("  Tip: Set issues.url in the configuration, e.g. https://example.atlassian.net/browse/{id}")
+		return
+	default:
+		ui.Errorf("✗ %s is not tied to an issue", selected.Name)
+		ui.Info("  Tip: Pass --issue or --link to 'ccswitch create' to tie new sessions to their task")
+		return
+	}
+
+	ui.Infof("Opening %s", url)
+	if err := openInBrowser(url); err != nil {
+		ui.Errorf("✗ Failed to open browser: %v", err)
+	}
+}
+
+// issueLabel describes the task of a session from its issue and the
+// address of its task, either of which may be empty
+func issueLabel(issue, url string) string {
+	switch {
+	case issue != "" && url != "":
+		return issue + " (" + url + ")"
+	case issue != "":
+		return issue
+	default:
+		return url
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newPickCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pick <session> [commit]",
+		Short: "Cherry-pick commits from a session onto the current branch",
+		Long: `Cherry-pick one or more commits from a session onto the current branch,
+without committing or rebasing anything else in the session.
+
+Without a commit, the session's commits that are not on the current branch
+are listed and you choose which to pick. A commit can also be a range such as
+HEAD~2.., resolved in the session's worktree. Picked commits are applied
+oldest first, and the pick is aborted if any of them conflicts.
+
+Examples:
+  ccswitch pick auth-fix                # Choose commits from a list
+  ccswitch pick auth-fix a1b2c3d        # Pick one commit
+  ccswitch pick auth-fix HEAD~2..       # Pick the session's last two commits
+  ccswitch pick auth-fix --no-commit    # Stage the changes instead of committing`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completePick,
+		Run:               pickCommits,
+	}
+
+	cmd.Flags().BoolP("no-commit", "n", false, "Stage the picked changes without committing them")
+	addSignFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func pickCommits(cmd *cobra.Command, args []string) {
+	noCommit, _ := cmd.Flags().GetBool("no-commit")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+	applySignFlag(cmd, manager)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	currentBranch, err := manager.GetCurrentBranch()
+	if err != nil {
+		ui.Errorf("✗ Failed to get current branch: %v", err)
+		return
+	}
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		return
+	}
+	if selected.Branch == currentBranch {
+		ui.Errorf("✗ Cannot pick commits from %s onto itself", currentBranch)
+		return
+	}
+
+	var hashes []string
+	if len(args) > 1 {
+		if hashes, err = git.ResolveCommits(selected.Path, args[1]); err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+	} else {
+		commits, err := git.GetCommits(selected.Path, currentBranch+".."+selected.Branch, time.Time{})
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		if len(commits) == 0 {
+			ui.Infof("%s has no commits that are not on %s", selected.Name, currentBranch)
+			return
+		}
+
+		noTUI, _ := cmd.Flags().GetBool("no-tui")
+		chosen, err := ui.PickCommits(commits, fmt.Sprintf("🍒 Pick commits from %s:", selected.Name), noTUI)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			return
+		}
+		if len(chosen) == 0 {
+			return // User quit
+		}
+
+		// The list is newest first; apply oldest first
+		for i := len(chosen) - 1; i >= 0; i-- {
+			hashes = append(hashes, chosen[i].Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		ui.Errorf("✗ No commits in %s", args[1])
+		return
+	}
+
+	ui.Infof("Picking %d commit(s) from %s onto %s", len(hashes), selected.Name, currentBranch)
+	fmt.Println()
+
+	if err := manager.PickCommits(hashes, noCommit); err != nil {
+		ui.Errorf("✗ Failed: %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		if errors.IsCherryPickConflict(err) {
+			printConflict(err)
+			logArgs := "--no-walk " + strings.Join(shortHashes(hashes), " ")
+			printExplanation(cmd, cherryPickConflictExplanation(currentDir, currentBranch, selected.Path, logArgs))
+		}
+		return
+	}
+
+	if noCommit {
+		ui.Successf("✓ Staged the changes of %d commit(s) from %s on %s", len(hashes), selected.Name, currentBranch)
+		ui.Info("  Review them with 'git diff --cached' and commit when ready")
+		return
+	}
+	ui.Successf("✓ Picked %d commit(s) from %s onto %s", len(hashes), selected.Name, currentBranch)
+}
+
+// shortHashes abbreviates commit hashes for display
+func shortHashes(hashes []string) []string {
+	short := make([]string, len(hashes))
+	for i, h := range hashes {
+		if len(h) > 7 {
+			h = h[:7]
+		}
+		short[i] = h
+	}
+	return short
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newPinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <session>",
+		Short: "Protect a session from cleanup",
+		Long: `Pin a session to protect it: cleanup never removes it, whether it is named,
+checked in the picker or included by --all, not even with --force, and
+prune-artifacts leaves its build artifacts alone. Pinned sessions are
+marked with 📌 in status and list.
+
+'ccswitch unpin' removes the protection.
+
+Examples:
+  ccswitch pin release-notes
+  ccswitch unpin release-notes`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSession,
+		Run: func(cmd *cobra.Command, args []string) {
+			pinSession(cmd, args, true)
+		},
+	}
+}
+
+func newUnpinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "unpin <session>",
+		Short:             "Allow a pinned session to be cleaned up again",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSession,
+		Run: func(cmd *cobra.Command, args []string) {
+			pinSession(cmd, args, false)
+		},
+	}
+}
+
+func pinSession(cmd *cobra.Command, args []string, pin bool) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "")
+	if selected == nil {
+		return
+	}
+
+	if err := manager.SetPinned(*selected, pin); err != nil {
+		ui.Errorf("✗ Failed to update %s: %v", selected.Name, err)
+		return
+	}
+	if pin {
+		ui.Successf("✓ Pinned %s: cleanup and prune-artifacts will skip it", selected.Name)
+	} else {
+		ui.Successf("✓ Unpinned %s", selected.Name)
+	}
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix starts the names of executables that add commands to
+// ccswitch, as git does with git-<name>
+const pluginPrefix = "ccswitch-"
+
+const (
+	commandsGroup = "commands"
+	pluginsGroup  = "plugins"
+)
+
+const pluginsHelp = `Plugins add commands to ccswitch. Any executable on PATH named
+ccswitch-<name> runs as 'ccswitch <name>', e.g. ccswitch-deploy as
+'ccswitch deploy', and is listed with the other commands in 'ccswitch help'.
+Built-in commands and aliases (see alias in the config) take precedence over
+plugins of the same name, and the first of several plugins of the same name
+on PATH is used.
+
+A plugin runs in the current directory, with its standard input and output,
+and receives the arguments after its name unchanged. Its exit code becomes
+ccswitch's. It sees:
+
+  CCSWITCH_BIN          The ccswitch executable, to call back into it
+  CCSWITCH_REPO         The main repository, when run inside one
+  CCSWITCH_SESSION      The session the current directory is in, with
+  CCSWITCH_BRANCH       CCSWITCH_BRANCH, CCSWITCH_WORKTREE, CCSWITCH_BASE_BRANCH
+  ...                   and the session's ports and variables as exec sets
+                        them; unset outside a session
+
+Through the shell integration, a plugin can switch the shell to a directory
+by printing a line 'cd <path>', quoting the path for the shell if it has
+spaces. The integration runs the last line of output starting with 'cd ',
+wherever it is, so no other line should start that way.
+
+Go programs that build their own ccswitch can compile commands in instead:
+call cmd.RegisterPlugin with a cobra command from an init function before
+cmd.Execute, and use the github.com/ksred/ccswitch/pkg/ccswitch package to
+work with sessions.
+
+Examples:
+  printf '#!/bin/sh\nexec make deploy SESSION="$CCSWITCH_SESSION"\n' > ~/bin/ccswitch-deploy
+  chmod +x ~/bin/ccswitch-deploy
+  ccswitch deploy`
+
+// compiledPlugins are the commands added with RegisterPlugin
+var compiledPlugins []*cobra.Command
+
+// RegisterPlugin adds command to ccswitch as a plugin, for Go programs that
+// build their own ccswitch: call it before Execute, e.g. from an init
+// function. A command named like a built-in command is ignored.
+func RegisterPlugin(command *cobra.Command) {
+	compiledPlugins = append(compiledPlugins, command)
+}
+
+func newPluginsHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "plugins",
+		Short: "Adding commands with ccswitch-<name> executables",
+		Long:  pluginsHelp,
+	}
+}
+
+// needsPlugins reports whether running args can involve a plugin command,
+// so that PATH isn't searched for commands that are built in
+func needsPlugins(root *cobra.Command, args []string) bool {
+	if _, _, err := root.Find(args); err != nil {
+		return true
+	}
+	return slices.Contains(args, "-h") || slices.Contains(args, "--help")
+}
+
+// findPlugins returns the executables named ccswitch-<name> in dirs by name,
+// the first one found for each name
+func findPlugins(dirs []string) map[string]string {
+	plugins := make(map[string]string)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || plugins[name] != "" {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				plugins[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the command a plugin executable file adds, without the
+// extension Windows runs it by
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, pluginPrefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !slices.ContainsFunc(executableExts(), func(e string) bool { return strings.EqualFold(e, ext) }) {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, name != "" && !strings.HasPrefix(name, "-")
+}
+
+// executableExts returns the extensions of files Windows runs
+func executableExts() []string {
+	if exts := os.Getenv("PATHEXT"); exts != "" {
+		return filepath.SplitList(exts)
+	}
+	return []string{".com", ".exe", ".bat", ".cmd"}
+}
+
+// isExecutable reports whether path is a file that can be run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// pluginCommands returns the commands running the executables in plugins
+func pluginCommands(plugins map[string]string) []*cobra.Command {
+	commands := make([]*cobra.Command, 0, len(plugins))
+	for name, path := range plugins {
+		commands = append(commands, newPluginCmd(name, path))
+	}
+	return commands
+}
+
+// addPlugins adds commands to root unless a command of the same name
+// exists, listing them apart from the built-in commands in help
+func addPlugins(root *cobra.Command, commands []*cobra.Command) {
+	for _, c := range commands {
+		if isCommand(root, c.Name()) {
+			continue
+		}
+		if !root.ContainsGroup(pluginsGroup) {
+			groupCommands(root)
+		}
+		c.GroupID = pluginsGroup
+		root.AddCommand(c)
+	}
+}
+
+// isCommand reports whether name or one of its aliases is a command of
+// root, including those cobra adds itself
+func isCommand(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupCommands puts the commands of root in a group of built-in commands,
+// so that help lists plugins in a group of their own after them
+func groupCommands(root *cobra.Command) {
+	root.AddGroup(
+		&cobra.Group{ID: commandsGroup, Title: "Available Commands:"},
+		&cobra.Group{ID: pluginsGroup, Title: "Plugin Commands:"},
+	)
+	for _, c := range root.Commands() {
+		if c.GroupID == "" {
+			c.GroupID = commandsGroup
+		}
+	}
+	root.SetHelpCommandGroupID(commandsGroup)
+	root.SetCompletionCommandGroupID(commandsGroup)
+}
+
+func newPluginCmd(name, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:   name,
+		Short: "Plugin: " + path,
+		Long: `Run the plugin at ` + path + `, passing it the
+arguments unchanged. See 'ccswitch help plugins'.`,
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			runPlugin(path, args)
+		},
+	}
+}
+
+func runPlugin(path string, args []string) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		ui.Errorf("✗ Failed to get current directory: %v", err)
+		os.Exit(1)
+	}
+
+	if err := executeInDir(currentDir, path, args, pluginEnv(currentDir)); err != nil {
+		code := proc.ExitCode(err)
+		if code == 127 {
+			ui.Errorf("✗ %v", err)
+		}
+		os.Exit(code)
+	}
+}
+
+// pluginEnv returns the environment of a plugin run in dir: the variables
+// describing the repository and the session dir is in, replacing any an
+// outer ccswitch left for another session
+func pluginEnv(dir string) []string {
+	described := []string{"SESSION", "BRANCH", "WORKTREE", "BASE_BRANCH", "REPO", "BIN"}
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		name, _, _ := strings.Cut(kv, "=")
+		suffix, ok := strings.CutPrefix(name, session.EnvPrefix)
+		return ok && slices.Contains(described, suffix)
+	})
+
+	if bin, err := os.Executable(); err == nil {
+		env = append(env, session.EnvPrefix+"BIN="+bin)
+	}
+	root, err := git.GetMainRepoPath(dir)
+	if err != nil {
+		return env
+	}
+	env = append(env, session.EnvPrefix+"REPO="+root)
+
+	manager := session.NewManager(dir)
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		return env
+	}
+	if current := currentSession(sessions, dir); current != nil {
+		env = manager.Env(env, *current)
+	}
+	return env
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses executable bits")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(first, "ccswitch-deploy"):  0755,
+		filepath.Join(first, "ccswitch-status"):  0755,
+		filepath.Join(first, "ccswitch-notes"):   0644,
+		filepath.Join(second, "ccswitch-deploy"): 0755,
+		filepath.Join(second, "ccswitch-lint"):   0755,
+		filepath.Join(second, "other-tool"):      0755,
+	} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plugins := findPlugins([]string{first, "", second})
+	expected := map[string]string{
+		"deploy": filepath.Join(first, "ccswitch-deploy"),
+		"status": filepath.Join(first, "ccswitch-status"),
+		"lint":   filepath.Join(second, "ccswitch-lint"),
+	}
+	if len(plugins) != len(expected) {
+		t.Errorf("findPlugins() = %v, expected %v", plugins, expected)
+	}
+	for name, path := range expected {
+		if plugins[name] != path {
+			t.Errorf("findPlugins()[%q] = %q, expected %q", name, plugins[name], path)
+		}
+	}
+
+	root := NewRootCmd()
+	if needsPlugins(root, []string{"status"}) {
+		t.Error("needsPlugins(status) = true, expected built-in commands to skip the PATH search")
+	}
+	if !needsPlugins(root, []string{"deploy", "--prod"}) {
+		t.Error("needsPlugins(deploy) = false, expected unknown commands to search PATH")
+	}
+
+	addPlugins(root, pluginCommands(plugins))
+	deploy, _, err := root.Find([]string{"deploy", "--prod"})
+	if err != nil || deploy.GroupID != pluginsGroup {
+		t.Errorf("Find(deploy) = %v, %v, expected the plugin command", deploy, err)
+	}
+	if status, _, _ := root.Find([]string{"status"}); status.GroupID != commandsGroup || status.Short == "Plugin: "+plugins["status"] {
+		t.Errorf("Find(status) = %q, expected the built-in command to take precedence", status.Short)
+	}
+
+	// Compiled plugins are grouped the same way
+	addPlugins(root, []*cobra.Command{{Use: "audit", Run: func(*cobra.Command, []string) {}}})
+	if audit, _, err := root.Find([]string{"audit"}); err != nil || audit.GroupID != pluginsGroup {
+		t.Errorf("Find(audit) = %v, %v, expected the compiled plugin", audit, err)
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/fanout"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addPorcelainFlag adds the --porcelain flag to commands that can report
+// their progress as events
+func addPorcelainFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("porcelain", false, "Print progress as line-delimited JSON events for other tools")
+}
+
+// eventStream prints the --porcelain events of an operation
+type eventStream struct {
+	operation string
+	out       io.Writer
+}
+
+// porcelainEvents returns the event stream if --porcelain is set, or nil.
+// Events then have stdout to themselves: messages are limited to errors,
+// which go to stderr.
+func porcelainEvents(cmd *cobra.Command, operation string) *eventStream {
+	if porcelain, _ := cmd.Flags().GetBool("porcelain"); !porcelain {
+		return nil
+	}
+	ui.SetQuiet(true)
+	ui.UseStderr()
+	return &eventStream{operation: operation, out: os.Stdout}
+}
+
+// emit prints event, stamped with the operation and the current time
+func (s *eventStream) emit(event schema.Event) {
+	event.Header = schema.NewHeader()
+	event.Operation = s.operation
+	event.Time = time.Now().UTC()
+	if err := schema.WriteLine(s.out, event); err != nil {
+		ui.Errorf("✗ %v", err)
+	}
+}
+
+// worktree prints an event about rebasing wt onto a branch
+func (s *eventStream) worktree(event string, wt git.Worktree, onto, reason string) {
+	s.emit(schema.Event{Event: event, Branch: wt.Branch, Path: wt.Path, Onto: onto, Reason: reason})
+}
+
+// done ends the stream with the number of worktrees by outcome
+func (s *eventStream) done(counts map[string]int) {
+	s.emit(schema.Event{Event: schema.EventDone, Counts: counts})
+}
+
+// rebase prints the outcome of rebasing wt onto a branch, which failed
+// with err unless it is nil. A failure ends the stream.
+func (s *eventStream) rebase(wt git.Worktree, onto string, err error) {
+	event := schema.EventRebased
+	var reason string
+	switch {
+	case err == nil:
+	case errors.IsRebaseConflict(err):
+		event, reason = schema.EventConflicted, err.Error()
+	case errors.IsVerifyFailed(err):
+		event, reason = schema.EventVerifyFailed, err.Error()
+	case errors.IsInterrupted(err):
+		event, reason = schema.EventInterrupted, err.Error()
+	default:
+		event, reason = schema.EventFailed, err.Error()
+	}
+	s.emit(schema.Event{Event: event, Branch: wt.Branch, Path: wt.Path, Onto: onto, Reason: reason, Conflict: conflictReport(err)})
+	if err != nil {
+		s.done(map[string]int{event: 1})
+	}
+}
+
+// porcelainFanoutObserver reports fanout progress as events
+type porcelainFanoutObserver struct {
+	events *eventStream
+	engine *fanout.Engine
+}
+
+func (o *porcelainFanoutObserver) OnTargetStart(wt git.Worktree) {
+	o.events.worktree(schema.EventStarted, wt, o.engine.Onto(wt), "")
+}
+
+func (o *porcelainFanoutObserver) OnVerify(wt git.Worktree) {
+	o.events.worktree(schema.EventVerifying, wt, o.engine.Onto(wt), "")
+}
+
+// OnConflict is reported by OnTargetDone, which follows it
+func (o *porcelainFanoutObserver) OnConflict(result fanout.Result) {}
+
+func (o *porcelainFanoutObserver) OnTargetDone(result fanout.Result) {
+	event, reason := fanoutEvent(result)
+	wt := result.Worktree
+	o.events.emit(schema.Event{Event: event, Branch: wt.Branch, Path: wt.Path, Onto: o.engine.Onto(wt), Reason: reason, Conflict: conflictReport(result.Err)})
+}
+
+// conflictReport returns what conflicted in the rebase err reports, or nil
+// if it did not stop on a conflict
+func conflictReport(err error) *schema.Conflict {
+	conflict := git.Conflict(err)
+	if conflict == nil {
+		return nil
+	}
+	return &schema.Conflict{Commit: conflict.Commit, Files: conflict.Files, Diff: conflict.Diff}
+}
+
+// fanoutEvent returns the event for the outcome of a fanout target and
+// the error that caused it, if any
+func fanoutEvent(result fanout.Result) (string, string) {
+	var reason string
+	if result.Err != nil {
+		reason = result.Err.Error()
+	}
+	switch result.Status {
+	case fanout.StatusSucceeded:
+		return schema.EventRebased, reason
+	case fanout.StatusConflicted:
+		return schema.EventConflicted, reason
+	case fanout.StatusSkipped:
+		return schema.EventSkipped, reason
+	case fanout.StatusInterrupted:
+		return schema.EventInterrupted, reason
+	case fanout.StatusVerifyFailed:
+		return schema.EventVerifyFailed, reason
+	default:
+		return schema.EventFailed, reason
+	}
+}
+
+// fanoutCounts counts the fanout results by event, with pending targets
+// the fanout stopped before as not started
+func fanoutCounts(results []fanout.Result, skipped int) map[string]int {
+	counts := make(map[string]int)
+	if skipped > 0 {
+		counts[schema.EventSkipped] = skipped
+	}
+	for _, r := range results {
+		if r.Status == fanout.StatusPending {
+			counts[schema.NotStarted]++
+			continue
+		}
+		event, _ := fanoutEvent(r)
+		counts[event]++
+	}
+	return counts
+}
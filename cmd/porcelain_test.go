@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/fanout"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+func TestEventStreamRebase(t *testing.T) {
+	wt := git.Worktree{Branch: "feature", Path: "/w/feature"}
+	tests := []struct {
+		name     string
+		err      error
+		expected []string
+	}{
+		{"rebased", nil, []string{schema.EventRebased}},
+		{"conflicted", errors.Wrap(errors.ErrRebaseConflict, "feature"), []string{schema.EventConflicted, schema.EventDone}},
+		{"interrupted", fmt.Errorf("git rebase: %w", errors.ErrInterrupted), []string{schema.EventInterrupted, schema.EventDone}},
+		{"failed", fmt.Errorf("exit status 1"), []string{schema.EventFailed, schema.EventDone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			events := &eventStream{operation: "rebase", out: &buf}
+			events.rebase(wt, "main", tt.err)
+
+			var got []string
+			for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+				var event schema.Event
+				if err := json.Unmarshal(line, &event); err != nil {
+					t.Fatalf("invalid event %q: %v", line, err)
+				}
+				if event.Operation != "rebase" || event.Schema != schema.Version {
+					t.Errorf("event %q lacks the operation or schema", line)
+				}
+				if event.Event != schema.EventDone && (event.Branch != "feature" || event.Onto != "main") {
+					t.Errorf("event %q lacks the worktree", line)
+				}
+				got = append(got, event.Event)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("events = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFanoutCounts(t *testing.T) {
+	results := []fanout.Result{
+		{Status: fanout.StatusSucceeded},
+		{Status: fanout.StatusSucceeded},
+		{Status: fanout.StatusConflicted},
+		{Status: fanout.StatusPending},
+	}
+	expected := map[string]int{
+		schema.EventRebased:    2,
+		schema.EventConflicted: 1,
+		schema.EventSkipped:    1,
+		schema.NotStarted:      1,
+	}
+	if got := fanoutCounts(results, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("fanoutCounts = %v, expected %v", got, expected)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/github"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
@@ -24,9 +25,9 @@ func newPRCmd() *cobra.Command {
 
 func createPullRequest(cmd *cobra.Command, args []string) {
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
@@ -105,7 +106,8 @@ func createPullRequest(cmd *cobra.Command, args []string) {
 
 	// Create PR using gh CLI
 	ui.Info("📝 Creating pull request...")
-	prURL, err := createPRWithGH(currentDir, currentSession.Name)
+	issue, _ := manager.Issue(*currentSession)
+	prURL, err := createPRWithGH(currentDir, currentSession.Name, issue)
 	if err != nil {
 		ui.Errorf("✗ Failed to create PR: %v", err)
 		return
@@ -128,16 +130,11 @@ func isGitHubCLIAvailable() bool {
 }
 
 func checkBranchHasCommits(dir, branch string) (bool, error) {
-	cmd := exec.Command("git", "rev-list", "--count", "main.."+branch) // #nosec G204
-	cmd.Dir = dir
-
-	output, err := cmd.Output()
+	count, err := git.CountCommits(dir, "main.."+branch)
 	if err != nil {
 		return false, err
 	}
-
-	count := strings.TrimSpace(string(output))
-	return count != "0", nil
+	return count != 0, nil
 }
 
 func pushBranch(dir, branch string) error {
@@ -149,12 +146,12 @@ func pushBranch(dir, branch string) error {
 	return cmd.Run()
 }
 
-func createPRWithGH(dir, sessionName string) (string, error) {
+func createPRWithGH(dir, sessionName, issue string) (string, error) {
 	// Generate PR title from session name
 	title := strings.ReplaceAll(sessionName, "-", " ")
 	title = cases.Title(language.English).String(title)
 
-	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body", "Created from ccswitch session: "+sessionName, "--web")
+	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body", prBody(sessionName, issue), "--web")
 	cmd.Dir = dir
 
 	output, err := cmd.Output()
@@ -174,6 +171,16 @@ func createPRWithGH(dir, sessionName string) (string, error) {
 	return string(output), nil
 }
 
+// prBody returns the pull request description of a session, closing its
+// issue when the session works on a GitHub issue
+func prBody(sessionName, issue string) string {
+	body := "Created from ccswitch session: " + sessionName
+	if number, ok := github.ParseRef(issue); ok && strings.HasPrefix(issue, "#") {
+		body += fmt.Sprintf("\n\nCloses #%d", number)
+	}
+	return body
+}
+
 func openInBrowser(url string) error {
 	var cmd *exec.Cmd
 
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/format"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat is what prompt prints without --format
+const defaultPromptFormat = `{{.Name}} ({{or .Branch .Commit}}{{if .Dirty}}*{{end}})`
+
+func newPromptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print the current session for a shell prompt",
+		Long: `Print the session the current directory is in, with its branch and a * when
+it has uncommitted changes, e.g. "fix-login (feature/fix-login*)". Prints
+nothing outside a session, including in the main worktree, so it can be
+embedded in any prompt.
+
+Finding the session reads git's files without running git. Only whether
+the worktree is dirty needs git, and the answer is cached for
+prompt.cache_ttl (default 5s), or until a commit, checkout or 'git add'
+changes HEAD or the index. Set prompt.cache_ttl to 0, or pass --no-cache,
+to ask git every time.
+
+--format takes a template of .Name, .Branch, .Commit, .Dirty, .Repo and
+.Path; see 'ccswitch help format'.
+
+For bash or zsh (with setopt prompt_subst):
+  PS1='$(ccswitch prompt) '"$PS1"
+
+For starship, in ~/.config/starship.toml:
+  [custom.ccswitch]
+  command = "ccswitch prompt"
+  when = true
+  require_repo = true
+  format = "[$output]($style) "
+
+Examples:
+  ccswitch prompt
+  ccswitch prompt --format '{{.Name}}{{if .Dirty}} ●{{end}}'
+  ccswitch prompt --no-cache`,
+		Args: cobra.NoArgs,
+		// Prompts render before every command line, so skip the
+		// repository registration and git setup other commands start with
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run:              showPrompt,
+	}
+
+	cmd.Flags().Bool("no-cache", false, "Ask git whether the worktree is dirty instead of using the cached answer")
+	supportsFormat(cmd)
+
+	return cmd
+}
+
+func showPrompt(cmd *cobra.Command, args []string) {
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+
+	tmpl, err := outputFormat(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if tmpl == nil {
+		tmpl = template.Must(format.Parse(defaultPromptFormat))
+	}
+
+	// Stay silent outside sessions and on errors, like nag
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		return
+	}
+	wt, ok := git.FindLinkedWorktree(currentDir)
+	if !ok {
+		return
+	}
+
+	var ttl time.Duration
+	if !noCache {
+		cfg, _ := config.LoadForRepo(filepath.Dir(wt.CommonDir))
+		ttl = promptCacheTTL(cfg)
+	}
+
+	if err := tmpl.Execute(os.Stdout, session.Prompt(wt, ttl)); err != nil {
+		ui.Errorf("✗ %v", err)
+	}
+}
+
+// promptCacheTTL returns prompt.cache_ttl, or its default if it is not set
+// or invalid
+func promptCacheTTL(cfg *config.Config) time.Duration {
+	text := cfg.Prompt.CacheTTL
+	if text == "" {
+		text = config.DefaultPromptCacheTTL
+	}
+	ttl, err := utils.ParseDuration(text)
+	if err != nil {
+		ttl, _ = utils.ParseDuration(config.DefaultPromptCacheTTL)
+	}
+	return ttl
+}
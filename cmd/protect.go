@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addForceFlag registers --force on commands that rewrite branches
+func addForceFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("force", false, "Allow rewriting protected branches after typing each branch name to confirm")
+}
+
+// guardProtected refuses to rewrite branches listed in git.protected_branches.
+// With --force, each protected branch must be confirmed by typing its name.
+// It reports whether the command may go ahead.
+func guardProtected(cmd *cobra.Command, branches []string) bool {
+	cfg := loadConfig(cmd)
+
+	var protected []string
+	for _, branch := range branches {
+		if cfg.IsProtectedBranch(branch) {
+			protected = append(protected, branch)
+		}
+	}
+	if len(protected) == 0 {
+		return true
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	if !force {
+		for _, branch := range protected {
+			ui.Errorf("✗ Refusing to rewrite protected branch %s", branch)
+		}
+		ui.Info("  Tip: Protected branches are set with git.protected_branches; pass --force to override")
+		printExplanation(cmd, protectedExplanation(strings.Join(protected, ", ")))
+		return false
+	}
+
+	for _, branch := range protected {
+		ui.Warningf("⚠ %s is a protected branch", branch)
+		fmt.Print("Type the branch name to confirm: ")
+
+		var typed string
+		fmt.Scanln(&typed)
+		if typed != branch {
+			ui.Info("Confirmation did not match, aborting")
+			return false
+		}
+	}
+	return true
+}
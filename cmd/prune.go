@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newPruneArtifactsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune-artifacts",
+		Short: "Delete build artifact directories in inactive sessions",
+		Long: `Delete build artifact directories (node_modules, target, dist, ...) in
+sessions you are not currently working in, to reclaim disk space.
+
+The directory names are configured with prune.artifact_dirs in the config file.
+Only directories ignored by git are removed, so tracked files are never touched.
+The main repository, pinned sessions (see 'ccswitch pin') and the session
+containing the current directory are skipped.
+
+Examples:
+  ccswitch prune-artifacts             # Show what would be removed and confirm
+  ccswitch prune-artifacts --dry-run   # Only report reclaimable space
+  ccswitch prune-artifacts --yes       # Remove without confirmation`,
+		Run: pruneArtifacts,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Only show what would be removed")
+	cmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func pruneArtifacts(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	cfg := loadConfig(cmd)
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	// Get sessions
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+
+	// Find artifact directories in inactive sessions
+	pinned := manager.Pinned()
+	var inactive []git.SessionInfo
+	for _, s := range sessions {
+		if s.Name == "main" || pinned[s.Path] || isWithinDir(currentDir, s.Path) {
+			continue
+		}
+		inactive = append(inactive, s)
+	}
+
+	if len(inactive) == 0 {
+		ui.Info("No inactive sessions to prune")
+		return
+	}
+
+	artifacts := make(map[string][]string)
+	var allDirs []string
+	for _, s := range inactive {
+		dirs := session.FindArtifactDirs(s.Path, cfg.Prune.ArtifactDirs)
+		artifacts[s.Name] = dirs
+		allDirs = append(allDirs, dirs...)
+	}
+
+	if len(allDirs) == 0 {
+		ui.Infof("No artifact directories (%s) found in inactive sessions", strings.Join(cfg.Prune.ArtifactDirs, ", "))
+		return
+	}
+
+	sizes := utils.DirSizes(allDirs)
+
+	ui.Title("🧹 Artifact directories in inactive sessions:")
+	fmt.Println()
+
+	var total int64
+	for _, s := range inactive {
+		dirs := artifacts[s.Name]
+		if len(dirs) == 0 {
+			continue
+		}
+		ui.Infof("  %s (%s)", s.Name, s.Branch)
+		for _, dir := range dirs {
+			rel, _ := filepath.Rel(s.Path, dir)
+			fmt.Printf("     %-40s %s\n", rel, utils.FormatBytes(sizes[dir]))
+			total += sizes[dir]
+		}
+	}
+
+	fmt.Println()
+	ui.Infof("Reclaimable: %s in %d directories", utils.FormatBytes(total), len(allDirs))
+
+	if dryRun {
+		return
+	}
+
+	if !skipConfirm {
+		fmt.Println()
+		fmt.Print("Delete these directories? (yes/no): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if strings.ToLower(confirm) != "yes" {
+			ui.Info("Prune cancelled")
+			return
+		}
+	}
+
+	var reclaimed int64
+	failed := 0
+	for _, dir := range allDirs {
+		if err := os.RemoveAll(dir); err != nil {
+			ui.Errorf("✗ Failed to remove %s: %v", dir, err)
+			failed++
+			continue
+		}
+		reclaimed += sizes[dir]
+	}
+
+	if failed == 0 {
+		ui.Successf("✓ Reclaimed %s", utils.FormatBytes(reclaimed))
+	} else {
+		ui.Infof("Reclaimed %s, %d directories could not be removed", utils.FormatBytes(reclaimed), failed)
+	}
+}
+
+// isWithinDir reports whether path is dir or is located inside it
+func isWithinDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
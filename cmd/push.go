@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addPushFlag registers --push on commands that rewrite branches
+func addPushFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("push", false, "Force-push rebased branches to their upstream (--force-with-lease); default: git.auto_push")
+}
+
+// pushEnabled reports whether rebased branches should be pushed: --push when
+// given, otherwise the auto_push setting
+func pushEnabled(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("push") {
+		push, _ := cmd.Flags().GetBool("push")
+		return push
+	}
+	cfg := loadConfig(cmd)
+	return cfg.Git.AutoPush
+}
+
+// resolveUpstreams looks up the upstream of every branch before anything is
+// rewritten. If any branch has no upstream, the refusal is reported and
+// false is returned.
+func resolveUpstreams(dir string, branches []string) (map[string]git.Upstream, bool) {
+	upstreams := make(map[string]git.Upstream, len(branches))
+	var missing []string
+	for _, branch := range branches {
+		upstream, err := git.GetUpstream(dir, branch)
+		if err != nil {
+			missing = append(missing, branch)
+			continue
+		}
+		upstreams[branch] = upstream
+	}
+
+	if len(missing) > 0 {
+		ui.Errorf("✗ Refusing to push: %s for %s", errors.ErrNoUpstream, strings.Join(missing, ", "))
+		ui.Infof("  Tip: %s, or pass --push=false", errors.ErrorHint(errors.ErrNoUpstream))
+		return nil, false
+	}
+	return upstreams, true
+}
+
+// pushBranches force-pushes branches to their upstreams and prints which
+// remotes were updated
+func pushBranches(dir string, branches []string, upstreams map[string]git.Upstream) {
+	if len(branches) == 0 {
+		return
+	}
+
+	ui.Info("Pushing rebased branches...")
+	updated := make(map[string][]string)
+	for _, branch := range branches {
+		upstream := upstreams[branch]
+		err := ui.WithProgress("Pushing "+branch, func(out io.Writer) error {
+			return git.PushWithLease(dir, branch, upstream, out)
+		})
+		if err != nil {
+			ui.Errorf("  ✗ %v", err)
+			continue
+		}
+		updated[upstream.Remote] = append(updated[upstream.Remote], branch+" → "+upstream.String())
+	}
+
+	remotes := make([]string, 0, len(updated))
+	for remote := range updated {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	for _, remote := range remotes {
+		ui.Successf("✓ Updated %s:", remote)
+		for _, line := range updated[remote] {
+			ui.Infof("    %s", line)
+		}
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/errors"
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -28,28 +30,137 @@ The rebase will:
 2. Stage and commit all changes in the worktree
 3. Rebase the commit onto the current branch
 4. Automatically abort if conflicts are detected
+5. Run git.verify_command, if set, on the result, resetting the current
+   branch to where it was if the command fails
+
+With "commit_style: conventional" in the git config section, an interactive
+wizard builds a conventional commit message. Scripts can pass --type, --scope
+and -m to build it non-interactively.
+
+With --ai-message, the commit message is written by git.message_command,
+such as an LLM CLI, which is given the diff of the changes on stdin. The
+message is shown to accept, edit or reject before anything is committed.
+
+To bring over all of a worktree's commits as one, --squash squashes them and
+its uncommitted changes into a single commit with the message you give,
+then rebases that commit. The worktree's branch is rewritten; its tip
+before the squash is kept in refs/ccswitch/backup/<branch>. With
+--keep-author, or "keep_author: true" in the git config section, the
+squashed commit keeps the author of the first commit, and the authors of
+the others are credited with Co-authored-by trailers.
+
+The commits rebase creates credit everyone in the git config section's
+co_authors list, and anyone given with --co-author, with a Co-authored-by
+trailer, e.g. an agent or a pair-programming partner.
+
+To bring over only some commits:
+  --interactive  Runs 'git rebase -i' in the worktree onto the current branch,
+                 so you pick, drop, reorder or squash its commits; the current
+                 branch is then fast-forwarded to the result. A rebase that
+                 stops for a conflict or an edit is left for you to finish.
+  --commits      Cherry-picks a commit or range onto the current branch,
+                 leaving the worktree untouched. The range is resolved in the
+                 worktree, so HEAD~2.. means its last two commits.
+
+With --sign, or "sign_commits: true" in the git config section, the new
+commit and every rebased or cherry-picked commit are signed with
+--gpg-sign, so signatures survive the rewrite. ccswitch checks that a
+signing key is set up before changing anything.
+
+The commit runs git's pre-commit and commit-msg hooks; if one rejects it, its
+output is shown. --no-verify skips them. "commit_hooks: skip" in the git
+config section always skips them, and "commit_hooks: enforce" always runs
+them and refuses --no-verify.
+
+With --porcelain, a worktree named as an argument is rebased without asking
+anything, and its progress is printed as JSON events, one per line: started,
+then rebased, conflicted, verify_failed or failed, then done. Uncommitted
+changes need a commit message from -m. The events are those of
+'ccswitch fanout --porcelain'.
 
 Examples:
   ccswitch rebase                    # Interactive selection from all worktrees
   ccswitch rebase /path/to/worktree  # Rebase specific worktree by path
-  ccswitch rebase feature-branch     # Rebase worktree by branch name`,
-		Args: cobra.MaximumNArgs(1),
-		Run:  rebaseSession,
+  ccswitch rebase feature-branch     # Rebase worktree by branch name
+  ccswitch rebase feature-branch --type feat --scope api -m "add endpoint"
+  ccswitch rebase feature-branch --ai-message  # Message from git.message_command
+  ccswitch rebase feature-branch --push  # Force-push the result upstream
+  ccswitch rebase feature-branch --sign  # Sign the commits
+  ccswitch rebase feature-branch --no-verify  # Skip commit hooks
+  ccswitch rebase feature-branch --squash -m "Add login page"
+  ccswitch rebase feature-branch --squash --keep-author -m "Add login page"
+  ccswitch rebase feature-branch --co-author "Jo Doe <jo@example.com>"
+  ccswitch rebase feature-branch --interactive
+  ccswitch rebase feature-branch --commits HEAD~2..
+  ccswitch rebase feature-branch --commits a1b2c3d
+  ccswitch rebase feature-branch --porcelain -m "wip"  # JSON events`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranch,
+		Run:               rebaseSession,
 	}
 
+	cmd.Flags().Bool("squash", false, "Squash the worktree's commits and uncommitted changes into one commit first")
+	cmd.Flags().BoolP("interactive", "i", false, "Choose the worktree's commits to bring over with 'git rebase -i'")
+	cmd.Flags().String("commits", "", "Cherry-pick only this commit or range (e.g. HEAD~2..) onto the current branch")
+	addCommitMessageFlags(cmd)
+	addAIMessageFlag(cmd)
+	addAuthorshipFlags(cmd)
+	addNoVerifyFlag(cmd)
+	addPorcelainFlag(cmd)
+	addPushFlag(cmd)
+	addSignFlag(cmd)
+	addForceFlag(cmd)
+	addWaitFlag(cmd)
+
 	return cmd
 }
 
 func rebaseSession(cmd *cobra.Command, args []string) {
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	commitRange, _ := cmd.Flags().GetString("commits")
+	squash, _ := cmd.Flags().GetBool("squash")
+	if interactive && commitRange != "" {
+		ui.Error("✗ --interactive and --commits cannot be used together")
+		return
+	}
+	if squash && (interactive || commitRange != "") {
+		ui.Error("✗ --squash cannot be used with --interactive or --commits")
+		return
+	}
+	events := porcelainEvents(cmd, "rebase")
+	if events != nil && (interactive || commitRange != "") {
+		ui.Error("✗ --porcelain cannot be used with --interactive or --commits")
+		return
+	}
+	if ai, _ := cmd.Flags().GetBool("ai-message"); ai && events != nil {
+		ui.Error("✗ --ai-message asks to confirm the message, so it cannot be used with --porcelain")
+		return
+	}
+
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
 	// Create session manager
 	manager := session.NewManager(currentDir)
+	applySignFlag(cmd, manager)
+	if err := applyNoVerifyFlag(cmd, manager); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if err := applyAuthorshipFlags(cmd, manager); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
 
 	// Get current branch (target branch for rebase)
 	currentBranch, err := manager.GetCurrentBranch()
@@ -95,17 +206,23 @@ func rebaseSession(cmd *cobra.Command, args []string) {
 		}
 		if targetWorktree == nil {
 			ui.Errorf("✗ Worktree '%s' not found", target)
+			if events != nil {
+				return
+			}
 			ui.Info("Available worktrees:")
 			for _, wt := range worktrees {
 				name := getWorktreeDisplayName(wt, currentDir)
-				fmt.Printf("  %s (%s)\n", name, wt.Branch)
+				fmt.Printf("  %s (%s)\n", name, git.BranchLabel(wt.Branch))
 				fmt.Printf("    Path: %s\n", wt.Path)
 			}
 			return
 		}
+	} else if events != nil {
+		ui.Error("✗ --porcelain needs the worktree to rebase as an argument")
+		return
 	} else {
 		// Interactive selection
-		targetWorktree = selectWorktreeForRebase(worktrees, currentDir)
+		targetWorktree = selectWorktreeForRebase(cmd, manager, worktrees, currentDir)
 		if targetWorktree == nil {
 			return // User quit
 		}
@@ -117,48 +234,250 @@ func rebaseSession(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if interactive && targetWorktree.Detached {
+		ui.Errorf("✗ %s is detached; an interactive rebase needs a branch to rewrite", getWorktreeDisplayName(*targetWorktree, currentDir))
+		return
+	}
+
+	if squash && targetWorktree.Detached {
+		ui.Errorf("✗ %s is detached; squashing needs a branch to rewrite", getWorktreeDisplayName(*targetWorktree, currentDir))
+		return
+	}
+
+	// An interactive rebase or a squash also rewrites the worktree's branch
+	rewritten := []string{currentBranch}
+	if interactive || squash {
+		rewritten = append(rewritten, targetWorktree.Branch)
+	}
+	if !guardProtected(cmd, rewritten) {
+		return
+	}
+
+	// Refuse before rewriting anything if the result can't be pushed
+	var upstreams map[string]git.Upstream
+	push := pushEnabled(cmd)
+	if push {
+		var ok bool
+		if upstreams, ok = resolveUpstreams(currentDir, []string{currentBranch}); !ok {
+			return
+		}
+	}
+
 	displayName := getWorktreeDisplayName(*targetWorktree, currentDir)
-	ui.Infof("Rebasing %s onto %s", displayName, currentBranch)
-	fmt.Println()
 
 	// Check if worktree has uncommitted changes
 	hasChanges := git.HasUncommittedChanges(targetWorktree.Path)
 
-	if hasChanges {
+	if commitRange != "" {
+		ui.Infof("Cherry-picking %s from %s onto %s", commitRange, displayName, currentBranch)
+		fmt.Println()
+		if hasChanges {
+			ui.Warningf("⚠ Uncommitted changes in %s are not included", displayName)
+		}
+
+		progress := ui.StartProgress("Cherry-picking")
+		manager.SetProgress(progress)
+		count, err := manager.CherryPickSession(targetWorktree.Path, commitRange, false)
+		manager.SetProgress(nil)
+		progress.Stop()
+		if err != nil {
+			ui.Errorf("✗ Failed: %v", err)
+			if errors.IsCherryPickConflict(err) {
+				printConflict(err)
+				printExplanation(cmd, cherryPickConflictExplanation(currentDir, currentBranch, targetWorktree.Path, commitRange))
+			}
+			return
+		}
+
+		ui.Successf("✓ Cherry-picked %d commit(s) from %s onto %s", count, displayName, currentBranch)
+		if push {
+			fmt.Println()
+			pushBranches(currentDir, []string{currentBranch}, upstreams)
+		}
+		return
+	}
+
+	if events != nil {
+		// There is no one to ask for a commit message
+		if message, _ := cmd.Flags().GetString("message"); message == "" {
+			if squash {
+				ui.Error("✗ --squash needs the commit message with -m")
+				return
+			}
+			if hasChanges {
+				ui.Errorf("✗ %s has uncommitted changes; pass the commit message with -m", displayName)
+				return
+			}
+		}
+		events.worktree(schema.EventStarted, *targetWorktree, currentBranch, "")
+	} else {
+		ui.Infof("Rebasing %s onto %s", displayName, currentBranch)
+		fmt.Println()
+	}
+
+	if interactive {
+		if hasChanges {
+			commitMessage, err := resolveWorktreeCommitMessage(cmd, manager, targetWorktree.Path, "HEAD")
+			if err != nil {
+				ui.Errorf("✗ %v", err)
+				if hint := errors.ErrorHint(err); hint != "" {
+					ui.Infof("  Tip: %s", hint)
+				}
+				return
+			}
+			ui.Info("Committing changes...")
+			if err := manager.CommitSession(targetWorktree.Path, commitMessage); err != nil {
+				ui.Errorf("✗ Failed: %v", err)
+				if hint := errors.ErrorHint(err); hint != "" {
+					ui.Infof("  Tip: %s", hint)
+				}
+				return
+			}
+		}
+
+		err := manager.InteractiveRebaseSession(targetWorktree.Path, currentBranch)
+		manager.Record(schema.OpRebase, "", []string{targetWorktree.Branch}, err)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			return
+		}
+	} else if squash {
+		// The message describes everything since the worktree forked
+		base, err := git.MergeBase(targetWorktree.Path, currentBranch, "HEAD")
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		count, _ := git.CountCommits(targetWorktree.Path, base+"..HEAD")
+		if count == 0 && !hasChanges {
+			ui.Infof("%s has no commits or changes to squash", displayName)
+			return
+		}
+		if events == nil && hasChanges {
+			ui.Infof("Squashing %d commit(s) and uncommitted changes into one", count)
+		} else if events == nil {
+			ui.Infof("Squashing %d commit(s) into one", count)
+		}
+		commitMessage, err := resolveWorktreeCommitMessage(cmd, manager, targetWorktree.Path, base)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			return
+		}
+
+		progress := ui.StartProgress("Squashing and rebasing")
+		manager.SetProgress(progress)
+		err = manager.SquashAndRebaseSession(targetWorktree.Path, currentBranch, commitMessage)
+		manager.SetProgress(nil)
+		progress.Stop()
+		manager.Record(schema.OpRebase, "", []string{currentBranch, targetWorktree.Branch}, err)
+		if events != nil {
+			events.rebase(*targetWorktree, currentBranch, err)
+		}
+		if err != nil {
+			ui.Errorf("✗ Failed: %v", err)
+			if errors.IsHookFailed(err) || errors.IsSigning(err) || errors.IsVerifyFailed(err) {
+				ui.Infof("  Tip: %s", errors.ErrorHint(err))
+			}
+			if errors.IsRebaseConflict(err) {
+				printConflict(err)
+				e := rebaseConflictExplanation(currentDir, currentBranch, git.BranchLabel(targetWorktree.Branch), "")
+				e.State += fmt.Sprintf("\nThe commits of %s were squashed into one before the rebase started; the previous tip is %s.", targetWorktree.Branch, git.BackupRef(targetWorktree.Branch))
+				printExplanation(cmd, e)
+			}
+			return
+		}
+	} else if hasChanges {
 		// Has uncommitted changes - need to commit first
-		commitMessage := promptForCommitMessage()
-		if commitMessage == "" {
-			ui.Error("✗ Commit message cannot be empty")
+		commitMessage, err := resolveWorktreeCommitMessage(cmd, manager, targetWorktree.Path, "HEAD")
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			if hint := errors.ErrorHint(err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
 			return
 		}
 
 		// Perform commit and rebase
-		ui.Info("Committing changes...")
-		if err := manager.CommitAndRebaseSession(targetWorktree.Path, commitMessage); err != nil {
+		progress := ui.StartProgress("Committing changes and rebasing")
+		manager.SetProgress(progress)
+		err = manager.CommitAndRebaseSession(targetWorktree.Path, commitMessage)
+		manager.SetProgress(nil)
+		progress.Stop()
+		manager.Record(schema.OpRebase, "", []string{currentBranch}, err)
+		if events != nil {
+			events.rebase(*targetWorktree, currentBranch, err)
+		}
+		if err != nil {
 			ui.Errorf("✗ Failed: %v", err)
+			if errors.IsHookFailed(err) || errors.IsSigning(err) || errors.IsVerifyFailed(err) {
+				ui.Infof("  Tip: %s", errors.ErrorHint(err))
+			}
+			if errors.IsRebaseConflict(err) {
+				printConflict(err)
+				e := rebaseConflictExplanation(currentDir, currentBranch, git.BranchLabel(targetWorktree.Branch), "")
+				e.State += fmt.Sprintf("\nYour changes were committed on %s before the rebase started.", git.BranchLabel(targetWorktree.Branch))
+				printExplanation(cmd, e)
+			}
 			return
 		}
 	} else {
 		// No uncommitted changes - just rebase existing commits
-		ui.Info("No uncommitted changes, rebasing existing commits...")
-		if err := manager.RebaseSession(targetWorktree.Path); err != nil {
+		progress := ui.StartProgress("No uncommitted changes, rebasing existing commits")
+		manager.SetProgress(progress)
+		err := manager.RebaseSession(targetWorktree.Path)
+		manager.SetProgress(nil)
+		progress.Stop()
+		manager.Record(schema.OpRebase, "", []string{currentBranch}, err)
+		if events != nil {
+			events.rebase(*targetWorktree, currentBranch, err)
+		}
+		if err != nil {
 			ui.Errorf("✗ Failed: %v", err)
+			if errors.IsVerifyFailed(err) {
+				ui.Infof("  Tip: %s", errors.ErrorHint(err))
+			}
+			if errors.IsRebaseConflict(err) {
+				printConflict(err)
+				printExplanation(cmd, rebaseConflictExplanation(currentDir, currentBranch, git.BranchLabel(targetWorktree.Branch), ""))
+			}
 			return
 		}
 	}
 
 	ui.Successf("✓ Successfully rebased %s onto %s", displayName, currentBranch)
 	ui.Infof("Worktree preserved at: %s", targetWorktree.Path)
+
+	if push {
+		if events == nil {
+			fmt.Println()
+		}
+		pushBranches(currentDir, []string{currentBranch}, upstreams)
+	}
+	if events != nil {
+		events.done(map[string]int{schema.EventRebased: 1})
+	}
 }
 
-func selectWorktreeForRebase(worktrees []git.Worktree, currentDir string) *git.Worktree {
-	// Filter out current directory and main worktree
+func selectWorktreeForRebase(cmd *cobra.Command, manager *session.Manager, worktrees []git.Worktree, currentDir string) *git.Worktree {
+	// Filter out current directory and bare repositories; detached worktrees
+	// are labeled as such
 	var availableWorktrees []git.Worktree
+	var choices []git.SessionInfo
 
 	for _, wt := range worktrees {
-		// Skip current directory and empty branches (detached HEAD)
-		if wt.Path != currentDir && wt.Branch != "" {
+		if wt.Path != currentDir && wt.HasHead() {
 			availableWorktrees = append(availableWorktrees, wt)
+			choices = append(choices, git.SessionInfo{
+				Name:   getWorktreeDisplayName(wt, currentDir),
+				Branch: wt.Branch,
+				Path:   wt.Path,
+			})
 		}
 	}
 
@@ -167,65 +486,17 @@ func selectWorktreeForRebase(worktrees []git.Worktree, currentDir string) *git.W
 		return nil
 	}
 
-	// Get current branch for comparison
-	currentBranch, _ := git.GetCurrentBranch(currentDir)
-
-	// Color definitions
-	yellow := color.New(color.FgYellow, color.Bold)
-	green := color.New(color.FgGreen)
-	gray := color.New(color.FgHiBlack)
-
-	// Show numbered list
-	ui.Title("Select worktree to rebase:")
-	fmt.Println()
-
-	for i, wt := range availableWorktrees {
-		name := getWorktreeDisplayName(wt, currentDir)
-
-		// Determine status and color
-		var statusColor *color.Color
-		var statusIcon string
-
-		if git.HasUncommittedChanges(wt.Path) {
-			// Has uncommitted changes - Yellow
-			statusColor = yellow
-			statusIcon = "●"
-		} else if diff, err := git.GetCommitCountDifference(wt.Path, currentBranch); err == nil && diff > 0 {
-			// Ahead of current branch - Green
-			statusColor = green
-			statusIcon = "↑"
-		} else {
-			// Behind or same - Gray/Default
-			statusColor = gray
-			statusIcon = "○"
-		}
-
-		// Print with status color
-		statusColor.Printf("  %d. %s %s (%s)\n", i+1, statusIcon, name, wt.Branch)
-		fmt.Printf("     Path: %s\n", wt.Path)
-	}
-
-	fmt.Println()
-	fmt.Print("Enter number (or q to quit): ")
-
-	scanner := bufio.NewScanner(os.Stdin)
-	if !scanner.Scan() {
-		return nil
-	}
-
-	input := strings.TrimSpace(scanner.Text())
-	if input == "q" || input == "" {
+	selected := pickSession(cmd, manager, choices, "Select worktree to rebase:")
+	if selected == nil {
 		return nil
 	}
 
-	// Parse number
-	var choice int
-	if _, err := fmt.Sscanf(input, "%d", &choice); err != nil || choice < 1 || choice > len(availableWorktrees) {
-		ui.Error("✗ Invalid selection")
-		return nil
+	for i := range availableWorktrees {
+		if availableWorktrees[i].Path == selected.Path {
+			return &availableWorktrees[i]
+		}
 	}
-
-	return &availableWorktrees[choice-1]
+	return nil
 }
 
 // getWorktreeDisplayName returns a friendly name for the worktree
@@ -234,9 +505,10 @@ func getWorktreeDisplayName(wt git.Worktree, currentDir string) string {
 	if strings.Contains(wt.Path, ".ccswitch/worktrees/") {
 		parts := strings.Split(wt.Path, string(filepath.Separator))
 		for i, part := range parts {
-			if part == ".ccswitch" && i+2 < len(parts) {
+			// Paths look like .ccswitch/worktrees/<repo>/<session>
+			if part == ".ccswitch" && i+3 < len(parts) {
 				// Return just the session name
-				return parts[i+2]
+				return utils.NameFromPath(parts[i+3])
 			}
 		}
 	}
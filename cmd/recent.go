@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newRecentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "Switch back to the previous session",
+		Long: `Switch back to the session used before the current one, like cd - does for
+directories. Running it again returns to where you started.
+
+Sessions count as used when they are switched to, picked in the list, or
+have a command run in them with work or exec. The session you are in is
+never the one switched to, whether or not ccswitch took you there.
+
+'ccswitch recent list' shows the sessions used most recently.
+
+Examples:
+  ccswitch -                 # Back to the previous session
+  ccswitch recent            # The same
+  ccswitch recent list       # The last 10 sessions used
+  ccswitch recent list -n 3  # The last 3`,
+		Args: cobra.NoArgs,
+		Run:  switchToRecent,
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Show the sessions used most recently",
+		Args:  cobra.NoArgs,
+		Run:   listRecent,
+	}
+	list.Flags().IntP("number", "n", 10, "Show at most this many sessions (0 for all)")
+	cmd.AddCommand(list)
+
+	return cmd
+}
+
+func switchToRecent(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	previous, err := manager.PreviousSession(currentDir)
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+	if previous == nil {
+		ui.Info("No previous session to switch back to")
+		ui.Info("  Tip: sessions count as used once you switch to them with 'ccswitch switch' or 'ccswitch list'")
+		return
+	}
+
+	// Record the session being left so the next switch comes back to it
+	if sessions, err := manager.ListSessions(); err == nil {
+		top := git.FindEnclosingRepository(currentDir)
+		for _, s := range sessions {
+			if s.Path == top {
+				_ = manager.MarkUsed(s)
+			}
+		}
+	}
+	_ = manager.MarkUsed(*previous)
+
+	// Output success message with consistent formatting
+	ui.Successf("✓ Switched to session: %s", previous.Name)
+	fmt.Printf("Branch: %s\n", previous.BranchLabel())
+	fmt.Printf("Location: %s\n", previous.Path)
+
+	// Output the cd command for shell evaluation
+	printCd(cmd, previous.Path)
+
+	// If shell integration is not active, show a helpful message
+	if !utils.IsShellIntegrationActive() {
+		fmt.Println()
+		ui.Info("💡 Note: Shell integration is not active.")
+		fmt.Println(utils.GetShellIntegrationInstructions())
+	}
+}
+
+func listRecent(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	recent, err := manager.RecentSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+	if len(recent) == 0 {
+		ui.Info("No sessions used yet")
+		return
+	}
+	if n, _ := cmd.Flags().GetInt("number"); n > 0 && len(recent) > n {
+		recent = recent[:n]
+	}
+
+	ui.Title("🕘 Recent sessions:")
+	fmt.Println()
+	now := time.Now()
+	gray := color.New(color.FgHiBlack)
+	for i, r := range recent {
+		fmt.Printf("  %d. %s (%s)\n", i+1, r.Name, r.BranchLabel())
+		gray.Printf("     Used %s · %s\n", utils.FormatRelative(r.LastUsed, now), r.Path)
+	}
+}
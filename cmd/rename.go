@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <session> <new name>",
+		Short: "Rename a session, its branch and its worktree",
+		Long: `Rename a session in one step: its branch is renamed with 'git branch -m',
+its worktree directory with 'git worktree move', and its metadata is updated.
+If any step fails, the earlier ones are undone.
+
+The new name is slugified like the description given to create, and the new
+branch is named after it as create names branches, with --type filling
+{type} in branch.template. With --keep-branch, only the session and its
+worktree are renamed, e.g. for sessions of existing branches.
+
+A branch that was pushed keeps tracking its old remote branch. Protected
+branches (git.protected_branches) are only renamed with --force.
+
+Examples:
+  ccswitch rename fix-login fix login redirect
+  ccswitch rename release-2-0 release --keep-branch`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeSession,
+		Run:               renameSession,
+	}
+
+	cmd.Flags().Bool("keep-branch", false, "Keep the session's branch name")
+	addBranchTypeFlag(cmd)
+	addForceFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func renameSession(cmd *cobra.Command, args []string) {
+	keepBranch, _ := cmd.Flags().GetBool("keep-branch")
+	description := strings.Join(args[1:], " ")
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+	applyBranchType(cmd, manager)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		return
+	}
+	if selected.Name == "main" {
+		ui.Error("✗ Cannot rename the main repository")
+		return
+	}
+	if !keepBranch && !guardProtected(cmd, []string{selected.Branch}) {
+		return
+	}
+
+	renamed, err := manager.RenameSession(*selected, description, keepBranch)
+	if err != nil {
+		ui.Errorf("✗ Failed to rename %s: %v", selected.Name, err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+
+	ui.Successf("✓ Renamed session: %s → %s", selected.Name, renamed.Name)
+	if renamed.Branch != selected.Branch {
+		ui.Infof("Branch: %s → %s", selected.Branch, renamed.Branch)
+	}
+	ui.Infof("Location: %s", renamed.Path)
+
+	// If we were inside the renamed worktree, follow it
+	if renamed.Path != selected.Path && isWithinDir(currentDir, selected.Path) {
+		writeCd(renamed.Path + strings.TrimPrefix(currentDir, selected.Path))
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newReposCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "Manage the repositories ccswitch knows about",
+		Long: `Manage the repositories ccswitch knows about.
+
+Every repository ccswitch is used in is remembered, so any command can be run
+against it from anywhere by passing its name to --repo.
+
+Examples:
+  ccswitch repos list                # Known repositories and their sessions
+  ccswitch --repo api list           # Pick a session of the api repository
+  ccswitch --repo api status         # Status of api's sessions
+  ccswitch repos forget old-project  # Stop listing a repository`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show known repositories and their sessions",
+		Args:  cobra.NoArgs,
+		Run:   listRepos,
+	}
+
+	forgetCmd := &cobra.Command{
+		Use:               "forget <name>",
+		Short:             "Remove a repository from the list, keeping its sessions",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeRepoName,
+		Run:               forgetRepo,
+	}
+
+	cmd.AddCommand(listCmd, forgetCmd)
+	return cmd
+}
+
+func listRepos(cmd *cobra.Command, args []string) {
+	repos, err := session.Repos()
+	if err != nil {
+		ui.Errorf("✗ Failed to load repositories: %v", err)
+		return
+	}
+	if len(repos) == 0 {
+		ui.Info("No repositories known yet; they are added when you use ccswitch in them")
+		return
+	}
+
+	ui.Title("📁 Repositories")
+	for i, repo := range repos {
+		if i > 0 {
+			fmt.Println()
+		}
+		if _, err := os.Stat(repo.Path); err != nil {
+			fmt.Printf("%s  %s (missing)\n", repo.Name, repo.Path)
+			continue
+		}
+
+		fmt.Printf("%s  %s\n", repo.Name, repo.Path)
+		sessions, err := session.NewManager(repo.Path).ListSessions()
+		if err != nil {
+			ui.Errorf("  ✗ Failed to list sessions: %v", err)
+			continue
+		}
+		for _, s := range sessions {
+			fmt.Printf("  %-24s %s\n", s.Name, s.Branch)
+		}
+	}
+}
+
+func forgetRepo(cmd *cobra.Command, args []string) {
+	repo, err := session.FindRepo(args[0])
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if err := session.ForgetRepo(repo.Path); err != nil {
+		ui.Errorf("✗ Failed to forget %s: %v", repo.Name, err)
+		return
+	}
+	ui.Successf("✓ Forgot %s (%s)", repo.Name, repo.Path)
+	ui.Info("  It is added again the next time you use ccswitch in it")
+}
+
+// completeRepoName completes the names of known repositories
+func completeRepoName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return repoNames(), cobra.ShellCompDirectiveNoFileComp
+}
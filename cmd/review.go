@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newReviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review [session]",
+		Short: "Review a session's changes before rebasing it",
+		Long: `Page through what a session would bring to the current branch, one file at
+a time, before rebasing it. The diff compares the session's working tree,
+uncommitted changes included, with where it forked from the current branch;
+untracked files are not shown.
+
+Press n and p to move between files, the arrow keys, space and b to scroll,
+a to approve and q to leave without approving. Leaving changes nothing.
+
+Once approved, you are asked whether to rebase the session now; --rebase
+goes straight into the rebase. The rebase is that of 'ccswitch rebase',
+and takes its commit message, --ai-message, --co-author, --push, --sign,
+--no-verify and --force flags.
+
+Examples:
+  ccswitch review                  # Pick a session
+  ccswitch review fix-login
+  ccswitch review fix-login --rebase -m "Fix login redirect"`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               reviewSession,
+	}
+
+	cmd.Flags().Bool("rebase", false, "Rebase the session once approved without asking")
+	addCommitMessageFlags(cmd)
+	addAIMessageFlag(cmd)
+	addAuthorshipFlags(cmd)
+	addNoVerifyFlag(cmd)
+	addPushFlag(cmd)
+	addSignFlag(cmd)
+	addForceFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func reviewSession(cmd *cobra.Command, args []string) {
+	rebase, _ := cmd.Flags().GetBool("rebase")
+	if !ui.Interactive() {
+		ui.Error("✗ review needs a terminal; use 'ccswitch diff' to print a session's changes")
+		return
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "🔍 Select session to review:")
+	if selected == nil {
+		return
+	}
+
+	// Review against the branch the rebase would land on
+	currentBranch, err := manager.GetCurrentBranch()
+	if err != nil {
+		ui.Errorf("✗ Failed to get current branch: %v", err)
+		return
+	}
+	if selected.Branch == currentBranch {
+		ui.Errorf("✗ %s is the current branch; there is nothing to review", currentBranch)
+		return
+	}
+
+	files, err := git.GetFileDiffs(selected.Path, currentBranch, true)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if len(files) == 0 {
+		ui.Infof("%s has no changes from %s", selected.Name, currentBranch)
+		return
+	}
+
+	title := fmt.Sprintf("🔍 %s: %d file(s) changed from %s", selected.Name, len(files), currentBranch)
+	approved, err := ui.ReviewDiff(title, files)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if !approved {
+		ui.Infof("Left %s untouched", selected.Name)
+		return
+	}
+	ui.Successf("✓ Approved %s", selected.Name)
+
+	if !rebase {
+		fmt.Printf("Rebase %s onto %s now? (y/N): ", selected.Name, currentBranch)
+		scanner := bufio.NewScanner(os.Stdin)
+		if !scanner.Scan() || strings.ToLower(strings.TrimSpace(scanner.Text())) != "y" {
+			ui.Infof("  Tip: run 'ccswitch rebase %s' when you are ready", selected.Branch)
+			return
+		}
+	}
+
+	fmt.Println()
+	rebaseSession(cmd, []string{selected.Path})
+}
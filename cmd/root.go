@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -14,36 +20,167 @@ context-switching overhead of stashing changes or switching branches.
 
 Key commands:
   ccswitch                    Create a new work session
+  ccswitch init               Set up ccswitch for a repository
   ccswitch checkout <branch>  Checkout an existing branch into a new worktree
   ccswitch list               Show and switch between sessions
+  ccswitch status             Show the state of all sessions
+  ccswitch watch              Monitor all sessions live
   ccswitch switch <session>   Switch to a specific session
+  ccswitch -                  Switch back to the previous session (see 'recent')
+  ccswitch move <session> <p> Move a session's worktree to a new location
+  ccswitch rename <s> <name>  Rename a session with its branch and worktree
+  ccswitch migrate-worktrees  Move sessions to the configured worktree root
+  ccswitch export [s...]      Write session definitions to share with a teammate
+  ccswitch import <file>      Recreate exported sessions
+  ccswitch import --from <t>  Import worktrees created by other tools
+  ccswitch adopt [path]       Register an existing worktree as a session
   ccswitch work <command>     Execute a command in a selected session
-  ccswitch cleanup            Remove a session interactively
+  ccswitch exec <s> -- <cmd>  Run a command in a named session (for scripts)
+  ccswitch test-matrix -- <c> Run a command in every session and compare results
+  ccswitch heartbeat          Publish what an agent is doing in its session
+  ccswitch agents spawn       Launch one agent per task, each in its own session
+  ccswitch diff [session]     Show a session's changes relative to current branch
+  ccswitch open-issue [s]     Open a session's issue in the browser
+  ccswitch tag <s> [tag...]   Add or remove the tags of a session
+  ccswitch note <s> [text]    Add to or show the notes of a session
+  ccswitch pin <session>      Protect a session from cleanup (unpin to undo)
+  ccswitch env [session]      Show or set the ports and variables of a session
+  ccswitch container up [s]   Start a dev container for a session
+  ccswitch vscode             Generate a VS Code workspace with every session
+  ccswitch log [session]      Browse the commits of a session
+  ccswitch graph              Show which session branches contain which commits
+  ccswitch bisect start       Find the commit that broke something, in a throwaway session
+  ccswitch stack              Create, list and restack stacked sessions
+  ccswitch cleanup            Remove sessions interactively (alias: delete)
   ccswitch cleanup --all      Remove ALL worktrees at once (bulk cleanup)
+  ccswitch prune-artifacts    Delete build artifacts in inactive sessions
+  ccswitch doctor             Check the environment and repair worktrees
+  ccswitch review [session]   Review a session's changes file by file before rebasing
   ccswitch rebase             Commit changes and rebase a worktree to current branch
+  ccswitch pick <session>     Cherry-pick chosen commits from a session
+  ccswitch transplant <s> [t] Move uncommitted changes to another session
+  ccswitch snapshot [session] Save uncommitted changes to restore later
+  ccswitch sync [session]     Rebase a session onto its base branch
   ccswitch fanout             Propagate current branch commits to all other worktrees
-  ccswitch pr                 Create a pull request for current session`,
+  ccswitch daemon             Fetch and report sessions behind their base periodically
+  ccswitch serve              Serve an HTTP API to drive sessions from editors and tools
+  ccswitch pr                 Create a pull request for current session
+  ccswitch digest             Summarize recent session activity
+  ccswitch stats              Show aggregate numbers about sessions and rebases
+  ccswitch history            Show what ccswitch has done in the repository
+  ccswitch nag                Warn about long-dirty sessions (for prompt hooks)
+  ccswitch prompt             Print the current session for PS1 or starship
+  ccswitch repos list         Show known repositories and their sessions
+  ccswitch --repo <name> ...  Run any command in another known repository
+  ccswitch <plugin> ...       Run a ccswitch-<plugin> executable (see 'help plugins')
+  ccswitch <alias> ...        Run a command defined under alias in the config`,
 		Run: createSession,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+				ui.SetQuiet(true)
+			}
+			registerRepo(cmd)
+			configureLanguage(cmd)
+			configureGit(cmd)
+			session.SetCommand(commandName(cmd))
+			warnUnformatted(cmd)
+		},
 	}
 
+	rootCmd.PersistentFlags().String("repo", "", "Operate on the repository at this path, or with this name (see 'ccswitch repos list'), instead of the current directory")
+	rootCmd.PersistentFlags().Bool("no-tui", false, "Use numbered lists instead of the interactive session picker")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Only print errors and the output of the command itself")
+	rootCmd.PersistentFlags().String("format", "", "Render the output of list, status, history and prompt with this Go template (see 'ccswitch help format')")
+	rootCmd.PersistentFlags().Bool("explain", false, "Explain conflicts and failed safety checks in detail, with commands to recover")
+	registerFlagCompletions(rootCmd)
+
 	rootCmd.AddCommand(newCreateCmd())
+	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newCheckoutCmd())
 	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newWatchCmd())
 	rootCmd.AddCommand(newSwitchCmd())
+	rootCmd.AddCommand(newRecentCmd())
+	rootCmd.AddCommand(newMoveCmd())
+	rootCmd.AddCommand(newRenameCmd())
+	rootCmd.AddCommand(newMigrateWorktreesCmd())
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newAdoptCmd())
 	rootCmd.AddCommand(newWorkCmd())
+	rootCmd.AddCommand(newExecCmd())
+	rootCmd.AddCommand(newTestMatrixCmd())
+	rootCmd.AddCommand(newHeartbeatCmd())
+	rootCmd.AddCommand(newAgentsCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newLogCmd())
+	rootCmd.AddCommand(newGraphCmd())
+	rootCmd.AddCommand(newBisectCmd())
+	rootCmd.AddCommand(newOpenIssueCmd())
+	rootCmd.AddCommand(newTagCmd())
+	rootCmd.AddCommand(newNoteCmd())
+	rootCmd.AddCommand(newPinCmd())
+	rootCmd.AddCommand(newUnpinCmd())
+	rootCmd.AddCommand(newEnvCmd())
+	rootCmd.AddCommand(newContainerCmd())
+	rootCmd.AddCommand(newVSCodeCmd())
+	rootCmd.AddCommand(newStackCmd())
 	rootCmd.AddCommand(newCleanupCmd())
+	rootCmd.AddCommand(newPruneArtifactsCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newReviewCmd())
 	rootCmd.AddCommand(newRebaseCmd())
+	rootCmd.AddCommand(newPickCmd())
+	rootCmd.AddCommand(newTransplantCmd())
+	rootCmd.AddCommand(newSnapshotCmd())
+	rootCmd.AddCommand(newSyncCmd())
 	rootCmd.AddCommand(newFanoutCmd())
+	rootCmd.AddCommand(newDaemonCmd())
+	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newInfoCmd())
 	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newReposCmd())
 	rootCmd.AddCommand(newPRCmd())
+	rootCmd.AddCommand(newDigestCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newNagCmd())
+	rootCmd.AddCommand(newPromptCmd())
 	rootCmd.AddCommand(newShellInitCmd())
 	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newFormatHelpCmd())
+	rootCmd.AddCommand(newPluginsHelpCmd())
+	addPlugins(rootCmd, compiledPlugins)
 
 	return rootCmd
 }
 
+// commandName returns the name of cmd without the root command, e.g.
+// "stack restack"; the root command itself creates sessions
+func commandName(cmd *cobra.Command) string {
+	if cmd == cmd.Root() {
+		return "create"
+	}
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
 // Execute runs the root command
 func Execute() error {
-	return NewRootCmd().Execute()
+	rootCmd := NewRootCmd()
+	args := resolveAlias(rootCmd, expandDash(os.Args[1:]))
+	if needsPlugins(rootCmd, args) {
+		addPlugins(rootCmd, pluginCommands(findPlugins(filepath.SplitList(os.Getenv("PATH")))))
+	}
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+// expandDash turns "ccswitch -" into "ccswitch recent": cobra takes a lone
+// dash for an argument rather than a command name
+func expandDash(args []string) []string {
+	if len(args) > 0 && args[0] == "-" {
+		return append([]string{"recent"}, args[1:]...)
+	}
+	return args
 }
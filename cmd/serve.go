@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/remote"
+	"github.com/ksred/ccswitch/internal/server"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/pkg/ccswitch"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve an HTTP API to drive sessions from editors and tools",
+		Long: `Serve a local HTTP API for the repository, so editor plugins and scripts can
+drive ccswitch without scraping its output. Responses are the JSON documents
+of --json (see the schema field); errors are {"error": ..., "hint": ...}.
+
+  GET    /api/v1/status                  Sessions compared to ?base= (default:
+  GET    /api/v1/sessions                the current branch), as status --json
+  POST   /api/v1/sessions                Create a session: {"description": ...,
+                                         "base": ..., "type": ..., "tags": [...]}
+  DELETE /api/v1/sessions/<s>            Remove a session; ?force=true discards
+                                         uncommitted changes, ?delete_branch=true
+                                         deletes its branch unless protected
+  POST   /api/v1/sessions/<s>/rebase     Rebase a session onto the current branch,
+                                         as rebase does; {"message": ...} commits
+                                         its uncommitted changes first
+  POST   /api/v1/sessions/<s>/exec       Run {"command": [...], "timeout": "5m"}
+                                         in a session and return its exit code
+                                         and output
+
+Sessions are named by session name or branch, URL-encoded. Requests that
+change sessions take the repository lock like ccswitch commands do, and are
+answered with 409 Conflict while another command holds it.
+
+Every request must send 'Authorization: Bearer <token>'. The token is
+CCSWITCH_TOKEN if set, or else the one in ~/.ccswitch/serve-token, generated
+the first time; status --remote and watch --remote send CCSWITCH_TOKEN.
+
+The API listens on 127.0.0.1:7777, or on a unix socket with --unix-socket,
+which only your user can connect to. Listening on other addresses exposes the
+token over plain HTTP: put a TLS proxy in front.
+
+Examples:
+  ccswitch serve
+  ccswitch serve --addr 127.0.0.1:9000
+  ccswitch serve --unix-socket ~/.ccswitch/api.sock
+  curl -H "Authorization: Bearer $(cat ~/.ccswitch/serve-token)" localhost:7777/api/v1/sessions`,
+		Args: cobra.NoArgs,
+		Run:  serveAPI,
+	}
+
+	cmd.Flags().String("addr", "127.0.0.1:7777", "Address to listen on")
+	cmd.Flags().String("unix-socket", "", "Listen on a unix socket at this path instead of --addr")
+
+	return cmd
+}
+
+func serveAPI(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	sessions, err := ccswitch.Open(currentDir)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	token, err := server.Token()
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	listener, address, err := serveListener(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	api := server.New(sessions, token)
+	api.Logf = func(format string, args ...any) {
+		ui.Infof("%s %s", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+	}
+	srv := &http.Server{Handler: api.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	// Let requests in progress finish when interrupted; commands they run
+	// are stopped with the server
+	stop := proc.OnInterrupt(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), proc.DefaultGracePeriod)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+	defer stop()
+
+	ui.Successf("✓ Serving the ccswitch API for %s on %s", sessions.Root(), address)
+	if os.Getenv(remote.TokenEnv) != "" {
+		ui.Infof("Token: %s", remote.TokenEnv)
+	} else {
+		ui.Infof("Token: %s", server.TokenPath())
+	}
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		ui.Errorf("✗ %v", err)
+	}
+}
+
+// serveListener listens on --unix-socket, or else --addr, and returns the
+// listener with its address for display
+func serveListener(cmd *cobra.Command) (net.Listener, string, error) {
+	socket, _ := cmd.Flags().GetString("unix-socket")
+	if socket == "" {
+		addr, _ := cmd.Flags().GetString("addr")
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, "", err
+		}
+		if host, _, _ := net.SplitHostPort(addr); !isLoopback(host) {
+			ui.Warningf("⚠ Listening on %s: the token travels unencrypted to every client that can reach it", addr)
+		}
+		return listener, "http://" + listener.Addr().String(), nil
+	}
+
+	socket = expandHome(socket)
+	// A socket left behind by a server that didn't exit cleanly
+	if info, err := os.Lstat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return nil, "", fmt.Errorf("%s is in use by another server", socket)
+		}
+		_ = os.Remove(socket)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return nil, "", err
+	}
+	return listener, socket, nil
+}
+
+// isLoopback reports whether host only accepts connections from this
+// machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
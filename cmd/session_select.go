@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// findSession returns the session matching name by session name or branch
+func findSession(sessions []git.SessionInfo, name string) *git.SessionInfo {
+	for _, s := range sessions {
+		if s.Name == name || s.Branch == name {
+			s := s // Create a copy to take address of
+			return &s
+		}
+	}
+	return nil
+}
+
+// resolveSession returns the session named in args, or lets the user pick one
+// interactively when no name is given. Errors are reported to the user and
+// nil is returned if no session was selected.
+func resolveSession(cmd *cobra.Command, manager *session.Manager, args []string, title string) *git.SessionInfo {
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return nil
+	}
+
+	if len(sessions) == 0 {
+		ui.Info("No active sessions")
+		return nil
+	}
+
+	if len(args) > 0 {
+		selected := findSession(sessions, args[0])
+		if selected == nil {
+			ui.Errorf("✗ Session '%s' not found", args[0])
+			ui.Info("Available sessions:")
+			for _, s := range sessions {
+				fmt.Printf("  %s (%s)\n", s.Name, s.BranchLabel())
+			}
+		}
+		return selected
+	}
+
+	return pickSession(cmd, manager, sessions, title)
+}
+
+// sessionIssues returns the issue of each session tied to one, keyed by
+// worktree path
+func sessionIssues(manager *session.Manager, sessions []git.SessionInfo) map[string]string {
+	issues := make(map[string]string)
+	for _, s := range sessions {
+		issue, url := manager.Issue(s)
+		if issue == "" {
+			issue = url
+		}
+		if issue != "" {
+			issues[s.Path] = issue
+		}
+	}
+	return issues
+}
+
+// sessionNotes returns the notes of sessions, keyed by worktree path
+func sessionNotes(manager *session.Manager, sessions []git.SessionInfo) map[string]string {
+	notes := make(map[string]string)
+	for _, s := range sessions {
+		if n := manager.Notes(s); n != "" {
+			notes[s.Path] = n
+		}
+	}
+	return notes
+}
+
+// pickSession lets the user pick one of sessions with the shared picker,
+// most recently used first, showing status glyphs relative to the current
+// branch. --no-tui switches to a numbered list. Errors are reported to the
+// user and nil is returned if no session was selected.
+func pickSession(cmd *cobra.Command, manager *session.Manager, sessions []git.SessionInfo, title string) *git.SessionInfo {
+	noTUI, _ := cmd.Flags().GetBool("no-tui")
+	opts := ui.PickOptions{
+		Title: title,
+		NoTUI: noTUI,
+		Activity: func() map[string]string {
+			return activitySummaries(manager)
+		},
+		LastUsed: manager.LastUsed(),
+		Issues:   sessionIssues(manager, sessions),
+		Notes:    sessionNotes(manager, sessions),
+		Pinned:   manager.Pinned(),
+		Expired:  manager.Expired(time.Now()),
+	}
+	if base, err := manager.GetCurrentBranch(); err == nil {
+		opts.BaseBranch = base
+	}
+
+	selected, err := ui.PickSession(sessions, opts)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return nil
+	}
+	return selected
+}
@@ -3,15 +3,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"runtime"
 
 	"github.com/spf13/cobra"
 )
 
 func newShellInitCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "shell-init",
+		Use:   "shell-init [bash|zsh|powershell]",
 		Short: "Output shell integration script",
 		Long: `Output the shell integration script that enables automatic directory switching.
+Without an argument, the shell is detected from $SHELL, and is PowerShell on
+Windows when $SHELL is not set.
 
 To install the shell integration:
 
@@ -21,23 +24,63 @@ For bash:
 
 For zsh:
   echo 'eval "$(ccswitch shell-init)"' >> ~/.zshrc
-  source ~/.zshrc`,
-		Run: shellInit,
+  source ~/.zshrc
+
+For PowerShell:
+  Add-Content $PROFILE 'Invoke-Expression (& ccswitch shell-init powershell | Out-String)'
+  . $PROFILE
+
+The script also defines a function printing the session the current
+directory is in, for your prompt:
+
+  PS1='$(ccswitch_session) \w \$ '                       # bash
+  setopt prompt_subst; PROMPT='$(ccswitch_session) %~ %# '  # zsh
+  function prompt { "$(Get-CcswitchSession) PS $PWD> " }   # PowerShell
+
+In PowerShell, ccswitch runs attached to the console and tells the wrapper
+where to go through a temporary file, so the direnv and mise integrations,
+which load bash environments, are not applied.`,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "powershell", "pwsh"},
+		Run:       shellInit,
 	}
 }
 
 func shellInit(cmd *cobra.Command, args []string) {
-	// Detect shell type
-	shell := os.Getenv("SHELL")
-	isZsh := os.Getenv("ZSH_VERSION") != "" || shell == "/bin/zsh" || shell == "/usr/bin/zsh"
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	} else {
+		shell = detectShell()
+	}
 
-	if isZsh {
+	switch shell {
+	case "zsh":
 		outputZshInit()
-	} else {
+	case "powershell", "pwsh":
+		outputPowerShellInit()
+	default:
 		outputBashInit()
 	}
 }
 
+// detectShell guesses the shell shell-init is run from
+func detectShell() string {
+	return shellFor(runtime.GOOS, os.Getenv("SHELL"), os.Getenv("ZSH_VERSION"))
+}
+
+// shellFor picks the shell detectShell reports on goos, from $SHELL and
+// $ZSH_VERSION
+func shellFor(goos, shell, zshVersion string) string {
+	switch {
+	case zshVersion != "" || shell == "/bin/zsh" || shell == "/usr/bin/zsh":
+		return "zsh"
+	case shell == "" && goos == "windows":
+		return "powershell"
+	}
+	return "bash"
+}
+
 func outputBashInit() {
 	// Output the shell wrapper function
 	fmt.Print(`# ccswitch shell wrapper function
@@ -59,8 +102,9 @@ ccswitch() {
             # Clean up temp file
             rm -f "$temp_file"
             ;;
-        cleanup|info|shell-init)
-            # These commands don't need special handling
+        cleanup|info|nag|prompt|shell-init|completion|__complete*)
+            # These commands never change directory; nag and prompt run on
+            # every prompt, so they skip the capture below
             CCSWITCH_SHELL_WRAPPER=1 command ccswitch "$@"
             ;;
         create|*)
@@ -83,25 +127,23 @@ ccswitch() {
     esac
 }
 
-# Bash completion for ccswitch
-_ccswitch_completions() {
-    local cur="${COMP_WORDS[COMP_CWORD]}"
-    local prev="${COMP_WORDS[COMP_CWORD-1]}"
-    
-    # Only provide completions for cleanup command
-    if [[ "$prev" == "cleanup" ]]; then
-        # Get list of sessions by running list non-interactively
-        local sessions=$(CCSWITCH_SHELL_WRAPPER=1 command ccswitch list 2>&1 | grep -E "^→" | sed 's/^→ //' | awk '{print $1}')
-        COMPREPLY=($(compgen -W "$sessions" -- "$cur"))
-    elif [[ "$COMP_CWORD" -eq 1 ]]; then
-        # Complete command names
-        COMPREPLY=($(compgen -W "list cleanup create info shell-init" -- "$cur"))
+# Print the name of the ccswitch session the current directory is in, for
+# prompts
+ccswitch_session() {
+    local paths top main
+    paths=$(git rev-parse --path-format=absolute --show-toplevel --git-common-dir 2>/dev/null) || return 0
+    top=${paths%%$'\n'*}
+    main=${paths#*$'\n'}
+    main=${main%/*}
+    if [ "$top" != "$main" ]; then
+        echo "${top##*/}"
     fi
 }
 
-# Enable bash completion if available
+# Bash completion for ccswitch, generated by the binary so it completes
+# session and branch names
 if [[ -n "$BASH_VERSION" ]]; then
-    complete -F _ccswitch_completions ccswitch
+    source <(command ccswitch completion bash)
 fi
 `)
 }
@@ -126,8 +168,9 @@ ccswitch() {
             # Clean up temp file
             rm -f "$temp_file"
             ;;
-        cleanup|info|shell-init)
-            # These commands don't need special handling
+        cleanup|info|nag|prompt|shell-init|completion|__complete*)
+            # These commands never change directory; nag and prompt run on
+            # every prompt, so they skip the capture below
             CCSWITCH_SHELL_WRAPPER=1 command ccswitch "$@"
             ;;
         create|*)
@@ -149,32 +192,70 @@ ccswitch() {
     esac
 }
 
-# Zsh completion for ccswitch
-_ccswitch() {
-    local -a commands sessions
-    commands=(
-        'list:List all sessions'
-        'cleanup:Clean up a session'
-        'create:Create a new session'
-        'info:Show session info'
-        'shell-init:Initialize shell integration'
-    )
-
-    case "$words[2]" in
-        cleanup)
-            # Get list of sessions for cleanup completion
-            sessions=(${(f)"$(CCSWITCH_SHELL_WRAPPER=1 command ccswitch list 2>&1 | grep -E '^→' | sed 's/^→ //' | awk '{print $1}')"})
-            _describe -t sessions 'session' sessions
-            ;;
-        *)
-            if [[ $CURRENT -eq 2 ]]; then
-                _describe -t commands 'ccswitch command' commands
-            fi
-            ;;
-    esac
+# Print the name of the ccswitch session the current directory is in, for
+# prompts
+ccswitch_session() {
+    local paths top main
+    paths=$(git rev-parse --path-format=absolute --show-toplevel --git-common-dir 2>/dev/null) || return 0
+    top=${paths%%$'\n'*}
+    main=${paths#*$'\n'}
+    main=${main%/*}
+    if [ "$top" != "$main" ]; then
+        echo "${top##*/}"
+    fi
+}
+
+# Zsh completion for ccswitch, generated by the binary so it completes
+# session and branch names. Requires compinit to have run.
+if (( $+functions[compdef] )); then
+    source <(command ccswitch completion zsh)
+    compdef _ccswitch ccswitch
+fi
+`)
+}
+
+func outputPowerShellInit() {
+	fmt.Print(`# ccswitch shell wrapper function. ccswitch runs attached to the console,
+# so its selectors work, and writes the directory to change to into a
+# temporary file
+function ccswitch {
+    $exe = Get-Command -Name ccswitch -CommandType Application -ErrorAction Stop | Select-Object -First 1
+    $cdFile = [System.IO.Path]::GetTempFileName()
+    $env:CCSWITCH_SHELL_WRAPPER = '1'
+    $env:CCSWITCH_CD_FILE = $cdFile
+    try {
+        & $exe.Source @args
+    } finally {
+        Remove-Item Env:CCSWITCH_SHELL_WRAPPER, Env:CCSWITCH_CD_FILE -ErrorAction SilentlyContinue
+        $dir = Get-Content -LiteralPath $cdFile -TotalCount 1 -ErrorAction SilentlyContinue
+        Remove-Item -LiteralPath $cdFile -ErrorAction SilentlyContinue
+        if ($dir) {
+            Set-Location -LiteralPath $dir
+        }
+    }
+}
+
+# Print the name of the ccswitch session the current directory is in, for
+# prompts. Leaves $LASTEXITCODE as it was.
+function Get-CcswitchSession {
+    $code = $global:LASTEXITCODE
+    try {
+        $paths = @(git rev-parse --path-format=absolute --show-toplevel --git-common-dir 2>$null)
+        if ($LASTEXITCODE -ne 0 -or $paths.Count -lt 2) {
+            return
+        }
+        $top = [System.IO.Path]::GetFullPath($paths[0])
+        $main = [System.IO.Path]::GetDirectoryName([System.IO.Path]::GetFullPath($paths[1]))
+        if ($top -ne $main) {
+            Split-Path -Leaf $top
+        }
+    } finally {
+        $global:LASTEXITCODE = $code
+    }
 }
 
-# Enable Zsh completion for ccswitch
-compdef _ccswitch ccswitch
+# PowerShell completion for ccswitch, generated by the binary so it
+# completes session and branch names
+& (Get-Command -Name ccswitch -CommandType Application | Select-Object -First 1).Source completion powershell | Out-String | Invoke-Expression
 `)
 }
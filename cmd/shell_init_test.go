@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShellFor(t *testing.T) {
+	tests := []struct {
+		goos, shell, zshVersion string
+		expected                string
+	}{
+		{"windows", "", "", "powershell"},
+		{"windows", "/usr/bin/bash", "", "bash"},
+		{"windows", "", "5.9", "zsh"},
+		{"linux", "", "", "bash"},
+		{"darwin", "/bin/zsh", "", "zsh"},
+		{"linux", "/bin/bash", "", "bash"},
+	}
+	for _, tt := range tests {
+		if got := shellFor(tt.goos, tt.shell, tt.zshVersion); got != tt.expected {
+			t.Errorf("shellFor(%q, %q, %q) = %q, expected %q", tt.goos, tt.shell, tt.zshVersion, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteCdFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cd")
+	t.Setenv(cdFileEnv, path)
+
+	dir := filepath.Join(t.TempDir(), "my session")
+	writeCd(dir, "export CCSWITCH_SESSION=auth")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("writeCd() did not write %s: %v", cdFileEnv, err)
+	}
+	if string(data) != dir+"\n" {
+		t.Errorf("%s holds %q, expected %q", cdFileEnv, data, dir+"\n")
+	}
+}
+
+func TestShellWord(t *testing.T) {
+	tests := []struct {
+		input, expected string
+	}{
+		{"/home/jo/src/app-feature", "/home/jo/src/app-feature"},
+		{"/home/jo/my projects/app", "'/home/jo/my projects/app'"},
+		{"/tmp/it's", `'/tmp/it'\''s'`},
+		{"/tmp/$(touch x)", "'/tmp/$(touch x)'"},
+		{"~jo", "'~jo'"},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := shellWord(tt.input); got != tt.expected {
+			t.Errorf("shellWord(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
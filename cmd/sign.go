@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// addSignFlag registers --sign on commands that create or rewrite commits
+func addSignFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("sign", false, "Sign created and rebased commits with --gpg-sign; default: git.sign_commits")
+}
+
+// applySignFlag overrides the sign_commits setting of manager with --sign
+// when it is given, and returns whether commits are signed
+func applySignFlag(cmd *cobra.Command, manager *session.Manager) bool {
+	cfg := manager.Config()
+	if cmd.Flags().Changed("sign") {
+		cfg.Git.SignCommits, _ = cmd.Flags().GetBool("sign")
+	}
+	return cfg.Git.SignCommits
+}
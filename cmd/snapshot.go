@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// snapshotNameLayout names snapshots saved without --name after their time
+const snapshotNameLayout = "2006-01-02-150405"
+
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot [session]",
+		Short: "Save a session's uncommitted changes to restore later",
+		Long: `Save the uncommitted changes of a session, staged or not and including
+untracked files that are not ignored, as a named snapshot. The session is
+left as it is unless --clear is given, which discards the changes once
+they are saved.
+
+Snapshots are kept as commits under refs/ccswitch/snapshots/<session>/ in
+the repository until they are dropped. Unlike the stash, which all
+worktrees share, each session has its own.
+
+Examples:
+  ccswitch snapshot fix-login
+  ccswitch snapshot fix-login --name before-refactor -m "Working login form"
+  ccswitch snapshot fix-login --clear     # Save, then start from a clean tree
+  ccswitch snapshot list fix-login
+  ccswitch snapshot restore fix-login before-refactor
+  ccswitch snapshot drop fix-login before-refactor`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               saveSnapshot,
+	}
+	cmd.Flags().String("name", "", "Name of the snapshot (default: the current time)")
+	cmd.Flags().StringP("message", "m", "", "Describe what the snapshot holds")
+	cmd.Flags().Bool("clear", false, "Discard the changes in the session once they are saved")
+	addWaitFlag(cmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "list [session]",
+		Short:             "List the snapshots of a session",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               listSnapshots,
+	})
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [session] [name]",
+		Short: "Bring back the changes saved in a snapshot",
+		Long: `Apply the changes saved in a snapshot to the session, the newest one if
+no name is given. Changes that don't apply cleanly on top of what the
+session holds now are merged with a 3-way merge, leaving conflict markers
+in the files that conflict. The snapshot is kept unless --drop is given.`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeSession,
+		Run:               restoreSnapshot,
+	}
+	restoreCmd.Flags().Bool("drop", false, "Delete the snapshot once it was restored cleanly")
+	addWaitFlag(restoreCmd)
+	cmd.AddCommand(restoreCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "drop <session> <name>",
+		Short:             "Delete a snapshot",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSession,
+		Run:               dropSnapshot,
+	})
+
+	return cmd
+}
+
+func saveSnapshot(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString("name")
+	message, _ := cmd.Flags().GetString("message")
+	clearChanges, _ := cmd.Flags().GetBool("clear")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to snapshot:")
+	if selected == nil {
+		return
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	if !git.HasUncommittedChanges(selected.Path) {
+		ui.Infof("No uncommitted changes in %s to snapshot", selected.Name)
+		return
+	}
+
+	now := time.Now()
+	if name == "" {
+		name = now.Format(snapshotNameLayout)
+	}
+	if message == "" {
+		message = fmt.Sprintf("Snapshot of %s (%s)", selected.Name, selected.BranchLabel())
+	}
+	if _, err := git.SaveSnapshot(selected.Path, git.SnapshotRef(selected.Name, name), message); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	ui.Successf("✓ Saved snapshot %s of %s", name, selected.Name)
+
+	if clearChanges {
+		if err := git.DiscardChanges(selected.Path); err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		ui.Infof("Cleared the changes in %s", selected.Name)
+	}
+	ui.Infof("  Restore it with 'ccswitch snapshot restore %s %s'", selected.Name, name)
+}
+
+func listSnapshots(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args, "Select session to list the snapshots of:")
+	if selected == nil {
+		return
+	}
+
+	snapshots, err := git.ListSnapshots(selected.Path, selected.Name)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if len(snapshots) == 0 {
+		ui.Infof("No snapshots of %s", selected.Name)
+		return
+	}
+
+	gray := color.New(color.FgHiBlack)
+	now := time.Now()
+	ui.Titlef("📸 Snapshots of %s", selected.Name)
+	for _, s := range snapshots {
+		fmt.Printf("  %-24s %s\n", s.Name, s.Message)
+		gray.Printf("  %-24s %s\n", "", utils.FormatRelative(s.Created, now))
+	}
+}
+
+func restoreSnapshot(cmd *cobra.Command, args []string) {
+	drop, _ := cmd.Flags().GetBool("drop")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:min(1, len(args))], "Select session to restore a snapshot in:")
+	if selected == nil {
+		return
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	snapshot, ok := findSnapshot(selected, args)
+	if !ok {
+		return
+	}
+
+	conflicted, err := git.RestoreSnapshot(selected.Path, snapshot.Commit)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		ui.Infof("  %s was left unchanged", selected.Name)
+		return
+	}
+	if len(conflicted) > 0 {
+		ui.Warningf("⚠ Restored snapshot %s in %s with a 3-way merge; %d file(s) conflict:", snapshot.Name, selected.Name, len(conflicted))
+		for _, file := range conflicted {
+			fmt.Printf("  %s\n", file)
+		}
+		ui.Infof("  Tip: Resolve the conflict markers in %s, then 'git add' the files", selected.Path)
+		return
+	}
+	ui.Successf("✓ Restored snapshot %s in %s", snapshot.Name, selected.Name)
+
+	if drop {
+		if err := git.DeleteRef(selected.Path, git.SnapshotRef(selected.Name, snapshot.Name)); err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		ui.Infof("Dropped snapshot %s", snapshot.Name)
+	}
+}
+
+func dropSnapshot(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		return
+	}
+	snapshot, ok := findSnapshot(selected, args)
+	if !ok {
+		return
+	}
+	if err := git.DeleteRef(selected.Path, git.SnapshotRef(selected.Name, snapshot.Name)); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	ui.Successf("✓ Dropped snapshot %s of %s", snapshot.Name, selected.Name)
+}
+
+// findSnapshot returns the snapshot of s named by args[1], or the newest if
+// args has no name. Errors are reported to the user and false is returned.
+func findSnapshot(s *git.SessionInfo, args []string) (git.Snapshot, bool) {
+	snapshots, err := git.ListSnapshots(s.Path, s.Name)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return git.Snapshot{}, false
+	}
+	if len(snapshots) == 0 {
+		ui.Errorf("✗ No snapshots of %s", s.Name)
+		return git.Snapshot{}, false
+	}
+	if len(args) < 2 {
+		return snapshots[0], true
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == args[1] {
+			return snapshot, true
+		}
+	}
+	ui.Errorf("✗ No snapshot of %s named %s", s.Name, args[1])
+	ui.Infof("  Tip: 'ccswitch snapshot list %s' shows them", s.Name)
+	return git.Snapshot{}, false
+}
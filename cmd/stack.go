@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newStackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stack",
+		Short: "Manage stacks of sessions built on each other",
+		Long: `Manage stacks of sessions whose branches build on each other.
+
+A stacked session branches from another session instead of the current
+branch. When the parent changes (new commits, amends, or a rebase), restack
+replays each child's own commits onto the parent's new tip.
+
+Examples:
+  ccswitch stack create add tests          # Stack on the session you are in
+  ccswitch stack create api --parent auth  # Stack on a specific session
+  ccswitch stack list                      # Show all stacks
+  ccswitch stack restack                   # Restack every stack
+  ccswitch stack restack auth              # Restack sessions stacked on auth`,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <description>",
+		Short: "Create a session stacked on another session",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   createStackedSession,
+	}
+	createCmd.Flags().String("parent", "", "Session to stack on (default: the session you are in)")
+	_ = createCmd.RegisterFlagCompletionFunc("parent", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sessionNames(cmd), cobra.ShellCompDirectiveNoFileComp
+	})
+	addSetupFlag(createCmd)
+	addWaitFlag(createCmd)
+	cmd.AddCommand(createCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Show stacks of sessions",
+		Args:  cobra.NoArgs,
+		Run:   listStacks,
+	})
+
+	restackCmd := &cobra.Command{
+		Use:               "restack [session]",
+		Short:             "Rebase stacked sessions onto their parents' latest commits",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               restackSessions,
+	}
+	addSignFlag(restackCmd)
+	addForceFlag(restackCmd)
+	addWaitFlag(restackCmd)
+	cmd.AddCommand(restackCmd)
+
+	return cmd
+}
+
+func createStackedSession(cmd *cobra.Command, args []string) {
+	parentName, _ := cmd.Flags().GetString("parent")
+	description := strings.TrimSpace(strings.Join(args, " "))
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	manager := session.NewManager(currentDir)
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		return
+	}
+
+	var parent *git.SessionInfo
+	if parentName != "" {
+		parent = findSession(sessions, parentName)
+		if parent == nil {
+			ui.Errorf("✗ Session '%s' not found", parentName)
+			return
+		}
+	} else {
+		parent = currentSession(sessions, currentDir)
+		if parent == nil {
+			ui.Error("✗ Not inside a session")
+			ui.Info("  Tip: Run this from a session's worktree or pass --parent <session>")
+			return
+		}
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	progress := ui.StartProgress("Creating worktree")
+	entry, err := manager.CreateStackedSession(description, *parent)
+	progress.Stop()
+	if err != nil {
+		ui.Errorf("✗ %s", err)
+		if hint := errors.ErrorHint(err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+	setupWorktree(cmd, manager, entry.Path)
+
+	ui.Successf("✓ Created session: %s", entry.Name)
+	ui.Infof("Branch: %s", entry.Branch)
+	ui.Infof("Stacked on: %s (%s)", parent.Name, parent.Branch)
+	ui.Infof("Location: %s", entry.Path)
+
+	// Output the cd command for the shell wrapper to execute on a separate line
+	printCd(cmd, entry.Path)
+
+	if !utils.IsShellIntegrationActive() {
+		fmt.Println()
+		ui.Info("💡 Note: Shell integration is not active.")
+		ui.Info(utils.GetShellIntegrationInstructions())
+	}
+}
+
+// currentSession returns the session whose worktree contains dir, excluding
+// the main repository
+func currentSession(sessions []git.SessionInfo, dir string) *git.SessionInfo {
+	top := git.FindEnclosingRepository(dir)
+	mainRepo, _ := git.GetMainRepoPath(dir)
+	if top == "" || top == mainRepo {
+		return nil
+	}
+	for _, s := range sessions {
+		if s.Path == top {
+			s := s // Create a copy to take address of
+			return &s
+		}
+	}
+	return nil
+}
+
+func listStacks(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	manager := session.NewManager(currentDir)
+
+	stacks, err := manager.Stacks()
+	if err != nil {
+		ui.Errorf("✗ Failed to load stacks: %v", err)
+		return
+	}
+
+	if len(stacks) == 0 {
+		ui.Info("No stacks")
+		ui.Info("  Tip: Create one with 'ccswitch stack create <description>' inside a session")
+		return
+	}
+
+	ui.Title("📚 Stacks")
+	for _, root := range stacks {
+		fmt.Println()
+		fmt.Printf("%s (%s)\n", root.Session.Name, root.Session.Branch)
+		printStackChildren(root.Children, "")
+	}
+
+	for _, root := range stacks {
+		if stackNeedsRestack(root) {
+			fmt.Println()
+			ui.Warningf("⚠ Some sessions are behind their parent. Run 'ccswitch stack restack' to update them.")
+			break
+		}
+	}
+}
+
+// printStackChildren prints stacked sessions as a tree below their parent
+func printStackChildren(children []*session.StackEntry, indent string) {
+	for i, child := range children {
+		branch, next := "├─ ", "│  "
+		if i == len(children)-1 {
+			branch, next = "└─ ", "   "
+		}
+
+		line := fmt.Sprintf("%s%s%s (%s)", indent, branch, child.Session.Name, child.Session.Branch)
+		if child.NeedsRestack {
+			ui.Warningf("%s  ⚠ needs restack", line)
+		} else {
+			fmt.Println(line)
+		}
+		printStackChildren(child.Children, indent+next)
+	}
+}
+
+// stackNeedsRestack reports whether any session in a stack needs restacking
+func stackNeedsRestack(entry *session.StackEntry) bool {
+	if entry.NeedsRestack {
+		return true
+	}
+	for _, child := range entry.Children {
+		if stackNeedsRestack(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func restackSessions(cmd *cobra.Command, args []string) {
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	manager := session.NewManager(currentDir)
+	applySignFlag(cmd, manager)
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	from := ""
+	if len(args) > 0 {
+		from = args[0]
+	}
+
+	targets, err := manager.RestackTargets(from)
+	if err != nil {
+		ui.Errorf("✗ Failed to restack: %v", err)
+		return
+	}
+
+	if len(targets) == 0 {
+		ui.Info("No stacked sessions to restack")
+		return
+	}
+
+	branches := make([]string, len(targets))
+	for i, t := range targets {
+		branches[i] = t.Session.Branch
+	}
+	if !guardProtected(cmd, branches) {
+		return
+	}
+
+	progress := ui.StartProgress(fmt.Sprintf("Restacking %d session(s)", len(targets)))
+	results := manager.Restack(targets)
+	progress.Stop()
+	for _, r := range results {
+		if !r.UpToDate {
+			manager.Record(schema.OpRebase, r.Session, []string{r.Branch}, r.Err)
+		}
+	}
+
+	restacked := 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			ui.Errorf("✗ Failed to restack %s onto %s: %v", r.Session, r.Parent, r.Err)
+			if hint := errors.ErrorHint(r.Err); hint != "" {
+				ui.Infof("  Tip: %s", hint)
+			}
+			switch {
+			case errors.IsRebaseConflict(r.Err):
+				printConflict(r.Err)
+				args := fmt.Sprintf("--onto %s %s", r.Parent, r.Base)
+				printExplanation(cmd, rebaseConflictExplanation(r.Path, r.Branch, args, "ccswitch stack restack"))
+			case errors.IsUncommittedChanges(r.Err):
+				printExplanation(cmd, uncommittedExplanation(r.Path, r.Branch, "ccswitch stack restack"))
+			}
+			return
+		case r.UpToDate:
+			ui.Infof("  %s is up to date with %s", r.Session, r.Parent)
+		default:
+			ui.Successf("✓ Restacked %s onto %s", r.Session, r.Parent)
+			restacked++
+		}
+	}
+
+	fmt.Println()
+	ui.Successf("✓ Restacked %d session(s)", restacked)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show aggregate numbers about sessions and rebases",
+		Long: `Show aggregate numbers about the sessions of the repository: how many
+there are, their average age, the commits they have on top of the current
+branch and the disk space their worktrees use, along with how the rebases
+done by rebase, sync, fanout, stack restack and daemon went.
+
+Rebase outcomes come from the operation history ccswitch records, so they
+only cover rebases since it started recording. --since limits them to a
+recent window.
+
+--json prints the numbers for dashboards, and --prometheus in the Prometheus
+text format, e.g. for node_exporter's textfile collector.
+
+Examples:
+  ccswitch stats
+  ccswitch stats --since 30d
+  ccswitch stats --json
+  ccswitch stats --prometheus > /var/lib/node_exporter/ccswitch.prom`,
+		Args: cobra.NoArgs,
+		Run:  showStats,
+	}
+
+	cmd.Flags().String("since", "", "Only count rebases in this recent window (e.g. 7d, 30d)")
+	cmd.Flags().Bool("json", false, "Output the numbers as JSON")
+	cmd.Flags().Bool("prometheus", false, "Output the numbers as Prometheus metrics")
+	cmd.MarkFlagsMutuallyExclusive("json", "prometheus")
+
+	return cmd
+}
+
+func showStats(cmd *cobra.Command, args []string) {
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	prometheus, _ := cmd.Flags().GetBool("prometheus")
+
+	var since time.Time
+	if sinceFlag != "" {
+		window, err := utils.ParseDuration(sinceFlag)
+		if err != nil {
+			ui.Errorf("✗ Invalid --since: %v", err)
+			return
+		}
+		since = time.Now().Add(-window)
+	}
+
+	// Get current directory
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	currentBranch, err := manager.GetCurrentBranch()
+	if err != nil {
+		ui.Errorf("✗ Failed to get current branch: %v", err)
+		return
+	}
+
+	stats, err := manager.Stats(currentBranch, since)
+	if err != nil {
+		ui.Errorf("✗ Failed to gather stats: %v", err)
+		return
+	}
+
+	switch {
+	case asJSON:
+		if err := schema.Write(os.Stdout, stats); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+	case prometheus:
+		writePrometheusStats(os.Stdout, stats)
+	default:
+		renderStats(stats, sinceFlag)
+	}
+}
+
+// renderStats prints the numbers for people
+func renderStats(stats *schema.Stats, since string) {
+	ui.Titlef("📊 Stats of %s (compared to %s)", stats.Repo, stats.Base)
+	fmt.Println()
+
+	fmt.Printf("  Sessions:             %d\n", stats.Sessions)
+	if stats.AverageAgeSeconds > 0 {
+		fmt.Printf("  Average age:          %s\n", utils.FormatDurationShort(time.Duration(stats.AverageAgeSeconds*float64(time.Second))))
+	}
+	fmt.Printf("  Commits:              %d (%.1f per session)\n", stats.Commits, stats.CommitsPerSession)
+	fmt.Printf("  Disk usage:           %s\n", utils.FormatBytes(stats.DiskBytes))
+	fmt.Println()
+
+	r := stats.Rebases
+	title := "Rebases"
+	if since != "" {
+		title += " in the last " + since
+	}
+	fmt.Printf("  %s:\n", title)
+	if r.Total == 0 {
+		fmt.Println("    None recorded")
+		return
+	}
+	fmt.Printf("    Total:              %d\n", r.Total)
+	fmt.Printf("    Succeeded:          %d (%.0f%%)\n", r.Succeeded, r.SuccessRate*100)
+	fmt.Printf("    Conflicted:         %d (%.0f%%)\n", r.Conflicted, r.ConflictRate*100)
+	if r.Failed > 0 {
+		fmt.Printf("    Failed:             %d\n", r.Failed)
+	}
+	if r.Interrupted > 0 {
+		fmt.Printf("    Interrupted:        %d\n", r.Interrupted)
+	}
+}
+
+// writePrometheusStats writes the numbers in the Prometheus text exposition
+// format, labeled with the repository
+func writePrometheusStats(w io.Writer, stats *schema.Stats) {
+	repo := "repo=" + strconv.Quote(stats.Repo)
+	metric := func(name, kind, help string, values ...string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, v := range values {
+			fmt.Fprintf(w, "%s%s\n", name, v)
+		}
+	}
+	value := func(labels string, v float64) string {
+		return fmt.Sprintf("{%s} %s", labels, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+
+	metric("ccswitch_sessions", "gauge", "Number of sessions.", value(repo, float64(stats.Sessions)))
+	metric("ccswitch_session_age_seconds_average", "gauge", "Average age of the sessions.", value(repo, stats.AverageAgeSeconds))
+	metric("ccswitch_session_commits", "gauge", "Commits of all sessions ahead of the base branch.", value(repo, float64(stats.Commits)))
+	metric("ccswitch_disk_bytes", "gauge", "Disk space used by the session worktrees.", value(repo, float64(stats.DiskBytes)))
+
+	r := stats.Rebases
+	metric("ccswitch_rebases_total", "counter", "Rebases recorded in the operation history, by result.",
+		value(repo+`,result="succeeded"`, float64(r.Succeeded)),
+		value(repo+`,result="conflicted"`, float64(r.Conflicted)),
+		value(repo+`,result="failed"`, float64(r.Failed)),
+		value(repo+`,result="interrupted"`, float64(r.Interrupted)),
+	)
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/format"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/remote"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of all sessions",
+		Long: `Show every session with its branch and state relative to the current branch.
+
+Status glyphs:
+  ●   uncommitted changes
+  ↑N  N commits ahead of the current branch
+  ↓N  N commits behind the current branch
+  ✓   clean and in sync
+
+Sessions whose agents publish their activity with 'ccswitch heartbeat' show
+what they are doing, or that they have gone quiet.
+
+When 'ccswitch daemon' runs for the repository, what its last pass found is
+shown below the sessions: which ones are behind the base and which it rebased.
+
+With --remote, the sessions of another machine running 'ccswitch serve' are
+shown instead, read-only, compared to that machine's current branch.
+
+Examples:
+  ccswitch status                                # Show session state
+  ccswitch status --size                         # Also show disk usage per worktree
+  ccswitch status --absolute                     # Show times as local timestamps instead of "3h ago"
+  ccswitch status --json                         # Machine-readable output
+  ccswitch status --format '{{.Name}}'           # One line per session, see 'ccswitch help format'
+  ccswitch status --remote http://buildbox:7777  # Show a ccswitch serve instance`,
+		Run: showStatus,
+	}
+
+	cmd.Flags().Bool("size", false, "Show disk usage of each worktree (computed in parallel)")
+	cmd.Flags().Bool("absolute", false, "Show created and last-active times as local timestamps")
+	cmd.Flags().Bool("json", false, "Output the status as JSON")
+	addRemoteFlag(cmd)
+	supportsFormat(cmd)
+
+	return cmd
+}
+
+// addRemoteFlag registers --remote on commands that can show the sessions of
+// a ccswitch serve instance
+func addRemoteFlag(cmd *cobra.Command) {
+	cmd.Flags().String("remote", "", "Show sessions of the ccswitch serve instance at this URL (read-only)")
+}
+
+// fetchRemoteStatus fetches the status of the ccswitch serve instance given
+// with --remote and returns it with the instance's host. Errors are reported
+// to the user and nil is returned.
+func fetchRemoteStatus(cmd *cobra.Command) (*schema.Status, string) {
+	remoteURL, _ := cmd.Flags().GetString("remote")
+
+	client, err := remote.New(remoteURL)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return nil, ""
+	}
+
+	doc, err := client.Status()
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		if errors.Is(err, remote.ErrUnauthorized) {
+			ui.Infof("  Tip: Set %s to the token of the remote 'ccswitch serve' (see ~/.ccswitch/serve-token there)", remote.TokenEnv)
+		} else {
+			ui.Info("  Tip: Check that 'ccswitch serve' is running on the remote machine and reachable")
+		}
+		return nil, ""
+	}
+	return doc, client.Host()
+}
+
+func showStatus(cmd *cobra.Command, args []string) {
+	showSize, _ := cmd.Flags().GetBool("size")
+	absolute, _ := cmd.Flags().GetBool("absolute")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	tmpl, err := outputFormat(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if tmpl != nil && (asJSON || showSize) {
+		ui.Error("✗ --format cannot be used with --json or --size")
+		return
+	}
+
+	var doc *schema.Status
+	var host string
+	var syncReport *session.SyncReport
+	if cmd.Flags().Changed("remote") {
+		if showSize {
+			ui.Error("✗ --size is not available with --remote")
+			return
+		}
+		if doc, host = fetchRemoteStatus(cmd); doc == nil {
+			return
+		}
+	} else {
+		// Get current directory
+		currentDir, err := workingDir(cmd)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+
+		// Create session manager
+		manager := session.NewManager(currentDir)
+
+		currentBranch, err := manager.GetCurrentBranch()
+		if err != nil {
+			ui.Errorf("✗ Failed to get current branch: %v", err)
+			return
+		}
+
+		if doc, err = manager.Status(currentBranch); err != nil {
+			ui.Errorf("✗ Failed to list sessions: %v", err)
+			return
+		}
+		syncReport = manager.SyncReport()
+	}
+
+	if asJSON {
+		if err := schema.Write(os.Stdout, doc); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+	if tmpl != nil {
+		if err := renderFormatted(os.Stdout, tmpl, format.Sessions(doc)); err != nil {
+			ui.Errorf("✗ %v", err)
+		}
+		return
+	}
+
+	if len(doc.Sessions) == 0 {
+		ui.Info("No active sessions")
+		return
+	}
+
+	var sizes map[string]int64
+	if showSize {
+		paths := make([]string, len(doc.Sessions))
+		for i, s := range doc.Sessions {
+			paths[i] = s.Path
+		}
+		sizes = utils.DirSizes(paths)
+	}
+
+	if host != "" {
+		ui.Titlef("📋 Sessions of %s on %s (compared to %s)", doc.Repo, host, doc.Base)
+	} else {
+		ui.Titlef("📋 Sessions (compared to %s)", doc.Base)
+	}
+	fmt.Println()
+
+	renderStatus(doc, sizes, absolute)
+	if syncReport != nil {
+		fmt.Println()
+		renderSyncReport(syncReport, absolute)
+	}
+}
+
+// renderSyncReport prints what the last pass of 'ccswitch daemon' found
+func renderSyncReport(report *session.SyncReport, absolute bool) {
+	gray := color.New(color.FgHiBlack)
+
+	gray.Printf("Background sync %s, against %s\n", utils.FormatTime(report.At, absolute), report.Base)
+	if len(report.Behind) == 0 {
+		ui.Success("  ✓ All sessions were up to date")
+	} else {
+		ui.Warningf("  ↓ Behind: %s", behindSummary(report.Behind))
+	}
+	if len(report.Rebased) > 0 {
+		ui.Successf("  ✓ Rebased: %s", strings.Join(report.Rebased, ", "))
+	}
+	if report.FetchError != "" {
+		ui.Warningf("  ⚠ %s", report.FetchError)
+	}
+	for _, e := range report.Errors {
+		ui.Errorf("  ✗ %s", e)
+	}
+	if report.Stale(time.Now()) {
+		ui.Warning("  ⚠ The daemon has missed its last pass and may have stopped")
+	}
+}
+
+// renderStatus prints a status document, with disk usage when sizes is
+// non-nil
+func renderStatus(doc *schema.Status, sizes map[string]int64, absolute bool) {
+	// Color definitions
+	yellow := color.New(color.FgYellow, color.Bold)
+	green := color.New(color.FgGreen)
+	gray := color.New(color.FgHiBlack)
+	cyan := color.New(color.FgCyan)
+
+	var totalSize int64
+	for _, s := range doc.Sessions {
+		statusColor := gray
+		switch {
+		case s.Dirty:
+			statusColor = yellow
+		case s.Ahead > 0:
+			statusColor = green
+		}
+
+		glyphs := ui.StatusGlyphs(git.WorktreeStatus{Dirty: s.Dirty, Ahead: s.Ahead, Behind: s.Behind})
+		if s.Error != "" {
+			glyphs = "?"
+		}
+
+		line := fmt.Sprintf("  %-8s %s (%s)", glyphs, s.Name, git.BranchLabel(s.Branch))
+		if s.Pinned {
+			line += "  📌"
+		}
+		if s.Expired {
+			line += "  ⌛ expired"
+		}
+		if sizes != nil {
+			totalSize += sizes[s.Path]
+			line += fmt.Sprintf("  [%s]", utils.FormatBytes(sizes[s.Path]))
+		}
+		statusColor.Println(line)
+		fmt.Printf("           Path: %s\n", s.Path)
+		if s.Ref != "" {
+			cyan.Printf("           Pinned at: %s\n", s.Ref)
+		}
+		if issue := issueLabel(s.Issue, s.IssueURL); issue != "" {
+			cyan.Printf("           Issue: %s\n", issue)
+		}
+		if len(s.Tags) > 0 {
+			cyan.Printf("           Tags: %s\n", strings.Join(s.Tags, ", "))
+		}
+		if s.Notes != "" {
+			lines := strings.Split(s.Notes, "\n")
+			cyan.Printf("           Notes: %s\n", lines[0])
+			for _, line := range lines[1:] {
+				cyan.Printf("                  %s\n", line)
+			}
+		}
+		if s.Identity != "" {
+			identity := config.Identity{Name: s.UserName, Email: s.UserEmail}
+			cyan.Printf("           Identity: %s (%s)\n", s.Identity, identity)
+		}
+		if times := sessionTimes(s, absolute); times != "" {
+			gray.Printf("           %s\n", times)
+		}
+		if s.Activity != nil {
+			activityColor := cyan
+			if s.Activity.Quiet {
+				activityColor = yellow
+			}
+			activityColor.Printf("           Activity: %s\n", activitySummary(s.Activity, time.Now()))
+		}
+	}
+
+	if sizes != nil {
+		fmt.Println()
+		ui.Infof("Total disk usage: %s", utils.FormatBytes(totalSize))
+	}
+}
+
+// sessionTimes describes when a session was created, last had a commit, was
+// last used and expires, relative to now unless absolute is set
+func sessionTimes(s schema.StatusSession, absolute bool) string {
+	var parts []string
+	if s.CreatedAt != nil {
+		parts = append(parts, "Created "+utils.FormatTime(*s.CreatedAt, absolute))
+	}
+	if s.LastActive != nil {
+		parts = append(parts, "Last active "+utils.FormatTime(*s.LastActive, absolute))
+	}
+	if s.LastUsed != nil {
+		parts = append(parts, "Last used "+utils.FormatTime(*s.LastUsed, absolute))
+	}
+	if s.ExpiresAt != nil {
+		verb := "Expires "
+		if s.Expired {
+			verb = "Expired "
+		}
+		parts = append(parts, verb+utils.FormatTime(*s.ExpiresAt, absolute))
+	}
+	return strings.Join(parts, " · ")
+}
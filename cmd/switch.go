@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/integrations"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/ksred/ccswitch/internal/utils"
@@ -18,9 +19,24 @@ func newSwitchCmd() *cobra.Command {
 		Long: `Switch to a specific session by name.
 
 The session name can be a partial match or the full name.
-If multiple sessions match, the first one is selected.`,
-		Args: cobra.ExactArgs(1),
-		Run:  switchSession,
+If multiple sessions match, the first one is selected.
+
+Tools listed under integrations in the config are set up in the session's
+worktree whenever it is switched to or created, if the worktree uses them
+and they are installed:
+
+  integrations: [direnv, mise]
+
+  direnv  .envrc is allowed and its variables are loaded
+  mise    mise.toml is trusted and the tool versions it and .tool-versions
+          pin are put on PATH
+
+The shell integration loads the environments after changing directory, so
+each worktree gets the right toolchain even without the tools' own shell
+hooks.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               switchSession,
 	}
 }
 
@@ -28,9 +44,9 @@ func switchSession(cmd *cobra.Command, args []string) {
 	sessionName := args[0]
 
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
@@ -66,14 +82,15 @@ func switchSession(cmd *cobra.Command, args []string) {
 		}
 		return
 	}
+	_ = manager.MarkUsed(*selected)
 
 	// Output success message with consistent formatting
 	ui.Successf("✓ Switched to session: %s", selected.Name)
-	fmt.Printf("Branch: %s\n", selected.Branch)
+	fmt.Printf("Branch: %s\n", selected.BranchLabel())
 	fmt.Printf("Location: %s\n", selected.Path)
 
 	// Output the cd command for shell evaluation
-	fmt.Printf("\ncd %s\n", selected.Path)
+	printCd(cmd, selected.Path)
 
 	// If shell integration is not active, show a helpful message
 	if !utils.IsShellIntegrationActive() {
@@ -82,3 +99,35 @@ func switchSession(cmd *cobra.Command, args []string) {
 		fmt.Println(utils.GetShellIntegrationInstructions())
 	}
 }
+
+// printCd prints the cd command the shell integration runs to enter dir,
+// after setting the configured integrations up there. The shell code
+// loading their environments follows the cd on the same line.
+func printCd(cmd *cobra.Command, dir string) {
+	env, err := integrations.Setup(dir, loadConfig(cmd).Integrations)
+	if err != nil {
+		ui.Warningf("⚠ %v", err)
+	}
+	writeCd(dir, env...)
+}
+
+// cdFileEnv names the file the PowerShell integration reads the directory
+// to change to from, since it runs ccswitch attached to the console rather
+// than reading its output
+const cdFileEnv = "CCSWITCH_CD_FILE"
+
+// writeCd prints the cd command for dir, quoted for the shell, followed by
+// the shell code in env, and records dir in the file named by CCSWITCH_CD_FILE when it is set
+func writeCd(dir string, env ...string) {
+	line := "cd " + shellWord(dir)
+	for _, code := range env {
+		line += " && " + code
+	}
+	fmt.Printf("\n%s\n", line)
+
+	if path := os.Getenv(cdFileEnv); path != "" {
+		if err := os.WriteFile(path, []byte(dir+"\n"), 0600); err != nil {
+			ui.Warningf("⚠ Failed to write %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/fanout"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [session]",
+		Short: "Rebase a session onto its base branch",
+		Long: `Rebase a session's branch onto the branch it builds on, to pick up what
+happened there since the session started.
+
+The base is the session's stack parent, or the branch it was created from
+(see 'ccswitch create --base'), or else the current branch. --onto rebases
+onto another branch instead. The session must have no uncommitted changes;
+a rebase that conflicts is aborted, leaving the session as it was. With
+git.verify_command set, the command checks the result and the rebase is
+rolled back if it fails.
+
+Examples:
+  ccswitch sync                      # Choose the session to sync
+  ccswitch sync fix-login            # Rebase onto its base branch
+  ccswitch sync fix-login --onto origin/main
+  ccswitch sync fix-login --push     # Force-push the result upstream`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               syncSession,
+	}
+
+	cmd.Flags().String("onto", "", "Rebase onto this branch instead of the session's base branch")
+	_ = cmd.RegisterFlagCompletionFunc("onto", completeBaseBranch)
+	addPushFlag(cmd)
+	addSignFlag(cmd)
+	addForceFlag(cmd)
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func syncSession(cmd *cobra.Command, args []string) {
+	onto, _ := cmd.Flags().GetString("onto")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+	sign := applySignFlag(cmd, manager)
+
+	selected := resolveSession(cmd, manager, args, "Select session to sync:")
+	if selected == nil {
+		return
+	}
+	if selected.Detached() {
+		ui.Errorf("✗ %s is detached at %s and has no branch to sync", selected.Name, manager.Ref(*selected))
+		return
+	}
+	if onto == "" {
+		onto = manager.BaseBranch(*selected)
+	}
+	if onto == "" || onto == selected.Branch {
+		ui.Errorf("✗ %s has no base branch to sync with; pass --onto to name one", selected.Branch)
+		return
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	if !guardProtected(cmd, []string{selected.Branch}) {
+		return
+	}
+
+	// Refuse before rewriting anything if the result can't be pushed
+	var upstreams map[string]git.Upstream
+	push := pushEnabled(cmd)
+	if push {
+		var ok bool
+		if upstreams, ok = resolveUpstreams(currentDir, []string{selected.Branch}); !ok {
+			return
+		}
+	}
+
+	wt := git.Worktree{Path: selected.Path, Branch: selected.Branch}
+	engine := fanout.New(onto, nil)
+	engine.Sign(sign)
+	engine.Verify(manager.Config().Git.VerifyCommand)
+
+	check := engine.Check([]git.Worktree{wt})[0]
+	switch {
+	case check.Err != nil:
+		ui.Errorf("✗ %v", check.Err)
+		return
+	case check.Dirty:
+		err := fmt.Errorf("%w in %s", errors.ErrUncommittedChanges, selected.Name)
+		ui.Errorf("✗ %v", err)
+		ui.Infof("  Tip: %s", errors.ErrorHint(err))
+		return
+	case check.Behind == 0:
+		ui.Successf("✓ %s is up to date with %s", selected.Name, onto)
+		return
+	}
+
+	stop := proc.OnInterrupt(engine.Interrupt)
+	progress := ui.StartProgress(fmt.Sprintf("Rebasing %s onto %s (%d new commit(s))", selected.Branch, onto, check.Behind))
+	result := engine.Run([]git.Worktree{wt})[0]
+	progress.Stop()
+	stop()
+	manager.Record(schema.OpRebase, selected.Name, []string{selected.Branch}, result.Err)
+
+	switch result.Status {
+	case fanout.StatusSucceeded:
+		ui.Successf("✓ Synced %s with %s", selected.Name, onto)
+	case fanout.StatusConflicted:
+		ui.Errorf("✗ Conflict rebasing %s onto %s, auto-aborted", selected.Branch, onto)
+		printConflict(result.Err)
+		printExplanation(cmd, rebaseConflictExplanation(wt.Path, wt.Branch, onto, "ccswitch sync"))
+		return
+	case fanout.StatusInterrupted:
+		ui.Warningf("⚠ Interrupted while rebasing %s, auto-aborted", selected.Branch)
+		return
+	default:
+		ui.Errorf("✗ Failed: %v", result.Err)
+		if hint := errors.ErrorHint(result.Err); hint != "" {
+			ui.Infof("  Tip: %s", hint)
+		}
+		return
+	}
+
+	if push {
+		fmt.Println()
+		pushBranches(currentDir, []string{selected.Branch}, upstreams)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newTagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag <session> [tag...]",
+		Short: "Add or remove the tags of a session",
+		Long: `Label a session with tags, e.g. the area it touches or its priority, so
+that 'ccswitch list --tag' can filter on them. Tags are single words.
+
+Without tags, the session's tags are shown. --remove takes the given tags
+off instead of adding them. New sessions can be tagged with
+'ccswitch create --tag'.
+
+Examples:
+  ccswitch tag fix-login frontend urgent
+  ccswitch tag fix-login --remove urgent
+  ccswitch tag fix-login`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSession,
+		Run:               tagSession,
+	}
+
+	cmd.Flags().Bool("remove", false, "Remove the given tags instead of adding them")
+
+	return cmd
+}
+
+func tagSession(cmd *cobra.Command, args []string) {
+	remove, _ := cmd.Flags().GetBool("remove")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	selected := resolveSession(cmd, manager, args[:1], "")
+	if selected == nil {
+		return
+	}
+
+	if len(args) == 1 {
+		tags := manager.Tags(*selected)
+		if len(tags) == 0 {
+			ui.Infof("%s has no tags", selected.Name)
+			return
+		}
+		fmt.Println(strings.Join(tags, "\n"))
+		return
+	}
+
+	add, drop := args[1:], []string(nil)
+	if remove {
+		add, drop = nil, args[1:]
+	}
+	tags, err := manager.TagSession(*selected, add, drop)
+	if err != nil {
+		ui.Errorf("✗ Failed to tag %s: %v", selected.Name, err)
+		return
+	}
+
+	if len(tags) == 0 {
+		ui.Successf("✓ %s has no tags", selected.Name)
+		return
+	}
+	ui.Successf("✓ Tagged %s: %s", selected.Name, strings.Join(tags, ", "))
+}
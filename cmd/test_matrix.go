@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// testMatrixOutputLines is how much of a failing command's output is kept
+const testMatrixOutputLines = 20
+
+func newTestMatrixCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test-matrix -- <command> [args...]",
+		Short: "Run a command in every session and compare the results",
+		Long: `Run the same command in the worktree of every session, one after the
+other, and show which sessions passed with how long each took. Useful for
+checking which feature branches still pass the test suite after a fanout.
+
+The command's output is captured; the last lines of it are shown for
+sessions where it failed. As with work, the command sees CCSWITCH_SESSION,
+CCSWITCH_BRANCH, CCSWITCH_WORKTREE and CCSWITCH_BASE_BRANCH describing the
+session. ccswitch exits with 1 if the command failed in any session.
+
+Examples:
+  ccswitch test-matrix -- go test ./...
+  ccswitch test-matrix -- make lint
+  ccswitch test-matrix --json -- npm test    # Machine-readable results`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  runTestMatrix,
+	}
+
+	cmd.Flags().Bool("json", false, "Output the results as JSON")
+
+	return cmd
+}
+
+func runTestMatrix(cmd *cobra.Command, args []string) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		os.Exit(1)
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		ui.Errorf("✗ Failed to list sessions: %v", err)
+		os.Exit(1)
+	}
+	if len(sessions) == 0 {
+		ui.Info("No active sessions")
+		return
+	}
+
+	if asJSON {
+		// Keep stdout for the document
+		ui.SetQuiet(true)
+		ui.UseStderr()
+	}
+
+	// Finish the session in progress on Ctrl+C, whose command is
+	// interrupted with it, but don't start any more
+	var interrupted atomic.Bool
+	stop := proc.OnInterrupt(func() { interrupted.Store(true) })
+	defer stop()
+
+	doc := schema.TestMatrix{Header: schema.NewHeader(), Command: args}
+	for _, s := range sessions {
+		if interrupted.Load() {
+			break
+		}
+		progress := ui.StartProgress(fmt.Sprintf("Running in %s", s.Name))
+		result := runMatrixSession(s, args, manager.Env(os.Environ(), s))
+		progress.Stop()
+
+		if result.Passed {
+			doc.Passed++
+			ui.Successf("  ✓ %s passed (%s)", s.Name, matrixDuration(result.DurationSeconds))
+		} else {
+			doc.Failed++
+			ui.Errorf("  ✗ %s failed with exit code %d (%s)", s.Name, result.ExitCode, matrixDuration(result.DurationSeconds))
+		}
+		doc.Results = append(doc.Results, result)
+	}
+
+	if asJSON {
+		if err := schema.Write(os.Stdout, doc); err != nil {
+			ui.Errorf("✗ %v", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Println()
+		renderTestMatrix(doc)
+	}
+
+	if notRun := len(sessions) - len(doc.Results); notRun > 0 {
+		ui.Warningf("⚠ Interrupted, %d session(s) not run", notRun)
+	}
+	if doc.Failed > 0 || len(doc.Results) < len(sessions) {
+		os.Exit(1)
+	}
+}
+
+// runMatrixSession runs the command in args in the worktree of s with env,
+// capturing its output, and returns the outcome
+func runMatrixSession(s git.SessionInfo, args []string, env []string) schema.TestMatrixResult {
+	c := exec.Command(args[0], args[1:]...)
+	c.Dir = s.Path
+	c.Env = env
+
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+
+	start := time.Now()
+	err := proc.Run(c, proc.DefaultGracePeriod)
+	result := schema.TestMatrixResult{
+		Session:         s.Name,
+		Branch:          s.Branch,
+		Path:            s.Path,
+		Passed:          err == nil,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		result.ExitCode = proc.ExitCode(err)
+		result.Output = utils.LastLines(output.String(), testMatrixOutputLines)
+		if result.Output == "" {
+			result.Output = err.Error()
+		}
+	}
+	return result
+}
+
+// renderTestMatrix prints the results as a table, followed by the output
+// of the failed sessions
+func renderTestMatrix(doc schema.TestMatrix) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed, color.Bold)
+	gray := color.New(color.FgHiBlack)
+
+	ui.Titlef("🧪 %s", strings.Join(doc.Command, " "))
+	fmt.Println()
+	fmt.Printf("  %-24s %-32s %-6s %5s %9s\n", "SESSION", "BRANCH", "RESULT", "EXIT", "DURATION")
+	for _, r := range doc.Results {
+		resultColor, label := green, "pass"
+		if !r.Passed {
+			resultColor, label = red, "FAIL"
+		}
+		resultColor.Printf("  %-24s %-32s %-6s %5d %9s\n", r.Session, r.Branch, label, r.ExitCode, matrixDuration(r.DurationSeconds))
+	}
+
+	for _, r := range doc.Results {
+		if r.Passed {
+			continue
+		}
+		fmt.Println()
+		red.Printf("── %s (%s)\n", r.Session, r.Branch)
+		for _, line := range strings.Split(r.Output, "\n") {
+			gray.Printf("   %s\n", line)
+		}
+	}
+
+	fmt.Println()
+	ui.Infof("%d passed, %d failed", doc.Passed, doc.Failed)
+}
+
+// matrixDuration formats a duration in seconds to a tenth of a second
+func matrixDuration(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestRunMatrixSession(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	s := git.SessionInfo{Name: "auth", Branch: "feature/auth", Path: t.TempDir()}
+
+	passed := runMatrixSession(s, []string{"sh", "-c", "echo ok"}, nil)
+	if !passed.Passed || passed.ExitCode != 0 || passed.Output != "" {
+		t.Errorf("passing command gave %+v", passed)
+	}
+	if passed.Session != "auth" || passed.Branch != "feature/auth" || passed.Path != s.Path {
+		t.Errorf("result lacks the session: %+v", passed)
+	}
+
+	failed := runMatrixSession(s, []string{"sh", "-c", "seq 1 30; exit 3"}, nil)
+	if failed.Passed || failed.ExitCode != 3 {
+		t.Errorf("failing command gave %+v", failed)
+	}
+	if lines := strings.Split(failed.Output, "\n"); len(lines) != testMatrixOutputLines || lines[len(lines)-1] != "30" {
+		t.Errorf("expected the last %d lines of output, got %q", testMatrixOutputLines, failed.Output)
+	}
+
+	missing := runMatrixSession(s, []string{"ccswitch-no-such-command"}, nil)
+	if missing.Passed || missing.ExitCode != 127 || missing.Output == "" {
+		t.Errorf("missing command gave %+v", missing)
+	}
+}
+
+func TestMatrixDuration(t *testing.T) {
+	if got := matrixDuration(4.237); got != "4.2s" {
+		t.Errorf("matrixDuration(4.237) = %q, expected 4.2s", got)
+	}
+}
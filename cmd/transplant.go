@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newTransplantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transplant <from-session> [to-session]",
+		Short: "Move uncommitted changes from one session to another",
+		Long: `Apply the uncommitted changes of one session to another session, or to
+the main worktree if none is named: changes to tracked files, staged or
+not, and untracked files that are not ignored. Useful when an agent worked
+in the wrong session.
+
+The changes stay in the source session unless --move is given, which
+discards them there once they applied cleanly. Changes that don't apply
+cleanly are merged with a 3-way merge, leaving conflict markers in the
+files that conflict for you to resolve.
+
+Examples:
+  ccswitch transplant fix-login               # Into the main worktree
+  ccswitch transplant fix-login add-tests
+  ccswitch transplant fix-login add-tests --move`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeSession,
+		Run:               transplantChanges,
+	}
+
+	cmd.Flags().Bool("move", false, "Discard the changes in the source session once they applied cleanly")
+	addWaitFlag(cmd)
+
+	return cmd
+}
+
+func transplantChanges(cmd *cobra.Command, args []string) {
+	move, _ := cmd.Flags().GetBool("move")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	from := resolveSession(cmd, manager, args[:1], "")
+	if from == nil {
+		return
+	}
+	var to *git.SessionInfo
+	if len(args) > 1 {
+		if to = resolveSession(cmd, manager, args[1:], ""); to == nil {
+			return
+		}
+	} else {
+		mainPath, err := git.GetMainRepoPath(currentDir)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		branch, _ := git.GetCurrentBranch(mainPath)
+		to = &git.SessionInfo{Name: "main", Branch: branch, Path: mainPath}
+	}
+	if filepath.Clean(from.Path) == filepath.Clean(to.Path) {
+		ui.Error("✗ The source and target are the same worktree")
+		return
+	}
+
+	lock := lockRepo(cmd, manager)
+	if lock == nil {
+		return
+	}
+	defer lock.Release()
+
+	if !git.HasUncommittedChanges(from.Path) {
+		ui.Infof("No uncommitted changes in %s to transplant", from.Name)
+		return
+	}
+
+	patch, err := git.UncommittedPatch(from.Path)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	if git.HasUncommittedChanges(to.Path) {
+		ui.Warningf("⚠ %s has uncommitted changes of its own; the transplanted changes are added to them", to.Name)
+	}
+
+	conflicted, err := git.ApplyPatch(to.Path, patch)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		ui.Infof("  %s was left unchanged", to.Name)
+		return
+	}
+
+	if len(conflicted) > 0 {
+		ui.Warningf("⚠ Transplanted changes from %s to %s with a 3-way merge; %d file(s) conflict:", from.Name, to.Name, len(conflicted))
+		for _, file := range conflicted {
+			fmt.Printf("  %s\n", file)
+		}
+		ui.Infof("  Tip: Resolve the conflict markers in %s, then 'git add' the files", to.Path)
+		if move {
+			ui.Infof("  The changes were kept in %s because of the conflicts", from.Name)
+		}
+		return
+	}
+
+	ui.Successf("✓ Transplanted changes from %s to %s (%s)", from.Name, to.Name, to.BranchLabel())
+	if !move {
+		return
+	}
+	if err := git.DiscardChanges(from.Path); err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+	ui.Infof("Discarded the changes in %s", from.Name)
+}
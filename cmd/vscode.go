@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os/exec"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newVSCodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vscode",
+		Short: "Generate a VS Code workspace with every session",
+		Long: `Write a VS Code multi-root workspace with a folder for each session,
+named after the session and its branch, so all worktrees can be seen side by
+side in one window.
+
+The workspace is written to ~/.ccswitch/workspaces/<repo>.code-workspace
+unless --output names another file, and is kept up to date from then on:
+creating, deleting, renaming, moving and adopting sessions rewrite its
+folders, and VS Code picks the change up. Settings and other entries added
+to the file are kept, but comments are not supported. --forget stops
+updating it.
+
+Examples:
+  ccswitch vscode
+  ccswitch vscode --open
+  ccswitch vscode --output ~/work/project.code-workspace
+  ccswitch vscode --forget`,
+		Args: cobra.NoArgs,
+		Run:  generateWorkspace,
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Write the workspace to this file")
+	cmd.Flags().Bool("open", false, "Open the workspace in VS Code")
+	cmd.Flags().Bool("forget", false, "Stop keeping the workspace up to date")
+	cmd.MarkFlagsMutuallyExclusive("forget", "output")
+	cmd.MarkFlagsMutuallyExclusive("forget", "open")
+
+	return cmd
+}
+
+func generateWorkspace(cmd *cobra.Command, args []string) {
+	output, _ := cmd.Flags().GetString("output")
+	open, _ := cmd.Flags().GetBool("open")
+	forget, _ := cmd.Flags().GetBool("forget")
+
+	currentDir, err := workingDir(cmd)
+	if err != nil {
+		ui.Errorf("✗ %v", err)
+		return
+	}
+
+	// Create session manager
+	manager := session.NewManager(currentDir)
+
+	if forget {
+		path, err := manager.ForgetWorkspace()
+		switch {
+		case err != nil:
+			ui.Errorf("✗ %v", err)
+		case path == "":
+			ui.Info("No workspace is kept up to date")
+		default:
+			ui.Successf("✓ Stopped updating %s", abbreviateHome(path))
+		}
+		return
+	}
+
+	if output == "" {
+		output = manager.WorkspacePath()
+	}
+	if output == "" {
+		output = manager.DefaultWorkspacePath()
+	}
+	if err := manager.WriteWorkspace(output); err != nil {
+		ui.Errorf("✗ Failed to write the workspace: %v", err)
+		return
+	}
+	path := manager.WorkspacePath()
+	ui.Successf("✓ Wrote %s", abbreviateHome(path))
+	ui.Info("It is kept up to date as sessions change")
+
+	if !open {
+		return
+	}
+	if _, err := exec.LookPath("code"); err != nil {
+		ui.Error("✗ The VS Code command line tool (code) is not on PATH")
+		ui.Info("  Tip: In VS Code, run 'Shell Command: Install code command in PATH'")
+		return
+	}
+	if err := exec.Command("code", path).Start(); err != nil {
+		ui.Errorf("✗ Failed to open VS Code: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/remote"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Monitor all sessions live",
+		Long: `Show a live table of all sessions, refreshed every few seconds, to monitor
+several agents working in parallel. Sessions that change, e.g. because an
+agent committed or published a heartbeat, are highlighted and listed under
+"Recent changes".
+
+Locally, commits, checkouts, staging and new sessions show up as soon as git
+records them; edits that are not staged yet show up at the next refresh,
+which only asks git about sessions whose state may have changed.
+
+With --no-tui, or when output is not a terminal, each change is printed as a
+line instead, which suits logs and narrow terminals.
+
+Examples:
+  ccswitch watch
+  ccswitch watch --interval 10s
+  ccswitch watch --remote http://buildbox:7777
+  ccswitch watch --no-tui >> agents.log`,
+		Args: cobra.NoArgs,
+		Run:  watchSessions,
+	}
+
+	cmd.Flags().Duration("interval", 2*time.Second, "How often to refresh")
+	addRemoteFlag(cmd)
+
+	return cmd
+}
+
+func watchSessions(cmd *cobra.Command, args []string) {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval < 500*time.Millisecond {
+		ui.Error("✗ --interval must be at least 500ms")
+		return
+	}
+
+	var load func() (*schema.Status, error)
+	var changes <-chan []string
+	title := "👀 Watching sessions"
+
+	if cmd.Flags().Changed("remote") {
+		remoteURL, _ := cmd.Flags().GetString("remote")
+		client, err := remote.New(remoteURL)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+		load = client.Status
+		title = fmt.Sprintf("👀 Watching sessions on %s", client.Host())
+	} else {
+		// Get current directory
+		currentDir, err := workingDir(cmd)
+		if err != nil {
+			ui.Errorf("✗ %v", err)
+			return
+		}
+
+		// Create session manager
+		manager := session.NewManager(currentDir)
+
+		// Without a watcher, changes are only seen every interval
+		watcher, err := git.NewWatcher(git.DefaultStatusCache)
+		if err == nil {
+			defer watcher.Close()
+			changes = watcher.Changes
+			_ = watcher.Add(currentDir)
+		}
+
+		load = func() (*schema.Status, error) {
+			// Follow the main repository if it switches branches
+			currentBranch, err := manager.GetCurrentBranch()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get current branch: %w", err)
+			}
+			doc, err := manager.Status(currentBranch)
+			if err == nil && watcher != nil {
+				// Sessions created since the last load are watched from now on
+				for _, s := range doc.Sessions {
+					_ = watcher.Add(s.Path)
+				}
+			}
+			return doc, err
+		}
+	}
+
+	if noTUI, _ := cmd.Flags().GetBool("no-tui"); noTUI || !ui.Interactive() {
+		watchPlain(load, interval, changes)
+		return
+	}
+
+	view := ui.NewWatchView(title, interval, load)
+	view.ReloadOn(changes)
+	if _, err := tea.NewProgram(view, tea.WithAltScreen()).Run(); err != nil {
+		ui.Errorf("✗ Failed to run watch: %v", err)
+	}
+}
+
+// watchPlain prints a line for every change until interrupted, loading
+// every interval and whenever changes receives
+func watchPlain(load func() (*schema.Status, error), interval time.Duration, changes <-chan []string) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *schema.Status
+	for {
+		doc, err := load()
+		now := time.Now().Format("15:04:05")
+		switch {
+		case err != nil:
+			ui.Errorf("%s ✗ %v", now, err)
+		case prev == nil:
+			fmt.Printf("%s watching %d session(s) of %s, compared to %s\n", now, len(doc.Sessions), doc.Repo, doc.Base)
+		default:
+			for _, c := range ui.DiffStatus(prev, doc) {
+				fmt.Printf("%s %s: %s\n", now, c.Session, c.What)
+			}
+		}
+		if err == nil {
+			prev = doc
+		}
+
+		select {
+		case <-interrupt:
+			return
+		case <-ticker.C:
+		case <-changes:
+			ticker.Reset(interval)
+		}
+	}
+}
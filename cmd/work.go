@@ -6,8 +6,10 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/notify"
+	"github.com/ksred/ccswitch/internal/proc"
 	"github.com/ksred/ccswitch/internal/session"
 	"github.com/ksred/ccswitch/internal/ui"
 	"github.com/spf13/cobra"
@@ -24,6 +26,20 @@ This command will:
 2. Let you select which session to work in
 3. Execute the specified command in that session's directory
 
+The command sees CCSWITCH_SESSION, CCSWITCH_BRANCH, CCSWITCH_WORKTREE and
+CCSWITCH_BASE_BRANCH describing the session, so build scripts and prompts
+can adapt to it, along with the session's ports and variables (see
+'ccswitch env').
+
+The command runs in its own process group. If ccswitch is interrupted or
+terminated, the command and everything it started are stopped (SIGTERM,
+then SIGKILL after 5 seconds), so dev servers don't outlive the run.
+
+With "notify: true" in the config, a desktop notification reports when the
+command finishes and whether it succeeded, so you can leave it running in
+another window. It uses osascript on macOS, notify-send on Linux and a
+toast on Windows.
+
 Examples:
   ccswitch work make build
   ccswitch work npm test
@@ -35,9 +51,9 @@ Examples:
 
 func workCommand(cmd *cobra.Command, args []string) {
 	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := workingDir(cmd)
 	if err != nil {
-		ui.Error("✗ Failed to get current directory")
+		ui.Errorf("✗ %v", err)
 		return
 	}
 
@@ -56,20 +72,7 @@ func workCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Use interactive selector
-	selector := ui.NewSessionSelector(sessions)
-	p := tea.NewProgram(selector)
-
-	if _, err := p.Run(); err != nil {
-		ui.Errorf("✗ Failed to run selector: %v", err)
-		return
-	}
-
-	if selector.IsQuit() {
-		return
-	}
-
-	selected := selector.GetSelected()
+	selected := pickSession(cmd, manager, sessions, "Select session to work in:")
 	if selected == nil {
 		return
 	}
@@ -86,18 +89,37 @@ func workCommand(cmd *cobra.Command, args []string) {
 	ui.Infof("  Location: %s", selected.Path)
 	fmt.Println()
 
-	err = executeInDir(selected.Path, commandName, commandArgs)
+	_ = manager.MarkUsed(*selected)
+	started := time.Now()
+	err = executeInDir(selected.Path, commandName, commandArgs, manager.Env(os.Environ(), *selected))
+	if loadConfig(cmd).Notify {
+		notifyWorkDone(selected.Name, strings.Join(args, " "), err, time.Since(started))
+	}
 	if err != nil {
 		ui.Errorf("✗ Command execution failed: %v", err)
-		os.Exit(1)
+		os.Exit(proc.ExitCode(err))
+	}
+}
+
+// notifyWorkDone shows a desktop notification that command, run in the
+// named session, finished with err after duration
+func notifyWorkDone(session, command string, err error, duration time.Duration) {
+	message := fmt.Sprintf("%s succeeded in %s", command, duration.Round(time.Second))
+	if err != nil {
+		message = fmt.Sprintf("%s failed (%v) after %s", command, err, duration.Round(time.Second))
+	}
+	if err := notify.Desktop("ccswitch: "+session, message); err != nil {
+		ui.Warningf("⚠ Desktop notification failed: %v", err)
 	}
 }
 
-// executeInDir executes a command in the specified directory
-func executeInDir(dir, command string, args []string) error {
+// executeInDir executes a command in the specified directory with exactly
+// the environment env
+func executeInDir(dir, command string, args []string, env []string) error {
 	// Create the command
 	cmd := exec.Command(command, args...)
 	cmd.Dir = dir
+	cmd.Env = env
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -106,11 +128,11 @@ func executeInDir(dir, command string, args []string) error {
 	if runtime.GOOS == "windows" {
 		// Check if this is a shell built-in or batch file
 		if isShellBuiltin(command) {
-			return executeViaShell(dir, command, args)
+			return executeViaShell(dir, command, args, env)
 		}
 	}
 
-	return cmd.Run()
+	return proc.Run(cmd, proc.DefaultGracePeriod)
 }
 
 // isShellBuiltin checks if a command is a shell built-in (Windows)
@@ -123,7 +145,7 @@ func isShellBuiltin(command string) bool {
 }
 
 // executeViaShell executes a command via the system shell
-func executeViaShell(dir, command string, args []string) error {
+func executeViaShell(dir, command string, args []string, env []string) error {
 	var shellCmd []string
 
 	if runtime.GOOS == "windows" {
@@ -142,9 +164,10 @@ func executeViaShell(dir, command string, args []string) error {
 
 	cmd := exec.Command(shellCmd[0], shellCmd[1:]...)
 	cmd.Dir = dir
+	cmd.Env = env
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	return cmd.Run()
+	return proc.Run(cmd, proc.DefaultGracePeriod)
 }
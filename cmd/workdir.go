@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/i18n"
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/ksred/ccswitch/internal/ui"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// workingDir returns the directory a command operates on: the repository
+// given with --repo, as a path or by the name of a known repository, or the
+// current directory
+func workingDir(cmd *cobra.Command) (string, error) {
+	repo, _ := cmd.Flags().GetString("repo")
+	if repo == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return dir, nil
+	}
+
+	dir, err := filepath.Abs(expandHome(repo))
+	if err != nil {
+		return "", fmt.Errorf("invalid repository path %s: %w", repo, err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if !isRepoName(repo) {
+			return "", fmt.Errorf("repository path %s does not exist", dir)
+		}
+		known, err := session.FindRepo(repo)
+		if err != nil {
+			return "", err
+		}
+		dir = known.Path
+		if _, err := os.Stat(dir); err != nil {
+			return "", fmt.Errorf("repository %s no longer exists at %s", repo, dir)
+		}
+	}
+	if !git.IsGitRepository(dir) {
+		return "", fmt.Errorf("%s is not a git repository", dir)
+	}
+	return dir, nil
+}
+
+// isRepoName reports whether a --repo value can be the name of a known
+// repository rather than a path
+func isRepoName(repo string) bool {
+	return repo != "" && !strings.ContainsAny(repo, `/\`) && !strings.HasPrefix(repo, "~") && !strings.HasPrefix(repo, ".")
+}
+
+// registerRepo adds the repository a command runs in to the registry of
+// known repositories, so it can later be named with --repo
+func registerRepo(cmd *cobra.Command) {
+	dir, err := workingDir(cmd)
+	if err != nil {
+		return
+	}
+	if root, err := git.GetMainRepoPath(dir); err == nil {
+		_ = session.RegisterRepo(root)
+	}
+}
+
+// loadConfig loads the configuration for the repository ccswitch operates on,
+// including its .ccswitch/config.yaml, or the global configuration outside a
+// repository
+func loadConfig(cmd *cobra.Command) *config.Config {
+	if dir, err := workingDir(cmd); err == nil {
+		if root, err := git.GetMainRepoPath(dir); err == nil {
+			cfg, _ := config.LoadForRepo(root)
+			return cfg
+		}
+	}
+	cfg, _ := config.Load()
+	return cfg
+}
+
+// configureLanguage shows ccswitch's messages in ui.language, or in the
+// language of the locale when it is not set
+func configureLanguage(cmd *cobra.Command) {
+	setting := loadConfig(cmd).UI.Language
+	if setting != "" && setting != "auto" && i18n.Detect(setting) == i18n.English && !strings.HasPrefix(strings.ToLower(setting), i18n.English) {
+		ui.Warningf("⚠ Ignoring ui.language %q: messages are available in %s", setting, strings.Join(i18n.Languages(), ", "))
+	}
+	i18n.SetLanguage(i18n.Detect(setting))
+}
+
+// configureGit makes the git commands ccswitch runs honor git.timeout, and
+// keeps the status cache on disk if git.status_cache is set
+func configureGit(cmd *cobra.Command) {
+	cfg := loadConfig(cmd)
+	if cfg.Git.Timeout != "" {
+		if timeout, err := utils.ParseDuration(cfg.Git.Timeout); err != nil {
+			ui.Warningf("⚠ Ignoring git.timeout: %v", err)
+		} else {
+			git.DefaultRunner = &git.ExecRunner{Timeout: timeout}
+		}
+	}
+
+	if cfg.Git.StatusCache == "" {
+		return
+	}
+	maxAge, err := utils.ParseDuration(cfg.Git.StatusCache)
+	if err != nil {
+		ui.Warningf("⚠ Ignoring git.status_cache: %v", err)
+		return
+	}
+	dir, err := workingDir(cmd)
+	if err != nil {
+		return
+	}
+	if root, err := git.GetMainRepoPath(dir); err == nil {
+		git.DefaultStatusCache.Dir = session.StatusCacheDir(filepath.Base(root))
+		git.DefaultStatusCache.MaxAge = maxAge
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/session"
+	"github.com/spf13/cobra"
+)
+
+func newRepoFlagCmd(repo string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "", "")
+	if repo != "" {
+		_ = cmd.Flags().Set("repo", repo)
+	}
+	return cmd
+}
+
+func TestWorkingDir(t *testing.T) {
+	repo := t.TempDir()
+	if err := exec.Command("git", "init", repo).Run(); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+
+	dir, err := workingDir(newRepoFlagCmd(repo))
+	if err != nil || dir != repo {
+		t.Errorf("workingDir(--repo %s) = %q, %v", repo, dir, err)
+	}
+
+	if _, err := workingDir(newRepoFlagCmd(t.TempDir())); err == nil {
+		t.Error("expected an error for a directory that is not a git repository")
+	}
+
+	if _, err := workingDir(newRepoFlagCmd("/does/not/exist")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	if err := session.RegisterRepo(repo); err != nil {
+		t.Fatalf("RegisterRepo failed: %v", err)
+	}
+	name := filepath.Base(repo)
+	if dir, err := workingDir(newRepoFlagCmd(name)); err != nil || dir != repo {
+		t.Errorf("workingDir(--repo %s) = %q, %v, expected %q", name, dir, err, repo)
+	}
+	if _, err := workingDir(newRepoFlagCmd("unknown-repo")); err == nil {
+		t.Error("expected an error for an unknown repository name")
+	}
+
+	cwd, _ := os.Getwd()
+	if dir, err := workingDir(newRepoFlagCmd("")); err != nil || dir != cwd {
+		t.Errorf("workingDir() without --repo = %q, %v, expected %q", dir, err, cwd)
+	}
+}
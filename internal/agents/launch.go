@@ -0,0 +1,131 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/proc"
+)
+
+// TaskEnv is the environment variable holding the task prompt
+const TaskEnv = "CCSWITCH_TASK"
+
+// DefaultCommand returns the agent command used when none is configured.
+// Agents in tmux run interactively; in the background they print and exit.
+func DefaultCommand(tmux bool) string {
+	task := "$" + TaskEnv
+	if runtime.GOOS == "windows" {
+		task = "%" + TaskEnv + "%"
+	}
+	if tmux {
+		return `claude "` + task + `"`
+	}
+	return `claude -p "` + task + `"`
+}
+
+// shellArgs returns the arguments that run command with the system shell
+func shellArgs(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"/bin/sh", "-c", command}
+}
+
+// StartBackground starts command in dir detached from ccswitch, with output
+// appended to logPath, and returns its pid
+func StartBackground(dir, command string, env []string, logPath string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return 0, err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+
+	args := shellArgs(command)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	proc.Detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start agent: %w", err)
+	}
+	pid := cmd.Process.Pid
+	// The agent outlives ccswitch; don't wait for it
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// Tmux places agents in panes of one tmux window, tiled
+type Tmux struct {
+	// Session is the tmux session to open the window in when ccswitch is
+	// not itself running inside tmux
+	Session string
+	window  string
+}
+
+// Start runs command in dir in a new pane with the variables in vars set,
+// and returns the pane id and the pid of the pane's process
+func (t *Tmux) Start(dir, command string, vars []string) (string, int, error) {
+	args := []string{"-P", "-F", "#{window_id} #{pane_id} #{pane_pid}", "-c", dir}
+	for _, kv := range vars {
+		args = append(args, "-e", kv)
+	}
+
+	var tmuxArgs []string
+	switch {
+	case t.window != "":
+		tmuxArgs = append([]string{"split-window", "-t", t.window}, args...)
+	case os.Getenv("TMUX") != "":
+		tmuxArgs = append([]string{"new-window", "-n", "agents"}, args...)
+	case exec.Command("tmux", "has-session", "-t", "="+t.Session).Run() == nil:
+		tmuxArgs = append([]string{"new-window", "-t", "=" + t.Session + ":", "-n", "agents"}, args...)
+	default:
+		tmuxArgs = append([]string{"new-session", "-d", "-s", t.Session, "-n", "agents"}, args...)
+	}
+	tmuxArgs = append(tmuxArgs, shellArgs(command)...)
+
+	output, err := exec.Command("tmux", tmuxArgs...).CombinedOutput()
+	if err != nil {
+		return "", 0, fmt.Errorf("tmux failed: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 3 {
+		return "", 0, fmt.Errorf("unexpected tmux output: %s", output)
+	}
+	pid, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", 0, fmt.Errorf("unexpected tmux output: %s", output)
+	}
+
+	if t.window == "" {
+		t.window = fields[0]
+	} else {
+		_ = exec.Command("tmux", "select-layout", "-t", t.window, "tiled").Run()
+	}
+	return fields[1], pid, nil
+}
+
+// Stop ends an agent: its tmux pane is closed, or its background process
+// group is terminated, killing it after grace
+func Stop(pid int, tmuxPane string, grace time.Duration) error {
+	if tmuxPane != "" {
+		output, err := exec.Command("tmux", "kill-pane", "-t", tmuxPane).CombinedOutput()
+		if err == nil || !proc.Alive(pid) {
+			return nil
+		}
+		return fmt.Errorf("failed to close tmux pane %s: %w, output: %s", tmuxPane, err, strings.TrimSpace(string(output)))
+	}
+	return proc.Terminate(pid, grace)
+}
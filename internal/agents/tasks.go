@@ -0,0 +1,83 @@
+// Package agents launches coding agents in ccswitch sessions, one per task,
+// either in the background or in tmux panes.
+package agents
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Task is one unit of work handed to an agent in its own session
+type Task struct {
+	// Name describes the task and becomes the session name
+	Name string `yaml:"name"`
+	// Prompt is the instruction given to the agent
+	Prompt string `yaml:"prompt"`
+}
+
+// UnmarshalYAML accepts a task as a plain string, used as both name and
+// prompt, or as a mapping
+func (t *Task) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		t.Prompt = node.Value
+		return nil
+	}
+	type plain Task
+	return node.Decode((*plain)(t))
+}
+
+// TaskFile is a list of tasks, e.g.
+//
+//	command: claude -p "$CCSWITCH_TASK"
+//	tasks:
+//	  - name: fix login redirect
+//	    prompt: The login page redirects to / instead of the page ...
+//	  - Add a dark mode toggle to the settings page
+type TaskFile struct {
+	// Command overrides the configured agent command for these tasks
+	Command string `yaml:"command"`
+	Tasks   []Task `yaml:"tasks"`
+}
+
+// LoadTasks reads and validates a task file
+func LoadTasks(path string) (*TaskFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTasks(data)
+}
+
+// nameWords is how many words of a prompt name a task without a name
+const nameWords = 6
+
+// ParseTasks parses and validates task file contents. Tasks without a name
+// are named after the first words of their prompt.
+func ParseTasks(data []byte) (*TaskFile, error) {
+	var file TaskFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid task file: %w", err)
+	}
+	if len(file.Tasks) == 0 {
+		return nil, fmt.Errorf("task file has no tasks")
+	}
+
+	for i := range file.Tasks {
+		task := &file.Tasks[i]
+		task.Prompt = strings.TrimSpace(task.Prompt)
+		if task.Prompt == "" {
+			return nil, fmt.Errorf("task %d has no prompt", i+1)
+		}
+		if task.Name == "" {
+			words := strings.Fields(task.Prompt)
+			if len(words) > nameWords {
+				words = words[:nameWords]
+			}
+			task.Name = strings.Join(words, " ")
+		}
+	}
+	return &file, nil
+}
@@ -0,0 +1,60 @@
+package agents
+
+import (
+	"testing"
+)
+
+func TestParseTasks(t *testing.T) {
+	data := []byte(`command: my-agent "$CCSWITCH_TASK"
+tasks:
+  - name: fix login redirect
+    prompt: |
+      The login page redirects to / instead of the page you came from.
+  - Add a dark mode toggle to the settings page of the app
+`)
+
+	file, err := ParseTasks(data)
+	if err != nil {
+		t.Fatalf("ParseTasks failed: %v", err)
+	}
+	if file.Command != `my-agent "$CCSWITCH_TASK"` {
+		t.Errorf("Command = %q", file.Command)
+	}
+	if len(file.Tasks) != 2 {
+		t.Fatalf("got %d tasks, expected 2", len(file.Tasks))
+	}
+
+	if file.Tasks[0].Name != "fix login redirect" {
+		t.Errorf("Tasks[0].Name = %q", file.Tasks[0].Name)
+	}
+	if file.Tasks[0].Prompt != "The login page redirects to / instead of the page you came from." {
+		t.Errorf("Tasks[0].Prompt = %q, expected trimmed prompt", file.Tasks[0].Prompt)
+	}
+
+	if file.Tasks[1].Name != "Add a dark mode toggle to" {
+		t.Errorf("Tasks[1].Name = %q, expected the first words of the prompt", file.Tasks[1].Name)
+	}
+	if file.Tasks[1].Prompt != "Add a dark mode toggle to the settings page of the app" {
+		t.Errorf("Tasks[1].Prompt = %q", file.Tasks[1].Prompt)
+	}
+}
+
+func TestParseTasksInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no tasks", "command: agent\n"},
+		{"empty tasks", "tasks: []\n"},
+		{"missing prompt", "tasks:\n  - name: nothing to do\n"},
+		{"not yaml", "tasks: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTasks([]byte(tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
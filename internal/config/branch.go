@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Placeholders of branch.template
+const (
+	NamePlaceholder   = "{name}"
+	TypePlaceholder   = "{type}"
+	UserPlaceholder   = "{user}"
+	PrefixPlaceholder = "{prefix}"
+)
+
+// DefaultBranchType fills {type} when branch.types is not set
+const DefaultBranchType = "feature"
+
+// placeholderPattern finds the placeholders of a template
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// BranchVars fill the placeholders of branch.template
+type BranchVars struct {
+	// Name is the session name
+	Name string
+	// Type is the kind of work, one of branch.types; empty is the first
+	Type string
+	// User identifies who the session belongs to
+	User string
+}
+
+// UsesUser reports whether new branch names include {user}, so callers only
+// look the user up when it is needed
+func (c *Config) UsesUser() bool {
+	return strings.Contains(c.Branch.Template, UserPlaceholder)
+}
+
+// BranchTypes returns the values {type} may take, the default first
+func (c *Config) BranchTypes() []string {
+	if len(c.Branch.Types) == 0 {
+		return []string{DefaultBranchType}
+	}
+	return c.Branch.Types
+}
+
+// BranchName returns the branch of a new session, applying the naming
+// policy: the name, type and user are slugified and placed in
+// branch.template, and the name is shortened to keep the branch within
+// branch.max_length. Names the policy does not allow fail with
+// errors.ErrBranchPolicy, explaining the rule that was broken.
+func (c *Config) BranchName(vars BranchVars) (string, error) {
+	template := c.Branch.Template
+	if template == "" {
+		template = PrefixPlaceholder + NamePlaceholder
+	}
+	if !strings.Contains(template, NamePlaceholder) {
+		return "", fmt.Errorf("%w: branch.template %q must contain %s", errors.ErrBranchPolicy, template, NamePlaceholder)
+	}
+	for _, p := range placeholderPattern.FindAllString(template, -1) {
+		switch p {
+		case NamePlaceholder, TypePlaceholder, UserPlaceholder, PrefixPlaceholder:
+		default:
+			return "", fmt.Errorf("%w: branch.template %q has unknown placeholder %s (expected %s, %s, %s or %s)",
+				errors.ErrBranchPolicy, template, p, NamePlaceholder, TypePlaceholder, UserPlaceholder, PrefixPlaceholder)
+		}
+	}
+
+	name := utils.Slugify(vars.Name)
+	if name == "" {
+		return "", fmt.Errorf("%w: %q has no letters or digits to name the branch after", errors.ErrBranchPolicy, vars.Name)
+	}
+
+	types := c.BranchTypes()
+	branchType := vars.Type
+	if branchType == "" {
+		branchType = types[0]
+	}
+	if !containsString(types, branchType) {
+		return "", fmt.Errorf("%w: type %q is not one of branch.types (%s)", errors.ErrBranchPolicy, branchType, strings.Join(types, ", "))
+	}
+
+	user := utils.Slugify(vars.User)
+	if user == "" && strings.Contains(template, UserPlaceholder) {
+		return "", fmt.Errorf("%w: branch.template uses %s but no user is known; set branch.user or git's user.name", errors.ErrBranchPolicy, UserPlaceholder)
+	}
+
+	branch := strings.NewReplacer(
+		TypePlaceholder, utils.Slugify(branchType),
+		UserPlaceholder, user,
+		PrefixPlaceholder, c.Branch.Prefix,
+	).Replace(template)
+
+	if limit := c.Branch.MaxLength; limit > 0 {
+		// Whatever is left after the rest of the template goes to the name
+		room := limit - (len(branch) - strings.Count(branch, NamePlaceholder)*len(NamePlaceholder))
+		room /= strings.Count(branch, NamePlaceholder)
+		if len(name) > room {
+			name = strings.TrimRight(name[:max(room, 0)], "-")
+		}
+		if name == "" {
+			return "", fmt.Errorf("%w: branch.max_length %d leaves no room for the session name in %q", errors.ErrBranchPolicy, limit, branch)
+		}
+	}
+	return strings.ReplaceAll(branch, NamePlaceholder, name), nil
+}
+
+// containsString reports whether list includes s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
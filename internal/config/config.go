@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,18 +16,160 @@ import (
 type Config struct {
 	Branch struct {
 		Prefix string `yaml:"prefix"`
+		// Template builds the branch of a new session from placeholders,
+		// e.g. "{user}/{type}/{name}"; empty means the prefix followed by
+		// the name. See BranchName.
+		Template string `yaml:"template"`
+		// Types lists the values {type} may take; the first is the default
+		Types []string `yaml:"types"`
+		// User fills {user}, instead of git's user.name
+		User string `yaml:"user"`
+		// MaxLength caps the length of new branch names by shortening the
+		// session name; 0 means no limit
+		MaxLength int `yaml:"max_length"`
 	} `yaml:"branch"`
 	Worktree struct {
 		RelativePath string `yaml:"relative_path"`
+		// Root is where session worktrees are created; see WorktreePath
+		Root string `yaml:"root"`
+		// CreationStrategy is how session worktrees are checked out; see
+		// CreationStrategies
+		CreationStrategy string `yaml:"creation_strategy"`
+		// Links lists paths, relative to the repository, that new
+		// worktrees share with the main worktree through links, such as
+		// node_modules or .env. Where symlinks can't be created, as on
+		// Windows without Developer Mode, directories are linked with
+		// junctions and files are copied.
+		Links []string `yaml:"links"`
 	} `yaml:"worktree"`
 	UI struct {
 		ShowEmoji   bool   `yaml:"show_emoji"`
 		ColorScheme string `yaml:"color_scheme"`
+		// Language is what ccswitch's messages are shown in, e.g. "zh";
+		// empty or "auto" follows LC_ALL, LC_MESSAGES or LANG. See
+		// i18n.Languages.
+		Language string `yaml:"language"`
 	} `yaml:"ui"`
 	Git struct {
 		DefaultBranch string `yaml:"default_branch"`
 		AutoFetch     bool   `yaml:"auto_fetch"`
+		CommitStyle   string `yaml:"commit_style"`
+		AutoPush      bool   `yaml:"auto_push"`
+		// ProtectedBranches lists branches (or glob patterns such as
+		// "release/*") that commands refuse to rebase or force-modify
+		ProtectedBranches []string `yaml:"protected_branches"`
+		// SignCommits signs the commits ccswitch creates or rewrites with
+		// --gpg-sign, as set up in git's own config
+		SignCommits bool `yaml:"sign_commits"`
+		// CommitHooks decides whether commits ccswitch creates run git's
+		// commit hooks: empty runs them unless --no-verify is given, and
+		// CommitHooksSkip or CommitHooksEnforce always skips or runs them
+		CommitHooks string `yaml:"commit_hooks"`
+		// Timeout stops git commands that run longer than this duration,
+		// such as a fetch stuck on an unreachable remote; empty means no
+		// limit
+		Timeout string `yaml:"timeout"`
+		// StatusCache keeps whether sessions are dirty and how far they
+		// are ahead and behind on disk between commands, trusting the
+		// dirty state for this duration; empty keeps them in memory for
+		// one command only. See git.StatusCache.
+		StatusCache string `yaml:"status_cache"`
+		// VerifyCommand is a shell command, such as "make test", run in a
+		// worktree after fanout or rebase rewrote its branch. If it fails,
+		// the branch is reset to where it was before.
+		VerifyCommand string `yaml:"verify_command"`
+		// MessageCommand is a shell command, such as an LLM CLI, that
+		// reads a diff on stdin and prints a commit message for it; see
+		// rebase --ai-message
+		MessageCommand string `yaml:"message_command"`
+		// CoAuthors, as "Name <email>", are credited with a
+		// Co-authored-by trailer on the commits rebase creates
+		CoAuthors []string `yaml:"co_authors"`
+		// KeepAuthor makes rebase --squash commit as the author of the
+		// session's first commit, crediting the authors of the others as
+		// co-authors
+		KeepAuthor bool `yaml:"keep_author"`
 	} `yaml:"git"`
+	Prune struct {
+		ArtifactDirs []string `yaml:"artifact_dirs"`
+	} `yaml:"prune"`
+	Nag struct {
+		DirtyAfter    string `yaml:"dirty_after"`
+		CheckInterval string `yaml:"check_interval"`
+	} `yaml:"nag"`
+	Agents struct {
+		// Command is the shell command ccswitch agents spawn launches in
+		// each session. The task prompt is in $CCSWITCH_TASK.
+		Command string `yaml:"command"`
+	} `yaml:"agents"`
+	// Issues links sessions to an issue tracker
+	Issues struct {
+		// URL is the address of an issue, with {id} standing for the
+		// issue given to create --issue, e.g.
+		// "https://example.atlassian.net/browse/{id}"
+		URL string `yaml:"url"`
+	} `yaml:"issues"`
+	// Identities are the git identities sessions can commit as, by name;
+	// see SessionIdentity
+	Identities map[string]Identity `yaml:"identities"`
+	// Identity names the identity of new sessions that don't choose one
+	Identity string `yaml:"identity"`
+	// Notifications tell the user when long operations such as fanout
+	// finish; see notify.Notifier
+	Notifications struct {
+		// Command is a shell command run with the summary in its
+		// environment and the notification as JSON on stdin
+		Command string `yaml:"command"`
+		// Webhook is a URL the notification is POSTed to as JSON, e.g. a
+		// Slack incoming webhook
+		Webhook string `yaml:"webhook"`
+	} `yaml:"notifications"`
+	// Prompt configures 'ccswitch prompt'
+	Prompt struct {
+		// CacheTTL is how long the prompt of a worktree is reused before
+		// git is asked again whether it is dirty; "0" asks every time.
+		// Empty means DefaultPromptCacheTTL.
+		CacheTTL string `yaml:"cache_ttl"`
+	} `yaml:"prompt"`
+	// Notify shows a desktop notification when a command run with
+	// ccswitch work finishes
+	Notify bool `yaml:"notify"`
+	// Sparse maps sparse-checkout profile names to the paths a session
+	// created with that profile checks out
+	Sparse map[string][]string `yaml:"sparse"`
+	// Env keeps the dev servers of sessions from fighting over ports and
+	// other resources; see SessionEnv
+	Env struct {
+		// PortBase is the first port handed out to sessions; 0 turns port
+		// allocation off
+		PortBase int `yaml:"port_base"`
+		// PortBlock is how many ports each session gets; see PortBlockSize
+		PortBlock int `yaml:"port_block"`
+		// Vars are set for the commands of every session, with the
+		// placeholders of SessionEnv filled in
+		Vars map[string]string `yaml:"vars"`
+		// File writes the variables of each session to .env.ccswitch in
+		// its worktree, for tools that read dotenv files
+		File bool `yaml:"file"`
+	} `yaml:"env"`
+	// Container defines the dev container 'ccswitch container up' starts
+	// for a session, instead of its devcontainer.json
+	Container struct {
+		// Compose is a compose file, relative to the worktree
+		Compose string `yaml:"compose"`
+		// Service is the compose service commands run in; the first one
+		// by default
+		Service string `yaml:"service"`
+	} `yaml:"container"`
+	// Integrations names the builtin integrations, such as direnv and
+	// mise, set up in a session's worktree when it is switched to; see
+	// integrations.Builtin
+	Integrations []string `yaml:"integrations"`
+	// Alias defines commands of the user's own by name, e.g. "fo" for
+	// "fanout --stack --skip-unsafe". A definition starting with "!" is a
+	// shell command instead, run with the session's variables. See
+	// LoadAliases for the ones that apply.
+	Alias map[string]string `yaml:"alias"`
 }
 
 // DefaultConfig returns the default configuration
@@ -34,6 +181,9 @@ func DefaultConfig() *Config {
 	cfg.UI.ColorScheme = "default"
 	cfg.Git.DefaultBranch = "main"
 	cfg.Git.AutoFetch = false
+	cfg.Prune.ArtifactDirs = []string{"node_modules", "target", "dist"}
+	cfg.Nag.DirtyAfter = "24h"
+	cfg.Nag.CheckInterval = "5m"
 	return cfg
 }
 
@@ -77,10 +227,174 @@ func Load() (*Config, error) {
 	if cfg.Git.DefaultBranch == "" {
 		cfg.Git.DefaultBranch = "main"
 	}
+	if len(cfg.Prune.ArtifactDirs) == 0 {
+		cfg.Prune.ArtifactDirs = DefaultConfig().Prune.ArtifactDirs
+	}
+	if cfg.Nag.DirtyAfter == "" {
+		cfg.Nag.DirtyAfter = "24h"
+	}
+	if cfg.Nag.CheckInterval == "" {
+		cfg.Nag.CheckInterval = "5m"
+	}
 
 	return cfg, nil
 }
 
+// SiblingWorktrees is the worktree root that places each session next to its
+// repository
+const SiblingWorktrees = "sibling"
+
+// WorktreePath returns where the worktree of the named session of the
+// repository at repoRoot belongs, depending on worktree.root:
+//
+//   - empty: ~/.ccswitch/worktrees/<repo>/<session>
+//   - "sibling": <repo>-<session> next to the repository
+//   - a directory, e.g. /mnt/scratch/worktrees: <dir>/<repo>/<session>. A
+//     leading ~ is expanded and relative paths start at the repository.
+func (c *Config) WorktreePath(repoRoot, session string) string {
+	repoName := filepath.Base(repoRoot)
+	root := c.Worktree.Root
+
+	switch root {
+	case "":
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, ".ccswitch", "worktrees", repoName, session)
+	case SiblingWorktrees:
+		return filepath.Join(filepath.Dir(repoRoot), repoName+"-"+session)
+	}
+
+	if root == "~" || strings.HasPrefix(root, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			root = filepath.Join(homeDir, root[1:])
+		}
+	}
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(repoRoot, root)
+	}
+	return filepath.Join(root, repoName, session)
+}
+
+// Values of git.commit_hooks
+const (
+	CommitHooksSkip    = "skip"
+	CommitHooksEnforce = "enforce"
+)
+
+// SkipCommitHooks reports whether commits skip git's commit hooks, given
+// whether --no-verify was passed. It fails if hooks are enforced and
+// --no-verify was passed.
+func (c *Config) SkipCommitHooks(noVerify bool) (bool, error) {
+	switch c.Git.CommitHooks {
+	case CommitHooksSkip:
+		return true, nil
+	case CommitHooksEnforce:
+		if noVerify {
+			return false, fmt.Errorf("commit hooks are enforced by git.commit_hooks; --no-verify is not allowed")
+		}
+		return false, nil
+	default:
+		return noVerify, nil
+	}
+}
+
+// Worktree creation strategies
+const (
+	// StrategyFull checks out every file (the default)
+	StrategyFull = "full"
+	// StrategySparse checks out only the files at the top level of the
+	// repository, using sparse-checkout with a sparse index; directories
+	// are added with 'git sparse-checkout add'
+	StrategySparse = "sparse"
+	// StrategyPartial is accepted for configurations written when it was
+	// offered, and checks out like StrategyFull: a worktree shares the
+	// object store of its repository, so only a partial clone (git clone
+	// --filter=blob:none) fetches less, whatever the strategy.
+	//
+	// Deprecated: use StrategyFull.
+	StrategyPartial = "partial"
+)
+
+// DefaultPromptCacheTTL is how long 'ccswitch prompt' reuses what it
+// found when prompt.cache_ttl is not set
+const DefaultPromptCacheTTL = "5s"
+
+// CreationStrategies lists the valid worktree.creation_strategy values
+var CreationStrategies = []string{StrategyFull, StrategySparse}
+
+// CreationStrategy returns the worktree creation strategy, StrategyFull if
+// none is set or it is the deprecated StrategyPartial
+func (c *Config) CreationStrategy() (string, error) {
+	switch c.Worktree.CreationStrategy {
+	case "", StrategyPartial:
+		return StrategyFull, nil
+	case StrategyFull, StrategySparse:
+		return c.Worktree.CreationStrategy, nil
+	default:
+		return "", fmt.Errorf("unknown worktree.creation_strategy %q (expected %s)", c.Worktree.CreationStrategy, strings.Join(CreationStrategies, ", "))
+	}
+}
+
+// SparseProfile returns the paths of the sparse-checkout profile name
+func (c *Config) SparseProfile(name string) ([]string, error) {
+	paths, ok := c.Sparse[name]
+	if !ok {
+		if len(c.Sparse) == 0 {
+			return nil, fmt.Errorf("unknown sparse profile %q: no profiles are defined under sparse in the config", name)
+		}
+		return nil, fmt.Errorf("unknown sparse profile %q (available: %s)", name, strings.Join(c.SparseProfiles(), ", "))
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("sparse profile %q lists no paths", name)
+	}
+	return paths, nil
+}
+
+// SparseProfiles returns the names of the sparse-checkout profiles, sorted
+func (c *Config) SparseProfiles() []string {
+	names := make([]string, 0, len(c.Sparse))
+	for name := range c.Sparse {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AliasNames returns the names of the aliases, sorted
+func (c *Config) AliasNames() []string {
+	names := make([]string, 0, len(c.Alias))
+	for name := range c.Alias {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IssuePlaceholder stands for the issue ID in issues.url
+const IssuePlaceholder = "{id}"
+
+// IssueURL returns the address of issue id from issues.url, or an empty
+// string if no tracker is configured
+func (c *Config) IssueURL(id string) string {
+	if id == "" || c.Issues.URL == "" {
+		return ""
+	}
+	if !strings.Contains(c.Issues.URL, IssuePlaceholder) {
+		return strings.TrimRight(c.Issues.URL, "/") + "/" + url.PathEscape(id)
+	}
+	return strings.ReplaceAll(c.Issues.URL, IssuePlaceholder, url.PathEscape(id))
+}
+
+// IsProtectedBranch reports whether branch matches one of the protected
+// branch patterns
+func (c *Config) IsProtectedBranch(branch string) bool {
+	for _, pattern := range c.Git.ProtectedBranches {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 // Save saves the configuration to file
 func (c *Config) Save() error {
 	homeDir, err := os.UserHomeDir()
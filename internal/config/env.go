@@ -0,0 +1,52 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPortBlock is how many ports each session gets unless
+// env.port_block says otherwise
+const DefaultPortBlock = 10
+
+// PortBlockSize returns how many ports each session gets
+func (c *Config) PortBlockSize() int {
+	if c.Env.PortBlock > 0 {
+		return c.Env.PortBlock
+	}
+	return DefaultPortBlock
+}
+
+// portPlaceholder matches {port} and {port+N}
+var portPlaceholder = regexp.MustCompile(`\{port(?:\+(\d+))?\}`)
+
+// SessionEnv returns env.vars for a session as NAME=value entries sorted by
+// name, filling in {session}, {branch}, and {port} and {port+N} with the
+// first port of the session's block and those after it. Variables using
+// ports are left out when the session has none.
+func (c *Config) SessionEnv(session, branch string, port int) []string {
+	names := make([]string, 0, len(c.Env.Vars))
+	for name := range c.Env.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		value := c.Env.Vars[name]
+		if portPlaceholder.MatchString(value) {
+			if port == 0 {
+				continue
+			}
+			value = portPlaceholder.ReplaceAllStringFunc(value, func(match string) string {
+				offset, _ := strconv.Atoi(portPlaceholder.FindStringSubmatch(match)[1])
+				return strconv.Itoa(port + offset)
+			})
+		}
+		value = strings.NewReplacer("{session}", session, "{branch}", branch).Replace(value)
+		env = append(env, name+"="+value)
+	}
+	return env
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Identity is who the commits of a session are made as, applied to its
+// worktree's git config when the session is created
+type Identity struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+	// SigningKey is the user.signingkey commits are signed with
+	SigningKey string `yaml:"signing_key"`
+	// Types lists the branch types (see branch.types) whose sessions use
+	// this identity unless another is chosen
+	Types []string `yaml:"types"`
+}
+
+// String returns the identity as git shows authors, e.g. "Jo <jo@example.com>"
+func (i Identity) String() string {
+	switch {
+	case i.Name == "":
+		return "<" + i.Email + ">"
+	case i.Email == "":
+		return i.Name
+	default:
+		return i.Name + " <" + i.Email + ">"
+	}
+}
+
+// IdentityNames returns the names of the configured identities, sorted
+func (c *Config) IdentityNames() []string {
+	names := make([]string, 0, len(c.Identities))
+	for name := range c.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SessionIdentity returns the name and identity a new session commits as:
+// the identity called name if it is given, else the first identity listing
+// branchType (empty is the default type) in its types, else the identity
+// setting. An empty name means the session keeps the repository's identity.
+func (c *Config) SessionIdentity(name, branchType string) (string, *Identity, error) {
+	if branchType == "" {
+		branchType = c.BranchTypes()[0]
+	}
+	if name == "" {
+		for _, candidate := range c.IdentityNames() {
+			for _, t := range c.Identities[candidate].Types {
+				if t == branchType {
+					name = candidate
+					break
+				}
+			}
+			if name != "" {
+				break
+			}
+		}
+	}
+	if name == "" {
+		name = c.Identity
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+
+	identity, ok := c.Identities[name]
+	if !ok {
+		if len(c.Identities) == 0 {
+			return "", nil, fmt.Errorf("unknown identity %q: no identities are defined in the config", name)
+		}
+		return "", nil, fmt.Errorf("unknown identity %q (available: %s)", name, strings.Join(c.IdentityNames(), ", "))
+	}
+	if identity.Name == "" && identity.Email == "" && identity.SigningKey == "" {
+		return "", nil, fmt.Errorf("identity %q sets none of name, email and signing_key", name)
+	}
+	return name, &identity, nil
+}
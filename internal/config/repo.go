@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoDir returns the directory holding ccswitch files committed to a
+// repository
+func RepoDir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".ccswitch")
+}
+
+// RepoConfigPath returns the path of the configuration shared by everyone
+// working on a repository
+func RepoConfigPath(repoRoot string) string {
+	return filepath.Join(RepoDir(repoRoot), "config.yaml")
+}
+
+// LocalConfigPath returns the path of personal, uncommitted overrides of a
+// repository's configuration
+func LocalConfigPath(repoRoot string) string {
+	return filepath.Join(RepoDir(repoRoot), "config.local.yaml")
+}
+
+// LoadForRepo loads the global configuration and applies the repository's
+// config.yaml and config.local.yaml on top of it, in that order. Settings a
+// file does not mention keep their previous value.
+func LoadForRepo(repoRoot string) (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return cfg, err
+	}
+
+	for _, path := range []string{RepoConfigPath(repoRoot), LocalConfigPath(repoRoot)} {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return cfg, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadAliases returns the aliases of the global configuration and of the
+// repository at repoRoot, if any, as LoadForRepo merges them. Shell aliases
+// of the repository's config.yaml are refused rather than returned: it is
+// committed, so anyone who can push to the repository could run commands in
+// every clone. Like git, only the user's own files define them.
+func LoadAliases(repoRoot string) (aliases map[string]string, refused map[string]bool, err error) {
+	aliases = make(map[string]string)
+	refused = make(map[string]bool)
+
+	paths := []string{GetConfigPath()}
+	if repoRoot != "" {
+		paths = append(paths, RepoConfigPath(repoRoot), LocalConfigPath(repoRoot))
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var file struct {
+			Alias map[string]string `yaml:"alias"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, nil, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+		shared := repoRoot != "" && path == RepoConfigPath(repoRoot)
+		for name, definition := range file.Alias {
+			if shared && strings.HasPrefix(definition, "!") {
+				refused[name] = true
+				continue
+			}
+			aliases[name] = definition
+			delete(refused, name)
+		}
+	}
+	return aliases, refused, nil
+}
+
+// StarterRepoConfig returns the commented configuration ccswitch init writes
+// for a repository
+func StarterRepoConfig() string {
+	defaults := DefaultConfig()
+
+	dirs := ""
+	for _, dir := range defaults.Prune.ArtifactDirs {
+		dirs += "    - " + dir + "\n"
+	}
+
+	return fmt.Sprintf(`# ccswitch settings shared by everyone working on this repository.
+# They override ~/.ccswitch/config.yaml. Personal overrides go in
+# .ccswitch/config.local.yaml, which is not committed.
+
+branch:
+  # Prefix for branches of new sessions
+  prefix: %s
+  # Or name them from a template of {name}, {type}, {user} and {prefix},
+  # with the kinds of work --type accepts and a length limit
+  # template: "{user}/{type}/{name}"
+  # types: [feat, fix, chore]
+  # max_length: 40
+
+# Paths new worktrees share with the main worktree through links; on
+# Windows without Developer Mode, directories use junctions and files are
+# copied
+# worktree:
+#   links:
+#     - node_modules
+#     - .env
+
+git:
+  # Branches ccswitch refuses to rebase or force-push without --force
+  # protected_branches:
+  #   - release/*
+  # Sign commits ccswitch creates or rebases with --gpg-sign
+  # sign_commits: true
+  # Always run commit hooks, refusing --no-verify (or "skip" to never run them)
+  # commit_hooks: enforce
+  # Check each branch fanout or rebase rewrote; a failure rolls it back
+  # verify_command: make test
+  # Write commit messages for rebase --ai-message from the diff on stdin
+  # message_command: claude -p "Write a commit message for this diff"
+  # Credit co-authors on the commits rebase creates
+  # co_authors:
+  #   - Jo Doe <jo@example.com>
+  # Keep the authors of squashed commits, the first as the author
+  # keep_author: true
+
+# Address of the issue given to 'ccswitch create --issue'
+# issues:
+#   url: https://example.atlassian.net/browse/{id}
+
+# Git identities sessions commit as, set in each new worktree only. Choose
+# one with 'ccswitch create --identity', by branch type, or as the default.
+# Personal identities belong in config.local.yaml.
+# identities:
+#   work:
+#     name: Jo Doe
+#     email: jo@example.com
+#     signing_key: ~/.ssh/id_ed25519.pub
+#   oss:
+#     email: jo@users.noreply.github.com
+#     types: [oss]
+# identity: work
+
+# Notify when fanout finishes. Webhook URLs hold a secret, so set them in
+# config.local.yaml rather than here.
+# notifications:
+#   command: notify-send ccswitch "$CCSWITCH_SUMMARY"
+#   webhook: https://hooks.slack.com/services/...
+
+# Commands of your own: 'ccswitch fo' runs 'ccswitch fanout --stack
+# --skip-unsafe'. Aliases starting with ! run in the shell, with the
+# variables of the session they are run in; they are only taken from
+# config.local.yaml and ~/.ccswitch/config.yaml, never from this file.
+# alias:
+#   fo: fanout --stack --skip-unsafe
+
+prune:
+  # Directories 'ccswitch prune-artifacts' deletes in inactive sessions
+  artifact_dirs:
+%s`, defaults.Branch.Prefix, dirs)
+}
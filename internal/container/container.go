@@ -0,0 +1,192 @@
+// Package container runs a dev container for each session, from the
+// worktree's devcontainer.json with the devcontainer CLI or from a compose
+// file with docker compose, so sessions get isolated runtimes.
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of containers
+const (
+	KindDevcontainer = "devcontainer"
+	KindCompose      = "compose"
+)
+
+// idLabel is the label the devcontainer CLI finds a session's container by
+const idLabel = "ccswitch.session"
+
+// Container is a session's dev container
+type Container struct {
+	Kind string `json:"kind"`
+	// ID is the docker ID of the container commands run in
+	ID string `json:"id"`
+	// Project names the container: the compose project, or the value of
+	// the label a devcontainer is found by
+	Project string `json:"project"`
+	// File and Service are the compose file, relative to the worktree,
+	// and the service commands run in
+	File      string    `json:"file,omitempty"`
+	Service   string    `json:"service,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ProjectName returns the name of the container of a session of repo,
+// made of the characters compose allows in project names
+func ProjectName(repo, session string) string {
+	name := strings.ToLower("ccswitch-" + repo + "-" + session)
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// Detect returns how the container of the worktree at dir is defined: by
+// composeFile, relative to dir, if it is set, or else by a devcontainer.json
+func Detect(dir, composeFile string) (string, error) {
+	if composeFile != "" {
+		if _, err := os.Stat(filepath.Join(dir, composeFile)); err != nil {
+			return "", fmt.Errorf("compose file %s not found in %s", composeFile, dir)
+		}
+		return KindCompose, nil
+	}
+	for _, path := range []string{filepath.Join(".devcontainer", "devcontainer.json"), ".devcontainer.json"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err == nil {
+			return KindDevcontainer, nil
+		}
+	}
+	return "", fmt.Errorf("no .devcontainer/devcontainer.json in %s and no container.compose in the config", dir)
+}
+
+// Up builds and starts the container of the worktree at dir, named project,
+// with env as the environment of the tools, which compose files can use.
+// Their progress is written to progress.
+func Up(dir, kind, project, composeFile, service string, env []string, progress io.Writer) (*Container, error) {
+	c := &Container{Kind: kind, Project: project, StartedAt: time.Now()}
+
+	switch kind {
+	case KindDevcontainer:
+		if _, err := exec.LookPath("devcontainer"); err != nil {
+			return nil, fmt.Errorf("the devcontainer CLI is not installed (npm install -g @devcontainers/cli)")
+		}
+		output, err := run(dir, env, progress, "devcontainer", "up", "--workspace-folder", dir, "--id-label", idLabel+"="+project)
+		if err != nil {
+			return nil, err
+		}
+		id, err := parseUpResult(output)
+		if err != nil {
+			return nil, err
+		}
+		c.ID = id
+	case KindCompose:
+		c.File = composeFile
+		if _, err := run(dir, env, progress, "docker", c.composeArgs("up", "--detach", "--build")...); err != nil {
+			return nil, err
+		}
+		if service == "" {
+			output, err := run(dir, env, progress, "docker", c.composeArgs("config", "--services")...)
+			if err != nil {
+				return nil, err
+			}
+			service, _, _ = strings.Cut(strings.TrimSpace(string(output)), "\n")
+		}
+		c.Service = service
+		output, err := run(dir, env, progress, "docker", c.composeArgs("ps", "--quiet", service)...)
+		if err != nil {
+			return nil, err
+		}
+		c.ID = strings.TrimSpace(string(output))
+		if c.ID == "" {
+			return nil, fmt.Errorf("service %s of %s is not running", service, composeFile)
+		}
+	default:
+		return nil, fmt.Errorf("unknown container kind %q", kind)
+	}
+	return c, nil
+}
+
+// parseUpResult returns the container ID in the result devcontainer up
+// prints as JSON on its last line
+func parseUpResult(output []byte) (string, error) {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var result struct {
+		Outcome     string `json:"outcome"`
+		ContainerID string `json:"containerId"`
+		Message     string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &result); err != nil {
+		return "", fmt.Errorf("unexpected devcontainer output: %s", output)
+	}
+	if result.Outcome != "success" || result.ContainerID == "" {
+		return "", fmt.Errorf("devcontainer up failed: %s", result.Message)
+	}
+	return result.ContainerID, nil
+}
+
+// composeArgs returns the docker arguments running a compose command on
+// the container's project
+func (c *Container) composeArgs(args ...string) []string {
+	return append([]string{"compose", "--file", c.File, "--project-name", c.Project}, args...)
+}
+
+// ExecArgs returns the command line running command in the container of
+// the worktree at dir, with a terminal allocated if tty is set
+func (c *Container) ExecArgs(dir string, command []string, tty bool) []string {
+	switch c.Kind {
+	case KindDevcontainer:
+		args := []string{"devcontainer", "exec", "--workspace-folder", dir, "--id-label", idLabel + "=" + c.Project}
+		return append(args, command...)
+	default:
+		args := append([]string{"docker"}, c.composeArgs("exec")...)
+		if !tty {
+			args = append(args, "--no-TTY")
+		}
+		return append(append(args, c.Service), command...)
+	}
+}
+
+// Down stops and removes the container of the worktree at dir
+func (c *Container) Down(dir string, env []string, progress io.Writer) error {
+	var err error
+	switch c.Kind {
+	case KindDevcontainer:
+		_, err = run(dir, env, progress, "docker", "rm", "--force", c.ID)
+	default:
+		_, err = run(dir, env, progress, "docker", c.composeArgs("down")...)
+	}
+	return err
+}
+
+// Running reports whether the container is running
+func (c *Container) Running() bool {
+	output, err := exec.Command("docker", "inspect", "--format", "{{.State.Running}}", c.ID).Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// run runs a tool in dir and returns its stdout, passing its stderr on to
+// progress and including it in the error if it fails
+func run(dir string, env []string, progress io.Writer, name string, args ...string) ([]byte, error) {
+	if progress == nil {
+		progress = io.Discard
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(&stderr, progress)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w, output: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjectName(t *testing.T) {
+	if got := ProjectName("My.Repo", "fix login"); got != "ccswitch-my-repo-fix-login" {
+		t.Errorf("ProjectName() = %q", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Detect(dir, ""); err == nil {
+		t.Error("Detect() found a container in an empty worktree")
+	}
+	if _, err := Detect(dir, "compose.yml"); err == nil {
+		t.Error("Detect() accepted a missing compose file")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".devcontainer", "devcontainer.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if kind, err := Detect(dir, ""); err != nil || kind != KindDevcontainer {
+		t.Errorf("Detect() = %q, %v; expected %s", kind, err, KindDevcontainer)
+	}
+
+	// A configured compose file wins over devcontainer.json
+	if err := os.WriteFile(filepath.Join(dir, "compose.yml"), []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if kind, err := Detect(dir, "compose.yml"); err != nil || kind != KindCompose {
+		t.Errorf("Detect() = %q, %v; expected %s", kind, err, KindCompose)
+	}
+}
+
+func TestParseUpResult(t *testing.T) {
+	output := []byte("[1 ms] Start\n{\"outcome\":\"success\",\"containerId\":\"abc123\",\"remoteUser\":\"node\"}\n")
+	if id, err := parseUpResult(output); err != nil || id != "abc123" {
+		t.Errorf("parseUpResult() = %q, %v", id, err)
+	}
+	if _, err := parseUpResult([]byte(`{"outcome":"error","message":"build failed"}`)); err == nil {
+		t.Error("parseUpResult() accepted a failed outcome")
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	compose := &Container{Kind: KindCompose, Project: "ccswitch-app-auth", File: "compose.yml", Service: "app"}
+	expected := []string{"docker", "compose", "--file", "compose.yml", "--project-name", "ccswitch-app-auth", "exec", "--no-TTY", "app", "npm", "test"}
+	if got := compose.ExecArgs("/w/auth", []string{"npm", "test"}, false); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExecArgs(compose) = %v, expected %v", got, expected)
+	}
+
+	dev := &Container{Kind: KindDevcontainer, Project: "ccswitch-app-auth"}
+	expected = []string{"devcontainer", "exec", "--workspace-folder", "/w/auth", "--id-label", "ccswitch.session=ccswitch-app-auth", "sh"}
+	if got := dev.ExecArgs("/w/auth", []string{"sh"}, true); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExecArgs(devcontainer) = %v, expected %v", got, expected)
+	}
+}
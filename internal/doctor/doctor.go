@@ -0,0 +1,310 @@
+// Package doctor checks that the environment ccswitch runs in is usable:
+// the git installation, the worktree root, configuration files and hooks
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// MinGitVersion is the oldest git ccswitch works with; older versions
+// lack rev-parse --path-format and worktree prune reporting
+const MinGitVersion = "2.31.0"
+
+// SparseGitVersion is the oldest git that supports sparse-checkout
+// profiles, which use a sparse index
+const SparseGitVersion = "2.32.0"
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	OK Status = iota
+	Warning
+	Failed
+)
+
+// Check is the result of one environment check
+type Check struct {
+	Name    string
+	Status  Status
+	Message string
+	// Suggestion tells the user how to fix a problem
+	Suggestion string
+	// Fix repairs the problem, if it can be fixed automatically
+	Fix func() error
+}
+
+// Run checks the environment for the repository at repoRoot with its
+// configuration cfg
+func Run(repoRoot string, cfg *config.Config) []Check {
+	checks := []Check{checkGitVersion()}
+	checks = append(checks, checkWorktrees(repoRoot))
+	checks = append(checks, checkPath(runtime.GOOS, os.Getenv("PATH"))...)
+	if runtime.GOOS == "windows" {
+		checks = append(checks, checkLongPaths(repoRoot))
+		checks = append(checks, checkSymlinks(repoRoot, cfg, utils.CanSymlink())...)
+	}
+	checks = append(checks, checkSessionRoot(cfg, repoRoot))
+	for _, path := range []string{config.GetConfigPath(), config.RepoConfigPath(repoRoot), config.LocalConfigPath(repoRoot)} {
+		if _, err := os.Stat(path); err == nil {
+			checks = append(checks, checkConfigFile(path))
+		}
+	}
+	if runtime.GOOS != "windows" {
+		checks = append(checks, checkHooks(repoRoot)...)
+	}
+	return checks
+}
+
+func checkGitVersion() Check {
+	check := Check{Name: "git version"}
+	version, err := git.Version()
+	if err != nil {
+		check.Status = Failed
+		check.Message = err.Error()
+		check.Suggestion = "Install git " + MinGitVersion + " or newer"
+		return check
+	}
+
+	switch {
+	case git.CompareVersions(version, MinGitVersion) < 0:
+		check.Status = Failed
+		check.Message = fmt.Sprintf("git %s is older than %s, the oldest version ccswitch supports", version, MinGitVersion)
+		check.Suggestion = "Upgrade git to " + MinGitVersion + " or newer"
+	case git.CompareVersions(version, SparseGitVersion) < 0:
+		check.Status = Warning
+		check.Message = fmt.Sprintf("git %s does not support sparse-checkout profiles, which need %s", version, SparseGitVersion)
+		check.Suggestion = "Upgrade git to use create --sparse"
+	default:
+		check.Message = "git " + version
+	}
+	return check
+}
+
+func checkWorktrees(repoRoot string) Check {
+	check := Check{Name: "worktree support"}
+	if _, err := git.NewWorktreeManager(repoRoot).List(); err != nil {
+		check.Status = Failed
+		check.Message = err.Error()
+		check.Suggestion = "Check that " + repoRoot + " is a git repository and that git supports worktrees"
+		return check
+	}
+	check.Message = "git worktree is available"
+	return check
+}
+
+// checkPath checks that git can be found, and on Windows that ccswitch can
+// be run by name, as the shell integration does
+func checkPath(goos, path string) []Check {
+	gitCheck := Check{Name: "git on PATH", Message: "git is on PATH"}
+	if _, err := exec.LookPath("git"); err != nil {
+		gitCheck.Status = Failed
+		gitCheck.Message = "git was not found on PATH"
+		gitCheck.Suggestion = "Install git or add its directory to PATH"
+	}
+	if goos != "windows" {
+		return []Check{gitCheck}
+	}
+
+	selfCheck := Check{Name: "ccswitch on PATH", Message: "ccswitch is on PATH"}
+	exe, err := os.Executable()
+	if err != nil {
+		return []Check{gitCheck}
+	}
+	if !pathContains(path, filepath.Dir(exe), goos) {
+		selfCheck.Status = Warning
+		selfCheck.Message = fmt.Sprintf("%s is not on PATH, so the shell integration cannot run ccswitch", filepath.Dir(exe))
+		selfCheck.Suggestion = fmt.Sprintf("Add it to PATH in System Properties > Environment Variables, or run: setx PATH \"%%PATH%%;%s\"", filepath.Dir(exe))
+	}
+	return []Check{gitCheck, selfCheck}
+}
+
+// pathContains reports whether dir is one of the entries of the PATH list,
+// ignoring case and trailing separators on Windows
+func pathContains(path, dir, goos string) bool {
+	clean := func(p string) string {
+		p = strings.TrimRight(p, `/\`)
+		if goos == "windows" {
+			p = strings.ToLower(strings.ReplaceAll(p, "/", `\`))
+		}
+		return p
+	}
+	separator := ":"
+	if goos == "windows" {
+		separator = ";"
+	}
+	for _, entry := range strings.Split(path, separator) {
+		if entry != "" && clean(entry) == clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLongPaths checks that git on Windows can handle paths longer than
+// 260 characters, which nested worktree roots easily reach
+func checkLongPaths(repoRoot string) Check {
+	check := Check{Name: "long paths", Message: "core.longpaths is enabled"}
+	if value, _ := git.GetConfig(repoRoot, "core.longpaths"); value != "true" {
+		check.Status = Warning
+		check.Message = "core.longpaths is not enabled, so files in deep worktrees may fail to check out"
+		check.Suggestion = "Run: git config --global core.longpaths true"
+		check.Fix = func() error {
+			return exec.Command("git", "config", "--global", "core.longpaths", "true").Run()
+		}
+	}
+	return check
+}
+
+// checkSymlinks checks what Windows without Developer Mode, where
+// canSymlink is false, does to links: worktree.links falls back to
+// junctions and copies, and git checks out committed symlinks as plain
+// files unless core.symlinks is on
+func checkSymlinks(repoRoot string, cfg *config.Config, canSymlink bool) []Check {
+	check := Check{Name: "symlinks", Message: "symlinks can be created"}
+	if !canSymlink {
+		check.Status = Warning
+		check.Message = "symlinks need Developer Mode or administrator rights"
+		if len(cfg.Worktree.Links) > 0 {
+			check.Message += "; worktree.links uses junctions for directories and copies files, which don't follow changes"
+		}
+		check.Suggestion = "Turn on Developer Mode in Settings > System > For developers"
+	}
+	checks := []Check{check}
+
+	links, err := git.TrackedSymlinks(repoRoot)
+	if err != nil || len(links) == 0 {
+		return checks
+	}
+	tracked := Check{Name: "committed symlinks", Message: fmt.Sprintf("%d committed symlink(s) are checked out as links", len(links))}
+	if value, _ := git.GetConfig(repoRoot, "core.symlinks"); value != "true" {
+		tracked.Status = Warning
+		tracked.Message = fmt.Sprintf("core.symlinks is off, so %d committed symlink(s), such as %s, are checked out as plain files", len(links), links[0])
+		tracked.Suggestion = "Once symlinks can be created, run: git config core.symlinks true, then recreate the affected worktrees"
+	}
+	return append(checks, tracked)
+}
+
+// checkSessionRoot checks that worktrees can be created in the worktree root
+func checkSessionRoot(cfg *config.Config, repoRoot string) Check {
+	root := filepath.Dir(cfg.WorktreePath(repoRoot, "session"))
+	check := Check{Name: "worktree root", Message: root + " is writable"}
+
+	// The root is created with the first worktree, so check the closest
+	// directory that exists
+	dir := root
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".ccswitch-doctor-*")
+	if err != nil {
+		check.Status = Failed
+		check.Message = fmt.Sprintf("cannot create worktrees in %s: %v", root, err)
+		check.Suggestion = fmt.Sprintf("Make %s writable, or set worktree.root to another directory", dir)
+		return check
+	}
+	f.Close()
+	_ = os.Remove(f.Name())
+	return check
+}
+
+// checkConfigFile checks that a configuration file parses and only uses
+// known settings
+func checkConfigFile(path string) Check {
+	check := Check{Name: "config file", Message: path + " is valid"}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		check.Status = Failed
+		check.Message = err.Error()
+		check.Suggestion = "Check the file's permissions"
+		return check
+	}
+	if err := yaml.Unmarshal(data, config.DefaultConfig()); err != nil {
+		check.Status = Failed
+		check.Message = fmt.Sprintf("%s: %v", path, err)
+		check.Suggestion = "Fix the YAML syntax; until then ccswitch uses the default settings"
+		return check
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config.DefaultConfig()); err != nil && err != io.EOF {
+		check.Status = Warning
+		check.Message = fmt.Sprintf("%s: %v", path, err)
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			check.Message = fmt.Sprintf("%s: %s", path, unknownSettings(typeErr))
+		}
+		check.Suggestion = "Remove or correct the settings ccswitch does not know; 'ccswitch config' shows the settings in effect"
+	}
+	return check
+}
+
+// unknownFieldRegex matches yaml's error for a setting with no config field
+var unknownFieldRegex = regexp.MustCompile(`^(line \d+): field (\S+) not found in type`)
+
+// unknownSettings rewrites yaml's errors about unknown fields, which name
+// Go types, as "line 3: unknown setting prefx"
+func unknownSettings(err *yaml.TypeError) string {
+	messages := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		if m := unknownFieldRegex.FindStringSubmatch(e); m != nil {
+			e = m[1] + ": unknown setting " + m[2]
+		}
+		messages[i] = e
+	}
+	return strings.Join(messages, "; ")
+}
+
+// clientHooks are the git hooks that run for commands ccswitch uses
+var clientHooks = []string{
+	"pre-commit", "prepare-commit-msg", "commit-msg", "post-commit",
+	"pre-rebase", "post-checkout", "post-merge", "post-rewrite", "pre-push",
+}
+
+// checkHooks finds hook scripts git skips because they are not executable
+func checkHooks(repoRoot string) []Check {
+	var checks []Check
+	for _, hook := range clientHooks {
+		path, err := git.GitPath(repoRoot, "hooks/"+hook)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode()&0111 != 0 {
+			continue
+		}
+		mode := info.Mode()
+		checks = append(checks, Check{
+			Name:       "hook " + hook,
+			Status:     Warning,
+			Message:    path + " is not executable, so git ignores it",
+			Suggestion: "Run: chmod +x " + path,
+			Fix: func() error {
+				return os.Chmod(path, mode|0111)
+			},
+		})
+	}
+	return checks
+}
@@ -0,0 +1,154 @@
+package doctor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/config"
+)
+
+func TestPathContains(t *testing.T) {
+	tests := []struct {
+		path     string
+		dir      string
+		goos     string
+		expected bool
+	}{
+		{"/usr/bin:/usr/local/bin", "/usr/local/bin", "linux", true},
+		{"/usr/bin:/usr/local/bin/", "/usr/local/bin", "linux", true},
+		{"/usr/bin", "/usr/local/bin", "linux", false},
+		{"/usr/bin", "/USR/BIN", "linux", false},
+		{`C:\Windows;C:\Tools\`, `c:\tools`, "windows", true},
+		{`C:\Windows;C:/Tools`, `C:\Tools`, "windows", true},
+		{`C:\Windows;;`, `C:\Tools`, "windows", false},
+	}
+
+	for _, tt := range tests {
+		if got := pathContains(tt.path, tt.dir, tt.goos); got != tt.expected {
+			t.Errorf("pathContains(%q, %q, %s) = %v, expected %v", tt.path, tt.dir, tt.goos, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Status
+	}{
+		{"valid", "branch:\n  prefix: task/\n", OK},
+		{"empty", "", OK},
+		{"syntax error", "branch:\n  prefix: [task/\n", Failed},
+		{"wrong type", "git:\n  sign_commits: sometimes\n", Failed},
+		{"unknown setting", "branch:\n  prefx: task/\n", Warning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			check := checkConfigFile(path)
+			if check.Status != tt.expected {
+				t.Errorf("checkConfigFile() = %+v, expected status %d", check, tt.expected)
+			}
+			if tt.expected == Warning && !strings.Contains(check.Message, "line 2: unknown setting prefx") {
+				t.Errorf("checkConfigFile() message = %q, expected it to name the unknown setting", check.Message)
+			}
+			if check.Status != OK && check.Suggestion == "" {
+				t.Error("a problem should come with a suggestion")
+			}
+		})
+	}
+}
+
+func TestCheckSessionRoot(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Worktree.Root = filepath.Join(root, "worktrees")
+
+	if check := checkSessionRoot(cfg, filepath.Join(root, "project")); check.Status != OK {
+		t.Errorf("checkSessionRoot() = %+v, expected OK for a root that can be created", check)
+	}
+
+	if err := os.Chmod(root, 0555); err != nil {
+		t.Fatalf("Failed to make root read-only: %v", err)
+	}
+	defer os.Chmod(root, 0755)
+	if check := checkSessionRoot(cfg, filepath.Join(root, "project")); check.Status != Failed {
+		t.Errorf("checkSessionRoot() = %+v, expected a failure for a read-only root", check)
+	}
+}
+
+func TestCheckHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks do not need to be executable on Windows")
+	}
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v, output: %s", err, output)
+	}
+	hooks := filepath.Join(repo, ".git", "hooks")
+	if err := os.WriteFile(filepath.Join(hooks, "pre-commit"), []byte("#!/bin/sh\nexit 0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hooks, "commit-msg"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+
+	checks := checkHooks(repo)
+	if len(checks) != 1 || !strings.HasSuffix(checks[0].Name, "pre-commit") || checks[0].Fix == nil {
+		t.Fatalf("checkHooks() = %+v, expected one fixable problem with pre-commit", checks)
+	}
+	if err := checks[0].Fix(); err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+	if checks := checkHooks(repo); len(checks) != 0 {
+		t.Errorf("checkHooks() after fixing = %+v, expected no problems", checks)
+	}
+}
+
+func TestCheckSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("committing a symlink needs symlinks")
+	}
+
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v, output: %s", err, output)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Worktree.Links = []string{"node_modules"}
+
+	checks := checkSymlinks(repo, cfg, false)
+	if len(checks) != 1 || checks[0].Status != Warning || !strings.Contains(checks[0].Message, "junctions") {
+		t.Fatalf("checkSymlinks() without symlinks = %+v, expected a warning about junctions", checks)
+	}
+
+	if err := os.Symlink("README.md", filepath.Join(repo, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	for _, args := range [][]string{{"add", "link"}, {"config", "core.symlinks", "false"}} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v, output: %s", args, err, output)
+		}
+	}
+
+	checks = checkSymlinks(repo, cfg, true)
+	if len(checks) != 2 || checks[0].Status != OK {
+		t.Fatalf("checkSymlinks() = %+v, expected symlinks to work and a check of the committed one", checks)
+	}
+	if checks[1].Status != Warning || !strings.Contains(checks[1].Message, "link") {
+		t.Errorf("committed symlinks check = %+v, expected a warning naming link", checks[1])
+	}
+}
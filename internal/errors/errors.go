@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+
+	"github.com/ksred/ccswitch/internal/i18n"
 )
 
 // Common errors
@@ -15,6 +17,22 @@ var (
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrAlreadyOnBranch    = errors.New("already on branch")
 	ErrNoSessions         = errors.New("no active sessions")
+	ErrRebaseConflict     = errors.New("rebase conflict detected")
+	ErrCherryPickConflict = errors.New("cherry-pick conflict detected")
+	ErrRebaseStopped      = errors.New("rebase stopped before finishing")
+	ErrNoUpstream         = errors.New("no upstream branch configured")
+	ErrLocked             = errors.New("another ccswitch command is running")
+	ErrSigning            = errors.New("commit signing failed")
+	ErrHookFailed         = errors.New("commit hook failed")
+	ErrNotInteractive     = errors.New("an interactive selection needs a terminal")
+	ErrInterrupted        = errors.New("interrupted")
+	ErrGitTimeout         = errors.New("git command timed out")
+	ErrVerifyFailed       = errors.New("verify command failed")
+	ErrBranchPolicy       = errors.New("branch name not allowed by the naming policy")
+	ErrNoMessageCommand   = errors.New("no commit message command configured")
+	ErrPinned             = errors.New("session is pinned")
+	ErrProtectedBranch    = errors.New("branch is protected")
+	ErrMainRepository     = errors.New("not possible for the main repository")
 )
 
 // Wrap wraps an error with additional context
@@ -55,8 +73,101 @@ func IsSessionNotFound(err error) bool {
 	return errors.Is(err, ErrSessionNotFound)
 }
 
-// ErrorHint provides helpful hints for common errors
+// IsRebaseConflict checks if the error is due to a rebase conflict
+func IsRebaseConflict(err error) bool {
+	return errors.Is(err, ErrRebaseConflict)
+}
+
+// IsCherryPickConflict checks if the error is due to a cherry-pick conflict
+func IsCherryPickConflict(err error) bool {
+	return errors.Is(err, ErrCherryPickConflict)
+}
+
+// IsRebaseStopped checks if the error is due to an interactive rebase left in progress
+func IsRebaseStopped(err error) bool {
+	return errors.Is(err, ErrRebaseStopped)
+}
+
+// IsNoUpstream checks if the error is due to a branch having no upstream
+func IsNoUpstream(err error) bool {
+	return errors.Is(err, ErrNoUpstream)
+}
+
+// IsLocked checks if the error is due to another command holding the repository lock
+func IsLocked(err error) bool {
+	return errors.Is(err, ErrLocked)
+}
+
+// IsSigning checks if error is a commit signing error
+func IsSigning(err error) bool {
+	return errors.Is(err, ErrSigning)
+}
+
+// IsHookFailed checks if error is a commit rejected by a git hook
+func IsHookFailed(err error) bool {
+	return errors.Is(err, ErrHookFailed)
+}
+
+// IsNotInteractive checks if error is an interactive selection attempted
+// without a terminal
+func IsNotInteractive(err error) bool {
+	return errors.Is(err, ErrNotInteractive)
+}
+
+// IsInterrupted checks if error is an operation stopped by Ctrl-C
+func IsInterrupted(err error) bool {
+	return errors.Is(err, ErrInterrupted)
+}
+
+// IsGitTimeout checks if error is a git command killed for taking longer
+// than git.timeout
+func IsGitTimeout(err error) bool {
+	return errors.Is(err, ErrGitTimeout)
+}
+
+// IsVerifyFailed checks if error is git.verify_command failing after a
+// rebase, which was then rolled back
+func IsVerifyFailed(err error) bool {
+	return errors.Is(err, ErrVerifyFailed)
+}
+
+// IsBranchPolicy checks if error is a branch name rejected by the branch
+// naming policy in the config
+func IsBranchPolicy(err error) bool {
+	return errors.Is(err, ErrBranchPolicy)
+}
+
+// IsNoMessageCommand checks if error is a commit message requested from
+// git.message_command when it is not set
+func IsNoMessageCommand(err error) bool {
+	return errors.Is(err, ErrNoMessageCommand)
+}
+
+// IsPinned checks if error is a pinned session refused removal
+func IsPinned(err error) bool {
+	return errors.Is(err, ErrPinned)
+}
+
+// IsProtectedBranch checks if error is a branch in git.protected_branches
+// refused rewriting
+func IsProtectedBranch(err error) bool {
+	return errors.Is(err, ErrProtectedBranch)
+}
+
+// IsMainRepository checks if error is an operation on sessions refused for
+// the main repository
+func IsMainRepository(err error) bool {
+	return errors.Is(err, ErrMainRepository)
+}
+
+// ErrorHint provides helpful hints for common errors, in the language
+// chosen with i18n
 func ErrorHint(err error) string {
+	return i18n.T(errorHint(err))
+}
+
+// errorHint returns the hint for err in English
+func errorHint(err error) string {
 	switch {
 	case IsUncommittedChanges(err):
 		return "Use 'git stash' to temporarily save changes"
@@ -70,6 +181,34 @@ func ErrorHint(err error) string {
 		return "Switch to main/master branch first, or use a different description"
 	case IsSessionNotFound(err):
 		return "Use 'ccswitch list' to see available sessions"
+	case IsRebaseConflict(err):
+		return "Rebase manually in the worktree to resolve the conflicts"
+	case IsCherryPickConflict(err):
+		return "Cherry-pick the commits manually to resolve the conflicts"
+	case IsRebaseStopped(err):
+		return "Finish it with 'git rebase --continue' in the worktree, then run the command again"
+	case IsNoUpstream(err):
+		return "Push once with 'git push -u <remote> <branch>' to set an upstream"
+	case IsLocked(err):
+		return "Wait for it to finish, or pass --wait to wait for the lock"
+	case IsHookFailed(err):
+		return "Fix what the hook reported, or pass --no-verify to skip commit hooks"
+	case IsSigning(err):
+		return "Check user.signingkey and gpg.format with 'git config', or turn off git.sign_commits"
+	case IsNotInteractive(err):
+		return "Name what to use as an argument, or pass --no-tui to choose from a numbered list read from stdin"
+	case IsGitTimeout(err):
+		return "git may be waiting for credentials it cannot prompt for; set up a credential helper or ssh-agent, or raise git.timeout"
+	case IsVerifyFailed(err):
+		return "The branch was reset to where it was before the rebase; fix what git.verify_command reported and try again"
+	case IsBranchPolicy(err):
+		return "Branch names follow branch.template, branch.types and branch.max_length; see 'ccswitch config'"
+	case IsNoMessageCommand(err):
+		return "Set git.message_command to a command that reads a diff on stdin and prints a commit message, e.g. claude -p 'Write a commit message for this diff'"
+	case IsPinned(err):
+		return "Run 'ccswitch unpin <session>' to allow removing it"
+	case IsProtectedBranch(err):
+		return "Protected branches are set with git.protected_branches"
 	default:
 		return ""
 	}
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"testing"
+
+	"github.com/ksred/ccswitch/internal/i18n"
 )
 
 func TestWrap(t *testing.T) {
@@ -64,6 +66,47 @@ func TestErrorCheckers(t *testing.T) {
 
 		{"IsSessionNotFound true", ErrSessionNotFound, IsSessionNotFound, true},
 		{"IsSessionNotFound false", ErrBranchNotFound, IsSessionNotFound, false},
+
+		{"IsSigning true", ErrSigning, IsSigning, true},
+		{"IsSigning false", ErrRebaseConflict, IsSigning, false},
+
+		{"IsHookFailed true", Wrap(ErrHookFailed, "context"), IsHookFailed, true},
+		{"IsHookFailed false", ErrSigning, IsHookFailed, false},
+
+		{"IsNotInteractive true", Wrap(ErrNotInteractive, "context"), IsNotInteractive, true},
+		{"IsNotInteractive false", ErrLocked, IsNotInteractive, false},
+
+		{"IsRebaseConflict true", ErrRebaseConflict, IsRebaseConflict, true},
+		{"IsRebaseConflict wrapped", Wrap(ErrRebaseConflict, "context"), IsRebaseConflict, true},
+		{"IsRebaseConflict false", ErrBranchExists, IsRebaseConflict, false},
+
+		{"IsCherryPickConflict true", ErrCherryPickConflict, IsCherryPickConflict, true},
+		{"IsCherryPickConflict false", ErrRebaseConflict, IsCherryPickConflict, false},
+
+		{"IsRebaseStopped true", ErrRebaseStopped, IsRebaseStopped, true},
+		{"IsRebaseStopped false", ErrRebaseConflict, IsRebaseStopped, false},
+
+		{"IsNoUpstream true", ErrNoUpstream, IsNoUpstream, true},
+		{"IsNoUpstream false", ErrRebaseConflict, IsNoUpstream, false},
+
+		{"IsLocked true", ErrLocked, IsLocked, true},
+		{"IsLocked false", ErrNoUpstream, IsLocked, false},
+
+		{"IsInterrupted true", ErrInterrupted, IsInterrupted, true},
+		{"IsInterrupted wrapped", Wrap(ErrInterrupted, "git rebase"), IsInterrupted, true},
+		{"IsInterrupted false", ErrGitTimeout, IsInterrupted, false},
+
+		{"IsGitTimeout true", ErrGitTimeout, IsGitTimeout, true},
+		{"IsGitTimeout false", ErrInterrupted, IsGitTimeout, false},
+
+		{"IsVerifyFailed true", Wrap(ErrVerifyFailed, "feature"), IsVerifyFailed, true},
+		{"IsVerifyFailed false", ErrHookFailed, IsVerifyFailed, false},
+
+		{"IsBranchPolicy true", Wrap(ErrBranchPolicy, "type \"wip\""), IsBranchPolicy, true},
+		{"IsBranchPolicy false", ErrBranchExists, IsBranchPolicy, false},
+
+		{"IsNoMessageCommand true", Wrap(ErrNoMessageCommand, "rebase"), IsNoMessageCommand, true},
+		{"IsNoMessageCommand false", ErrVerifyFailed, IsNoMessageCommand, false},
 	}
 
 	for _, tt := range tests {
@@ -77,6 +120,7 @@ func TestErrorCheckers(t *testing.T) {
 }
 
 func TestErrorHint(t *testing.T) {
+	i18n.SetLanguage(i18n.English)
 	tests := []struct {
 		name string
 		err  error
@@ -107,6 +151,51 @@ func TestErrorHint(t *testing.T) {
 			err:  ErrSessionNotFound,
 			want: "Use 'ccswitch list' to see available sessions",
 		},
+		{
+			name: "no upstream hint",
+			err:  ErrNoUpstream,
+			want: "Push once with 'git push -u <remote> <branch>' to set an upstream",
+		},
+		{
+			name: "locked hint",
+			err:  ErrLocked,
+			want: "Wait for it to finish, or pass --wait to wait for the lock",
+		},
+		{
+			name: "signing hint",
+			err:  ErrSigning,
+			want: "Check user.signingkey and gpg.format with 'git config', or turn off git.sign_commits",
+		},
+		{
+			name: "hook failed hint",
+			err:  ErrHookFailed,
+			want: "Fix what the hook reported, or pass --no-verify to skip commit hooks",
+		},
+		{
+			name: "not interactive hint",
+			err:  ErrNotInteractive,
+			want: "Name what to use as an argument, or pass --no-tui to choose from a numbered list read from stdin",
+		},
+		{
+			name: "git timeout hint",
+			err:  ErrGitTimeout,
+			want: "git may be waiting for credentials it cannot prompt for; set up a credential helper or ssh-agent, or raise git.timeout",
+		},
+		{
+			name: "verify failed hint",
+			err:  ErrVerifyFailed,
+			want: "The branch was reset to where it was before the rebase; fix what git.verify_command reported and try again",
+		},
+		{
+			name: "branch policy hint",
+			err:  ErrBranchPolicy,
+			want: "Branch names follow branch.template, branch.types and branch.max_length; see 'ccswitch config'",
+		},
+		{
+			name: "no message command hint",
+			err:  ErrNoMessageCommand,
+			want: "Set git.message_command to a command that reads a diff on stdin and prints a commit message, e.g. claude -p 'Write a commit message for this diff'",
+		},
 		{
 			name: "unknown error no hint",
 			err:  errors.New("unknown error"),
@@ -140,6 +229,19 @@ func TestErrorConstants(t *testing.T) {
 		ErrSessionNotFound,
 		ErrAlreadyOnBranch,
 		ErrNoSessions,
+		ErrRebaseConflict,
+		ErrCherryPickConflict,
+		ErrRebaseStopped,
+		ErrNoUpstream,
+		ErrLocked,
+		ErrSigning,
+		ErrHookFailed,
+		ErrNotInteractive,
+		ErrInterrupted,
+		ErrGitTimeout,
+		ErrVerifyFailed,
+		ErrBranchPolicy,
+		ErrNoMessageCommand,
 	}
 
 	seen := make(map[string]bool)
@@ -0,0 +1,327 @@
+// Package fanout rebases a set of worktrees onto a source branch.
+//
+// The engine never prints: progress is reported through an Observer and the
+// outcome of every target is returned as a Result, so the CLI, TUI and other
+// front ends can render the same operation differently.
+package fanout
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Status is the outcome of a fanout target
+type Status int
+
+const (
+	// StatusPending means the target was not processed, e.g. because the
+	// fanout stopped at an earlier target
+	StatusPending Status = iota
+	StatusSucceeded
+	StatusConflicted
+	StatusFailed
+	StatusSkipped
+	// StatusInterrupted means the rebase was stopped by Ctrl-C and aborted,
+	// leaving the target as it was
+	StatusInterrupted
+	// StatusVerifyFailed means the verify command failed after the rebase,
+	// which was rolled back
+	StatusVerifyFailed
+)
+
+var statusNames = []string{"pending", "succeeded", "conflicted", "failed", "skipped", "interrupted", "verify_failed"}
+
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// Check is the result of the pre-fanout safety checks for a target
+type Check struct {
+	Worktree git.Worktree
+	Dirty    bool
+	// Ahead and Behind count commits relative to the branch the target is
+	// rebased onto
+	Ahead  int
+	Behind int
+	// Stacked is set in stack mode, where commits ahead are replayed onto
+	// the parent rather than treated as unsafe
+	Stacked bool
+	// Remote is set for temporary worktrees of remote-only branches, whose
+	// commits ahead are someone else's work to replay, not local work
+	Remote bool
+	Err    error
+}
+
+// Safe reports whether the target can be rebased without losing work
+func (c Check) Safe() bool {
+	return c.Err == nil && !c.Dirty && (c.Ahead == 0 || c.Stacked || c.Remote)
+}
+
+// Forceable reports whether the target can be rebased if the user accepts
+// the risk: a clean branch ahead of its base has its commits replayed on
+// top, while uncommitted changes would block the rebase
+func (c Check) Forceable() bool {
+	return c.Err == nil && !c.Dirty
+}
+
+// Result is the outcome of rebasing a single target
+type Result struct {
+	Worktree git.Worktree
+	Status   Status
+	Err      error
+}
+
+// Observer receives progress callbacks while a fanout runs
+type Observer interface {
+	OnTargetStart(wt git.Worktree)
+	OnTargetDone(result Result)
+	OnConflict(result Result)
+}
+
+// VerifyObserver is implemented by observers that want to know when the
+// verify command starts in a target, after it was rebased
+type VerifyObserver interface {
+	OnVerify(wt git.Worktree)
+}
+
+// NopObserver ignores all callbacks
+type NopObserver struct{}
+
+func (NopObserver) OnTargetStart(git.Worktree) {}
+func (NopObserver) OnTargetDone(Result)        {}
+func (NopObserver) OnConflict(Result)          {}
+
+// Engine rebases worktrees onto a source branch
+type Engine struct {
+	source   string
+	observer Observer
+	// parents maps stacked branches to their parent in stack mode
+	parents map[string]string
+	stacked bool
+	// sign signs the rebased commits with --gpg-sign
+	sign bool
+	// tips records target tips before they were rebased, so branches
+	// stacked on them can be replayed with rebase --onto
+	tips map[string]string
+	// verify is a shell command run in each target after it was rebased
+	verify string
+	// interrupted stops the run before its next target
+	interrupted atomic.Bool
+}
+
+// New creates an engine that rebases onto source and reports to observer.
+// A nil observer is replaced with NopObserver.
+func New(source string, observer Observer) *Engine {
+	if observer == nil {
+		observer = NopObserver{}
+	}
+	return &Engine{source: source, observer: observer, tips: make(map[string]string)}
+}
+
+// Sign makes the engine sign the commits it rebases
+func (e *Engine) Sign(sign bool) {
+	e.sign = sign
+}
+
+// Verify makes the engine run command with the system shell in each target
+// after rebasing it. If the command fails, the target is reset to its
+// backup ref (see git.BackupRef), saved before the rebase, and the run
+// stops.
+func (e *Engine) Verify(command string) {
+	e.verify = command
+}
+
+// Stack switches the engine to stack mode: each target is rebased onto its
+// parent from parents (see Parents) instead of onto the source
+func (e *Engine) Stack(parents map[string]string) *Engine {
+	e.parents = parents
+	e.stacked = true
+	return e
+}
+
+// Onto returns the branch wt is rebased onto
+func (e *Engine) Onto(wt git.Worktree) string {
+	if parent, ok := e.parents[wt.Branch]; ok && e.stacked {
+		return parent
+	}
+	return e.source
+}
+
+// Source returns the branch targets are rebased onto
+func (e *Engine) Source() string {
+	return e.source
+}
+
+// Targets selects the worktrees a fanout from currentDir applies to: every
+// worktree other than currentDir that has a branch other than the source
+func (e *Engine) Targets(worktrees []git.Worktree, currentDir string) []git.Worktree {
+	var targets []git.Worktree
+	for _, wt := range worktrees {
+		if wt.Path != currentDir && wt.Branch != "" && wt.Branch != e.source {
+			targets = append(targets, wt)
+		}
+	}
+	return targets
+}
+
+// Interrupt stops a run before its next target, e.g. on Ctrl-C. It is safe
+// to call while Run is in progress; a rebase in progress is stopped and
+// aborted by the git runner.
+func (e *Engine) Interrupt() {
+	e.interrupted.Store(true)
+}
+
+// Interrupted reports whether the run was interrupted
+func (e *Engine) Interrupted() bool {
+	return e.interrupted.Load()
+}
+
+// Check runs the safety checks for each target
+func (e *Engine) Check(targets []git.Worktree) []Check {
+	checks := make([]Check, 0, len(targets))
+	for _, wt := range targets {
+		check := Check{Worktree: wt, Dirty: git.HasUncommittedChanges(wt.Path), Stacked: e.stacked}
+		if !check.Dirty {
+			check.Ahead, check.Behind, check.Err = git.GetAheadBehind(wt.Path, e.Onto(wt))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// Run rebases each target onto the source branch (or its parent in stack
+// mode) in order; see Order for sorting stacked targets. It stops at the
+// first conflict or failure, or when interrupted; remaining targets are
+// returned as pending.
+func (e *Engine) Run(targets []git.Worktree) []Result {
+	results := make([]Result, len(targets))
+	for i, wt := range targets {
+		results[i] = Result{Worktree: wt, Status: StatusPending}
+	}
+
+	for i, wt := range targets {
+		if e.interrupted.Load() {
+			break
+		}
+		e.observer.OnTargetStart(wt)
+
+		result := e.rebase(wt)
+		results[i] = result
+
+		if result.Status == StatusConflicted {
+			e.observer.OnConflict(result)
+		}
+		if result.Status == StatusInterrupted {
+			e.Interrupt()
+		}
+		e.observer.OnTargetDone(result)
+
+		if result.Status != StatusSucceeded {
+			break
+		}
+	}
+
+	return results
+}
+
+// rebase rebases a single worktree onto its target branch, auto-aborting on conflict
+func (e *Engine) rebase(wt git.Worktree) Result {
+	tip, err := git.ResolveRef(wt.Path, wt.Branch)
+	if err != nil {
+		return Result{Worktree: wt, Status: StatusFailed, Err: err}
+	}
+	e.tips[wt.Branch] = tip
+	if e.verify != "" {
+		if err := git.SaveBackup(wt.Path, wt.Branch); err != nil {
+			return Result{Worktree: wt, Status: StatusFailed, Err: err}
+		}
+	}
+
+	rebaser := git.NewRebaseManager(wt.Path)
+	rebaser.Sign = e.sign
+	onto := e.Onto(wt)
+	if oldTip, ok := e.rewrittenParent(wt); ok {
+		// The parent was rewritten earlier in this run: replay only the
+		// commits made on top of its old tip
+		_, _, err = rebaser.RebaseOnto(onto, oldTip)
+	} else {
+		_, _, err = rebaser.RebaseCommit(onto)
+	}
+
+	switch {
+	case err == nil && e.verify != "":
+		return e.verifyTarget(wt)
+	case err == nil:
+		return Result{Worktree: wt, Status: StatusSucceeded}
+	case errors.IsRebaseConflict(err):
+		return Result{Worktree: wt, Status: StatusConflicted, Err: err}
+	case errors.IsInterrupted(err):
+		return Result{Worktree: wt, Status: StatusInterrupted, Err: err}
+	default:
+		return Result{Worktree: wt, Status: StatusFailed, Err: err}
+	}
+}
+
+// verifyOutputLines is how much of the verify command's output a failure
+// reports
+const verifyOutputLines = 20
+
+// verifyTarget runs the verify command in a rebased target, rolling the
+// rebase back if the command fails or is interrupted
+func (e *Engine) verifyTarget(wt git.Worktree) Result {
+	if o, ok := e.observer.(VerifyObserver); ok {
+		o.OnVerify(wt)
+	}
+	output, err := proc.RunShell(wt.Path, e.verify)
+	if err == nil {
+		return Result{Worktree: wt, Status: StatusSucceeded}
+	}
+
+	result := Result{Worktree: wt, Status: StatusVerifyFailed}
+	if e.interrupted.Load() {
+		result.Status = StatusInterrupted
+		result.Err = fmt.Errorf("verify command: %w, rebase rolled back", errors.ErrInterrupted)
+	} else {
+		result.Err = fmt.Errorf("%w (%v), rebase rolled back", errors.ErrVerifyFailed, err)
+		if tail := utils.LastLines(string(output), verifyOutputLines); tail != "" {
+			result.Err = fmt.Errorf("%w:\n%s", result.Err, tail)
+		}
+	}
+	if restoreErr := git.RestoreBackup(wt.Path, wt.Branch); restoreErr != nil {
+		return Result{Worktree: wt, Status: StatusFailed, Err: fmt.Errorf("%v; %w", result.Err, restoreErr)}
+	}
+	return result
+}
+
+// rewrittenParent returns the tip wt's parent had before it was rebased
+// earlier in this run, if it was
+func (e *Engine) rewrittenParent(wt git.Worktree) (string, bool) {
+	onto := e.Onto(wt)
+	oldTip, ok := e.tips[onto]
+	return oldTip, ok && onto != e.source
+}
+
+// RebaseArgs returns the git rebase arguments used for wt, so the rebase can
+// be repeated by hand after a conflict
+func (e *Engine) RebaseArgs(wt git.Worktree) string {
+	if oldTip, ok := e.rewrittenParent(wt); ok {
+		return fmt.Sprintf("--onto %s %s", e.Onto(wt), oldTip)
+	}
+	return e.Onto(wt)
+}
+
+// Count returns the number of results with the given status
+func Count(results []Result, status Status) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
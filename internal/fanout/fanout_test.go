@@ -0,0 +1,280 @@
+package fanout
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// recordingObserver records the callbacks it receives
+type recordingObserver struct {
+	started   []string
+	done      []Status
+	conflicts []string
+}
+
+func (o *recordingObserver) OnTargetStart(wt git.Worktree) { o.started = append(o.started, wt.Branch) }
+func (o *recordingObserver) OnTargetDone(r Result)         { o.done = append(o.done, r.Status) }
+func (o *recordingObserver) OnConflict(r Result) {
+	o.conflicts = append(o.conflicts, r.Worktree.Branch)
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git %v failed: %v, output: %s", args, err, output)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// setupRepo creates a repo on main with two worktrees: "clean" rebases
+// cleanly onto main, "conflict" conflicts with main's latest commit
+func setupRepo(t *testing.T) (repo string, clean, conflict git.Worktree) {
+	t.Helper()
+	root := t.TempDir()
+	repo = filepath.Join(root, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo dir: %v", err)
+	}
+
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	writeFile(t, filepath.Join(repo, "file.txt"), "base\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "base")
+
+	clean = git.Worktree{Path: filepath.Join(root, "clean"), Branch: "clean"}
+	conflict = git.Worktree{Path: filepath.Join(root, "conflict"), Branch: "conflict"}
+	runGit(t, repo, "worktree", "add", "-b", clean.Branch, clean.Path)
+	runGit(t, repo, "worktree", "add", "-b", conflict.Branch, conflict.Path)
+
+	writeFile(t, filepath.Join(conflict.Path, "file.txt"), "conflict\n")
+	runGit(t, conflict.Path, "commit", "-am", "conflicting change")
+
+	writeFile(t, filepath.Join(repo, "file.txt"), "main\n")
+	runGit(t, repo, "commit", "-am", "main change")
+
+	return repo, clean, conflict
+}
+
+func TestEngineTargetsAndCheck(t *testing.T) {
+	repo, clean, conflict := setupRepo(t)
+
+	engine := New("main", nil)
+	worktrees := []git.Worktree{{Path: repo, Branch: "main"}, clean, conflict, {Path: "/detached"}}
+	targets := engine.Targets(worktrees, repo)
+	if len(targets) != 2 {
+		t.Fatalf("Targets() returned %d worktrees, expected 2", len(targets))
+	}
+
+	checks := engine.Check(targets)
+	if !checks[0].Safe() || checks[0].Behind != 1 {
+		t.Errorf("clean worktree check = %+v, expected safe and behind by 1", checks[0])
+	}
+	if checks[1].Safe() {
+		t.Errorf("diverged worktree is ahead and should not be safe: %+v", checks[1])
+	}
+	if !checks[1].Forceable() {
+		t.Errorf("diverged worktree is clean and should be forceable: %+v", checks[1])
+	}
+	if (Check{Dirty: true}).Forceable() {
+		t.Error("dirty worktree should not be forceable")
+	}
+}
+
+func TestEngineRunStopsAtConflict(t *testing.T) {
+	_, clean, conflict := setupRepo(t)
+
+	observer := &recordingObserver{}
+	engine := New("main", observer)
+	pending := git.Worktree{Path: "/never/used", Branch: "pending"}
+
+	results := engine.Run([]git.Worktree{clean, conflict, pending})
+
+	expected := []Status{StatusSucceeded, StatusConflicted, StatusPending}
+	for i, r := range results {
+		if r.Status != expected[i] {
+			t.Errorf("results[%d].Status = %s, expected %s", i, r.Status, expected[i])
+		}
+	}
+	if len(observer.started) != 2 || len(observer.done) != 2 {
+		t.Errorf("observer saw %d starts and %d completions, expected 2 each", len(observer.started), len(observer.done))
+	}
+	if len(observer.conflicts) != 1 || observer.conflicts[0] != "conflict" {
+		t.Errorf("observer conflicts = %v, expected [conflict]", observer.conflicts)
+	}
+	if git.HasUncommittedChanges(conflict.Path) {
+		t.Error("conflicting rebase should have been aborted")
+	}
+	if Count(results, StatusSucceeded) != 1 {
+		t.Errorf("Count(StatusSucceeded) = %d, expected 1", Count(results, StatusSucceeded))
+	}
+}
+
+// setupStack creates a repo where "b" is stacked on "a", and main and "a"
+// have both moved on since the branches were created
+func setupStack(t *testing.T) (repo string, a, b git.Worktree) {
+	t.Helper()
+	root := t.TempDir()
+	repo = filepath.Join(root, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo dir: %v", err)
+	}
+
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	writeFile(t, filepath.Join(repo, "file.txt"), "base\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "base")
+
+	a = git.Worktree{Path: filepath.Join(root, "a"), Branch: "a"}
+	b = git.Worktree{Path: filepath.Join(root, "b"), Branch: "b"}
+	runGit(t, repo, "worktree", "add", "-b", a.Branch, a.Path)
+	writeFile(t, filepath.Join(a.Path, "a.txt"), "a\n")
+	runGit(t, a.Path, "add", ".")
+	runGit(t, a.Path, "commit", "-m", "a change")
+
+	runGit(t, repo, "worktree", "add", "-b", b.Branch, b.Path, a.Branch)
+	writeFile(t, filepath.Join(b.Path, "b.txt"), "b\n")
+	runGit(t, b.Path, "add", ".")
+	runGit(t, b.Path, "commit", "-m", "b change")
+
+	writeFile(t, filepath.Join(repo, "main.txt"), "main\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "main change")
+
+	return repo, a, b
+}
+
+func TestEngineParentsAndOrder(t *testing.T) {
+	repo, a, b := setupStack(t)
+
+	engine := New("main", nil)
+	parents, err := engine.Parents(repo, []git.Worktree{b, a})
+	if err != nil {
+		t.Fatalf("Parents() failed: %v", err)
+	}
+	if len(parents) != 1 || parents["b"] != "a" {
+		t.Errorf("Parents() = %v, expected map[b:a]", parents)
+	}
+
+	ordered := Order([]git.Worktree{b, a}, parents)
+	if ordered[0].Branch != "a" || ordered[1].Branch != "b" {
+		t.Errorf("Order() = [%s %s], expected [a b]", ordered[0].Branch, ordered[1].Branch)
+	}
+}
+
+func TestEngineRunStack(t *testing.T) {
+	repo, a, b := setupStack(t)
+
+	engine := New("main", nil)
+	parents, err := engine.Parents(repo, []git.Worktree{a, b})
+	if err != nil {
+		t.Fatalf("Parents() failed: %v", err)
+	}
+	engine.Stack(parents)
+
+	if onto := engine.Onto(b); onto != "a" {
+		t.Errorf("Onto(b) = %s, expected a", onto)
+	}
+	for _, check := range engine.Check([]git.Worktree{a, b}) {
+		if !check.Safe() {
+			t.Errorf("stacked check for %s should be safe: %+v", check.Worktree.Branch, check)
+		}
+	}
+
+	results := engine.Run(Order([]git.Worktree{b, a}, parents))
+	if Count(results, StatusSucceeded) != 2 {
+		t.Fatalf("expected both branches to succeed, got %+v", results)
+	}
+
+	// b now holds main's change and a's commit exactly once
+	if n, _ := git.CountCommits(repo, "main..b"); n != 2 {
+		t.Errorf("main..b has %d commits, expected 2", n)
+	}
+	if !git.IsAncestor(repo, "main", "a") || !git.IsAncestor(repo, "a", "b") {
+		t.Error("expected main <- a <- b after stacked fanout")
+	}
+}
+
+func TestEngineRunInterrupted(t *testing.T) {
+	_, clean, conflict := setupRepo(t)
+
+	observer := &recordingObserver{}
+	engine := New("main", observer)
+	engine.Interrupt()
+	results := engine.Run([]git.Worktree{clean, conflict})
+
+	if len(observer.started) != 0 {
+		t.Errorf("started %v after the interruption, expected nothing", observer.started)
+	}
+	if n := Count(results, StatusPending); n != 2 {
+		t.Errorf("%d results pending, expected 2", n)
+	}
+	if !engine.Interrupted() {
+		t.Error("Interrupted() = false, expected true")
+	}
+}
+
+// verifyingObserver also records when the verify command starts
+type verifyingObserver struct {
+	recordingObserver
+	verified []string
+}
+
+func (o *verifyingObserver) OnVerify(wt git.Worktree) { o.verified = append(o.verified, wt.Branch) }
+
+func TestEngineRunVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	repo, a, b := setupStack(t)
+
+	tipB, err := git.ResolveRef(b.Path, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only a passes: it has no b.txt
+	observer := &verifyingObserver{}
+	engine := New("main", observer)
+	engine.Verify("test ! -f b.txt || { echo tests failed; exit 1; }")
+
+	results := engine.Run([]git.Worktree{a, b})
+	if results[0].Status != StatusSucceeded || results[1].Status != StatusVerifyFailed {
+		t.Fatalf("statuses = %s, %s, expected succeeded, verify_failed (errors %v, %v)",
+			results[0].Status, results[1].Status, results[0].Err, results[1].Err)
+	}
+	if !errors.IsVerifyFailed(results[1].Err) || !strings.Contains(results[1].Err.Error(), "tests failed") {
+		t.Errorf("error = %v, expected the verify command's output", results[1].Err)
+	}
+	if len(observer.verified) != 2 {
+		t.Errorf("OnVerify called for %v, expected both targets", observer.verified)
+	}
+
+	if tip, _ := git.ResolveRef(b.Path, "b"); tip != tipB {
+		t.Errorf("b = %s after failing verification, expected it rolled back to %s", tip, tipB)
+	}
+	if backup, _ := git.ResolveRef(repo, git.BackupRef("b")); backup != tipB {
+		t.Errorf("backup of b = %s, expected %s", backup, tipB)
+	}
+	if ahead, _, _ := git.GetAheadBehind(a.Path, "main"); ahead != 1 {
+		t.Errorf("a is %d commits ahead of main, expected it rebased and kept", ahead)
+	}
+}
@@ -0,0 +1,76 @@
+package fanout
+
+import "github.com/ksred/ccswitch/internal/git"
+
+// Parents detects which targets are stacked on other targets, returning a
+// map from branch to parent branch. A branch is stacked on another target
+// when that target's tip is in its history and adds commits beyond the
+// source; the nearest such target is its parent. Branches based directly on
+// the source are absent from the map.
+func (e *Engine) Parents(dir string, targets []git.Worktree) (map[string]string, error) {
+	tips := make(map[string]string, len(targets))
+	distance := make(map[string]int, len(targets))
+	for _, wt := range targets {
+		tip, err := git.ResolveRef(dir, wt.Branch)
+		if err != nil {
+			return nil, err
+		}
+		tips[wt.Branch] = tip
+
+		n, err := git.CountCommits(dir, e.source+".."+wt.Branch)
+		if err != nil {
+			return nil, err
+		}
+		distance[wt.Branch] = n
+	}
+
+	parents := make(map[string]string)
+	for _, child := range targets {
+		best := ""
+		for _, candidate := range targets {
+			c := candidate.Branch
+			if c == child.Branch || tips[c] == tips[child.Branch] || distance[c] == 0 {
+				continue
+			}
+			if distance[c] <= distance[best] {
+				continue
+			}
+			if git.IsAncestor(dir, tips[c], tips[child.Branch]) {
+				best = c
+			}
+		}
+		if best != "" {
+			parents[child.Branch] = best
+		}
+	}
+
+	return parents, nil
+}
+
+// Order returns targets sorted so that every parent comes before the
+// branches stacked on it. Otherwise the original order is kept.
+func Order(targets []git.Worktree, parents map[string]string) []git.Worktree {
+	byBranch := make(map[string]git.Worktree, len(targets))
+	for _, wt := range targets {
+		byBranch[wt.Branch] = wt
+	}
+
+	ordered := make([]git.Worktree, 0, len(targets))
+	visited := make(map[string]bool, len(targets))
+	var visit func(wt git.Worktree)
+	visit = func(wt git.Worktree) {
+		if visited[wt.Branch] {
+			return
+		}
+		visited[wt.Branch] = true
+		if parent, ok := byBranch[parents[wt.Branch]]; ok {
+			visit(parent)
+		}
+		ordered = append(ordered, wt)
+	}
+
+	for _, wt := range targets {
+		visit(wt)
+	}
+	return ordered
+}
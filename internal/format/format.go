@@ -0,0 +1,152 @@
+// Package format renders the output of commands with user templates, in
+// the way of docker ps --format
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Session is what a template sees for each session listed by list and
+// status: its fields in 'ccswitch status --json', along with those of the
+// status it belongs to
+type Session struct {
+	schema.StatusSession
+	// Repo is the repository of the session, and Base the branch Dirty,
+	// Ahead and Behind are relative to
+	Repo string `json:"repo"`
+	Base string `json:"base"`
+}
+
+// Operation is what a template sees for each entry shown by history
+type Operation struct {
+	schema.Operation
+	Repo string `json:"repo"`
+}
+
+// Sessions returns the sessions of a status for templates
+func Sessions(doc *schema.Status) []Session {
+	sessions := make([]Session, len(doc.Sessions))
+	for i, s := range doc.Sessions {
+		sessions[i] = Session{StatusSession: s, Repo: doc.Repo, Base: doc.Base}
+	}
+	return sessions
+}
+
+// Operations returns the operations of a history for templates
+func Operations(doc *schema.History) []Operation {
+	ops := make([]Operation, len(doc.Operations))
+	for i, op := range doc.Operations {
+		ops[i] = Operation{Operation: op, Repo: doc.Repo}
+	}
+	return ops
+}
+
+// Parse parses a template rendered once per item, ending it with a newline
+// unless it has one
+func Parse(text string) (*template.Template, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("format").Funcs(funcs).Parse(text)
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"pad": func(width int, v any) string {
+		return fmt.Sprintf("%-*v", width, v)
+	},
+	"ago": func(v any) string {
+		switch t := v.(type) {
+		case time.Time:
+			return utils.FormatTime(t, false)
+		case *time.Time:
+			if t != nil {
+				return utils.FormatTime(*t, false)
+			}
+		}
+		return ""
+	},
+}
+
+// Help documents the data templates see and the functions they can call
+const Help = `--format renders the output of list, status, history and prompt with a
+Go template (https://pkg.go.dev/text/template), once per session or
+operation, instead of the usual output. Fields are the Go names of those in the
+command's --json output.
+
+Session, for each session of list and status:
+  .Name        Session name
+  .Branch      Branch, empty for detached sessions
+  .Detached    Whether the worktree has a detached HEAD
+  .Ref         Tag or commit a detached session was created at
+  .Path        Worktree directory
+  .Dirty       Whether there are uncommitted changes
+  .Ahead       Commits ahead of .Base
+  .Behind      Commits behind .Base
+  .Error       Why the session's state could not be read, if it couldn't
+  .CreatedAt   When the session was created
+  .LastActive  When the session's last commit was made
+  .LastUsed    When the session was last switched to or used
+  .Issue       Ticket the session works on, e.g. JIRA-123
+  .IssueURL    Address of the session's task
+  .Tags        Tags of the session
+  .Notes       Notes kept with the session (see 'ccswitch note')
+  .Pinned      Whether cleanup and prune-artifacts skip the session
+  .ExpiresAt   When a session created with --ttl expires
+  .Expired     Whether it has expired
+  .Identity    Git identity the session commits as
+  .UserName    Author name the identity sets
+  .UserEmail   Author email the identity sets
+  .Activity    What the session's agent last reported, with .Status,
+               .UpdatedAt and .Quiet; nil without heartbeats
+  .Repo        Repository name
+  .Base        Branch the status is relative to
+
+Operation, for each entry of history:
+  .At          When the operation happened
+  .Op          create, delete, rename, move, rebase, fanout or pick
+  .Command     ccswitch command that performed it
+  .User        User that ran the command
+  .Session     Session it affected
+  .Branches    Branches it affected
+  .Result      succeeded, failed, conflicted or interrupted
+  .Error       What went wrong, if anything
+  .Repo        Repository name
+
+Prompt, for the session of the current directory in prompt:
+  .Name        Session name
+  .Branch      Branch, empty for detached sessions
+  .Commit      Short commit a detached session is at
+  .Dirty       Whether there are uncommitted changes
+  .Repo        Repository name
+  .Path        Worktree directory
+
+Functions, besides the template builtins:
+  join LIST SEP   Joins a list, e.g. {{join .Tags ","}}
+  pad N VALUE     Pads a value to N characters, to line up columns
+  ago TIME        Relative time, e.g. "3h ago"
+  json VALUE      JSON encoding, e.g. {{json .}}
+  upper, lower    Changes the case of a string
+
+Optional times are nil when unset: ago prints nothing for them, and
+{{with .LastUsed}}...{{end}} skips them.
+
+Examples:
+  ccswitch list --format '{{pad 20 .Name}} {{.Branch}}'
+  ccswitch status --format '{{.Name}}{{if .Dirty}} (dirty){{end}} +{{.Ahead}}'
+  ccswitch history --format '{{ago .At}} {{.User}} {{.Op}} {{join .Branches ","}}'
+  ccswitch list --tag frontend --format '{{json .}}'
+  ccswitch prompt --format '{{.Name}}{{if .Dirty}}*{{end}}'`
@@ -0,0 +1,63 @@
+package format
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// fieldNames returns the fields templates see in a struct type, with
+// those of embedded structs promoted
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, fieldNames(f.Type)...)
+			continue
+		}
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestHelpDocumentsEveryField(t *testing.T) {
+	for _, v := range []any{Session{}, Operation{}} {
+		for _, name := range fieldNames(reflect.TypeOf(v)) {
+			if !strings.Contains(Help, "  ."+name+" ") {
+				t.Errorf("Help does not document %T.%s", v, name)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	used := time.Now().Add(-3 * time.Hour)
+	doc := &schema.Status{Repo: "project", Base: "main", Sessions: []schema.StatusSession{
+		{Name: "auth", Branch: "feature/auth", Ahead: 2, Dirty: true, LastUsed: &used, Tags: []string{"a", "b"}},
+		{Name: "ui", Branch: "feature/ui"},
+	}}
+
+	tmpl, err := Parse(`{{pad 6 .Name}}{{.Repo}}/{{.Branch}}{{if .Dirty}} dirty{{end}} +{{.Ahead}} [{{join .Tags ","}}] {{ago .LastUsed}}`)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	for _, s := range Sessions(doc) {
+		if err := tmpl.Execute(&buf, s); err != nil {
+			t.Fatalf("Execute() failed: %v", err)
+		}
+	}
+	expected := "auth  project/feature/auth dirty +2 [a,b] 3h ago\nui    project/feature/ui +0 [] \n"
+	if buf.String() != expected {
+		t.Errorf("output = %q, expected %q", buf.String(), expected)
+	}
+
+	if _, err := Parse("{{.Name"); err == nil {
+		t.Error("Parse() accepted an unterminated action")
+	}
+}
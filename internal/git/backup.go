@@ -0,0 +1,32 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BackupRef returns the ref a branch's tip is saved under before ccswitch
+// rewrites the branch, so it can be restored
+func BackupRef(branch string) string {
+	return "refs/ccswitch/backup/" + branch
+}
+
+// SaveBackup saves the current tip of branch as its backup, replacing any
+// earlier one
+func SaveBackup(dir, branch string) error {
+	result, err := run(dir, "update-ref", BackupRef(branch), "refs/heads/"+branch)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w, output: %s", branch, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// RestoreBackup resets branch, which is checked out in dir, to its backup,
+// discarding what was committed since and changes to tracked files
+func RestoreBackup(dir, branch string) error {
+	result, err := runCleanup(nil, dir, "reset", "--hard", BackupRef(branch))
+	if err != nil {
+		return fmt.Errorf("failed to restore %s from %s: %w, output: %s", branch, BackupRef(branch), err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "first.txt", "first\n")
+
+	before, err := ResolveRef(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveBackup(repo, "main"); err != nil {
+		t.Fatalf("SaveBackup() failed: %v", err)
+	}
+	if backup, err := ResolveRef(repo, BackupRef("main")); err != nil || backup != before {
+		t.Fatalf("backup = %q, %v, expected %s", backup, err, before)
+	}
+
+	commitIn(t, repo, "second.txt", "second\n")
+	if err := os.WriteFile(filepath.Join(repo, "first.txt"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreBackup(repo, "main"); err != nil {
+		t.Fatalf("RestoreBackup() failed: %v", err)
+	}
+	if after, _ := ResolveRef(repo, "main"); after != before {
+		t.Errorf("main = %s after restoring, expected %s", after, before)
+	}
+	if HasUncommittedChanges(repo) {
+		t.Error("RestoreBackup() left changes behind")
+	}
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/proc"
+)
+
+// BisectStart starts bisecting in the worktree at dir between good, a commit
+// without the problem, and bad, one with it, checking out the first commit
+// to test
+func BisectStart(dir, good, bad string) error {
+	if result, err := run(dir, "bisect", "start", bad, good); err != nil {
+		return fmt.Errorf("failed to start bisecting: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// BisectRun tests commits in the worktree at dir with 'git bisect run' until
+// the first bad one is found, writing git's progress and the command's
+// output to out. command is run by the system shell and exits with 0 for
+// good commits, 125 for commits that cannot be tested and other codes below
+// 128 for bad ones. It stops when ccswitch is interrupted, but unlike other
+// git commands it is not timed, since testing can take long, and runs in
+// the user's locale.
+func BisectRun(dir, command string, out io.Writer) error {
+	args := append([]string{"bisect", "run"}, proc.ShellArgs(command)...)
+	untimed := &ExecRunner{}
+	_, err := untimed.Run(context.Background(), Command{Dir: dir, Args: args, Stdout: out, Stderr: out})
+	return err
+}
+
+// BisectCulprit returns the first bad commit a finished bisect in dir found,
+// or an empty string if it found none, e.g. because every candidate was
+// skipped
+func BisectCulprit(dir string) (string, error) {
+	result, err := run(dir, "bisect", "log")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the bisect log: %w", err)
+	}
+	const marker = "# first bad commit: ["
+	for _, line := range strings.Split(string(result.Stdout), "\n") {
+		if rest, ok := strings.CutPrefix(line, marker); ok {
+			if hash, _, ok := strings.Cut(rest, "]"); ok {
+				return hash, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// BisectReset ends bisecting in dir. It runs as cleanup, so it also runs
+// after an interruption.
+func BisectReset(dir string) error {
+	if result, err := runCleanup(nil, dir, "bisect", "reset"); err != nil {
+		return fmt.Errorf("failed to reset bisect: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBisect(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "one.txt", "1\n")
+	good, err := ResolveRef(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRef() failed: %v", err)
+	}
+	commitIn(t, repo, "two.txt", "2\n")
+	commitIn(t, repo, "broken.txt", "bug\n")
+	culprit, err := ResolveRef(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRef() failed: %v", err)
+	}
+	commitIn(t, repo, "three.txt", "3\n")
+	commitIn(t, repo, "four.txt", "4\n")
+
+	if err := BisectStart(repo, good, "HEAD"); err != nil {
+		t.Fatalf("BisectStart() failed: %v", err)
+	}
+	// git.timeout is for hung network operations, not test suites
+	defer func(runner GitRunner) { DefaultRunner = runner }(DefaultRunner)
+	DefaultRunner = &ExecRunner{Timeout: time.Nanosecond}
+
+	var out bytes.Buffer
+	if err := BisectRun(repo, "test ! -f broken.txt", &out); err != nil {
+		t.Fatalf("BisectRun() failed: %v, output: %s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "is the first bad commit") {
+		t.Errorf("BisectRun() output = %q", out.String())
+	}
+
+	DefaultRunner = &ExecRunner{}
+	found, err := BisectCulprit(repo)
+	if err != nil || found != culprit {
+		t.Errorf("BisectCulprit() = %q, %v; expected %s", found, err, culprit)
+	}
+
+	if err := BisectReset(repo); err != nil {
+		t.Fatalf("BisectReset() failed: %v", err)
+	}
+	if branch, _ := GetCurrentBranch(repo); branch != "main" {
+		t.Errorf("branch after BisectReset() = %q", branch)
+	}
+}
@@ -2,13 +2,14 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 )
 
 // BranchManager handles git branch operations
 type BranchManager struct {
 	repoPath string
+	// Runner runs git; nil means DefaultRunner
+	Runner GitRunner
 }
 
 // NewBranchManager creates a new BranchManager
@@ -18,11 +19,20 @@ func NewBranchManager(repoPath string) *BranchManager {
 
 // Create creates a new branch
 func (bm *BranchManager) Create(name string) error {
-	cmd := exec.Command("git", "branch", name)
-	cmd.Dir = bm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := bm.run("branch", name)
 	if err != nil {
-		return fmt.Errorf("failed to create branch: %w, output: %s", err, string(output))
+		return fmt.Errorf("failed to create branch: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// CreateFrom creates a new branch starting at startPoint. The branch does
+// not track startPoint, even if it is a remote-tracking branch such as
+// origin/develop, so it is later pushed under its own name.
+func (bm *BranchManager) CreateFrom(name, startPoint string) error {
+	result, err := bm.run("branch", "--no-track", name, startPoint)
+	if err != nil {
+		return fmt.Errorf("failed to create branch: %w, output: %s", err, string(result.Combined))
 	}
 	return nil
 }
@@ -33,38 +43,72 @@ func (bm *BranchManager) Delete(name string, force bool) error {
 	if force {
 		flag = "-D"
 	}
-	cmd := exec.Command("git", "branch", flag, name)
-	cmd.Dir = bm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := bm.run("branch", flag, name)
 	if err != nil {
-		return fmt.Errorf("failed to delete branch: %w, output: %s", err, string(output))
+		return fmt.Errorf("failed to delete branch: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// Rename renames a branch, including where it is checked out in worktrees
+func (bm *BranchManager) Rename(oldName, newName string) error {
+	result, err := bm.run("branch", "-m", oldName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch: %w, output: %s", err, string(result.Combined))
 	}
 	return nil
 }
 
 // Exists checks if a branch exists
 func (bm *BranchManager) Exists(name string) bool {
-	cmd := exec.Command("git", "rev-parse", "--verify", "refs/heads/"+name) // #nosec G204
-	cmd.Dir = bm.repoPath
-	output, err := cmd.CombinedOutput()
-	return err == nil && strings.TrimSpace(string(output)) != ""
+	result, err := bm.run("rev-parse", "--verify", "refs/heads/"+name)
+	return err == nil && strings.TrimSpace(string(result.Stdout)) != ""
+}
+
+// List returns the names of all local branches
+func (bm *BranchManager) List() ([]string, error) {
+	result, err := bm.run("for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return strings.Fields(string(result.Stdout)), nil
+}
+
+// ListWithRemotes returns the names of all local branches followed by the
+// remote-tracking branches, e.g. origin/main, leaving out the remotes' HEAD
+func (bm *BranchManager) ListWithRemotes() ([]string, error) {
+	result, err := bm.run("for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var branches []string
+	for _, ref := range strings.Fields(string(result.Stdout)) {
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branches = append(branches, name)
+		} else if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok && !strings.HasSuffix(name, "/HEAD") {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
 }
 
 // GetCurrent returns the current branch name
 func (bm *BranchManager) GetCurrent() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	cmd.Dir = bm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := bm.run("branch", "--show-current")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(string(result.Stdout)), nil
 }
 
 // HasUncommittedChanges checks if there are uncommitted changes
 func (bm *BranchManager) HasUncommittedChanges() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = bm.repoPath
-	output, err := cmd.CombinedOutput()
-	return err == nil && strings.TrimSpace(string(output)) != ""
+	result, err := bm.run("status", "--porcelain")
+	return err == nil && strings.TrimSpace(string(result.Stdout)) != ""
+}
+
+// run runs git with args in the repository
+func (bm *BranchManager) run(args ...string) (Result, error) {
+	return runWith(bm.Runner, bm.repoPath, args...)
 }
@@ -2,13 +2,26 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
 )
 
 // CommitManager handles git commit operations
 type CommitManager struct {
 	repoPath string
+	// Runner runs git; nil means DefaultRunner
+	Runner GitRunner
+	// Sign makes Commit sign its commit with --gpg-sign
+	Sign bool
+	// NoVerify makes Commit skip the pre-commit and commit-msg hooks
+	NoVerify bool
+	// CoAuthors are credited with a Co-authored-by trailer in the message
+	// of each commit
+	CoAuthors []string
+	// Author, as "Name <email>", makes Commit commit as someone other than
+	// the configured user, who remains the committer
+	Author string
 }
 
 // NewCommitManager creates a new CommitManager
@@ -18,41 +31,71 @@ func NewCommitManager(repoPath string) *CommitManager {
 
 // HasChanges checks if there are uncommitted changes
 func (cm *CommitManager) HasChanges() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = cm.repoPath
-	output, err := cmd.CombinedOutput()
-	return err == nil && strings.TrimSpace(string(output)) != ""
+	result, err := cm.run("status", "--porcelain")
+	return err == nil && strings.TrimSpace(string(result.Stdout)) != ""
 }
 
 // StageAll stages all changes
 func (cm *CommitManager) StageAll() error {
-	cmd := exec.Command("git", "add", "-A")
-	cmd.Dir = cm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := cm.run("add", "-A")
 	if err != nil {
-		return fmt.Errorf("failed to stage changes: %w, output: %s", err, string(output))
+		return fmt.Errorf("failed to stage changes: %w, output: %s", err, string(result.Combined))
 	}
 	return nil
 }
 
 // Commit creates a commit with the given message
 func (cm *CommitManager) Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	cmd.Dir = cm.repoPath
-	output, err := cmd.CombinedOutput()
+	if cm.Sign {
+		if err := CheckSigningKey(cm.repoPath); err != nil {
+			return err
+		}
+	}
+
+	if len(cm.CoAuthors) > 0 {
+		message = AddTrailers(message, CoAuthorTrailer, cm.CoAuthors)
+	}
+	args := append([]string{"commit", "-m", message}, signArgs(cm.Sign)...)
+	if cm.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if cm.Author != "" {
+		args = append(args, "--author", cm.Author)
+	}
+	result, err := cm.run(args...)
 	if err != nil {
+		output := result.Combined
+		if isSigningFailure(string(output)) {
+			return fmt.Errorf("%w: %s", errors.ErrSigning, strings.TrimSpace(string(output)))
+		}
+		if !cm.NoVerify && HasCommitHooks(cm.repoPath) {
+			return fmt.Errorf("%w, output:\n%s", errors.ErrHookFailed, indentOutput(string(output)))
+		}
 		return fmt.Errorf("failed to commit: %w, output: %s", err, string(output))
 	}
 	return nil
 }
 
+// ResetSoft moves the checked out branch to rev, leaving the changes of the
+// commits it drops staged
+func (cm *CommitManager) ResetSoft(rev string) error {
+	result, err := cm.run("reset", "--soft", rev)
+	if err != nil {
+		return fmt.Errorf("failed to reset to %s: %w, output: %s", rev, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
 // GetLastCommitHash returns the hash of the last commit
 func (cm *CommitManager) GetLastCommitHash() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = cm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := cm.run("rev-parse", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get last commit: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// run runs git with args in the repository
+func (cm *CommitManager) run(args ...string) (Result, error) {
+	return runWith(cm.Runner, cm.repoPath, args...)
 }
@@ -0,0 +1,38 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetConfig returns the value of a git config key, or "" if it is unset
+func GetConfig(dir, key string) (string, error) {
+	result, err := run(dir, "config", "--get", key)
+	if err != nil {
+		if ExitCode(err) == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// SetConfig sets a git config key in the repository's local config
+func SetConfig(dir, key, value string) error {
+	if result, err := run(dir, "config", key, value); err != nil {
+		return fmt.Errorf("failed to set %s: %w, output: %s", key, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// SetWorktreeConfig sets a git config key for the worktree at dir alone,
+// enabling per-worktree config in the repository if it is not yet
+func SetWorktreeConfig(dir, key, value string) error {
+	if result, err := run(dir, "config", "extensions.worktreeConfig", "true"); err != nil {
+		return fmt.Errorf("failed to enable per-worktree config: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	if result, err := run(dir, "config", "--worktree", key, value); err != nil {
+		return fmt.Errorf("failed to set %s: %w, output: %s", key, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
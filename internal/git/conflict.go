@@ -0,0 +1,62 @@
+package git
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// maxConflictDiffLines caps the conflicting hunks a ConflictError keeps
+const maxConflictDiffLines = 200
+
+// ConflictError is a rebase or cherry-pick that stopped on a conflict and
+// was aborted, with what conflicted, captured before the abort
+type ConflictError struct {
+	// Err is ErrRebaseConflict or ErrCherryPickConflict
+	Err error
+	// Commit is the commit that failed to apply: its short hash and subject
+	Commit string
+	// Files are the files that conflicted
+	Files []string
+	// Diff holds the conflicting hunks with their conflict markers, cut
+	// short after maxConflictDiffLines lines
+	Diff string
+}
+
+func (e *ConflictError) Error() string {
+	msg := e.Err.Error()
+	if len(e.Files) > 0 {
+		msg += " in " + strings.Join(e.Files, ", ")
+	}
+	return msg + ", auto-aborted"
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Conflict returns what conflicted in the rebase or cherry-pick err
+// reports, or nil if err is not a conflict
+func Conflict(err error) *ConflictError {
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	return nil
+}
+
+// conflictError captures the conflict the rebase or cherry-pick in progress
+// stopped on, while it can still be seen; head is the ref of the commit
+// being applied, e.g. REBASE_HEAD. Whatever can't be read is left out.
+func (rm *RebaseManager) conflictError(sentinel error, head string) *ConflictError {
+	conflict := &ConflictError{Err: sentinel}
+	if result, err := rm.run("log", "-1", "--format=%h %s", head, "--"); err == nil {
+		conflict.Commit = strings.TrimSpace(string(result.Stdout))
+	}
+	conflict.Files = rm.unmergedFiles()
+	if result, err := rm.run("diff", "--no-color", "--diff-filter=U"); err == nil {
+		conflict.Diff = utils.FirstLines(string(result.Stdout), maxConflictDiffLines)
+	}
+	return conflict
+}
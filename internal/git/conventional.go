@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CommitStyleConventional is the config value that enables conventional commits
+const CommitStyleConventional = "conventional"
+
+// MaxConventionalHeaderLength is the maximum length of a conventional commit header
+const MaxConventionalHeaderLength = 72
+
+// ConventionalTypes lists the commit types accepted by conventional commits
+var ConventionalTypes = []string{
+	"feat", "fix", "docs", "style", "refactor", "perf",
+	"test", "build", "ci", "chore", "revert",
+}
+
+var conventionalScopeRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+
+// ConventionalCommit represents a commit message following the conventional commits spec
+type ConventionalCommit struct {
+	Type     string
+	Scope    string
+	Subject  string
+	Body     string
+	Breaking bool
+}
+
+// IsConventionalType checks if the given type is an accepted conventional commit type
+func IsConventionalType(commitType string) bool {
+	for _, t := range ConventionalTypes {
+		if t == commitType {
+			return true
+		}
+	}
+	return false
+}
+
+// Header returns the first line of the commit message, e.g. "feat(api): add endpoint"
+func (c ConventionalCommit) Header() string {
+	var b strings.Builder
+	b.WriteString(c.Type)
+	if c.Scope != "" {
+		b.WriteString("(" + c.Scope + ")")
+	}
+	if c.Breaking {
+		b.WriteString("!")
+	}
+	b.WriteString(": ")
+	b.WriteString(c.Subject)
+	return b.String()
+}
+
+// Validate checks that the commit conforms to conventional commits
+func (c ConventionalCommit) Validate() error {
+	if !IsConventionalType(c.Type) {
+		return fmt.Errorf("invalid commit type %q (expected one of: %s)", c.Type, strings.Join(ConventionalTypes, ", "))
+	}
+	if c.Scope != "" && !conventionalScopeRegex.MatchString(c.Scope) {
+		return fmt.Errorf("invalid scope %q (use lowercase letters, digits, '.', '_', '/' or '-')", c.Scope)
+	}
+	if strings.TrimSpace(c.Subject) == "" {
+		return fmt.Errorf("subject cannot be empty")
+	}
+	if strings.HasSuffix(c.Subject, ".") {
+		return fmt.Errorf("subject should not end with a period")
+	}
+	if strings.Contains(c.Subject, "\n") {
+		return fmt.Errorf("subject must be a single line")
+	}
+	if header := c.Header(); len(header) > MaxConventionalHeaderLength {
+		return fmt.Errorf("header is %d characters long (max %d)", len(header), MaxConventionalHeaderLength)
+	}
+	return nil
+}
+
+// String returns the full commit message
+func (c ConventionalCommit) String() string {
+	msg := c.Header()
+	if body := strings.TrimSpace(c.Body); body != "" {
+		msg += "\n\n" + body
+	}
+	return msg
+}
+
+var conventionalHeaderRegex = regexp.MustCompile(`^([a-z]+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// ParseConventionalCommit parses a commit message into its conventional parts
+func ParseConventionalCommit(message string) (ConventionalCommit, error) {
+	header, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	matches := conventionalHeaderRegex.FindStringSubmatch(strings.TrimSpace(header))
+	if matches == nil {
+		return ConventionalCommit{}, fmt.Errorf("message %q does not match \"type(scope): subject\"", header)
+	}
+
+	commit := ConventionalCommit{
+		Type:     matches[1],
+		Scope:    matches[2],
+		Breaking: matches[3] == "!",
+		Subject:  matches[4],
+		Body:     strings.TrimSpace(body),
+	}
+	if err := commit.Validate(); err != nil {
+		return ConventionalCommit{}, err
+	}
+	return commit, nil
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConventionalCommitString(t *testing.T) {
+	tests := []struct {
+		name     string
+		commit   ConventionalCommit
+		expected string
+	}{
+		{
+			name:     "type and subject",
+			commit:   ConventionalCommit{Type: "fix", Subject: "handle empty input"},
+			expected: "fix: handle empty input",
+		},
+		{
+			name:     "with scope",
+			commit:   ConventionalCommit{Type: "feat", Scope: "api", Subject: "add endpoint"},
+			expected: "feat(api): add endpoint",
+		},
+		{
+			name:     "breaking change",
+			commit:   ConventionalCommit{Type: "feat", Scope: "api", Subject: "drop v1", Breaking: true},
+			expected: "feat(api)!: drop v1",
+		},
+		{
+			name:     "with body",
+			commit:   ConventionalCommit{Type: "docs", Subject: "update readme", Body: "  Explain install steps.  "},
+			expected: "docs: update readme\n\nExplain install steps.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.commit.String(); got != tt.expected {
+				t.Errorf("String() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConventionalCommitValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  ConventionalCommit
+		wantErr bool
+	}{
+		{"valid", ConventionalCommit{Type: "feat", Scope: "api", Subject: "add endpoint"}, false},
+		{"valid without scope", ConventionalCommit{Type: "chore", Subject: "bump deps"}, false},
+		{"unknown type", ConventionalCommit{Type: "feature", Subject: "add endpoint"}, true},
+		{"uppercase scope", ConventionalCommit{Type: "feat", Scope: "API", Subject: "add endpoint"}, true},
+		{"empty subject", ConventionalCommit{Type: "fix", Subject: "  "}, true},
+		{"trailing period", ConventionalCommit{Type: "fix", Subject: "fix bug."}, true},
+		{"header too long", ConventionalCommit{Type: "fix", Subject: strings.Repeat("a", 80)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.commit.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseConventionalCommit(t *testing.T) {
+	commit, err := ParseConventionalCommit("feat(api)!: drop v1 endpoints\n\nClients must migrate to v2.")
+	if err != nil {
+		t.Fatalf("ParseConventionalCommit() failed: %v", err)
+	}
+	if commit.Type != "feat" || commit.Scope != "api" || !commit.Breaking {
+		t.Errorf("ParseConventionalCommit() = %+v, unexpected header fields", commit)
+	}
+	if commit.Subject != "drop v1 endpoints" {
+		t.Errorf("Subject = %q, expected %q", commit.Subject, "drop v1 endpoints")
+	}
+	if commit.Body != "Clients must migrate to v2." {
+		t.Errorf("Body = %q, expected %q", commit.Body, "Clients must migrate to v2.")
+	}
+
+	for _, invalid := range []string{"add endpoint", "feature: add endpoint", "fix:missing space"} {
+		if _, err := ParseConventionalCommit(invalid); err == nil {
+			t.Errorf("ParseConventionalCommit(%q) should fail", invalid)
+		}
+	}
+}
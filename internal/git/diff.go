@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffFileStat holds line change counts for a single file
+type DiffFileStat struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	Binary     bool
+}
+
+// DiffStat summarises the changes between two revisions
+type DiffStat struct {
+	Files      []DiffFileStat
+	Insertions int
+	Deletions  int
+}
+
+// diffArgs returns the revision arguments comparing a worktree with base.
+// Without workingTree, committed changes since the merge base are compared
+// (base...HEAD); with it, the working tree is compared to the merge base.
+func diffArgs(worktreePath, base string, workingTree bool) ([]string, error) {
+	if !workingTree {
+		return []string{base + "...HEAD"}, nil
+	}
+
+	result, err := run(worktreePath, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base with %s: %w, output: %s", base, err, string(result.Combined))
+	}
+	return []string{strings.TrimSpace(string(result.Stdout))}, nil
+}
+
+// GetDiffStat returns per-file change counts of a worktree relative to base
+func GetDiffStat(worktreePath, base string, workingTree bool) (DiffStat, error) {
+	revs, err := diffArgs(worktreePath, base, workingTree)
+	if err != nil {
+		return DiffStat{}, err
+	}
+
+	args := append([]string{"diff", "--numstat"}, revs...)
+	result, err := run(worktreePath, args...)
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("failed to get diff: %w", err)
+	}
+	return ParseNumstat(string(result.Stdout)), nil
+}
+
+// ParseNumstat parses git diff --numstat output
+func ParseNumstat(output string) DiffStat {
+	var stat DiffStat
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		file := DiffFileStat{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			file.Binary = true
+		} else {
+			file.Insertions, _ = strconv.Atoi(fields[0])
+			file.Deletions, _ = strconv.Atoi(fields[1])
+		}
+
+		stat.Files = append(stat.Files, file)
+		stat.Insertions += file.Insertions
+		stat.Deletions += file.Deletions
+	}
+	return stat
+}
+
+// WriteDiffPatch writes the full patch of a worktree relative to base to w
+func WriteDiffPatch(worktreePath, base string, workingTree bool, w io.Writer) error {
+	revs, err := diffArgs(worktreePath, base, workingTree)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{"diff"}, revs...)
+	result, err := DefaultRunner.Run(context.Background(), Command{Dir: worktreePath, Args: args, Stdout: w})
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w, output: %s", err, string(result.Stderr))
+	}
+	return nil
+}
+
+// FileDiff is the patch of a single file
+type FileDiff struct {
+	Path  string
+	Patch string
+	// Insertions and Deletions count the added and removed lines
+	Insertions int
+	Deletions  int
+}
+
+// GetFileDiffs returns the patch of a worktree relative to base, file by
+// file, in the order git lists them
+func GetFileDiffs(worktreePath, base string, workingTree bool) ([]FileDiff, error) {
+	revs, err := diffArgs(worktreePath, base, workingTree)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"diff", "--no-color", "--no-ext-diff"}, revs...)
+	result, err := run(worktreePath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+	return SplitPatch(string(result.Stdout)), nil
+}
+
+// SplitPatch splits the output of git diff into the patches of each file
+func SplitPatch(patch string) []FileDiff {
+	var files []FileDiff
+	var current *FileDiff
+	var lines []string
+	flush := func() {
+		if current != nil {
+			current.Patch = strings.Join(lines, "\n")
+			files = append(files, *current)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(patch, "\n"), "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current, lines = &FileDiff{Path: patchHeaderPath(line)}, nil
+		}
+		if current == nil {
+			continue
+		}
+		lines = append(lines, line)
+
+		switch {
+		case strings.HasPrefix(line, "+++ b/"):
+			current.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "--- a/") && current.Path == "":
+			current.Path = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++ "):
+			current.Insertions++
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "--- "):
+			current.Deletions++
+		}
+	}
+	flush()
+	return files
+}
+
+// patchHeaderPath returns the path of a "diff --git a/<path> b/<path>"
+// line, which is ambiguous when the path contains " b/"; the ---/+++ lines
+// that follow settle it for files with content changes
+func patchHeaderPath(line string) string {
+	header := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+len(" b/"):]
+	}
+	return header
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tcmd/diff.go\n0\t5\tREADME.md\n-\t-\tlogo.png\n"
+
+	stat := ParseNumstat(output)
+	if len(stat.Files) != 3 {
+		t.Fatalf("ParseNumstat() returned %d files, expected 3", len(stat.Files))
+	}
+	if stat.Insertions != 10 || stat.Deletions != 7 {
+		t.Errorf("ParseNumstat() totals = +%d -%d, expected +10 -7", stat.Insertions, stat.Deletions)
+	}
+	if stat.Files[0].Path != "cmd/diff.go" || stat.Files[0].Insertions != 10 || stat.Files[0].Deletions != 2 {
+		t.Errorf("Files[0] = %+v, unexpected", stat.Files[0])
+	}
+	if !stat.Files[2].Binary {
+		t.Error("Files[2] should be marked binary")
+	}
+
+	if empty := ParseNumstat(""); len(empty.Files) != 0 {
+		t.Errorf("ParseNumstat(\"\") returned %d files, expected 0", len(empty.Files))
+	}
+}
+
+func TestSplitPatch(t *testing.T) {
+	patch := `diff --git a/cmd/diff.go b/cmd/diff.go
+index 1111111..2222222 100644
+--- a/cmd/diff.go
++++ b/cmd/diff.go
+@@ -1,3 +1,3 @@
+ package cmd
+-var a = 1
++var a = 2
++var b = 3
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+	files := SplitPatch(patch)
+	if len(files) != 2 {
+		t.Fatalf("SplitPatch() returned %d files, expected 2", len(files))
+	}
+	if files[0].Path != "cmd/diff.go" || files[0].Insertions != 2 || files[0].Deletions != 1 {
+		t.Errorf("files[0] = %s +%d -%d, expected cmd/diff.go +2 -1", files[0].Path, files[0].Insertions, files[0].Deletions)
+	}
+	if files[1].Path != "old.txt" || files[1].Insertions != 0 || files[1].Deletions != 1 {
+		t.Errorf("files[1] = %s +%d -%d, expected old.txt +0 -1", files[1].Path, files[1].Insertions, files[1].Deletions)
+	}
+	if !strings.HasPrefix(files[1].Patch, "diff --git a/old.txt") || !strings.HasSuffix(files[1].Patch, "-gone") {
+		t.Errorf("files[1].Patch = %q, expected the whole patch of old.txt", files[1].Patch)
+	}
+
+	if empty := SplitPatch(""); len(empty) != 0 {
+		t.Errorf("SplitPatch(\"\") returned %d files, expected 0", len(empty))
+	}
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"os"
+	"strings"
+)
+
+// commitHooks are the hooks git commit runs that can reject a commit
+var commitHooks = []string{"pre-commit", "prepare-commit-msg", "commit-msg"}
+
+// HasCommitHooks reports whether git commit in dir runs hooks that can
+// reject the commit, looking where core.hooksPath points if it is set
+func HasCommitHooks(dir string) bool {
+	for _, hook := range commitHooks {
+		result, err := run(dir, "rev-parse", "--path-format=absolute", "--git-path", "hooks/"+hook)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(strings.TrimSpace(string(result.Stdout))); err == nil && info.Mode()&0111 != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// indentOutput indents each line of command output for display under an
+// error message
+func indentOutput(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,53 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func TestCommitHookFailure(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+
+	if HasCommitHooks(repo) {
+		t.Fatal("HasCommitHooks() should be false in a new repository")
+	}
+
+	// Hooks are found through core.hooksPath
+	hooks := filepath.Join(repo, "hooks")
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		t.Fatalf("Failed to create hooks dir: %v", err)
+	}
+	hook := "#!/bin/sh\necho 'lint: trailing whitespace in a.txt'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(hooks, "pre-commit"), []byte(hook), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	gitIn(t, repo, "config", "core.hooksPath", hooks)
+	if !HasCommitHooks(repo) {
+		t.Fatal("HasCommitHooks() should find the pre-commit hook in core.hooksPath")
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a \n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	cm := NewCommitManager(repo)
+	if err := cm.StageAll(); err != nil {
+		t.Fatalf("StageAll() failed: %v", err)
+	}
+
+	err := cm.Commit("add a")
+	if !errors.IsHookFailed(err) || !strings.Contains(err.Error(), "trailing whitespace") {
+		t.Errorf("Commit() = %v, expected a hook failure with the hook's output", err)
+	}
+
+	cm.NoVerify = true
+	if err := cm.Commit("add a"); err != nil {
+		t.Errorf("Commit() with NoVerify failed: %v", err)
+	}
+}
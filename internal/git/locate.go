@@ -0,0 +1,162 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitDirs are the directories git keeps a worktree's state in, found by
+// reading git's files rather than running git, for callers such as shell
+// prompts that can't afford to run it
+type GitDirs struct {
+	// Path is the top level of the worktree
+	Path string
+	// GitDir is the worktree's own git directory, e.g.
+	// <repo>/.git/worktrees/<name>, and CommonDir the repository's. They
+	// are the same in a main worktree.
+	GitDir    string
+	CommonDir string
+}
+
+// FindGitDirs returns the git directories of the worktree containing dir.
+// ok is false outside any repository.
+func FindGitDirs(dir string) (GitDirs, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		info, err := os.Stat(filepath.Join(dir, ".git"))
+		if err == nil {
+			if info.IsDir() {
+				gitDir := filepath.Join(dir, ".git")
+				return GitDirs{Path: dir, GitDir: gitDir, CommonDir: gitDir}, true
+			}
+			return readGitFile(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return GitDirs{}, false
+		}
+		dir = parent
+	}
+}
+
+// FindLinkedWorktree returns the git directories of the worktree added
+// with 'git worktree add' that contains dir. ok is false in a main
+// worktree, a submodule or outside any repository.
+func FindLinkedWorktree(dir string) (GitDirs, bool) {
+	dirs, ok := FindGitDirs(dir)
+	if !ok || !dirs.Linked() {
+		return GitDirs{}, false
+	}
+	return dirs, true
+}
+
+// Linked reports whether the worktree was added with 'git worktree add'
+func (d GitDirs) Linked() bool {
+	return d.GitDir != d.CommonDir
+}
+
+// readGitFile reads the .git file of the worktree at top, which points at
+// its git directory, and that directory's commondir file
+func readGitFile(top string) (GitDirs, bool) {
+	data, err := os.ReadFile(filepath.Join(top, ".git"))
+	if err != nil {
+		return GitDirs{}, false
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return GitDirs{}, false
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(top, gitDir)
+	}
+	gitDir = filepath.Clean(gitDir)
+
+	// Submodules have a git directory of their own, without commondir
+	data, err = os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return GitDirs{Path: top, GitDir: gitDir, CommonDir: gitDir}, true
+	}
+	commonDir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return GitDirs{Path: top, GitDir: gitDir, CommonDir: filepath.Clean(commonDir)}, true
+}
+
+// Head returns the branch checked out in the worktree, or the commit when
+// its HEAD is detached
+func (d GitDirs) Head() (branch, commit string, err error) {
+	data, err := os.ReadFile(filepath.Join(d.GitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/"), "", nil
+	}
+	return "", head, nil
+}
+
+// Commit returns the commit checked out in the worktree, or "" if it
+// can't be read
+func (d GitDirs) Commit() string {
+	branch, commit, err := d.Head()
+	if err != nil || commit != "" {
+		return commit
+	}
+	return d.readRef("refs/heads/" + branch)
+}
+
+// ResolveRef returns the object name refers to: HEAD, a full object name,
+// or a local branch, remote-tracking branch or tag, read from loose or
+// packed refs. Returns "" for anything else, such as HEAD~1, which only
+// git can resolve.
+func (d GitDirs) ResolveRef(name string) string {
+	if name == "HEAD" {
+		return d.Commit()
+	}
+	if isObjectName(name) {
+		return name
+	}
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/tags/"} {
+		if hash := d.readRef(prefix + name); hash != "" {
+			return hash
+		}
+	}
+	return ""
+}
+
+// readRef reads ref, such as refs/heads/main, from its loose file or else
+// from packed-refs
+func (d GitDirs) readRef(ref string) string {
+	if data, err := os.ReadFile(filepath.Join(d.CommonDir, filepath.FromSlash(ref))); err == nil {
+		if hash := strings.TrimSpace(string(data)); isObjectName(hash) {
+			return hash
+		}
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(d.CommonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if hash, name, ok := strings.Cut(line, " "); ok && name == ref {
+			return hash
+		}
+	}
+	return ""
+}
+
+// isObjectName reports whether s is a full SHA-1 or SHA-256 object name
+func isObjectName(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLinkedWorktree(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "initial")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gitIn(t, repo, "worktree", "add", "-b", "feature/one", worktree)
+
+	if _, ok := FindLinkedWorktree(repo); ok {
+		t.Error("FindLinkedWorktree() found a linked worktree in the main one")
+	}
+	if _, ok := FindLinkedWorktree(t.TempDir()); ok {
+		t.Error("FindLinkedWorktree() found a linked worktree outside a repository")
+	}
+
+	sub := filepath.Join(worktree, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wt, ok := FindLinkedWorktree(sub)
+	if !ok {
+		t.Fatal("FindLinkedWorktree() found no worktree in a subdirectory of one")
+	}
+	if wt.Path != worktree {
+		t.Errorf("Path = %q, expected %q", wt.Path, worktree)
+	}
+	if resolved, _ := filepath.EvalSymlinks(filepath.Dir(wt.CommonDir)); resolved != mustEvalSymlinks(t, repo) {
+		t.Errorf("CommonDir = %q, expected the .git of %q", wt.CommonDir, repo)
+	}
+
+	branch, commit, err := wt.Head()
+	if err != nil || branch != "feature/one" || commit != "" {
+		t.Errorf("Head() = %q, %q, %v, expected feature/one", branch, commit, err)
+	}
+
+	gitIn(t, worktree, "checkout", "-q", "--detach")
+	if branch, commit, _ := wt.Head(); branch != "" || len(commit) != 40 {
+		t.Errorf("Head() of a detached worktree = %q, %q, expected a commit", branch, commit)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
+
+func TestLinkedWorktreeCommit(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "initial")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gitIn(t, repo, "worktree", "add", "-b", "feature/one", worktree)
+
+	wt, ok := FindLinkedWorktree(worktree)
+	if !ok {
+		t.Fatal("FindLinkedWorktree() found no worktree")
+	}
+	expected, err := ResolveRef(repo, "feature/one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := wt.Commit(); got != expected {
+		t.Errorf("Commit() from a loose ref = %q, expected %q", got, expected)
+	}
+
+	gitIn(t, repo, "pack-refs", "--all")
+	if got := wt.Commit(); got != expected {
+		t.Errorf("Commit() from packed refs = %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logFormat separates commit fields with the ASCII unit separator
+const logFormat = "--format=%H%x1f%an%x1f%at%x1f%s"
+
+// GetCommits returns the commits in revRange (e.g. "main..feature"), newest first.
+// If since is non-zero, only commits after that time are returned.
+func GetCommits(dir, revRange string, since time.Time) ([]Commit, error) {
+	args := []string{"log", logFormat}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	args = append(args, revRange, "--")
+
+	result, err := run(dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+	return ParseCommits(string(result.Stdout)), nil
+}
+
+// CommitAuthors returns the authors of the commits in revRange as
+// "Name <email>", each once, oldest first
+func CommitAuthors(dir, revRange string) ([]string, error) {
+	result, err := run(dir, "log", "--reverse", "--format=%an <%ae>", revRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit authors: %w", err)
+	}
+
+	var authors []string
+	seen := make(map[string]bool)
+	for _, author := range strings.Split(strings.TrimSpace(string(result.Stdout)), "\n") {
+		if author != "" && !seen[author] {
+			seen[author] = true
+			authors = append(authors, author)
+		}
+	}
+	return authors, nil
+}
+
+// ParseCommits parses git log output produced with logFormat
+func ParseCommits(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		commit := Commit{Hash: fields[0], Author: fields[1], Subject: fields[3]}
+		if unix, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			commit.Date = time.Unix(unix, 0)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// GetLastCommitTime returns the committer date of the tip of ref
+func GetLastCommitTime(dir, ref string) (time.Time, error) {
+	result, err := run(dir, "log", "-1", "--format=%ct", ref, "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last commit time: %w", err)
+	}
+	unix, err := strconv.ParseInt(strings.TrimSpace(string(result.Stdout)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit time: %w", err)
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// HasMergeConflicts reports whether merging branch into base would conflict,
+// computed in-memory with git merge-tree without touching any worktree.
+// Requires git 2.38 or later.
+func HasMergeConflicts(dir, base, branch string) (bool, error) {
+	result, err := run(dir, "merge-tree", "--write-tree", "--name-only", base, branch)
+	if err == nil {
+		return false, nil
+	}
+	if ExitCode(err) == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to check for conflicts: %w, output: %s", err, string(result.Combined))
+}
+
+// ResolveRef returns the commit hash ref points to
+func ResolveRef(dir, ref string) (string, error) {
+	result, err := run(dir, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// MergeBase returns the best common ancestor of two refs
+func MergeBase(dir, a, b string) (string, error) {
+	result, err := run(dir, "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// IsAncestor reports whether ancestor is in the history of descendant
+func IsAncestor(dir, ancestor, descendant string) bool {
+	_, err := run(dir, "merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+// CountCommits returns the number of commits in revRange (e.g. "main..feature")
+func CountCommits(dir, revRange string) (int, error) {
+	result, err := run(dir, "rev-list", "--count", revRange, "--")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(result.Stdout)))
+}
+
+// ResolveCommits returns the commits selected by rev, oldest first. rev is
+// either a range such as "main..feature" or "HEAD~3.." or a single commit,
+// which selects only that commit.
+func ResolveCommits(dir, rev string) ([]string, error) {
+	if !strings.Contains(rev, "..") {
+		hash, err := ResolveRef(dir, rev)
+		if err != nil {
+			return nil, err
+		}
+		return []string{hash}, nil
+	}
+
+	result, err := run(dir, "rev-list", "--reverse", rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return strings.Fields(string(result.Stdout)), nil
+}
+
+// GetTopoCommits returns the commits reachable from tips but not from base,
+// children before their parents as git log --graph draws them
+func GetTopoCommits(dir, base string, tips []string) ([]Commit, error) {
+	args := append([]string{"log", "--topo-order", logFormat}, tips...)
+	args = append(args, "^"+base, "--")
+
+	result, err := run(dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+	return ParseCommits(string(result.Stdout)), nil
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/redact"
+)
+
+// Upstream is the remote branch a local branch tracks
+type Upstream struct {
+	Remote string
+	Branch string
+}
+
+func (u Upstream) String() string {
+	return u.Remote + "/" + u.Branch
+}
+
+// GetUpstream returns the upstream of branch, or ErrNoUpstream if none is configured
+func GetUpstream(dir, branch string) (Upstream, error) {
+	remote, err := GetConfig(dir, "branch."+branch+".remote")
+	if err != nil || remote == "" || remote == "." {
+		return Upstream{}, fmt.Errorf("%w for %s", errors.ErrNoUpstream, branch)
+	}
+	merge, err := GetConfig(dir, "branch."+branch+".merge")
+	if err != nil || merge == "" {
+		return Upstream{}, fmt.Errorf("%w for %s", errors.ErrNoUpstream, branch)
+	}
+	return Upstream{Remote: remote, Branch: strings.TrimPrefix(merge, "refs/heads/")}, nil
+}
+
+// PushWithLease force-pushes branch to its upstream with --force-with-lease,
+// so the push is refused if the remote moved since it was last fetched.
+// git's progress is streamed to progress unless it is nil.
+func PushWithLease(dir, branch string, upstream Upstream, progress io.Writer) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:refs/remotes/%s", upstream.Branch, upstream)
+	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, upstream.Branch)
+
+	args := []string{"push", lease, upstream.Remote, refspec}
+	if progress != nil {
+		args = append(args, "--progress")
+	}
+	result, err := runStreaming(dir, progress, args...)
+	if err != nil {
+		// Remote errors can echo URLs with embedded credentials
+		return fmt.Errorf("failed to push %s to %s: %w, output: %s", branch, upstream, err, redact.String(errorOutput(result)))
+	}
+	return nil
+}
+
+// DeleteRemoteBranch deletes upstream's branch on its remote
+func DeleteRemoteBranch(dir string, upstream Upstream) error {
+	result, err := run(dir, "push", upstream.Remote, "--delete", upstream.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w, output: %s", upstream, err, redact.String(strings.TrimSpace(string(result.Combined))))
+	}
+	return nil
+}
+
+// Fetch updates the remote-tracking branches of remote, pruning those
+// deleted there. git's progress is streamed to progress unless it is nil.
+func Fetch(dir, remote string, progress io.Writer) error {
+	args := []string{"fetch", "--prune", remote}
+	if progress != nil {
+		args = append(args, "--progress")
+	}
+	result, err := runStreaming(dir, progress, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w, output: %s", remote, err, redact.String(errorOutput(result)))
+	}
+	return nil
+}
+
+// RemoteOnlyBranches returns the branches of remote that have no local
+// branch of the same name, as last fetched
+func RemoteOnlyBranches(dir, remote string) ([]string, error) {
+	result, err := run(dir, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes/"+remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches of %s: %w, output: %s", remote, err, strings.TrimSpace(string(result.Combined)))
+	}
+
+	local := make(map[string]bool)
+	var remoteBranches []string
+	for _, ref := range strings.Fields(string(result.Stdout)) {
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			local[name] = true
+		} else if name, ok := strings.CutPrefix(ref, "refs/remotes/"+remote+"/"); ok && name != "HEAD" {
+			remoteBranches = append(remoteBranches, name)
+		}
+	}
+
+	var branches []string
+	for _, name := range remoteBranches {
+		if !local[name] {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
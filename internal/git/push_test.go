@@ -0,0 +1,148 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git %v failed: %v, output: %s", args, err, output)
+	}
+}
+
+func TestPushWithLease(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+
+	gitIn(t, root, "init", "--bare", "-b", "main", remote)
+	gitIn(t, root, "clone", remote, repo)
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("v1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	gitIn(t, repo, "add", ".")
+	gitIn(t, repo, "commit", "-m", "initial")
+	gitIn(t, repo, "push", "-u", "origin", "main")
+
+	gitIn(t, repo, "branch", "local-only")
+	if _, err := GetUpstream(repo, "local-only"); !errors.IsNoUpstream(err) {
+		t.Errorf("GetUpstream(local-only) error = %v, expected ErrNoUpstream", err)
+	}
+
+	upstream, err := GetUpstream(repo, "main")
+	if err != nil {
+		t.Fatalf("GetUpstream(main) failed: %v", err)
+	}
+	if upstream.String() != "origin/main" {
+		t.Errorf("GetUpstream(main) = %s, expected origin/main", upstream)
+	}
+
+	// Rewrite history so only a forced push succeeds
+	gitIn(t, repo, "commit", "--amend", "-m", "rewritten")
+	if err := PushWithLease(repo, "main", upstream, nil); err != nil {
+		t.Fatalf("PushWithLease() failed: %v", err)
+	}
+
+	local, _ := ResolveRef(repo, "main")
+	pushed, _ := ResolveRef(remote, "main")
+	if local != pushed {
+		t.Errorf("remote main is %s, expected %s", pushed, local)
+	}
+}
+
+func TestDeleteRemoteBranch(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+
+	gitIn(t, root, "init", "--bare", "-b", "main", remote)
+	gitIn(t, root, "clone", remote, repo)
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	gitIn(t, repo, "commit", "--allow-empty", "-m", "initial")
+	gitIn(t, repo, "push", "-u", "origin", "main")
+	gitIn(t, repo, "push", "-u", "origin", "main:feature")
+
+	upstream := Upstream{Remote: "origin", Branch: "feature"}
+	if err := DeleteRemoteBranch(repo, upstream); err != nil {
+		t.Fatalf("DeleteRemoteBranch() failed: %v", err)
+	}
+	if _, err := ResolveRef(remote, "feature"); err == nil {
+		t.Error("feature still exists on the remote")
+	}
+
+	if err := DeleteRemoteBranch(repo, upstream); err == nil {
+		t.Error("DeleteRemoteBranch() succeeded for a branch that no longer exists")
+	}
+}
+
+func TestListWithRemotes(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+
+	gitIn(t, root, "init", "--bare", "-b", "main", remote)
+	gitIn(t, root, "clone", remote, repo)
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "file.txt", "v1\n")
+	gitIn(t, repo, "push", "-u", "origin", "main")
+	gitIn(t, repo, "push", "origin", "main:develop")
+	gitIn(t, repo, "remote", "set-head", "origin", "main")
+	gitIn(t, repo, "branch", "local-only")
+
+	branches, err := NewBranchManager(repo).ListWithRemotes()
+	if err != nil {
+		t.Fatalf("ListWithRemotes() failed: %v", err)
+	}
+	expected := []string{"local-only", "main", "origin/develop", "origin/main"}
+	if strings.Join(branches, ",") != strings.Join(expected, ",") {
+		t.Errorf("ListWithRemotes() = %v, expected %v", branches, expected)
+	}
+}
+
+func TestRemoteOnlyBranches(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+	teammate := filepath.Join(root, "teammate")
+
+	gitIn(t, root, "init", "--bare", "-b", "main", remote)
+	gitIn(t, root, "clone", remote, repo)
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	gitIn(t, repo, "commit", "--allow-empty", "-m", "initial")
+	gitIn(t, repo, "push", "-u", "origin", "main")
+
+	// A teammate pushes a branch this clone has never checked out
+	gitIn(t, root, "clone", remote, teammate)
+	gitIn(t, teammate, "push", "origin", "main:feature/theirs")
+	gitIn(t, repo, "push", "origin", "main:mine")
+	gitIn(t, repo, "branch", "mine")
+
+	if err := Fetch(repo, "origin", nil); err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	branches, err := RemoteOnlyBranches(repo, "origin")
+	if err != nil {
+		t.Fatalf("RemoteOnlyBranches() failed: %v", err)
+	}
+	if strings.Join(branches, ",") != "feature/theirs" {
+		t.Errorf("RemoteOnlyBranches() = %v, expected [feature/theirs]", branches)
+	}
+
+	if err := Fetch(repo, "nowhere", nil); err == nil {
+		t.Error("Fetch() of a missing remote succeeded, expected an error")
+	}
+}
@@ -1,14 +1,26 @@
 package git
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
 )
 
 // RebaseManager handles git rebase operations
 type RebaseManager struct {
 	repoPath string
+	// Runner runs git; nil means DefaultRunner
+	Runner GitRunner
+	// Sign makes rebases and cherry-picks sign the commits they create
+	// with --gpg-sign, so rewritten commits keep a signature
+	Sign bool
+	// Progress, if set, receives git's output as rebases and cherry-picks
+	// run, e.g. "Rebasing (2/5)"
+	Progress io.Writer
 }
 
 // NewRebaseManager creates a new RebaseManager
@@ -19,33 +31,209 @@ func NewRebaseManager(repoPath string) *RebaseManager {
 // RebaseCommit rebases a specific commit onto the current branch
 // Returns (success, conflictDetected, error)
 func (rm *RebaseManager) RebaseCommit(commitHash string) (bool, bool, error) {
+	return rm.rebase(commitHash)
+}
+
+// RebaseOnto replays the commits after upstream onto newBase, as used for
+// stacked branches whose parent has been rewritten
+// Returns (success, conflictDetected, error)
+func (rm *RebaseManager) RebaseOnto(newBase, upstream string) (bool, bool, error) {
+	return rm.rebase("--onto", newBase, upstream)
+}
+
+// RebaseInteractive runs git rebase -i onto upstream attached to the
+// terminal, so the user edits the todo list of the commits after upstream.
+// A rebase that stops for a conflict or an edit is left in progress for the
+// user to finish; check InProgress afterwards.
+func (rm *RebaseManager) RebaseInteractive(upstream string) error {
+	if err := rm.checkSigning(); err != nil {
+		return err
+	}
+
+	args := append([]string{"rebase", "-i"}, signArgs(rm.Sign)...)
+	_, err := rm.runner().Run(context.Background(), Command{
+		Dir:    rm.repoPath,
+		Args:   append(args, upstream),
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil && !rm.InProgress() {
+		return fmt.Errorf("rebase failed: %w", err)
+	}
+	return nil
+}
+
+// InProgress reports whether a rebase is in progress in the worktree
+func (rm *RebaseManager) InProgress() bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		result, err := rm.run("rev-parse", "--path-format=absolute", "--git-path", name)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(strings.TrimSpace(string(result.Stdout))); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CherryPick applies commits, oldest first, onto the current branch,
+// auto-aborting on conflict. With noCommit the changes are staged instead of
+// committed; the worktree must then be clean beforehand, since an abort
+// resets it.
+// Returns (success, conflictDetected, error)
+func (rm *RebaseManager) CherryPick(commits []string, noCommit bool) (bool, bool, error) {
+	args := []string{"cherry-pick"}
+	if noCommit {
+		args = append(args, "--no-commit")
+	} else {
+		if err := rm.checkSigning(); err != nil {
+			return false, false, err
+		}
+		args = append(args, signArgs(rm.Sign)...)
+	}
+	result, err := rm.runStreaming(append(args, commits...)...)
+
+	if err != nil {
+		outputStr := string(result.Combined)
+		// Leave the branch as it was, whatever went wrong
+		var conflict *ConflictError
+		if !IsInterruption(err) && len(rm.unmergedFiles()) > 0 {
+			conflict = rm.conflictError(errors.ErrCherryPickConflict, "CHERRY_PICK_HEAD")
+		}
+		if noCommit {
+			// A single commit picked without committing leaves no state
+			// for --abort to use
+			_ = rm.resetMerge()
+			_, _ = runCleanup(rm.Runner, rm.repoPath, "cherry-pick", "--quit")
+		} else {
+			_ = rm.abort("cherry-pick")
+		}
+		if IsInterruption(err) {
+			return false, false, fmt.Errorf("%w, auto-aborted", err)
+		}
+		if conflict != nil {
+			return false, true, conflict
+		}
+		if isSigningFailure(outputStr) {
+			return false, false, fmt.Errorf("%w, auto-aborted: %s", errors.ErrSigning, strings.TrimSpace(outputStr))
+		}
+		return false, false, fmt.Errorf("cherry-pick failed: %w, output: %s", err, outputStr)
+	}
+
+	return true, false, nil
+}
+
+// resetMerge discards a failed merge, keeping unrelated changes
+func (rm *RebaseManager) resetMerge() error {
+	result, err := runCleanup(rm.Runner, rm.repoPath, "reset", "--merge")
+	if err != nil {
+		return fmt.Errorf("failed to reset: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// FastForward moves the current branch forward to ref, failing if that
+// would need a merge
+func (rm *RebaseManager) FastForward(ref string) error {
+	result, err := rm.run("merge", "--ff-only", ref)
+	if err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %w, output: %s", ref, err, string(result.Combined))
+	}
+	return nil
+}
+
+// rebase runs git rebase with args, auto-aborting on conflict
+func (rm *RebaseManager) rebase(args ...string) (bool, bool, error) {
+	if err := rm.checkSigning(); err != nil {
+		return false, false, err
+	}
+
 	// Perform rebase
-	rebaseCmd := exec.Command("git", "rebase", commitHash)
-	rebaseCmd.Dir = rm.repoPath
-	output, err := rebaseCmd.CombinedOutput()
+	args = append(append([]string{"rebase"}, signArgs(rm.Sign)...), args...)
+	result, err := rm.runStreaming(args...)
 
 	if err != nil {
-		outputStr := string(output)
-		// Check if it's a conflict error
-		if strings.Contains(outputStr, "conflict") || strings.Contains(outputStr, "CONFLICT") ||
-			strings.Contains(outputStr, "Failed to merge") {
-			// Auto-abort on conflict
+		outputStr := string(result.Combined)
+		if IsInterruption(err) {
+			// Don't leave the rebase stopped halfway
+			_ = rm.AbortRebase()
+			return false, false, fmt.Errorf("%w, auto-aborted", err)
+		}
+		// A rebase that stopped midway is auto-aborted. It stopped on a
+		// conflict if it left files unmerged, as git status reports them.
+		inProgress := rm.InProgress()
+		if inProgress && len(rm.unmergedFiles()) > 0 {
+			// Report what conflicted before the abort clears it
+			conflict := rm.conflictError(errors.ErrRebaseConflict, "REBASE_HEAD")
 			_ = rm.AbortRebase()
-			return false, true, fmt.Errorf("rebase conflict detected, auto-aborted")
+			return false, true, conflict
+		}
+		if inProgress {
+			_ = rm.AbortRebase()
+		}
+		if isSigningFailure(outputStr) {
+			return false, false, fmt.Errorf("%w, auto-aborted: %s", errors.ErrSigning, strings.TrimSpace(outputStr))
+		}
+		if !inProgress {
+			// git refused to start, e.g. over uncommitted changes
+			return false, false, fmt.Errorf("rebase failed: %w, output: %s", err, outputStr)
 		}
-		return false, false, fmt.Errorf("rebase failed: %w, output: %s", err, outputStr)
+		return false, false, fmt.Errorf("rebase failed: %w, auto-aborted, output: %s", err, outputStr)
 	}
 
 	return true, false, nil
 }
 
+// checkSigning makes sure commits can be signed before rewriting any
+func (rm *RebaseManager) checkSigning() error {
+	if !rm.Sign {
+		return nil
+	}
+	return CheckSigningKey(rm.repoPath)
+}
+
+// unmergedFiles returns the files of the worktree with unresolved merge
+// conflicts, as git status classifies them rather than from the messages
+// git printed
+func (rm *RebaseManager) unmergedFiles() []string {
+	result, err := rm.run("status", "--porcelain=v2", "-z", "--untracked-files=no")
+	if err != nil {
+		return nil
+	}
+	return ParseUnmergedZ(string(result.Stdout))
+}
+
 // AbortRebase aborts the current rebase
 func (rm *RebaseManager) AbortRebase() error {
-	cmd := exec.Command("git", "rebase", "--abort")
-	cmd.Dir = rm.repoPath
-	output, err := cmd.CombinedOutput()
+	return rm.abort("rebase")
+}
+
+// abort aborts the git operation (rebase or cherry-pick) in progress
+func (rm *RebaseManager) abort(operation string) error {
+	result, err := runCleanup(rm.Runner, rm.repoPath, operation, "--abort")
 	if err != nil {
-		return fmt.Errorf("failed to abort rebase: %w, output: %s", err, string(output))
+		return fmt.Errorf("failed to abort %s: %w, output: %s", operation, err, string(result.Combined))
 	}
 	return nil
 }
+
+// run runs git with args in the worktree
+func (rm *RebaseManager) run(args ...string) (Result, error) {
+	return runWith(rm.Runner, rm.repoPath, args...)
+}
+
+// runStreaming runs git with args in the repository, streaming its output
+// to Progress
+func (rm *RebaseManager) runStreaming(args ...string) (Result, error) {
+	return rm.runner().Run(context.Background(), Command{Dir: rm.repoPath, Args: args, Progress: rm.Progress})
+}
+
+// runner returns the runner git runs with
+func (rm *RebaseManager) runner() GitRunner {
+	if rm.Runner != nil {
+		return rm.Runner
+	}
+	return DefaultRunner
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func commitIn(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	gitIn(t, dir, "add", name)
+	gitIn(t, dir, "commit", "-m", "change "+name)
+}
+
+func TestCherryPick(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "base.txt", "base\n")
+
+	gitIn(t, repo, "checkout", "-b", "feature")
+	commitIn(t, repo, "a.txt", "a\n")
+	commitIn(t, repo, "b.txt", "b\n")
+	commitIn(t, repo, "c.txt", "c\n")
+	gitIn(t, repo, "checkout", "main")
+
+	commits, err := ResolveCommits(repo, "feature~2..feature")
+	if err != nil {
+		t.Fatalf("ResolveCommits() failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("ResolveCommits(feature~2..feature) returned %d commits, expected 2", len(commits))
+	}
+	if last, _ := ResolveRef(repo, "feature"); commits[1] != last {
+		t.Errorf("ResolveCommits() should list oldest first, got %v", commits)
+	}
+
+	single, err := ResolveCommits(repo, "feature~1")
+	if err != nil || len(single) != 1 || single[0] != commits[0] {
+		t.Errorf("ResolveCommits(feature~1) = %v, %v, expected [%s]", single, err, commits[0])
+	}
+
+	rm := NewRebaseManager(repo)
+	if _, _, err := rm.CherryPick(commits, false); err != nil {
+		t.Fatalf("CherryPick() failed: %v", err)
+	}
+	for name, expected := range map[string]bool{"a.txt": false, "b.txt": true, "c.txt": true} {
+		if _, err := os.Stat(filepath.Join(repo, name)); (err == nil) != expected {
+			t.Errorf("%s present = %v, expected %v", name, err == nil, expected)
+		}
+	}
+
+	// A conflicting commit is aborted, leaving main as it was
+	gitIn(t, repo, "checkout", "-b", "other", "main~2")
+	commitIn(t, repo, "b.txt", "other\n")
+	conflicting, _ := ResolveRef(repo, "HEAD")
+	gitIn(t, repo, "checkout", "main")
+	before, _ := ResolveRef(repo, "main")
+
+	_, hasConflict, err := rm.CherryPick([]string{conflicting}, false)
+	if !hasConflict || !errors.IsCherryPickConflict(err) {
+		t.Errorf("CherryPick() = %v, %v, expected a conflict", hasConflict, err)
+	}
+	if conflict := Conflict(err); conflict == nil || len(conflict.Files) != 1 || conflict.Files[0] != "b.txt" {
+		t.Errorf("Conflict() = %+v, expected b.txt to conflict", conflict)
+	}
+	if after, _ := ResolveRef(repo, "main"); after != before {
+		t.Errorf("main moved to %s after an aborted cherry-pick, expected %s", after, before)
+	}
+	if HasUncommittedChanges(repo) {
+		t.Error("aborted cherry-pick should leave a clean worktree")
+	}
+}
+
+func TestRebaseConflictDetails(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "base.txt", "base\n")
+
+	gitIn(t, repo, "checkout", "-b", "feature")
+	commitIn(t, repo, "shared.txt", "feature\n")
+	gitIn(t, repo, "checkout", "main")
+	commitIn(t, repo, "shared.txt", "main\n")
+	gitIn(t, repo, "checkout", "feature")
+
+	_, hasConflict, err := NewRebaseManager(repo).RebaseCommit("main")
+	if !hasConflict || !errors.IsRebaseConflict(err) {
+		t.Fatalf("RebaseCommit() = %v, %v, expected a conflict", hasConflict, err)
+	}
+	conflict := Conflict(err)
+	if conflict == nil {
+		t.Fatalf("Conflict(%v) = nil, expected the conflict's details", err)
+	}
+	if len(conflict.Files) != 1 || conflict.Files[0] != "shared.txt" {
+		t.Errorf("Files = %v, expected [shared.txt]", conflict.Files)
+	}
+	if !strings.HasSuffix(conflict.Commit, " change shared.txt") {
+		t.Errorf("Commit = %q, expected the feature commit", conflict.Commit)
+	}
+	if !strings.Contains(conflict.Diff, "<<<<<<<") || !strings.Contains(conflict.Diff, "+ feature") {
+		t.Errorf("Diff = %q, expected the conflicting hunk", conflict.Diff)
+	}
+	if !strings.Contains(err.Error(), "in shared.txt, auto-aborted") {
+		t.Errorf("error = %q, expected it to name the file", err)
+	}
+}
+
+func TestCherryPickNoCommit(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "base.txt", "base\n")
+
+	gitIn(t, repo, "checkout", "-b", "feature")
+	commitIn(t, repo, "a.txt", "feature\n")
+	picked, _ := ResolveRef(repo, "HEAD")
+	gitIn(t, repo, "checkout", "main")
+	before, _ := ResolveRef(repo, "main")
+
+	rm := NewRebaseManager(repo)
+	if _, _, err := rm.CherryPick([]string{picked}, true); err != nil {
+		t.Fatalf("CherryPick() failed: %v", err)
+	}
+	if after, _ := ResolveRef(repo, "main"); after != before {
+		t.Error("CherryPick() with noCommit should not commit")
+	}
+	if !HasUncommittedChanges(repo) {
+		t.Error("CherryPick() with noCommit should stage the changes")
+	}
+	gitIn(t, repo, "reset", "--hard")
+
+	// A conflict leaves no cherry-pick state behind, so it is reset instead
+	commitIn(t, repo, "a.txt", "main\n")
+	if _, hasConflict, err := rm.CherryPick([]string{picked}, true); !hasConflict {
+		t.Errorf("CherryPick() = %v, expected a conflict", err)
+	}
+	if HasUncommittedChanges(repo) {
+		t.Error("aborted cherry-pick should leave a clean worktree")
+	}
+}
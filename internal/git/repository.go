@@ -3,43 +3,37 @@ package git
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // GetRepoName returns the repository name from the current directory
 func GetRepoName(dir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	result, err := run(dir, "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", err
 	}
-	repoPath := strings.TrimSpace(string(output))
+	repoPath := strings.TrimSpace(string(result.Stdout))
 	return filepath.Base(repoPath), nil
 }
 
 // GetMainRepoPath returns the path to the main repository (not worktree)
 func GetMainRepoPath(dir string) (string, error) {
 	// First get the common git directory
-	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	result, err := run(dir, "rev-parse", "--git-common-dir")
 	if err != nil {
 		return "", err
 	}
-	gitDir := strings.TrimSpace(string(output))
+	gitDir := strings.TrimSpace(string(result.Stdout))
 
 	// If gitDir is just ".git", we're in the main repo already
 	if gitDir == ".git" {
-		cmd = exec.Command("git", "rev-parse", "--show-toplevel")
-		cmd.Dir = dir
-		output, err = cmd.CombinedOutput()
+		result, err = run(dir, "rev-parse", "--show-toplevel")
 		if err != nil {
 			return "", err
 		}
-		return strings.TrimSpace(string(output)), nil
+		return strings.TrimSpace(string(result.Stdout)), nil
 	}
 
 	// The main repo path is the parent of the .git directory
@@ -49,13 +43,11 @@ func GetMainRepoPath(dir string) (string, error) {
 	// If not, we might be in the main repo already
 	if !strings.HasSuffix(gitDir, ".git") {
 		// We're likely in a bare repository or the main repo
-		cmd = exec.Command("git", "rev-parse", "--show-toplevel")
-		cmd.Dir = dir
-		output, err = cmd.CombinedOutput()
+		result, err = run(dir, "rev-parse", "--show-toplevel")
 		if err != nil {
 			return "", err
 		}
-		mainPath = strings.TrimSpace(string(output))
+		mainPath = strings.TrimSpace(string(result.Stdout))
 	}
 
 	return mainPath, nil
@@ -69,63 +61,239 @@ func IsGitRepository(dir string) bool {
 	}
 
 	// Check if we're in a worktree or subdirectory
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = dir
-	err = cmd.Run()
+	_, err = run(dir, "rev-parse", "--git-dir")
 	return err == nil
 }
 
+// FindEnclosingRepository returns the top level of the git repository that
+// contains path, checking the nearest existing ancestor if path doesn't exist yet.
+// Returns an empty string if path is not inside a repository.
+func FindEnclosingRepository(path string) string {
+	dir := filepath.Clean(path)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+
+	result, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(result.Stdout))
+}
+
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(dir string) (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	result, err := run(dir, "branch", "--show-current")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(string(result.Stdout)), nil
 }
 
 // HasUncommittedChanges checks if a worktree has uncommitted changes
 func HasUncommittedChanges(dir string) bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
-	return err == nil && strings.TrimSpace(string(output)) != ""
+	result, err := run(dir, "status", "--porcelain")
+	return err == nil && strings.TrimSpace(string(result.Stdout)) != ""
 }
 
 // GetCommitCountDifference returns the number of commits the worktree branch
 // is ahead (+) or behind (-) relative to the base branch.
 // Positive values = ahead, Negative = behind, Zero = same
 func GetCommitCountDifference(worktreePath, baseBranch string) (int, error) {
-	// Get ahead count: commits in worktree that are not in baseBranch
-	aheadCmd := exec.Command("git", "rev-list", "--count", baseBranch+"..HEAD")
-	aheadCmd.Dir = worktreePath
-	aheadOutput, err := aheadCmd.CombinedOutput()
+	ahead, behind, err := GetAheadBehind(worktreePath, baseBranch)
 	if err != nil {
 		return 0, err
 	}
-	ahead := strings.TrimSpace(string(aheadOutput))
+
+	// Return net difference (positive = ahead, negative = behind)
+	return ahead - behind, nil
+}
+
+// GetAheadBehind returns how many commits the worktree's HEAD is ahead of and
+// behind the base branch
+func GetAheadBehind(worktreePath, baseBranch string) (ahead, behind int, err error) {
+	// Get ahead count: commits in worktree that are not in baseBranch
+	aheadResult, err := run(worktreePath, "rev-list", "--count", baseBranch+"..HEAD")
+	if err != nil {
+		return 0, 0, err
+	}
 
 	// Get behind count: commits in baseBranch that are not in worktree
-	behindCmd := exec.Command("git", "rev-list", "--count", "HEAD.."+baseBranch)
-	behindCmd.Dir = worktreePath
-	behindOutput, err := behindCmd.CombinedOutput()
+	behindResult, err := run(worktreePath, "rev-list", "--count", "HEAD.."+baseBranch)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	behind := strings.TrimSpace(string(behindOutput))
 
 	// Parse counts (default to 0 if empty)
-	aheadCount := 0
-	behindCount := 0
-	if ahead != "" {
-		fmt.Sscanf(ahead, "%d", &aheadCount)
+	if s := strings.TrimSpace(string(aheadResult.Stdout)); s != "" {
+		fmt.Sscanf(s, "%d", &ahead)
 	}
-	if behind != "" {
-		fmt.Sscanf(behind, "%d", &behindCount)
+	if s := strings.TrimSpace(string(behindResult.Stdout)); s != "" {
+		fmt.Sscanf(s, "%d", &behind)
 	}
 
-	// Return net difference (positive = ahead, negative = behind)
-	return aheadCount - behindCount, nil
+	return ahead, behind, nil
+}
+
+// IsIgnored checks if path is ignored by git in the given worktree
+func IsIgnored(dir, path string) bool {
+	_, err := run(dir, "check-ignore", "-q", path)
+	return err == nil
+}
+
+// Exclude adds pattern to the repository's info/exclude, which all its
+// worktrees share, unless it is there already
+func Exclude(dir, pattern string) error {
+	result, err := run(dir, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(strings.TrimSpace(string(result.Stdout)), "info", "exclude")
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, pattern+"\n"...)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// GitPath returns the absolute path of name inside the git directory of
+// the worktree at dir, e.g. "hooks/pre-commit", honoring core.hooksPath
+// and the directory shared by all worktrees
+func GitPath(dir, name string) (string, error) {
+	result, err := run(dir, "rev-parse", "--path-format=absolute", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// TrackedSymlinks returns the paths of the symlinks committed to the
+// repository at dir
+func TrackedSymlinks(dir string) ([]string, error) {
+	result, err := run(dir, "ls-files", "--stage", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	var links []string
+	for _, entry := range strings.Split(string(result.Stdout), "\x00") {
+		// <mode> <object> <stage>\t<path>
+		if mode, path, ok := strings.Cut(entry, "\t"); ok && strings.HasPrefix(mode, "120000 ") {
+			links = append(links, path)
+		}
+	}
+	return links, nil
+}
+
+// GetWorktreeStatus returns the dirty/ahead/behind state of a worktree
+// relative to the base branch
+func GetWorktreeStatus(worktreePath, baseBranch string) (WorktreeStatus, error) {
+	status := WorktreeStatus{Dirty: HasUncommittedChanges(worktreePath)}
+	ahead, behind, err := GetAheadBehind(worktreePath, baseBranch)
+	if err != nil {
+		return status, err
+	}
+	status.Ahead = ahead
+	status.Behind = behind
+	return status, nil
+}
+
+// GetChangedFiles returns the paths (relative to dir) of files with
+// uncommitted changes, including untracked files
+func GetChangedFiles(dir string) ([]string, error) {
+	result, err := run(dir, "status", "--porcelain=v1", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	return ParseStatusZ(string(result.Stdout)), nil
+}
+
+// ParseStatusZ parses git status --porcelain=v1 -z output into file paths
+func ParseStatusZ(output string) []string {
+	var files []string
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files
+}
+
+// ParseUnmergedZ parses git status --porcelain=v2 -z output into the paths
+// of the files with unresolved merge conflicts
+func ParseUnmergedZ(output string) []string {
+	var files []string
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		switch {
+		case strings.HasPrefix(entry, "u "):
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			if fields := strings.SplitN(entry, " ", 11); len(fields) == 11 {
+				files = append(files, fields[10])
+			}
+		case strings.HasPrefix(entry, "2 "):
+			// Renames and copies are followed by the original path
+			i++
+		}
+	}
+	return files
+}
+
+// GetOldestChangeTime returns the modification time of the oldest file with
+// uncommitted changes, approximating how long the worktree has been dirty.
+// Returns false if the worktree is clean.
+func GetOldestChangeTime(dir string) (time.Time, bool, error) {
+	files, err := GetChangedFiles(dir)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var oldest time.Time
+	for _, file := range files {
+		info, err := os.Lstat(filepath.Join(dir, file))
+		if err != nil {
+			// Deleted files have no mtime
+			continue
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+
+	if len(files) == 0 {
+		return time.Time{}, false, nil
+	}
+	if oldest.IsZero() {
+		// Only deletions; the best we can say is "now"
+		oldest = time.Now()
+	}
+	return oldest, true, nil
 }
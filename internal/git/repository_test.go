@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -171,3 +172,32 @@ func TestGetMainRepoPathNonGitDir(t *testing.T) {
 		t.Error("GetMainRepoPath() should fail for non-git directory")
 	}
 }
+
+func TestParseStatusZ(t *testing.T) {
+	output := " M cmd/nag.go\x00?? notes.txt\x00R  new.go\x00old.go\x00D  gone.go\x00"
+
+	files := ParseStatusZ(output)
+	expected := []string{"cmd/nag.go", "notes.txt", "new.go", "gone.go"}
+	if len(files) != len(expected) {
+		t.Fatalf("ParseStatusZ() = %v, expected %v", files, expected)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("ParseStatusZ()[%d] = %q, expected %q", i, files[i], expected[i])
+		}
+	}
+}
+
+func TestParseUnmergedZ(t *testing.T) {
+	output := "1 .M N... 100644 100644 100644 a1 a1 cmd/nag.go\x00" +
+		"2 R. N... 100644 100644 100644 b1 b1 R100 new.go\x00u old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 c1 c2 c3 has space.go\x00" +
+		"u AA N... 000000 100644 100644 100644 0 d2 d3 added.go\x00" +
+		"? notes.txt\x00"
+
+	files := ParseUnmergedZ(output)
+	expected := []string{"has space.go", "added.go"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("ParseUnmergedZ() = %q, expected %q", files, expected)
+	}
+}
@@ -0,0 +1,241 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/proc"
+)
+
+// Command is a git invocation
+type Command struct {
+	// Dir is the directory git runs in
+	Dir string
+	// Args are the arguments after "git"
+	Args []string
+	// Env is added to the environment git inherits, as KEY=value
+	Env []string
+	// Stdin is git's standard input; nil means no input
+	Stdin io.Reader
+	// Stdout and Stderr, if set, receive git's output as it is written
+	// instead of it being captured in the Result, e.g. to attach git to the
+	// terminal
+	Stdout io.Writer
+	Stderr io.Writer
+	// Progress, if set, receives what git writes to stdout and stderr as it
+	// is written, such as --progress updates, while the two streams are
+	// still captured separately in the Result. Streams sent to Stdout or
+	// Stderr are not passed on.
+	Progress io.Writer
+	// Cleanup marks commands that put things back after another command
+	// failed, such as aborting a rebase. They run even after the operation
+	// was interrupted, so the worktree is left in a recoverable state.
+	Cleanup bool
+}
+
+// Result is the output git wrote, unless it went to the Command's own
+// writers
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+	// Combined is stdout and stderr interleaved as they were written
+	Combined []byte
+}
+
+// GitRunner runs git commands. The managers and functions in this package
+// run git through one, so tests can substitute a fake and another backend
+// can be plugged in. A failed command returns an error with an ExitCode
+// method, as *exec.ExitError has, and the output written up to then.
+type GitRunner interface {
+	Run(ctx context.Context, cmd Command) (Result, error)
+}
+
+// ExecRunner runs the git executable. It is safe for concurrent use.
+//
+// Commands that don't read the terminal run in their own process group,
+// which is killed when the context is done, the command times out or
+// ccswitch is interrupted with Ctrl-C. Being in the background, git can't
+// prompt for credentials there, so it is told to fail instead of waiting.
+// Commands whose output is captured run with LC_ALL=C, so the messages
+// ccswitch reports and inspects are the same whatever the user's locale.
+// Once interrupted, the runner refuses further commands except cleanup, so
+// that the operation in progress stops.
+type ExecRunner struct {
+	// Env is added to the environment of every command
+	Env []string
+	// Timeout kills commands that run longer, unless it is zero. Commands
+	// that read input, such as an interactive rebase, are not timed.
+	Timeout time.Duration
+
+	interrupted atomic.Bool
+}
+
+// Run runs git as a subprocess, killing it when ctx is done
+func (r *ExecRunner) Run(ctx context.Context, c Command) (Result, error) {
+	if r.interrupted.Load() && !c.Cleanup {
+		return Result{}, fmt.Errorf("git %s: %w", c.Args[0], errors.ErrInterrupted)
+	}
+	if c.Cleanup {
+		ctx = context.WithoutCancel(ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if r.Timeout > 0 && c.Stdin == nil {
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	if !c.Cleanup {
+		// A second Ctrl-C during cleanup terminates ccswitch as usual
+		stop := proc.OnInterrupt(func() {
+			r.interrupted.Store(true)
+			cancel()
+		})
+		defer stop()
+	}
+
+	cmd := exec.CommandContext(ctx, "git", c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Stdin = c.Stdin
+	var env []string
+	if c.Stdout == nil && c.Stderr == nil {
+		// ccswitch reads the output, so it must not depend on the user's
+		// language. Output shown to the user stays in it.
+		env = append(env, "LC_ALL=C")
+	}
+	env = append(append(env, r.Env...), c.Env...)
+	if proc.KillGroupOnCancel(cmd) {
+		env = append(env, "GIT_TERMINAL_PROMPT=0")
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	output := outputCapture{progress: c.Progress}
+	cmd.Stdout = c.Stdout
+	if cmd.Stdout == nil {
+		cmd.Stdout = output.writer(&output.stdout)
+	}
+	cmd.Stderr = c.Stderr
+	if cmd.Stderr == nil {
+		cmd.Stderr = output.writer(&output.stderr)
+	}
+
+	err := cmd.Run()
+	result := Result{
+		Stdout:   output.stdout.Bytes(),
+		Stderr:   output.stderr.Bytes(),
+		Combined: output.combined.Bytes(),
+	}
+	if err != nil && ctx.Err() != nil {
+		switch {
+		case r.interrupted.Load():
+			return result, fmt.Errorf("git %s: %w", c.Args[0], errors.ErrInterrupted)
+		case stderrors.Is(ctx.Err(), context.DeadlineExceeded) && r.Timeout > 0:
+			return result, fmt.Errorf("git %s: %w after %s: %w", c.Args[0], errors.ErrGitTimeout, r.Timeout, ctx.Err())
+		}
+		return result, fmt.Errorf("git %s: %w", c.Args[0], ctx.Err())
+	}
+	return result, err
+}
+
+// errorOutput returns what a failed command reported on stderr, keeping
+// only the last of the progress updates git separates with carriage returns,
+// or its combined output if it wrote nothing there
+func errorOutput(result Result) string {
+	if len(bytes.TrimSpace(result.Stderr)) == 0 {
+		return strings.TrimSpace(string(result.Combined))
+	}
+	var lines []string
+	for _, line := range strings.Split(string(result.Stderr), "\n") {
+		if i := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsInterruption reports whether err is a command killed because the
+// operation was interrupted or timed out, rather than one that failed
+func IsInterruption(err error) bool {
+	return errors.IsInterrupted(err) || errors.IsGitTimeout(err)
+}
+
+// outputCapture collects stdout and stderr separately and interleaved,
+// passing them on to progress as they arrive unless it is nil. exec copies
+// the two streams in separate goroutines, so writes are locked.
+type outputCapture struct {
+	mu                       sync.Mutex
+	stdout, stderr, combined bytes.Buffer
+	progress                 io.Writer
+}
+
+func (o *outputCapture) writer(stream *bytes.Buffer) io.Writer {
+	return captureWriter{capture: o, stream: stream}
+}
+
+type captureWriter struct {
+	capture *outputCapture
+	stream  *bytes.Buffer
+}
+
+func (w captureWriter) Write(p []byte) (int, error) {
+	w.capture.mu.Lock()
+	defer w.capture.mu.Unlock()
+	w.stream.Write(p)
+	if w.capture.progress != nil {
+		// A display that fails must not fail the command
+		_, _ = w.capture.progress.Write(p)
+	}
+	return w.capture.combined.Write(p)
+}
+
+// DefaultRunner runs git for the functions in this package and for managers
+// without a Runner of their own. Replace it before running any commands,
+// not while they run.
+var DefaultRunner GitRunner = &ExecRunner{}
+
+// runCleanup runs a cleanup command with args in dir using runner, or
+// DefaultRunner if runner is nil
+func runCleanup(runner GitRunner, dir string, args ...string) (Result, error) {
+	if runner == nil {
+		runner = DefaultRunner
+	}
+	return runner.Run(context.Background(), Command{Dir: dir, Args: args, Cleanup: true})
+}
+
+// runWith runs git with args in dir using runner, or DefaultRunner if
+// runner is nil
+func runWith(runner GitRunner, dir string, args ...string) (Result, error) {
+	if runner == nil {
+		runner = DefaultRunner
+	}
+	return runner.Run(context.Background(), Command{Dir: dir, Args: args})
+}
+
+// run runs git with args in dir using DefaultRunner
+func run(dir string, args ...string) (Result, error) {
+	return runWith(nil, dir, args...)
+}
+
+// ExitCode returns the status a failed git command exited with, or -1 if
+// err does not carry one, e.g. because git could not be started
+func ExitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if stderrors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+// fakeExitError is a failed command as a fake runner reports it
+type fakeExitError int
+
+func (e fakeExitError) Error() string { return "exit status" }
+func (e fakeExitError) ExitCode() int { return int(e) }
+
+// fakeRunner answers commands from canned output, recording what ran
+type fakeRunner struct {
+	mu       sync.Mutex
+	commands []Command
+	respond  func(c Command) (Result, error)
+}
+
+func (f *fakeRunner) Run(ctx context.Context, c Command) (Result, error) {
+	f.mu.Lock()
+	f.commands = append(f.commands, c)
+	f.mu.Unlock()
+	return f.respond(c)
+}
+
+func TestManagersUseRunner(t *testing.T) {
+	fake := &fakeRunner{respond: func(c Command) (Result, error) {
+		if c.Args[0] == "rev-parse" && c.Args[2] == "refs/heads/exists" {
+			return Result{Stdout: []byte("abc123\n"), Combined: []byte("abc123\n")}, nil
+		}
+		return Result{Combined: []byte("fatal: not a valid ref")}, fakeExitError(128)
+	}}
+
+	bm := NewBranchManager("/repo")
+	bm.Runner = fake
+	if !bm.Exists("exists") {
+		t.Error("Exists(exists) = false, expected true")
+	}
+	if bm.Exists("missing") {
+		t.Error("Exists(missing) = true, expected false")
+	}
+	err := bm.Create("new")
+	if err == nil || !strings.Contains(err.Error(), "fatal: not a valid ref") {
+		t.Errorf("Create error = %v, expected it to include git's output", err)
+	}
+
+	for _, c := range fake.commands {
+		if c.Dir != "/repo" {
+			t.Errorf("git %v ran in %q, expected /repo", c.Args, c.Dir)
+		}
+	}
+	if len(fake.commands) != 3 {
+		t.Errorf("ran %d commands, expected 3", len(fake.commands))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	previous := DefaultRunner
+	defer func() { DefaultRunner = previous }()
+	DefaultRunner = &fakeRunner{respond: func(c Command) (Result, error) {
+		return Result{}, fakeExitError(1)
+	}}
+
+	// git config exits with 1 for unset keys
+	value, err := GetConfig("/repo", "user.name")
+	if err != nil || value != "" {
+		t.Errorf("GetConfig = %q, %v, expected an unset key", value, err)
+	}
+
+	if code := ExitCode(fmt.Errorf("not started")); code != -1 {
+		t.Errorf("ExitCode of a plain error = %d, expected -1", code)
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	dir := t.TempDir()
+	gitIn(t, dir, "init")
+
+	runner := &ExecRunner{Env: []string{"GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=ccswitch.runner", "GIT_CONFIG_VALUE_0=exec"}}
+	result, err := runner.Run(context.Background(), Command{Dir: dir, Args: []string{"config", "ccswitch.runner"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(result.Stdout)); got != "exec" {
+		t.Errorf("config from the injected environment = %q, expected exec", got)
+	}
+
+	result, err = runner.Run(context.Background(), Command{Dir: dir, Args: []string{"rev-parse", "--verify", "missing"}})
+	if ExitCode(err) != 128 {
+		t.Errorf("ExitCode = %d, expected 128 (error %v)", ExitCode(err), err)
+	}
+	if len(result.Stderr) == 0 || string(result.Combined) != string(result.Stderr) {
+		t.Errorf("Stderr = %q, Combined = %q, expected git's error in both", result.Stderr, result.Combined)
+	}
+}
+
+func TestExecRunnerTimeout(t *testing.T) {
+	dir := t.TempDir()
+	gitIn(t, dir, "init")
+
+	// The alias runs sleep, which is killed with git's process group
+	runner := &ExecRunner{Timeout: 100 * time.Millisecond}
+	start := time.Now()
+	_, err := runner.Run(context.Background(), Command{Dir: dir, Args: []string{"-c", "alias.hang=!sleep 30", "hang"}})
+	if !errors.IsGitTimeout(err) {
+		t.Errorf("Run error = %v, expected a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %s, expected it to be killed after the timeout", elapsed)
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !windows
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func TestExecRunnerInterrupt(t *testing.T) {
+	dir := t.TempDir()
+	gitIn(t, dir, "init")
+
+	runner := &ExecRunner{}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	}()
+	_, err := runner.Run(context.Background(), Command{Dir: dir, Args: []string{"-c", "alias.hang=!sleep 30", "hang"}})
+	if !errors.IsInterrupted(err) {
+		t.Fatalf("Run error = %v, expected an interruption", err)
+	}
+
+	// The operation stops, but cleanup still runs
+	if _, err := runner.Run(context.Background(), Command{Dir: dir, Args: []string{"status"}}); !errors.IsInterrupted(err) {
+		t.Errorf("Run after the interruption error = %v, expected it to be refused", err)
+	}
+	if _, err := runner.Run(context.Background(), Command{Dir: dir, Args: []string{"status"}, Cleanup: true}); err != nil {
+		t.Errorf("cleanup Run error = %v, expected it to run", err)
+	}
+}
+
+func TestExecRunnerLocale(t *testing.T) {
+	dir := t.TempDir()
+	gitIn(t, dir, "init")
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+
+	// Captured output is in the C locale; output passed through is not
+	args := []string{"-c", "alias.locale=!echo $LC_ALL", "locale"}
+	result, err := (&ExecRunner{}).Run(context.Background(), Command{Dir: dir, Args: args})
+	if err != nil || strings.TrimSpace(string(result.Stdout)) != "C" {
+		t.Errorf("LC_ALL of a captured command = %q, %v, expected C", result.Stdout, err)
+	}
+
+	var out bytes.Buffer
+	if _, err := (&ExecRunner{}).Run(context.Background(), Command{Dir: dir, Args: args, Stdout: &out, Stderr: &out}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "de_DE.UTF-8" {
+		t.Errorf("LC_ALL of a command writing to the terminal = %q, expected the user's", got)
+	}
+}
+
+func TestExecRunnerProgress(t *testing.T) {
+	dir := t.TempDir()
+	gitIn(t, dir, "init")
+
+	// Progress sees both streams as they are written, and they are still
+	// captured apart
+	args := []string{"-c", `alias.work=!echo done; printf 'step 1\rstep 2\n' >&2; echo 'fatal: broken' >&2; exit 1`, "work"}
+	var progress bytes.Buffer
+	result, err := (&ExecRunner{}).Run(context.Background(), Command{Dir: dir, Args: args, Progress: &progress})
+	if ExitCode(err) != 1 {
+		t.Fatalf("Run error = %v, expected exit status 1", err)
+	}
+	for _, want := range []string{"done", "step 2", "fatal: broken"} {
+		if !strings.Contains(progress.String(), want) {
+			t.Errorf("progress = %q, expected it to include %q", progress.String(), want)
+		}
+	}
+	if got := strings.TrimSpace(string(result.Stdout)); got != "done" {
+		t.Errorf("Stdout = %q, expected only what went to stdout", got)
+	}
+	if got := errorOutput(result); got != "step 2\nfatal: broken" {
+		t.Errorf("errorOutput() = %q, expected stderr without overwritten progress", got)
+	}
+}
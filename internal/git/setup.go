@@ -0,0 +1,71 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UsesLFS reports whether the worktree at dir tracks files with Git LFS,
+// going by its top-level .gitattributes
+func UsesLFS(dir string) bool {
+	f, err := os.Open(filepath.Join(dir, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") && strings.Contains(line, "filter=lfs") {
+			return true
+		}
+	}
+	return false
+}
+
+// LFSInstalled reports whether the git-lfs extension is installed
+func LFSInstalled(dir string) bool {
+	_, err := run(dir, "lfs", "version")
+	return err == nil
+}
+
+// HasSubmodules reports whether the worktree at dir declares submodules
+func HasSubmodules(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".gitmodules"))
+	return err == nil
+}
+
+// PullLFS downloads and checks out the LFS objects of the worktree at dir,
+// streaming git's progress to out
+func PullLFS(dir string, out io.Writer) error {
+	return runWithProgress(dir, out, "lfs", "pull")
+}
+
+// UpdateSubmodules initializes and checks out the submodules of the worktree
+// at dir, recursively, streaming git's progress to out
+func UpdateSubmodules(dir string, out io.Writer) error {
+	return runWithProgress(dir, out, "submodule", "update", "--init", "--recursive", "--progress")
+}
+
+// runStreaming runs git with args in dir, streaming its output to progress
+// as it arrives unless progress is nil
+func runStreaming(dir string, progress io.Writer, args ...string) (Result, error) {
+	return DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: args, Progress: progress})
+}
+
+// runWithProgress runs git with args in dir, streaming its output to out.
+// A failure reports what git wrote to stderr, without the progress it
+// wrote to stdout.
+func runWithProgress(dir string, out io.Writer, args ...string) error {
+	result, err := runStreaming(dir, out, args...)
+	if err != nil {
+		return fmt.Errorf("git %s failed: %w, output: %s", strings.Join(args, " "), err, errorOutput(result))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsesLFS(t *testing.T) {
+	dir := t.TempDir()
+	if UsesLFS(dir) {
+		t.Error("UsesLFS() should be false without .gitattributes")
+	}
+
+	attrs := "# *.psd filter=lfs\n*.txt text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	if UsesLFS(dir) {
+		t.Error("UsesLFS() should ignore commented out LFS patterns")
+	}
+
+	attrs += "*.bin filter=lfs diff=lfs merge=lfs -text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	if !UsesLFS(dir) {
+		t.Error("UsesLFS() should detect filter=lfs")
+	}
+}
+
+func TestUpdateSubmodules(t *testing.T) {
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	repo := filepath.Join(root, "repo")
+	for _, dir := range []string{lib, repo} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		gitIn(t, dir, "init", "-b", "main")
+		gitIn(t, dir, "config", "user.email", "test@example.com")
+		gitIn(t, dir, "config", "user.name", "Test User")
+	}
+	commitIn(t, lib, "lib.txt", "lib\n")
+
+	gitIn(t, repo, "-c", "protocol.file.allow=always", "submodule", "add", lib, "lib")
+	gitIn(t, repo, "commit", "-m", "add lib")
+
+	worktree := filepath.Join(root, "worktree")
+	gitIn(t, repo, "worktree", "add", "-b", "feature", worktree)
+	if !HasSubmodules(worktree) {
+		t.Fatal("HasSubmodules() should detect .gitmodules")
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "lib", "lib.txt")); err == nil {
+		t.Fatal("git worktree add should leave the submodule empty")
+	}
+
+	// Submodules are cloned from a local path in this test
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+	if err := UpdateSubmodules(worktree, io.Discard); err != nil {
+		t.Fatalf("UpdateSubmodules() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "lib", "lib.txt")); err != nil {
+		t.Errorf("submodule not checked out: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+// CheckSigningKey reports whether commits made in dir can be signed, going
+// by gpg.format: OpenPGP needs gpg and a secret key for user.signingkey (or
+// the committer's email), SSH needs user.signingkey or
+// gpg.ssh.defaultKeyCommand, and X.509 needs gpgsm. The error wraps
+// errors.ErrSigning.
+func CheckSigningKey(dir string) error {
+	format, err := GetConfig(dir, "gpg.format")
+	if err != nil {
+		return fmt.Errorf("failed to read gpg.format: %w", err)
+	}
+	key, err := GetConfig(dir, "user.signingkey")
+	if err != nil {
+		return fmt.Errorf("failed to read user.signingkey: %w", err)
+	}
+
+	switch format {
+	case "ssh":
+		if key != "" {
+			return nil
+		}
+		if command, _ := GetConfig(dir, "gpg.ssh.defaultKeyCommand"); command != "" {
+			return nil
+		}
+		return fmt.Errorf("%w: gpg.format is ssh but user.signingkey is not set", errors.ErrSigning)
+
+	case "x509":
+		_, err := signingProgram(dir, "gpg.x509.program", "gpgsm")
+		return err
+
+	case "", "openpgp":
+		program, err := signingProgram(dir, "gpg.openpgp.program", "gpg")
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			if key, _ = GetConfig(dir, "user.email"); key == "" {
+				return fmt.Errorf("%w: neither user.signingkey nor user.email is set", errors.ErrSigning)
+			}
+		}
+		if err := exec.Command(program, "--list-secret-keys", key).Run(); err != nil {
+			return fmt.Errorf("%w: no secret key for %s in %s", errors.ErrSigning, key, program)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unknown gpg.format %q", errors.ErrSigning, format)
+	}
+}
+
+// signingProgram returns the program git signs with: the one set in key (or
+// gpg.program for OpenPGP), or fallback, which must be installed
+func signingProgram(dir, key, fallback string) (string, error) {
+	program, _ := GetConfig(dir, key)
+	if program == "" && fallback == "gpg" {
+		program, _ = GetConfig(dir, "gpg.program")
+	}
+	if program == "" {
+		program = fallback
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s not found", errors.ErrSigning, program)
+	}
+	return path, nil
+}
+
+// signArgs returns the flag that makes git sign the commits it creates
+func signArgs(sign bool) []string {
+	if sign {
+		return []string{"--gpg-sign"}
+	}
+	return nil
+}
+
+// isSigningFailure reports whether git output says a commit could not be
+// signed
+func isSigningFailure(output string) bool {
+	return strings.Contains(output, "failed to sign") || strings.Contains(output, "Couldn't load public key")
+}
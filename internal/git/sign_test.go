@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func TestCheckSigningKey(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+
+	gitIn(t, repo, "config", "gpg.format", "ssh")
+	if err := CheckSigningKey(repo); !errors.IsSigning(err) {
+		t.Errorf("CheckSigningKey() without user.signingkey = %v, expected a signing error", err)
+	}
+	gitIn(t, repo, "config", "user.signingkey", "~/.ssh/id_ed25519.pub")
+	if err := CheckSigningKey(repo); err != nil {
+		t.Errorf("CheckSigningKey() with an SSH key failed: %v", err)
+	}
+
+	gitIn(t, repo, "config", "gpg.format", "openpgp")
+	gitIn(t, repo, "config", "gpg.program", filepath.Join(repo, "no-such-gpg"))
+	if err := CheckSigningKey(repo); !errors.IsSigning(err) {
+		t.Errorf("CheckSigningKey() with a missing gpg = %v, expected a signing error", err)
+	}
+}
+
+func TestRebaseKeepsSignatures(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+
+	repo := t.TempDir()
+	key := filepath.Join(t.TempDir(), "key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Skipf("ssh-keygen failed: %v, output: %s", err, output)
+	}
+
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	gitIn(t, repo, "config", "gpg.format", "ssh")
+	gitIn(t, repo, "config", "user.signingkey", key+".pub")
+	commitIn(t, repo, "base.txt", "base\n")
+
+	gitIn(t, repo, "checkout", "-b", "feature")
+	commitIn(t, repo, "a.txt", "a\n")
+	gitIn(t, repo, "checkout", "main")
+	commitIn(t, repo, "b.txt", "b\n")
+	gitIn(t, repo, "checkout", "feature")
+
+	rm := NewRebaseManager(repo)
+	rm.Sign = true
+	if _, _, err := rm.RebaseCommit("main"); err != nil {
+		t.Fatalf("RebaseCommit() failed: %v", err)
+	}
+
+	output, err := exec.Command("git", "-C", repo, "cat-file", "commit", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git cat-file failed: %v", err)
+	}
+	if !strings.Contains(string(output), "gpgsig") {
+		t.Errorf("rebased commit is not signed:\n%s", output)
+	}
+
+	// A key that cannot be used is reported as a signing error
+	gitIn(t, repo, "config", "user.signingkey", filepath.Join(repo, "missing.pub"))
+	if err := os.WriteFile(filepath.Join(repo, "c.txt"), []byte("c\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	gitIn(t, repo, "add", "c.txt")
+	cm := NewCommitManager(repo)
+	cm.Sign = true
+	if err := cm.Commit("change c.txt"); !errors.IsSigning(err) {
+		t.Errorf("Commit() with a missing key = %v, expected a signing error", err)
+	}
+}
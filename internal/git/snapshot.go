@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotsRef is where the snapshots of sessions are kept, under
+// <session>/<name>
+const SnapshotsRef = "refs/ccswitch/snapshots/"
+
+// Snapshot is the uncommitted state of a worktree saved by SaveSnapshot
+type Snapshot struct {
+	Name string
+	// Commit holds the saved state on top of the commit that was checked
+	// out, its parent
+	Commit  string
+	Created time.Time
+	Message string
+}
+
+// SnapshotRef returns the ref snapshot name of session is kept under
+func SnapshotRef(session, name string) string {
+	return SnapshotsRef + session + "/" + name
+}
+
+// SaveSnapshot saves the uncommitted changes of the worktree at dir, staged
+// or not and including untracked files that are not ignored, as a commit
+// on top of HEAD kept under ref. Like 'git stash create', the worktree is
+// left as it is.
+func SaveSnapshot(dir, ref, message string) (string, error) {
+	if result, err := run(dir, "check-ref-format", ref); err != nil {
+		return "", fmt.Errorf("invalid snapshot name %s: %w, output: %s", ref, err, strings.TrimSpace(string(result.Combined)))
+	}
+
+	var commit string
+	err := withWorktreeIndex(dir, func(env []string) error {
+		tree, err := DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: []string{"write-tree"}, Env: env})
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot: %w, output: %s", err, strings.TrimSpace(string(tree.Combined)))
+		}
+		result, err := run(dir, "commit-tree", strings.TrimSpace(string(tree.Stdout)), "-p", "HEAD", "-m", message)
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+		commit = strings.TrimSpace(string(result.Stdout))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if result, err := run(dir, "update-ref", ref, commit); err != nil {
+		return "", fmt.Errorf("failed to save snapshot: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return commit, nil
+}
+
+// ListSnapshots returns the snapshots of session, newest first
+func ListSnapshots(dir, session string) ([]Snapshot, error) {
+	prefix := SnapshotRef(session, "")
+	result, err := run(dir, "for-each-ref", "--sort=-creatordate", "--format=%(refname)%00%(objectname)%00%(creatordate:unix)%00%(subject)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(string(result.Stdout)), "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[2], 10, 64)
+		snapshots = append(snapshots, Snapshot{
+			Name:    strings.TrimPrefix(fields[0], prefix),
+			Commit:  fields[1],
+			Created: time.Unix(unix, 0),
+			Message: fields[3],
+		})
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot applies the changes saved in snapshot commit to the
+// worktree at dir, as ApplyPatch does, returning the files left with
+// conflicts
+func RestoreSnapshot(dir, commit string) ([]string, error) {
+	result, err := run(dir, "diff", "--binary", commit+"^", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return ApplyPatch(dir, result.Stdout)
+}
+
+// DeleteRef deletes ref, such as a snapshot
+func DeleteRef(dir, ref string) error {
+	if result, err := run(dir, "update-ref", "-d", ref); err != nil {
+		return fmt.Errorf("failed to delete %s: %w, output: %s", ref, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
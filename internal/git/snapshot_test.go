@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "app.txt", "one\n")
+
+	if err := os.WriteFile(filepath.Join(repo, "app.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write app.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("Failed to write notes.txt: %v", err)
+	}
+
+	if _, err := SaveSnapshot(repo, SnapshotRef("auth", "bad..name"), "x"); err == nil {
+		t.Error("SaveSnapshot() should refuse an invalid name")
+	}
+	commit, err := SaveSnapshot(repo, SnapshotRef("auth", "before-refactor"), "before refactor")
+	if err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+	if !HasUncommittedChanges(repo) {
+		t.Error("SaveSnapshot() should leave the changes in place")
+	}
+
+	snapshots, err := ListSnapshots(repo, "auth")
+	if err != nil {
+		t.Fatalf("ListSnapshots() failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != "before-refactor" || snapshots[0].Commit != commit || snapshots[0].Message != "before refactor" {
+		t.Fatalf("ListSnapshots() = %+v", snapshots)
+	}
+	if others, _ := ListSnapshots(repo, "other"); len(others) != 0 {
+		t.Errorf("ListSnapshots() of another session = %+v", others)
+	}
+
+	if err := DiscardChanges(repo); err != nil {
+		t.Fatalf("DiscardChanges() failed: %v", err)
+	}
+	conflicted, err := RestoreSnapshot(repo, commit)
+	if err != nil || conflicted != nil {
+		t.Fatalf("RestoreSnapshot() = %v, %v", conflicted, err)
+	}
+	for name, expected := range map[string]string{"app.txt": "one\ntwo\n", "notes.txt": "untracked\n"} {
+		if data, _ := os.ReadFile(filepath.Join(repo, name)); string(data) != expected {
+			t.Errorf("%s after RestoreSnapshot() = %q", name, data)
+		}
+	}
+
+	if err := DeleteRef(repo, SnapshotRef("auth", "before-refactor")); err != nil {
+		t.Fatalf("DeleteRef() failed: %v", err)
+	}
+	if snapshots, _ := ListSnapshots(repo, "auth"); len(snapshots) != 0 {
+		t.Errorf("ListSnapshots() after DeleteRef() = %+v", snapshots)
+	}
+}
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatusCache remembers whether worktrees are dirty and how far they are
+// ahead of and behind their base, which on large repositories take git a
+// while to work out. Answers are kept for the commits of HEAD and the base
+// and the time the index was written, so commits, checkouts and staging
+// are seen at once. Edits that are not staged change none of these, so
+// whether a worktree is dirty is trusted for MaxAge only.
+//
+// Refs are read from git's files to check the cache; where they can't be,
+// as for a base such as HEAD~1, git is asked every time.
+type StatusCache struct {
+	// Dir, when set, keeps answers on disk too, so that they outlive the
+	// process
+	Dir string
+	// MaxAge is how long whether a worktree is dirty is trusted; 0 asks
+	// git every time
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]statusEntry
+}
+
+// DefaultStatusCache is the cache of CachedWorktreeStatus, kept in memory
+// for as long as ccswitch runs, such as the refreshes of a TUI
+var DefaultStatusCache = &StatusCache{MaxAge: 2 * time.Second}
+
+// statusEntry is what the cache knows about a worktree and a base
+type statusEntry struct {
+	Head      string    `json:"head"`
+	Base      string    `json:"base"`
+	IndexTime time.Time `json:"index_time"`
+	Ahead     int       `json:"ahead"`
+	Behind    int       `json:"behind"`
+	// Counted is false until Ahead and Behind are set
+	Counted bool `json:"counted"`
+	Dirty   bool `json:"dirty"`
+	// CheckedAt is when Dirty was found out; zero until it is
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// CachedWorktreeStatus is GetWorktreeStatus answered from
+// DefaultStatusCache where possible, for code that shows the state of
+// worktrees rather than acting on it
+func CachedWorktreeStatus(worktreePath, baseBranch string) (WorktreeStatus, error) {
+	return DefaultStatusCache.WorktreeStatus(worktreePath, baseBranch)
+}
+
+// WorktreeStatus returns the dirty/ahead/behind state of a worktree
+// relative to the base branch, as GetWorktreeStatus does
+func (c *StatusCache) WorktreeStatus(worktreePath, baseBranch string) (WorktreeStatus, error) {
+	dirs, ok := FindGitDirs(worktreePath)
+	if !ok {
+		return GetWorktreeStatus(worktreePath, baseBranch)
+	}
+	head, base := dirs.Commit(), dirs.ResolveRef(baseBranch)
+	if head == "" || base == "" {
+		return GetWorktreeStatus(worktreePath, baseBranch)
+	}
+
+	key := statusKey(dirs.Path, baseBranch)
+	entry, _ := c.get(key)
+	if entry.Head != head || entry.Base != base {
+		entry = statusEntry{Head: head, Base: base}
+	}
+	if !entry.Counted {
+		ahead, behind, err := GetAheadBehind(worktreePath, baseBranch)
+		if err != nil {
+			return WorktreeStatus{}, err
+		}
+		entry.Ahead, entry.Behind, entry.Counted = ahead, behind, true
+	}
+	entry = c.checkDirty(dirs, entry)
+	c.put(key, entry)
+
+	return WorktreeStatus{Dirty: entry.Dirty, Ahead: entry.Ahead, Behind: entry.Behind}, nil
+}
+
+// HasUncommittedChanges checks if a worktree has uncommitted changes, as
+// the function of the same name does
+func (c *StatusCache) HasUncommittedChanges(dir string) bool {
+	dirs, ok := FindGitDirs(dir)
+	if !ok {
+		return HasUncommittedChanges(dir)
+	}
+	head := dirs.Commit()
+
+	key := statusKey(dirs.Path, "")
+	entry, _ := c.get(key)
+	if entry.Head != head {
+		entry = statusEntry{Head: head}
+	}
+	entry = c.checkDirty(dirs, entry)
+	c.put(key, entry)
+	return entry.Dirty
+}
+
+// checkDirty fills in whether the worktree is dirty, unless entry knows
+// it from less than MaxAge ago and the index has not been written since
+func (c *StatusCache) checkDirty(dirs GitDirs, entry statusEntry) statusEntry {
+	if !entry.CheckedAt.IsZero() && time.Since(entry.CheckedAt) < c.MaxAge && entry.IndexTime.Equal(indexTime(dirs)) {
+		return entry
+	}
+	entry.CheckedAt = time.Now()
+	entry.Dirty = HasUncommittedChanges(dirs.Path)
+	// git status may have refreshed the index, so take its time after
+	entry.IndexTime = indexTime(dirs)
+	return entry
+}
+
+// Invalidate makes the cache ask git again whether the worktree at path is
+// dirty, for callers that learned it may have changed, such as a Watcher
+func (c *StatusCache) Invalidate(path string) {
+	prefix := statusKeyDir(path) + string(filepath.Separator)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			entry.CheckedAt = time.Time{}
+			c.entries[key] = entry
+		}
+	}
+}
+
+func (c *StatusCache) get(key string) (statusEntry, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok || c.Dir == "" {
+		return entry, ok
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return statusEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *StatusCache) put(key string, entry statusEntry) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]statusEntry{}
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	if c.Dir == "" {
+		return
+	}
+
+	// The cache only saves time, so failing to write it is fine
+	path := filepath.Join(c.Dir, key)
+	data, err := json.Marshal(entry)
+	if err != nil || os.MkdirAll(filepath.Dir(path), 0755) != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0600) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
+
+// statusKey names the entry of the worktree at path and base, as a path
+// relative to the cache directory
+func statusKey(path, base string) string {
+	sum := sha256.Sum256([]byte(base))
+	return filepath.Join(statusKeyDir(path), hex.EncodeToString(sum[:8])+".json")
+}
+
+// statusKeyDir names the directory of the entries of the worktree at path
+func statusKeyDir(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// indexTime returns when the index of the worktree was last written
+func indexTime(dirs GitDirs) time.Time {
+	stat, err := os.Stat(filepath.Join(dirs.GitDir, "index"))
+	if err != nil {
+		return time.Time{}
+	}
+	return stat.ModTime()
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatusCache(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "README.md", "readme\n")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gitIn(t, repo, "worktree", "add", "-b", "feature", worktree)
+	commitIn(t, worktree, "feature.txt", "feature\n")
+
+	dir := t.TempDir()
+	cache := &StatusCache{Dir: dir, MaxAge: time.Hour}
+	status, err := cache.WorktreeStatus(worktree, "main")
+	if err != nil || status != (WorktreeStatus{Ahead: 1}) {
+		t.Fatalf("WorktreeStatus() = %+v, %v, expected 1 ahead", status, err)
+	}
+
+	// Unstaged edits are not seen until MaxAge has passed
+	if err := os.WriteFile(filepath.Join(worktree, "README.md"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := cache.WorktreeStatus(worktree, "main"); status.Dirty {
+		t.Errorf("WorktreeStatus() within MaxAge = %+v, expected the cached clean state", status)
+	}
+
+	// A new cache reads the entry from disk
+	onDisk := &StatusCache{Dir: dir, MaxAge: time.Hour}
+	if status, _ := onDisk.WorktreeStatus(worktree, "main"); status.Dirty || status.Ahead != 1 {
+		t.Errorf("WorktreeStatus() from disk = %+v, expected the cached state", status)
+	}
+	if status, _ := (&StatusCache{}).WorktreeStatus(worktree, "main"); !status.Dirty {
+		t.Errorf("WorktreeStatus() without MaxAge = %+v, expected dirty", status)
+	}
+
+	// Moving the base or HEAD invalidates the counts
+	commitIn(t, repo, "main.txt", "main\n")
+	if status, _ := cache.WorktreeStatus(worktree, "main"); status.Behind != 1 {
+		t.Errorf("WorktreeStatus() after main moved = %+v, expected 1 behind", status)
+	}
+	gitIn(t, worktree, "commit", "-qam", "Edit readme")
+	if status, _ := cache.WorktreeStatus(worktree, "main"); status != (WorktreeStatus{Ahead: 2, Behind: 1}) {
+		t.Errorf("WorktreeStatus() after committing = %+v, expected clean, 2 ahead and 1 behind", status)
+	}
+
+	// Bases git's files can't resolve go to git every time
+	if status, err := cache.WorktreeStatus(worktree, "main~1"); err != nil || status.Behind != 0 || status.Ahead != 2 {
+		t.Errorf("WorktreeStatus(main~1) = %+v, %v, expected 2 ahead", status, err)
+	}
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CoAuthorTrailer is the trailer that credits the other authors of a commit
+const CoAuthorTrailer = "Co-authored-by"
+
+// identPattern matches a person as git names authors, "Name <email>"
+var identPattern = regexp.MustCompile(`^[^<>]+ <[^<>\s]+>$`)
+
+// trailerPattern matches a line of a trailer block, e.g. "Signed-off-by: Jo"
+var trailerPattern = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+// IsIdent reports whether s names a person as git does, e.g.
+// "Jo Doe <jo@example.com>"
+func IsIdent(s string) bool {
+	return identPattern.MatchString(strings.TrimSpace(s))
+}
+
+// AddTrailers returns message with a "key: value" trailer for each of
+// values it doesn't already have, appended to its trailer block or in a new
+// one after a blank line
+func AddTrailers(message, key string, values []string) string {
+	message = strings.TrimRight(message, "\n")
+	lines := strings.Split(message, "\n")
+
+	have := make(map[string]bool)
+	for _, line := range lines {
+		have[strings.ToLower(strings.TrimSpace(line))] = true
+	}
+	var trailers []string
+	for _, value := range values {
+		trailer := key + ": " + strings.TrimSpace(value)
+		if !have[strings.ToLower(trailer)] {
+			have[strings.ToLower(trailer)] = true
+			trailers = append(trailers, trailer)
+		}
+	}
+	if len(trailers) == 0 {
+		return message
+	}
+
+	// The last paragraph is a trailer block if every line is a trailer,
+	// unless it is the subject
+	last := len(lines)
+	for last > 0 && lines[last-1] != "" {
+		last--
+	}
+	inBlock := last > 0
+	for _, line := range lines[last:] {
+		inBlock = inBlock && trailerPattern.MatchString(line)
+	}
+	if !inBlock {
+		message += "\n"
+	}
+	return message + "\n" + strings.Join(trailers, "\n")
+}
@@ -0,0 +1,65 @@
+package git
+
+import "testing"
+
+func TestAddTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		values  []string
+		want    string
+	}{
+		{
+			name:    "subject only",
+			message: "Fix login\n",
+			values:  []string{"Jo <jo@example.com>"},
+			want:    "Fix login\n\nCo-authored-by: Jo <jo@example.com>",
+		},
+		{
+			name:    "body",
+			message: "Fix login\n\nThe redirect lost the session.",
+			values:  []string{"Jo <jo@example.com>", "Al <al@example.com>"},
+			want:    "Fix login\n\nThe redirect lost the session.\n\nCo-authored-by: Jo <jo@example.com>\nCo-authored-by: Al <al@example.com>",
+		},
+		{
+			name:    "existing trailer block",
+			message: "Fix login\n\nSigned-off-by: Al <al@example.com>",
+			values:  []string{"Jo <jo@example.com>"},
+			want:    "Fix login\n\nSigned-off-by: Al <al@example.com>\nCo-authored-by: Jo <jo@example.com>",
+		},
+		{
+			name:    "already credited",
+			message: "Fix login\n\nco-authored-by: Jo <jo@example.com>",
+			values:  []string{"Jo <jo@example.com>", "Jo <jo@example.com>"},
+			want:    "Fix login\n\nco-authored-by: Jo <jo@example.com>",
+		},
+		{
+			name:    "conventional subject",
+			message: "fix: login",
+			values:  []string{"Jo <jo@example.com>"},
+			want:    "fix: login\n\nCo-authored-by: Jo <jo@example.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddTrailers(tt.message, CoAuthorTrailer, tt.values); got != tt.want {
+				t.Errorf("AddTrailers() = %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsIdent(t *testing.T) {
+	for s, want := range map[string]bool{
+		"Jo Doe <jo@example.com>": true,
+		"Pat <pat@example.com>":   true,
+		"jo@example.com":          false,
+		"<jo@example.com>":        false,
+		"Jo <jo@example.com":      false,
+	} {
+		if got := IsIdent(s); got != want {
+			t.Errorf("IsIdent(%q) = %v, expected %v", s, got, want)
+		}
+	}
+}
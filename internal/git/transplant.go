@@ -0,0 +1,112 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UncommittedPatch returns the changes in the worktree at dir that are not
+// committed, staged or not, as a binary patch against HEAD. Untracked files
+// that are not ignored are included. The worktree and its index are left as
+// they are: the changes are staged in a temporary index.
+func UncommittedPatch(dir string) ([]byte, error) {
+	var patch []byte
+	err := withWorktreeIndex(dir, func(env []string) error {
+		result, err := DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: []string{"diff", "--cached", "--binary", "HEAD"}, Env: env})
+		if err != nil {
+			return fmt.Errorf("failed to collect changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+		patch = result.Stdout
+		return nil
+	})
+	return patch, err
+}
+
+// UncommittedDiff returns the changes in the worktree at dir since base,
+// uncommitted ones included, as a text diff that only names binary files.
+// With base HEAD, it is what a commit of everything would hold.
+func UncommittedDiff(dir, base string) (string, error) {
+	var diff string
+	err := withWorktreeIndex(dir, func(env []string) error {
+		result, err := DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: []string{"diff", "--cached", "--no-color", "--no-ext-diff", base}, Env: env})
+		if err != nil {
+			return fmt.Errorf("failed to collect changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+		diff = string(result.Stdout)
+		return nil
+	})
+	return diff, err
+}
+
+// withWorktreeIndex stages everything in the worktree at dir, including
+// untracked files that are not ignored, in a temporary index, and calls fn
+// with the environment that makes git use it. The worktree's own index is
+// not touched.
+func withWorktreeIndex(dir string, fn func(env []string) error) error {
+	tmp, err := os.MkdirTemp("", "ccswitch-index-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "-A"}} {
+		if result, err := DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: args, Env: env}); err != nil {
+			return fmt.Errorf("failed to collect changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+	}
+	return fn(env)
+}
+
+// ApplyPatch applies patch to the worktree at dir. A patch that does not
+// apply cleanly is applied with a 3-way merge instead, which stages the
+// files that merged and leaves conflict markers in the others; those are
+// returned. The worktree is left as it was if neither works.
+func ApplyPatch(dir string, patch []byte) ([]string, error) {
+	apply := func(args ...string) (Result, error) {
+		args = append([]string{"apply", "--binary"}, args...)
+		return DefaultRunner.Run(context.Background(), Command{Dir: dir, Args: args, Stdin: bytes.NewReader(patch)})
+	}
+
+	if _, err := apply("--check"); err == nil {
+		if result, err := apply(); err != nil {
+			return nil, fmt.Errorf("failed to apply changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+		return nil, nil
+	}
+
+	result, err := apply("--3way")
+	if err == nil {
+		return nil, nil
+	}
+	conflicted, listErr := ConflictedFiles(dir)
+	if listErr != nil || len(conflicted) == 0 {
+		return nil, fmt.Errorf("failed to apply changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return conflicted, nil
+}
+
+// ConflictedFiles returns the files of the worktree at dir with unresolved
+// merge conflicts
+func ConflictedFiles(dir string) ([]string, error) {
+	result, err := run(dir, "status", "--porcelain=v2", "-z", "--untracked-files=no")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %w", err)
+	}
+	return ParseUnmergedZ(string(result.Stdout)), nil
+}
+
+// DiscardChanges throws away the uncommitted changes of the worktree at dir,
+// including untracked files that are not ignored
+func DiscardChanges(dir string) error {
+	for _, args := range [][]string{{"reset", "--hard", "HEAD"}, {"clean", "-fd"}} {
+		if result, err := run(dir, args...); err != nil {
+			return fmt.Errorf("failed to discard changes: %w, output: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+	}
+	return nil
+}
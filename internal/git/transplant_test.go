@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTransplantChanges(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "my app.txt", "one\ntwo\nthree\n")
+
+	source := filepath.Join(root, "source")
+	target := filepath.Join(root, "target")
+	gitIn(t, repo, "worktree", "add", "-b", "source", source)
+	gitIn(t, repo, "worktree", "add", "-b", "target", target)
+
+	write := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write(source, "my app.txt", "one\ntwo\nthree\nfour\n")
+	write(source, "new.txt", "untracked\n")
+
+	patch, err := UncommittedPatch(source)
+	if err != nil {
+		t.Fatalf("UncommittedPatch() failed: %v", err)
+	}
+	if status, _ := run(source, "status", "--porcelain", "-z"); string(status.Stdout) != " M my app.txt\x00?? new.txt\x00" {
+		t.Errorf("UncommittedPatch() changed the source index: %q", status.Stdout)
+	}
+
+	conflicted, err := ApplyPatch(target, patch)
+	if err != nil || conflicted != nil {
+		t.Fatalf("ApplyPatch() = %v, %v", conflicted, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "new.txt")); string(data) != "untracked\n" {
+		t.Errorf("new.txt in target = %q", data)
+	}
+
+	// A target that changed the same lines gets a 3-way merge, and the
+	// conflicted path keeps its space
+	gitIn(t, target, "checkout", "--", "my app.txt")
+	gitIn(t, target, "clean", "-fd")
+	commitIn(t, target, "my app.txt", "one\ntwo\nthree\nFOUR\n")
+	conflicted, err = ApplyPatch(target, patch)
+	if err != nil {
+		t.Fatalf("ApplyPatch() with a conflict failed: %v", err)
+	}
+	if !reflect.DeepEqual(conflicted, []string{"my app.txt"}) {
+		t.Errorf("ApplyPatch() conflicted = %v", conflicted)
+	}
+
+	if err := DiscardChanges(source); err != nil {
+		t.Fatalf("DiscardChanges() failed: %v", err)
+	}
+	if HasUncommittedChanges(source) {
+		t.Error("DiscardChanges() left changes behind")
+	}
+}
@@ -1,15 +1,63 @@
 package git
 
+import "time"
+
 // Worktree represents a git worktree
 type Worktree struct {
 	Path   string
 	Branch string
 	Commit string
+	// Locked is set for worktrees locked with git worktree lock, which
+	// git will not prune or remove
+	Locked     bool
+	LockReason string
+	// Detached is set for worktrees with a detached HEAD, which have no
+	// branch checked out
+	Detached bool
 }
 
 // SessionInfo represents information about a ccswitch session
 type SessionInfo struct {
-	Name   string
+	Name string
+	// Branch is empty for detached sessions, pinned to a tag or commit
 	Branch string
 	Path   string
 }
+
+// DetachedLabel stands for the branch of detached sessions in listings
+const DetachedLabel = "detached HEAD"
+
+// Detached reports whether the session has a detached HEAD instead of a
+// branch checked out
+func (s SessionInfo) Detached() bool {
+	return s.Branch == ""
+}
+
+// BranchLabel returns the session's branch, or DetachedLabel for detached
+// sessions
+func (s SessionInfo) BranchLabel() string {
+	return BranchLabel(s.Branch)
+}
+
+// BranchLabel returns branch, or DetachedLabel if a session has none
+func BranchLabel(branch string) string {
+	if branch == "" {
+		return DetachedLabel
+	}
+	return branch
+}
+
+// Commit represents a single commit in a branch's history
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Subject string
+}
+
+// WorktreeStatus summarises the state of a worktree relative to a base branch
+type WorktreeStatus struct {
+	Dirty  bool
+	Ahead  int
+	Behind int
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version returns the version of the installed git, e.g. "2.43.0"
+func Version() (string, error) {
+	result, err := run("", "version")
+	if err != nil {
+		return "", fmt.Errorf("failed to run git version: %w", err)
+	}
+	fields := strings.Fields(string(result.Stdout))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected git version output: %s", strings.TrimSpace(string(result.Stdout)))
+	}
+	return fields[2], nil
+}
+
+// CompareVersions compares dotted version numbers, returning -1, 0 or 1 as
+// a is older than, the same as or newer than b. Suffixes such as
+// ".windows.1" or "-rc0" are ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < 3; i++ {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the major, minor and patch numbers of a version
+func versionParts(version string) [3]int {
+	var parts [3]int
+	for i, field := range strings.SplitN(version, ".", 4) {
+		if i == 3 {
+			break
+		}
+		end := strings.IndexFunc(field, func(r rune) bool { return r < '0' || r > '9' })
+		if end >= 0 {
+			field = field[:end]
+		}
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
@@ -0,0 +1,32 @@
+package git
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.43.0", "2.31.0", 1},
+		{"2.31.0", "2.31.0", 0},
+		{"2.30.9", "2.31.0", -1},
+		{"2.31", "2.31.0", 0},
+		{"2.45.1.windows.1", "2.45.1", 0},
+		{"2.46.0-rc0", "2.45.2", 1},
+		{"1.9.5", "2.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("CompareVersions(%s, %s) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+
+	version, err := Version()
+	if err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	if CompareVersions(version, "1.0.0") <= 0 {
+		t.Errorf("Version() = %s, expected a real git version", version)
+	}
+}
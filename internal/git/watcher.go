@@ -0,0 +1,182 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a Watcher collects changes before reporting
+// them, since a single git command writes several files
+const watchDebounce = 100 * time.Millisecond
+
+// Watcher reports changes to the git state of worktrees as they happen:
+// their HEAD and index, and the refs and worktree list of their
+// repository. Edits to files that are not staged are not seen.
+type Watcher struct {
+	// Changes receives the top levels of the worktrees whose git state
+	// changed, a batch at a time
+	Changes chan []string
+
+	fs    *fsnotify.Watcher
+	cache *StatusCache
+
+	mu sync.Mutex
+	// worktrees maps each watched directory to the worktrees a change in
+	// it affects
+	worktrees map[string]map[string]bool
+	// owners maps the git directory of each watched worktree to its top
+	// level, as the main worktree's is also shared by all worktrees
+	owners map[string]string
+	done   chan struct{}
+}
+
+// NewWatcher starts a watcher that invalidates what cache knows about the
+// worktrees that change; cache may be nil
+func NewWatcher(cache *StatusCache) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		Changes:   make(chan []string, 1),
+		fs:        fsw,
+		cache:     cache,
+		worktrees: map[string]map[string]bool{},
+		owners:    map[string]string{},
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Add watches the worktree containing path; adding it again does nothing
+func (w *Watcher) Add(path string) error {
+	dirs, ok := FindGitDirs(path)
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	// The worktree's own HEAD and index, and what all worktrees of the
+	// repository share: packed-refs, the list of worktrees and the refs
+	watch := []string{dirs.GitDir, dirs.CommonDir, filepath.Join(dirs.CommonDir, "worktrees")}
+	for _, refs := range []string{"heads", "remotes", "tags"} {
+		_ = filepath.WalkDir(filepath.Join(dirs.CommonDir, "refs", refs), func(dir string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				watch = append(watch, dir)
+			}
+			return nil
+		})
+	}
+	w.mu.Lock()
+	w.owners[dirs.GitDir] = dirs.Path
+	w.mu.Unlock()
+	for _, dir := range watch {
+		if err := w.watchDir(dir, dirs.Path); err != nil && dir == dirs.GitDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchDir watches dir for changes affecting the worktree at path
+func (w *Watcher) watchDir(dir, path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.worktrees[dir] == nil {
+		if err := w.fs.Add(dir); err != nil {
+			return err
+		}
+		w.worktrees[dir] = map[string]bool{}
+	}
+	w.worktrees[dir][path] = true
+	return nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fs.Close()
+}
+
+// run collects the worktrees affected by file events and reports them
+// once they have been quiet for watchDebounce
+func (w *Watcher) run() {
+	changed := map[string]bool{}
+	var flush <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			for _, path := range w.affected(event) {
+				changed[path] = true
+			}
+			if len(changed) > 0 {
+				flush = time.After(watchDebounce)
+			}
+
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+
+		case <-flush:
+			flush = nil
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				if w.cache != nil {
+					w.cache.Invalidate(path)
+				}
+				paths = append(paths, path)
+			}
+			changed = map[string]bool{}
+			select {
+			case w.Changes <- paths:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// affected returns the worktrees event affects, watching directories
+// created under refs, such as that of a first feature/ branch
+func (w *Watcher) affected(event fsnotify.Event) []string {
+	// Lock files come and go around every write
+	if strings.HasSuffix(event.Name, ".lock") {
+		return nil
+	}
+	dir := filepath.Dir(event.Name)
+
+	w.mu.Lock()
+	var paths []string
+	if owner, ok := w.owners[dir]; ok && filepath.Base(event.Name) != "packed-refs" {
+		// Only packed-refs in a git directory is shared with other worktrees
+		paths = []string{owner}
+	} else {
+		for path := range w.worktrees[dir] {
+			paths = append(paths, path)
+		}
+	}
+	w.mu.Unlock()
+
+	if event.Has(fsnotify.Create) && strings.Contains(filepath.ToSlash(event.Name), "/refs/") {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			for _, path := range paths {
+				_ = w.watchDir(event.Name, path)
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "README.md", "readme\n")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gitIn(t, repo, "worktree", "add", "-b", "feature", worktree)
+	dirs, _ := FindGitDirs(worktree)
+
+	cache := &StatusCache{MaxAge: time.Hour}
+	if _, err := cache.WorktreeStatus(worktree, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "README.md"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(cache)
+	if err != nil {
+		t.Skipf("file watching unavailable: %v", err)
+	}
+	defer w.Close()
+	for _, path := range []string{repo, worktree} {
+		if err := w.Add(path); err != nil {
+			t.Fatalf("Add(%s) failed: %v", path, err)
+		}
+	}
+
+	// Staging the edit reports the worktree alone and drops its cached state
+	gitIn(t, worktree, "add", "README.md")
+	select {
+	case paths := <-w.Changes:
+		if len(paths) != 1 || paths[0] != dirs.Path {
+			t.Errorf("Changes = %v, expected only %s", paths, dirs.Path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported after staging")
+	}
+	if status, _ := cache.WorktreeStatus(worktree, "main"); !status.Dirty {
+		t.Errorf("WorktreeStatus() after staging = %+v, expected dirty", status)
+	}
+
+	// A branch created under a new directory of refs is seen too
+	gitIn(t, repo, "branch", "fix/typo")
+	drain(w.Changes)
+	gitIn(t, repo, "update-ref", "refs/heads/fix/other", "HEAD")
+	select {
+	case <-w.Changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported for a branch in a new refs directory")
+	}
+}
+
+// drain waits for changes to settle and discards them
+func drain(changes chan []string) {
+	for {
+		select {
+		case <-changes:
+		case <-time.After(3 * watchDebounce):
+			return
+		}
+	}
+}
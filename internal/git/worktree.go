@@ -2,15 +2,18 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/ksred/ccswitch/internal/utils"
 )
 
 // WorktreeManager handles git worktree operations
 type WorktreeManager struct {
 	repoPath string
+	// Runner runs git; nil means DefaultRunner
+	Runner GitRunner
 }
 
 // NewWorktreeManager creates a new WorktreeManager
@@ -20,34 +23,93 @@ func NewWorktreeManager(repoPath string) *WorktreeManager {
 
 // Create creates a new worktree
 func (wm *WorktreeManager) Create(path, branch string) error {
-	cmd := exec.Command("git", "worktree", "add", path, branch)
-	cmd.Dir = wm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := wm.run("worktree", "add", path, branch)
 	if err != nil {
-		return fmt.Errorf("failed to create worktree: %w, output: %s", err, string(output))
+		return fmt.Errorf("failed to create worktree: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// CreateDetached creates a worktree with a detached HEAD at commit, e.g. to
+// reproduce a release from its tag
+func (wm *WorktreeManager) CreateDetached(path, commit string) error {
+	result, err := wm.run("worktree", "add", "--detach", path, commit)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// CreateSparse creates a new worktree that checks out only paths, plus the
+// files at the top level, using sparse-checkout with a sparse index. Nothing
+// outside them is written to disk, not even temporarily. The sparse-checkout
+// settings apply to this worktree only.
+func (wm *WorktreeManager) CreateSparse(path, branch string, paths []string) error {
+	if result, err := wm.run("worktree", "add", "--no-checkout", path, branch); err != nil {
+		return fmt.Errorf("failed to create worktree: %w, output: %s", err, string(result.Combined))
+	}
+
+	steps := [][]string{
+		append([]string{"sparse-checkout", "set", "--sparse-index"}, paths...),
+		{"checkout", branch},
+	}
+	for _, args := range steps {
+		if result, err := runWith(wm.Runner, path, args...); err != nil {
+			_, _ = runCleanup(wm.Runner, wm.repoPath, "worktree", "remove", path, "--force")
+			return fmt.Errorf("failed to set up sparse checkout: %w, output: %s", err, string(result.Combined))
+		}
 	}
 	return nil
 }
 
 // List returns all worktrees
 func (wm *WorktreeManager) List() ([]Worktree, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = wm.repoPath
-	output, err := cmd.CombinedOutput()
+	result, err := wm.run("worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
-	return ParseWorktrees(string(output)), nil
+	return ParseWorktrees(string(result.Stdout)), nil
 }
 
 // Remove removes a worktree
 func (wm *WorktreeManager) Remove(path string) error {
-	cmd := exec.Command("git", "worktree", "remove", path, "--force")
-	cmd.Dir = wm.repoPath
-	_, err := cmd.CombinedOutput()
+	_, err := wm.run("worktree", "remove", path, "--force")
 	return err
 }
 
+// Prune removes the registrations of worktrees whose directories no longer
+// exist, except locked ones
+func (wm *WorktreeManager) Prune() error {
+	result, err := wm.run("worktree", "prune")
+	if err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// Unlock unlocks a worktree locked with git worktree lock
+func (wm *WorktreeManager) Unlock(path string) error {
+	result, err := wm.run("worktree", "unlock", path)
+	if err != nil {
+		return fmt.Errorf("failed to unlock worktree: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// Move relocates a worktree to a new path
+func (wm *WorktreeManager) Move(oldPath, newPath string) error {
+	result, err := wm.run("worktree", "move", oldPath, newPath)
+	if err != nil {
+		return fmt.Errorf("failed to move worktree: %w, output: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// run runs git with args in the repository
+func (wm *WorktreeManager) run(args ...string) (Result, error) {
+	return runWith(wm.Runner, wm.repoPath, args...)
+}
+
 // ParseWorktrees parses git worktree list --porcelain output
 func ParseWorktrees(output string) []Worktree {
 	var worktrees []Worktree
@@ -72,6 +134,11 @@ func ParseWorktrees(output string) []Worktree {
 			currentWorktree.Branch = matches[1]
 		} else if strings.HasPrefix(line, "HEAD ") {
 			currentWorktree.Commit = strings.TrimPrefix(line, "HEAD ")
+		} else if line == "detached" {
+			currentWorktree.Detached = true
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			currentWorktree.Locked = true
+			currentWorktree.LockReason = strings.TrimPrefix(strings.TrimPrefix(line, "locked"), " ")
 		}
 	}
 
@@ -82,6 +149,12 @@ func ParseWorktrees(output string) []Worktree {
 	return worktrees
 }
 
+// HasHead reports whether the worktree has a branch or a detached HEAD
+// checked out, unlike bare repositories
+func (wt Worktree) HasHead() bool {
+	return wt.Branch != "" || wt.Detached
+}
+
 // GetSessionsFromWorktrees extracts session information from worktrees
 func GetSessionsFromWorktrees(worktrees []Worktree, repoName string) []SessionInfo {
 	var sessions []SessionInfo
@@ -89,7 +162,7 @@ func GetSessionsFromWorktrees(worktrees []Worktree, repoName string) []SessionIn
 	// First, find and add the main repository
 	for _, wt := range worktrees {
 		// Check if this is the main worktree (not in .ccswitch directory)
-		if !strings.Contains(wt.Path, ".ccswitch") && wt.Branch != "" {
+		if !strings.Contains(wt.Path, ".ccswitch") && wt.HasHead() {
 			// This is likely the main repository
 			sessions = append(sessions, SessionInfo{
 				Name:   "main",
@@ -105,14 +178,14 @@ func GetSessionsFromWorktrees(worktrees []Worktree, repoName string) []SessionIn
 	for _, wt := range worktrees {
 		// Check if it's a ccswitch worktree and extract the repo name from path
 		// Use both / and \ for cross-platform compatibility
-		if (strings.Contains(wt.Path, ".ccswitch/worktrees/") || strings.Contains(wt.Path, ".ccswitch\\worktrees\\")) && wt.Branch != "" {
+		if (strings.Contains(wt.Path, ".ccswitch/worktrees/") || strings.Contains(wt.Path, ".ccswitch\\worktrees\\")) && wt.HasHead() {
 			// Normalize path separators to / for consistent parsing
 			normalizedPath := strings.ReplaceAll(wt.Path, "\\", "/")
 			parts := strings.Split(normalizedPath, "/")
 			for i, part := range parts {
 				if part == ".ccswitch" && i+2 < len(parts) && parts[i+1] == "worktrees" {
 					if i+2 < len(parts) && parts[i+2] == repoName {
-						sessionName := filepath.Base(wt.Path)
+						sessionName := utils.NameFromPath(filepath.Base(wt.Path))
 						sessions = append(sessions, SessionInfo{
 							Name:   sessionName,
 							Branch: wt.Branch,
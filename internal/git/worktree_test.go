@@ -51,7 +51,29 @@ HEAD abc123def
 detached
 `,
 			expected: []Worktree{
-				{Path: "/home/user/project", Branch: "", Commit: "abc123def"},
+				{Path: "/home/user/project", Branch: "", Commit: "abc123def", Detached: true},
+			},
+		},
+		{
+			name: "locked worktrees",
+			input: `worktree /home/user/project
+HEAD abc123
+branch refs/heads/main
+
+worktree /mnt/usb/feature1
+HEAD def456
+branch refs/heads/feature1
+locked on removable drive
+
+worktree /home/user/.ccswitch/worktrees/project/feature2
+HEAD ghi789
+branch refs/heads/feature2
+locked
+`,
+			expected: []Worktree{
+				{Path: "/home/user/project", Branch: "main", Commit: "abc123"},
+				{Path: "/mnt/usb/feature1", Branch: "feature1", Commit: "def456", Locked: true, LockReason: "on removable drive"},
+				{Path: "/home/user/.ccswitch/worktrees/project/feature2", Branch: "feature2", Commit: "ghi789", Locked: true},
 			},
 		},
 		{
@@ -78,6 +100,9 @@ detached
 				if result[i].Commit != tt.expected[i].Commit {
 					t.Errorf("Worktree[%d].Commit = %s, expected %s", i, result[i].Commit, tt.expected[i].Commit)
 				}
+				if result[i].Locked != tt.expected[i].Locked || result[i].LockReason != tt.expected[i].LockReason {
+					t.Errorf("Worktree[%d] locked = %v %q, expected %v %q", i, result[i].Locked, result[i].LockReason, tt.expected[i].Locked, tt.expected[i].LockReason)
+				}
 			}
 		})
 	}
@@ -325,6 +350,18 @@ func TestGetSessionsFromWorktrees(t *testing.T) {
 				{Name: "feature", Branch: "feature/test", Path: "/home/user/.ccswitch/worktrees/myrepo/feature"},
 			},
 		},
+		{
+			name: "detached session pinned to a tag",
+			worktrees: []Worktree{
+				{Path: "/home/user/myrepo", Branch: "main", Commit: "abc123"},
+				{Path: "/home/user/.ccswitch/worktrees/myrepo/release", Commit: "def456", Detached: true},
+			},
+			repoName: "myrepo",
+			expected: []SessionInfo{
+				{Name: "main", Branch: "main", Path: "/home/user/myrepo"},
+				{Name: "release", Branch: "", Path: "/home/user/.ccswitch/worktrees/myrepo/release"},
+			},
+		},
 		{
 			name: "only ccswitch worktrees (no main repo)",
 			worktrees: []Worktree{
@@ -379,3 +416,45 @@ func TestGetSessionsFromWorktrees(t *testing.T) {
 		})
 	}
 }
+
+func TestWorktreeManagerCreateSparse(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, "services", "api"), 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	commitIn(t, repo, "README.md", "readme\n")
+	commitIn(t, repo, "services/api/main.go", "package main\n")
+	if err := os.MkdirAll(filepath.Join(repo, "web"), 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+	commitIn(t, repo, "web/index.html", "<html>\n")
+	gitIn(t, repo, "branch", "feature")
+
+	worktree := filepath.Join(root, "worktree")
+	wm := NewWorktreeManager(repo)
+	if err := wm.CreateSparse(worktree, "feature", []string{"services/api"}); err != nil {
+		t.Fatalf("CreateSparse() failed: %v", err)
+	}
+
+	for name, expected := range map[string]bool{"README.md": true, "services/api/main.go": true, "web/index.html": false} {
+		if _, err := os.Stat(filepath.Join(worktree, name)); (err == nil) != expected {
+			t.Errorf("%s present = %v, expected %v", name, err == nil, expected)
+		}
+	}
+	if HasUncommittedChanges(worktree) {
+		t.Error("sparse worktree should be clean")
+	}
+
+	// The main worktree keeps its full checkout
+	if _, err := os.Stat(filepath.Join(repo, "web", "index.html")); err != nil {
+		t.Errorf("main worktree lost files: %v", err)
+	}
+
+	if err := wm.CreateSparse(filepath.Join(root, "missing"), "no-such-branch", []string{"web"}); err == nil {
+		t.Error("CreateSparse() of a missing branch should fail")
+	}
+}
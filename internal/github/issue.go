@@ -0,0 +1,134 @@
+// Package github reads issues of the GitHub repository a checkout was
+// cloned from, with the gh CLI when it is installed and the REST API
+// otherwise.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// Timeout bounds fetching an issue
+const Timeout = 15 * time.Second
+
+// APIURL is the GitHub REST API the issue is read from without gh
+var APIURL = "https://api.github.com"
+
+// Issue is a GitHub issue
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"html_url"`
+}
+
+// Ref returns how commits and pull requests refer to the issue, e.g. #123
+func (i Issue) Ref() string {
+	return "#" + strconv.Itoa(i.Number)
+}
+
+// ParseRef returns the number of an issue referred to as #123 or 123
+func ParseRef(ref string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	return n, err == nil && n > 0
+}
+
+// FetchIssue reads issue number of the repository at dir
+func FetchIssue(dir string, number int) (*Issue, error) {
+	if _, err := exec.LookPath("gh"); err == nil {
+		return fetchWithGH(dir, number)
+	}
+
+	remote, err := git.GetConfig(dir, "remote.origin.url")
+	if err != nil || remote == "" {
+		return nil, fmt.Errorf("cannot tell which GitHub repository issue #%d belongs to: no origin remote", number)
+	}
+	owner, repo, ok := ParseRemote(remote)
+	if !ok {
+		return nil, fmt.Errorf("origin %s is not a GitHub repository", remote)
+	}
+	return fetchWithAPI(owner, repo, number, os.Getenv("GITHUB_TOKEN"))
+}
+
+// fetchWithGH reads the issue with the gh CLI, which knows the repository
+// and the user's credentials
+func fetchWithGH(dir string, number int) (*Issue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gh", "issue", "view", strconv.Itoa(number), "--json", "number,title,url")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("gh issue view %d failed: %v: %s", number, err, stderr)
+	}
+
+	var result struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		URL    string `json:"url"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to read gh output: %w", err)
+	}
+	return &Issue{Number: result.Number, Title: result.Title, URL: result.URL}, nil
+}
+
+// fetchWithAPI reads the issue from the REST API, authenticating with token
+// if it is set
+func fetchWithAPI(owner, repo string, number int, token string) (*Issue, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", strings.TrimRight(APIURL, "/"), owner, repo, number)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("issue #%d of %s/%s: GitHub returned %s: %s", number, owner, repo, resp.Status, strings.TrimSpace(string(reply)))
+	}
+
+	var issue Issue
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return nil, fmt.Errorf("failed to read issue #%d: %w", number, err)
+	}
+	return &issue, nil
+}
+
+// remotePattern matches the owner and repository of GitHub remotes in the
+// https, ssh and scp-like forms
+var remotePattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemote returns the owner and repository of a GitHub remote URL
+func ParseRemote(remote string) (string, string, bool) {
+	m := remotePattern.FindStringSubmatch(strings.TrimSpace(remote))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
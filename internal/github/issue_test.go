@@ -0,0 +1,69 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		owner  string
+		repo   string
+		ok     bool
+	}{
+		{"https://github.com/ksred/ccswitch.git", "ksred", "ccswitch", true},
+		{"https://github.com/ksred/ccswitch", "ksred", "ccswitch", true},
+		{"git@github.com:ksred/ccswitch.git", "ksred", "ccswitch", true},
+		{"ssh://git@github.com/ksred/ccswitch.git", "ksred", "ccswitch", true},
+		{"https://gitlab.com/ksred/ccswitch.git", "", "", false},
+	}
+
+	for _, tt := range tests {
+		owner, repo, ok := ParseRemote(tt.remote)
+		if owner != tt.owner || repo != tt.repo || ok != tt.ok {
+			t.Errorf("ParseRemote(%q) = %q, %q, %v", tt.remote, owner, repo, ok)
+		}
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	for ref, expected := range map[string]int{"#123": 123, "42": 42, "JIRA-1": 0, "#0": 0, "": 0} {
+		n, ok := ParseRef(ref)
+		if n != expected && ok || ok != (expected > 0) {
+			t.Errorf("ParseRef(%q) = %d, %v; expected %d", ref, n, ok, expected)
+		}
+	}
+}
+
+func TestFetchWithAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/ksred/ccswitch/issues/7" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{"number": 7, "title": "Fix login redirect", "html_url": "https://github.com/ksred/ccswitch/issues/7"}`)
+	}))
+	defer server.Close()
+
+	old := APIURL
+	APIURL = server.URL
+	defer func() { APIURL = old }()
+
+	issue, err := fetchWithAPI("ksred", "ccswitch", 7, "secret")
+	if err != nil {
+		t.Fatalf("fetchWithAPI() failed: %v", err)
+	}
+	if issue.Title != "Fix login redirect" || issue.Ref() != "#7" || issue.URL != "https://github.com/ksred/ccswitch/issues/7" {
+		t.Errorf("fetchWithAPI() = %+v", issue)
+	}
+
+	if _, err := fetchWithAPI("ksred", "ccswitch", 8, ""); err == nil {
+		t.Error("fetchWithAPI() of a missing issue should fail")
+	}
+}
@@ -0,0 +1,109 @@
+// Package i18n translates ccswitch's own messages. Messages are looked up
+// by their English text, so untranslated ones are shown as written and
+// English needs no catalog. Output of git and other tools is not
+// translated.
+package i18n
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// English and Chinese are the languages messages are available in
+const (
+	English = "en"
+	Chinese = "zh"
+)
+
+// catalogs holds the translations of each language other than English,
+// keyed by the English message
+var catalogs = map[string]map[string]string{
+	Chinese: zh,
+}
+
+// current is the language messages are shown in
+var current atomic.Value
+
+func init() {
+	current.Store(Detect(""))
+}
+
+// Languages returns the languages messages can be shown in
+func Languages() []string {
+	return []string{English, Chinese}
+}
+
+// Supported reports whether lang is one of Languages
+func Supported(lang string) bool {
+	return lang == English || catalogs[lang] != nil
+}
+
+// Detect returns the language setting chooses, or if it is empty or
+// "auto", the language of the locale in LC_ALL, LC_MESSAGES or LANG.
+// Languages without translations fall back to English.
+func Detect(setting string) string {
+	if setting != "" && setting != "auto" {
+		return normalize(setting)
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return normalize(locale)
+		}
+	}
+	return English
+}
+
+// normalize turns a locale such as "zh_CN.UTF-8" into a supported
+// language
+func normalize(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if Supported(lang) {
+		return lang
+	}
+	return English
+}
+
+// SetLanguage shows messages in lang from now on; unsupported languages
+// show them in English
+func SetLanguage(lang string) {
+	current.Store(normalize(lang))
+}
+
+// Language returns the language messages are shown in
+func Language() string {
+	return current.Load().(string)
+}
+
+// T returns message in the current language. A message that starts with a
+// marker such as "✓ " or "⚠ " is looked up without it, so one translation
+// serves however the message is decorated.
+func T(message string) string {
+	catalog := catalogs[Language()]
+	if catalog == nil {
+		return message
+	}
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	prefix, rest := splitMarker(message)
+	if translated, ok := catalog[rest]; ok && prefix != "" {
+		return prefix + translated
+	}
+	return message
+}
+
+// splitMarker splits the indentation and symbols that start message, such
+// as "  ✓ ", from its text
+func splitMarker(message string) (string, string) {
+	for i, r := range message {
+		if r != ' ' && r != '•' && !unicode.IsSymbol(r) && !unicode.Is(unicode.Mn, r) {
+			return message[:i], message[i:]
+		}
+	}
+	return message, ""
+}
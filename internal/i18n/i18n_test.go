@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting string
+		env     map[string]string
+		want    string
+	}{
+		{"setting", "zh", map[string]string{"LANG": "en_US.UTF-8"}, Chinese},
+		{"setting with region", "zh_CN", nil, Chinese},
+		{"auto follows LANG", "auto", map[string]string{"LANG": "zh_CN.UTF-8"}, Chinese},
+		{"LC_ALL wins", "", map[string]string{"LC_ALL": "en_GB.UTF-8", "LANG": "zh_CN.UTF-8"}, English},
+		{"LC_MESSAGES before LANG", "", map[string]string{"LC_MESSAGES": "zh_TW", "LANG": "en_US"}, Chinese},
+		{"untranslated language", "", map[string]string{"LANG": "de_DE.UTF-8"}, English},
+		{"C locale", "", map[string]string{"LANG": "C"}, English},
+		{"no locale", "", nil, English},
+		{"unsupported setting", "fr", map[string]string{"LANG": "zh_CN"}, English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := Detect(tt.setting); got != tt.want {
+				t.Errorf("Detect(%q) = %q, expected %q", tt.setting, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(Language())
+
+	SetLanguage(English)
+	if got := T("No active sessions"); got != "No active sessions" {
+		t.Errorf("T in English = %q, expected the message unchanged", got)
+	}
+
+	SetLanguage(Chinese)
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"No active sessions", "没有活动的会话"},
+		{"✗ No active sessions", "✗ 没有活动的会话"},
+		{"  Branch: %s", "  分支：%s"},
+		{"⚠ Branch: %s", "⚠ 分支：%s"},
+		{"Not a translated message", "Not a translated message"},
+		{"✓ Not a translated message", "✓ Not a translated message"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := T(tt.message); got != tt.want {
+			t.Errorf("T(%q) = %q, expected %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsKeepVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for message, translated := range catalog {
+			if verbs(message) != verbs(translated) {
+				t.Errorf("%s translation of %q has verbs %q, expected %q", lang, message, verbs(translated), verbs(message))
+			}
+		}
+	}
+}
+
+// verbs returns the formatting verbs of a message in order
+func verbs(message string) string {
+	var out []byte
+	for i := 0; i < len(message)-1; i++ {
+		if message[i] == '%' {
+			out = append(out, message[i+1])
+			i++
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,96 @@
+package i18n
+
+// zh holds the Chinese (Simplified) translations
+var zh = map[string]string{
+	// Common results
+	"Failed: %v":                             "失败：%v",
+	"Failed to list sessions: %v":            "无法列出会话：%v",
+	"Failed to list worktrees: %v":           "无法列出工作树：%v",
+	"Failed to get current branch: %v":       "无法获取当前分支：%v",
+	"Failed to remove %s: %v":                "无法删除 %s：%v",
+	"Failed to write %s: %v":                 "无法写入 %s：%v",
+	"Not in a git repository":                "当前目录不在 git 仓库中",
+	"Session '%s' not found":                 "未找到会话 '%s'",
+	"Session not found: %s":                  "未找到会话：%s",
+	"Worktree '%s' not found":                "未找到工作树 '%s'",
+	"Available sessions:":                    "可用会话：",
+	"Available worktrees:":                   "可用工作树：",
+	"No active sessions":                     "没有活动的会话",
+	"No active sessions on %s":               "%s 上没有活动的会话",
+	"No matching sessions":                   "没有匹配的会话",
+	"No sessions selected":                   "未选择会话",
+	"  Tip: %s":                              "  提示：%s",
+	"Branch: %s":                             "分支：%s",
+	"  Branch: %s":                           "  分支：%s",
+	"Location: %s":                           "位置：%s",
+	"Tags: %s":                               "标签：%s",
+	"Issue: %s":                              "问题：%s",
+	"Expires: %s (%s)":                       "过期时间：%s（%s）",
+	"Note: Shell integration is not active.": "注意：Shell 集成未启用。",
+
+	// Creating and switching
+	"Created session: %s":                   "已创建会话：%s",
+	"Switched to session: %s":               "已切换到会话：%s",
+	"Switched to %s branch":                 "已切换到 %s 分支",
+	"Description cannot be empty":           "描述不能为空",
+	"Invalid --ttl: %v":                     "无效的 --ttl：%v",
+	"Imported session: %s":                  "已导入会话：%s",
+	"Import cancelled":                      "已取消导入",
+	"No previous session to switch back to": "没有可以切换回去的上一个会话",
+	"Recent sessions:":                      "最近使用的会话：",
+
+	// Cleanup
+	"Cleaned up session: %s":                           "已清理会话：%s",
+	"No active sessions to cleanup":                    "没有需要清理的活动会话",
+	"No worktree sessions to cleanup":                  "没有需要清理的工作树会话",
+	"No expired sessions to cleanup":                   "没有需要清理的过期会话",
+	"Expired sessions:":                                "已过期的会话：",
+	"You are about to remove the following worktrees:": "即将删除以下工作树：",
+	"Discarding uncommitted changes in %s:":            "将丢弃 %s 中未提交的更改：",
+	"%s has uncommitted changes:":                      "%s 有未提交的更改：",
+	"%s is pinned":                                     "%s 已固定",
+	"Keeping pinned sessions: %s":                      "保留已固定的会话：%s",
+	"Keeping protected branch %s":                      "保留受保护的分支 %s",
+	"Could not switch to main/master branch":           "无法切换到 main/master 分支",
+	"Summary":                                          "摘要",
+	"Total disk usage: %s":                             "总磁盘占用：%s",
+
+	// Rebasing
+	"Rebasing %s onto %s":             "正在将 %s 变基到 %s",
+	"Successfully rebased %s onto %s": "已成功将 %s 变基到 %s",
+	"Worktree preserved at: %s":       "工作树保留在：%s",
+	"Cannot rebase %s onto itself":    "无法将 %s 变基到自身",
+	"No worktrees found":              "未找到工作树",
+	"Committing changes...":           "正在提交更改……",
+	"Suggested commit message:":       "建议的提交信息：",
+	"Approved %s":                     "已批准 %s",
+	"Left %s untouched":               "未改动 %s",
+	"Fanout cancelled":                "已取消 fanout",
+
+	// Status and lists
+	"Sessions (compared to %s)":             "会话（与 %s 比较）",
+	"Sessions of %s on %s":                  "%s 在 %s 上的会话",
+	"Sessions of %s on %s (compared to %s)": "%s 在 %s 上的会话（与 %s 比较）",
+
+	// Hints for common errors
+	"Use 'git stash' to temporarily save changes":                                                                                                        "使用 'git stash' 临时保存更改",
+	"Use 'git branch -D <branch>' to delete it first":                                                                                                    "先用 'git branch -D <branch>' 删除它",
+	"Use 'git branch -a' to see available branches":                                                                                                      "使用 'git branch -a' 查看可用分支",
+	"Use a different description or remove the existing directory":                                                                                       "换一个描述，或删除已存在的目录",
+	"Switch to main/master branch first, or use a different description":                                                                                 "先切换到 main/master 分支，或换一个描述",
+	"Use 'ccswitch list' to see available sessions":                                                                                                      "使用 'ccswitch list' 查看可用会话",
+	"Rebase manually in the worktree to resolve the conflicts":                                                                                           "在工作树中手动变基以解决冲突",
+	"Cherry-pick the commits manually to resolve the conflicts":                                                                                          "手动 cherry-pick 这些提交以解决冲突",
+	"Finish it with 'git rebase --continue' in the worktree, then run the command again":                                                                 "在工作树中用 'git rebase --continue' 完成变基，然后重新运行该命令",
+	"Push once with 'git push -u <remote> <branch>' to set an upstream":                                                                                  "用 'git push -u <remote> <branch>' 推送一次以设置上游分支",
+	"Wait for it to finish, or pass --wait to wait for the lock":                                                                                         "等待它完成，或加上 --wait 等待锁释放",
+	"Fix what the hook reported, or pass --no-verify to skip commit hooks":                                                                               "修复钩子报告的问题，或加上 --no-verify 跳过提交钩子",
+	"Check user.signingkey and gpg.format with 'git config', or turn off git.sign_commits":                                                               "用 'git config' 检查 user.signingkey 和 gpg.format，或关闭 git.sign_commits",
+	"Name what to use as an argument, or pass --no-tui to choose from a numbered list read from stdin":                                                   "以参数指定要使用的对象，或加上 --no-tui 从标准输入读取的编号列表中选择",
+	"git may be waiting for credentials it cannot prompt for; set up a credential helper or ssh-agent, or raise git.timeout":                             "git 可能在等待无法提示输入的凭据；请配置凭据助手或 ssh-agent，或调大 git.timeout",
+	"The branch was reset to where it was before the rebase; fix what git.verify_command reported and try again":                                         "分支已恢复到变基之前的位置；修复 git.verify_command 报告的问题后重试",
+	"Branch names follow branch.template, branch.types and branch.max_length; see 'ccswitch config'":                                                     "分支名称遵循 branch.template、branch.types 和 branch.max_length；参见 'ccswitch config'",
+	"Set git.message_command to a command that reads a diff on stdin and prints a commit message, e.g. claude -p 'Write a commit message for this diff'": "将 git.message_command 设置为从标准输入读取 diff 并输出提交信息的命令，例如 claude -p 'Write a commit message for this diff'",
+	"Run 'ccswitch unpin <session>' to allow removing it":                                                                                                "运行 'ccswitch unpin <session>' 以允许删除它",
+	"Protected branches are set with git.protected_branches":                                                                                             "受保护的分支由 git.protected_branches 设置",
+}
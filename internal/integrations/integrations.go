@@ -0,0 +1,112 @@
+// Package integrations sets up developer tools in a session's worktree when
+// it is switched to, such as direnv and mise, so that each worktree gets
+// its own environment and toolchain without user scripts.
+package integrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Integration is a tool set up in a worktree that has its config files
+type Integration struct {
+	Name string
+	// Files are the config files of the tool; a worktree with any of them
+	// uses it
+	Files []string
+	// Trusted are the files of Files the tool loads only once it was told
+	// to trust them, by running Trust followed by the file
+	Trusted []string
+	Trust   []string
+	// Env is shell code loading the tool's environment in the worktree,
+	// for shells without the tool's own hook
+	Env string
+}
+
+// Builtin are the integrations that can be enabled, by name
+var Builtin = map[string]Integration{
+	"direnv": {
+		Name:    "direnv",
+		Files:   []string{".envrc"},
+		Trusted: []string{".envrc"},
+		Trust:   []string{"direnv", "allow"},
+		Env:     `eval "$(direnv export bash 2>/dev/null)"`,
+	},
+	"mise": {
+		Name:    "mise",
+		Files:   []string{"mise.toml", ".mise.toml", ".tool-versions"},
+		Trusted: []string{"mise.toml", ".mise.toml"},
+		Trust:   []string{"mise", "trust"},
+		Env:     `eval "$(mise env --shell bash 2>/dev/null)"`,
+	},
+}
+
+// Names returns the names of the builtin integrations, sorted
+func Names() []string {
+	names := make([]string, 0, len(Builtin))
+	for name := range Builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Setup sets the named integrations up in the worktree at dir: those the
+// worktree uses and that are installed have their config files trusted.
+// It returns the shell code loading their environments, along with the
+// errors of those that could not be set up.
+func Setup(dir string, names []string) ([]string, error) {
+	var env []string
+	var errs []error
+	for _, name := range names {
+		integration, ok := Builtin[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown integration %q (available: %s)", name, strings.Join(Names(), ", ")))
+			continue
+		}
+		if !integration.usedIn(dir) {
+			continue
+		}
+		if _, err := exec.LookPath(integration.Trust[0]); err != nil {
+			continue
+		}
+		if err := integration.trust(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		env = append(env, integration.Env)
+	}
+	return env, errors.Join(errs...)
+}
+
+// usedIn reports whether the worktree at dir has config files of the tool
+func (i Integration) usedIn(dir string) bool {
+	for _, file := range i.Files {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// trust tells the tool to trust the config files it needs trusted in the
+// worktree at dir
+func (i Integration) trust(dir string) error {
+	for _, file := range i.Trusted {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		cmd := exec.Command(i.Trust[0], append(i.Trust[1:], path)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed in %s: %w, output: %s", strings.Join(i.Trust, " "), dir, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build !windows
+
+package integrations
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	// A fake direnv that records what it was asked to allow
+	bin := t.TempDir()
+	log := filepath.Join(t.TempDir(), "allowed")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "direnv"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	dir := t.TempDir()
+	env, err := Setup(dir, []string{"direnv", "mise"})
+	if err != nil || len(env) != 0 {
+		t.Errorf("Setup() without config files = %v, %v; expected nothing", env, err)
+	}
+
+	for _, file := range []string{".envrc", "mise.toml"} {
+		if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// mise is not installed, so only direnv is set up
+	env, err = Setup(dir, []string{"direnv", "mise"})
+	if err != nil {
+		t.Fatalf("Setup() failed: %v", err)
+	}
+	if expected := []string{Builtin["direnv"].Env}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("Setup() = %v, expected %v", env, expected)
+	}
+	data, _ := os.ReadFile(log)
+	if got := strings.TrimSpace(string(data)); got != "allow "+filepath.Join(dir, ".envrc") {
+		t.Errorf("direnv was run with %q", got)
+	}
+
+	if _, err := Setup(dir, []string{"asdf"}); err == nil || !strings.Contains(err.Error(), "unknown integration") {
+		t.Errorf("Setup() with an unknown integration = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Desktop shows a native desktop notification: with osascript on macOS, a
+// toast on Windows and notify-send elsewhere
+func Desktop(title, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	name, args, env := desktopCommand(runtime.GOOS, title, message)
+	cmd := exec.CommandContext(ctx, name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, detail)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// windowsToast shows a toast with the title and message from the
+// environment, so they need no quoting
+const windowsToast = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:CCSWITCH_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:CCSWITCH_NOTIFY_MESSAGE)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('ccswitch').Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+
+// desktopCommand returns the program, arguments and extra environment that
+// show a notification on goos. Title and message are passed as arguments or
+// variables rather than spliced into a script, so they need no escaping.
+func desktopCommand(goos, title, message string) (string, []string, []string) {
+	switch goos {
+	case "darwin":
+		return "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message,
+		}, nil
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", windowsToast},
+			[]string{"CCSWITCH_NOTIFY_TITLE=" + title, "CCSWITCH_NOTIFY_MESSAGE=" + message}
+	default:
+		return "notify-send", []string{"--app-name=ccswitch", "--", title, message}, nil
+	}
+}
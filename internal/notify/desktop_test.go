@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDesktopCommand(t *testing.T) {
+	title, message := `ccswitch: "auth"`, "npm test failed with exit status 1"
+
+	name, args, env := desktopCommand("darwin", title, message)
+	if name != "osascript" || !reflect.DeepEqual(args[len(args)-2:], []string{title, message}) || env != nil {
+		t.Errorf("darwin: %s %q %q, expected title and message as arguments", name, args, env)
+	}
+
+	name, args, env = desktopCommand("linux", title, message)
+	if name != "notify-send" || !reflect.DeepEqual(args[len(args)-2:], []string{title, message}) {
+		t.Errorf("linux: %s %q, expected title and message as arguments", name, args)
+	}
+
+	name, args, env = desktopCommand("windows", title, message)
+	expectedEnv := []string{"CCSWITCH_NOTIFY_TITLE=" + title, "CCSWITCH_NOTIFY_MESSAGE=" + message}
+	if name != "powershell" || !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("windows: %s %q, expected title and message in the environment", name, env)
+	}
+	if strings.Contains(strings.Join(args, " "), message) {
+		t.Errorf("windows: message spliced into the script %q", args)
+	}
+}
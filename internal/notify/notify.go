@@ -0,0 +1,131 @@
+// Package notify tells the user that a long operation finished: by running
+// a command and posting a summary to a webhook, as set up in the
+// notifications section of the config, or with a desktop notification.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/redact"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// Timeout bounds the notification command and the webhook request, so a
+// hung receiver doesn't hold up ccswitch
+const Timeout = 10 * time.Second
+
+// Environment variables the notification command gets the summary in
+const (
+	OperationEnv = "CCSWITCH_OPERATION"
+	ResultEnv    = "CCSWITCH_RESULT"
+	SummaryEnv   = "CCSWITCH_SUMMARY"
+)
+
+// Notifier sends notifications
+type Notifier struct {
+	// Command is run with the system shell, with the summary in the
+	// CCSWITCH_OPERATION, CCSWITCH_RESULT and CCSWITCH_SUMMARY variables and
+	// the notification as JSON on stdin
+	Command string
+	// Webhook is a URL the notification is POSTed to as JSON
+	Webhook string
+}
+
+// Enabled reports whether there is anything to notify
+func (n Notifier) Enabled() bool {
+	return n.Command != "" || n.Webhook != ""
+}
+
+// Send runs the command and posts to the webhook. Both are tried, and the
+// errors of either are returned together. Secrets in the summary are
+// masked, since it leaves ccswitch.
+func (n Notifier) Send(doc schema.Notification) error {
+	doc.Text = redact.String(doc.Text)
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	var errs []error
+	if n.Command != "" {
+		if err := n.runCommand(doc, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.Webhook != "" {
+		if err := n.post(body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// runCommand runs the notification command
+func (n Notifier) runCommand(doc schema.Notification, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	args := shellArgs(n.Command)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		OperationEnv+"="+doc.Operation,
+		ResultEnv+"="+doc.Result,
+		SummaryEnv+"="+doc.Text,
+	)
+	cmd.Stdin = bytes.NewReader(body)
+	// Don't wait for processes the command left running with our output
+	cmd.WaitDelay = time.Second
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("notification command timed out after %s", Timeout)
+		}
+		return fmt.Errorf("notification command failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// post sends the notification to the webhook. Webhook URLs usually embed
+// a secret, so errors name only the host.
+func (n Notifier) post(body []byte) error {
+	u, err := url.Parse(n.Webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid notifications.webhook: expected an http(s) URL")
+	}
+
+	client := &http.Client{Timeout: Timeout}
+	resp, err := client.Post(u.String(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if stderrors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to reach webhook at %s: %w", u.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook at %s returned %s: %s", u.Host, resp.Status, strings.TrimSpace(string(reply)))
+	}
+	return nil
+}
+
+// shellArgs returns the arguments that run command with the system shell
+func shellArgs(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"/bin/sh", "-c", command}
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+func testNotification() schema.Notification {
+	return schema.Notification{
+		Header:    schema.NewHeader(),
+		Text:      "ccswitch fanout in project succeeded: 2 rebased",
+		Operation: "fanout",
+		Repo:      "project",
+		Result:    schema.ResultSucceeded,
+		Counts:    map[string]int{schema.EventRebased: 2},
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var received schema.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := (Notifier{Webhook: server.URL + "/hook"}).Send(testNotification()); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if received.Text != testNotification().Text || received.Counts[schema.EventRebased] != 2 {
+		t.Errorf("webhook received %+v", received)
+	}
+}
+
+func TestSendWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := (Notifier{Webhook: server.URL + "/services/T000/B000/s3cr3t"}).Send(testNotification())
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("Send() error = %v, expected the webhook's reply", err)
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Send() error %q reveals the webhook URL", err)
+	}
+}
+
+func TestSendCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	n := Notifier{Command: `printf '%s %s %s\n' "$CCSWITCH_OPERATION" "$CCSWITCH_RESULT" "$CCSWITCH_SUMMARY" > ` + out + ` && cat >> ` + out}
+	if err := n.Send(testNotification()); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, body, _ := strings.Cut(string(data), "\n")
+	if env != "fanout succeeded ccswitch fanout in project succeeded: 2 rebased" {
+		t.Errorf("command environment = %q", env)
+	}
+	var doc schema.Notification
+	if err := json.Unmarshal([]byte(body), &doc); err != nil || doc.Repo != "project" {
+		t.Errorf("command stdin = %q, expected the notification as JSON", body)
+	}
+
+	err = (Notifier{Command: "echo no route >&2; exit 3"}).Send(testNotification())
+	if err == nil || !strings.Contains(err.Error(), "no route") {
+		t.Errorf("Send() error = %v, expected the command's output", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package proc runs user commands (work, hooks, verify) so that they and
+// everything they spawn are cleaned up when ccswitch is interrupted.
+//
+// Commands run in their own process group (a job object on Windows). When
+// ccswitch receives a termination signal, the group is asked to exit and is
+// killed after a grace period. Processes left running in the group after the
+// command exits, such as a dev server started in the background, are
+// terminated the same way so they don't keep ports busy.
+package proc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// DefaultGracePeriod is how long processes get to exit after being asked to
+// before they are killed
+const DefaultGracePeriod = 5 * time.Second
+
+// Run starts cmd in its own process group and waits for it to exit
+func Run(cmd *exec.Cmd, grace time.Duration) error {
+	prepare(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	g, err := attach(cmd)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to create process group: %w", err)
+	}
+	defer g.release()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, terminationSignals...)
+	defer signal.Stop(sigs)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		g.cleanup(grace)
+		return err
+	case sig := <-sigs:
+		g.terminate(done, grace)
+		return fmt.Errorf("interrupted by %s", sig)
+	}
+}
+
+// RunShell runs command with the system shell in dir, as Run does, and
+// returns what it printed on stdout and stderr
+func RunShell(dir, command string) ([]byte, error) {
+	cmd := ShellCommand(command)
+	cmd.Dir = dir
+
+	// One writer for both streams, so they are interleaved as printed
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := Run(cmd, DefaultGracePeriod)
+	return output.Bytes(), err
+}
+
+// ExitCode returns the exit code ccswitch should use after a command it ran
+// failed with err. Commands killed by a signal map to 128+signal as in
+// shells, and commands that could not be found to 127.
+func ExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		if code := exitErr.ExitCode(); code > 0 {
+			return code
+		}
+		return 1
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return 127
+	}
+	return 1
+}
+
+// ShellCommand returns a command that runs command with the system shell
+func ShellCommand(command string) *exec.Cmd {
+	args := ShellArgs(command)
+	return exec.Command(args[0], args[1:]...)
+}
+
+// ShellArgs returns the program and arguments that run command with the
+// system shell, for commands another program starts, such as git bisect run
+func ShellArgs(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"/bin/sh", "-c", command}
+}
+
+// OnInterrupt calls fn when ccswitch receives a termination signal, until
+// stop is called. Meanwhile the signal does not terminate ccswitch, so the
+// operation in progress can stop cleanly; after stop, it does again.
+func OnInterrupt(fn func()) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, terminationSignals...)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			fn()
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,147 @@
+//go:build !windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var terminationSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// pollInterval is how often a group is checked for remaining processes
+const pollInterval = 50 * time.Millisecond
+
+// group is a unix process group led by the command
+type group struct {
+	pgid int
+	// tty is the terminal handed to the group, or -1
+	tty int
+}
+
+// prepare places the command in a new process group. Interactive commands
+// become the terminal's foreground group so they can read input and receive
+// Ctrl+C like they would when run directly.
+func prepare(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	if cmd.Stdin == os.Stdin && isForeground(int(os.Stdin.Fd())) {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = int(os.Stdin.Fd())
+	}
+}
+
+// KillGroupOnCancel makes a command that does not read the terminal start
+// in its own process group, and makes cancelling its context terminate the
+// whole group, such as git with the ssh or credential helper it started.
+// SIGTERM lets git remove its lock files; the command is killed if it is
+// still running after DefaultGracePeriod. It reports whether the command was
+// given a group; processes in it cannot read the terminal, which stays with
+// ccswitch.
+func KillGroupOnCancel(cmd *exec.Cmd) bool {
+	if cmd.Stdin != nil {
+		return false
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return unix.Kill(-cmd.Process.Pid, unix.SIGTERM)
+	}
+	cmd.WaitDelay = DefaultGracePeriod
+	return true
+}
+
+func attach(cmd *exec.Cmd) (*group, error) {
+	g := &group{pgid: cmd.Process.Pid, tty: -1}
+	if cmd.SysProcAttr.Foreground {
+		g.tty = cmd.SysProcAttr.Ctty
+	}
+	return g, nil
+}
+
+// terminate sends SIGTERM to the group and SIGKILL to whatever is left once
+// grace has passed, then waits for the command to be reaped
+func (g *group) terminate(done <-chan error, grace time.Duration) {
+	g.cleanup(grace)
+	<-done
+}
+
+// cleanup terminates the processes in the group, killing them if they are
+// still running after grace
+func (g *group) cleanup(grace time.Duration) {
+	if !g.alive() {
+		return
+	}
+
+	_ = unix.Kill(-g.pgid, unix.SIGTERM)
+	deadline := time.Now().Add(grace)
+	for g.alive() && time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+	}
+	if g.alive() {
+		_ = unix.Kill(-g.pgid, unix.SIGKILL)
+	}
+}
+
+// alive reports whether any process remains in the group
+func (g *group) alive() bool {
+	return unix.Kill(-g.pgid, 0) == nil
+}
+
+// release takes back the terminal if it was handed to the group
+func (g *group) release() {
+	if g.tty < 0 {
+		return
+	}
+
+	// Changing the foreground group from the background raises SIGTTOU
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(g.tty, unix.TIOCSPGRP, unix.Getpgrp())
+}
+
+// isForeground reports whether fd is a terminal and ccswitch is its
+// foreground process group
+func isForeground(fd int) bool {
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	return err == nil && pgrp == unix.Getpgrp()
+}
+
+// Alive reports whether a process with the given pid exists
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := unix.Kill(pid, 0)
+	return err == nil || err == unix.EPERM
+}
+
+// Detach makes cmd start in a new session, so it keeps running after
+// ccswitch exits and its process group can be stopped with Terminate
+func Detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
+
+// Terminate stops a process started with Detach and everything it started,
+// killing them if they are still running after grace
+func Terminate(pid int, grace time.Duration) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	(&group{pgid: pid, tty: -1}).cleanup(grace)
+	return nil
+}
@@ -0,0 +1,140 @@
+//go:build !windows
+
+package proc
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunShell(t *testing.T) {
+	dir := t.TempDir()
+	output, err := RunShell(dir, "pwd; echo failed >&2; exit 2")
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Errorf("RunShell() error = %v, expected exit status 2", err)
+	}
+	resolved, _ := filepath.EvalSymlinks(dir)
+	if got := string(output); got != resolved+"\nfailed\n" && got != dir+"\nfailed\n" {
+		t.Errorf("RunShell() output = %q, expected the directory and the error", got)
+	}
+}
+
+func TestRunKillsLeftoverProcesses(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	// The shell exits immediately, leaving a background child behind
+	cmd := exec.Command("sh", "-c", "sleep 30 & echo $! > "+pidFile)
+	if err := Run(cmd, time.Second); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("failed to read pid file: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("invalid pid %q: %v", data, err)
+	}
+
+	if running(pid) {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+		t.Errorf("background process %d is still running", pid)
+	}
+}
+
+func TestRunReturnsExitError(t *testing.T) {
+	err := Run(exec.Command("sh", "-c", "exit 3"), time.Second)
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 {
+		t.Errorf("Run() error = %v, expected exit status 3", err)
+	}
+}
+
+func TestKillGroupOnCancel(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+	if !KillGroupOnCancel(cmd) {
+		t.Fatal("KillGroupOnCancel() = false, expected a group for a command without stdin")
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	var pid int
+	for deadline := time.Now().Add(5 * time.Second); pid == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		data, _ := os.ReadFile(pidFile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if pid == 0 {
+		t.Fatal("background process did not start")
+	}
+
+	cancel()
+	start := time.Now()
+	_ = cmd.Wait()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Wait() took %s after cancel", elapsed)
+	}
+	for deadline := time.Now().Add(time.Second); running(pid) && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if running(pid) {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+		t.Errorf("background process %d survived the cancel", pid)
+	}
+
+	interactive := exec.Command("true")
+	interactive.Stdin = os.Stdin
+	if KillGroupOnCancel(interactive) {
+		t.Error("KillGroupOnCancel() = true for a command reading stdin")
+	}
+}
+
+// running reports whether pid is a live process. Zombies count as exited:
+// orphans are only reaped once init gets to them.
+func running(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *exec.Cmd
+		expected int
+	}{
+		{"exit status", exec.Command("sh", "-c", "exit 3"), 3},
+		{"killed by signal", exec.Command("sh", "-c", "kill -TERM $$"), 143},
+		{"not found", exec.Command("ccswitch-no-such-command"), 127},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cmd.Run()
+			if err == nil {
+				t.Fatal("expected command to fail")
+			}
+			if code := ExitCode(err); code != tt.expected {
+				t.Errorf("ExitCode(%v) = %d, expected %d", err, code, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+//go:build windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var terminationSignals = []os.Signal{os.Interrupt}
+
+// group is a job object containing the command and its children
+type group struct {
+	job windows.Handle
+}
+
+func prepare(cmd *exec.Cmd) {}
+
+// KillGroupOnCancel leaves cmd as it is: the console delivers Ctrl+C to
+// every process attached to it, and cancelling the context kills only the
+// command itself
+func KillGroupOnCancel(cmd *exec.Cmd) bool {
+	return false
+}
+
+// attach assigns the started command to a job object that kills all
+// processes in it when the handle is closed
+func attach(cmd *exec.Cmd) (*group, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, err
+	}
+	defer windows.CloseHandle(process)
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, err
+	}
+	return &group{job: job}, nil
+}
+
+// terminate gives the command grace to handle the console's Ctrl+C itself,
+// then kills every process in the job
+func (g *group) terminate(done <-chan error, grace time.Duration) {
+	select {
+	case <-done:
+	case <-time.After(grace):
+	}
+	_ = windows.TerminateJobObject(g.job, 1)
+}
+
+// cleanup kills processes left in the job after the command exited
+func (g *group) cleanup(grace time.Duration) {
+	_ = windows.TerminateJobObject(g.job, 1)
+}
+
+func (g *group) release() {
+	_ = windows.CloseHandle(g.job)
+}
+
+// Alive reports whether a process with the given pid exists
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == 259 // STILL_ACTIVE
+}
+
+// Detach makes cmd start without the console of ccswitch, so it keeps
+// running after ccswitch exits
+func Detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &windows.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS
+}
+
+// Terminate stops a process started with Detach and its child processes.
+// Windows has no way to ask them to exit, so grace is not used.
+func Terminate(pid int, grace time.Duration) error {
+	output, err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stop process %d: %w, output: %s", pid, err, string(output))
+	}
+	return nil
+}
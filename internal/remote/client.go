@@ -0,0 +1,121 @@
+// Package remote reads session state from another machine's ccswitch serve
+// API. It is read-only: nothing on the remote side can be changed through it.
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// StatusPath is the serve API endpoint returning a schema.Status document
+const StatusPath = "/api/v1/status"
+
+// DefaultTimeout bounds each request to a remote instance
+const DefaultTimeout = 10 * time.Second
+
+// TokenEnv is the environment variable holding the token of the serve API,
+// which clients send with every request
+const TokenEnv = "CCSWITCH_TOKEN"
+
+// ErrUnauthorized is returned when the serve instance refuses the token
+var ErrUnauthorized = errors.New("missing or wrong token")
+
+// Client reads from a ccswitch serve instance
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	token   string
+}
+
+// New creates a client for the serve instance at rawURL, e.g.
+// "http://buildbox:7777", authenticating with the token in TokenEnv. A
+// missing scheme defaults to http.
+func New(rawURL string) (*Client, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL %q: %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote URL %q: expected http://host:port", rawURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	return &Client{baseURL: u, http: &http.Client{Timeout: DefaultTimeout}, token: os.Getenv(TokenEnv)}, nil
+}
+
+// Host returns the host:port of the remote instance
+func (c *Client) Host() string {
+	return c.baseURL.Host
+}
+
+// Status fetches the session status of the remote instance
+func (c *Client) Status() (*schema.Status, error) {
+	var doc schema.Status
+	if err := c.get(StatusPath, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// get fetches path and decodes the JSON document into v, refusing documents
+// from a newer schema version than this build understands
+func (c *Client) get(path string, v any) error {
+	endpoint := *c.baseURL
+	endpoint.Path += path
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.Host(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", c.Host(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(body))
+		var apiErr schema.APIError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%s refused the request: %w", c.Host(), ErrUnauthorized)
+		}
+		return fmt.Errorf("%s returned %s: %s", c.Host(), resp.Status, message)
+	}
+
+	var header schema.Header
+	if err := json.Unmarshal(body, &header); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", c.Host(), err)
+	}
+	if header.Schema > schema.Version {
+		return fmt.Errorf("%s uses schema version %d, this ccswitch understands up to %d; upgrade ccswitch",
+			c.Host(), header.Schema, schema.Version)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", c.Host(), err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		input   string
+		host    string
+		wantErr bool
+	}{
+		{"http://buildbox:7777", "buildbox:7777", false},
+		{"buildbox:7777", "buildbox:7777", false},
+		{"https://ccswitch.example.com/", "ccswitch.example.com", false},
+		{"ftp://buildbox", "", true},
+		{"http://", "", true},
+	}
+
+	for _, tt := range tests {
+		client, err := New(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && client.Host() != tt.host {
+			t.Errorf("New(%q).Host() = %q, expected %q", tt.input, client.Host(), tt.host)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != StatusPath {
+			http.NotFound(w, r)
+			return
+		}
+		schema.Write(w, schema.Status{
+			Header:   schema.NewHeader(),
+			Repo:     "project",
+			Base:     "main",
+			Sessions: []schema.StatusSession{{Name: "auth", Branch: "feature/auth", Ahead: 1}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if status.Repo != "project" || len(status.Sessions) != 1 || status.Sessions[0].Ahead != 1 {
+		t.Errorf("Status() = %+v", status)
+	}
+}
+
+func TestStatusRejectsNewerSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"schema": 99, "repo": "project"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New(server.URL)
+	if _, err := client.Status(); err == nil || !strings.Contains(err.Error(), "upgrade ccswitch") {
+		t.Errorf("Status() error = %v, expected schema version error", err)
+	}
+}
+
+func TestStatusReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not a git repository", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, _ := New(server.URL)
+	if _, err := client.Status(); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Status() error = %v, expected server message", err)
+	}
+}
+
+func TestStatusSendsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			schema.Write(w, schema.APIError{Header: schema.NewHeader(), Error: "missing or wrong token"})
+			return
+		}
+		schema.Write(w, schema.Status{Header: schema.NewHeader(), Repo: "project"})
+	}))
+	defer server.Close()
+
+	client, _ := New(server.URL)
+	if _, err := client.Status(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Status() without token error = %v, expected ErrUnauthorized", err)
+	}
+
+	t.Setenv(TokenEnv, "secret")
+	client, _ = New(server.URL)
+	if status, err := client.Status(); err != nil || status.Repo != "project" {
+		t.Errorf("Status() with token = %+v, %v", status, err)
+	}
+}
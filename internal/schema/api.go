@@ -0,0 +1,61 @@
+package schema
+
+// Session is the serve API's response to creating, removing or rebasing a
+// session
+type Session struct {
+	Header
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+}
+
+// ExecResult is the serve API's response to running a command in a session
+type ExecResult struct {
+	Header
+	Session         string   `json:"session"`
+	Command         []string `json:"command"`
+	ExitCode        int      `json:"exit_code"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	// Output is what the command printed on stdout and stderr, cut to its
+	// end if it is long
+	Output string `json:"output"`
+	// TimedOut is set when the command was stopped for running longer than
+	// the timeout of the request
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// APIError is the body of the serve API's error responses
+type APIError struct {
+	Header
+	Error string `json:"error"`
+	// Hint suggests how to resolve the error, when there is a known way
+	Hint string `json:"hint,omitempty"`
+}
+
+// CreateSessionRequest is the body of a request to the serve API to create
+// a session
+type CreateSessionRequest struct {
+	Description string `json:"description"`
+	// Base is the branch or commit to start from instead of the current
+	// branch of the main repository
+	Base string `json:"base,omitempty"`
+	// Type is the branch type, e.g. "fix", picking the branch prefix
+	Type string   `json:"type,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RebaseRequest is the body of a request to the serve API to rebase a
+// session onto the current branch of the main repository; it may be empty
+type RebaseRequest struct {
+	// Message commits the session's uncommitted changes with this message
+	// first; without it, a session with uncommitted changes is refused
+	Message string `json:"message,omitempty"`
+}
+
+// ExecRequest is the body of a request to the serve API to run a command
+// in a session
+type ExecRequest struct {
+	Command []string `json:"command"`
+	// Timeout stops the command after this long, e.g. "5m"
+	Timeout string `json:"timeout,omitempty"`
+}
@@ -0,0 +1,520 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// documents lists every top-level JSON document; add new ones here
+var documents = []any{
+	APIError{},
+	Diff{},
+	Event{},
+	ExecResult{},
+	History{},
+	Notification{},
+	Session{},
+	Stats{},
+	Status{},
+	TestMatrix{},
+}
+
+func TestDocumentsEmbedHeader(t *testing.T) {
+	for _, doc := range documents {
+		typ := reflect.TypeOf(doc)
+		field, ok := typ.FieldByName("Header")
+		if !ok || !field.Anonymous {
+			t.Errorf("%s does not embed Header", typ.Name())
+		}
+	}
+}
+
+// TestDiffCompat pins the field names of schema version 1. If this test
+// fails, the change breaks integrations: add fields instead, or bump Version.
+func TestDiffCompat(t *testing.T) {
+	doc := Diff{
+		Header:      NewHeader(),
+		Session:     "feature",
+		Branch:      "feature/x",
+		Base:        "main",
+		WorkingTree: true,
+		Files:       []DiffFile{{Path: "a.go", Insertions: 2, Deletions: 1}, {Path: "logo.png", Binary: true}},
+		Insertions:  2,
+		Deletions:   1,
+	}
+
+	expected := `{
+  "schema": 1,
+  "session": "feature",
+  "branch": "feature/x",
+  "base": "main",
+  "working_tree": true,
+  "files": [
+    {
+      "path": "a.go",
+      "insertions": 2,
+      "deletions": 1,
+      "binary": false
+    },
+    {
+      "path": "logo.png",
+      "insertions": 0,
+      "deletions": 0,
+      "binary": true
+    }
+  ],
+  "insertions": 2,
+  "deletions": 1
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Diff JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded Diff
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+// TestStatusCompat pins the field names of schema version 1. If this test
+// fails, the change breaks integrations: add fields instead, or bump Version.
+func TestStatusCompat(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	doc := Status{
+		Header: NewHeader(),
+		Repo:   "project",
+		Base:   "main",
+		Sessions: []StatusSession{
+			{Name: "auth", Branch: "feature/auth", Path: "/w/auth", Dirty: true, Ahead: 2, CreatedAt: &created,
+				Issue: "AUTH-12", IssueURL: "https://issues.example.com/AUTH-12", Tags: []string{"backend"},
+				Identity: "work", UserName: "Jo", UserEmail: "jo@example.com",
+				Activity: &Activity{Status: "running tests", UpdatedAt: created}},
+			{Name: "gone", Branch: "feature/gone", Path: "/w/gone", Error: "not a worktree"},
+		},
+	}
+
+	expected := `{
+  "schema": 1,
+  "repo": "project",
+  "base": "main",
+  "sessions": [
+    {
+      "name": "auth",
+      "branch": "feature/auth",
+      "path": "/w/auth",
+      "dirty": true,
+      "ahead": 2,
+      "behind": 0,
+      "created_at": "2024-05-01T12:00:00Z",
+      "issue": "AUTH-12",
+      "issue_url": "https://issues.example.com/AUTH-12",
+      "tags": [
+        "backend"
+      ],
+      "identity": "work",
+      "user_name": "Jo",
+      "user_email": "jo@example.com",
+      "activity": {
+        "status": "running tests",
+        "updated_at": "2024-05-01T12:00:00Z",
+        "quiet": false
+      }
+    },
+    {
+      "name": "gone",
+      "branch": "feature/gone",
+      "path": "/w/gone",
+      "dirty": false,
+      "ahead": 0,
+      "behind": 0,
+      "error": "not a worktree"
+    }
+  ]
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Status JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded Status
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+// TestEventCompat pins the field names of schema version 1. If this test
+// fails, the change breaks integrations: add fields instead, or bump Version.
+func TestEventCompat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Header: NewHeader(), Event: EventSkipped, Operation: "fanout", Time: at,
+			Branch: "feature/auth", Path: "/w/auth", Onto: "main", Reason: "uncommitted changes"},
+		{Header: NewHeader(), Event: EventConflicted, Operation: "rebase", Time: at,
+			Branch: "feature/auth", Path: "/w/auth", Onto: "main", Reason: "rebase conflict detected in a.go, auto-aborted",
+			Conflict: &Conflict{Commit: "1a2b3c4 Add login", Files: []string{"a.go"}, Diff: "diff --cc a.go"}},
+		{Header: NewHeader(), Event: EventDone, Operation: "fanout", Time: at,
+			Counts: map[string]int{"rebased": 2, "skipped": 1}},
+	}
+
+	expected := `{"schema":1,"event":"skipped","operation":"fanout","time":"2024-05-01T12:00:00Z","branch":"feature/auth","path":"/w/auth","onto":"main","reason":"uncommitted changes"}
+{"schema":1,"event":"conflicted","operation":"rebase","time":"2024-05-01T12:00:00Z","branch":"feature/auth","path":"/w/auth","onto":"main","reason":"rebase conflict detected in a.go, auto-aborted","conflict":{"commit":"1a2b3c4 Add login","files":["a.go"],"diff":"diff --cc a.go"}}
+{"schema":1,"event":"done","operation":"fanout","time":"2024-05-01T12:00:00Z","counts":{"rebased":2,"skipped":1}}
+`
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		if err := WriteLine(&buf, event); err != nil {
+			t.Fatalf("WriteLine() failed: %v", err)
+		}
+	}
+	if buf.String() != expected {
+		t.Errorf("Event JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	for i, line := range bytes.Split(bytes.TrimSpace([]byte(expected)), []byte("\n")) {
+		var decoded Event
+		if err := json.Unmarshal(line, &decoded); err != nil {
+			t.Fatalf("failed to decode version 1 event: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, events[i]) {
+			t.Errorf("decoded %+v, expected %+v", decoded, events[i])
+		}
+	}
+}
+
+// TestNotificationCompat pins the field names of schema version 1. If this
+// test fails, the change breaks integrations: add fields instead, or bump
+// Version.
+func TestNotificationCompat(t *testing.T) {
+	doc := Notification{
+		Header:          NewHeader(),
+		Text:            "ccswitch fanout in project succeeded: 2 rebased",
+		Operation:       "fanout",
+		Repo:            "project",
+		Result:          ResultSucceeded,
+		Counts:          map[string]int{"rebased": 2},
+		DurationSeconds: 12.5,
+		Time:            time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	expected := `{
+  "schema": 1,
+  "text": "ccswitch fanout in project succeeded: 2 rebased",
+  "operation": "fanout",
+  "repo": "project",
+  "result": "succeeded",
+  "counts": {
+    "rebased": 2
+  },
+  "duration_seconds": 12.5,
+  "time": "2024-05-01T12:00:00Z"
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Notification JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded Notification
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestTestMatrixCompat(t *testing.T) {
+	doc := TestMatrix{
+		Header:  NewHeader(),
+		Command: []string{"go", "test", "./..."},
+		Results: []TestMatrixResult{
+			{Session: "auth", Branch: "feature/auth", Path: "/w/auth", Passed: true, DurationSeconds: 4.5},
+			{Session: "api", Branch: "feature/api", Path: "/w/api", ExitCode: 1, DurationSeconds: 2, Output: "FAIL"},
+		},
+		Passed: 1,
+		Failed: 1,
+	}
+
+	expected := `{
+  "schema": 1,
+  "command": [
+    "go",
+    "test",
+    "./..."
+  ],
+  "results": [
+    {
+      "session": "auth",
+      "branch": "feature/auth",
+      "path": "/w/auth",
+      "passed": true,
+      "exit_code": 0,
+      "duration_seconds": 4.5
+    },
+    {
+      "session": "api",
+      "branch": "feature/api",
+      "path": "/w/api",
+      "passed": false,
+      "exit_code": 1,
+      "duration_seconds": 2,
+      "output": "FAIL"
+    }
+  ],
+  "passed": 1,
+  "failed": 1
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("TestMatrix JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded TestMatrix
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestStatsCompat(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	doc := Stats{
+		Header:            NewHeader(),
+		Repo:              "project",
+		Base:              "main",
+		Sessions:          2,
+		AverageAgeSeconds: 86400,
+		Commits:           5,
+		CommitsPerSession: 2.5,
+		DiskBytes:         1048576,
+		Since:             &since,
+		Rebases: RebaseStats{
+			Total: 4, Succeeded: 3, Conflicted: 1,
+			SuccessRate: 0.75, ConflictRate: 0.25,
+		},
+	}
+
+	expected := `{
+  "schema": 1,
+  "repo": "project",
+  "base": "main",
+  "sessions": 2,
+  "average_age_seconds": 86400,
+  "commits": 5,
+  "commits_per_session": 2.5,
+  "disk_bytes": 1048576,
+  "since": "2024-05-01T00:00:00Z",
+  "rebases": {
+    "total": 4,
+    "succeeded": 3,
+    "conflicted": 1,
+    "failed": 0,
+    "interrupted": 0,
+    "success_rate": 0.75,
+    "conflict_rate": 0.25
+  }
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Stats JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded Stats
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestHistoryCompat(t *testing.T) {
+	doc := History{
+		Header: NewHeader(),
+		Repo:   "project",
+		Operations: []Operation{
+			{At: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), Op: OpCreate, Command: "create", User: "jane", Session: "auth", Branches: []string{"feature/auth"}, Result: ResultSucceeded},
+			{At: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC), Op: OpRebase, Command: "fanout", User: "jane", Branches: []string{"feature/auth"}, Result: ResultConflicted, Error: "rebase conflict detected"},
+		},
+	}
+
+	expected := `{
+  "schema": 1,
+  "repo": "project",
+  "operations": [
+    {
+      "at": "2024-05-01T09:30:00Z",
+      "op": "create",
+      "command": "create",
+      "user": "jane",
+      "session": "auth",
+      "branches": [
+        "feature/auth"
+      ],
+      "result": "succeeded"
+    },
+    {
+      "at": "2024-05-02T10:00:00Z",
+      "op": "rebase",
+      "command": "fanout",
+      "user": "jane",
+      "branches": [
+        "feature/auth"
+      ],
+      "result": "conflicted",
+      "error": "rebase conflict detected"
+    }
+  ]
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("History JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded History
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestSessionCompat(t *testing.T) {
+	doc := Session{Header: NewHeader(), Name: "auth", Branch: "feature/auth", Path: "/w/auth"}
+
+	expected := `{
+  "schema": 1,
+  "name": "auth",
+  "branch": "feature/auth",
+  "path": "/w/auth"
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Session JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded Session
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestExecResultCompat(t *testing.T) {
+	doc := ExecResult{
+		Header:          NewHeader(),
+		Session:         "auth",
+		Command:         []string{"make", "test"},
+		ExitCode:        2,
+		DurationSeconds: 1.5,
+		Output:          "FAIL",
+		TimedOut:        true,
+	}
+
+	expected := `{
+  "schema": 1,
+  "session": "auth",
+  "command": [
+    "make",
+    "test"
+  ],
+  "exit_code": 2,
+  "duration_seconds": 1.5,
+  "output": "FAIL",
+  "timed_out": true
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("ExecResult JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded ExecResult
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
+
+func TestAPIErrorCompat(t *testing.T) {
+	doc := APIError{Header: NewHeader(), Error: "session not found: auth", Hint: "Run 'ccswitch list' to see sessions"}
+
+	expected := `{
+  "schema": 1,
+  "error": "session not found: auth",
+  "hint": "Run 'ccswitch list' to see sessions"
+}
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("APIError JSON changed:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	var decoded APIError
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("failed to decode version 1 document: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded %+v, expected %+v", decoded, doc)
+	}
+}
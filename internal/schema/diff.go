@@ -0,0 +1,21 @@
+package schema
+
+// DiffFile is a file changed in a session
+type DiffFile struct {
+	Path       string `json:"path"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary"`
+}
+
+// Diff is the output of ccswitch diff --json
+type Diff struct {
+	Header
+	Session     string     `json:"session"`
+	Branch      string     `json:"branch"`
+	Base        string     `json:"base"`
+	WorkingTree bool       `json:"working_tree"`
+	Files       []DiffFile `json:"files"`
+	Insertions  int        `json:"insertions"`
+	Deletions   int        `json:"deletions"`
+}
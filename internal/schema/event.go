@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Events printed by --porcelain as an operation progresses
+const (
+	EventStarted     = "started"
+	EventRebased     = "rebased"
+	EventConflicted  = "conflicted"
+	EventSkipped     = "skipped"
+	EventFailed      = "failed"
+	EventInterrupted = "interrupted"
+	// EventVerifying is the verify command starting after a rebase, and
+	// EventVerifyFailed it failing, after which the rebase was rolled back
+	EventVerifying    = "verifying"
+	EventVerifyFailed = "verify_failed"
+	// EventDone ends the stream, counting the worktrees by outcome
+	EventDone = "done"
+)
+
+// NotStarted counts the worktrees an operation stopped before, in the done
+// event
+const NotStarted = "not_started"
+
+// Event is a line of the --porcelain event stream of fanout and rebase
+type Event struct {
+	Header
+	Event string `json:"event"`
+	// Operation is the command that emitted the event: fanout or rebase
+	Operation string    `json:"operation"`
+	Time      time.Time `json:"time"`
+	Branch    string    `json:"branch,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	// Onto is the branch the worktree is rebased onto
+	Onto string `json:"onto,omitempty"`
+	// Reason says why a worktree was skipped or failed
+	Reason string `json:"reason,omitempty"`
+	// Conflict describes what conflicted, in conflicted events
+	Conflict *Conflict `json:"conflict,omitempty"`
+	// Counts maps outcomes to the number of worktrees, in the done event
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+// Conflict is what a rebase stopped on before it was aborted
+type Conflict struct {
+	// Commit is the commit that failed to apply: its short hash and subject
+	Commit string   `json:"commit,omitempty"`
+	Files  []string `json:"files"`
+	// Diff holds the conflicting hunks with their conflict markers, cut
+	// short if they are long
+	Diff string `json:"diff,omitempty"`
+}
+
+// WriteLine encodes document as a single line of JSON, for streams where
+// each line is a document
+func WriteLine(w io.Writer, document any) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
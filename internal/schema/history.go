@@ -0,0 +1,38 @@
+package schema
+
+import "time"
+
+// Operations recorded in the history
+const (
+	OpCreate = "create"
+	OpDelete = "delete"
+	OpRename = "rename"
+	OpMove   = "move"
+	// OpRebase is recorded for each branch rebased, whichever command did
+	// it, and OpFanout once per fanout
+	OpRebase = "rebase"
+	OpFanout = "fanout"
+	OpPick   = "pick"
+)
+
+// Operation is an entry of the operation history
+type Operation struct {
+	At time.Time `json:"at"`
+	Op string    `json:"op"`
+	// Command is the ccswitch command that performed the operation, e.g.
+	// "stack restack"
+	Command  string   `json:"command,omitempty"`
+	User     string   `json:"user,omitempty"`
+	Session  string   `json:"session,omitempty"`
+	Branches []string `json:"branches,omitempty"`
+	// Result is succeeded, failed, conflicted or interrupted
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// History is the output of ccswitch history --json, oldest first
+type History struct {
+	Header
+	Repo       string      `json:"repo"`
+	Operations []Operation `json:"operations"`
+}
@@ -0,0 +1,31 @@
+package schema
+
+import "time"
+
+// Results of a finished operation
+const (
+	ResultSucceeded   = "succeeded"
+	ResultFailed      = "failed"
+	ResultInterrupted = "interrupted"
+	// ResultConflicted is recorded in the operation history for rebases
+	// that hit a conflict and were aborted
+	ResultConflicted = "conflicted"
+)
+
+// Notification is posted to notifications.webhook, and given to
+// notifications.command on stdin, when a long operation finishes
+type Notification struct {
+	Header
+	// Text summarises the outcome in a sentence. Slack and compatible
+	// incoming webhooks show it as the message.
+	Text      string `json:"text"`
+	Operation string `json:"operation"`
+	Repo      string `json:"repo"`
+	// Result is succeeded, failed or interrupted
+	Result string `json:"result"`
+	// Counts maps outcomes to the number of worktrees, as in the done
+	// event of --porcelain
+	Counts          map[string]int `json:"counts,omitempty"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Time            time.Time      `json:"time"`
+}
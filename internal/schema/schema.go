@@ -0,0 +1,37 @@
+// Package schema defines the JSON documents ccswitch prints for --json and
+// --porcelain and serves over its HTTP API.
+//
+// These structs are a public contract for scripts and integrations. Adding
+// fields is compatible; removing or renaming fields or changing their types
+// is not and requires bumping Version. compat_test.go pins the current
+// field names so incompatible changes fail the build.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Version is the schema version written to every JSON document
+const Version = 1
+
+// Header is embedded in every top-level JSON document
+type Header struct {
+	Schema int `json:"schema"`
+}
+
+// NewHeader returns a header for the current schema version
+func NewHeader() Header {
+	return Header{Schema: Version}
+}
+
+// Write encodes document as indented JSON followed by a newline
+func Write(w io.Writer, document any) error {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
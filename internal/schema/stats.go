@@ -0,0 +1,34 @@
+package schema
+
+import "time"
+
+// RebaseStats counts the rebases in the operation history by outcome
+type RebaseStats struct {
+	Total       int `json:"total"`
+	Succeeded   int `json:"succeeded"`
+	Conflicted  int `json:"conflicted"`
+	Failed      int `json:"failed"`
+	Interrupted int `json:"interrupted"`
+	// SuccessRate and ConflictRate are fractions of Total, 0 without rebases
+	SuccessRate  float64 `json:"success_rate"`
+	ConflictRate float64 `json:"conflict_rate"`
+}
+
+// Stats is the output of ccswitch stats --json
+type Stats struct {
+	Header
+	Repo string `json:"repo"`
+	// Base is the branch commits are counted against
+	Base     string `json:"base"`
+	Sessions int    `json:"sessions"`
+	// AverageAgeSeconds is over the sessions whose creation time is known
+	AverageAgeSeconds float64 `json:"average_age_seconds"`
+	// Commits counts the commits of all sessions ahead of Base
+	Commits           int     `json:"commits"`
+	CommitsPerSession float64 `json:"commits_per_session"`
+	DiskBytes         int64   `json:"disk_bytes"`
+	// Since is where the operation history counted in Rebases starts, if
+	// limited
+	Since   *time.Time  `json:"since,omitempty"`
+	Rebases RebaseStats `json:"rebases"`
+}
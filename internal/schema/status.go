@@ -0,0 +1,63 @@
+package schema
+
+import "time"
+
+// StatusSession is the state of one session relative to the base branch
+type StatusSession struct {
+	Name string `json:"name"`
+	// Branch is empty for detached sessions, which have Detached set and
+	// Ref the tag or commit they were created at
+	Branch   string `json:"branch"`
+	Detached bool   `json:"detached,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	Path     string `json:"path"`
+	Dirty    bool   `json:"dirty"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	// Error is set when the session's state could not be determined
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	LastActive *time.Time `json:"last_active,omitempty"`
+	// LastUsed is when the session was last switched to or had a command
+	// run in it through ccswitch
+	LastUsed *time.Time `json:"last_used,omitempty"`
+	// Issue is the ticket the session works on, and IssueURL its address
+	Issue    string `json:"issue,omitempty"`
+	IssueURL string `json:"issue_url,omitempty"`
+	// Tags label the session, e.g. "frontend"
+	Tags []string `json:"tags,omitempty"`
+	// ExpiresAt is when a session created with a TTL expires, and Expired
+	// is set once it has
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired,omitempty"`
+	// Pinned sessions are kept by cleanup and prune-artifacts
+	Pinned bool `json:"pinned,omitempty"`
+	// Notes is the free-form text kept with the session with ccswitch note
+	Notes string `json:"notes,omitempty"`
+	// Identity is the configured git identity the session commits as, and
+	// UserName and UserEmail the author of its commits that it sets
+	Identity  string `json:"identity,omitempty"`
+	UserName  string `json:"user_name,omitempty"`
+	UserEmail string `json:"user_email,omitempty"`
+	// Activity is what the session's agent last reported with ccswitch
+	// heartbeat, if anything
+	Activity *Activity `json:"activity,omitempty"`
+}
+
+// Activity is the latest heartbeat published by a session's agent
+type Activity struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Quiet is set when no heartbeat arrived recently, a sign the agent
+	// may be stuck
+	Quiet bool `json:"quiet"`
+}
+
+// Status is the output of ccswitch status --json and of the serve API's
+// status endpoint
+type Status struct {
+	Header
+	Repo     string          `json:"repo"`
+	Base     string          `json:"base"`
+	Sessions []StatusSession `json:"sessions"`
+}
@@ -0,0 +1,22 @@
+package schema
+
+// TestMatrixResult is the outcome of the command in one session
+type TestMatrixResult struct {
+	Session         string  `json:"session"`
+	Branch          string  `json:"branch"`
+	Path            string  `json:"path"`
+	Passed          bool    `json:"passed"`
+	ExitCode        int     `json:"exit_code"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	// Output is the tail of what a failing command printed
+	Output string `json:"output,omitempty"`
+}
+
+// TestMatrix is the output of ccswitch test-matrix --json
+type TestMatrix struct {
+	Header
+	Command []string           `json:"command"`
+	Results []TestMatrixResult `json:"results"`
+	Passed  int                `json:"passed"`
+	Failed  int                `json:"failed"`
+}
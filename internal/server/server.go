@@ -0,0 +1,275 @@
+// Package server serves ccswitch's HTTP API, so editors and other tools can
+// list, create, remove and rebase sessions and run commands in them instead
+// of scraping the output of the CLI.
+//
+// Every request must carry the server's token as a bearer token. Responses
+// are the JSON documents of the schema package; failures are answered with
+// a schema.APIError.
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/remote"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/utils"
+	"github.com/ksred/ccswitch/pkg/ccswitch"
+)
+
+// SessionsPath lists sessions and creates them; a session's name or branch
+// follows it to remove the session, and /rebase or /exec after that
+const SessionsPath = "/api/v1/sessions"
+
+// ExecOutputLimit is how much of a command's output the exec endpoint
+// returns, from its end
+const ExecOutputLimit = 1 << 20
+
+// maxRequestBody bounds the JSON bodies the API accepts
+const maxRequestBody = 1 << 20
+
+// Server answers API requests for the sessions of a repository
+type Server struct {
+	sessions *ccswitch.SessionManager
+	token    string
+
+	// Logf reports each request that changes something; nil is silent
+	Logf func(format string, args ...any)
+}
+
+// New creates a server for sessions that accepts requests carrying token
+func New(sessions *ccswitch.SessionManager, token string) *Server {
+	return &Server{sessions: sessions, token: token}
+}
+
+// Handler returns the handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+remote.StatusPath, s.status)
+	mux.HandleFunc("GET "+SessionsPath, s.status)
+	mux.HandleFunc("POST "+SessionsPath, s.create)
+	mux.HandleFunc("DELETE "+SessionsPath+"/{session}", s.remove)
+	mux.HandleFunc("POST "+SessionsPath+"/{session}/rebase", s.rebase)
+	mux.HandleFunc("POST "+SessionsPath+"/{session}/exec", s.exec)
+	return s.authenticate(mux)
+}
+
+// authenticate refuses requests without the server's token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ccswitch"`)
+			writeError(w, http.StatusUnauthorized, remote.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	doc, err := s.sessions.Status(r.URL.Query().Get("base"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// create creates a session and prepares its worktree as ccswitch create
+// does; setup problems are logged, since the session is usable regardless
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req schema.CreateSessionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("description is required"))
+		return
+	}
+
+	created, err := s.sessions.Create(description, ccswitch.CreateOptions{Base: req.Base, Type: req.Type, Tags: req.Tags})
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	for _, problem := range s.sessions.Setup(created) {
+		s.logf("Setting up %s: %v", created.Name, problem)
+	}
+	s.logf("Created session %s (%s)", created.Name, created.Branch)
+	writeJSON(w, http.StatusCreated, sessionDocument(created))
+}
+
+// remove removes a session. Pinned sessions are refused, as are sessions
+// with uncommitted changes unless force=true is given. With
+// delete_branch=true, the branch is deleted as well unless it is protected.
+func (s *Server) remove(w http.ResponseWriter, r *http.Request) {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	deleteBranch, _ := strconv.ParseBool(r.URL.Query().Get("delete_branch"))
+
+	removed, err := s.sessions.Remove(r.PathValue("session"), ccswitch.RemoveOptions{Force: force, DeleteBranch: deleteBranch})
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	s.logf("Removed session %s", removed.Name)
+	writeJSON(w, http.StatusOK, sessionDocument(removed))
+}
+
+// rebase rebases a session onto the current branch of the main repository,
+// as ccswitch rebase does
+func (s *Server) rebase(w http.ResponseWriter, r *http.Request) {
+	var req schema.RebaseRequest
+	if r.ContentLength != 0 && !readJSON(w, r, &req) {
+		return
+	}
+
+	rebased, err := s.sessions.Rebase(r.PathValue("session"), req.Message)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	s.logf("Rebased %s", rebased.Name)
+	writeJSON(w, http.StatusOK, sessionDocument(rebased))
+}
+
+// exec runs a command in a session's worktree, as ccswitch exec does, and
+// reports its exit code and output. The command is stopped when the client
+// goes away or the request's timeout passes.
+func (s *Server) exec(w http.ResponseWriter, r *http.Request) {
+	var req schema.ExecRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if len(req.Command) == 0 || req.Command[0] == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("command is required"))
+		return
+	}
+
+	ctx := r.Context()
+	if req.Timeout != "" {
+		timeout, err := utils.ParseDuration(req.Timeout)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid timeout: %w", err))
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	target, err := s.sessions.Find(r.PathValue("session"))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	c, err := s.sessions.Command(ctx, target.Name, req.Command[0], req.Command[1:]...)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	output := &tailWriter{limit: ExecOutputLimit}
+	c.Stdout = output
+	c.Stderr = output
+	proc.KillGroupOnCancel(c)
+
+	s.logf("Running %s in %s", strings.Join(req.Command, " "), target.Name)
+	start := time.Now()
+	err = proc.Run(c, proc.DefaultGracePeriod)
+	result := schema.ExecResult{
+		Header:          schema.NewHeader(),
+		Session:         target.Name,
+		Command:         req.Command,
+		DurationSeconds: time.Since(start).Seconds(),
+		Output:          output.String(),
+		TimedOut:        ctx.Err() == context.DeadlineExceeded,
+	}
+	if err != nil {
+		result.ExitCode = proc.ExitCode(err)
+		if result.Output == "" {
+			result.Output = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// sessionDocument returns the document answering a request about s
+func sessionDocument(s ccswitch.Session) schema.Session {
+	return schema.Session{Header: schema.NewHeader(), Name: s.Name, Branch: s.Branch, Path: s.Path}
+}
+
+// errorStatus returns the HTTP status answering a failed operation
+func errorStatus(err error) int {
+	switch {
+	case errors.IsSessionNotFound(err):
+		return http.StatusNotFound
+	case errors.IsBranchPolicy(err), errors.IsMainRepository(err), errors.IsAlreadyOnBranch(err):
+		return http.StatusBadRequest
+	case errors.IsLocked(err), errors.IsUncommittedChanges(err), errors.IsBranchExists(err),
+		errors.IsWorktreeExists(err), errors.IsRebaseConflict(err), errors.IsRebaseStopped(err),
+		errors.IsVerifyFailed(err), errors.IsHookFailed(err), errors.IsPinned(err), errors.IsProtectedBranch(err):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// readJSON decodes the request's body into v. Failures are answered and
+// false is returned.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, document any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = schema.Write(w, document)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, schema.APIError{Header: schema.NewHeader(), Error: err.Error(), Hint: errors.ErrorHint(err)})
+}
+
+// tailWriter keeps the last limit bytes written to it
+type tailWriter struct {
+	limit int
+	buf   bytes.Buffer
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(p) > t.limit {
+		p = p[len(p)-t.limit:]
+	}
+	if over := t.buf.Len() + len(p) - t.limit; over > 0 {
+		t.buf.Next(over)
+	}
+	t.buf.Write(p)
+	return n, nil
+}
+
+func (t *tailWriter) String() string {
+	return t.buf.String()
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/pkg/ccswitch"
+)
+
+func TestServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "-b", "main")
+	gitIn(t, repo, "config", "user.email", "test@example.com")
+	gitIn(t, repo, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo, "add", ".")
+	gitIn(t, repo, "commit", "-m", "Initial commit")
+
+	sessions, err := ccswitch.Open(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(New(sessions, "secret").Handler())
+	defer api.Close()
+
+	if code := call(t, api, "", http.MethodGet, SessionsPath, nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("GET %s without token = %d, expected %d", SessionsPath, code, http.StatusUnauthorized)
+	}
+
+	var created schema.Session
+	if code := call(t, api, "secret", http.MethodPost, SessionsPath, schema.CreateSessionRequest{Description: "add login"}, &created); code != http.StatusCreated {
+		t.Fatalf("POST %s = %d, expected %d", SessionsPath, code, http.StatusCreated)
+	}
+	if created.Name != "add-login" || created.Path == "" {
+		t.Fatalf("created %+v, expected session add-login", created)
+	}
+
+	var status schema.Status
+	if code := call(t, api, "secret", http.MethodGet, SessionsPath, nil, &status); code != http.StatusOK || len(status.Sessions) != 2 || status.Base != "main" {
+		t.Fatalf("GET %s = %d, %+v, expected the main repository and the new session compared to main", SessionsPath, code, status)
+	}
+	if code := call(t, api, "secret", http.MethodDelete, SessionsPath+"/main", nil, nil); code != http.StatusBadRequest {
+		t.Errorf("DELETE %s/main = %d, expected %d", SessionsPath, code, http.StatusBadRequest)
+	}
+
+	var result schema.ExecResult
+	execPath := SessionsPath + "/" + created.Name + "/exec"
+	request := schema.ExecRequest{Command: []string{"sh", "-c", `echo "$CCSWITCH_SESSION" > work.txt; cat work.txt; exit 3`}}
+	if code := call(t, api, "secret", http.MethodPost, execPath, request, &result); code != http.StatusOK {
+		t.Fatalf("POST %s = %d, expected %d", execPath, code, http.StatusOK)
+	}
+	if result.ExitCode != 3 || strings.TrimSpace(result.Output) != created.Name {
+		t.Errorf("exec = %+v, expected exit code 3 and the session's name", result)
+	}
+	request = schema.ExecRequest{Command: []string{"sleep", "10"}, Timeout: "100ms"}
+	if call(t, api, "secret", http.MethodPost, execPath, request, &result); !result.TimedOut {
+		t.Errorf("exec past its timeout = %+v, expected it timed out", result)
+	}
+
+	// Uncommitted changes are neither rebased nor removed without consent
+	var apiErr schema.APIError
+	rebasePath := SessionsPath + "/" + created.Name + "/rebase"
+	if code := call(t, api, "secret", http.MethodPost, rebasePath, nil, &apiErr); code != http.StatusConflict {
+		t.Errorf("POST %s with uncommitted changes = %d, expected %d", rebasePath, code, http.StatusConflict)
+	}
+	if code := call(t, api, "secret", http.MethodPost, rebasePath, schema.RebaseRequest{Message: "Add work"}, nil); code != http.StatusOK {
+		t.Errorf("POST %s with a message = %d, expected %d", rebasePath, code, http.StatusOK)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "work.txt")); err != nil {
+		t.Errorf("rebased work missing from main: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(created.Path, "scratch.txt"), []byte("scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessionPath := SessionsPath + "/" + created.Name
+	if code := call(t, api, "secret", http.MethodDelete, sessionPath, nil, nil); code != http.StatusConflict {
+		t.Errorf("DELETE %s with uncommitted changes = %d, expected %d", sessionPath, code, http.StatusConflict)
+	}
+	if code := call(t, api, "secret", http.MethodDelete, sessionPath+"?force=true", nil, nil); code != http.StatusOK {
+		t.Errorf("DELETE %s?force=true = %d, expected %d", sessionPath, code, http.StatusOK)
+	}
+	if code := call(t, api, "secret", http.MethodDelete, sessionPath, nil, &apiErr); code != http.StatusNotFound {
+		t.Errorf("DELETE %s again = %d, expected %d", sessionPath, code, http.StatusNotFound)
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{limit: 5}
+	w.Write([]byte("abc"))
+	w.Write([]byte("defg"))
+	if got := w.String(); got != "cdefg" {
+		t.Errorf("tail = %q, expected %q", got, "cdefg")
+	}
+	w.Write([]byte("0123456789"))
+	if got := w.String(); got != "56789" {
+		t.Errorf("tail = %q, expected %q", got, "56789")
+	}
+}
+
+// call sends a request with body encoded as JSON, decodes the response into
+// v unless it is nil and returns the status code
+func call(t *testing.T, api *httptest.Server, token, method, path string, body, v any) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, api.URL+path, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
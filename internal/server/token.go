@@ -0,0 +1,47 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/remote"
+)
+
+// TokenPath returns where ccswitch serve keeps the token it generates
+func TokenPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ccswitch", "serve-token")
+}
+
+// Token returns the token the API requires: the one in remote.TokenEnv, or
+// else the one kept at TokenPath, generated the first time so that it
+// survives restarts of the server
+func Token() (string, error) {
+	if token := os.Getenv(remote.TokenEnv); token != "" {
+		return token, nil
+	}
+
+	path := TokenPath()
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate a token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to save the token: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to save the token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// UnmanagedWorktrees returns worktrees of the repository that aren't
+// tracked as ccswitch sessions, excluding the main repository and
+// worktrees in detached HEAD state
+func (m *Manager) UnmanagedWorktrees() ([]git.Worktree, error) {
+	worktrees, err := m.worktreeManager.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		managed[s.Path] = true
+	}
+
+	var unmanaged []git.Worktree
+	for i, wt := range worktrees {
+		// The first worktree is always the main repository
+		if i == 0 || wt.Branch == "" || managed[wt.Path] {
+			continue
+		}
+		unmanaged = append(unmanaged, wt)
+	}
+	return unmanaged, nil
+}
+
+// UnmanagedWorktreeAt returns the worktree containing path if it can be
+// adopted as a session, or an error explaining why not
+func (m *Manager) UnmanagedWorktreeAt(path string) (*git.Worktree, error) {
+	top := git.FindEnclosingRepository(path)
+	if top == "" {
+		return nil, fmt.Errorf("%s is not inside a git worktree", path)
+	}
+
+	worktrees, err := m.worktreeManager.List()
+	if err != nil {
+		return nil, err
+	}
+	var found *git.Worktree
+	for i := range worktrees {
+		if samePath(worktrees[i].Path, top) {
+			if i == 0 {
+				return nil, fmt.Errorf("%s is the main repository", top)
+			}
+			found = &worktrees[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s is not a worktree of this repository", top)
+	}
+	if found.Branch == "" {
+		return nil, fmt.Errorf("%s has a detached HEAD; check out a branch first", found.Path)
+	}
+
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if s.Path == found.Path {
+			return nil, fmt.Errorf("%s is already the session %s", found.Path, s.Name)
+		}
+	}
+	return found, nil
+}
+
+// samePath reports whether two paths name the same directory, resolving
+// symlinks such as /tmp on macOS
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
+// SessionNameForBranch returns the session name ccswitch uses for a branch
+func SessionNameForBranch(branch string) string {
+	return utils.Slugify(branch)
+}
+
+// AdoptWorktree registers an existing worktree as a session, keeping its path
+func (m *Manager) AdoptWorktree(wt git.Worktree, name string) error {
+	if name == "" || name == "main" {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.Name == name && s.Path != wt.Path {
+			return fmt.Errorf("session name %q is already used by %s", name, s.Path)
+		}
+	}
+
+	existing, err := m.metadata.Get(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to read session metadata")
+	}
+	if existing != nil && existing.Path != wt.Path {
+		return fmt.Errorf("session name %q is already used by %s", name, existing.Path)
+	}
+
+	err = m.metadata.Put(&Metadata{
+		Name:      name,
+		Branch:    wt.Branch,
+		Path:      wt.Path,
+		CreatedAt: time.Now(),
+	})
+	m.refreshWorkspace()
+	return err
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnmanagedWorktreeAt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	base := t.TempDir()
+	repo := filepath.Join(base, "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	foreign := filepath.Join(base, "project-hotfix")
+	runGit(t, repo, "worktree", "add", "-b", "hotfix", foreign)
+	if err := os.MkdirAll(filepath.Join(foreign, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	manager := NewManager(repo)
+
+	wt, err := manager.UnmanagedWorktreeAt(filepath.Join(foreign, "sub"))
+	if err != nil {
+		t.Fatalf("UnmanagedWorktreeAt() failed: %v", err)
+	}
+	if wt.Branch != "hotfix" {
+		t.Errorf("UnmanagedWorktreeAt() = %+v, expected the hotfix worktree", wt)
+	}
+
+	if _, err := manager.UnmanagedWorktreeAt(repo); err == nil {
+		t.Error("the main repository should not be adoptable")
+	}
+	if _, err := manager.UnmanagedWorktreeAt(t.TempDir()); err == nil {
+		t.Error("a directory outside any worktree should not be adoptable")
+	}
+
+	if err := manager.AdoptWorktree(*wt, "hotfix"); err != nil {
+		t.Fatalf("AdoptWorktree() failed: %v", err)
+	}
+	if _, err := manager.UnmanagedWorktreeAt(foreign); err == nil {
+		t.Error("an adopted worktree should not be adoptable again")
+	}
+	sessions, _ := manager.ListSessions()
+	if s := findByName(sessions, "hotfix"); s == nil || !samePath(s.Path, foreign) {
+		t.Errorf("ListSessions() should include the adopted worktree, got %+v", sessions)
+	}
+}
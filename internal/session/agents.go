@@ -0,0 +1,54 @@
+package session
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Running reports whether the agent process is still running
+func (a *AgentInfo) Running() bool {
+	return a.StoppedAt == nil && proc.Alive(a.PID)
+}
+
+// AgentLogPath returns the file the output of a background agent in the
+// named session goes to
+func (m *Manager) AgentLogPath(name string) string {
+	return filepath.Join(StateDir(m.repoName), "agents", utils.PathName(name)+".log")
+}
+
+// RecordAgent stores the agent launched in the named session
+func (m *Manager) RecordAgent(name string, agent *AgentInfo) error {
+	return m.metadata.Update(name, func(meta *Metadata) {
+		meta.Agent = agent
+	})
+}
+
+// MarkAgentStopped records that the agent of the named session was stopped
+func (m *Manager) MarkAgentStopped(name string) error {
+	return m.metadata.Update(name, func(meta *Metadata) {
+		if meta.Agent != nil {
+			now := time.Now()
+			meta.Agent.StoppedAt = &now
+		}
+	})
+}
+
+// AgentSessions returns the sessions that have had an agent launched in
+// them, sorted by name
+func (m *Manager) AgentSessions() ([]*Metadata, error) {
+	entries, err := m.metadata.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []*Metadata
+	for _, entry := range entries {
+		if entry.Agent != nil {
+			agents = append(agents, entry)
+		}
+	}
+	return agents, nil
+}
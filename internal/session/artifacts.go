@@ -0,0 +1,43 @@
+package session
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// FindArtifactDirs returns directories under worktreePath whose name matches
+// one of names. Only directories ignored by git are returned, so tracked
+// content is never reported as a disposable artifact.
+func FindArtifactDirs(worktreePath string, names []string) []string {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var dirs []string
+	_ = filepath.WalkDir(worktreePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() || path == worktreePath {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if wanted[d.Name()] {
+			if git.IsIgnored(worktreePath, path) {
+				dirs = append(dirs, path)
+			}
+			// Never descend into artifact directories
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return dirs
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetBranchType sets the {type} of the branches of sessions created from now
+// on; empty uses the first of branch.types
+func (m *Manager) SetBranchType(branchType string) {
+	m.branchType = branchType
+}
+
+// SetBase makes sessions created from now on start at base, a local or
+// remote-tracking branch, instead of the current branch. The base is
+// recorded with the session as the branch it builds on (see BaseBranch).
+func (m *Manager) SetBase(base string) error {
+	if base != "" {
+		if _, err := git.ResolveRef(m.repoPath, base); err != nil {
+			return fmt.Errorf("%w: %s", errors.ErrBranchNotFound, base)
+		}
+	}
+	m.base = base
+	return nil
+}
+
+// BranchName returns the branch a session named after description gets,
+// following the branch naming policy of the config
+func (m *Manager) BranchName(description string) (string, error) {
+	vars := config.BranchVars{Name: description, Type: m.branchType}
+	if m.config.UsesUser() {
+		vars.User = m.branchUser()
+	}
+	return m.config.BranchName(vars)
+}
+
+// branchUser returns who fills {user} in branch names: branch.user, else
+// git's user.name, else the login name
+func (m *Manager) branchUser() string {
+	if m.config.Branch.User != "" {
+		return m.config.Branch.User
+	}
+	if name, _ := git.GetConfig(m.repoPath, "user.name"); name != "" {
+		return name
+	}
+	return os.Getenv("USER")
+}
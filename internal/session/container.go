@@ -0,0 +1,56 @@
+package session
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksred/ccswitch/internal/container"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// Container returns the dev container of s, or nil if none was started
+func (m *Manager) Container(s git.SessionInfo) *container.Container {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return nil
+	}
+	return meta.Container
+}
+
+// ContainerUp builds and starts the dev container of s unless it is
+// running already, and records it with the session. The tools see the
+// session's environment, so compose files can use its ports and variables.
+func (m *Manager) ContainerUp(s git.SessionInfo, progress io.Writer) (*container.Container, bool, error) {
+	if c := m.Container(s); c != nil && c.Running() {
+		return c, false, nil
+	}
+
+	kind, err := container.Detect(s.Path, m.config.Container.Compose)
+	if err != nil {
+		return nil, false, err
+	}
+	project := container.ProjectName(m.repoName, s.Name)
+	c, err := container.Up(s.Path, kind, project, m.config.Container.Compose, m.config.Container.Service, m.Env(os.Environ(), s), progress)
+	if err != nil {
+		return nil, false, err
+	}
+	err = m.updateMetadata(s, func(meta *Metadata) {
+		meta.Container = c
+	})
+	return c, true, err
+}
+
+// ContainerDown stops and removes the dev container of s, if it has one,
+// and reports whether it did
+func (m *Manager) ContainerDown(s git.SessionInfo, progress io.Writer) (bool, error) {
+	c := m.Container(s)
+	if c == nil {
+		return false, nil
+	}
+	if err := c.Down(s.Path, m.Env(os.Environ(), s), progress); err != nil {
+		return false, err
+	}
+	return true, m.updateMetadata(s, func(meta *Metadata) {
+		meta.Container = nil
+	})
+}
@@ -0,0 +1,311 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestCreateSessionStrategies(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(filepath.Join(repo, "api"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "web"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+	commitFile(t, repo, "api/main.go", "package main\n")
+	commitFile(t, repo, "web/index.html", "<html>\n")
+
+	manager := NewManager(repo)
+	cfg := manager.Config()
+	cfg.Sparse = map[string][]string{"backend": {"api"}}
+
+	tests := []struct {
+		name     string
+		create   func() error
+		strategy string
+		expected map[string]bool
+	}{
+		{"full", func() error { return manager.CreateSession("full") }, "",
+			map[string]bool{"README.md": true, "api/main.go": true, "web/index.html": true}},
+		{"sparse", func() error { return manager.CreateSession("sparse") }, config.StrategySparse,
+			map[string]bool{"README.md": true, "api/main.go": false, "web/index.html": false}},
+		{"profile", func() error { return manager.CreateSparseSession("profile", "backend") }, "",
+			map[string]bool{"README.md": true, "api/main.go": true, "web/index.html": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Worktree.CreationStrategy = tt.strategy
+			if err := tt.create(); err != nil {
+				t.Fatalf("creating session failed: %v", err)
+			}
+			path := manager.GetSessionPath(tt.name)
+			for name, expected := range tt.expected {
+				if _, err := os.Stat(filepath.Join(path, name)); (err == nil) != expected {
+					t.Errorf("%s present = %v, expected %v", name, err == nil, expected)
+				}
+			}
+		})
+	}
+
+	cfg.Worktree.CreationStrategy = "shallow"
+	if err := manager.CreateSession("invalid"); err == nil {
+		t.Error("CreateSession() with an unknown strategy should fail")
+	}
+	if err := manager.CreateSparseSession("missing", "frontend"); err == nil {
+		t.Error("CreateSparseSession() with an unknown profile should fail")
+	}
+}
+
+func TestCreateSessionBranchTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	manager := NewManager(repo)
+	cfg := manager.Config()
+	cfg.Branch.Template = "{user}/{type}/{name}"
+	cfg.Branch.Types = []string{"feat", "fix"}
+
+	manager.SetBranchType("fix")
+	meta, err := manager.NewSession("Login Redirect")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+	if meta.Name != "login-redirect" || meta.Branch != "test-user/fix/login-redirect" {
+		t.Errorf("NewSession() = %s on %s, expected login-redirect on test-user/fix/login-redirect", meta.Name, meta.Branch)
+	}
+
+	manager.SetBranchType("wip")
+	if _, err := manager.NewSession("other"); err == nil {
+		t.Error("NewSession() should refuse a type not in branch.types")
+	}
+}
+
+func TestCreateSessionFromBase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+	runGit(t, repo, "branch", "develop")
+	commitFile(t, repo, "main.txt", "main only\n")
+
+	manager := NewManager(repo)
+	if err := manager.SetBase("no-such-branch"); err == nil {
+		t.Error("SetBase() should refuse a branch that does not exist")
+	}
+	if err := manager.SetBase("develop"); err != nil {
+		t.Fatalf("SetBase() failed: %v", err)
+	}
+	meta, err := manager.NewSession("from develop")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+
+	if meta.BaseBranch != "develop" {
+		t.Errorf("BaseBranch = %q, expected develop", meta.BaseBranch)
+	}
+	if got := manager.BaseBranch(git.SessionInfo{Path: meta.Path}); got != "develop" {
+		t.Errorf("Manager.BaseBranch() = %q, expected develop", got)
+	}
+	if _, err := os.Stat(filepath.Join(meta.Path, "main.txt")); !os.IsNotExist(err) {
+		t.Error("session should start at develop, without main's commits")
+	}
+}
+
+func TestCreateSessionWithIssue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	manager := NewManager(repo)
+	manager.Config().Issues.URL = "https://issues.example.com/{id}"
+
+	if err := manager.SetIssue("AUTH-1", "ftp://example.com"); err == nil {
+		t.Error("SetIssue() should refuse a link that is not an http(s) URL")
+	}
+	if err := manager.SetIssue("AUTH-1", ""); err != nil {
+		t.Fatalf("SetIssue() failed: %v", err)
+	}
+	tracked, err := manager.NewSession("tracked")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+	issue, url := manager.Issue(git.SessionInfo{Path: tracked.Path})
+	if issue != "AUTH-1" || url != "https://issues.example.com/AUTH-1" {
+		t.Errorf("Issue() = %q, %q", issue, url)
+	}
+
+	if err := manager.SetIssue("", "https://example.com/task/7"); err != nil {
+		t.Fatalf("SetIssue() failed: %v", err)
+	}
+	linked, err := manager.NewSession("linked")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+	if issue, url := manager.Issue(git.SessionInfo{Path: linked.Path}); issue != "" || url != "https://example.com/task/7" {
+		t.Errorf("Issue() = %q, %q", issue, url)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Name == "tracked" && (s.Issue != "AUTH-1" || s.IssueURL != "https://issues.example.com/AUTH-1") {
+			t.Errorf("status of tracked = %+v", s)
+		}
+	}
+}
+
+func TestCreateSessionWithIdentity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	manager := NewManager(repo)
+	cfg := manager.Config()
+	cfg.Branch.Types = []string{"feat", "oss"}
+	cfg.Identities = map[string]config.Identity{
+		"work": {Name: "Jo Work", Email: "jo@work.example.com", SigningKey: "ABCD1234"},
+		"oss":  {Email: "jo@users.noreply.example.com", Types: []string{"oss"}},
+	}
+	cfg.Identity = "work"
+
+	if err := manager.SetIdentity("home"); err == nil {
+		t.Error("SetIdentity() should refuse an unknown identity")
+	}
+
+	work, err := manager.NewSession("work task")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+	name, identity := manager.Identity(git.SessionInfo{Path: work.Path})
+	if name != "work" || identity.String() != "Jo Work <jo@work.example.com>" || identity.SigningKey != "ABCD1234" {
+		t.Errorf("Identity() = %q, %+v", name, identity)
+	}
+
+	manager.SetBranchType("oss")
+	oss, err := manager.NewSession("oss task")
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+	if name, identity := manager.Identity(git.SessionInfo{Path: oss.Path}); name != "oss" || identity.String() != "Test User <jo@users.noreply.example.com>" {
+		t.Errorf("Identity() = %q, %+v", name, identity)
+	}
+
+	// The repository itself keeps its identity
+	if email, _ := git.GetConfig(repo, "user.email"); email != "test@example.com" {
+		t.Errorf("user.email of the repository = %q", email)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Name == "work-task" && (s.Identity != "work" || s.UserEmail != "jo@work.example.com") {
+			t.Errorf("status of work-task = %+v", s)
+		}
+	}
+}
+
+func TestCreateDetachedSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "v1\n")
+	runGit(t, repo, "tag", "v1.0")
+	commitFile(t, repo, "README.md", "v2\n")
+
+	manager := NewManager(repo)
+	if _, err := manager.CreateDetachedSession("bad", "v9.9", nil); err == nil {
+		t.Error("CreateDetachedSession() should refuse an unknown tag")
+	}
+	meta, err := manager.CreateDetachedSession("repro release", "v1.0", nil)
+	if err != nil {
+		t.Fatalf("CreateDetachedSession() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(meta.Path, "README.md")); string(data) != "v1\n" {
+		t.Errorf("README.md = %q, expected the tagged version", data)
+	}
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() failed: %v", err)
+	}
+	var found *git.SessionInfo
+	for i := range sessions {
+		if sessions[i].Name == "repro-release" {
+			found = &sessions[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("ListSessions() = %+v, expected the detached session", sessions)
+	}
+	if !found.Detached() || found.BranchLabel() != git.DetachedLabel || manager.Ref(*found) != "v1.0" {
+		t.Errorf("detached session = %+v, ref %q", *found, manager.Ref(*found))
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Name == "repro-release" && (!s.Detached || s.Ref != "v1.0" || s.Behind != 1) {
+			t.Errorf("status of repro-release = %+v", s)
+		}
+	}
+
+	renamed, err := manager.RenameSession(*found, "old release", false)
+	if err != nil {
+		t.Fatalf("RenameSession() failed: %v", err)
+	}
+	if renamed.Name != "old-release" || !renamed.Detached() {
+		t.Errorf("RenameSession() = %+v", renamed)
+	}
+}
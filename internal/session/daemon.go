@@ -0,0 +1,101 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SyncReport is what the last pass of 'ccswitch daemon' found
+type SyncReport struct {
+	At time.Time `json:"at"`
+	// Next is when the daemon will look again
+	Next time.Time `json:"next"`
+	// Base is what sessions were compared to, e.g. origin/main
+	Base       string          `json:"base"`
+	FetchError string          `json:"fetch_error,omitempty"`
+	Behind     []BehindSession `json:"behind"`
+	// Rebased are the sessions the pass fanned out to
+	Rebased []string `json:"rebased,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// BehindSession is a session missing commits of the base
+type BehindSession struct {
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+	Dirty  bool   `json:"dirty"`
+	Ahead  int    `json:"ahead"`
+	Behind int    `json:"behind"`
+}
+
+// Stale reports whether the daemon missed its next pass by a whole
+// interval, a sign that it is no longer running
+func (r SyncReport) Stale(now time.Time) bool {
+	return now.After(r.Next.Add(r.Next.Sub(r.At)))
+}
+
+// syncReportPath returns the file the daemon writes its report to
+func syncReportPath(repoName string) string {
+	return filepath.Join(StateDir(repoName), "sync.json")
+}
+
+// BehindSessions returns the sessions with a branch that are behind base
+func (m *Manager) BehindSessions(base string) ([]BehindSession, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var behind []BehindSession
+	for _, s := range sessions {
+		if s.Detached() {
+			continue
+		}
+		status, err := git.GetWorktreeStatus(s.Path, base)
+		if err != nil || status.Behind == 0 {
+			continue
+		}
+		behind = append(behind, BehindSession{
+			Name: s.Name, Branch: s.Branch, Path: s.Path,
+			Dirty: status.Dirty, Ahead: status.Ahead, Behind: status.Behind,
+		})
+	}
+	return behind, nil
+}
+
+// SaveSyncReport replaces the daemon's report
+func (m *Manager) SaveSyncReport(report SyncReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	path := syncReportPath(m.repoName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// SyncReport returns the daemon's last report, or nil if it never ran
+func (m *Manager) SyncReport() *SyncReport {
+	data, err := os.ReadFile(syncReportPath(m.repoName))
+	if err != nil {
+		return nil
+	}
+	var report SyncReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil
+	}
+	return &report
+}
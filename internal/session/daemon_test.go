@@ -0,0 +1,63 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBehindSessionsAndSyncReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	for _, name := range []string{"stale", "fresh"} {
+		if err := manager.CreateSession(name); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", name, err)
+		}
+	}
+	sessions, _ := manager.ListSessions()
+	fresh := findByName(sessions, "fresh")
+	if fresh == nil {
+		t.Fatalf("fresh session not found in %+v", sessions)
+	}
+
+	commitFile(t, repo, "main.txt", "main\n")
+	runGit(t, fresh.Path, "rebase", "main")
+
+	behind, err := manager.BehindSessions("main")
+	if err != nil {
+		t.Fatalf("BehindSessions() failed: %v", err)
+	}
+	if len(behind) != 1 || behind[0].Name != "stale" || behind[0].Behind != 1 {
+		t.Errorf("BehindSessions() = %+v, expected only stale, 1 behind", behind)
+	}
+
+	if manager.SyncReport() != nil {
+		t.Error("SyncReport() before the daemon ran should be nil")
+	}
+	at := time.Now().Add(-time.Hour).Truncate(time.Second)
+	report := SyncReport{At: at, Next: at.Add(15 * time.Minute), Base: "main", Behind: behind}
+	if err := manager.SaveSyncReport(report); err != nil {
+		t.Fatalf("SaveSyncReport() failed: %v", err)
+	}
+	saved := manager.SyncReport()
+	if saved == nil || !saved.At.Equal(at) || len(saved.Behind) != 1 || saved.Behind[0].Branch != behind[0].Branch {
+		t.Fatalf("SyncReport() = %+v, expected %+v", saved, report)
+	}
+	if !saved.Stale(time.Now()) {
+		t.Error("a report whose next pass is 45m overdue should be stale")
+	}
+	if saved.Stale(at.Add(20 * time.Minute)) {
+		t.Error("a report 5m past its next pass should not be stale yet")
+	}
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// CreateDetachedSession creates a session pinned to ref, a tag or commit,
+// whose worktree has a detached HEAD instead of a new branch: for bisecting
+// or reproducing a release. sparse limits the checkout as for other
+// sessions. The ref is recorded with the session.
+func (m *Manager) CreateDetachedSession(description, ref string, sparse []string) (*Metadata, error) {
+	sessionName := utils.Slugify(description)
+	if sessionName == "" {
+		return nil, fmt.Errorf("invalid session name: %q", description)
+	}
+	commit, err := git.ResolveRef(m.repoPath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unknown tag or commit %q: %w", ref, err)
+	}
+	identityName, identity, err := m.config.SessionIdentity(m.identity, m.branchType)
+	if err != nil {
+		return nil, err
+	}
+
+	worktreePath := m.GetSessionPath(sessionName)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create worktree directory")
+	}
+
+	// A commit rather than a branch name is checked out detached
+	strategy, err := m.config.CreationStrategy()
+	if err != nil {
+		return nil, err
+	}
+	if len(sparse) > 0 || strategy == config.StrategySparse {
+		err = m.worktreeManager.CreateSparse(worktreePath, commit, sparse)
+	} else {
+		err = m.worktreeManager.CreateDetached(worktreePath, commit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		if err := applyIdentity(worktreePath, identity); err != nil {
+			_ = m.worktreeManager.Remove(worktreePath)
+			return nil, err
+		}
+	}
+
+	entry := m.recordSession(sessionName, "", "", worktreePath)
+	entry.Ref, entry.Identity = ref, identityName
+	_ = m.metadata.Put(entry)
+	return entry, nil
+}
+
+// Ref returns the tag or commit detached session s was created at, if it
+// was created by CreateDetachedSession
+func (m *Manager) Ref(s git.SessionInfo) string {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return ""
+	}
+	return meta.Ref
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// DigestSession summarises a single session's recent activity
+type DigestSession struct {
+	Name         string
+	Branch       string
+	Path         string
+	CreatedAt    time.Time
+	IsNew        bool
+	Commits      []git.Commit
+	LastActivity time.Time
+	Stale        bool
+	Dirty        bool
+	Conflicts    bool
+}
+
+// Digest is a rollup of session activity over a time window
+type Digest struct {
+	Repo        string
+	Base        string
+	Since       time.Time
+	GeneratedAt time.Time
+	Sessions    []DigestSession
+}
+
+// BuildDigest collects activity for all sessions since the given time.
+// Sessions with no activity for longer than staleAfter are marked stale, and
+// each session is checked for conflicts against the current branch.
+func (m *Manager) BuildDigest(since time.Time, staleAfter time.Duration) (*Digest, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := m.GetCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	digest := &Digest{
+		Repo:        m.repoName,
+		Base:        base,
+		Since:       since,
+		GeneratedAt: now,
+	}
+
+	for _, s := range sessions {
+		if s.Name == "main" || s.Branch == base {
+			continue
+		}
+
+		entry := DigestSession{
+			Name:   s.Name,
+			Branch: s.Branch,
+			Path:   s.Path,
+			Dirty:  git.DefaultStatusCache.HasUncommittedChanges(s.Path),
+		}
+
+		if meta, err := m.metadata.Get(s.Name); err == nil && meta != nil {
+			entry.CreatedAt = meta.CreatedAt
+			entry.IsNew = meta.CreatedAt.After(since)
+		}
+
+		if commits, err := git.GetCommits(s.Path, base+".."+s.Branch, since); err == nil {
+			entry.Commits = commits
+		}
+
+		entry.LastActivity = entry.CreatedAt
+		if last, err := git.GetLastCommitTime(s.Path, s.Branch); err == nil && last.After(entry.LastActivity) {
+			entry.LastActivity = last
+		}
+		entry.Stale = !entry.Dirty && now.Sub(entry.LastActivity) > staleAfter
+
+		if conflicts, err := git.HasMergeConflicts(m.repoPath, base, s.Branch); err == nil {
+			entry.Conflicts = conflicts
+		}
+
+		digest.Sessions = append(digest.Sessions, entry)
+	}
+
+	return digest, nil
+}
+
+// NewSessions returns the sessions created within the digest window
+func (d *Digest) NewSessions() []DigestSession {
+	return d.filter(func(s DigestSession) bool { return s.IsNew })
+}
+
+// ActiveSessions returns the sessions with commits within the digest window
+func (d *Digest) ActiveSessions() []DigestSession {
+	return d.filter(func(s DigestSession) bool { return len(s.Commits) > 0 })
+}
+
+// StaleSessions returns the sessions with no recent activity
+func (d *Digest) StaleSessions() []DigestSession {
+	return d.filter(func(s DigestSession) bool { return s.Stale })
+}
+
+// ConflictedSessions returns the sessions that would conflict with the base branch
+func (d *Digest) ConflictedSessions() []DigestSession {
+	return d.filter(func(s DigestSession) bool { return s.Conflicts })
+}
+
+// TotalCommits returns the number of commits across all sessions
+func (d *Digest) TotalCommits() int {
+	total := 0
+	for _, s := range d.Sessions {
+		total += len(s.Commits)
+	}
+	return total
+}
+
+func (d *Digest) filter(keep func(DigestSession) bool) []DigestSession {
+	var result []DigestSession
+	for _, s := range d.Sessions {
+		if keep(s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
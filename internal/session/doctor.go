@@ -0,0 +1,211 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Kinds of problem found by Diagnose
+const (
+	// ProblemMissingDir is a registered worktree whose directory is gone,
+	// e.g. after rm -rf
+	ProblemMissingDir = "missing-dir"
+	// ProblemLocked is a worktree locked with git worktree lock, which
+	// git refuses to remove or prune
+	ProblemLocked = "locked"
+	// ProblemStaleMetadata is session metadata for a worktree git no
+	// longer knows about
+	ProblemStaleMetadata = "stale-metadata"
+	// ProblemOrphanDir is a directory in the worktree root that is not a
+	// registered worktree
+	ProblemOrphanDir = "orphan-dir"
+)
+
+// Problem is an inconsistency between git's worktrees, the worktree root on
+// disk and the session metadata
+type Problem struct {
+	Kind string
+	// Name is the session name, or the directory name if there is none
+	Name   string
+	Path   string
+	Detail string
+	// Locked is set for missing worktrees that must be unlocked before
+	// they can be pruned
+	Locked bool
+}
+
+// Fix describes what Fix does about the problem
+func (p Problem) Fix() string {
+	switch p.Kind {
+	case ProblemMissingDir:
+		if p.Locked {
+			return "unlock and prune the worktree registration"
+		}
+		return "prune the worktree registration"
+	case ProblemLocked:
+		return "unlock the worktree"
+	case ProblemStaleMetadata:
+		return "forget the session"
+	case ProblemOrphanDir:
+		return "delete the directory and its contents"
+	}
+	return ""
+}
+
+// Diagnose finds registered worktrees whose directories are missing, locked
+// worktrees, metadata of sessions whose worktrees git no longer knows about,
+// and directories in the worktree root that are not worktrees
+func (m *Manager) Diagnose() ([]Problem, error) {
+	worktrees, err := m.worktreeManager.List()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := m.metadata.All()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		names[entry.Path] = entry.Name
+	}
+	nameOf := func(path string) string {
+		if name, ok := names[path]; ok {
+			return name
+		}
+		return utils.NameFromPath(filepath.Base(path))
+	}
+
+	isRegistered := func(path string) bool {
+		for _, wt := range worktrees {
+			if samePath(wt.Path, path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var problems []Problem
+	for i, wt := range worktrees {
+		// The first worktree is always the main repository
+		if i == 0 {
+			continue
+		}
+
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			problems = append(problems, Problem{
+				Kind:   ProblemMissingDir,
+				Name:   nameOf(wt.Path),
+				Path:   wt.Path,
+				Detail: "worktree is registered but its directory no longer exists",
+				Locked: wt.Locked,
+			})
+			continue
+		}
+		if wt.Locked {
+			detail := "worktree is locked"
+			if wt.LockReason != "" {
+				detail += ": " + wt.LockReason
+			}
+			problems = append(problems, Problem{
+				Kind:   ProblemLocked,
+				Name:   nameOf(wt.Path),
+				Path:   wt.Path,
+				Detail: detail,
+			})
+		}
+	}
+
+	for _, entry := range entries {
+		if !isRegistered(entry.Path) {
+			problems = append(problems, Problem{
+				Kind:   ProblemStaleMetadata,
+				Name:   entry.Name,
+				Path:   entry.Path,
+				Detail: "session metadata refers to a worktree git does not know about",
+			})
+		}
+	}
+
+	for _, dir := range m.worktreeRootDirs() {
+		if !isRegistered(dir) {
+			problems = append(problems, Problem{
+				Kind:   ProblemOrphanDir,
+				Name:   utils.NameFromPath(filepath.Base(dir)),
+				Path:   dir,
+				Detail: "directory in the worktree root is not a registered worktree",
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// worktreeRootDirs returns the directories in the repository's worktree
+// root. Sibling worktrees have no root of their own, so none are returned.
+func (m *Manager) worktreeRootDirs() []string {
+	if m.config.Worktree.Root == config.SiblingWorktrees {
+		return nil
+	}
+	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
+	if err != nil {
+		return nil
+	}
+	root := filepath.Dir(m.config.WorktreePath(mainRepoPath, "session"))
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// Fix repairs a problem found by Diagnose
+func (m *Manager) Fix(p Problem) error {
+	switch p.Kind {
+	case ProblemMissingDir:
+		if p.Locked {
+			if err := m.worktreeManager.Unlock(p.Path); err != nil {
+				return err
+			}
+		}
+		if err := m.worktreeManager.Prune(); err != nil {
+			return err
+		}
+		m.forget(p.Path)
+		return nil
+
+	case ProblemLocked:
+		return m.worktreeManager.Unlock(p.Path)
+
+	case ProblemStaleMetadata:
+		m.forget(p.Path)
+		return nil
+
+	case ProblemOrphanDir:
+		if err := os.RemoveAll(p.Path); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", p.Path, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown problem kind %q", p.Kind)
+}
+
+// forget deletes the metadata and heartbeat of the session at path
+func (m *Manager) forget(path string) {
+	if entry, err := m.metadata.FindByPath(path); err == nil && entry != nil {
+		_ = m.metadata.Delete(entry.Name)
+	}
+	m.removeHeartbeat(path)
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiagnoseAndFix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	for _, name := range []string{"removed", "locked"} {
+		if err := manager.CreateSession(name); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", name, err)
+		}
+	}
+	removed := manager.GetSessionPath("removed")
+	locked := manager.GetSessionPath("locked")
+	stray := manager.GetSessionPath("stray")
+
+	if err := os.RemoveAll(removed); err != nil {
+		t.Fatalf("Failed to remove worktree: %v", err)
+	}
+	runGit(t, repo, "worktree", "lock", "--reason", "on a usb drive", locked)
+	if err := os.MkdirAll(stray, 0755); err != nil {
+		t.Fatalf("Failed to create stray directory: %v", err)
+	}
+	if err := manager.metadata.Put(&Metadata{Name: "gone", Branch: "feature/gone", Path: manager.GetSessionPath("gone"), CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to store metadata: %v", err)
+	}
+
+	problems, err := manager.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose() failed: %v", err)
+	}
+	found := make(map[string]Problem)
+	for _, p := range problems {
+		found[p.Kind+" "+p.Name] = p
+	}
+	expected := []string{
+		ProblemMissingDir + " removed",
+		ProblemLocked + " locked",
+		ProblemStaleMetadata + " gone",
+		ProblemOrphanDir + " stray",
+	}
+	if len(problems) != len(expected) {
+		t.Errorf("Diagnose() = %+v, expected %v", problems, expected)
+	}
+	for _, key := range expected {
+		if _, ok := found[key]; !ok {
+			t.Errorf("Diagnose() did not report %s, got %+v", key, problems)
+		}
+	}
+	if p := found[ProblemLocked+" locked"]; p.Detail != "worktree is locked: on a usb drive" {
+		t.Errorf("locked problem detail = %q", p.Detail)
+	}
+
+	for _, p := range problems {
+		if err := manager.Fix(p); err != nil {
+			t.Errorf("Fix(%s %s) failed: %v", p.Kind, p.Name, err)
+		}
+	}
+
+	if problems, err = manager.Diagnose(); err != nil || len(problems) != 0 {
+		t.Errorf("Diagnose() after fixing = %+v, %v, expected no problems", problems, err)
+	}
+	if _, err := os.Stat(stray); !os.IsNotExist(err) {
+		t.Error("the stray directory should have been deleted")
+	}
+	if entry, _ := manager.metadata.Get("removed"); entry != nil {
+		t.Error("metadata of the removed session should have been deleted")
+	}
+	sessions, _ := manager.ListSessions()
+	if findByName(sessions, "removed") != nil || findByName(sessions, "locked") == nil {
+		t.Errorf("ListSessions() after fixing = %+v", sessions)
+	}
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// EnvPrefix starts the names of the variables describing a session to the
+// commands run in it
+const EnvPrefix = "CCSWITCH_"
+
+// BaseBranch returns the branch a session builds on: its stack parent, the
+// branch it was created from, or else the main repository's current branch
+func (m *Manager) BaseBranch(s git.SessionInfo) string {
+	if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil {
+		if meta.Parent != "" {
+			return meta.Parent
+		}
+		if meta.BaseBranch != "" {
+			return meta.BaseBranch
+		}
+	}
+
+	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
+	if err != nil {
+		mainRepoPath = m.repoPath
+	}
+	branch, _ := git.GetCurrentBranch(mainRepoPath)
+	return branch
+}
+
+// Env returns the environment for a command run in s: environ with
+// CCSWITCH_SESSION, CCSWITCH_BRANCH, CCSWITCH_WORKTREE and
+// CCSWITCH_BASE_BRANCH set to describe s, along with its SessionVars. A
+// block of ports that can't be allocated is left out.
+func (m *Manager) Env(environ []string, s git.SessionInfo) []string {
+	vars, _ := m.SessionVars(s)
+	return sessionEnv(environ, s, m.BaseBranch(s), vars...)
+}
+
+// sessionEnv returns environ with the variables describing s and extra
+// set, replacing any values it had
+func sessionEnv(environ []string, s git.SessionInfo, baseBranch string, extra ...string) []string {
+	vars := append(slices.Clip(extra),
+		EnvPrefix+"SESSION="+s.Name,
+		EnvPrefix+"BRANCH="+s.Branch,
+		EnvPrefix+"WORKTREE="+s.Path,
+		EnvPrefix+"BASE_BRANCH="+baseBranch,
+	)
+
+	set := make(map[string]bool, len(vars))
+	for _, kv := range vars {
+		set[envName(kv)] = true
+	}
+
+	env := make([]string, 0, len(environ)+len(vars))
+	for _, kv := range environ {
+		// Replace values left by an outer ccswitch, e.g. exec inside work
+		if !set[envName(kv)] {
+			env = append(env, kv)
+		}
+	}
+	return append(env, vars...)
+}
+
+// envName returns the name part of a NAME=value environment entry
+func envName(kv string) string {
+	name, _, _ := strings.Cut(kv, "=")
+	return name
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestSessionEnv(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"CCSWITCH_SESSION=outer",
+		"CCSWITCH_BASE_BRANCH=develop",
+		"CCSWITCH_OTHER=kept",
+		"HOME=/home/dev",
+	}
+	s := git.SessionInfo{Name: "auth", Branch: "feature/auth", Path: "/tmp/auth"}
+
+	got := sessionEnv(environ, s, "main", "PATH=/opt/bin", "PORT=3010")
+	expected := []string{
+		"CCSWITCH_OTHER=kept",
+		"HOME=/home/dev",
+		"PATH=/opt/bin",
+		"PORT=3010",
+		"CCSWITCH_SESSION=auth",
+		"CCSWITCH_BRANCH=feature/auth",
+		"CCSWITCH_WORKTREE=/tmp/auth",
+		"CCSWITCH_BASE_BRANCH=main",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("sessionEnv() = %v, expected %v", got, expected)
+	}
+}
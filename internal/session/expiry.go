@@ -0,0 +1,59 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetTTL makes sessions created from now on expire ttl after they are
+// created; zero lets them live for ever
+func (m *Manager) SetTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("invalid TTL %s: it must be positive", ttl)
+	}
+	m.ttl = ttl
+	return nil
+}
+
+// ExpiresAt returns when s expires, or nil if it was created without a TTL
+func (m *Manager) ExpiresAt(s git.SessionInfo) *time.Time {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return nil
+	}
+	return meta.ExpiresAt
+}
+
+// Expired returns the sessions whose TTL ran out by now, keyed by worktree
+// path
+func (m *Manager) Expired(now time.Time) map[string]bool {
+	expired := make(map[string]bool)
+	entries, err := m.metadata.All()
+	if err != nil {
+		return expired
+	}
+	for _, entry := range entries {
+		if entry.ExpiresAt != nil && !now.Before(*entry.ExpiresAt) {
+			expired[entry.Path] = true
+		}
+	}
+	return expired
+}
+
+// ExpiredSessions returns the sessions whose TTL ran out by now
+func (m *Manager) ExpiredSessions(now time.Time) ([]git.SessionInfo, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	expired := m.Expired(now)
+	var result []git.SessionInfo
+	for _, s := range sessions {
+		if expired[s.Path] {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.SetTTL(-time.Hour); err == nil {
+		t.Error("SetTTL() accepted a negative TTL")
+	}
+	if err := manager.CreateSession("forever"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if err := manager.SetTTL(48 * time.Hour); err != nil {
+		t.Fatalf("SetTTL() failed: %v", err)
+	}
+	if err := manager.CreateSession("experiment"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	forever, experiment := findByName(sessions, "forever"), findByName(sessions, "experiment")
+
+	if expires := manager.ExpiresAt(*forever); expires != nil {
+		t.Errorf("ExpiresAt() of a session without a TTL = %v, expected none", expires)
+	}
+	expires := manager.ExpiresAt(*experiment)
+	if expires == nil || time.Until(*expires) < 47*time.Hour || time.Until(*expires) > 48*time.Hour {
+		t.Fatalf("ExpiresAt() = %v, expected in two days", expires)
+	}
+
+	if expired, _ := manager.ExpiredSessions(time.Now()); len(expired) != 0 {
+		t.Errorf("ExpiredSessions() now = %v, expected none", expired)
+	}
+	later := expires.Add(time.Minute)
+	expired, err := manager.ExpiredSessions(later)
+	if err != nil || len(expired) != 1 || expired[0].Name != "experiment" {
+		t.Errorf("ExpiredSessions() after the TTL = %v, %v; expected experiment", expired, err)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if (s.ExpiresAt != nil) != (s.Name == "experiment") || s.Expired {
+			t.Errorf("Status() reports %s expiring at %v, expired %v", s.Name, s.ExpiresAt, s.Expired)
+		}
+	}
+}
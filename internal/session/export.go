@@ -0,0 +1,171 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SessionSetVersion is the version of the session set file format
+const SessionSetVersion = 1
+
+// SessionSet is a shareable description of sessions, written by export and
+// read by import to recreate them in another clone of the repository
+type SessionSet struct {
+	Version  int                 `yaml:"version"`
+	Repo     string              `yaml:"repo,omitempty"`
+	Sessions []SessionDefinition `yaml:"sessions"`
+}
+
+// SessionDefinition is what it takes to recreate a session: its branch and
+// what is recorded with it, but not its working tree. Personal settings,
+// such as the identity it commits as, are left out.
+type SessionDefinition struct {
+	Name string `yaml:"name"`
+	// Branch is empty for detached sessions, which have Ref instead
+	Branch string `yaml:"branch,omitempty"`
+	Ref    string `yaml:"ref,omitempty"`
+	// Base is the branch the session builds on, and Parent the session
+	// branch a stacked session was created from
+	Base   string            `yaml:"base,omitempty"`
+	Parent string            `yaml:"parent,omitempty"`
+	Issue  string            `yaml:"issue,omitempty"`
+	Link   string            `yaml:"link,omitempty"`
+	Tags   []string          `yaml:"tags,omitempty"`
+	Env    map[string]string `yaml:"env,omitempty"`
+	Notes  string            `yaml:"notes,omitempty"`
+}
+
+// ExportSessions describes sessions, the main repository excluded, for
+// ImportSession to recreate them elsewhere
+func (m *Manager) ExportSessions(sessions []git.SessionInfo) *SessionSet {
+	set := &SessionSet{Version: SessionSetVersion, Repo: m.repoName}
+	for _, s := range sessions {
+		if s.Name == "main" {
+			continue
+		}
+		def := SessionDefinition{Name: s.Name, Branch: s.Branch, Base: m.BaseBranch(s)}
+		if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil {
+			def.Ref, def.Parent = meta.Ref, meta.Parent
+			def.Issue, def.Link = meta.Issue, meta.Link
+			def.Tags, def.Env, def.Notes = meta.Tags, meta.Env, meta.Notes
+		}
+		set.Sessions = append(set.Sessions, def)
+	}
+	return set
+}
+
+// MarshalSessionSet encodes set as YAML
+func MarshalSessionSet(set *SessionSet) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(set); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseSessionSet decodes a session set written by export
+func ParseSessionSet(data []byte) (*SessionSet, error) {
+	var set SessionSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid session file: %w", err)
+	}
+	if set.Version > SessionSetVersion {
+		return nil, fmt.Errorf("session file version %d is newer than this ccswitch supports (%d)", set.Version, SessionSetVersion)
+	}
+	for i, def := range set.Sessions {
+		if utils.Slugify(def.Name) == "" {
+			return nil, fmt.Errorf("invalid session file: session %d has no name", i+1)
+		}
+		if def.Branch == "" && def.Ref == "" {
+			return nil, fmt.Errorf("invalid session file: %s has neither a branch nor a ref", def.Name)
+		}
+		if _, err := normalizeTags(def.Tags); err != nil {
+			return nil, fmt.Errorf("invalid session file: %s: %w", def.Name, err)
+		}
+	}
+	return &set, nil
+}
+
+// ImportSession recreates the session def describes. An existing local
+// branch is checked out; otherwise the branch is created from its
+// counterpart on origin if there is one, or else from the session's base,
+// falling back to the current branch if the base doesn't exist here.
+func (m *Manager) ImportSession(def SessionDefinition) (*Metadata, error) {
+	if def.Ref != "" && def.Branch == "" {
+		entry, err := m.CreateDetachedSession(def.Name, def.Ref, nil)
+		if err != nil {
+			return nil, err
+		}
+		return entry, m.applyDefinition(entry, def)
+	}
+
+	sessionName := utils.Slugify(def.Name)
+	currentBranch, err := m.branchManager.GetCurrent()
+	if err == nil && currentBranch == def.Branch {
+		return nil, fmt.Errorf("%w: %s", errors.ErrAlreadyOnBranch, def.Branch)
+	}
+
+	worktreePath := m.GetSessionPath(sessionName)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create worktree directory")
+	}
+
+	created := false
+	if !m.branchManager.Exists(def.Branch) {
+		startPoint := "origin/" + def.Branch
+		if _, err := git.ResolveRef(m.repoPath, startPoint); err != nil {
+			startPoint = def.Base
+			if _, err := git.ResolveRef(m.repoPath, startPoint); startPoint == "" || err != nil {
+				startPoint = currentBranch
+			}
+		}
+		if err := m.branchManager.CreateFrom(def.Branch, startPoint); err != nil {
+			return nil, err
+		}
+		created = true
+	}
+
+	if err := m.addWorktree(worktreePath, def.Branch, nil); err != nil {
+		if created {
+			_ = m.branchManager.Delete(def.Branch, false)
+		}
+		return nil, err
+	}
+
+	base := def.Base
+	if base == "" {
+		base = currentBranch
+	}
+	entry := m.recordSession(sessionName, def.Branch, base, worktreePath)
+	return entry, m.applyDefinition(entry, def)
+}
+
+// applyDefinition records what def holds besides the branch with entry
+func (m *Manager) applyDefinition(entry *Metadata, def SessionDefinition) error {
+	entry.Parent = def.Parent
+	entry.Issue, entry.Link = def.Issue, def.Link
+	entry.Tags, _ = normalizeTags(def.Tags)
+	entry.Env, entry.Notes = def.Env, def.Notes
+	if err := m.metadata.Put(entry); err != nil {
+		return err
+	}
+	if m.config.Env.File {
+		_, _ = m.WriteEnvFile(git.SessionInfo{Name: entry.Name, Branch: entry.Branch, Path: entry.Path})
+	}
+	return nil
+}
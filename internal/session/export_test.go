@@ -0,0 +1,90 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.SetTags([]string{"backend"}); err != nil {
+		t.Fatalf("SetTags() failed: %v", err)
+	}
+	if err := manager.CreateSession("pushed"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if err := manager.SetTags(nil); err != nil {
+		t.Fatalf("SetTags() failed: %v", err)
+	}
+	if err := manager.CreateSession("local only"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	pushed := findByName(sessions, "pushed")
+	commitFile(t, pushed.Path, "pushed.txt", "work\n")
+	if _, err := manager.AppendNote(*pushed, "halfway there"); err != nil {
+		t.Fatalf("AppendNote() failed: %v", err)
+	}
+
+	data, err := MarshalSessionSet(manager.ExportSessions(sessions))
+	if err != nil {
+		t.Fatalf("MarshalSessionSet() failed: %v", err)
+	}
+	set, err := ParseSessionSet(data)
+	if err != nil {
+		t.Fatalf("ParseSessionSet() failed: %v\n%s", err, data)
+	}
+	if len(set.Sessions) != 2 {
+		t.Fatalf("exported %d sessions, expected 2 without main:\n%s", len(set.Sessions), data)
+	}
+
+	// A teammate's clone has the pushed branch on origin only
+	clone := filepath.Join(t.TempDir(), "project")
+	runGit(t, filepath.Dir(clone), "clone", "-q", repo, clone)
+	runGit(t, clone, "update-ref", "-d", "refs/remotes/origin/feature/local-only")
+	t.Setenv("HOME", t.TempDir())
+
+	teammate := NewManager(clone)
+	for _, def := range set.Sessions {
+		if _, err := teammate.ImportSession(def); err != nil {
+			t.Fatalf("ImportSession(%s) failed: %v", def.Name, err)
+		}
+	}
+	imported, _ := teammate.ListSessions()
+	got := findByName(imported, "pushed")
+	if got == nil || got.Branch != "feature/pushed" {
+		t.Fatalf("imported sessions = %+v, expected pushed on feature/pushed", imported)
+	}
+	if _, err := os.Stat(filepath.Join(got.Path, "pushed.txt")); err != nil {
+		t.Errorf("pushed should start from origin's branch with its commit: %v", err)
+	}
+	if findByName(imported, "local-only") == nil {
+		t.Errorf("local-only should be created from its base, got %+v", imported)
+	}
+	if notes := teammate.Notes(*got); notes != "halfway there" {
+		t.Errorf("imported notes = %q", notes)
+	}
+	if tags := teammate.Tags(*got); !reflect.DeepEqual(tags, []string{"backend"}) {
+		t.Errorf("imported tags = %v", tags)
+	}
+
+	if _, err := teammate.ImportSession(set.Sessions[0]); err == nil {
+		t.Error("importing a session twice should fail")
+	}
+	if _, err := ParseSessionSet([]byte("version: 1\nsessions:\n  - name: x\n")); err == nil {
+		t.Error("ParseSessionSet() accepted a session without a branch")
+	}
+}
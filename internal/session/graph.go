@@ -0,0 +1,110 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// Graph is the topology of the session branches relative to a base branch
+type Graph struct {
+	Base     string
+	BaseHash string
+	Branches []GraphBranch
+	// Commits are on at least one session branch and not on the base,
+	// children before their parents
+	Commits []GraphCommit
+	// Omitted counts the commits left out by the limit
+	Omitted int
+}
+
+// GraphBranch is a session branch in a Graph
+type GraphBranch struct {
+	Session string
+	Branch  string
+	Tip     string
+	Ahead   int
+	Behind  int
+	// BuildsOn names the sessions whose commits are all on this branch too,
+	// as when it was stacked on them
+	BuildsOn []string
+}
+
+// GraphCommit is a commit in a Graph with, for each of its branches,
+// whether the branch contains it
+type GraphCommit struct {
+	git.Commit
+	In []bool
+}
+
+// Graph returns how the session branches relate to base: which of their
+// commits are on which branches, newest first, showing at most limit
+// commits if limit is positive. Detached sessions and sessions on base
+// itself are left out.
+func (m *Manager) Graph(base string, limit int) (*Graph, error) {
+	baseHash, err := git.ResolveRef(m.repoPath, base)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{Base: base, BaseHash: baseHash}
+	var tips []string
+	var contains []map[string]bool
+	for _, s := range sessions {
+		if s.Detached() || s.Branch == base {
+			continue
+		}
+		tip, err := git.ResolveRef(m.repoPath, s.Branch)
+		if err != nil {
+			return nil, err
+		}
+		own, err := git.ResolveCommits(m.repoPath, base+".."+s.Branch)
+		if err != nil {
+			return nil, err
+		}
+		behind, err := git.CountCommits(m.repoPath, s.Branch+".."+base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s to %s: %w", s.Branch, base, err)
+		}
+
+		set := make(map[string]bool, len(own))
+		for _, hash := range own {
+			set[hash] = true
+		}
+		g.Branches = append(g.Branches, GraphBranch{Session: s.Name, Branch: s.Branch, Tip: tip, Ahead: len(own), Behind: behind})
+		tips = append(tips, s.Branch)
+		contains = append(contains, set)
+	}
+	if len(tips) == 0 {
+		return g, nil
+	}
+
+	for i := range g.Branches {
+		for j, other := range g.Branches {
+			if i != j && other.Ahead > 0 && contains[i][other.Tip] && other.Tip != g.Branches[i].Tip {
+				g.Branches[i].BuildsOn = append(g.Branches[i].BuildsOn, other.Session)
+			}
+		}
+	}
+
+	commits, err := git.GetTopoCommits(m.repoPath, base, tips)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(commits) > limit {
+		g.Omitted = len(commits) - limit
+		commits = commits[:limit]
+	}
+	for _, c := range commits {
+		in := make([]bool, len(contains))
+		for i, set := range contains {
+			in[i] = set[c.Hash]
+		}
+		g.Commits = append(g.Commits, GraphCommit{Commit: c, In: in})
+	}
+	return g, nil
+}
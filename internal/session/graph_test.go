@@ -0,0 +1,70 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("parent"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	parent := findByName(sessions, "parent")
+	commitFile(t, parent.Path, "parent.txt", "parent\n")
+
+	child, err := manager.CreateStackedSession("child", *parent)
+	if err != nil {
+		t.Fatalf("CreateStackedSession() failed: %v", err)
+	}
+	commitFile(t, child.Path, "child.txt", "child\n")
+	commitFile(t, repo, "main.txt", "main\n")
+
+	g, err := manager.Graph("main", 0)
+	if err != nil {
+		t.Fatalf("Graph() failed: %v", err)
+	}
+	if len(g.Branches) != 2 || len(g.Commits) != 2 {
+		t.Fatalf("Graph() = %+v, expected 2 branches and 2 commits", g)
+	}
+
+	byName := map[string]int{}
+	for i, b := range g.Branches {
+		byName[b.Session] = i
+	}
+	p, c := g.Branches[byName["parent"]], g.Branches[byName["child"]]
+	if p.Ahead != 1 || p.Behind != 1 || c.Ahead != 2 || c.Behind != 1 {
+		t.Errorf("ahead/behind = parent %d/%d, child %d/%d; expected 1/1 and 2/1", p.Ahead, p.Behind, c.Ahead, c.Behind)
+	}
+	if !reflect.DeepEqual(c.BuildsOn, []string{"parent"}) || p.BuildsOn != nil {
+		t.Errorf("BuildsOn = parent %v, child %v; expected child to build on parent", p.BuildsOn, c.BuildsOn)
+	}
+
+	// The child's commit comes first and is only on the child
+	newest, oldest := g.Commits[0], g.Commits[1]
+	if newest.Subject != "change child.txt" || newest.In[byName["parent"]] || !newest.In[byName["child"]] {
+		t.Errorf("newest commit = %+v, expected the child's alone", newest)
+	}
+	if oldest.Subject != "change parent.txt" || !oldest.In[byName["parent"]] || !oldest.In[byName["child"]] {
+		t.Errorf("oldest commit = %+v, expected the parent's on both", oldest)
+	}
+
+	g, err = manager.Graph("main", 1)
+	if err != nil || len(g.Commits) != 1 || g.Omitted != 1 {
+		t.Errorf("Graph() with limit 1 = %+v, %v; expected 1 commit and 1 omitted", g, err)
+	}
+}
@@ -0,0 +1,113 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// HeartbeatQuietAfter is how long after its last heartbeat a session is shown
+// as quiet rather than working, a sign that its agent may be stuck
+const HeartbeatQuietAfter = 5 * time.Minute
+
+// Heartbeat is the activity an agent last published for its session
+type Heartbeat struct {
+	Session string    `json:"session"`
+	Path    string    `json:"path"`
+	Status  string    `json:"status"`
+	At      time.Time `json:"at"`
+}
+
+// Quiet reports whether the agent has not sent a heartbeat recently
+func (h Heartbeat) Quiet(now time.Time) bool {
+	return now.Sub(h.At) > HeartbeatQuietAfter
+}
+
+// heartbeatDir returns the directory holding one heartbeat file per session,
+// so agents beating in parallel never contend for a file
+func heartbeatDir(repoName string) string {
+	return filepath.Join(StateDir(repoName), "heartbeats")
+}
+
+// Heartbeat records status as the current activity of the named session
+func (m *Manager) Heartbeat(name, status string) error {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return err
+	}
+	var path string
+	for _, s := range sessions {
+		if s.Name == name {
+			path = s.Path
+			break
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("%w: %s", errors.ErrSessionNotFound, name)
+	}
+
+	data, err := json.Marshal(Heartbeat{Session: name, Path: path, Status: status, At: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	dir := heartbeatDir(m.repoName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(dir, utils.PathName(name)+".json")
+	tmpPath := fmt.Sprintf("%s.%d.tmp", file, os.Getpid())
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, file)
+}
+
+// ClearHeartbeat removes the heartbeat of the named session, e.g. when its
+// agent has finished
+func (m *Manager) ClearHeartbeat(name string) error {
+	err := os.Remove(filepath.Join(heartbeatDir(m.repoName), utils.PathName(name)+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Heartbeats returns the latest heartbeat of every session that has one,
+// keyed by worktree path
+func (m *Manager) Heartbeats() map[string]Heartbeat {
+	heartbeats := make(map[string]Heartbeat)
+
+	entries, err := os.ReadDir(heartbeatDir(m.repoName))
+	if err != nil {
+		return heartbeats
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(heartbeatDir(m.repoName), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var h Heartbeat
+		if err := json.Unmarshal(data, &h); err != nil {
+			continue
+		}
+		heartbeats[h.Path] = h
+	}
+	return heartbeats
+}
+
+// removeHeartbeat deletes the heartbeat of the session at path
+func (m *Manager) removeHeartbeat(path string) {
+	if h, ok := m.Heartbeats()[path]; ok {
+		_ = m.ClearHeartbeat(h.Session)
+	}
+}
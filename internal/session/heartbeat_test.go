@@ -0,0 +1,65 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func TestHeartbeat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("agent"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	agent := findByName(sessions, "agent")
+	if agent == nil {
+		t.Fatalf("agent session not found in %+v", sessions)
+	}
+
+	if err := manager.Heartbeat("missing", "running tests"); !errors.IsSessionNotFound(err) {
+		t.Errorf("Heartbeat(missing) error = %v, expected ErrSessionNotFound", err)
+	}
+
+	if err := manager.Heartbeat("agent", "running tests"); err != nil {
+		t.Fatalf("Heartbeat() failed: %v", err)
+	}
+	h, ok := manager.Heartbeats()[agent.Path]
+	if !ok || h.Status != "running tests" || h.Session != "agent" {
+		t.Fatalf("Heartbeats()[%s] = %+v, %v", agent.Path, h, ok)
+	}
+	if h.Quiet(time.Now()) || !h.Quiet(time.Now().Add(2*HeartbeatQuietAfter)) {
+		t.Errorf("Quiet() should only be set %s after the heartbeat", HeartbeatQuietAfter)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Name == "agent" && (s.Activity == nil || s.Activity.Status != "running tests") {
+			t.Errorf("Status() activity = %+v, expected running tests", s.Activity)
+		}
+	}
+
+	if err := manager.RemoveSession(agent.Path, false, ""); err != nil {
+		t.Fatalf("RemoveSession() failed: %v", err)
+	}
+	if len(manager.Heartbeats()) != 0 {
+		t.Errorf("RemoveSession() should remove the heartbeat, got %+v", manager.Heartbeats())
+	}
+}
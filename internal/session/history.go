@@ -0,0 +1,135 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/redact"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// command is the ccswitch command running, recorded with its operations
+var command string
+
+// SetCommand names the ccswitch command running, e.g. "fanout", for the
+// operations it records
+func SetCommand(name string) {
+	command = name
+}
+
+// OperationResult returns the history result of an operation that ended
+// with err
+func OperationResult(err error) string {
+	switch {
+	case err == nil:
+		return schema.ResultSucceeded
+	case errors.IsRebaseConflict(err), errors.IsCherryPickConflict(err):
+		return schema.ResultConflicted
+	case errors.IsInterrupted(err):
+		return schema.ResultInterrupted
+	default:
+		return schema.ResultFailed
+	}
+}
+
+// historyPath returns the file the operation history is appended to
+func historyPath(repoName string) string {
+	return filepath.Join(StateDir(repoName), "history.jsonl")
+}
+
+// currentUser returns the name of the user running ccswitch
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// RecordOperation appends op to the operation history, stamped with the
+// current time, command and user unless it has them. Its error is masked,
+// since it can carry git or verify output with credentials. Recording is
+// best effort: a history that can't be written never fails the operation
+// itself.
+func (m *Manager) RecordOperation(op schema.Operation) {
+	op.Error = redact.String(op.Error)
+	if op.At.IsZero() {
+		op.At = time.Now()
+	}
+	if op.Command == "" {
+		op.Command = command
+	}
+	if op.User == "" {
+		op.User = currentUser()
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return
+	}
+
+	path := historyPath(m.repoName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	// A single write of a line to a file opened for appending is not
+	// interleaved with those of other ccswitch processes
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+// Record records the outcome of op on branches, in the named session if
+// there is one
+func (m *Manager) Record(op, sessionName string, branches []string, err error) {
+	entry := schema.Operation{Op: op, Session: sessionName, Branches: branches, Result: OperationResult(err)}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	m.RecordOperation(entry)
+}
+
+// branchList returns the non-empty branches, leaving out those of detached
+// sessions
+func branchList(branches ...string) []string {
+	var list []string
+	for _, b := range branches {
+		if b != "" {
+			list = append(list, b)
+		}
+	}
+	return list
+}
+
+// Operations returns the recorded operations since the given time, oldest
+// first. Lines that can't be parsed, e.g. cut short by a crash, are skipped.
+func (m *Manager) Operations(since time.Time) ([]schema.Operation, error) {
+	f, err := os.Open(historyPath(m.repoName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ops []schema.Operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var op schema.Operation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			continue
+		}
+		if !op.At.Before(since) {
+			ops = append(ops, op)
+		}
+	}
+	return ops, scanner.Err()
+}
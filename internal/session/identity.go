@@ -0,0 +1,51 @@
+package session
+
+import (
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetIdentity makes sessions created from now on commit as the configured
+// identity name instead of the one identity or branch types choose
+func (m *Manager) SetIdentity(name string) error {
+	if name != "" {
+		if _, _, err := m.config.SessionIdentity(name, ""); err != nil {
+			return err
+		}
+	}
+	m.identity = name
+	return nil
+}
+
+// Identity returns the name of the configured identity s commits as, if it
+// was created with one, and the identity git uses in its worktree
+func (m *Manager) Identity(s git.SessionInfo) (string, config.Identity) {
+	var name string
+	if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil {
+		name = meta.Identity
+	}
+	var identity config.Identity
+	identity.Name, _ = git.GetConfig(s.Path, "user.name")
+	identity.Email, _ = git.GetConfig(s.Path, "user.email")
+	identity.SigningKey, _ = git.GetConfig(s.Path, "user.signingkey")
+	return name, identity
+}
+
+// applyIdentity sets identity in the git config of the worktree at path
+// only, leaving the repository's other worktrees as they are
+func applyIdentity(path string, identity *config.Identity) error {
+	settings := []struct{ key, value string }{
+		{"user.name", identity.Name},
+		{"user.email", identity.Email},
+		{"user.signingkey", identity.SigningKey},
+	}
+	for _, setting := range settings {
+		if setting.value == "" {
+			continue
+		}
+		if err := git.SetWorktreeConfig(path, setting.key, setting.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
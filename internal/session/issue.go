@@ -0,0 +1,41 @@
+package session
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetIssue ties sessions created from now on to their task: an issue ID
+// such as JIRA-123, whose address issues.url gives, and a link to the task,
+// which overrides that address. Either may be empty.
+func (m *Manager) SetIssue(issue, link string) error {
+	if link != "" {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid link %q: expected an http(s) URL", link)
+		}
+	}
+	m.issue, m.link = issue, link
+	return nil
+}
+
+// Issue returns the issue s works on and the address of its task, either
+// of which may be empty
+func (m *Manager) Issue(s git.SessionInfo) (string, string) {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return "", ""
+	}
+	return meta.Issue, m.issueURL(meta)
+}
+
+// issueURL returns the address of a session's task: its link, or else the
+// address of its issue
+func (m *Manager) issueURL(meta *Metadata) string {
+	if meta.Link != "" {
+		return meta.Link
+	}
+	return m.config.IssueURL(meta.Issue)
+}
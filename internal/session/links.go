@@ -0,0 +1,46 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// SharedLink is a path of worktree.links linked into a new worktree
+type SharedLink struct {
+	Path string
+	// Kind is how the path was linked: utils.LinkSymlink, LinkJunction or
+	// LinkCopy
+	Kind string
+	Err  error
+}
+
+// LinkShared links the paths of worktree.links, such as node_modules or
+// .env, from the main worktree into the worktree at path. Paths missing
+// from the main worktree, or already in the new one, are skipped.
+func (m *Manager) LinkShared(path string) []SharedLink {
+	if len(m.config.Worktree.Links) == 0 {
+		return nil
+	}
+	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
+	if err != nil {
+		mainRepoPath = m.repoPath
+	}
+
+	var links []SharedLink
+	for _, rel := range m.config.Worktree.Links {
+		target := filepath.Join(mainRepoPath, filepath.FromSlash(rel))
+		link := filepath.Join(path, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		kind, err := utils.Link(target, link)
+		links = append(links, SharedLink{Path: rel, Kind: kind, Err: err})
+	}
+	return links
+}
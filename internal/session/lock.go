@@ -0,0 +1,175 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/proc"
+)
+
+// LockStaleAfter is the age after which a lock is considered abandoned even
+// if its process cannot be checked, e.g. because it was taken on another host
+const LockStaleAfter = 12 * time.Hour
+
+// lockPollInterval is how often WaitLock retries a held lock
+const lockPollInterval = 250 * time.Millisecond
+
+// LockInfo describes the command holding a repository lock
+type LockInfo struct {
+	PID        int       `json:"pid"`
+	Host       string    `json:"host"`
+	Command    string    `json:"command"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// stale reports whether the holder of the lock has gone away
+func (l LockInfo) stale() bool {
+	if time.Since(l.AcquiredAt) > LockStaleAfter {
+		return true
+	}
+	host, _ := os.Hostname()
+	return l.Host == host && !proc.Alive(l.PID)
+}
+
+// same reports whether l and other describe the same acquisition
+func (l LockInfo) same(other LockInfo) bool {
+	return l.PID == other.PID && l.Host == other.Host && l.AcquiredAt.Equal(other.AcquiredAt)
+}
+
+// Lock is a held repository lock. Commands that change worktrees or rewrite
+// branches hold it so that they cannot interleave with each other.
+type Lock struct {
+	path string
+	info LockInfo
+}
+
+// LockPath returns the path of the lock file for a repository
+func LockPath(repoName string) string {
+	return filepath.Join(StateDir(repoName), "lock")
+}
+
+// Lock takes the repository lock for command without waiting. If another
+// live command holds it, the error wraps errors.ErrLocked.
+func (m *Manager) Lock(command string) (*Lock, error) {
+	return AcquireLock(LockPath(m.repoName), command)
+}
+
+// WaitLock takes the repository lock for command, waiting for the current
+// holder to release it
+func (m *Manager) WaitLock(command string) (*Lock, error) {
+	for {
+		lock, err := m.Lock(command)
+		if !errors.IsLocked(err) {
+			return lock, err
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// AcquireLock creates the lock file at path, replacing it if its holder has
+// gone away
+func AcquireLock(path, command string) (*Lock, error) {
+	host, _ := os.Hostname()
+	info := LockInfo{
+		PID:        os.Getpid(),
+		Host:       host,
+		Command:    command,
+		AcquiredAt: time.Now(),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	for {
+		err := createLockFile(path, info)
+		if err == nil {
+			return &Lock{path: path, info: info}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		holder, err := readLockFile(path)
+		if os.IsNotExist(err) {
+			// Released while we looked: try again
+			continue
+		}
+		if err == nil && !holder.stale() {
+			return nil, fmt.Errorf("%w: %s (pid %d) since %s",
+				errors.ErrLocked, holder.Command, holder.PID, holder.AcquiredAt.Local().Format("15:04:05"))
+		}
+
+		// The holder is gone or the file is unreadable: break the lock
+		if err := breakLock(path, holder); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Release removes the lock file if it is still held by this lock
+func (l *Lock) Release() error {
+	holder, err := readLockFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !holder.same(l.info) {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// createLockFile writes info to a temporary file and links it into place, so
+// the lock appears atomically with its contents and fails if it exists
+func createLockFile(path string, info LockInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := fmt.Sprintf("%s.%d.tmp", path, info.PID)
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	return os.Link(tmpPath, path)
+}
+
+// readLockFile reads the holder of the lock file at path
+func readLockFile(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("invalid lock file %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// breakLock removes a stale lock file. If another command replaced the lock
+// after stale was read, its lock is put back.
+func breakLock(path string, stale LockInfo) error {
+	movedPath := fmt.Sprintf("%s.%d.stale", path, os.Getpid())
+	if err := os.Rename(path, movedPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove stale lock: %w", err)
+	}
+	defer os.Remove(movedPath)
+
+	if moved, err := readLockFile(movedPath); err == nil && !moved.same(stale) {
+		_ = os.Link(movedPath, path)
+	}
+	return nil
+}
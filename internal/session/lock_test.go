@@ -0,0 +1,108 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func writeLockInfo(t *testing.T, path string, info LockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "lock")
+
+	lock, err := AcquireLock(path, "ccswitch fanout")
+	if err != nil {
+		t.Fatalf("AcquireLock() failed: %v", err)
+	}
+
+	if _, err := AcquireLock(path, "ccswitch rebase"); !errors.IsLocked(err) {
+		t.Errorf("second AcquireLock() error = %v, expected ErrLocked", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file should be removed after Release(), stat error = %v", err)
+	}
+
+	lock, err = AcquireLock(path, "ccswitch rebase")
+	if err != nil {
+		t.Fatalf("AcquireLock() after release failed: %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLockBreaksStaleLocks(t *testing.T) {
+	host, _ := os.Hostname()
+
+	tests := []struct {
+		name   string
+		holder LockInfo
+	}{
+		{
+			name:   "dead process",
+			holder: LockInfo{PID: 999999999, Host: host, Command: "ccswitch fanout", AcquiredAt: time.Now()},
+		},
+		{
+			name:   "expired on another host",
+			holder: LockInfo{PID: 1, Host: "elsewhere", Command: "ccswitch fanout", AcquiredAt: time.Now().Add(-2 * LockStaleAfter)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lock")
+			writeLockInfo(t, path, tt.holder)
+
+			lock, err := AcquireLock(path, "ccswitch rebase")
+			if err != nil {
+				t.Fatalf("AcquireLock() should break stale lock, got: %v", err)
+			}
+			lock.Release()
+		})
+	}
+}
+
+func TestAcquireLockKeepsLiveLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+	writeLockInfo(t, path, LockInfo{PID: 1, Host: "elsewhere", Command: "ccswitch fanout", AcquiredAt: time.Now()})
+
+	if _, err := AcquireLock(path, "ccswitch rebase"); !errors.IsLocked(err) {
+		t.Errorf("AcquireLock() error = %v, expected ErrLocked", err)
+	}
+}
+
+func TestReleaseKeepsOtherHoldersLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	lock, err := AcquireLock(path, "ccswitch fanout")
+	if err != nil {
+		t.Fatalf("AcquireLock() failed: %v", err)
+	}
+
+	// Another command broke the lock and took it over
+	other := LockInfo{PID: 1, Host: "elsewhere", Command: "ccswitch rebase", AcquiredAt: time.Now()}
+	writeLockInfo(t, path, other)
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Release() should leave another holder's lock in place: %v", err)
+	}
+}
@@ -2,13 +2,18 @@ package session
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ksred/ccswitch/internal/config"
 	"github.com/ksred/ccswitch/internal/errors"
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+	"github.com/ksred/ccswitch/internal/schema"
 	"github.com/ksred/ccswitch/internal/utils"
 )
 
@@ -17,8 +22,23 @@ type Manager struct {
 	worktreeManager *git.WorktreeManager
 	branchManager   *git.BranchManager
 	config          *config.Config
+	metadata        *MetadataStore
 	repoPath        string
 	repoName        string
+	// branchType fills {type} in the branches of new sessions
+	branchType string
+	// base is where new sessions start, instead of the current branch
+	base string
+	// issue and link tie new sessions to their task
+	issue, link string
+	// tags label new sessions
+	tags []string
+	// ttl is how long new sessions live before they expire; zero for ever
+	ttl time.Duration
+	// identity names the git identity new sessions commit as
+	identity string
+	// progress receives git's output as rebases run
+	progress io.Writer
 }
 
 // NewManager creates a new session manager
@@ -31,12 +51,13 @@ func NewManager(repoPath string) *Manager {
 	}
 
 	repoName := filepath.Base(mainRepoPath)
-	cfg, _ := config.Load()
+	cfg, _ := config.LoadForRepo(mainRepoPath)
 
 	return &Manager{
 		worktreeManager: git.NewWorktreeManager(mainRepoPath),
 		branchManager:   git.NewBranchManager(repoPath), // Keep current path for branch operations
 		config:          cfg,
+		metadata:        NewMetadataStore(repoName),
 		repoPath:        repoPath,
 		repoName:        repoName,
 	}
@@ -44,59 +65,102 @@ func NewManager(repoPath string) *Manager {
 
 // CreateSession creates a new work session
 func (m *Manager) CreateSession(description string) error {
-	branchName := m.config.Branch.Prefix + utils.Slugify(description)
+	_, err := m.createSession(description, "", nil)
+	return err
+}
+
+// CreateSparseSession creates a new work session whose worktree checks out
+// only the paths of the sparse-checkout profile in the configuration
+func (m *Manager) CreateSparseSession(description, profile string) error {
+	paths, err := m.config.SparseProfile(profile)
+	if err != nil {
+		return err
+	}
+	_, err = m.createSession(description, "", paths)
+	return err
+}
+
+// NewSession creates a new work session and returns its metadata
+func (m *Manager) NewSession(description string) (*Metadata, error) {
+	return m.createSession(description, "", nil)
+}
+
+// createSession creates a session whose branch starts at startPoint, or at
+// the base set with SetBase or else the current branch if startPoint is
+// empty, and returns its metadata. With
+// sparse paths, only those are checked out.
+func (m *Manager) createSession(description, startPoint string, sparse []string) (*Metadata, error) {
+	if startPoint == "" {
+		startPoint = m.base
+	}
+	branchName, err := m.BranchName(description)
+	if err != nil {
+		return nil, err
+	}
+	identityName, identity, err := m.config.SessionIdentity(m.identity, m.branchType)
+	if err != nil {
+		return nil, err
+	}
 	sessionName := utils.Slugify(description)
 
 	// Check if we're already on the branch we want to create
 	currentBranch, err := m.branchManager.GetCurrent()
 	if err == nil && currentBranch == branchName {
-		return fmt.Errorf("%w: %s", errors.ErrAlreadyOnBranch, branchName)
+		return nil, fmt.Errorf("%w: %s", errors.ErrAlreadyOnBranch, branchName)
 	}
 
 	// Check if branch already exists
 	if m.branchManager.Exists(branchName) {
-		return fmt.Errorf("%w: %s", errors.ErrBranchExists, branchName)
+		return nil, fmt.Errorf("%w: %s", errors.ErrBranchExists, branchName)
 	}
 
 	// Get worktree path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return errors.Wrap(err, "failed to get home directory")
-	}
-
-	// Get repo name from the main repo path
-	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
-	if err != nil {
-		mainRepoPath = m.repoPath
-	}
-	repoName := filepath.Base(mainRepoPath)
-
-	worktreeBasePath := filepath.Join(homeDir, ".ccswitch", "worktrees", repoName)
-	worktreePath := filepath.Join(worktreeBasePath, sessionName)
+	worktreePath := m.GetSessionPath(sessionName)
 
 	// Check if worktree directory already exists
 	if _, err := os.Stat(worktreePath); err == nil {
-		return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
 	}
 
 	// Ensure the worktree base directory exists
-	if err := os.MkdirAll(worktreeBasePath, 0755); err != nil {
-		return errors.Wrap(err, "failed to create worktree directory")
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create worktree directory")
 	}
 
 	// Create branch
-	if err := m.branchManager.Create(branchName); err != nil {
-		return err
+	createBranch := m.branchManager.Create
+	if startPoint != "" {
+		createBranch = func(name string) error { return m.branchManager.CreateFrom(name, startPoint) }
+	}
+	if err := createBranch(branchName); err != nil {
+		return nil, err
 	}
 
 	// Create worktree
-	if err := m.worktreeManager.Create(worktreePath, branchName); err != nil {
+	if err := m.addWorktree(worktreePath, branchName, sparse); err != nil {
 		// Try to clean up the branch we just created
 		_ = m.branchManager.Delete(branchName, false)
-		return err
+		return nil, err
 	}
 
-	return nil
+	if identity != nil {
+		if err := applyIdentity(worktreePath, identity); err != nil {
+			_ = m.worktreeManager.Remove(worktreePath)
+			_ = m.branchManager.Delete(branchName, false)
+			return nil, err
+		}
+	}
+
+	baseBranch := startPoint
+	if baseBranch == "" {
+		baseBranch = currentBranch
+	}
+	entry := m.recordSession(sessionName, branchName, baseBranch, worktreePath)
+	if identityName != "" {
+		entry.Identity = identityName
+		_ = m.metadata.Put(entry)
+	}
+	return entry, nil
 }
 
 // CheckoutSession creates a worktree for an existing branch
@@ -115,20 +179,7 @@ func (m *Manager) CheckoutSession(branchName string) error {
 	}
 
 	// Get worktree path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return errors.Wrap(err, "failed to get home directory")
-	}
-
-	// Get repo name from the main repo path
-	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
-	if err != nil {
-		mainRepoPath = m.repoPath
-	}
-	repoName := filepath.Base(mainRepoPath)
-
-	worktreeBasePath := filepath.Join(homeDir, ".ccswitch", "worktrees", repoName)
-	worktreePath := filepath.Join(worktreeBasePath, sessionName)
+	worktreePath := m.GetSessionPath(sessionName)
 
 	// Check if worktree directory already exists
 	if _, err := os.Stat(worktreePath); err == nil {
@@ -136,34 +187,191 @@ func (m *Manager) CheckoutSession(branchName string) error {
 	}
 
 	// Ensure the worktree base directory exists
-	if err := os.MkdirAll(worktreeBasePath, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
 		return errors.Wrap(err, "failed to create worktree directory")
 	}
 
 	// Create worktree for existing branch
-	if err := m.worktreeManager.Create(worktreePath, branchName); err != nil {
+	if err := m.addWorktree(worktreePath, branchName, nil); err != nil {
 		return err
 	}
 
+	m.recordSession(sessionName, branchName, "", worktreePath)
 	return nil
 }
 
+// addWorktree checks out branch at path following the configured creation
+// strategy, or only the sparse paths if given
+func (m *Manager) addWorktree(path, branch string, sparse []string) error {
+	strategy, err := m.config.CreationStrategy()
+	if err != nil {
+		return err
+	}
+	if len(sparse) > 0 || strategy == config.StrategySparse {
+		return m.worktreeManager.CreateSparse(path, branch, sparse)
+	}
+	return m.worktreeManager.Create(path, branch)
+}
+
+// recordSession stores metadata for a newly created session
+func (m *Manager) recordSession(name, branch, baseBranch, path string) *Metadata {
+	entry := &Metadata{
+		Name:       name,
+		Branch:     branch,
+		Path:       path,
+		CreatedAt:  time.Now(),
+		BaseBranch: baseBranch,
+		Issue:      m.issue,
+		Link:       m.link,
+		Tags:       m.tags,
+	}
+	if m.ttl > 0 {
+		expires := entry.CreatedAt.Add(m.ttl)
+		entry.ExpiresAt = &expires
+	}
+	// Ports and the env file are best effort: 'ccswitch env' allocates
+	// and writes them again
+	_ = m.metadata.modify(func(file *metadataFile) error {
+		if m.config.Env.PortBase > 0 {
+			entry.Port, _ = m.freePort(file)
+		}
+		file.Sessions[name] = entry
+		return nil
+	})
+	if m.config.Env.File {
+		_, _ = m.WriteEnvFile(git.SessionInfo{Name: name, Branch: branch, Path: path})
+	}
+	m.Record(schema.OpCreate, name, branchList(branch), nil)
+	m.refreshWorkspace()
+	return entry
+}
+
 // ListSessions returns all active sessions
 func (m *Manager) ListSessions() ([]git.SessionInfo, error) {
 	worktrees, err := m.worktreeManager.List()
 	if err != nil {
 		return nil, err
 	}
-	return git.GetSessionsFromWorktrees(worktrees, m.repoName), nil
+	sessions := git.GetSessionsFromWorktrees(worktrees, m.repoName)
+	m.restoreShortenedNames(sessions)
+	return m.appendRelocatedSessions(sessions, worktrees), nil
+}
+
+// restoreShortenedNames gives sessions whose names were too long to be kept
+// whole in their worktree's path their names from the metadata store
+func (m *Manager) restoreShortenedNames(sessions []git.SessionInfo) {
+	for i, s := range sessions {
+		if !utils.IsShortenedPathName(filepath.Base(s.Path)) {
+			continue
+		}
+		if entry, err := m.metadata.FindByPath(s.Path); err == nil && entry != nil {
+			sessions[i].Name = entry.Name
+		}
+	}
+}
+
+// appendRelocatedSessions adds sessions whose worktrees were moved outside the
+// default worktree directory, using the metadata store to recognise them
+func (m *Manager) appendRelocatedSessions(sessions []git.SessionInfo, worktrees []git.Worktree) []git.SessionInfo {
+	entries, err := m.metadata.All()
+	if err != nil || len(entries) == 0 {
+		return sessions
+	}
+
+	known := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		known[s.Path] = true
+	}
+
+	for _, entry := range entries {
+		if known[entry.Path] {
+			continue
+		}
+		for _, wt := range worktrees {
+			if wt.Path == entry.Path && wt.HasHead() {
+				sessions = append(sessions, git.SessionInfo{
+					Name:   entry.Name,
+					Branch: wt.Branch,
+					Path:   wt.Path,
+				})
+				known[wt.Path] = true
+				break
+			}
+		}
+	}
+
+	return sessions
+}
+
+// Config returns the configuration for the repository
+func (m *Manager) Config() *config.Config {
+	return m.config
+}
+
+// RepoName returns the name of the repository
+func (m *Manager) RepoName() string {
+	return m.repoName
+}
+
+// Metadata returns the metadata store for the repository
+func (m *Manager) Metadata() *MetadataStore {
+	return m.metadata
+}
+
+// commitManager returns a CommitManager for dir that signs commits if
+// git.sign_commits is set and skips hooks if git.commit_hooks says so
+func (m *Manager) commitManager(dir string) *git.CommitManager {
+	cm := git.NewCommitManager(dir)
+	cm.Sign = m.config.Git.SignCommits
+	cm.NoVerify = m.config.Git.CommitHooks == config.CommitHooksSkip
+	cm.CoAuthors = m.config.Git.CoAuthors
+	return cm
+}
+
+// SetProgress streams git's output to w as rebases and cherry-picks run,
+// until it is set back to nil
+func (m *Manager) SetProgress(w io.Writer) {
+	m.progress = w
+}
+
+// rebaseManager returns a RebaseManager for dir that signs the commits it
+// rewrites if git.sign_commits is set
+func (m *Manager) rebaseManager(dir string) *git.RebaseManager {
+	rm := git.NewRebaseManager(dir)
+	rm.Sign = m.config.Git.SignCommits
+	rm.Progress = m.progress
+	return rm
 }
 
 // RemoveSession removes a session and optionally its branch
 func (m *Manager) RemoveSession(sessionPath string, deleteBranch bool, branchName string) error {
+	name := utils.NameFromPath(filepath.Base(sessionPath))
+	if entry, err := m.metadata.FindByPath(sessionPath); err == nil && entry != nil {
+		name = entry.Name
+	}
+	err := m.removeSession(sessionPath, deleteBranch, branchName)
+	m.Record(schema.OpDelete, name, branchList(branchName), err)
+	m.refreshWorkspace()
+	return err
+}
+
+// removeSession removes a session's worktree and metadata, and its branch
+// if deleteBranch is set
+func (m *Manager) removeSession(sessionPath string, deleteBranch bool, branchName string) error {
+	// Stop the session's dev container while its compose file is still
+	// there; a container that can't be stopped doesn't keep the session
+	_, _ = m.ContainerDown(git.SessionInfo{Name: utils.NameFromPath(filepath.Base(sessionPath)), Branch: branchName, Path: sessionPath}, nil)
+
 	// Remove worktree
 	if err := m.worktreeManager.Remove(sessionPath); err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
+	if entry, err := m.metadata.FindByPath(sessionPath); err == nil && entry != nil {
+		_ = m.metadata.Delete(entry.Name)
+	}
+	m.removeHeartbeat(sessionPath)
+
 	// Delete branch if requested
 	if deleteBranch && branchName != "" {
 		if err := m.branchManager.Delete(branchName, false); err != nil {
@@ -178,49 +386,182 @@ func (m *Manager) RemoveSession(sessionPath string, deleteBranch bool, branchNam
 	return nil
 }
 
-// GetSessionPath returns the path for a session
+// GetSessionPath returns the path for a session, following the configured
+// worktree root
 func (m *Manager) GetSessionPath(sessionName string) string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".ccswitch", "worktrees", m.repoName, sessionName)
+	mainRepoPath, err := git.GetMainRepoPath(m.repoPath)
+	if err != nil {
+		mainRepoPath = m.repoPath
+	}
+	return m.config.WorktreePath(mainRepoPath, utils.PathName(sessionName))
 }
 
 // CommitAndRebaseSession commits changes in a session and rebases to current branch
 func (m *Manager) CommitAndRebaseSession(sessionPath, commitMessage string) error {
-	// 1. Check for changes in the session
-	commitManager := git.NewCommitManager(sessionPath)
+	// 1-3. Stage and commit all changes in the session
+	if err := m.CommitSession(sessionPath, commitMessage); err != nil {
+		return err
+	}
+
+	// 4. Get the commit hash
+	commitManager := m.commitManager(sessionPath)
+	commitHash, err := commitManager.GetLastCommitHash()
+	if err != nil {
+		return fmt.Errorf("failed to get commit hash: %w", err)
+	}
+
+	// 5. Rebase to current branch (from main repo path)
+	return m.rebaseCurrent(commitHash)
+}
+
+// SquashAndRebaseSession squashes the commits a worktree made since it
+// forked from branch, the current branch of the main repo, and its
+// uncommitted changes into a single commit with commitMessage, then rebases
+// it as CommitAndRebaseSession does. The tip of the worktree's branch
+// before the squash is kept as its backup (see git.BackupRef). With
+// git.keep_author, the commit is authored by the author of the first
+// squashed commit, and the authors of the others are credited as
+// co-authors. If the commit fails, the worktree's commits are put back,
+// with the changes staged.
+func (m *Manager) SquashAndRebaseSession(sessionPath, branch, commitMessage string) error {
+	base, err := git.MergeBase(sessionPath, branch, "HEAD")
+	if err != nil {
+		return err
+	}
+	head, err := git.ResolveRef(sessionPath, "HEAD")
+	if err != nil {
+		return err
+	}
+	commitManager := m.commitManager(sessionPath)
+	if head == base && !commitManager.HasChanges() {
+		return fmt.Errorf("no commits or changes to squash in session")
+	}
+
+	worktreeBranch, err := git.GetCurrentBranch(sessionPath)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree branch: %w", err)
+	}
+	if worktreeBranch != "" {
+		if err := git.SaveBackup(sessionPath, worktreeBranch); err != nil {
+			return err
+		}
+	}
+
+	if m.config.Git.KeepAuthor && head != base {
+		authors, err := git.CommitAuthors(sessionPath, base+"..HEAD")
+		if err != nil {
+			return err
+		}
+		if len(authors) > 0 {
+			commitManager.Author = authors[0]
+			commitManager.CoAuthors = append(slices.Clip(commitManager.CoAuthors), authors[1:]...)
+		}
+	}
+
+	if err := commitManager.ResetSoft(base); err != nil {
+		return err
+	}
+	if err := commitManager.StageAll(); err != nil {
+		_ = commitManager.ResetSoft(head)
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+	if err := commitManager.Commit(commitMessage); err != nil {
+		_ = commitManager.ResetSoft(head)
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	commitHash, err := commitManager.GetLastCommitHash()
+	if err != nil {
+		return fmt.Errorf("failed to get commit hash: %w", err)
+	}
+	return m.rebaseCurrent(commitHash)
+}
+
+// CommitSession stages and commits all changes in a session
+func (m *Manager) CommitSession(sessionPath, commitMessage string) error {
+	commitManager := m.commitManager(sessionPath)
 	if !commitManager.HasChanges() {
 		return fmt.Errorf("no changes to commit in session")
 	}
 
-	// 2. Stage all changes
 	if err := commitManager.StageAll(); err != nil {
 		return fmt.Errorf("failed to stage changes: %w", err)
 	}
 
-	// 3. Commit changes
 	if err := commitManager.Commit(commitMessage); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
+	return nil
+}
 
-	// 4. Get the commit hash
-	commitHash, err := commitManager.GetLastCommitHash()
+// InteractiveRebaseSession runs git rebase -i in a worktree onto branch, the
+// current branch of the main repo, so the user picks, drops, reorders and
+// squashes the worktree's commits. The current branch is then fast-forwarded
+// to the result. If the rebase stops before finishing, the error wraps
+// errors.ErrRebaseStopped and the current branch is left unchanged.
+func (m *Manager) InteractiveRebaseSession(worktreePath, branch string) error {
+	worktreeBranch, err := git.GetCurrentBranch(worktreePath)
 	if err != nil {
-		return fmt.Errorf("failed to get commit hash: %w", err)
+		return fmt.Errorf("failed to get worktree branch: %w", err)
 	}
 
-	// 5. Rebase to current branch (from main repo path)
-	rebaseManager := git.NewRebaseManager(m.repoPath)
-	success, hasConflict, err := rebaseManager.RebaseCommit(commitHash)
+	rebaseManager := m.rebaseManager(worktreePath)
+	if err := rebaseManager.RebaseInteractive(branch); err != nil {
+		return err
+	}
+	if rebaseManager.InProgress() {
+		return fmt.Errorf("%w in %s", errors.ErrRebaseStopped, worktreePath)
+	}
+
+	return m.rebaseManager(m.repoPath).FastForward(worktreeBranch)
+}
+
+// CherryPickSession applies the commits selected by rev, resolved in the
+// worktree so that e.g. "HEAD~2.." means its last two commits, onto the
+// current branch of the main repo. It returns the number of commits applied.
+func (m *Manager) CherryPickSession(worktreePath, rev string, noCommit bool) (int, error) {
+	commits, err := git.ResolveCommits(worktreePath, rev)
+	if err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, fmt.Errorf("no commits in %s", rev)
+	}
+
+	if err := m.PickCommits(commits, noCommit); err != nil {
+		return 0, err
+	}
+	return len(commits), nil
+}
+
+// PickCommits cherry-picks commits, oldest first, onto the current branch of
+// the main repo, auto-aborting on conflict. With noCommit the changes are
+// staged for the user to commit, which requires a clean worktree.
+func (m *Manager) PickCommits(commits []string, noCommit bool) error {
+	err := m.pickCommits(commits, noCommit)
+	branch, _ := m.branchManager.GetCurrent()
+	m.Record(schema.OpPick, "", branchList(branch), err)
+	return err
+}
+
+// pickCommits does the work of PickCommits
+func (m *Manager) pickCommits(commits []string, noCommit bool) error {
+	if noCommit && git.HasUncommittedChanges(m.repoPath) {
+		return fmt.Errorf("%w in %s", errors.ErrUncommittedChanges, m.repoPath)
+	}
+
+	rebaseManager := m.rebaseManager(m.repoPath)
+	success, hasConflict, err := rebaseManager.CherryPick(commits, noCommit)
 
 	if err != nil {
 		if hasConflict {
-			return fmt.Errorf("rebase aborted due to conflicts: %w", err)
+			return fmt.Errorf("cherry-pick aborted due to conflicts: %w", err)
 		}
 		return err
 	}
 
 	if !success {
-		return fmt.Errorf("rebase failed")
+		return fmt.Errorf("cherry-pick failed")
 	}
 
 	return nil
@@ -233,10 +574,39 @@ func (m *Manager) RebaseSession(worktreePath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get worktree branch: %w", err)
 	}
+	// A detached worktree's commits are rebased by their hash
+	if worktreeBranch == "" {
+		if worktreeBranch, err = git.ResolveRef(worktreePath, "HEAD"); err != nil {
+			return err
+		}
+	}
 
 	// Rebase the worktree branch onto current branch using rebase manager
-	rebaseManager := git.NewRebaseManager(m.repoPath)
-	success, hasConflict, err := rebaseManager.RebaseCommit(worktreeBranch)
+	return m.rebaseCurrent(worktreeBranch)
+}
+
+// verifyOutputLines is how much of the verify command's output a failure
+// reports
+const verifyOutputLines = 20
+
+// rebaseCurrent rebases the current branch onto upstream. With
+// git.verify_command set, the command then checks the result, and the
+// branch is reset to its backup ref if it fails.
+func (m *Manager) rebaseCurrent(upstream string) error {
+	verify := m.config.Git.VerifyCommand
+	var branch string
+	if verify != "" {
+		var err error
+		if branch, err = m.branchManager.GetCurrent(); err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		if err := git.SaveBackup(m.repoPath, branch); err != nil {
+			return err
+		}
+	}
+
+	rebaseManager := m.rebaseManager(m.repoPath)
+	success, hasConflict, err := rebaseManager.RebaseCommit(upstream)
 
 	if err != nil {
 		if hasConflict {
@@ -249,7 +619,21 @@ func (m *Manager) RebaseSession(worktreePath string) error {
 		return fmt.Errorf("rebase failed")
 	}
 
-	return nil
+	if verify == "" {
+		return nil
+	}
+	output, err := proc.RunShell(m.repoPath, verify)
+	if err == nil {
+		return nil
+	}
+	if restoreErr := git.RestoreBackup(m.repoPath, branch); restoreErr != nil {
+		return fmt.Errorf("%w (%v); %w", errors.ErrVerifyFailed, err, restoreErr)
+	}
+	err = fmt.Errorf("%w (%v), rebase rolled back", errors.ErrVerifyFailed, err)
+	if tail := utils.LastLines(string(output), verifyOutputLines); tail != "" {
+		err = fmt.Errorf("%w:\n%s", err, tail)
+	}
+	return err
 }
 
 // GetCurrentBranch returns the current branch of the main repo
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/proc"
+)
+
+// GenerateCommitMessage runs git.message_command in the worktree at path
+// with the diff of its changes since base, uncommitted ones and untracked
+// files included, on stdin, and returns what the command prints as the
+// commit message. Pass HEAD as base to describe only uncommitted changes.
+// The worktree and its index are left as they are.
+func (m *Manager) GenerateCommitMessage(worktreePath, base string) (string, error) {
+	command := m.config.Git.MessageCommand
+	if command == "" {
+		return "", errors.ErrNoMessageCommand
+	}
+
+	diff, err := git.UncommittedDiff(worktreePath, base)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("no changes to describe in %s", worktreePath)
+	}
+
+	cmd := proc.ShellCommand(command)
+	cmd.Dir = worktreePath
+	cmd.Env = os.Environ()
+	cmd.Stdin = strings.NewReader(diff)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := proc.Run(cmd, proc.DefaultGracePeriod); err != nil {
+		return "", fmt.Errorf("git.message_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	message := strings.TrimSpace(stdout.String())
+	if message == "" {
+		return "", fmt.Errorf("git.message_command printed no commit message")
+	}
+	return message, nil
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+func TestGenerateCommitMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if _, err := manager.GenerateCommitMessage(repo, "HEAD"); !errors.IsNoMessageCommand(err) {
+		t.Errorf("GenerateCommitMessage() without a command returned %v, expected ErrNoMessageCommand", err)
+	}
+
+	// The command sees the diff, untracked files included
+	manager.Config().Git.MessageCommand = `grep -q '^+added' && printf '\n  Add new.txt\n\n'`
+	if _, err := manager.GenerateCommitMessage(repo, "HEAD"); err == nil {
+		t.Error("GenerateCommitMessage() with no changes should fail")
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("added\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	message, err := manager.GenerateCommitMessage(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() failed: %v", err)
+	}
+	if message != "Add new.txt" {
+		t.Errorf("GenerateCommitMessage() = %q, expected %q", message, "Add new.txt")
+	}
+	status, _ := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if strings.TrimSpace(string(status)) != "?? new.txt" {
+		t.Errorf("status after GenerateCommitMessage() = %q, expected new.txt left untracked", status)
+	}
+
+	manager.Config().Git.MessageCommand = "echo no model >&2; exit 1"
+	if _, err := manager.GenerateCommitMessage(repo, "HEAD"); err == nil {
+		t.Error("GenerateCommitMessage() should fail when the command fails")
+	}
+}
@@ -0,0 +1,288 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/container"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// Metadata holds persisted information about a session
+type Metadata struct {
+	Name      string    `json:"name"`
+	Branch    string    `json:"branch"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	// BaseBranch is the branch the session was created from
+	BaseBranch string `json:"base_branch,omitempty"`
+	// Parent is the branch a stacked session was created from
+	Parent string `json:"parent,omitempty"`
+	// ParentBase is the parent commit the branch is currently based on,
+	// used to replay only the session's own commits when restacking
+	ParentBase string `json:"parent_base,omitempty"`
+	// Issue is the ticket the session works on, e.g. JIRA-123
+	Issue string `json:"issue,omitempty"`
+	// Link is the address of the session's task, overriding the one
+	// issues.url gives for Issue
+	Link string `json:"link,omitempty"`
+	// Ref is the tag or commit a detached session was created at
+	Ref string `json:"ref,omitempty"`
+	// Identity names the configured git identity the session commits as
+	Identity string `json:"identity,omitempty"`
+	// Tags label the session for filtering, e.g. with list --tag
+	Tags []string `json:"tags,omitempty"`
+	// ExpiresAt is when a session created with a TTL expires, after which
+	// cleanup --expired removes it
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Pinned sessions are protected: cleanup and prune-artifacts skip them
+	Pinned bool `json:"pinned,omitempty"`
+	// Notes is free-form text kept with the session, e.g. what its agent
+	// is working on or what blocks it
+	Notes string `json:"notes,omitempty"`
+	// Port is the first port of the block allocated to the session
+	Port int `json:"port,omitempty"`
+	// Env holds variables set for the session's commands with
+	// 'ccswitch env --set', overriding env.vars
+	Env map[string]string `json:"env,omitempty"`
+	// Container is the dev container started for the session with
+	// ccswitch container up
+	Container *container.Container `json:"container,omitempty"`
+	// Agent is the agent process launched in the session by ccswitch
+	// agents spawn
+	Agent *AgentInfo `json:"agent,omitempty"`
+	// LastUsed is when the session was last switched to or had a command
+	// run in it
+	LastUsed *time.Time `json:"last_used,omitempty"`
+}
+
+// AgentInfo describes an agent process running in a session
+type AgentInfo struct {
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	Task      string    `json:"task"`
+	StartedAt time.Time `json:"started_at"`
+	// Log is the file the agent's output goes to when it runs in the
+	// background
+	Log string `json:"log,omitempty"`
+	// TmuxPane is the pane the agent runs in when started in tmux
+	TmuxPane string `json:"tmux_pane,omitempty"`
+	// StoppedAt is set when ccswitch agents stop ended the agent
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+}
+
+// MetadataStore persists session metadata for a repository. Writers hold a
+// lock on a file next to it from loading the metadata until it is saved, so
+// concurrent commands never lose each other's changes, and readers see
+// either the previous or the new file since it is replaced atomically.
+type MetadataStore struct {
+	path string
+}
+
+type metadataFile struct {
+	Sessions map[string]*Metadata `json:"sessions"`
+}
+
+// StateDir returns the directory holding ccswitch state for a repository
+func StateDir(repoName string) string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ccswitch", "state", repoName)
+}
+
+// NewMetadataStore creates a metadata store for the given repository
+func NewMetadataStore(repoName string) *MetadataStore {
+	return &MetadataStore{path: filepath.Join(StateDir(repoName), "sessions.json")}
+}
+
+// load reads all metadata entries, returning an empty set if the file doesn't exist
+func (s *MetadataStore) load() (*metadataFile, error) {
+	file := &metadataFile{Sessions: map[string]*Metadata{}}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	if file.Sessions == nil {
+		file.Sessions = map[string]*Metadata{}
+	}
+	return file, nil
+}
+
+// save writes all metadata entries atomically, through a temporary file of
+// its own
+func (s *MetadataStore) save(file *metadataFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// modify applies fn to the metadata entries and saves them, holding the
+// store's lock throughout. Nothing is saved if fn fails.
+func (s *MetadataStore) modify(fn func(file *metadataFile) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	defer unlockFile(lock)
+
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(file); err != nil {
+		return err
+	}
+	return s.save(file)
+}
+
+// entryFor returns the entry of the session s, found by its path, creating
+// one under its name if it has none
+func (f *metadataFile) entryFor(s git.SessionInfo) *Metadata {
+	for _, m := range f.Sessions {
+		if m.Path == s.Path {
+			return m
+		}
+	}
+	m, ok := f.Sessions[s.Name]
+	if !ok {
+		m = &Metadata{Name: s.Name}
+		f.Sessions[s.Name] = m
+	}
+	if m.Path == "" {
+		m.Branch, m.Path = s.Branch, s.Path
+	}
+	return m
+}
+
+// All returns all metadata entries sorted by name
+func (s *MetadataStore) All() ([]*Metadata, error) {
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Metadata, 0, len(file.Sessions))
+	for _, m := range file.Sessions {
+		entries = append(entries, m)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Get returns the metadata for a session, or nil if none is recorded
+func (s *MetadataStore) Get(name string) (*Metadata, error) {
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return file.Sessions[name], nil
+}
+
+// FindByPath returns the metadata for the session at the given path, or nil
+func (s *MetadataStore) FindByPath(path string) (*Metadata, error) {
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range file.Sessions {
+		if m.Path == path {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// Put creates or replaces the metadata for a session
+func (s *MetadataStore) Put(m *Metadata) error {
+	return s.modify(func(file *metadataFile) error {
+		file.Sessions[m.Name] = m
+		return nil
+	})
+}
+
+// Update applies fn to the metadata of a session, creating the entry if needed
+func (s *MetadataStore) Update(name string, fn func(m *Metadata)) error {
+	return s.modify(func(file *metadataFile) error {
+		m, ok := file.Sessions[name]
+		if !ok {
+			m = &Metadata{Name: name}
+			file.Sessions[name] = m
+		}
+		fn(m)
+		return nil
+	})
+}
+
+// Rename re-keys the metadata of the session at oldPath under its new name,
+// branch and path, creating the entry if needed. Sessions that were created
+// from or stacked on oldBranch follow the rename.
+func (s *MetadataStore) Rename(oldPath, oldBranch string, renamed git.SessionInfo) error {
+	return s.modify(func(file *metadataFile) error {
+		var entry *Metadata
+		for key, m := range file.Sessions {
+			if m.Path == oldPath {
+				entry = m
+				delete(file.Sessions, key)
+				break
+			}
+		}
+		if entry == nil {
+			entry = &Metadata{CreatedAt: time.Now()}
+		}
+		entry.Name, entry.Branch, entry.Path = renamed.Name, renamed.Branch, renamed.Path
+		file.Sessions[renamed.Name] = entry
+
+		if renamed.Branch != oldBranch {
+			for _, m := range file.Sessions {
+				if m.Parent == oldBranch {
+					m.Parent = renamed.Branch
+				}
+				if m.BaseBranch == oldBranch {
+					m.BaseBranch = renamed.Branch
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the metadata for a session
+func (s *MetadataStore) Delete(name string) error {
+	return s.modify(func(file *metadataFile) error {
+		delete(file.Sessions, name)
+		return nil
+	})
+}
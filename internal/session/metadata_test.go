@@ -0,0 +1,92 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMetadataStore(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", originalHome)
+
+	store := NewMetadataStore("project")
+
+	// Empty store
+	entries, err := store.All()
+	if err != nil {
+		t.Fatalf("All() on empty store failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("All() returned %d entries, expected 0", len(entries))
+	}
+
+	if err := store.Put(&Metadata{Name: "feature-b", Branch: "feature/b", Path: "/tmp/b"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := store.Put(&Metadata{Name: "feature-a", Branch: "feature/a", Path: "/tmp/a"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	entries, err = store.All()
+	if err != nil {
+		t.Fatalf("All() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "feature-a" {
+		t.Errorf("All() should return 2 entries sorted by name, got %+v", entries)
+	}
+
+	entry, err := store.FindByPath("/tmp/b")
+	if err != nil || entry == nil || entry.Name != "feature-b" {
+		t.Errorf("FindByPath() = %+v, %v; expected feature-b", entry, err)
+	}
+
+	if err := store.Update("feature-b", func(m *Metadata) { m.Path = "/fast/b" }); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	entry, _ = store.Get("feature-b")
+	if entry == nil || entry.Path != "/fast/b" {
+		t.Errorf("Get() after Update() = %+v, expected path /fast/b", entry)
+	}
+
+	if err := store.Delete("feature-a"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if entry, _ := store.Get("feature-a"); entry != nil {
+		t.Error("Get() should return nil after Delete()")
+	}
+}
+
+func TestMetadataStoreConcurrentWriters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := NewMetadataStore("project")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("session-%d", i)
+			if err := store.Update(name, func(m *Metadata) { m.Notes = name }); err != nil {
+				t.Errorf("Update(%s) failed: %v", name, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := store.All()
+	if err != nil {
+		t.Fatalf("All() after concurrent writes failed: %v", err)
+	}
+	if len(entries) != writers {
+		t.Errorf("All() returned %d entries, expected %d: updates were lost", len(entries), writers)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(StateDir("project"), "*.tmp")); len(leftover) != 0 {
+		t.Errorf("temporary files left behind: %v", leftover)
+	}
+}
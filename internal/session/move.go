@@ -0,0 +1,102 @@
+package session
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// MoveSession relocates a session's worktree and updates its metadata.
+// Absolute symlinks inside the worktree that pointed into the old location
+// are re-pointed at the new one. Returns the number of symlinks fixed.
+func (m *Manager) MoveSession(info git.SessionInfo, newPath string) (int, error) {
+	fixed, err := m.moveSession(info, newPath)
+	m.Record(schema.OpMove, info.Name, branchList(info.Branch), err)
+	m.refreshWorkspace()
+	return fixed, err
+}
+
+// moveSession does the work of MoveSession
+func (m *Manager) moveSession(info git.SessionInfo, newPath string) (int, error) {
+	newPath, err := filepath.Abs(newPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to resolve destination")
+	}
+
+	if _, err := os.Stat(newPath); err == nil {
+		return 0, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, newPath)
+	}
+
+	if repo := git.FindEnclosingRepository(newPath); repo != "" {
+		return 0, fmt.Errorf("destination %s is inside the git repository %s", newPath, repo)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return 0, errors.Wrap(err, "failed to create destination directory")
+	}
+
+	if err := m.worktreeManager.Move(info.Path, newPath); err != nil {
+		return 0, err
+	}
+
+	fixed, err := relinkSymlinks(newPath, info.Path, newPath)
+	if err != nil {
+		return fixed, errors.Wrap(err, "worktree moved but failed to fix symlinks")
+	}
+
+	err = m.metadata.Update(info.Name, func(entry *Metadata) {
+		entry.Branch = info.Branch
+		entry.Path = newPath
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+	})
+	if err != nil {
+		return fixed, errors.Wrap(err, "worktree moved but failed to update session metadata")
+	}
+
+	return fixed, nil
+}
+
+// relinkSymlinks rewrites absolute symlinks under root whose target lies
+// within oldPrefix so that they point into newPrefix instead
+func relinkSymlinks(root, oldPrefix, newPrefix string) (int, error) {
+	fixed := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil || !filepath.IsAbs(target) {
+			return nil
+		}
+		if target != oldPrefix && !strings.HasPrefix(target, oldPrefix+string(filepath.Separator)) {
+			return nil
+		}
+
+		newTarget := newPrefix + strings.TrimPrefix(target, oldPrefix)
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if err := os.Symlink(newTarget, path); err != nil {
+			return err
+		}
+		fixed++
+		return nil
+	})
+	return fixed, err
+}
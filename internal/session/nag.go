@@ -0,0 +1,93 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// DirtySession is a session with uncommitted changes
+type DirtySession struct {
+	Name       string    `json:"name"`
+	Branch     string    `json:"branch"`
+	Path       string    `json:"path"`
+	DirtySince time.Time `json:"dirty_since"`
+}
+
+// dirtyCache is the on-disk cache of the last dirty-session scan
+type dirtyCache struct {
+	CheckedAt time.Time      `json:"checked_at"`
+	Sessions  []DirtySession `json:"sessions"`
+}
+
+// FindDirtySessions returns sessions whose uncommitted changes are older
+// than after. A scan result younger than cacheFor is reused, keeping prompt
+// hooks fast; pass zero to always scan.
+func (m *Manager) FindDirtySessions(after, cacheFor time.Duration) ([]DirtySession, error) {
+	cachePath := filepath.Join(StateDir(m.repoName), "dirty.json")
+
+	var dirty []DirtySession
+	cache, ok := readDirtyCache(cachePath)
+	if ok && cacheFor > 0 && time.Since(cache.CheckedAt) < cacheFor {
+		dirty = cache.Sessions
+	} else {
+		var err error
+		dirty, err = m.scanDirtySessions()
+		if err != nil {
+			return nil, err
+		}
+		writeDirtyCache(cachePath, dirtyCache{CheckedAt: time.Now(), Sessions: dirty})
+	}
+
+	var result []DirtySession
+	for _, d := range dirty {
+		if time.Since(d.DirtySince) > after {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// scanDirtySessions checks every session for uncommitted changes
+func (m *Manager) scanDirtySessions() ([]DirtySession, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirty []DirtySession
+	for _, s := range sessions {
+		since, isDirty, err := git.GetOldestChangeTime(s.Path)
+		if err != nil || !isDirty {
+			continue
+		}
+		dirty = append(dirty, DirtySession{Name: s.Name, Branch: s.Branch, Path: s.Path, DirtySince: since})
+	}
+	return dirty, nil
+}
+
+func readDirtyCache(path string) (dirtyCache, bool) {
+	var cache dirtyCache
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, false
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, false
+	}
+	return cache, true
+}
+
+func writeDirtyCache(path string, cache dirtyCache) {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
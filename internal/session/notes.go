@@ -0,0 +1,38 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// Notes returns the notes kept with s
+func (m *Manager) Notes(s git.SessionInfo) string {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return ""
+	}
+	return meta.Notes
+}
+
+// AppendNote adds text to the notes of s on a line of its own, returning
+// the notes it ends up with
+func (m *Manager) AppendNote(s git.SessionInfo, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	var notes string
+	err := m.updateMetadata(s, func(meta *Metadata) {
+		if meta.Notes != "" && text != "" {
+			meta.Notes += "\n"
+		}
+		meta.Notes += text
+		notes = meta.Notes
+	})
+	return notes, err
+}
+
+// SetNotes replaces the notes of s with text; empty text clears them
+func (m *Manager) SetNotes(s git.SessionInfo, text string) error {
+	return m.updateMetadata(s, func(meta *Metadata) {
+		meta.Notes = strings.TrimSpace(text)
+	})
+}
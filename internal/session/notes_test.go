@@ -0,0 +1,63 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("feature"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	feature := findByName(sessions, "feature")
+
+	if notes := manager.Notes(*feature); notes != "" {
+		t.Errorf("Notes() of a new session = %q, expected none", notes)
+	}
+
+	if _, err := manager.AppendNote(*feature, "refactoring the login form"); err != nil {
+		t.Fatalf("AppendNote() failed: %v", err)
+	}
+	notes, err := manager.AppendNote(*feature, "  blocked on the API review\n")
+	if err != nil {
+		t.Fatalf("AppendNote() failed: %v", err)
+	}
+	expected := "refactoring the login form\nblocked on the API review"
+	if notes != expected || manager.Notes(*feature) != expected {
+		t.Errorf("notes after appending = %q, expected %q", manager.Notes(*feature), expected)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Name == "feature" && s.Notes != expected {
+			t.Errorf("Status() notes = %q, expected %q", s.Notes, expected)
+		}
+	}
+
+	if err := manager.SetNotes(*feature, "done\n\n"); err != nil {
+		t.Fatalf("SetNotes() failed: %v", err)
+	}
+	if notes := manager.Notes(*feature); notes != "done" {
+		t.Errorf("Notes() after SetNotes() = %q, expected done", notes)
+	}
+	if err := manager.SetNotes(*feature, ""); err != nil || manager.Notes(*feature) != "" {
+		t.Errorf("SetNotes() with no text should clear the notes, got %q, %v", manager.Notes(*feature), err)
+	}
+}
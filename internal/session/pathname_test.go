@@ -0,0 +1,73 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionPathsForBranchNames(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	long := "feature/" + strings.Repeat("very-long-branch-name-", 8) + "end"
+	branches := map[string]string{
+		"feature/foo-bar": "feature-foo-bar",
+		"users/jo/wip":    "users-jo-wip",
+		"fix/Ünïcode-名前":  "fix-ünïcode-名前",
+		long:              SessionNameForBranch(long),
+	}
+
+	manager := NewManager(repo)
+	root := filepath.Dir(manager.GetSessionPath("x"))
+	for branch := range branches {
+		runGit(t, repo, "branch", branch)
+		if err := manager.CheckoutSession(branch); err != nil {
+			t.Fatalf("CheckoutSession(%q) failed: %v", branch, err)
+		}
+	}
+
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() failed: %v", err)
+	}
+	if len(sessions) != len(branches)+1 {
+		t.Fatalf("ListSessions() = %+v, expected main and %d sessions", sessions, len(branches))
+	}
+	for _, s := range sessions[1:] {
+		name, ok := branches[s.Branch]
+		if !ok || s.Name != name {
+			t.Errorf("session of %s is named %q, expected %q", s.Branch, s.Name, name)
+		}
+		// Every worktree is directly in the root, however the branch is named
+		if filepath.Dir(s.Path) != root {
+			t.Errorf("worktree of %s is at %s, expected it in %s", s.Branch, s.Path, root)
+		}
+		if len(filepath.Base(s.Path)) > 100 {
+			t.Errorf("worktree directory of %s is %d bytes long", s.Branch, len(filepath.Base(s.Path)))
+		}
+		if s.Path != manager.GetSessionPath(s.Name) {
+			t.Errorf("worktree of %s is at %s, expected %s", s.Name, s.Path, manager.GetSessionPath(s.Name))
+		}
+	}
+
+	// The long name survives a rename to another long name and back
+	s := findByName(sessions, branches[long])
+	renamed, err := manager.RenameSession(*s, strings.Repeat("another-long-name-", 8), true)
+	if err != nil {
+		t.Fatalf("RenameSession() failed: %v", err)
+	}
+	sessions, _ = manager.ListSessions()
+	if found := findByName(sessions, renamed.Name); found == nil || found.Path != renamed.Path {
+		t.Errorf("renamed session %q not found at %s in %+v", renamed.Name, renamed.Path, sessions)
+	}
+}
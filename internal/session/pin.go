@@ -0,0 +1,28 @@
+package session
+
+import (
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetPinned pins s, protecting it from cleanup and prune-artifacts, or
+// unpins it
+func (m *Manager) SetPinned(s git.SessionInfo, pinned bool) error {
+	return m.updateMetadata(s, func(meta *Metadata) {
+		meta.Pinned = pinned
+	})
+}
+
+// Pinned returns the pinned sessions, keyed by worktree path
+func (m *Manager) Pinned() map[string]bool {
+	pinned := make(map[string]bool)
+	entries, err := m.metadata.All()
+	if err != nil {
+		return pinned
+	}
+	for _, entry := range entries {
+		if entry.Pinned {
+			pinned[entry.Path] = true
+		}
+	}
+	return pinned
+}
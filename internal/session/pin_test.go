@@ -0,0 +1,58 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPinned(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	for _, name := range []string{"keep", "other"} {
+		if err := manager.CreateSession(name); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", name, err)
+		}
+	}
+	sessions, _ := manager.ListSessions()
+	keep := findByName(sessions, "keep")
+
+	if pinned := manager.Pinned(); len(pinned) != 0 {
+		t.Errorf("Pinned() before pinning = %v, expected none", pinned)
+	}
+
+	if err := manager.SetPinned(*keep, true); err != nil {
+		t.Fatalf("SetPinned() failed: %v", err)
+	}
+	pinned := manager.Pinned()
+	if len(pinned) != 1 || !pinned[keep.Path] {
+		t.Errorf("Pinned() = %v, expected only %s", pinned, keep.Path)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.Pinned != (s.Name == "keep") {
+			t.Errorf("Status() reports %s pinned = %v", s.Name, s.Pinned)
+		}
+	}
+
+	if err := manager.SetPinned(*keep, false); err != nil {
+		t.Fatalf("SetPinned(false) failed: %v", err)
+	}
+	if pinned := manager.Pinned(); len(pinned) != 0 {
+		t.Errorf("Pinned() after unpinning = %v, expected none", pinned)
+	}
+}
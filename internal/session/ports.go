@@ -0,0 +1,166 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// EnvFile is the dotenv file in a worktree that the session's variables are
+// written to when env.file is set
+const EnvFile = ".env.ccswitch"
+
+// maxPort is the highest TCP port
+const maxPort = 65535
+
+// envVarName matches the names variables can be given with env --set
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Port returns the first port of the block allocated to s, allocating the
+// lowest free block if s has none yet. It returns 0 when env.port_base is
+// not set. The block is picked and recorded under the metadata store's
+// lock, so sessions whose commands start at the same time get different
+// blocks.
+func (m *Manager) Port(s git.SessionInfo) (int, error) {
+	if m.config.Env.PortBase <= 0 {
+		return 0, nil
+	}
+	if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil && meta.Port != 0 {
+		return meta.Port, nil
+	}
+
+	var port int
+	err := m.metadata.modify(func(file *metadataFile) error {
+		meta := file.entryFor(s)
+		if meta.Port == 0 {
+			free, err := m.freePort(file)
+			if err != nil {
+				return err
+			}
+			meta.Port = free
+		}
+		port = meta.Port
+		return nil
+	})
+	return port, err
+}
+
+// freePort returns the first port of the lowest block no session in file
+// has
+func (m *Manager) freePort(file *metadataFile) (int, error) {
+	used := make(map[int]bool, len(file.Sessions))
+	for _, entry := range file.Sessions {
+		used[entry.Port] = true
+	}
+
+	size := m.config.PortBlockSize()
+	for port := m.config.Env.PortBase; port+size-1 <= maxPort; port += size {
+		if !used[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free block of %d ports from %d", size, m.config.Env.PortBase)
+}
+
+// SessionVars returns the variables ccswitch sets for the commands of s
+// besides those describing it, as NAME=value entries: CCSWITCH_PORT and
+// CCSWITCH_PORT_END bounding its block of ports, env.vars, and the
+// variables set for s alone, which override env.vars
+func (m *Manager) SessionVars(s git.SessionInfo) ([]string, error) {
+	port, err := m.Port(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars []string
+	if port != 0 {
+		vars = append(vars,
+			EnvPrefix+"PORT="+strconv.Itoa(port),
+			EnvPrefix+"PORT_END="+strconv.Itoa(port+m.config.PortBlockSize()-1),
+		)
+	}
+
+	overrides := map[string]string{}
+	if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil {
+		overrides = meta.Env
+	}
+	for _, kv := range m.config.SessionEnv(s.Name, s.Branch, port) {
+		if _, ok := overrides[envName(kv)]; !ok {
+			vars = append(vars, kv)
+		}
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vars = append(vars, name+"="+overrides[name])
+	}
+	return vars, nil
+}
+
+// SetSessionEnv sets the variables in set for the commands of s alone and
+// removes those named in unset
+func (m *Manager) SetSessionEnv(s git.SessionInfo, set map[string]string, unset []string) error {
+	for name := range set {
+		if !envVarName.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+
+	return m.updateMetadata(s, func(meta *Metadata) {
+		if meta.Env == nil {
+			meta.Env = map[string]string{}
+		}
+		for name, value := range set {
+			meta.Env[name] = value
+		}
+		for _, name := range unset {
+			delete(meta.Env, name)
+		}
+		if len(meta.Env) == 0 {
+			meta.Env = nil
+		}
+	})
+}
+
+// WriteEnvFile writes the variables of s to EnvFile in its worktree, which
+// git is told to ignore, and returns the file's path
+func (m *Manager) WriteEnvFile(s git.SessionInfo) (string, error) {
+	vars, err := m.SessionVars(s)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("# Written by ccswitch for session " + s.Name + "; changes are overwritten\n")
+	for _, kv := range vars {
+		name, value, _ := strings.Cut(kv, "=")
+		b.WriteString(name + "=" + dotenvValue(value) + "\n")
+	}
+
+	if err := git.Exclude(s.Path, "/"+EnvFile); err != nil {
+		return "", fmt.Errorf("failed to ignore %s: %w", EnvFile, err)
+	}
+	path := filepath.Join(s.Path, EnvFile)
+	return path, os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// dotenvValue quotes a value for a dotenv file unless it is made of
+// characters that need none
+func dotenvValue(value string) string {
+	if value != "" && !strings.ContainsFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+=%", r))
+	}) {
+		return value
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestSessionPortsAndVars(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	cfg := manager.Config()
+	cfg.Env.PortBase = 4000
+	cfg.Env.PortBlock = 5
+	cfg.Env.Vars = map[string]string{"PORT": "{port}", "API_PORT": "{port+1}", "DB": "app_{session}"}
+	cfg.Env.File = true
+
+	for _, name := range []string{"first", "second", "third"} {
+		if err := manager.CreateSession(name); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", name, err)
+		}
+	}
+	sessions, _ := manager.ListSessions()
+	first, second, third := findByName(sessions, "first"), findByName(sessions, "second"), findByName(sessions, "third")
+	if first == nil || second == nil || third == nil {
+		t.Fatalf("sessions not found in %+v", sessions)
+	}
+
+	// Deleting a session frees its block for the next one
+	if err := manager.RemoveSession(second.Path, true, second.Branch); err != nil {
+		t.Fatalf("RemoveSession(second) failed: %v", err)
+	}
+	if err := manager.CreateSession("fourth"); err != nil {
+		t.Fatalf("CreateSession(fourth) failed: %v", err)
+	}
+	sessions, _ = manager.ListSessions()
+	fourth := findByName(sessions, "fourth")
+
+	for _, tt := range []struct {
+		name     string
+		expected int
+	}{{"first", 4000}, {"third", 4010}, {"fourth", 4005}} {
+		s := findByName(sessions, tt.name)
+		if port, err := manager.Port(*s); err != nil || port != tt.expected {
+			t.Errorf("Port(%s) = %d, %v; expected %d", tt.name, port, err, tt.expected)
+		}
+	}
+
+	if err := manager.SetSessionEnv(*fourth, map[string]string{"DB": "shared db"}, nil); err != nil {
+		t.Fatalf("SetSessionEnv() failed: %v", err)
+	}
+	if err := manager.SetSessionEnv(*fourth, map[string]string{"not valid": "x"}, nil); err == nil {
+		t.Error("SetSessionEnv() accepted an invalid name")
+	}
+
+	vars, err := manager.SessionVars(*fourth)
+	if err != nil {
+		t.Fatalf("SessionVars() failed: %v", err)
+	}
+	expected := []string{"CCSWITCH_PORT=4005", "CCSWITCH_PORT_END=4009", "API_PORT=4006", "PORT=4005", "DB=shared db"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("SessionVars() = %v, expected %v", vars, expected)
+	}
+
+	env := manager.Env([]string{"PORT=3000"}, *fourth)
+	if !containsAll(env, "PORT=4005", "CCSWITCH_SESSION=fourth") || containsAll(env, "PORT=3000") {
+		t.Errorf("Env() = %v, expected the session's ports to replace PORT", env)
+	}
+
+	// The env file was written on creation, before the override
+	data, err := os.ReadFile(filepath.Join(first.Path, EnvFile))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", EnvFile, err)
+	}
+	if !strings.Contains(string(data), "PORT=4000\n") || !strings.Contains(string(data), "DB=app_first\n") {
+		t.Errorf("%s = %q, expected the ports and variables of first", EnvFile, data)
+	}
+	if _, err := manager.WriteEnvFile(*fourth); err != nil {
+		t.Fatalf("WriteEnvFile() failed: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(fourth.Path, EnvFile))
+	if !strings.Contains(string(data), `DB="shared db"`) {
+		t.Errorf("%s = %q, expected the override quoted", EnvFile, data)
+	}
+
+	status, err := exec.Command("git", "-C", first.Path, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatalf("git status failed: %v", err)
+	}
+	if strings.Contains(string(status), EnvFile) {
+		t.Errorf("git status shows %s: %q", EnvFile, status)
+	}
+}
+
+// containsAll reports whether env has all of the given entries
+func containsAll(env []string, entries ...string) bool {
+	for _, entry := range entries {
+		found := false
+		for _, kv := range env {
+			found = found || kv == entry
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPortConcurrently(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	manager := NewManager(filepath.Join(t.TempDir(), "project"))
+	manager.Config().Env.PortBase = 4000
+	manager.Config().Env.PortBlock = 5
+
+	const sessions = 8
+	ports := make([]int, sessions)
+	var wg sync.WaitGroup
+	for i := range ports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("session-%d", i)
+			port, err := manager.Port(git.SessionInfo{Name: name, Branch: "feature/" + name, Path: "/work/" + name})
+			if err != nil {
+				t.Errorf("Port(%s) failed: %v", name, err)
+			}
+			ports[i] = port
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool)
+	for _, port := range ports {
+		if port == 0 || seen[port] {
+			t.Fatalf("Port() in parallel = %v, expected a block of its own for each session", ports)
+		}
+		seen[port] = true
+	}
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// PromptInfo is what 'ccswitch prompt' shows about the session a
+// directory is in
+type PromptInfo struct {
+	Name string `json:"name"`
+	// Branch is empty when the worktree's HEAD is detached, and Commit
+	// is then the commit it is at
+	Branch string `json:"branch"`
+	Commit string `json:"commit,omitempty"`
+	Dirty  bool   `json:"dirty"`
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+}
+
+// Prompt returns what the shell prompt shows for the session in the
+// worktree wt. Whether it is dirty is kept on disk for ttl, or until HEAD
+// or the index change; a ttl of 0 asks git every time.
+func Prompt(wt git.GitDirs, ttl time.Duration) PromptInfo {
+	repo := filepath.Base(filepath.Dir(wt.CommonDir))
+	info := PromptInfo{Name: filepath.Base(wt.Path), Repo: repo, Path: wt.Path}
+	if entry, err := NewMetadataStore(repo).FindByPath(wt.Path); err == nil && entry != nil {
+		info.Name = entry.Name
+	}
+	info.Branch, info.Commit, _ = wt.Head()
+	if len(info.Commit) > 7 {
+		info.Commit = info.Commit[:7]
+	}
+
+	cache := &git.StatusCache{MaxAge: ttl}
+	if ttl > 0 {
+		cache.Dir = StatusCacheDir(repo)
+	}
+	info.Dirty = cache.HasUncommittedChanges(wt.Path)
+	return info
+}
+
+// StatusCacheDir returns the directory git.StatusCache keeps the answers
+// of a repository in on disk
+func StatusCacheDir(repoName string) string {
+	return filepath.Join(StateDir(repoName), "status")
+}
@@ -0,0 +1,63 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	feature := filepath.Join(root, "fix-login")
+	runGit(t, repo, "worktree", "add", "-b", "feature/fix-login", feature)
+	wt, ok := git.FindLinkedWorktree(feature)
+	if !ok {
+		t.Fatal("FindLinkedWorktree() found no worktree")
+	}
+
+	info := Prompt(wt, time.Hour)
+	if info.Name != "fix-login" || info.Branch != "feature/fix-login" || info.Dirty || info.Repo != "project" {
+		t.Fatalf("Prompt() = %+v, expected clean fix-login on feature/fix-login in project", info)
+	}
+
+	// Unstaged changes only show once the cache expires
+	if err := os.WriteFile(filepath.Join(feature, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if info := Prompt(wt, time.Hour); info.Dirty {
+		t.Errorf("Prompt() within the cache TTL = %+v, expected the cached clean state", info)
+	}
+	if info := Prompt(wt, 0); !info.Dirty {
+		t.Errorf("Prompt() without caching = %+v, expected dirty", info)
+	}
+
+	// Committing moves the branch, which invalidates the cache
+	runGit(t, feature, "commit", "-qam", "Change readme")
+	if info := Prompt(wt, time.Hour); info.Dirty {
+		t.Errorf("Prompt() after committing = %+v, expected clean", info)
+	}
+
+	// So does staging, which writes the index
+	time.Sleep(10 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(feature, "README.md"), []byte("changed again\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, feature, "add", "README.md")
+	if info := Prompt(wt, time.Hour); !info.Dirty {
+		t.Errorf("Prompt() after staging = %+v, expected the cache to be invalidated", info)
+	}
+}
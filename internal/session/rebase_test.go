@@ -0,0 +1,149 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestRebaseSessionVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	feature := filepath.Join(root, "feature")
+	runGit(t, repo, "worktree", "add", "-b", "feature", feature)
+	commitFile(t, feature, "broken.txt", "breaks the tests\n")
+	commitFile(t, repo, "main.txt", "main\n")
+
+	before, err := git.ResolveRef(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(repo)
+	manager.Config().Git.VerifyCommand = "test ! -f broken.txt || { echo tests failed; exit 1; }"
+	err = manager.RebaseSession(feature)
+	if !errors.IsVerifyFailed(err) || !strings.Contains(err.Error(), "tests failed") {
+		t.Fatalf("RebaseSession() error = %v, expected the verify command's failure", err)
+	}
+	if after, _ := git.ResolveRef(repo, "main"); after != before {
+		t.Errorf("main = %s, expected it rolled back to %s", after, before)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "broken.txt")); err == nil {
+		t.Error("rolled back worktree still has the rebased files")
+	}
+
+	manager.Config().Git.VerifyCommand = "true"
+	if err := manager.RebaseSession(feature); err != nil {
+		t.Fatalf("RebaseSession() failed: %v", err)
+	}
+	if after, _ := git.ResolveRef(repo, "main"); after == before {
+		t.Error("main was not rebased although verification passed")
+	}
+}
+
+func TestSquashAndRebaseSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	feature := filepath.Join(root, "feature")
+	runGit(t, repo, "worktree", "add", "-b", "feature", feature)
+	manager := NewManager(repo)
+	if err := manager.SquashAndRebaseSession(feature, "main", "Nothing"); err == nil {
+		t.Error("SquashAndRebaseSession() without commits or changes should fail")
+	}
+
+	commitFile(t, feature, "one.txt", "wip 1\n")
+	commitFile(t, feature, "two.txt", "wip 2\n")
+	commitFile(t, repo, "main.txt", "main\n")
+	if err := os.WriteFile(filepath.Join(feature, "three.txt"), []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	tip, _ := git.ResolveRef(feature, "HEAD")
+
+	if err := manager.SquashAndRebaseSession(feature, "main", "Add the feature"); err != nil {
+		t.Fatalf("SquashAndRebaseSession() failed: %v", err)
+	}
+	if n, _ := git.CountCommits(repo, "main..feature"); n != 0 {
+		t.Errorf("feature has %d commit(s) not on main, expected none", n)
+	}
+	if n, _ := git.CountCommits(repo, "main"); n != 3 {
+		t.Errorf("main has %d commit(s), expected the squashed commit besides its own two", n)
+	}
+	commits, err := git.GetCommits(repo, "feature~1..feature", time.Time{})
+	if err != nil || len(commits) != 1 || commits[0].Subject != "Add the feature" {
+		t.Fatalf("feature ends with %+v (%v), expected one commit \"Add the feature\"", commits, err)
+	}
+	for _, name := range []string{"one.txt", "two.txt", "three.txt"} {
+		if _, err := os.Stat(filepath.Join(feature, name)); err != nil {
+			t.Errorf("%s missing from the squashed session: %v", name, err)
+		}
+	}
+	if backup, _ := git.ResolveRef(feature, git.BackupRef("feature")); backup != tip {
+		t.Errorf("backup of feature = %s, expected the tip before the squash %s", backup, tip)
+	}
+}
+
+func TestSquashKeepsAuthors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "README.md", "readme\n")
+
+	feature := filepath.Join(root, "feature")
+	runGit(t, repo, "worktree", "add", "-b", "feature", feature)
+	commitFile(t, feature, "one.txt", "1\n", "--author", "Ann <ann@example.com>")
+	commitFile(t, feature, "two.txt", "2\n", "--author", "Bob <bob@example.com>")
+	commitFile(t, feature, "three.txt", "3\n", "--author", "Ann <ann@example.com>")
+
+	manager := NewManager(repo)
+	manager.Config().Git.KeepAuthor = true
+	manager.Config().Git.CoAuthors = []string{"Pat <pat@example.com>"}
+	if err := manager.SquashAndRebaseSession(feature, "main", "Add the feature"); err != nil {
+		t.Fatalf("SquashAndRebaseSession() failed: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", repo, "log", "-1", "--format=%an <%ae>%n%cn%n%B", "feature").Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	want := "Ann <ann@example.com>\nTest User\nAdd the feature\n\nCo-authored-by: Pat <pat@example.com>\nCo-authored-by: Bob <bob@example.com>"
+	if got := strings.TrimSpace(string(out)); got != want {
+		t.Errorf("squashed commit =\n%s\nexpected\n%s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// RenameSession renames a session after description: its branch is named
+// after it by the branch naming policy unless keepBranch is set, and its
+// worktree directory is renamed to match. If any step fails, the steps done
+// so far are undone. Returns the renamed session.
+func (m *Manager) RenameSession(info git.SessionInfo, description string, keepBranch bool) (git.SessionInfo, error) {
+	renamed, err := m.renameSession(info, description, keepBranch)
+	if renamed != info {
+		branches := branchList(info.Branch)
+		if renamed.Branch != info.Branch {
+			branches = append(branches, renamed.Branch)
+		}
+		m.Record(schema.OpRename, info.Name, branches, err)
+		m.refreshWorkspace()
+	}
+	return renamed, err
+}
+
+// renameSession does the work of RenameSession
+func (m *Manager) renameSession(info git.SessionInfo, description string, keepBranch bool) (git.SessionInfo, error) {
+	renamed := git.SessionInfo{
+		Name:   utils.Slugify(description),
+		Branch: info.Branch,
+	}
+	if renamed.Name == "" {
+		return info, fmt.Errorf("invalid session name: %q", description)
+	}
+	// Detached sessions have no branch to rename
+	if !keepBranch && !info.Detached() {
+		branch, err := m.BranchName(renamed.Name)
+		if err != nil {
+			return info, err
+		}
+		renamed.Branch = branch
+	}
+	// Sessions at their configured location stay there; moved ones are
+	// renamed where they are
+	renamed.Path = filepath.Join(filepath.Dir(info.Path), utils.PathName(renamed.Name))
+	if info.Path == m.GetSessionPath(info.Name) {
+		renamed.Path = m.GetSessionPath(renamed.Name)
+	}
+
+	if renamed == info {
+		return info, fmt.Errorf("session is already named %s", info.Name)
+	}
+
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return info, err
+	}
+	for _, s := range sessions {
+		if s.Name == renamed.Name && s.Path != info.Path {
+			return info, fmt.Errorf("%w: a session named %s exists at %s", errors.ErrWorktreeExists, s.Name, s.Path)
+		}
+	}
+	if renamed.Branch != info.Branch && m.branchManager.Exists(renamed.Branch) {
+		return info, fmt.Errorf("%w: %s", errors.ErrBranchExists, renamed.Branch)
+	}
+	if renamed.Path != info.Path {
+		if _, err := os.Stat(renamed.Path); err == nil {
+			return info, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, renamed.Path)
+		}
+	}
+
+	// Each completed step registers how to undo it
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	if renamed.Branch != info.Branch {
+		if err := m.branchManager.Rename(info.Branch, renamed.Branch); err != nil {
+			return info, err
+		}
+		undo = append(undo, func() { _ = m.branchManager.Rename(renamed.Branch, info.Branch) })
+	}
+
+	if renamed.Path != info.Path {
+		if err := m.worktreeManager.Move(info.Path, renamed.Path); err != nil {
+			rollback()
+			return info, err
+		}
+		undo = append(undo, func() { _ = m.worktreeManager.Move(renamed.Path, info.Path) })
+
+		// Symlinks may be partly fixed when relinking fails, so always undo
+		undo = append(undo, func() { _, _ = relinkSymlinks(renamed.Path, renamed.Path, info.Path) })
+		if _, err := relinkSymlinks(renamed.Path, info.Path, renamed.Path); err != nil {
+			rollback()
+			return info, errors.Wrap(err, "failed to fix symlinks")
+		}
+	}
+
+	if err := m.metadata.Rename(info.Path, info.Branch, renamed); err != nil {
+		rollback()
+		return info, errors.Wrap(err, "failed to update session metadata")
+	}
+
+	// Heartbeats are stored by session name; the agent publishes a new one
+	m.removeHeartbeat(info.Path)
+
+	return renamed, nil
+}
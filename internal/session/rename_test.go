@@ -0,0 +1,102 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("old name"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	old := findByName(sessions, "old-name")
+	if old == nil {
+		t.Fatalf("old-name session not found in %+v", sessions)
+	}
+	child, err := manager.CreateStackedSession("child", *old)
+	if err != nil {
+		t.Fatalf("CreateStackedSession() failed: %v", err)
+	}
+
+	renamed, err := manager.RenameSession(*old, "New Name", false)
+	if err != nil {
+		t.Fatalf("RenameSession() failed: %v", err)
+	}
+	if renamed.Name != "new-name" || renamed.Branch != "feature/new-name" || renamed.Path != filepath.Join(filepath.Dir(old.Path), "new-name") {
+		t.Errorf("RenameSession() = %+v", renamed)
+	}
+
+	sessions, _ = manager.ListSessions()
+	if findByName(sessions, "old-name") != nil {
+		t.Errorf("old-name should be gone, got %+v", sessions)
+	}
+	if s := findByName(sessions, "new-name"); s == nil || *s != renamed {
+		t.Errorf("ListSessions() should contain %+v, got %+v", renamed, sessions)
+	}
+	if manager.branchManager.Exists(old.Branch) {
+		t.Errorf("branch %s should have been renamed", old.Branch)
+	}
+
+	meta, _ := manager.metadata.Get("new-name")
+	if meta == nil || meta.Path != renamed.Path || meta.BaseBranch != "main" {
+		t.Errorf("metadata of new-name = %+v", meta)
+	}
+	if meta, _ := manager.metadata.Get(child.Name); meta == nil || meta.Parent != renamed.Branch {
+		t.Errorf("child should be stacked on %s, got %+v", renamed.Branch, meta)
+	}
+
+	if _, err := manager.RenameSession(renamed, "child", false); err == nil {
+		t.Error("renaming onto an existing session should fail")
+	}
+}
+
+func TestRenameSessionRollsBack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("locked"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	locked := findByName(sessions, "locked")
+	if locked == nil {
+		t.Fatalf("locked session not found in %+v", sessions)
+	}
+
+	// A locked worktree cannot be moved, so the branch rename is undone
+	runGit(t, repo, "worktree", "lock", locked.Path)
+	if _, err := manager.RenameSession(*locked, "unlocked", false); err == nil {
+		t.Fatal("RenameSession() of a locked worktree should fail")
+	}
+
+	if !manager.branchManager.Exists(locked.Branch) || manager.branchManager.Exists("feature/unlocked") {
+		t.Error("the branch rename should have been rolled back")
+	}
+	sessions, _ = manager.ListSessions()
+	if s := findByName(sessions, "locked"); s == nil || *s != *locked {
+		t.Errorf("session should be unchanged, got %+v", sessions)
+	}
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Repo is a repository ccswitch has been used in
+type Repo struct {
+	// Name is the repository's directory name, accepted by --repo
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// reposFile is the on-disk format of the repository registry
+type reposFile struct {
+	Repos map[string]*Repo `json:"repos"`
+}
+
+// reposPath returns the file listing the known repositories
+func reposPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ccswitch", "repos.json")
+}
+
+func loadRepos() (*reposFile, error) {
+	file := &reposFile{Repos: map[string]*Repo{}}
+
+	data, err := os.ReadFile(reposPath())
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	if file.Repos == nil {
+		file.Repos = map[string]*Repo{}
+	}
+	return file, nil
+}
+
+func saveRepos(file *reposFile) error {
+	path := reposPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// RegisterRepo adds the main repository at path to the registry, if it is
+// not known yet
+func RegisterRepo(path string) error {
+	file, err := loadRepos()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Repos[path]; ok {
+		return nil
+	}
+
+	file.Repos[path] = &Repo{Name: filepath.Base(path), Path: path, AddedAt: time.Now()}
+	return saveRepos(file)
+}
+
+// Repos returns the known repositories sorted by name
+func Repos() ([]*Repo, error) {
+	file, err := loadRepos()
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]*Repo, 0, len(file.Repos))
+	for _, r := range file.Repos {
+		repos = append(repos, r)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].Name != repos[j].Name {
+			return repos[i].Name < repos[j].Name
+		}
+		return repos[i].Path < repos[j].Path
+	})
+	return repos, nil
+}
+
+// FindRepo returns the known repository with the given name or path
+func FindRepo(name string) (*Repo, error) {
+	repos, err := Repos()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Repo
+	for _, r := range repos {
+		if r.Path == name {
+			return r, nil
+		}
+		if r.Name == name {
+			matches = append(matches, r)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no known repository named %s (see 'ccswitch repos list')", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("several repositories are named %s; pass the path instead, e.g. %s", name, matches[0].Path)
+	}
+}
+
+// ForgetRepo removes a repository from the registry. Its sessions are left
+// untouched.
+func ForgetRepo(path string) error {
+	file, err := loadRepos()
+	if err != nil {
+		return err
+	}
+	delete(file.Repos, path)
+	return saveRepos(file)
+}
@@ -0,0 +1,47 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestRepoRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if repos, err := Repos(); err != nil || len(repos) != 0 {
+		t.Fatalf("Repos() on empty registry = %v, %v", repos, err)
+	}
+
+	for _, path := range []string{"/src/api", "/src/web", "/work/api", "/src/api"} {
+		if err := RegisterRepo(path); err != nil {
+			t.Fatalf("RegisterRepo(%s) failed: %v", path, err)
+		}
+	}
+
+	repos, err := Repos()
+	if err != nil {
+		t.Fatalf("Repos() failed: %v", err)
+	}
+	if len(repos) != 3 || repos[0].Path != "/src/api" || repos[2].Name != "web" {
+		t.Fatalf("Repos() should return 3 repos sorted by name, got %+v", repos)
+	}
+
+	if repo, err := FindRepo("web"); err != nil || repo.Path != "/src/web" {
+		t.Errorf("FindRepo(web) = %+v, %v", repo, err)
+	}
+	if _, err := FindRepo("api"); err == nil {
+		t.Error("FindRepo(api) should fail when two repositories share the name")
+	}
+	if repo, err := FindRepo("/work/api"); err != nil || repo.Name != "api" {
+		t.Errorf("FindRepo(/work/api) = %+v, %v", repo, err)
+	}
+	if _, err := FindRepo("missing"); err == nil {
+		t.Error("FindRepo(missing) should fail")
+	}
+
+	if err := ForgetRepo("/work/api"); err != nil {
+		t.Fatalf("ForgetRepo() failed: %v", err)
+	}
+	if repo, err := FindRepo("api"); err != nil || repo.Path != "/src/api" {
+		t.Errorf("FindRepo(api) after forgetting the other = %+v, %v", repo, err)
+	}
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// StackEntry is a session in a stack of branches built on each other
+type StackEntry struct {
+	Session git.SessionInfo
+	// Parent is the branch the session is stacked on, empty for stack roots
+	Parent   string
+	Children []*StackEntry
+	// NeedsRestack is set when the parent has moved since the session was
+	// last based on it
+	NeedsRestack bool
+}
+
+// RestackResult is the outcome of restacking one session
+type RestackResult struct {
+	Session string
+	Branch  string
+	Path    string
+	Parent  string
+	// Base is the commit the session's own commits were replayed from
+	Base string
+	// UpToDate is set when the session was already based on its parent's tip
+	UpToDate bool
+	Err      error
+}
+
+// CreateStackedSession creates a new session whose branch starts at the tip
+// of parent and records parent in its metadata
+func (m *Manager) CreateStackedSession(description string, parent git.SessionInfo) (*Metadata, error) {
+	base, err := git.ResolveRef(m.repoPath, parent.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := m.createSession(description, parent.Branch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Parent = parent.Branch
+	entry.ParentBase = base
+	if err := m.metadata.Put(entry); err != nil {
+		return nil, fmt.Errorf("failed to record stack parent: %w", err)
+	}
+	return entry, nil
+}
+
+// Stacks returns the stacks of sessions as trees. Only sessions that are
+// stacked on another session, or have sessions stacked on them, are included.
+func (m *Manager) Stacks() ([]*StackEntry, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := m.metadata.All()
+	if err != nil {
+		return nil, err
+	}
+
+	byBranch := make(map[string]*StackEntry, len(sessions))
+	for _, s := range sessions {
+		byBranch[s.Branch] = &StackEntry{Session: s}
+	}
+
+	var roots []*StackEntry
+	inStack := make(map[string]bool)
+	for _, meta := range entries {
+		child, ok := byBranch[meta.Branch]
+		if !ok || meta.Parent == "" {
+			continue
+		}
+		parent, ok := byBranch[meta.Parent]
+		if !ok {
+			// Parent session is gone: the session heads its own stack
+			continue
+		}
+
+		child.Parent = meta.Parent
+		child.NeedsRestack = m.needsRestack(meta)
+		parent.Children = append(parent.Children, child)
+		inStack[meta.Branch] = true
+		inStack[meta.Parent] = true
+	}
+
+	for _, s := range sessions {
+		entry := byBranch[s.Branch]
+		if inStack[s.Branch] && entry.Parent == "" {
+			roots = append(roots, entry)
+		}
+	}
+	return roots, nil
+}
+
+// needsRestack reports whether the parent of a stacked session has moved
+// since the session was last based on it
+func (m *Manager) needsRestack(meta *Metadata) bool {
+	tip, err := git.ResolveRef(m.repoPath, meta.Parent)
+	if err != nil {
+		return false
+	}
+	if meta.ParentBase != "" {
+		return tip != meta.ParentBase
+	}
+	return !git.IsAncestor(m.repoPath, tip, meta.Branch)
+}
+
+// RestackTargets returns the stacked sessions Restack would process, parents
+// before children. If from is non-empty only that session's descendants are
+// included.
+func (m *Manager) RestackTargets(from string) ([]*StackEntry, error) {
+	roots, err := m.Stacks()
+	if err != nil {
+		return nil, err
+	}
+
+	var queue []*StackEntry
+	var collect func(entries []*StackEntry, include bool)
+	collect = func(entries []*StackEntry, include bool) {
+		for _, e := range entries {
+			if include && e.Parent != "" {
+				queue = append(queue, e)
+			}
+			collect(e.Children, include || e.Session.Name == from || e.Session.Branch == from)
+		}
+	}
+	collect(roots, from == "")
+	return queue, nil
+}
+
+// Restack rebases every stacked session in targets (see RestackTargets)
+// whose parent has moved onto the parent's new tip, replaying only the
+// session's own commits. Restacking stops at the first failure or conflict.
+func (m *Manager) Restack(targets []*StackEntry) []RestackResult {
+	var results []RestackResult
+	for _, entry := range targets {
+		result := m.restackSession(entry)
+		results = append(results, result)
+		if result.Err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// restackSession rebases a single stacked session onto its parent's tip
+func (m *Manager) restackSession(entry *StackEntry) RestackResult {
+	s := entry.Session
+	result := RestackResult{Session: s.Name, Branch: s.Branch, Path: s.Path, Parent: entry.Parent}
+
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		result.Err = fmt.Errorf("no stack metadata for %s", s.Name)
+		return result
+	}
+
+	tip, err := git.ResolveRef(m.repoPath, entry.Parent)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	base := meta.ParentBase
+	if base == "" {
+		if base, err = git.MergeBase(m.repoPath, entry.Parent, s.Branch); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	result.Base = base
+	if base == tip {
+		result.UpToDate = true
+		return result
+	}
+	if git.IsAncestor(m.repoPath, tip, s.Branch) {
+		// Already rebased by hand, e.g. after resolving a conflict
+		result.UpToDate = true
+		result.Err = m.metadata.Update(meta.Name, func(md *Metadata) { md.ParentBase = tip })
+		return result
+	}
+
+	if git.HasUncommittedChanges(s.Path) {
+		result.Err = fmt.Errorf("%s: %w", s.Name, errors.ErrUncommittedChanges)
+		return result
+	}
+
+	if _, _, err := m.rebaseManager(s.Path).RebaseOnto(entry.Parent, base); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Err = m.metadata.Update(meta.Name, func(md *Metadata) { md.ParentBase = tip })
+	return result
+}
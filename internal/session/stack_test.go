@@ -0,0 +1,118 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git %v failed: %v, output: %s", args, err, output)
+	}
+}
+
+func commitFile(t *testing.T, dir, name, content string, extra ...string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, append([]string{"commit", "-m", "change " + name}, extra...)...)
+}
+
+func TestRestackAfterParentAmend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("parent"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	parent := findByName(sessions, "parent")
+	if parent == nil {
+		t.Fatalf("parent session not found in %+v", sessions)
+	}
+	commitFile(t, parent.Path, "parent.txt", "v1\n")
+
+	child, err := manager.CreateStackedSession("child", *parent)
+	if err != nil {
+		t.Fatalf("CreateStackedSession() failed: %v", err)
+	}
+	commitFile(t, child.Path, "child.txt", "child\n")
+
+	stacks, err := manager.Stacks()
+	if err != nil || len(stacks) != 1 || len(stacks[0].Children) != 1 {
+		t.Fatalf("Stacks() = %+v, %v; expected parent with one child", stacks, err)
+	}
+	if stacks[0].Children[0].NeedsRestack {
+		t.Error("child should not need a restack before the parent changes")
+	}
+
+	// Rewrite the parent's commit so the child is based on a stale commit
+	commitFile(t, parent.Path, "parent.txt", "v2\n", "--amend")
+
+	stacks, _ = manager.Stacks()
+	if !stacks[0].Children[0].NeedsRestack {
+		t.Error("child should need a restack after the parent was amended")
+	}
+
+	targets, err := manager.RestackTargets("")
+	if err != nil || len(targets) != 1 {
+		t.Fatalf("RestackTargets() = %+v, %v; expected the child", targets, err)
+	}
+	results := manager.Restack(targets)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Restack() = %+v", results)
+	}
+
+	if !git.IsAncestor(repo, parent.Branch, child.Branch) {
+		t.Error("child should be based on the amended parent")
+	}
+	if n, _ := git.CountCommits(repo, parent.Branch+".."+child.Branch); n != 1 {
+		t.Errorf("child has %d commits on top of parent, expected 1", n)
+	}
+
+	targets, _ = manager.RestackTargets("parent")
+	results = manager.Restack(targets)
+	if len(results) != 1 || !results[0].UpToDate {
+		t.Errorf("second Restack() = %+v, expected child up to date", results)
+	}
+
+	// Rebasing by hand, as after resolving a conflict, counts as restacked
+	commitFile(t, parent.Path, "parent.txt", "v3\n", "--amend")
+	runGit(t, child.Path, "rebase", "--onto", parent.Branch, results[0].Base)
+
+	targets, _ = manager.RestackTargets("")
+	results = manager.Restack(targets)
+	if len(results) != 1 || !results[0].UpToDate || results[0].Err != nil {
+		t.Errorf("Restack() after manual rebase = %+v, expected child up to date", results)
+	}
+	if stacks, _ = manager.Stacks(); stacks[0].Children[0].NeedsRestack {
+		t.Error("child should not need a restack after it was rebased by hand")
+	}
+}
+
+func findByName(sessions []git.SessionInfo, name string) *git.SessionInfo {
+	for i := range sessions {
+		if sessions[i].Name == name {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
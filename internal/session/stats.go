@@ -0,0 +1,83 @@
+package session
+
+import (
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// Stats aggregates the sessions, compared to base, and the rebases recorded
+// in the operation history since the given time, or all of them if it is
+// zero
+func (m *Manager) Stats(base string, since time.Time) (*schema.Stats, error) {
+	status, err := m.Status(base)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := m.Operations(since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &schema.Stats{
+		Header:   schema.NewHeader(),
+		Repo:     status.Repo,
+		Base:     base,
+		Sessions: len(status.Sessions),
+	}
+	if !since.IsZero() {
+		stats.Since = &since
+	}
+
+	now := time.Now()
+	var totalAge time.Duration
+	var aged int
+	paths := make([]string, len(status.Sessions))
+	for i, s := range status.Sessions {
+		paths[i] = s.Path
+		stats.Commits += s.Ahead
+		if s.CreatedAt != nil {
+			totalAge += now.Sub(*s.CreatedAt)
+			aged++
+		}
+	}
+	if aged > 0 {
+		stats.AverageAgeSeconds = (totalAge / time.Duration(aged)).Seconds()
+	}
+	if stats.Sessions > 0 {
+		stats.CommitsPerSession = float64(stats.Commits) / float64(stats.Sessions)
+	}
+	for _, size := range utils.DirSizes(paths) {
+		stats.DiskBytes += size
+	}
+
+	stats.Rebases = rebaseStats(ops)
+	return stats, nil
+}
+
+// rebaseStats counts the rebases among ops by result
+func rebaseStats(ops []schema.Operation) schema.RebaseStats {
+	var r schema.RebaseStats
+	for _, op := range ops {
+		if op.Op != schema.OpRebase {
+			continue
+		}
+		r.Total++
+		switch op.Result {
+		case schema.ResultSucceeded:
+			r.Succeeded++
+		case schema.ResultConflicted:
+			r.Conflicted++
+		case schema.ResultInterrupted:
+			r.Interrupted++
+		default:
+			r.Failed++
+		}
+	}
+	if r.Total > 0 {
+		r.SuccessRate = float64(r.Succeeded) / float64(r.Total)
+		r.ConflictRate = float64(r.Conflicted) / float64(r.Total)
+	}
+	return r
+}
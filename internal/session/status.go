@@ -0,0 +1,73 @@
+package session
+
+import (
+	"time"
+
+	"github.com/ksred/ccswitch/internal/config"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+// Status reports the state of every session relative to the base branch
+func (m *Manager) Status(base string) (*schema.Status, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &schema.Status{
+		Header:   schema.NewHeader(),
+		Repo:     m.repoName,
+		Base:     base,
+		Sessions: make([]schema.StatusSession, 0, len(sessions)),
+	}
+
+	heartbeats := m.Heartbeats()
+	lastUsed := m.LastUsed()
+	now := time.Now()
+
+	for _, s := range sessions {
+		entry := schema.StatusSession{Name: s.Name, Branch: s.Branch, Detached: s.Detached(), Path: s.Path}
+
+		if status, err := git.CachedWorktreeStatus(s.Path, base); err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Dirty, entry.Ahead, entry.Behind = status.Dirty, status.Ahead, status.Behind
+		}
+
+		if meta, err := m.metadata.FindByPath(s.Path); err == nil && meta != nil {
+			if !meta.CreatedAt.IsZero() {
+				created := meta.CreatedAt
+				entry.CreatedAt = &created
+			}
+			entry.Ref = meta.Ref
+			entry.Issue, entry.IssueURL = meta.Issue, m.issueURL(meta)
+			entry.Tags = meta.Tags
+			entry.Notes = meta.Notes
+			entry.Pinned = meta.Pinned
+			if meta.ExpiresAt != nil {
+				expires := *meta.ExpiresAt
+				entry.ExpiresAt, entry.Expired = &expires, !now.Before(expires)
+			}
+			if meta.Identity != "" {
+				var identity config.Identity
+				entry.Identity, identity = m.Identity(s)
+				entry.UserName, entry.UserEmail = identity.Name, identity.Email
+			}
+		}
+		if last, err := git.GetLastCommitTime(s.Path, "HEAD"); err == nil {
+			entry.LastActive = &last
+		}
+
+		if used, ok := lastUsed[s.Path]; ok {
+			entry.LastUsed = &used
+		}
+
+		if h, ok := heartbeats[s.Path]; ok {
+			entry.Activity = &schema.Activity{Status: h.Status, UpdatedAt: h.At, Quiet: h.Quiet(now)}
+		}
+
+		doc.Sessions = append(doc.Sessions, entry)
+	}
+	return doc, nil
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// SetTags labels sessions created from now on with tags, e.g. "frontend"
+func (m *Manager) SetTags(tags []string) error {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	m.tags = normalized
+	return nil
+}
+
+// Tags returns the tags of s, sorted
+func (m *Manager) Tags(s git.SessionInfo) []string {
+	meta, err := m.metadata.FindByPath(s.Path)
+	if err != nil || meta == nil {
+		return nil
+	}
+	return meta.Tags
+}
+
+// TagSession adds the tags in add to s and takes those in remove off it,
+// returning the tags it ends up with
+func (m *Manager) TagSession(s git.SessionInfo, add, remove []string) ([]string, error) {
+	add, err := normalizeTags(add)
+	if err != nil {
+		return nil, err
+	}
+	remove, err = normalizeTags(remove)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = m.updateMetadata(s, func(meta *Metadata) {
+		meta.Tags = slices.DeleteFunc(append(meta.Tags, add...), func(tag string) bool {
+			return slices.Contains(remove, tag)
+		})
+		slices.Sort(meta.Tags)
+		meta.Tags = slices.Compact(meta.Tags)
+		if len(meta.Tags) == 0 {
+			meta.Tags = nil
+		}
+		tags = meta.Tags
+	})
+	return tags, err
+}
+
+// normalizeTags trims, sorts and dedupes tags, rejecting those that are
+// empty or contain whitespace or commas
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || strings.ContainsAny(tag, " \t\n,") {
+			return nil, fmt.Errorf("invalid tag %q: tags are single words", tag)
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
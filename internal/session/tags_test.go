@@ -0,0 +1,68 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.SetTags([]string{"bad tag"}); err == nil {
+		t.Error("SetTags() accepted a tag with a space")
+	}
+	if err := manager.SetTags([]string{"urgent", "frontend", "urgent"}); err != nil {
+		t.Fatalf("SetTags() failed: %v", err)
+	}
+	if err := manager.CreateSession("feature"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	feature := findByName(sessions, "feature")
+	main := findByName(sessions, "main")
+	if feature == nil || main == nil {
+		t.Fatalf("sessions not found in %+v", sessions)
+	}
+
+	if got, expected := manager.Tags(*feature), []string{"frontend", "urgent"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tags(feature) = %v, expected %v", got, expected)
+	}
+
+	tags, err := manager.TagSession(*feature, []string{"api"}, []string{"urgent"})
+	if err != nil {
+		t.Fatalf("TagSession(feature) failed: %v", err)
+	}
+	if expected := []string{"api", "frontend"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("TagSession(feature) = %v, expected %v", tags, expected)
+	}
+
+	// The main repository has no metadata until it is tagged
+	if _, err := manager.TagSession(*main, []string{"release"}, nil); err != nil {
+		t.Fatalf("TagSession(main) failed: %v", err)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	got := map[string][]string{}
+	for _, s := range doc.Sessions {
+		got[s.Name] = s.Tags
+	}
+	expected := map[string][]string{"feature": {"api", "frontend"}, "main": {"release"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Status() tags = %v, expected %v", got, expected)
+	}
+}
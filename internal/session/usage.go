@@ -0,0 +1,88 @@
+package session
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// MarkUsed records that s was just switched to or had a command run in it
+func (m *Manager) MarkUsed(s git.SessionInfo) error {
+	now := time.Now()
+	return m.updateMetadata(s, func(meta *Metadata) {
+		meta.LastUsed = &now
+	})
+}
+
+// updateMetadata applies fn to the metadata of s. Sessions created outside
+// ccswitch, such as the main repository, get an entry the first time. The
+// entry is found and changed under the metadata store's lock, so commands
+// run in parallel, such as exec from several agents, keep each other's
+// changes.
+func (m *Manager) updateMetadata(s git.SessionInfo, fn func(meta *Metadata)) error {
+	return m.metadata.modify(func(file *metadataFile) error {
+		fn(file.entryFor(s))
+		return nil
+	})
+}
+
+// LastUsed returns when each session was last used, keyed by worktree path.
+// Sessions that were never used are missing.
+func (m *Manager) LastUsed() map[string]time.Time {
+	used := make(map[string]time.Time)
+	entries, err := m.metadata.All()
+	if err != nil {
+		return used
+	}
+	for _, entry := range entries {
+		if entry.LastUsed != nil {
+			used[entry.Path] = *entry.LastUsed
+		}
+	}
+	return used
+}
+
+// RecentSession is a session with when it was last used
+type RecentSession struct {
+	git.SessionInfo
+	LastUsed time.Time
+}
+
+// RecentSessions returns the sessions that were used, most recently used
+// first
+func (m *Manager) RecentSessions() ([]RecentSession, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	used := m.LastUsed()
+
+	var recent []RecentSession
+	for _, s := range sessions {
+		if t, ok := used[s.Path]; ok {
+			recent = append(recent, RecentSession{SessionInfo: s, LastUsed: t})
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].LastUsed.After(recent[j].LastUsed)
+	})
+	return recent, nil
+}
+
+// PreviousSession returns the most recently used session other than the one
+// containing dir, like cd - for sessions, or nil if there is none
+func (m *Manager) PreviousSession(dir string) (*git.SessionInfo, error) {
+	recent, err := m.RecentSessions()
+	if err != nil {
+		return nil, err
+	}
+	current := git.FindEnclosingRepository(dir)
+	for _, r := range recent {
+		if r.Path != current {
+			s := r.SessionInfo
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
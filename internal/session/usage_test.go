@@ -0,0 +1,149 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestMarkUsed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("feature"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	sessions, _ := manager.ListSessions()
+	feature := findByName(sessions, "feature")
+	main := findByName(sessions, "main")
+	if feature == nil || main == nil {
+		t.Fatalf("sessions not found in %+v", sessions)
+	}
+
+	if len(manager.LastUsed()) != 0 {
+		t.Errorf("LastUsed() before any use = %v, expected none", manager.LastUsed())
+	}
+
+	if err := manager.MarkUsed(*feature); err != nil {
+		t.Fatalf("MarkUsed(feature) failed: %v", err)
+	}
+	// The main repository has no metadata until it is used
+	if err := manager.MarkUsed(*main); err != nil {
+		t.Fatalf("MarkUsed(main) failed: %v", err)
+	}
+
+	used := manager.LastUsed()
+	if len(used) != 2 {
+		t.Fatalf("LastUsed() = %v, expected both sessions", used)
+	}
+	if !used[main.Path].After(used[feature.Path]) {
+		t.Errorf("main was used after feature, got %v", used)
+	}
+
+	meta, _ := manager.metadata.FindByPath(feature.Path)
+	if meta == nil || meta.Branch != feature.Branch || meta.BaseBranch != "main" {
+		t.Errorf("MarkUsed() should keep the existing metadata, got %+v", meta)
+	}
+
+	doc, err := manager.Status("main")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	for _, s := range doc.Sessions {
+		if s.LastUsed == nil {
+			t.Errorf("Status() should report when %s was last used", s.Name)
+		}
+	}
+}
+
+func TestPreviousSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	for _, name := range []string{"one", "two"} {
+		if err := manager.CreateSession(name); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", name, err)
+		}
+	}
+	sessions, _ := manager.ListSessions()
+	one, two := findByName(sessions, "one"), findByName(sessions, "two")
+
+	if previous, err := manager.PreviousSession(repo); err != nil || previous != nil {
+		t.Fatalf("PreviousSession() before any use = %v, %v; expected none", previous, err)
+	}
+
+	_ = manager.MarkUsed(*one)
+	_ = manager.MarkUsed(*two)
+
+	recent, err := manager.RecentSessions()
+	if err != nil {
+		t.Fatalf("RecentSessions() failed: %v", err)
+	}
+	if len(recent) != 2 || recent[0].Name != "two" || recent[1].Name != "one" {
+		t.Errorf("RecentSessions() = %+v, expected two then one", recent)
+	}
+
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		// From the last session used, go back to the one before it
+		{filepath.Join(two.Path, "sub"), "one"},
+		{one.Path, "two"},
+		{repo, "two"},
+	}
+	for _, tt := range tests {
+		previous, err := manager.PreviousSession(tt.dir)
+		if err != nil || previous == nil || previous.Name != tt.expected {
+			t.Errorf("PreviousSession(%s) = %v, %v; expected %s", tt.dir, previous, err, tt.expected)
+		}
+	}
+}
+
+func TestMarkUsedConcurrently(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	manager := NewManager(filepath.Join(t.TempDir(), "project"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, name := range []string{"auth", "billing"} {
+			wg.Add(1)
+			go func(s git.SessionInfo) {
+				defer wg.Done()
+				if err := manager.MarkUsed(s); err != nil {
+					t.Errorf("MarkUsed(%s) failed: %v", s.Name, err)
+				}
+			}(git.SessionInfo{Name: name, Branch: "feature/" + name, Path: "/work/" + name})
+		}
+	}
+	wg.Wait()
+
+	entries, err := manager.metadata.All()
+	if err != nil {
+		t.Fatalf("All() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].LastUsed == nil || entries[1].LastUsed == nil {
+		t.Errorf("entries after concurrent MarkUsed = %+v, expected both sessions marked used", entries)
+	}
+}
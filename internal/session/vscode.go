@@ -0,0 +1,117 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// workspaceFolder is a folder of a VS Code multi-root workspace
+type workspaceFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// workspaceRecordPath returns the file recording where the repository's VS
+// Code workspace is kept
+func workspaceRecordPath(repoName string) string {
+	return filepath.Join(StateDir(repoName), "vscode.json")
+}
+
+// DefaultWorkspacePath returns where the repository's VS Code workspace is
+// written unless another file is given
+func (m *Manager) DefaultWorkspacePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ccswitch", "workspaces", m.repoName+".code-workspace")
+}
+
+// WorkspacePath returns the VS Code workspace kept up to date for the
+// repository, or an empty string if there is none
+func (m *Manager) WorkspacePath() string {
+	data, err := os.ReadFile(workspaceRecordPath(m.repoName))
+	if err != nil {
+		return ""
+	}
+	var record struct {
+		Path string `json:"path"`
+	}
+	if json.Unmarshal(data, &record) != nil {
+		return ""
+	}
+	return record.Path
+}
+
+// WriteWorkspace writes a VS Code multi-root workspace with a folder for
+// each session to path, and keeps it up to date as sessions are created,
+// deleted, renamed and moved. Settings and other entries of an existing
+// workspace are kept; only its folders are replaced.
+func (m *Manager) WriteWorkspace(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if err := m.writeWorkspace(path); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]string{"path": path})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(StateDir(m.repoName), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(workspaceRecordPath(m.repoName), data, 0600)
+}
+
+// ForgetWorkspace stops keeping the VS Code workspace up to date and
+// returns its path, leaving the file itself alone
+func (m *Manager) ForgetWorkspace() (string, error) {
+	path := m.WorkspacePath()
+	if path == "" {
+		return "", nil
+	}
+	return path, os.Remove(workspaceRecordPath(m.repoName))
+}
+
+// refreshWorkspace rewrites the VS Code workspace, if one is kept, after
+// the sessions changed. It is best effort, like recording operations.
+func (m *Manager) refreshWorkspace() {
+	if path := m.WorkspacePath(); path != "" {
+		_ = m.writeWorkspace(path)
+	}
+}
+
+func (m *Manager) writeWorkspace(path string) error {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return err
+	}
+
+	workspace := map[string]any{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &workspace); err != nil {
+			return fmt.Errorf("%s is not a workspace ccswitch can update (comments are not supported): %w", path, err)
+		}
+	}
+
+	folders := make([]workspaceFolder, 0, len(sessions))
+	for _, s := range sessions {
+		folders = append(folders, workspaceFolder{Name: s.Name + " (" + s.BranchLabel() + ")", Path: s.Path})
+	}
+	workspace["folders"] = folders
+
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	commitFile(t, repo, "base.txt", "base\n")
+
+	manager := NewManager(repo)
+	if err := manager.CreateSession("first"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+
+	// Settings added by the user survive rewrites
+	path := filepath.Join(t.TempDir(), "project.code-workspace")
+	if err := os.WriteFile(path, []byte(`{"settings": {"editor.tabSize": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.WriteWorkspace(path); err != nil {
+		t.Fatalf("WriteWorkspace() failed: %v", err)
+	}
+	if got := manager.WorkspacePath(); got != path {
+		t.Errorf("WorkspacePath() = %q, expected %q", got, path)
+	}
+
+	type workspace struct {
+		Folders  []workspaceFolder `json:"folders"`
+		Settings map[string]any    `json:"settings"`
+	}
+	read := func() workspace {
+		t.Helper()
+		var w workspace
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read workspace: %v", err)
+		}
+		if err := json.Unmarshal(data, &w); err != nil {
+			t.Fatalf("Invalid workspace %s: %v", data, err)
+		}
+		return w
+	}
+	names := func(w workspace) []string {
+		var names []string
+		for _, f := range w.Folders {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	w := read()
+	if len(w.Folders) != 2 || w.Settings["editor.tabSize"] != float64(2) {
+		t.Errorf("workspace = %+v, expected the main repository, first and the settings", w)
+	}
+
+	// Creating and deleting sessions keep the workspace up to date
+	if err := manager.CreateSession("second"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if got := names(read()); len(got) != 3 {
+		t.Errorf("folders after create = %v, expected 3", got)
+	}
+	sessions, _ := manager.ListSessions()
+	first := findByName(sessions, "first")
+	if err := manager.RemoveSession(first.Path, true, first.Branch); err != nil {
+		t.Fatalf("RemoveSession() failed: %v", err)
+	}
+	got := names(read())
+	if len(got) != 2 || got[1] != "second (feature/second)" {
+		t.Errorf("folders after delete = %v", got)
+	}
+
+	if _, err := manager.ForgetWorkspace(); err != nil {
+		t.Fatalf("ForgetWorkspace() failed: %v", err)
+	}
+	if err := manager.CreateSession("third"); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if got := names(read()); len(got) != 2 {
+		t.Errorf("folders after forgetting = %v, expected no update", got)
+	}
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/errors"
+)
+
+// ChecklistItem is one entry of a checklist
+type ChecklistItem struct {
+	Label string
+	// Note explains the item, e.g. why it is unchecked
+	Note    string
+	Checked bool
+	// Locked items keep their initial state
+	Locked bool
+}
+
+// Checklist lets the user review items and toggle the ones that are not
+// locked, either interactively or, with noTUI, by entering numbers on stdin.
+// It returns whether each item is checked, or nil without an error if the
+// user quit.
+func Checklist(title string, items []ChecklistItem, noTUI bool) ([]bool, error) {
+	if noTUI {
+		return checklistNumbered(title, items, os.Stdin)
+	}
+	if !Interactive() {
+		return nil, errors.ErrNotInteractive
+	}
+
+	list := newChecklist(title, items)
+	if _, err := tea.NewProgram(list).Run(); err != nil {
+		return nil, fmt.Errorf("failed to run checklist: %w", err)
+	}
+	if list.quit {
+		return nil, nil
+	}
+	return list.checked, nil
+}
+
+// checklist is the interactive form of Checklist
+type checklist struct {
+	title   string
+	items   []ChecklistItem
+	checked []bool
+	cursor  int
+	done    bool
+	quit    bool
+}
+
+func newChecklist(title string, items []ChecklistItem) *checklist {
+	checked := make([]bool, len(items))
+	for i, item := range items {
+		checked[i] = item.Checked
+	}
+	return &checklist{title: title, items: items, checked: checked}
+}
+
+func (c *checklist) Init() tea.Cmd {
+	return nil
+}
+
+func (c *checklist) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("ctrl+c", "esc", "q"))):
+		c.quit = true
+		return c, tea.Quit
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("up", "k", "ctrl+p"))):
+		if c.cursor > 0 {
+			c.cursor--
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("down", "j", "ctrl+n"))):
+		if c.cursor < len(c.items)-1 {
+			c.cursor++
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys(" "))):
+		if !c.items[c.cursor].Locked {
+			c.checked[c.cursor] = !c.checked[c.cursor]
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter"))):
+		c.done = true
+		return c, tea.Quit
+	}
+	return c, nil
+}
+
+func (c *checklist) View() string {
+	if c.quit || c.done {
+		return ""
+	}
+
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	b.WriteString(TitleStyle.Render(c.title))
+	b.WriteString("\n\n")
+
+	for i, item := range c.items {
+		cursor := "  "
+		if c.cursor == i {
+			cursor = "→ "
+		}
+
+		line := fmt.Sprintf("%s%s %s", cursor, checkBox(c.checked[i], item.Locked), item.Label)
+		switch {
+		case c.cursor == i:
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true).Render(line))
+		case item.Locked:
+			b.WriteString(dim.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		if item.Note != "" {
+			b.WriteString(dim.Render("  " + item.Note))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dim.Render("↑/↓: navigate • space: toggle • enter: confirm • esc: quit"))
+
+	return b.String()
+}
+
+// checkBox renders the state of a checklist item
+func checkBox(checked, locked bool) string {
+	switch {
+	case locked && !checked:
+		return "[-]"
+	case checked:
+		return "[x]"
+	default:
+		return "[ ]"
+	}
+}
+
+// checklistNumbered prints the checklist with numbers and reads the numbers
+// of the items to toggle from in
+func checklistNumbered(title string, items []ChecklistItem, in io.Reader) ([]bool, error) {
+	Title(title)
+	fmt.Println()
+
+	checked := make([]bool, len(items))
+	gray := color.New(color.FgHiBlack)
+	for i, item := range items {
+		checked[i] = item.Checked
+		fmt.Printf("  %d. %s %s\n", i+1, checkBox(item.Checked, item.Locked), item.Label)
+		if item.Note != "" {
+			gray.Printf("     %s\n", item.Note)
+		}
+	}
+
+	fmt.Println()
+	gray.Println("Toggle items by number, e.g. 2 or 1,3-4; press Enter to keep the list as shown")
+	fmt.Print("Enter numbers (or q to quit): ")
+
+	input, err := readLine(in)
+	if err != nil {
+		return nil, err
+	}
+
+	input = strings.TrimSpace(input)
+	switch input {
+	case "q":
+		return nil, nil
+	case "":
+		return checked, nil
+	}
+
+	toggled, err := parseNumberList(input, len(items))
+	if err != nil {
+		return nil, err
+	}
+	for i := range toggled {
+		if items[i].Locked {
+			return nil, fmt.Errorf("%s cannot be changed", items[i].Label)
+		}
+		checked[i] = !checked[i]
+	}
+	return checked, nil
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChecklistNumbered(t *testing.T) {
+	items := []ChecklistItem{
+		{Label: "safe", Checked: true},
+		{Label: "ahead"},
+		{Label: "dirty", Locked: true},
+	}
+
+	tests := []struct {
+		input    string
+		expected []bool
+		wantErr  bool
+	}{
+		{"\n", []bool{true, false, false}, false},
+		{"1,2\n", []bool{false, true, false}, false},
+		{"3\n", nil, true},
+		{"q\n", nil, false},
+	}
+
+	for _, tt := range tests {
+		checked, err := checklistNumbered("Review", items, strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("input %q: error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(checked, tt.expected) {
+			t.Errorf("input %q: checked %v, expected %v", tt.input, checked, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// PickCommits lets the user choose any number of commits, either with an
+// interactive checklist or, with noTUI, a numbered list read from stdin. The
+// chosen commits are returned in the order given. It returns nil without an
+// error if the user quit without choosing.
+func PickCommits(commits []git.Commit, title string, noTUI bool) ([]git.Commit, error) {
+	if noTUI {
+		return pickCommitsNumbered(commits, title, os.Stdin)
+	}
+	if !Interactive() {
+		return nil, errors.ErrNotInteractive
+	}
+
+	selector := newCommitSelector(commits, title)
+	if _, err := tea.NewProgram(selector).Run(); err != nil {
+		return nil, fmt.Errorf("failed to run selector: %w", err)
+	}
+	return selector.selected(), nil
+}
+
+// commitLine formats a commit for a list, e.g. "a1b2c3d Fix login (Ann, 2h ago)"
+func commitLine(c git.Commit, now time.Time) string {
+	hash := c.Hash
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	return fmt.Sprintf("%s %s (%s, %s)", hash, c.Subject, c.Author, utils.FormatRelative(c.Date, now))
+}
+
+// commitSelector is a checklist of commits
+type commitSelector struct {
+	title   string
+	commits []git.Commit
+	chosen  map[int]bool
+	cursor  int
+	done    bool
+	quit    bool
+}
+
+func newCommitSelector(commits []git.Commit, title string) *commitSelector {
+	return &commitSelector{title: title, commits: commits, chosen: make(map[int]bool)}
+}
+
+func (s *commitSelector) Init() tea.Cmd {
+	return nil
+}
+
+func (s *commitSelector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return s, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("ctrl+c", "esc", "q"))):
+		s.quit = true
+		return s, tea.Quit
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("up", "k", "ctrl+p"))):
+		if s.cursor > 0 {
+			s.cursor--
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("down", "j", "ctrl+n"))):
+		if s.cursor < len(s.commits)-1 {
+			s.cursor++
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys(" "))):
+		s.chosen[s.cursor] = !s.chosen[s.cursor]
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("a"))):
+		all := len(s.selected()) < len(s.commits)
+		for i := range s.commits {
+			s.chosen[i] = all
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter"))):
+		// Enter without a choice takes the commit under the cursor
+		if len(s.selected()) == 0 && len(s.commits) > 0 {
+			s.chosen[s.cursor] = true
+		}
+		s.done = true
+		return s, tea.Quit
+	}
+	return s, nil
+}
+
+// selected returns the chosen commits in list order, or nil if the user quit
+func (s *commitSelector) selected() []git.Commit {
+	if s.quit {
+		return nil
+	}
+	var commits []git.Commit
+	for i, c := range s.commits {
+		if s.chosen[i] {
+			commits = append(commits, c)
+		}
+	}
+	return commits
+}
+
+func (s *commitSelector) View() string {
+	if s.quit || s.done {
+		return ""
+	}
+
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	now := time.Now()
+
+	b.WriteString(TitleStyle.Render(s.title))
+	b.WriteString("\n\n")
+
+	for i, c := range s.commits {
+		cursor := "  "
+		if s.cursor == i {
+			cursor = "→ "
+		}
+		check := "[ ]"
+		if s.chosen[i] {
+			check = "[x]"
+		}
+
+		line := fmt.Sprintf("%s%s %s", cursor, check, commitLine(c, now))
+		if s.cursor == i {
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true).Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dim.Render("↑/↓: navigate • space: toggle • a: toggle all • enter: pick • esc: quit"))
+
+	return b.String()
+}
+
+// pickCommitsNumbered prints a numbered list of commits and reads the choice
+// from in: numbers and ranges such as "1,3-4", or "all"
+func pickCommitsNumbered(commits []git.Commit, title string, in io.Reader) ([]git.Commit, error) {
+	Title(title)
+	fmt.Println()
+
+	now := time.Now()
+	for i, c := range commits {
+		fmt.Printf("  %d. %s\n", i+1, commitLine(c, now))
+	}
+
+	fmt.Println()
+	color.New(color.FgHiBlack).Println("Pick one or more, e.g. 2 or 1,3-4 or all")
+	fmt.Print("Enter numbers (or q to quit): ")
+
+	input, err := readLine(in)
+	if err != nil {
+		return nil, err
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "q" || input == "" {
+		return nil, nil
+	}
+
+	chosen, err := parseNumberList(input, len(commits))
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []git.Commit
+	for i, c := range commits {
+		if chosen[i] {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// parseNumberList parses 1-based numbers and ranges such as "1,3-4", or
+// "all", into a set of 0-based indexes below n
+func parseNumberList(input string, n int) (map[int]bool, error) {
+	chosen := make(map[int]bool)
+	if input == "all" {
+		for i := 0; i < n; i++ {
+			chosen[i] = true
+		}
+		return chosen, nil
+	}
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		first, err1 := strconv.Atoi(strings.TrimSpace(from))
+		last, err2 := strconv.Atoi(strings.TrimSpace(to))
+		if err1 != nil || err2 != nil || first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("invalid selection: %s", part)
+		}
+		for i := first; i <= last; i++ {
+			chosen[i-1] = true
+		}
+	}
+	return chosen, nil
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestParseNumberList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[int]bool
+		wantErr  bool
+	}{
+		{"2", map[int]bool{1: true}, false},
+		{"1,3", map[int]bool{0: true, 2: true}, false},
+		{"1-3", map[int]bool{0: true, 1: true, 2: true}, false},
+		{" 1 , 2-3 ", map[int]bool{0: true, 1: true, 2: true}, false},
+		{"all", map[int]bool{0: true, 1: true, 2: true}, false},
+		{"4", nil, true},
+		{"0", nil, true},
+		{"3-1", nil, true},
+		{"x", nil, true},
+	}
+
+	for _, tt := range tests {
+		chosen, err := parseNumberList(tt.input, 3)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("input %q: error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(chosen, tt.expected) {
+			t.Errorf("input %q: chosen %v, expected %v", tt.input, chosen, tt.expected)
+		}
+	}
+}
+
+func TestPickCommitsNumbered(t *testing.T) {
+	commits := []git.Commit{{Hash: "ccc", Subject: "third"}, {Hash: "bbb", Subject: "second"}, {Hash: "aaa", Subject: "first"}}
+
+	selected, err := pickCommitsNumbered(commits, "Pick", strings.NewReader("3,1\n"))
+	if err != nil {
+		t.Fatalf("pickCommitsNumbered() failed: %v", err)
+	}
+	if len(selected) != 2 || selected[0].Hash != "ccc" || selected[1].Hash != "aaa" {
+		t.Errorf("pickCommitsNumbered() = %+v, expected ccc and aaa in list order", selected)
+	}
+
+	if selected, err := pickCommitsNumbered(commits, "Pick", strings.NewReader("q\n")); err != nil || selected != nil {
+		t.Errorf("pickCommitsNumbered(q) = %+v, %v, expected nothing", selected, err)
+	}
+}
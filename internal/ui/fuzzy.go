@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore reports whether all characters of pattern appear in text in
+// order (case-insensitive) and scores the match. Higher scores are better:
+// consecutive characters and matches at word boundaries score extra.
+func FuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	score := 0
+	pi := 0
+	prevMatch := -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+
+		score++
+		if ti == prevMatch+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		prevMatch = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+
+	// Prefer shorter candidates when scores are otherwise equal
+	return score*100 - len(t), true
+}
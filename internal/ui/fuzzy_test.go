@@ -0,0 +1,38 @@
+package ui
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		match   bool
+	}{
+		{"", "anything", true},
+		{"auth", "fix-authentication", true},
+		{"fa", "fix-authentication", true},
+		{"FA", "fix-authentication", true},
+		{"xyz", "fix-authentication", false},
+		{"tuaf", "fix-authentication", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := FuzzyScore(tt.pattern, tt.text); ok != tt.match {
+			t.Errorf("FuzzyScore(%q, %q) match = %v, expected %v", tt.pattern, tt.text, ok, tt.match)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	consecutive, _ := FuzzyScore("auth", "auth-service")
+	scattered, _ := FuzzyScore("auth", "a-unit-test-helper")
+	if consecutive <= scattered {
+		t.Errorf("consecutive match scored %d, expected more than scattered match %d", consecutive, scattered)
+	}
+
+	boundary, _ := FuzzyScore("db", "fix-db")
+	inner, _ := FuzzyScore("db", "feedback")
+	if boundary <= inner {
+		t.Errorf("word boundary match scored %d, expected more than inner match %d", boundary, inner)
+	}
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// logChrome is the number of lines the log viewer uses besides commits:
+// title, blank line, blank line, status and help
+const logChrome = 5
+
+// ShowLog shows commits, newest first, in a scrollable view. The commit
+// under the cursor can be copied or opened with 'git show', run in dir.
+func ShowLog(title, dir string, commits []git.Commit) error {
+	viewer := newLogViewer(title, dir, commits)
+	if _, err := tea.NewProgram(viewer, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("failed to run log viewer: %w", err)
+	}
+	return nil
+}
+
+// PrintLog prints commits, newest first, one per line
+func PrintLog(commits []git.Commit) {
+	for _, c := range commits {
+		fmt.Println(logLine(c))
+	}
+}
+
+// logLine formats a commit for the log, e.g.
+// "a1b2c3d  2024-05-01 14:03  Ann  Fix login"
+func logLine(c git.Commit) string {
+	hash := c.Hash
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	return fmt.Sprintf("%s  %s  %s  %s", hash, c.Date.Format("2006-01-02 15:04"), c.Author, c.Subject)
+}
+
+// gitShowDoneMsg reports that git show returned
+type gitShowDoneMsg struct{ err error }
+
+// logViewer is a scrollable list of commits
+type logViewer struct {
+	title   string
+	dir     string
+	commits []git.Commit
+	cursor  int
+	// offset is the index of the first commit shown
+	offset int
+	height int
+	status string
+}
+
+func newLogViewer(title, dir string, commits []git.Commit) *logViewer {
+	return &logViewer{title: title, dir: dir, commits: commits, height: 20}
+}
+
+func (v *logViewer) Init() tea.Cmd {
+	return nil
+}
+
+// rows returns how many commits fit on screen
+func (v *logViewer) rows() int {
+	if v.height-logChrome < 1 {
+		return 1
+	}
+	return v.height - logChrome
+}
+
+// moveTo moves the cursor to i, scrolling to keep it on screen
+func (v *logViewer) moveTo(i int) {
+	if i >= len(v.commits) {
+		i = len(v.commits) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	v.cursor = i
+
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+v.rows() {
+		v.offset = v.cursor - v.rows() + 1
+	}
+}
+
+func (v *logViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.height = msg.Height
+		v.moveTo(v.cursor)
+		return v, nil
+
+	case gitShowDoneMsg:
+		if msg.err != nil {
+			v.status = fmt.Sprintf("git show failed: %v", msg.err)
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		v.status = ""
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c", "esc", "q"))):
+			return v, tea.Quit
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k", "ctrl+p"))):
+			v.moveTo(v.cursor - 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j", "ctrl+n"))):
+			v.moveTo(v.cursor + 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgup", "ctrl+u"))):
+			v.moveTo(v.cursor - v.rows())
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown", "ctrl+d"))):
+			v.moveTo(v.cursor + v.rows())
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("home", "g"))):
+			v.moveTo(0)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("end", "G"))):
+			v.moveTo(len(v.commits) - 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("y", "c"))):
+			if len(v.commits) == 0 {
+				break
+			}
+			hash := v.commits[v.cursor].Hash
+			if err := utils.CopyToClipboard(hash, os.Stdout); err != nil {
+				v.status = fmt.Sprintf("Copy failed: %v", err)
+			} else {
+				v.status = fmt.Sprintf("Copied %s", hash)
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "s"))):
+			if len(v.commits) == 0 {
+				break
+			}
+			show := exec.Command("git", "show", v.commits[v.cursor].Hash)
+			show.Dir = v.dir
+			return v, tea.ExecProcess(show, func(err error) tea.Msg { return gitShowDoneMsg{err} })
+		}
+	}
+	return v, nil
+}
+
+func (v *logViewer) View() string {
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	now := time.Now()
+
+	b.WriteString(TitleStyle.Render(v.title))
+	b.WriteString("\n\n")
+
+	if len(v.commits) == 0 {
+		b.WriteString(dim.Render("  No commits"))
+		b.WriteString("\n")
+	}
+
+	end := v.offset + v.rows()
+	if end > len(v.commits) {
+		end = len(v.commits)
+	}
+	for i := v.offset; i < end; i++ {
+		c := v.commits[i]
+		cursor := "  "
+		if v.cursor == i {
+			cursor = "→ "
+		}
+		line := cursor + logLine(c)
+		if v.cursor == i {
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true).Render(line))
+			b.WriteString(dim.Render("  " + utils.FormatRelative(c.Date, now)))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if v.status != "" {
+		b.WriteString(InfoStyle.Render(v.status))
+	} else if len(v.commits) > 0 {
+		b.WriteString(dim.Render(fmt.Sprintf("%d of %d", v.cursor+1, len(v.commits))))
+	}
+	b.WriteString("\n")
+	b.WriteString(dim.Render("↑/↓/pgup/pgdn: scroll • enter: git show • y: copy SHA • q: quit"))
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestLogViewerScrolling(t *testing.T) {
+	commits := make([]git.Commit, 30)
+	for i := range commits {
+		commits[i] = git.Commit{Hash: fmt.Sprintf("%040d", i), Author: "Ann", Subject: fmt.Sprintf("commit %d", i)}
+	}
+
+	v := newLogViewer("Log", "", commits)
+	v.Update(tea.WindowSizeMsg{Width: 80, Height: logChrome + 10})
+
+	for i := 0; i < 12; i++ {
+		v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if v.cursor != 12 || v.offset != 3 {
+		t.Errorf("after 12 downs cursor = %d, offset = %d; expected 12 and 3", v.cursor, v.offset)
+	}
+
+	v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if v.cursor != 29 || v.offset != 20 {
+		t.Errorf("after G cursor = %d, offset = %d; expected 29 and 20", v.cursor, v.offset)
+	}
+
+	v.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if v.cursor != 19 || v.offset != 19 {
+		t.Errorf("after pgup cursor = %d, offset = %d; expected 19 and 19", v.cursor, v.offset)
+	}
+
+	view := v.View()
+	if !strings.Contains(view, "commit 19") || strings.Contains(view, "commit 18") || strings.Contains(view, "commit 29") {
+		t.Errorf("View() should show commits 19 to 28:\n%s", view)
+	}
+}
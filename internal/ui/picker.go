@@ -0,0 +1,367 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// PickOptions configures PickSession
+type PickOptions struct {
+	// Title is shown above the list of sessions
+	Title string
+	// BaseBranch enables status glyphs relative to this branch
+	BaseBranch string
+	// Activity returns what each session's agent is doing, keyed by worktree
+	// path. The interactive selector polls it to show activity live.
+	Activity func() map[string]string
+	// LastUsed is when each session was last used, keyed by worktree path.
+	// When set, sessions are listed most recently used first.
+	LastUsed map[string]time.Time
+	// Issues is the task each session works on, keyed by worktree path
+	Issues map[string]string
+	// Notes are the notes of each session, keyed by worktree path, shown
+	// for the session under the cursor
+	Notes map[string]string
+	// Pinned marks the pinned sessions, keyed by worktree path
+	Pinned map[string]bool
+	// Expired marks the sessions whose TTL ran out, keyed by worktree path
+	Expired map[string]bool
+	// Action names what is done to the sessions PickSessions returns, e.g.
+	// "Remove", on the summary the user confirms them on
+	Action string
+	// Checked are the sessions PickSessions starts with checked, keyed by
+	// worktree path
+	Checked map[string]bool
+	// Disabled are the sessions PickSessions won't let be checked, with the
+	// reason, keyed by worktree path
+	Disabled map[string]string
+	// Warnings are shown next to sessions in PickSessions, keyed by
+	// worktree path
+	Warnings map[string]string
+	// NoTUI replaces the interactive selector with a numbered list read from
+	// stdin, for terminals and scripts where bubbletea does not work
+	NoTUI bool
+}
+
+// PickSession lets the user pick one of sessions, either with the interactive
+// selector or with a numbered list. It returns nil without an error if the
+// user quit without picking.
+func PickSession(sessions []git.SessionInfo, opts PickOptions) (*git.SessionInfo, error) {
+	if opts.NoTUI {
+		return pickNumbered(sessions, opts, os.Stdin)
+	}
+	if !Interactive() {
+		return nil, errors.ErrNotInteractive
+	}
+
+	selector := NewSessionSelector(sessions)
+	if opts.Title != "" {
+		selector.WithTitle(opts.Title)
+	}
+	if opts.BaseBranch != "" {
+		selector.WithStatus(opts.BaseBranch)
+	}
+	if opts.Activity != nil {
+		selector.WithActivity(opts.Activity)
+	}
+	if opts.LastUsed != nil {
+		selector.WithLastUsed(opts.LastUsed)
+	}
+	if opts.Issues != nil {
+		selector.WithIssues(opts.Issues)
+	}
+	if opts.Notes != nil {
+		selector.WithNotes(opts.Notes)
+	}
+	if opts.Pinned != nil {
+		selector.WithPinned(opts.Pinned)
+	}
+	if opts.Expired != nil {
+		selector.WithExpired(opts.Expired)
+	}
+
+	if _, err := tea.NewProgram(selector).Run(); err != nil {
+		return nil, fmt.Errorf("failed to run selector: %w", err)
+	}
+	if selector.IsQuit() {
+		return nil, nil
+	}
+	return selector.GetSelected(), nil
+}
+
+// pickNumbered prints a numbered list of sessions and reads the choice from in
+func pickNumbered(sessions []git.SessionInfo, opts PickOptions, in io.Reader) (*git.SessionInfo, error) {
+	title := opts.Title
+	if title == "" {
+		title = defaultSelectorTitle
+	}
+	Title(title)
+	fmt.Println()
+
+	var activities map[string]string
+	if opts.Activity != nil {
+		activities = opts.Activity()
+	}
+
+	if opts.LastUsed != nil {
+		sessions = append([]git.SessionInfo(nil), sessions...)
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return opts.LastUsed[sessions[i].Path].After(opts.LastUsed[sessions[j].Path])
+		})
+	}
+
+	now := time.Now()
+	gray := color.New(color.FgHiBlack)
+	for i, session := range sessions {
+		line := fmt.Sprintf("  %d. %s (%s)", i+1, session.Name, session.BranchLabel())
+		if opts.BaseBranch != "" {
+			glyphs := "?"
+			if st, err := git.CachedWorktreeStatus(session.Path, opts.BaseBranch); err == nil {
+				glyphs = StatusGlyphs(st)
+			}
+			line = fmt.Sprintf("  %d. %-8s %s (%s)", i+1, glyphs, session.Name, session.BranchLabel())
+		}
+		if opts.Pinned[session.Path] {
+			line += " 📌"
+		}
+		if opts.Expired[session.Path] {
+			line += " ⌛ expired"
+		}
+		fmt.Println(line)
+		gray.Printf("     Path: %s\n", session.Path)
+		if used, ok := opts.LastUsed[session.Path]; ok {
+			gray.Printf("     Last used: %s\n", utils.FormatRelative(used, now))
+		}
+		if issue := opts.Issues[session.Path]; issue != "" {
+			gray.Printf("     Issue: %s\n", issue)
+		}
+		if activity := activities[session.Path]; activity != "" {
+			gray.Printf("     Activity: %s\n", activity)
+		}
+		if notes := opts.Notes[session.Path]; notes != "" {
+			gray.Printf("     Notes: %s\n", strings.ReplaceAll(notes, "\n", " · "))
+		}
+	}
+
+	fmt.Println()
+	fmt.Print("Enter number (or q to quit): ")
+
+	input, err := readLine(in)
+	if err != nil {
+		return nil, err
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "q" || input == "" {
+		return nil, nil
+	}
+
+	var choice int
+	if _, err := fmt.Sscanf(input, "%d", &choice); err != nil || choice < 1 || choice > len(sessions) {
+		return nil, fmt.Errorf("invalid selection: %s", input)
+	}
+
+	return &sessions[choice-1], nil
+}
+
+// PickSessions lets the user check any number of sessions, either with the
+// interactive selector or with a numbered list, then confirm them on a
+// summary. It returns nil without an error if the user quit or declined.
+func PickSessions(sessions []git.SessionInfo, opts PickOptions) ([]git.SessionInfo, error) {
+	if opts.NoTUI {
+		return pickSessionsNumbered(sessions, opts, os.Stdin)
+	}
+	if !Interactive() {
+		return nil, errors.ErrNotInteractive
+	}
+
+	selector := NewSessionSelector(sessions).WithMulti(pickAction(opts), opts.Checked, opts.Disabled, opts.Warnings)
+	if opts.Title != "" {
+		selector.WithTitle(opts.Title)
+	}
+	if opts.BaseBranch != "" {
+		selector.WithStatus(opts.BaseBranch)
+	}
+	if opts.LastUsed != nil {
+		selector.WithLastUsed(opts.LastUsed)
+	}
+	if opts.Issues != nil {
+		selector.WithIssues(opts.Issues)
+	}
+	if opts.Notes != nil {
+		selector.WithNotes(opts.Notes)
+	}
+	if opts.Pinned != nil {
+		selector.WithPinned(opts.Pinned)
+	}
+	if opts.Expired != nil {
+		selector.WithExpired(opts.Expired)
+	}
+
+	if _, err := tea.NewProgram(selector).Run(); err != nil {
+		return nil, fmt.Errorf("failed to run selector: %w", err)
+	}
+	if selector.IsQuit() {
+		return nil, nil
+	}
+	return selector.GetChecked(), nil
+}
+
+// pickSessionsNumbered prints a numbered list of sessions, reads the ones to
+// toggle from in, and asks on in to confirm the summary
+func pickSessionsNumbered(sessions []git.SessionInfo, opts PickOptions, in io.Reader) ([]git.SessionInfo, error) {
+	title := opts.Title
+	if title == "" {
+		title = defaultSelectorTitle
+	}
+	Title(title)
+	fmt.Println()
+
+	checked := make([]bool, len(sessions))
+	gray := color.New(color.FgHiBlack)
+	for i, session := range sessions {
+		reason := opts.Disabled[session.Path]
+		checked[i] = opts.Checked[session.Path] && reason == ""
+		fmt.Printf("  %d. %s %s (%s)\n", i+1, checkBox(checked[i], reason != ""), session.Name, session.BranchLabel())
+		if note := reason + opts.Warnings[session.Path]; note != "" {
+			gray.Printf("     %s\n", note)
+		}
+	}
+
+	fmt.Println()
+	gray.Println("Toggle sessions by number, e.g. 2 or 1,3-4; press Enter to keep the list as shown")
+	fmt.Print("Enter numbers (or q to quit): ")
+
+	input, err := readLine(in)
+	if err != nil {
+		return nil, err
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "q" {
+		return nil, nil
+	}
+	if input != "" {
+		toggled, err := parseNumberList(input, len(sessions))
+		if err != nil {
+			return nil, err
+		}
+		for i := range toggled {
+			if reason := opts.Disabled[sessions[i].Path]; reason != "" {
+				return nil, fmt.Errorf("%s cannot be selected: %s", sessions[i].Name, reason)
+			}
+			checked[i] = !checked[i]
+		}
+	}
+
+	var selected []git.SessionInfo
+	for i, c := range checked {
+		if c {
+			selected = append(selected, sessions[i])
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	fmt.Println()
+	Title(fmt.Sprintf("%s %d session(s)?", pickAction(opts), len(selected)))
+	for _, session := range selected {
+		fmt.Printf("  • %s (%s)\n", session.Name, session.BranchLabel())
+		if warning := opts.Warnings[session.Path]; warning != "" {
+			gray.Printf("    %s\n", warning)
+		}
+	}
+	fmt.Println()
+	fmt.Print("Proceed? (y/N): ")
+
+	answer, err := readLine(in)
+	if err != nil {
+		return nil, err
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return nil, nil
+	}
+	return selected, nil
+}
+
+// pickAction returns the action of opts, for the summary of PickSessions
+func pickAction(opts PickOptions) string {
+	if opts.Action == "" {
+		return "Select"
+	}
+	return opts.Action
+}
+
+// readLine reads a single line from in without buffering past the newline,
+// so later prompts reading the same stdin still see their input
+func readLine(in io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// PickBranch lets the user pick one of branches from a numbered list read
+// from stdin, by number or by typing the branch name. It returns an empty
+// string without an error if the user quit without picking.
+func PickBranch(branches []string, title string) (string, error) {
+	return pickBranchNumbered(branches, title, os.Stdin)
+}
+
+// pickBranchNumbered prints a numbered list of branches and reads the
+// choice from in
+func pickBranchNumbered(branches []string, title string, in io.Reader) (string, error) {
+	Title(title)
+	fmt.Println()
+	for i, branch := range branches {
+		fmt.Printf("  %d. %s\n", i+1, branch)
+	}
+
+	fmt.Println()
+	fmt.Print("Enter number or branch name (or q to quit): ")
+
+	input, err := readLine(in)
+	if err != nil {
+		return "", err
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "q" || input == "" {
+		return "", nil
+	}
+	for _, branch := range branches {
+		if branch == input {
+			return branch, nil
+		}
+	}
+
+	var choice int
+	if _, err := fmt.Sscanf(input, "%d", &choice); err != nil || choice < 1 || choice > len(branches) {
+		return "", fmt.Errorf("invalid selection: %s", input)
+	}
+	return branches[choice-1], nil
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestPickNumbered(t *testing.T) {
+	sessions := []git.SessionInfo{
+		{Name: "one", Branch: "feature/one", Path: "/tmp/one"},
+		{Name: "two", Branch: "feature/two", Path: "/tmp/two"},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"2\n", "two", false},
+		{"1", "one", false},
+		{"q\n", "", false},
+		{"\n", "", false},
+		{"3\n", "", true},
+		{"abc\n", "", true},
+	}
+
+	for _, tt := range tests {
+		selected, err := pickNumbered(sessions, PickOptions{}, strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("input %q: error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+
+		name := ""
+		if selected != nil {
+			name = selected.Name
+		}
+		if name != tt.expected {
+			t.Errorf("input %q: selected %q, expected %q", tt.input, name, tt.expected)
+		}
+	}
+}
+
+func TestPickBranchNumbered(t *testing.T) {
+	branches := []string{"main", "develop", "origin/release"}
+
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"2\n", "develop", false},
+		{"origin/release\n", "origin/release", false},
+		{"q\n", "", false},
+		{"4\n", "", true},
+		{"feature\n", "", true},
+	}
+
+	for _, tt := range tests {
+		selected, err := pickBranchNumbered(branches, "Base branch:", strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr || selected != tt.expected {
+			t.Errorf("input %q: selected %q, %v; expected %q, error %v", tt.input, selected, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestPickSessionsNumbered(t *testing.T) {
+	sessions := []git.SessionInfo{
+		{Name: "one", Branch: "feature/one", Path: "/tmp/one"},
+		{Name: "two", Branch: "feature/two", Path: "/tmp/two"},
+		{Name: "dirty", Branch: "feature/dirty", Path: "/tmp/dirty"},
+	}
+	opts := PickOptions{
+		Action:   "Remove",
+		Checked:  map[string]bool{"/tmp/one": true},
+		Disabled: map[string]string{"/tmp/dirty": "uncommitted changes"},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"\ny\n", "one", false},
+		{"2\ny\n", "one,two", false},
+		{"1-2\nyes\n", "two", false},
+		{"2\nn\n", "", false},
+		{"2\n\n", "", false},
+		{"1\n", "", false},
+		{"q\n", "", false},
+		{"3\ny\n", "", true},
+		{"4\n", "", true},
+	}
+
+	for _, tt := range tests {
+		selected, err := pickSessionsNumbered(sessions, opts, strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("input %q: error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+
+		if got := names(selected); got != tt.expected {
+			t.Errorf("input %q: selected %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSessionSelectorMulti(t *testing.T) {
+	sessions := []git.SessionInfo{
+		{Name: "one", Branch: "feature/one", Path: "/tmp/one"},
+		{Name: "two", Branch: "feature/two", Path: "/tmp/two"},
+		{Name: "dirty", Branch: "feature/dirty", Path: "/tmp/dirty"},
+	}
+	press := func(s *SessionSelector, keys ...tea.KeyMsg) {
+		for _, k := range keys {
+			s.Update(k)
+		}
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	disabled := map[string]string{"/tmp/dirty": "uncommitted changes"}
+
+	// Space toggles instead of filtering, and disabled sessions stay unchecked
+	s := NewSessionSelector(sessions).WithMulti("Remove", nil, disabled, nil)
+	press(s, space, down, down, space, tea.KeyMsg{Type: tea.KeyCtrlA})
+	if s.filter != "" {
+		t.Errorf("space changed the filter to %q", s.filter)
+	}
+	if got := names(s.GetChecked()); got != "one,two" {
+		t.Errorf("after ctrl+a checked %q, expected one,two", got)
+	}
+
+	// Enter shows the summary; n goes back and y confirms
+	press(s, enter)
+	if !s.confirming || !strings.Contains(s.View(), "Remove 2 session(s)?") {
+		t.Fatalf("enter did not show the summary:\n%s", s.View())
+	}
+	press(s, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if s.confirming {
+		t.Error("n did not go back to the list")
+	}
+	press(s, tea.KeyMsg{Type: tea.KeyCtrlA}, tea.KeyMsg{Type: tea.KeyUp}, space, enter, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !s.confirmed || s.IsQuit() {
+		t.Fatal("y did not confirm the summary")
+	}
+	if got := names(s.GetChecked()); got != "two" {
+		t.Errorf("confirmed %q, expected two", got)
+	}
+
+	// With nothing checked, enter takes the session under the cursor
+	s = NewSessionSelector(sessions).WithMulti("Remove", nil, disabled, nil)
+	press(s, down, enter)
+	if got := names(s.GetChecked()); !s.confirming || got != "two" {
+		t.Errorf("enter with nothing checked confirming %v with %q, expected two", s.confirming, got)
+	}
+}
+
+func names(sessions []git.SessionInfo) string {
+	var names []string
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// spinnerFrames animate the progress spinner
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressStatusWidth is how much of a progress line is shown next to the
+// spinner
+const progressStatusWidth = 60
+
+// Progress reports a long-running operation on stderr. On a terminal it is
+// a spinner with the label, the elapsed time and the latest line written to
+// it, such as git's --progress output. Otherwise the label is printed once
+// and only complete lines written to it are passed through, so logs don't
+// fill with progress updates.
+type Progress struct {
+	out   io.Writer
+	tty   bool
+	start time.Time
+	stop  chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	label   string
+	status  string
+	partial []byte
+	stopped bool
+}
+
+// StartProgress shows a spinner for an operation until Stop is called.
+// In quiet mode nothing is shown.
+func StartProgress(label string) *Progress {
+	if quiet {
+		return startProgress(io.Discard, false, label)
+	}
+	return startProgress(os.Stderr, isTerminal(os.Stderr), label)
+}
+
+func startProgress(out io.Writer, tty bool, label string) *Progress {
+	p := &Progress{
+		out:   out,
+		tty:   tty,
+		start: time.Now(),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		label: label,
+	}
+	if !tty {
+		fmt.Fprintf(out, "%s...\n", label)
+		close(p.done)
+		return p
+	}
+	go p.spin()
+	return p
+}
+
+// WithProgress runs fn with a spinner for label, passing it the writer to
+// stream git's progress output to
+func WithProgress(label string, fn func(out io.Writer) error) error {
+	p := StartProgress(label)
+	defer p.Stop()
+	return fn(p)
+}
+
+// spin redraws the spinner until Stop is called
+func (p *Progress) spin() {
+	defer close(p.done)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		p.mu.Lock()
+		line := fmt.Sprintf("%s %s", spinnerFrames[frame%len(spinnerFrames)], p.label)
+		if elapsed := time.Since(p.start); elapsed >= time.Second {
+			line += fmt.Sprintf(" (%ds)", int(elapsed.Seconds()))
+		}
+		if p.status != "" {
+			line += "  " + p.status
+		}
+		p.mu.Unlock()
+		fmt.Fprintf(p.out, "\r\x1b[K%s", InfoStyle.Render(line))
+
+		select {
+		case <-p.stop:
+			fmt.Fprint(p.out, "\r\x1b[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetLabel changes what the operation is described as, e.g. when it moves
+// on to its next step
+func (p *Progress) SetLabel(label string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.label = label
+	p.status = ""
+	if !p.tty {
+		fmt.Fprintf(p.out, "%s...\n", label)
+	}
+}
+
+// Write takes progress output, in which git separates updates of the same
+// line with carriage returns
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexAny(p.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		// git clears the line with an escape sequence after its last update
+		line := string(bytes.TrimSpace(bytes.ReplaceAll(p.partial[:i], []byte("\x1b[K"), nil)))
+		complete := p.partial[i] == '\n'
+		p.partial = p.partial[i+1:]
+
+		if line == "" {
+			continue
+		}
+		if p.tty {
+			p.status = truncateStatus(line)
+		} else if complete {
+			fmt.Fprintln(p.out, line)
+		}
+	}
+	return len(b), nil
+}
+
+// Stop removes the spinner. It is safe to call more than once.
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	if !p.tty {
+		if line := string(bytes.TrimSpace(p.partial)); line != "" {
+			fmt.Fprintln(p.out, line)
+		}
+	}
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+}
+
+// truncateStatus shortens a progress line to fit next to the spinner
+func truncateStatus(line string) string {
+	runes := []rune(line)
+	if len(runes) <= progressStatusWidth {
+		return line
+	}
+	return string(runes[:progressStatusWidth-1]) + "…"
+}
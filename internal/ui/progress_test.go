@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+	p := startProgress(&out, false, "Pulling Git LFS objects")
+
+	// git rewrites the same line with carriage returns, then ends it
+	p.Write([]byte("Receiving objects:  50% (1/2)\rReceiving objects: 100% (2/2)"))
+	p.Write([]byte(", done.\nResolving"))
+	p.SetLabel("Initializing submodules")
+	p.Write([]byte(" deltas: 100% (1/1)"))
+	p.Stop()
+	p.Stop()
+
+	expected := "Pulling Git LFS objects...\n" +
+		"Receiving objects: 100% (2/2), done.\n" +
+		"Initializing submodules...\n" +
+		"Resolving deltas: 100% (1/1)\n"
+	if out.String() != expected {
+		t.Errorf("progress output = %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestProgressOnTerminal(t *testing.T) {
+	var out bytes.Buffer
+	p := startProgress(&out, true, "Rebasing")
+
+	p.Write([]byte("Updating files:  10% (1/10)\rUpdating files:  20% (2/10)\r"))
+	p.mu.Lock()
+	status := p.status
+	p.mu.Unlock()
+	if status != "Updating files:  20% (2/10)" {
+		t.Errorf("status = %q, expected the latest progress line", status)
+	}
+
+	p.Write([]byte("Rebasing (3/3)\r\r\x1b[KSuccessfully rebased\n"))
+	p.mu.Lock()
+	status = p.status
+	p.mu.Unlock()
+	if status != "Successfully rebased" {
+		t.Errorf("status = %q, expected the line git cleared and rewrote", status)
+	}
+
+	p.Write([]byte(strings.Repeat("x", 100) + "\n"))
+	p.mu.Lock()
+	status = p.status
+	p.mu.Unlock()
+	if len([]rune(status)) != progressStatusWidth || !strings.HasSuffix(status, "…") {
+		t.Errorf("status = %q, expected it truncated to %d characters", status, progressStatusWidth)
+	}
+
+	p.Stop()
+	if !strings.HasSuffix(out.String(), "\r\x1b[K") {
+		t.Errorf("the spinner should be cleared when stopped, got %q", out.String())
+	}
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// reviewChrome is the number of lines the review pager uses besides the
+// patch: title, file line, blank line, blank line, status and help
+const reviewChrome = 6
+
+// ReviewDiff pages through files one at a time and reports whether the
+// changes were approved. Leaving without approving is not an error.
+func ReviewDiff(title string, files []git.FileDiff) (bool, error) {
+	viewer := newReviewViewer(title, files)
+	if _, err := tea.NewProgram(viewer, tea.WithAltScreen()).Run(); err != nil {
+		return false, fmt.Errorf("failed to run review: %w", err)
+	}
+	return viewer.approved, nil
+}
+
+// reviewViewer is a pager over the patches of a diff, file by file
+type reviewViewer struct {
+	title string
+	files []git.FileDiff
+	// lines holds the patch of each file split into lines
+	lines [][]string
+	file  int
+	// offset is the index of the first line of the patch shown
+	offset int
+	height int
+	// seen records the files that have been shown
+	seen     map[int]bool
+	approved bool
+}
+
+func newReviewViewer(title string, files []git.FileDiff) *reviewViewer {
+	v := &reviewViewer{title: title, files: files, height: 20, seen: map[int]bool{0: true}}
+	for _, f := range files {
+		v.lines = append(v.lines, strings.Split(f.Patch, "\n"))
+	}
+	return v
+}
+
+func (v *reviewViewer) Init() tea.Cmd {
+	return nil
+}
+
+// rows returns how many lines of the patch fit on screen
+func (v *reviewViewer) rows() int {
+	if v.height-reviewChrome < 1 {
+		return 1
+	}
+	return v.height - reviewChrome
+}
+
+// scrollTo shows the patch from line i, keeping the screen filled
+func (v *reviewViewer) scrollTo(i int) {
+	if len(v.files) == 0 {
+		return
+	}
+	if last := len(v.lines[v.file]) - v.rows(); i > last {
+		i = last
+	}
+	if i < 0 {
+		i = 0
+	}
+	v.offset = i
+}
+
+// showFile moves to file i from its first line
+func (v *reviewViewer) showFile(i int) {
+	if i < 0 || i >= len(v.files) {
+		return
+	}
+	v.file, v.offset = i, 0
+	v.seen[i] = true
+}
+
+func (v *reviewViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.height = msg.Height
+		v.scrollTo(v.offset)
+		return v, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c", "esc", "q"))):
+			return v, tea.Quit
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+			v.approved = true
+			return v, tea.Quit
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			v.scrollTo(v.offset - 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			v.scrollTo(v.offset + 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgup", "ctrl+u", "b"))):
+			v.scrollTo(v.offset - v.rows())
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown", "ctrl+d", " "))):
+			v.scrollTo(v.offset + v.rows())
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("home", "g"))):
+			v.scrollTo(0)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("end", "G"))):
+			if len(v.files) > 0 {
+				v.scrollTo(len(v.lines[v.file]))
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("right", "n", "tab"))):
+			v.showFile(v.file + 1)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("left", "p", "shift+tab"))):
+			v.showFile(v.file - 1)
+		}
+	}
+	return v, nil
+}
+
+func (v *reviewViewer) View() string {
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	b.WriteString(TitleStyle.Render(v.title))
+	b.WriteString("\n")
+
+	if len(v.files) == 0 {
+		b.WriteString("\n")
+		b.WriteString(dim.Render("  No changes"))
+		b.WriteString("\n\n\n")
+		b.WriteString(dim.Render("a: approve • q: quit"))
+		return b.String()
+	}
+
+	f := v.files[v.file]
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("File %d/%d: %s", v.file+1, len(v.files), f.Path)))
+	b.WriteString(dim.Render(fmt.Sprintf("  +%d -%d", f.Insertions, f.Deletions)))
+	b.WriteString("\n\n")
+
+	lines := v.lines[v.file]
+	end := v.offset + v.rows()
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for _, line := range lines[v.offset:end] {
+		b.WriteString(patchLineStyle(line).Render(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	status := fmt.Sprintf("Lines %d-%d of %d", v.offset+1, end, len(lines))
+	if unseen := len(v.files) - len(v.seen); unseen > 0 {
+		status += fmt.Sprintf(" • %d file(s) not viewed yet", unseen)
+	}
+	b.WriteString(dim.Render(status))
+	b.WriteString("\n")
+	b.WriteString(dim.Render("↑/↓/space/b: scroll • n/p: next/previous file • a: approve • q: quit without approving"))
+
+	return b.String()
+}
+
+// patchLineStyle colours a line of a patch the way git diff does
+func patchLineStyle(line string) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "diff --git "):
+		return lipgloss.NewStyle().Bold(true)
+	case strings.HasPrefix(line, "@@"):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("37"))
+	case strings.HasPrefix(line, "+"):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+	case strings.HasPrefix(line, "-"):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	}
+	return lipgloss.NewStyle()
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+func TestReviewViewer(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("+line %d", i))
+	}
+	files := []git.FileDiff{
+		{Path: "a.go", Patch: strings.Join(lines, "\n"), Insertions: 30},
+		{Path: "b.go", Patch: "-gone", Deletions: 1},
+	}
+
+	v := newReviewViewer("Review", files)
+	v.Update(tea.WindowSizeMsg{Width: 80, Height: reviewChrome + 10})
+
+	v.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if v.offset != 10 {
+		t.Errorf("after pgdown offset = %d, expected 10", v.offset)
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if v.offset != 20 {
+		t.Errorf("after G offset = %d, expected 20", v.offset)
+	}
+	if view := v.View(); !strings.Contains(view, "line 29") || strings.Contains(view, "line 19") || !strings.Contains(view, "1 file(s) not viewed") {
+		t.Errorf("View() should show lines 20 to 29 and one unviewed file:\n%s", view)
+	}
+
+	v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if v.file != 1 || v.offset != 0 {
+		t.Errorf("after n file = %d, offset = %d; expected 1 and 0", v.file, v.offset)
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if v.file != 1 {
+		t.Errorf("n on the last file moved to file %d", v.file)
+	}
+	if view := v.View(); !strings.Contains(view, "File 2/2: b.go") || strings.Contains(view, "not viewed") {
+		t.Errorf("View() should show b.go with every file viewed:\n%s", view)
+	}
+
+	if _, cmd := v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil || v.approved {
+		t.Error("q should quit without approving")
+	}
+	if _, cmd := v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}); cmd == nil || !v.approved {
+		t.Error("a should approve and quit")
+	}
+}
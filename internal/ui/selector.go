@@ -2,84 +2,515 @@ package ui
 
 import (
 	"fmt"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/utils"
 )
 
+// SortMode controls the order in which the selector lists sessions
+type SortMode int
+
+const (
+	SortDefault SortMode = iota
+	SortRecent
+	SortName
+	SortAhead
+)
+
+var sortModeNames = []string{"default", "recent", "name", "ahead"}
+
+func (m SortMode) String() string {
+	return sortModeNames[m]
+}
+
+// sessionStatus holds asynchronously loaded state for a session
+type sessionStatus struct {
+	status       git.WorktreeStatus
+	lastActivity time.Time
+	err          error
+}
+
+// statusMsg delivers a loaded session status to the selector
+type statusMsg struct {
+	path   string
+	status sessionStatus
+}
+
+// activityMsg delivers the latest agent activity to the selector
+type activityMsg map[string]string
+
+// activityPollInterval is how often the selector reloads agent activity
+const activityPollInterval = 2 * time.Second
+
+const defaultSelectorTitle = "📂 Select session to switch to:"
+
+// maxSelectorNoteLines caps the notes shown for the session under the cursor
+const maxSelectorNoteLines = 6
+
 type SessionSelector struct {
-	sessions []git.SessionInfo
-	cursor   int
-	selected int
-	quit     bool
+	title      string
+	sessions   []git.SessionInfo
+	visible    []int
+	filter     string
+	sortMode   SortMode
+	baseBranch string
+	statuses   map[string]sessionStatus
+	activity   func() map[string]string
+	activities map[string]string
+	lastUsed   map[string]time.Time
+	issues     map[string]string
+	notes      map[string]string
+	pinned     map[string]bool
+	expired    map[string]bool
+	cursor     int
+	selected   int
+	quit       bool
+
+	// multi lets any number of sessions be checked with space; enter then
+	// shows what will be done to them, named by action, to confirm
+	multi      bool
+	action     string
+	checked    map[int]bool
+	disabled   map[string]string
+	warnings   map[string]string
+	confirming bool
+	confirmed  bool
 }
 
 func NewSessionSelector(sessions []git.SessionInfo) *SessionSelector {
-	return &SessionSelector{
+	s := &SessionSelector{
+		title:    defaultSelectorTitle,
 		sessions: sessions,
 		selected: -1,
+		statuses: make(map[string]sessionStatus),
+	}
+	s.refresh()
+	return s
+}
+
+// WithTitle replaces the title shown above the list
+func (s *SessionSelector) WithTitle(title string) *SessionSelector {
+	s.title = title
+	return s
+}
+
+// WithStatus enables status glyphs relative to baseBranch. Statuses are
+// loaded in the background so the selector opens immediately.
+func (s *SessionSelector) WithStatus(baseBranch string) *SessionSelector {
+	s.baseBranch = baseBranch
+	return s
+}
+
+// WithActivity shows what each session's agent is doing, as returned by
+// activity keyed by worktree path. It is polled while the selector is open.
+func (s *SessionSelector) WithActivity(activity func() map[string]string) *SessionSelector {
+	s.activity = activity
+	return s
+}
+
+// WithLastUsed shows how recently each session was used, as given by used
+// keyed by worktree path, and lists the most recently used sessions first
+func (s *SessionSelector) WithLastUsed(used map[string]time.Time) *SessionSelector {
+	s.lastUsed = used
+	s.sortMode = SortRecent
+	s.refresh()
+	return s
+}
+
+// WithIssues shows the task each session works on, as given by issues
+// keyed by worktree path
+func (s *SessionSelector) WithIssues(issues map[string]string) *SessionSelector {
+	s.issues = issues
+	return s
+}
+
+// WithNotes shows the notes of the session under the cursor below the
+// list, as given by notes keyed by worktree path
+func (s *SessionSelector) WithNotes(notes map[string]string) *SessionSelector {
+	s.notes = notes
+	return s
+}
+
+// WithPinned marks the pinned sessions, keyed by worktree path
+func (s *SessionSelector) WithPinned(pinned map[string]bool) *SessionSelector {
+	s.pinned = pinned
+	return s
+}
+
+// WithExpired marks the sessions whose TTL ran out, keyed by worktree path
+func (s *SessionSelector) WithExpired(expired map[string]bool) *SessionSelector {
+	s.expired = expired
+	return s
+}
+
+// WithMulti lets the user check any number of sessions with space, starting
+// with those in checked, and confirm them on a summary of what action does
+// to them. Sessions in disabled, keyed by worktree path, can't be checked;
+// the reason is shown next to them, like warnings for the others.
+func (s *SessionSelector) WithMulti(action string, checked map[string]bool, disabled, warnings map[string]string) *SessionSelector {
+	s.multi = true
+	s.action = action
+	s.disabled = disabled
+	s.warnings = warnings
+	s.checked = make(map[int]bool)
+	for i, session := range s.sessions {
+		if checked[session.Path] && disabled[session.Path] == "" {
+			s.checked[i] = true
+		}
 	}
+	return s
 }
 
 func (s *SessionSelector) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if s.activity != nil {
+		cmds = append(cmds, s.loadActivity)
+	}
+	if s.baseBranch == "" {
+		return tea.Batch(cmds...)
+	}
+
+	// Limit concurrent git processes on repos with many sessions
+	sem := make(chan struct{}, runtime.NumCPU())
+	for _, session := range s.sessions {
+		path, base := session.Path, s.baseBranch
+		cmds = append(cmds, func() tea.Msg {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			return loadSessionStatus(path, base)
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadActivity reads the current agent activity
+func (s *SessionSelector) loadActivity() tea.Msg {
+	return activityMsg(s.activity())
+}
+
+func loadSessionStatus(path, baseBranch string) statusMsg {
+	var st sessionStatus
+	st.status, st.err = git.CachedWorktreeStatus(path, baseBranch)
+	if last, err := git.GetLastCommitTime(path, "HEAD"); err == nil {
+		st.lastActivity = last
+	}
+	return statusMsg{path: path, status: st}
 }
 
 func (s *SessionSelector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if msg, ok := msg.(tea.KeyMsg); ok {
+	switch msg := msg.(type) {
+	case statusMsg:
+		s.statuses[msg.path] = msg.status
+		if s.sortMode == SortRecent || s.sortMode == SortAhead {
+			s.refresh()
+		}
+
+	case activityMsg:
+		s.activities = msg
+		return s, tea.Tick(activityPollInterval, func(time.Time) tea.Msg {
+			return s.loadActivity()
+		})
+
+	case tea.KeyMsg:
+		if s.confirming {
+			return s.updateConfirm(msg)
+		}
 		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("q", "ctrl+c", "esc"))):
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
 			s.quit = true
 			return s, tea.Quit
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+		case s.multi && key.Matches(msg, key.NewBinding(key.WithKeys(" "))):
+			if len(s.visible) > 0 {
+				s.toggle(s.visible[s.cursor])
+			}
+
+		case s.multi && key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+a"))):
+			s.toggleAll()
+
+		case s.multi && key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			// With nothing checked, enter picks the session under the cursor
+			if len(s.checked) == 0 && len(s.visible) > 0 {
+				s.toggle(s.visible[s.cursor])
+			}
+			if len(s.checked) > 0 {
+				s.confirming = true
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			if s.filter != "" {
+				s.filter = ""
+				s.refresh()
+				return s, nil
+			}
+			s.quit = true
+			return s, tea.Quit
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "ctrl+p"))):
 			if s.cursor > 0 {
 				s.cursor--
 			}
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
-			if s.cursor < len(s.sessions)-1 {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "ctrl+n"))):
+			if s.cursor < len(s.visible)-1 {
 				s.cursor++
 			}
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("enter", " "))):
-			s.selected = s.cursor
-			return s, tea.Quit
+		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+			s.sortMode = (s.sortMode + 1) % SortMode(len(sortModeNames))
+			s.refresh()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if len(s.visible) > 0 {
+				s.selected = s.visible[s.cursor]
+				return s, tea.Quit
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("backspace"))):
+			if s.filter != "" {
+				runes := []rune(s.filter)
+				s.filter = string(runes[:len(runes)-1])
+				s.refresh()
+			}
+
+		case msg.Type == tea.KeyRunes:
+			s.filter += string(msg.Runes)
+			s.refresh()
 		}
 	}
 	return s, nil
 }
 
+// updateConfirm handles keys on the summary shown before returning the
+// checked sessions
+func (s *SessionSelector) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
+		s.quit = true
+		return s, tea.Quit
+	case key.Matches(msg, key.NewBinding(key.WithKeys("y", "enter"))):
+		s.confirmed = true
+		return s, tea.Quit
+	case key.Matches(msg, key.NewBinding(key.WithKeys("n", "esc", "backspace"))):
+		s.confirming = false
+	}
+	return s, nil
+}
+
+// toggle checks or unchecks the session at index i, unless it is disabled
+func (s *SessionSelector) toggle(i int) {
+	if s.disabled[s.sessions[i].Path] != "" {
+		return
+	}
+	if s.checked[i] {
+		delete(s.checked, i)
+	} else {
+		s.checked[i] = true
+	}
+}
+
+// toggleAll checks every visible session that can be checked, or unchecks
+// them all if they already are
+func (s *SessionSelector) toggleAll() {
+	all := true
+	for _, i := range s.visible {
+		if s.disabled[s.sessions[i].Path] == "" && !s.checked[i] {
+			all = false
+		}
+	}
+	for _, i := range s.visible {
+		if all {
+			delete(s.checked, i)
+		} else if s.disabled[s.sessions[i].Path] == "" {
+			s.checked[i] = true
+		}
+	}
+}
+
+// refresh recomputes the visible sessions from the filter and sort mode
+func (s *SessionSelector) refresh() {
+	scores := make(map[int]int)
+	s.visible = s.visible[:0]
+	for i, session := range s.sessions {
+		score, ok := FuzzyScore(s.filter, session.Name+" "+session.Branch)
+		if !ok {
+			continue
+		}
+		scores[i] = score
+		s.visible = append(s.visible, i)
+	}
+
+	sort.SliceStable(s.visible, func(a, b int) bool {
+		i, j := s.visible[a], s.visible[b]
+		if s.filter != "" && scores[i] != scores[j] {
+			return scores[i] > scores[j]
+		}
+		return s.less(i, j)
+	})
+
+	if s.cursor >= len(s.visible) {
+		s.cursor = len(s.visible) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+// less orders two sessions by the current sort mode
+func (s *SessionSelector) less(i, j int) bool {
+	a, b := s.sessions[i], s.sessions[j]
+	switch s.sortMode {
+	case SortRecent:
+		return s.recency(a.Path).After(s.recency(b.Path))
+	case SortName:
+		return a.Name < b.Name
+	case SortAhead:
+		return s.statuses[a.Path].status.Ahead > s.statuses[b.Path].status.Ahead
+	default:
+		return i < j
+	}
+}
+
+// recency returns when the session at path was last used or committed to,
+// whichever is later
+func (s *SessionSelector) recency(path string) time.Time {
+	last := s.statuses[path].lastActivity
+	if used := s.lastUsed[path]; used.After(last) {
+		return used
+	}
+	return last
+}
+
+// glyphs returns the status glyphs for a session, or a placeholder while loading
+func (s *SessionSelector) glyphs(session git.SessionInfo) string {
+	if s.baseBranch == "" {
+		return ""
+	}
+	st, ok := s.statuses[session.Path]
+	switch {
+	case !ok:
+		return "…"
+	case st.err != nil:
+		return "?"
+	default:
+		return StatusGlyphs(st.status)
+	}
+}
+
 func (s *SessionSelector) View() string {
-	if s.quit || s.selected >= 0 {
+	if s.quit || s.selected >= 0 || s.confirmed {
 		return ""
 	}
+	if s.confirming {
+		return s.confirmView()
+	}
 
 	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	now := time.Now()
 
-	b.WriteString(TitleStyle.Render("📂 Select session to switch to:"))
+	b.WriteString(TitleStyle.Render(s.title))
+	b.WriteString("\n")
+	b.WriteString(dim.Render(fmt.Sprintf("Filter: %s▏  Sort: %s", s.filter, s.sortMode)))
 	b.WriteString("\n\n")
 
-	for i, session := range s.sessions {
+	if len(s.visible) == 0 {
+		b.WriteString(dim.Render("  No matching sessions"))
+		b.WriteString("\n")
+	}
+
+	for pos, i := range s.visible {
+		session := s.sessions[i]
 		cursor := "  "
-		if s.cursor == i {
+		if s.cursor == pos {
 			cursor = "→ "
 		}
 
-		sessionLine := fmt.Sprintf("%s%s (%s)", cursor, session.Name, session.Branch)
-		if s.cursor == i {
+		reason := s.disabled[session.Path]
+		if s.multi {
+			cursor += checkBox(s.checked[i], reason != "") + " "
+		}
+
+		sessionLine := fmt.Sprintf("%s%s (%s)", cursor, session.Name, session.BranchLabel())
+		if glyphs := s.glyphs(session); glyphs != "" {
+			sessionLine = fmt.Sprintf("%s%-8s %s (%s)", cursor, glyphs, session.Name, session.BranchLabel())
+		}
+
+		if s.pinned[session.Path] {
+			sessionLine += " 📌"
+		}
+		if s.expired[session.Path] {
+			sessionLine += " ⌛ expired"
+		}
+
+		switch {
+		case s.cursor == pos:
 			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true).Render(sessionLine))
-		} else {
+		case reason != "":
+			b.WriteString(dim.Render(sessionLine))
+		default:
 			b.WriteString(sessionLine)
 		}
+		if note := reason + s.warnings[session.Path]; s.multi && note != "" {
+			b.WriteString(dim.Render("  · " + note))
+		}
+		if last := s.recency(session.Path); !last.IsZero() {
+			b.WriteString(dim.Render("  " + utils.FormatRelative(last, now)))
+		}
+		if issue := s.issues[session.Path]; issue != "" {
+			b.WriteString(dim.Render("  · " + issue))
+		}
+		if activity := s.activities[session.Path]; activity != "" {
+			b.WriteString(dim.Render("  · " + activity))
+		}
 		b.WriteString("\n")
 	}
 
+	if len(s.visible) > 0 {
+		if notes := s.notes[s.sessions[s.visible[s.cursor]].Path]; notes != "" {
+			b.WriteString("\n")
+			b.WriteString(lipgloss.NewStyle().Bold(true).Render("Notes"))
+			b.WriteString("\n")
+			for _, line := range strings.Split(utils.FirstLines(notes, maxSelectorNoteLines), "\n") {
+				b.WriteString(dim.Render("  " + line))
+				b.WriteString("\n")
+			}
+		}
+	}
+
 	b.WriteString("\n")
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("↑/↓/j/k: navigate • enter/space: select • q/esc: quit"))
+	if s.multi {
+		b.WriteString(dim.Render(fmt.Sprintf("%d checked • type to filter • ↑/↓: navigate • space: toggle • ctrl+a: all • tab: sort • enter: review • esc: clear/quit", len(s.checked))))
+	} else {
+		b.WriteString(dim.Render("type to filter • ↑/↓: navigate • tab: sort • enter: select • esc: clear/quit"))
+	}
+
+	return b.String()
+}
+
+// confirmView summarises what will be done to the checked sessions
+func (s *SessionSelector) confirmView() string {
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("%s %d session(s)?", s.action, len(s.checked))))
+	b.WriteString("\n\n")
+	for _, session := range s.GetChecked() {
+		b.WriteString(fmt.Sprintf("  • %s (%s)", session.Name, session.BranchLabel()))
+		if warning := s.warnings[session.Path]; warning != "" {
+			b.WriteString(dim.Render("  · " + warning))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(dim.Render("y/enter: confirm • n/esc: back to the list • ctrl+c: quit"))
 
 	return b.String()
 }
@@ -91,6 +522,18 @@ func (s *SessionSelector) GetSelected() *git.SessionInfo {
 	return nil
 }
 
+// GetChecked returns the checked sessions in the order they were given,
+// once the user confirmed them
+func (s *SessionSelector) GetChecked() []git.SessionInfo {
+	var checked []git.SessionInfo
+	for i, session := range s.sessions {
+		if s.checked[i] {
+			checked = append(checked, session)
+		}
+	}
+	return checked
+}
+
 func (s *SessionSelector) IsQuit() bool {
 	return s.quit
 }
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksred/ccswitch/internal/git"
+)
+
+// StatusGlyphs renders a compact status such as "● ↑2 ↓1" for a worktree.
+// Returns "✓" when the worktree is clean and in sync.
+func StatusGlyphs(status git.WorktreeStatus) string {
+	var parts []string
+	if status.Dirty {
+		parts = append(parts, "●")
+	}
+	if status.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", status.Ahead))
+	}
+	if status.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", status.Behind))
+	}
+	if len(parts) == 0 {
+		return "✓"
+	}
+	return strings.Join(parts, " ")
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/fatih/color"
+	"github.com/ksred/ccswitch/internal/i18n"
 )
 
 var (
@@ -19,52 +20,79 @@ var (
 	warningColor = color.New(color.FgYellow)
 )
 
+// The helpers below print messages in the language chosen with i18n,
+// translating the format before it is filled in
+
 // Infof prints a formatted info message in blue
 func Infof(format string, args ...interface{}) {
-	infoColor.Printf(format+"\n", args...)
+	if quiet {
+		return
+	}
+	infoColor.Printf(i18n.T(format)+"\n", args...)
 }
 
 // Successf prints a formatted success message in green
 func Successf(format string, args ...interface{}) {
-	successColor.Printf(format+"\n", args...)
+	if quiet {
+		return
+	}
+	successColor.Printf(i18n.T(format)+"\n", args...)
 }
 
 // Errorf prints a formatted error message in red
 func Errorf(format string, args ...interface{}) {
-	errorColor.Printf(format+"\n", args...)
+	errorColor.Printf(i18n.T(format)+"\n", args...)
 }
 
 // Info prints a message in blue
 func Info(msg string) {
-	infoColor.Println(msg)
+	if quiet {
+		return
+	}
+	infoColor.Println(i18n.T(msg))
 }
 
 // Success prints a message in green
 func Success(msg string) {
-	successColor.Println(msg)
+	if quiet {
+		return
+	}
+	successColor.Println(i18n.T(msg))
 }
 
 // Error prints a message in red
 func Error(msg string) {
-	errorColor.Println(msg)
+	errorColor.Println(i18n.T(msg))
 }
 
 // Titlef prints a formatted title message in magenta bold
 func Titlef(format string, args ...interface{}) {
-	titleColor.Printf(format+"\n", args...)
+	if quiet {
+		return
+	}
+	titleColor.Printf(i18n.T(format)+"\n", args...)
 }
 
 // Title prints a title message in magenta bold
 func Title(msg string) {
-	titleColor.Println(msg)
+	if quiet {
+		return
+	}
+	titleColor.Println(i18n.T(msg))
 }
 
 // Warningf prints a formatted warning message in yellow
 func Warningf(format string, args ...interface{}) {
-	warningColor.Printf(format+"\n", args...)
+	if quiet {
+		return
+	}
+	warningColor.Printf(i18n.T(format)+"\n", args...)
 }
 
 // Warning prints a warning message in yellow
 func Warning(msg string) {
-	warningColor.Println(msg)
+	if quiet {
+		return
+	}
+	warningColor.Println(i18n.T(msg))
 }
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// quiet suppresses everything but errors
+var quiet bool
+
+func init() {
+	if !ColorEnabled() {
+		DisableColor()
+	} else if os.Getenv("CCSWITCH_SHELL_WRAPPER") == "1" {
+		// The shell wrapper pipes stdout through tee to the terminal, so
+		// take the color support from stderr, which is not piped
+		color.NoColor = false
+		lipgloss.SetColorProfile(termenv.NewOutput(os.Stderr).EnvColorProfile())
+	}
+}
+
+// SetQuiet suppresses informational, success and warning messages and
+// progress spinners, leaving only errors
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// IsQuiet reports whether only errors are printed
+func IsQuiet() bool {
+	return quiet
+}
+
+// UseStderr prints messages on stderr, leaving stdout to machine-readable
+// output such as the --porcelain event stream
+func UseStderr() {
+	color.Output = colorable.NewColorable(os.Stderr)
+}
+
+// DisableColor turns off colors and styles in all output
+func DisableColor() {
+	color.NoColor = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// ColorEnabled reports whether output should be colored: not when NO_COLOR
+// is set, TERM is dumb, or stdout is not a terminal
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// Interactive reports whether the interactive selectors can be used: stdin
+// and stdout must be terminals
+func Interactive() bool {
+	return isTerminal(os.Stdin) && stdoutIsTerminal()
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal, counting the shell
+// wrapper, which pipes stdout through tee to the terminal
+func stdoutIsTerminal() bool {
+	return isTerminal(os.Stdout) || os.Getenv("CCSWITCH_SHELL_WRAPPER") == "1"
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
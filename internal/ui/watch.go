@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/utils"
+)
+
+// watchHighlightFor is how long a changed session stays highlighted
+const watchHighlightFor = 10 * time.Second
+
+// watchLogSize is the number of recent changes shown below the table
+const watchLogSize = 8
+
+// StatusChange describes how a session changed between two status loads
+type StatusChange struct {
+	Session string
+	Path    string
+	What    string
+}
+
+// DiffStatus returns the changes from prev to cur, in the order of cur's
+// sessions followed by removed sessions. A nil prev yields no changes.
+func DiffStatus(prev, cur *schema.Status) []StatusChange {
+	if prev == nil || cur == nil {
+		return nil
+	}
+
+	before := make(map[string]schema.StatusSession, len(prev.Sessions))
+	for _, s := range prev.Sessions {
+		before[s.Path] = s
+	}
+
+	var changes []StatusChange
+	seen := make(map[string]bool, len(cur.Sessions))
+	for _, s := range cur.Sessions {
+		seen[s.Path] = true
+		old, ok := before[s.Path]
+		if !ok {
+			changes = append(changes, StatusChange{s.Name, s.Path, "new session"})
+			continue
+		}
+		for _, what := range sessionChanges(old, s) {
+			changes = append(changes, StatusChange{s.Name, s.Path, what})
+		}
+	}
+	for _, s := range prev.Sessions {
+		if !seen[s.Path] {
+			changes = append(changes, StatusChange{s.Name, s.Path, "removed"})
+		}
+	}
+	return changes
+}
+
+// sessionChanges describes what changed in one session
+func sessionChanges(old, cur schema.StatusSession) []string {
+	var changes []string
+
+	switch {
+	case cur.Ahead > old.Ahead:
+		changes = append(changes, fmt.Sprintf("%d new commit(s)", cur.Ahead-old.Ahead))
+	case cur.Ahead < old.Ahead:
+		changes = append(changes, fmt.Sprintf("now %d ahead (was %d)", cur.Ahead, old.Ahead))
+	case !timeEqual(cur.LastActive, old.LastActive):
+		changes = append(changes, "commits rewritten")
+	}
+
+	if cur.Behind != old.Behind {
+		changes = append(changes, fmt.Sprintf("now %d behind", cur.Behind))
+	}
+
+	if cur.Dirty && !old.Dirty {
+		changes = append(changes, "uncommitted changes")
+	} else if !cur.Dirty && old.Dirty && cur.Ahead <= old.Ahead {
+		changes = append(changes, "changes discarded or stashed")
+	}
+
+	switch {
+	case cur.Activity == nil:
+	case old.Activity == nil || cur.Activity.Status != old.Activity.Status:
+		changes = append(changes, "activity: "+cur.Activity.Status)
+	case cur.Activity.Quiet && !old.Activity.Quiet:
+		changes = append(changes, "went quiet")
+	}
+
+	return changes
+}
+
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// statusLoadedMsg delivers a freshly loaded status to the watch view
+type statusLoadedMsg struct {
+	doc *schema.Status
+	err error
+	at  time.Time
+}
+
+// loggedChange is a change shown in the watch view's log
+type loggedChange struct {
+	StatusChange
+	at time.Time
+}
+
+// reloadTickMsg asks the watch view to reload once interval has passed
+// since load number gen
+type reloadTickMsg struct {
+	gen int
+}
+
+// gitChangedMsg tells the watch view that the git state of worktrees
+// changed
+type gitChangedMsg struct{}
+
+// WatchView is a live table of sessions, reloaded every interval, that
+// highlights sessions as they change
+type WatchView struct {
+	title    string
+	load     func() (*schema.Status, error)
+	interval time.Duration
+	// changes, when set, reloads the view as soon as it receives
+	changes <-chan []string
+	// gen counts loads, so only the tick of the latest one reloads
+	gen int
+	// loading is set while a load runs, and pending when changes arrived
+	// meanwhile, so loads never overlap
+	loading, pending bool
+
+	doc       *schema.Status
+	err       error
+	updatedAt time.Time
+	changedAt map[string]time.Time
+	log       []loggedChange
+}
+
+// NewWatchView creates a watch view that calls load every interval
+func NewWatchView(title string, interval time.Duration, load func() (*schema.Status, error)) *WatchView {
+	return &WatchView{
+		title:     title,
+		load:      load,
+		interval:  interval,
+		changedAt: make(map[string]time.Time),
+	}
+}
+
+// ReloadOn makes the view reload as soon as changes receives, such as the
+// Changes of a git.Watcher, besides every interval
+func (w *WatchView) ReloadOn(changes <-chan []string) {
+	w.changes = changes
+}
+
+func (w *WatchView) Init() tea.Cmd {
+	w.loading = true
+	return tea.Batch(w.reload, w.waitForChanges)
+}
+
+// waitForChanges waits for the next change sent to the view
+func (w *WatchView) waitForChanges() tea.Msg {
+	if w.changes == nil {
+		return nil
+	}
+	if _, ok := <-w.changes; !ok {
+		return nil
+	}
+	return gitChangedMsg{}
+}
+
+// reload loads the status in the background
+func (w *WatchView) reload() tea.Msg {
+	doc, err := w.load()
+	return statusLoadedMsg{doc: doc, err: err, at: time.Now()}
+}
+
+func (w *WatchView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statusLoadedMsg:
+		w.loading = false
+		w.err = msg.err
+		if msg.err == nil {
+			for _, c := range DiffStatus(w.doc, msg.doc) {
+				w.changedAt[c.Path] = msg.at
+				w.log = append(w.log, loggedChange{c, msg.at})
+			}
+			if len(w.log) > watchLogSize {
+				w.log = w.log[len(w.log)-watchLogSize:]
+			}
+			w.doc = msg.doc
+			w.updatedAt = msg.at
+		}
+		if w.pending {
+			w.pending = false
+			w.loading = true
+			return w, w.reload
+		}
+		w.gen++
+		gen := w.gen
+		return w, tea.Tick(w.interval, func(time.Time) tea.Msg {
+			return reloadTickMsg{gen: gen}
+		})
+
+	case reloadTickMsg:
+		// Reloads for changes restart the interval
+		if msg.gen == w.gen && !w.loading {
+			w.loading = true
+			return w, w.reload
+		}
+
+	case gitChangedMsg:
+		if w.loading {
+			w.pending = true
+			return w, w.waitForChanges
+		}
+		w.loading = true
+		return w, tea.Batch(w.reload, w.waitForChanges)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"))) {
+			return w, tea.Quit
+		}
+	}
+	return w, nil
+}
+
+func (w *WatchView) View() string {
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	highlight := lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	now := time.Now()
+
+	title := w.title
+	if w.doc != nil {
+		title = fmt.Sprintf("%s (compared to %s)", w.title, w.doc.Base)
+	}
+	b.WriteString(TitleStyle.Render(title))
+	b.WriteString("\n")
+
+	switch {
+	case w.doc == nil && w.err == nil:
+		b.WriteString(dim.Render("Loading…"))
+		b.WriteString("\n")
+		return b.String()
+	case w.err != nil:
+		b.WriteString(errStyle.Render("✗ " + w.err.Error()))
+		b.WriteString("\n")
+	}
+	if w.doc == nil {
+		return b.String()
+	}
+
+	refresh := fmt.Sprintf("every %s", w.interval)
+	if w.changes != nil {
+		refresh = fmt.Sprintf("on git changes and every %s", w.interval)
+	}
+	b.WriteString(dim.Render(fmt.Sprintf("Updated %s · %s", w.updatedAt.Format("15:04:05"), refresh)))
+	b.WriteString("\n\n")
+
+	if len(w.doc.Sessions) == 0 {
+		b.WriteString(dim.Render("  No active sessions"))
+		b.WriteString("\n")
+	}
+
+	for _, s := range w.doc.Sessions {
+		glyphs := StatusGlyphs(git.WorktreeStatus{Dirty: s.Dirty, Ahead: s.Ahead, Behind: s.Behind})
+		if s.Error != "" {
+			glyphs = "?"
+		}
+		last := ""
+		if s.LastActive != nil {
+			last = utils.FormatRelative(*s.LastActive, now)
+		}
+		line := fmt.Sprintf("  %-8s %-24s %-32s %-10s", glyphs, s.Name, git.BranchLabel(s.Branch), last)
+		if s.Activity != nil {
+			activity := s.Activity.Status
+			if s.Activity.Quiet {
+				activity = "quiet, last: " + activity
+			}
+			line += " " + activity
+		}
+
+		if now.Sub(w.changedAt[s.Path]) < watchHighlightFor {
+			b.WriteString(highlight.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(w.log) > 0 {
+		b.WriteString("\n")
+		b.WriteString(dim.Render("Recent changes"))
+		b.WriteString("\n")
+		for _, c := range w.log {
+			b.WriteString(fmt.Sprintf("  %s  %s: %s\n", c.at.Format("15:04:05"), c.Session, c.What))
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dim.Render("q: quit"))
+
+	return b.String()
+}
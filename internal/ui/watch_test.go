@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+func TestDiffStatus(t *testing.T) {
+	t1 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	prev := &schema.Status{Sessions: []schema.StatusSession{
+		{Name: "a", Path: "/w/a", Ahead: 1, LastActive: &t1},
+		{Name: "b", Path: "/w/b", Dirty: true, LastActive: &t1},
+		{Name: "c", Path: "/w/c", LastActive: &t1, Activity: &schema.Activity{Status: "testing"}},
+		{Name: "gone", Path: "/w/gone"},
+	}}
+	cur := &schema.Status{Sessions: []schema.StatusSession{
+		{Name: "a", Path: "/w/a", Ahead: 3, LastActive: &t2},
+		{Name: "b", Path: "/w/b", Ahead: 1, LastActive: &t2},
+		{Name: "c", Path: "/w/c", LastActive: &t1, Activity: &schema.Activity{Status: "testing", Quiet: true}},
+		{Name: "new", Path: "/w/new"},
+	}}
+
+	expected := []StatusChange{
+		{"a", "/w/a", "2 new commit(s)"},
+		{"b", "/w/b", "1 new commit(s)"},
+		{"c", "/w/c", "went quiet"},
+		{"new", "/w/new", "new session"},
+		{"gone", "/w/gone", "removed"},
+	}
+	if changes := DiffStatus(prev, cur); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("DiffStatus() = %+v, expected %+v", changes, expected)
+	}
+
+	if changes := DiffStatus(nil, cur); changes != nil {
+		t.Errorf("DiffStatus(nil, cur) = %+v, expected none", changes)
+	}
+	if changes := DiffStatus(cur, cur); len(changes) != 0 {
+		t.Errorf("DiffStatus(cur, cur) = %+v, expected none", changes)
+	}
+}
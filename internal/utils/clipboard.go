@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are tried in order to copy text to the system clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// CopyToClipboard copies text to the system clipboard. Without a clipboard
+// command, e.g. over SSH, the text is sent to the terminal in an OSC 52
+// escape sequence written to term, which most terminals honour.
+func CopyToClipboard(text string, term io.Writer) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+
+	_, err := fmt.Fprintf(term, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
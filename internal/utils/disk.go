@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// DirSize returns the total size in bytes of all regular files under path.
+// Symlinks are not followed.
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable entries rather than failing the whole walk
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, infoErr := d.Info(); infoErr == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// DirSizes computes the sizes of several directories in parallel
+func DirSizes(paths []string) map[string]int64 {
+	sizes := make(map[string]int64, len(paths))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, runtime.NumCPU())
+	for _, path := range paths {
+		wg.Add(1)
+		go func(p string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			size, _ := DirSize(p)
+			mu.Lock()
+			sizes[p] = size
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	return sizes
+}
+
+// FormatBytes formats a byte count as a human-readable string
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
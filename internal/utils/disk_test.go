@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatBytes(tt.input); result != tt.expected {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestDirSizes(t *testing.T) {
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dirA, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dirA, "one.txt"), make([]byte, 100), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dirA, "nested", "two.txt"), make([]byte, 50), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	sizes := DirSizes([]string{dirA, dirB})
+	if sizes[dirA] != 150 {
+		t.Errorf("DirSizes()[dirA] = %d, expected 150", sizes[dirA])
+	}
+	if sizes[dirB] != 0 {
+		t.Errorf("DirSizes()[dirB] = %d, expected 0", sizes[dirB])
+	}
+}
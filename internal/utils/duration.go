@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration parses a duration like time.ParseDuration, additionally
+// accepting day ("d") and week ("w") units, e.g. "2d", "1w", "1d12h"
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		j := i
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') && rest[j] != '.' {
+			j++
+		}
+		number, unit := rest[:i], rest[i:j]
+		rest = rest[j:]
+
+		var multiplier time.Duration
+		switch unit {
+		case "d":
+			multiplier = 24 * time.Hour
+		case "w":
+			multiplier = 7 * 24 * time.Hour
+		default:
+			d, err := time.ParseDuration(number + unit)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			total += d
+			continue
+		}
+
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total += time.Duration(value * float64(multiplier))
+	}
+
+	return total, nil
+}
+
+// FormatDurationShort formats a duration using its largest whole unit,
+// e.g. "45s", "5m", "3h", "2d", "3w"
+func FormatDurationShort(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	default:
+		return fmt.Sprintf("%dw", int(d.Hours()/(24*7)))
+	}
+}
+
+// TimestampLayout is used for absolute times shown to users
+const TimestampLayout = "2006-01-02 15:04 MST"
+
+// FormatRelative describes t relative to now, e.g. "3h ago" or "in 5m".
+// Differences under a minute are "just now".
+func FormatRelative(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d > -time.Minute && d < time.Minute:
+		return "just now"
+	case d < 0:
+		return "in " + FormatDurationShort(-d)
+	default:
+		return FormatDurationShort(d) + " ago"
+	}
+}
+
+// FormatTimestamp formats t in the local timezone using TimestampLayout
+func FormatTimestamp(t time.Time) string {
+	return t.Local().Format(TimestampLayout)
+}
+
+// FormatTime formats t as a relative duration, or as a local timestamp when
+// absolute is set
+func FormatTime(t time.Time, absolute bool) string {
+	if absolute {
+		return FormatTimestamp(t)
+	}
+	return FormatRelative(t, time.Now())
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"2h", 2 * time.Hour, false},
+		{"1d", 24 * time.Hour, false},
+		{"2d", 48 * time.Hour, false},
+		{"1w", 7 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"5x", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseDuration(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDurationShort(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{10 * time.Second, "10s"},
+		{5 * time.Minute, "5m"},
+		{90 * time.Minute, "1h"},
+		{50 * time.Hour, "2d"},
+		{15 * 24 * time.Hour, "2w"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatDurationShort(tt.input); result != tt.expected {
+			t.Errorf("FormatDurationShort(%v) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input    time.Time
+		expected string
+	}{
+		{now.Add(-20 * time.Second), "just now"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.Add(5 * time.Minute), "in 5m"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatRelative(tt.input, now); result != tt.expected {
+			t.Errorf("FormatRelative(%v) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatTimestampUsesLocalTime(t *testing.T) {
+	original := time.Local
+	time.Local = time.FixedZone("TST", 2*60*60)
+	defer func() { time.Local = original }()
+
+	utc := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if result := FormatTimestamp(utc); result != "2024-05-01 14:00 TST" {
+		t.Errorf("FormatTimestamp() = %q, expected %q", result, "2024-05-01 14:00 TST")
+	}
+}
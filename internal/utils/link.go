@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// The ways Link can link a path
+const (
+	LinkSymlink  = "symlink"
+	LinkJunction = "junction"
+	LinkCopy     = "copy"
+)
+
+var (
+	symlinkOnce sync.Once
+	symlinkOK   bool
+)
+
+// CanSymlink reports whether ccswitch can create symbolic links. On
+// Windows this needs Developer Mode or administrator rights, so it is
+// tried once, the first time it is asked.
+func CanSymlink() bool {
+	symlinkOnce.Do(func() {
+		symlinkOK = probeSymlink()
+	})
+	return symlinkOK
+}
+
+// Link makes link stand for target, an absolute path, and returns how:
+// as a symlink where possible, otherwise as a junction if target is a
+// directory on Windows, or else as a copy, which does not follow later
+// changes to target
+func Link(target, link string) (string, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return "", err
+	}
+
+	if CanSymlink() {
+		if err := os.Symlink(target, link); err != nil {
+			return "", err
+		}
+		return LinkSymlink, nil
+	}
+	// Junctions only reach directories on local volumes; copy the rest
+	if info.IsDir() && createJunction(target, link) == nil {
+		return LinkJunction, nil
+	}
+	if err := copyTree(target, link); err != nil {
+		_ = os.RemoveAll(link)
+		return "", fmt.Errorf("failed to copy %s: %w", target, err)
+	}
+	return LinkCopy, nil
+}
+
+// copyTree copies the file or directory at src to dst. Links inside src
+// are left out, since they could not be recreated.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
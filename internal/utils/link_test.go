@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(dir, "worktree", "nested", "shared")
+	kind, err := Link(target, link)
+	if err != nil {
+		t.Fatalf("Link() failed: %v", err)
+	}
+	if CanSymlink() && kind != LinkSymlink {
+		t.Errorf("Link() = %s, expected %s when symlinks are available", kind, LinkSymlink)
+	}
+	if err := os.WriteFile(filepath.Join(target, "file"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if kind != LinkCopy {
+		if _, err := os.Stat(filepath.Join(link, "file")); err != nil {
+			t.Errorf("file added to the target is not seen through the %s: %v", kind, err)
+		}
+	}
+
+	if _, err := Link(filepath.Join(dir, "missing"), filepath.Join(dir, "other")); err == nil {
+		t.Error("Link() to a missing target succeeded")
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file"), []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "sub", "file"))
+	if err != nil || string(data) != "content" {
+		t.Errorf("copied file = %q, %v, expected %q", data, err, "content")
+	}
+
+	single := filepath.Join(t.TempDir(), "single")
+	if err := copyTree(filepath.Join(src, "sub", "file"), single); err != nil {
+		t.Fatalf("copyTree() of a file failed: %v", err)
+	}
+	if data, _ := os.ReadFile(single); string(data) != "content" {
+		t.Errorf("copied file = %q, expected %q", data, "content")
+	}
+}
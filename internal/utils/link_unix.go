@@ -0,0 +1,15 @@
+//go:build !windows
+
+package utils
+
+import "errors"
+
+// probeSymlink reports that symlinks work, as they always do outside
+// Windows
+func probeSymlink() bool {
+	return true
+}
+
+func createJunction(target, link string) error {
+	return errors.New("junctions are only available on Windows")
+}
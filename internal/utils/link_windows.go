@@ -0,0 +1,31 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// probeSymlink creates a symlink in a temporary directory, which fails
+// without Developer Mode or administrator rights
+func probeSymlink() bool {
+	dir, err := os.MkdirTemp("", "ccswitch-symlink-*")
+	if err != nil {
+		return false
+	}
+	defer os.RemoveAll(dir)
+	return os.Symlink(dir, filepath.Join(dir, "link")) == nil
+}
+
+// createJunction links the directory target as link with a junction,
+// which unlike a symlink needs no special rights
+func createJunction(target, link string) error {
+	output, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create junction: %w, output: %s", err, string(output))
+	}
+	return nil
+}
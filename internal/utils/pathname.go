@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPathNameLength is the longest path component PathName returns, in
+// bytes, well under the 255 most filesystems allow
+const MaxPathNameLength = 96
+
+// pathNameHashLength is the length of the hash ending names PathName shortens
+const pathNameHashLength = 8
+
+// PathName turns a session name into a single path component that is safe
+// on every platform, e.g. "feature/foo" into "feature%2Ffoo". Letters,
+// digits, "-" and "_" are kept, including non-ASCII letters; other
+// characters, as well as "." at the start, are escaped as %XX per byte, so
+// NameFromPath returns the name. Names longer than MaxPathNameLength are cut
+// short and end with "~" and a hash of the whole name instead, which
+// NameFromPath can't reverse; IsShortenedPathName reports those.
+func PathName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r == '-' || r == '_' || (r == '.' && i > 0) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		for _, c := range []byte(string(r)) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	escaped := b.String()
+	if len(escaped) <= MaxPathNameLength {
+		return escaped
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := "~" + hex.EncodeToString(sum[:])[:pathNameHashLength]
+	cut := MaxPathNameLength - len(suffix)
+	// Don't split an escape or a multi-byte character
+	for cut > 0 && (escaped[cut-1] == '%' || (cut > 1 && escaped[cut-2] == '%') || !utf8.RuneStart(escaped[cut])) {
+		cut--
+	}
+	return escaped[:cut] + suffix
+}
+
+// IsShortenedPathName reports whether PathName shortened a name to
+// component, so that NameFromPath can't recover it
+func IsShortenedPathName(component string) bool {
+	i := strings.LastIndexByte(component, '~')
+	return i >= 0 && len(component)-i-1 == pathNameHashLength
+}
+
+// NameFromPath returns the session name PathName turned into component.
+// Components that aren't escaped, such as those of worktrees made before
+// escaping, are returned as they are.
+func NameFromPath(component string) string {
+	if !strings.Contains(component, "%") {
+		return component
+	}
+	var b strings.Builder
+	for i := 0; i < len(component); i++ {
+		if component[i] == '%' && i+2 < len(component) {
+			if c, err := hex.DecodeString(component[i+1 : i+3]); err == nil {
+				b.WriteByte(c[0])
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(component[i])
+	}
+	return b.String()
+}
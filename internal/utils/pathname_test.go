@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"fix-login", "fix-login"},
+		{"feature/foo-bar", "feature%2Ffoo-bar"},
+		{"users/jo/wip", "users%2Fjo%2Fwip"},
+		{"café-résumé", "café-résumé"},
+		{"修复-登录", "修复-登录"},
+		{"v1.2_final", "v1.2_final"},
+		{".hidden", "%2Ehidden"},
+		{"..", "%2E."},
+		{"50%", "50%25"},
+		{"a b:c\\d", "a%20b%3Ac%5Cd"},
+		{"emoji🚀", "emoji%F0%9F%9A%80"},
+	}
+
+	for _, tt := range tests {
+		got := PathName(tt.name)
+		if got != tt.expected {
+			t.Errorf("PathName(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+		if back := NameFromPath(got); back != tt.name {
+			t.Errorf("NameFromPath(%q) = %q, expected %q", got, back, tt.name)
+		}
+		if IsShortenedPathName(got) {
+			t.Errorf("IsShortenedPathName(%q) = true, expected false", got)
+		}
+	}
+}
+
+func TestPathNameLong(t *testing.T) {
+	for _, name := range []string{
+		strings.Repeat("a", 200),
+		strings.Repeat("feature/", 30),
+		strings.Repeat("é", 100),
+	} {
+		got := PathName(name)
+		if len(got) > MaxPathNameLength {
+			t.Errorf("PathName(%q) is %d bytes, expected at most %d", name, len(got), MaxPathNameLength)
+		}
+		if !IsShortenedPathName(got) {
+			t.Errorf("IsShortenedPathName(%q) = false, expected true", got)
+		}
+		if !strings.HasPrefix(name, NameFromPath(got[:strings.LastIndexByte(got, '~')])) {
+			t.Errorf("PathName(%q) = %q, expected it to start with the name", name, got)
+		}
+	}
+
+	// Names differing only past the cut still get different paths
+	a, b := strings.Repeat("x", 150)+"a", strings.Repeat("x", 150)+"b"
+	if PathName(a) == PathName(b) {
+		t.Errorf("PathName(%q) == PathName(%q), expected them to differ", a, b)
+	}
+}
+
+func TestNameFromPathUnescaped(t *testing.T) {
+	// Worktrees made before escaping keep their names
+	for _, component := range []string{"fix-login", "100%", "%zz"} {
+		if got := NameFromPath(component); got != component {
+			t.Errorf("NameFromPath(%q) = %q, expected it unchanged", component, got)
+		}
+	}
+}
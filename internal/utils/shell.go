@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"runtime"
 )
 
 // IsShellIntegrationActive checks if we're running inside the shell wrapper
@@ -13,6 +14,13 @@ func IsShellIntegrationActive() bool {
 // GetShellIntegrationInstructions returns instructions for setting up shell integration
 func GetShellIntegrationInstructions() string {
 	shell := os.Getenv("SHELL")
+	if shell == "" && runtime.GOOS == "windows" {
+		return `To enable automatic directory switching, add this to your PowerShell profile:
+  Add-Content $PROFILE 'Invoke-Expression (& ccswitch shell-init powershell | Out-String)'
+
+Then reload your profile:
+  . $PROFILE`
+	}
 	if shell == "/bin/zsh" || shell == "/usr/bin/zsh" {
 		return `To enable automatic directory switching, add this to your ~/.zshrc:
   eval "$(ccswitch shell-init)"
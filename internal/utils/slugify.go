@@ -5,10 +5,11 @@ import (
 	"strings"
 )
 
-// Slugify converts a string to a URL-friendly slug
+// Slugify converts a string to a URL-friendly slug. Letters and digits of
+// any script are kept, lowercased.
 func Slugify(s string) string {
 	s = strings.ToLower(s)
-	s = regexp.MustCompile(`[^a-z0-9-]+`).ReplaceAllString(s, "-")
+	s = regexp.MustCompile(`[^\p{L}\p{N}-]+`).ReplaceAllString(s, "-")
 	s = regexp.MustCompile(`-+`).ReplaceAllString(s, "-")
 	s = strings.Trim(s, "-")
 	return s
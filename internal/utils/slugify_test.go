@@ -15,7 +15,9 @@ func TestSlugify(t *testing.T) {
 		{"special chars removal", "test@#$%^&*()", "test"},
 		{"multiple spaces", "too   many    spaces", "too-many-spaces"},
 		{"trim dashes", "--trimmed--", "trimmed"},
-		{"unicode handling", "café résumé", "caf-r-sum"},
+		{"unicode handling", "café résumé", "café-résumé"},
+		{"non-latin scripts", "修复 登录", "修复-登录"},
+		{"branch with slashes", "feature/foo-bar", "feature-foo-bar"},
 		{"numbers preserved", "test123", "test123"},
 		{"dash preserved", "already-dashed", "already-dashed"},
 		{"underscore to dash", "test_underscore", "test-underscore"},
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LastLines returns the last n lines of output, e.g. to report the end of
+// a failed command's output
+func LastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FirstLines returns the first n lines of output, ending with a note of how
+// many were left out if there are more
+func FirstLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n... %d more lines", len(lines)-n)
+}
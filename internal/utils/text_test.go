@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		output   string
+		n        int
+		expected string
+	}{
+		{"a\nb\nc\n", 2, "b\nc"},
+		{"a\nb\n", 5, "a\nb"},
+		{"", 3, ""},
+	}
+
+	for _, tt := range tests {
+		if got := LastLines(tt.output, tt.n); got != tt.expected {
+			t.Errorf("LastLines(%q, %d) = %q, expected %q", tt.output, tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestFirstLines(t *testing.T) {
+	tests := []struct {
+		output   string
+		n        int
+		expected string
+	}{
+		{"a\nb\nc\n", 2, "a\nb\n... 1 more lines"},
+		{"a\nb\n", 5, "a\nb"},
+		{"", 3, ""},
+	}
+
+	for _, tt := range tests {
+		if got := FirstLines(tt.output, tt.n); got != tt.expected {
+			t.Errorf("FirstLines(%q, %d) = %q, expected %q", tt.output, tt.n, got, tt.expected)
+		}
+	}
+}
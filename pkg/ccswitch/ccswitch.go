@@ -0,0 +1,301 @@
+// Package ccswitch is the Go API of ccswitch, for programs that manage
+// sessions without running the ccswitch binary.
+//
+// It never prints or prompts: operations return their results, and their
+// errors can be tested with errors.Is against the Err values of this
+// package. Like ccswitch commands, operations that change sessions take the
+// repository lock and fail with ErrLocked while another command holds it,
+// follow the configuration of the repository (.ccswitch/config.yaml and
+// ~/.ccswitch/config.yaml) and are recorded in its history.
+//
+// Status and StatusSession are the documents of ccswitch status --json and
+// change only as its schema version allows.
+package ccswitch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ksred/ccswitch/internal/errors"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+	"github.com/ksred/ccswitch/internal/session"
+)
+
+type (
+	// Session is a session: a worktree of the repository with its branch,
+	// which is empty for detached sessions
+	Session = git.SessionInfo
+	// Worktree is a git worktree of the repository
+	Worktree = git.Worktree
+	// WorktreeStatus is the state of a worktree relative to a base branch
+	WorktreeStatus = git.WorktreeStatus
+	// Status is the state of every session relative to a base branch
+	Status = schema.Status
+	// StatusSession is the state of one session in a Status
+	StatusSession = schema.StatusSession
+)
+
+// Errors operations fail with, wrapped with details
+var (
+	ErrSessionNotFound    = errors.ErrSessionNotFound
+	ErrUncommittedChanges = errors.ErrUncommittedChanges
+	ErrBranchExists       = errors.ErrBranchExists
+	ErrBranchNotFound     = errors.ErrBranchNotFound
+	ErrWorktreeExists     = errors.ErrWorktreeExists
+	ErrAlreadyOnBranch    = errors.ErrAlreadyOnBranch
+	ErrBranchPolicy       = errors.ErrBranchPolicy
+	ErrLocked             = errors.ErrLocked
+	ErrRebaseConflict     = errors.ErrRebaseConflict
+	ErrVerifyFailed       = errors.ErrVerifyFailed
+	ErrHookFailed         = errors.ErrHookFailed
+	ErrPinned             = errors.ErrPinned
+	ErrProtectedBranch    = errors.ErrProtectedBranch
+	ErrMainRepository     = errors.ErrMainRepository
+)
+
+// SessionManager manages the sessions of a repository. It is safe for
+// concurrent use; operations that change sessions exclude each other
+// through the repository lock.
+type SessionManager struct {
+	root string
+}
+
+// Open returns a manager for the sessions of the repository containing dir,
+// which may be the main repository or any of its worktrees
+func Open(dir string) (*SessionManager, error) {
+	root, err := git.GetMainRepoPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository: %w", dir, err)
+	}
+	return &SessionManager{root: root}, nil
+}
+
+// Root returns the path of the main repository
+func (sm *SessionManager) Root() string {
+	return sm.root
+}
+
+// manager returns a session manager for one operation, since options such
+// as the base of new sessions are set on it
+func (sm *SessionManager) manager() *session.Manager {
+	return session.NewManager(sm.root)
+}
+
+// lock takes the repository lock for operation
+func (sm *SessionManager) lock(m *session.Manager, operation string) (*session.Lock, error) {
+	return m.Lock(filepath.Base(os.Args[0]) + " " + operation)
+}
+
+// CurrentBranch returns the branch checked out in the main repository,
+// which sessions are created from and rebased onto by default
+func (sm *SessionManager) CurrentBranch() (string, error) {
+	return sm.manager().GetCurrentBranch()
+}
+
+// Sessions returns every session, the main repository included
+func (sm *SessionManager) Sessions() ([]Session, error) {
+	return sm.manager().ListSessions()
+}
+
+// Find returns the session with the given name or branch
+func (sm *SessionManager) Find(name string) (Session, error) {
+	return find(sm.manager(), name)
+}
+
+func find(m *session.Manager, name string) (Session, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Name == name || s.Branch == name {
+			return s, nil
+		}
+	}
+	return Session{}, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+}
+
+// Status returns the state of every session relative to base, or to the
+// current branch if base is empty, as ccswitch status --json does
+func (sm *SessionManager) Status(base string) (*Status, error) {
+	m := sm.manager()
+	if base == "" {
+		var err error
+		if base, err = m.GetCurrentBranch(); err != nil {
+			return nil, fmt.Errorf("failed to get current branch: %w", err)
+		}
+	}
+	return m.Status(base)
+}
+
+// WorktreeStatus returns whether s has uncommitted changes and how far its
+// branch is ahead of and behind base, or the current branch if base is
+// empty
+func (sm *SessionManager) WorktreeStatus(s Session, base string) (WorktreeStatus, error) {
+	if base == "" {
+		var err error
+		if base, err = sm.CurrentBranch(); err != nil {
+			return WorktreeStatus{}, fmt.Errorf("failed to get current branch: %w", err)
+		}
+	}
+	return git.GetWorktreeStatus(s.Path, base)
+}
+
+// CreateOptions are the choices ccswitch create offers as flags
+type CreateOptions struct {
+	// Base is the branch or commit to start from instead of the current
+	// branch
+	Base string
+	// Type is the branch type, e.g. "fix", picking the branch prefix from
+	// branch.types
+	Type string
+	Tags []string
+}
+
+// Create creates a session for description, naming its branch after the
+// configured template. Call Setup to prepare its worktree as ccswitch
+// create does.
+func (sm *SessionManager) Create(description string, opts CreateOptions) (Session, error) {
+	m := sm.manager()
+	m.SetBranchType(opts.Type)
+	if err := m.SetBase(opts.Base); err != nil {
+		return Session{}, err
+	}
+	if err := m.SetTags(opts.Tags); err != nil {
+		return Session{}, err
+	}
+
+	lock, err := sm.lock(m, "create")
+	if err != nil {
+		return Session{}, err
+	}
+	defer lock.Release()
+
+	entry, err := m.NewSession(description)
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{Name: entry.Name, Branch: entry.Branch, Path: entry.Path}, nil
+}
+
+// Setup prepares the worktree of a new session as ccswitch create does: it
+// links the paths of worktree.links, pulls Git LFS objects and initializes
+// submodules. It returns what failed; the session is usable regardless.
+func (sm *SessionManager) Setup(s Session) []error {
+	var problems []error
+	for _, link := range sm.manager().LinkShared(s.Path) {
+		if link.Err != nil {
+			problems = append(problems, fmt.Errorf("failed to link %s: %w", link.Path, link.Err))
+		}
+	}
+	if git.UsesLFS(s.Path) && git.LFSInstalled(s.Path) {
+		if err := git.PullLFS(s.Path, io.Discard); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if git.HasSubmodules(s.Path) {
+		if err := git.UpdateSubmodules(s.Path, io.Discard); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// RemoveOptions are the choices ccswitch cleanup offers as flags
+type RemoveOptions struct {
+	// Force discards uncommitted changes instead of refusing the removal
+	Force bool
+	// DeleteBranch deletes the session's branch too, unless it is protected
+	DeleteBranch bool
+}
+
+// Remove removes the session with the given name or branch. Pinned
+// sessions and the main repository are never removed.
+func (sm *SessionManager) Remove(name string, opts RemoveOptions) (Session, error) {
+	m := sm.manager()
+	lock, err := sm.lock(m, "remove")
+	if err != nil {
+		return Session{}, err
+	}
+	defer lock.Release()
+
+	s, err := find(m, name)
+	if err != nil {
+		return Session{}, err
+	}
+	if s.Path == sm.root {
+		return Session{}, fmt.Errorf("%w: cannot remove it as a session", ErrMainRepository)
+	}
+	if m.Pinned()[s.Path] {
+		return Session{}, fmt.Errorf("%w: %s", ErrPinned, s.Name)
+	}
+	if !opts.Force && git.HasUncommittedChanges(s.Path) {
+		return Session{}, fmt.Errorf("%w in %s", ErrUncommittedChanges, s.Name)
+	}
+
+	deleteBranch := opts.DeleteBranch && !m.Config().IsProtectedBranch(s.Branch)
+	return s, m.RemoveSession(s.Path, deleteBranch, s.Branch)
+}
+
+// Rebase rebases the session with the given name or branch onto the
+// current branch, as ccswitch rebase does. Uncommitted changes are
+// committed with message first; without a message they are refused.
+func (sm *SessionManager) Rebase(name, message string) (Session, error) {
+	m := sm.manager()
+	lock, err := sm.lock(m, "rebase")
+	if err != nil {
+		return Session{}, err
+	}
+	defer lock.Release()
+
+	s, err := find(m, name)
+	if err != nil {
+		return Session{}, err
+	}
+	current, err := m.GetCurrentBranch()
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if s.Branch == current {
+		return Session{}, fmt.Errorf("%w: cannot rebase %s onto itself", ErrAlreadyOnBranch, current)
+	}
+	if m.Config().IsProtectedBranch(current) {
+		return Session{}, fmt.Errorf("%w: %s", ErrProtectedBranch, current)
+	}
+
+	dirty := git.HasUncommittedChanges(s.Path)
+	if dirty && message == "" {
+		return Session{}, fmt.Errorf("%w in %s; a commit message is needed to commit them", ErrUncommittedChanges, s.Name)
+	}
+	if dirty {
+		err = m.CommitAndRebaseSession(s.Path, message)
+	} else {
+		err = m.RebaseSession(s.Path)
+	}
+	m.Record(schema.OpRebase, "", []string{current}, err)
+	return s, err
+}
+
+// Command returns a command running name with args in the worktree of the
+// session with the given name or branch, as ccswitch exec does: it sees
+// CCSWITCH_SESSION, CCSWITCH_BRANCH, CCSWITCH_WORKTREE, CCSWITCH_BASE_BRANCH
+// and the session's ports and variables. The command is killed if ctx is
+// done before it exits.
+func (sm *SessionManager) Command(ctx context.Context, session, name string, args ...string) (*exec.Cmd, error) {
+	m := sm.manager()
+	s, err := find(m, session)
+	if err != nil {
+		return nil, err
+	}
+	_ = m.MarkUsed(s)
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = s.Path
+	cmd.Env = m.Env(os.Environ(), s)
+	return cmd, nil
+}
@@ -0,0 +1,158 @@
+package ccswitch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupRepo returns a manager for a new repository on main with one commit
+func setupRepo(t *testing.T) *SessionManager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	writeFile(t, filepath.Join(repo, "README.md"), "readme\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "Initial commit")
+
+	sm, err := Open(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sm
+}
+
+func TestSessionManager(t *testing.T) {
+	sm := setupRepo(t)
+
+	created, err := sm.Create("add login", CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if created.Name != "add-login" || created.Path == "" {
+		t.Fatalf("Create() = %+v, expected session add-login", created)
+	}
+	if problems := sm.Setup(created); len(problems) != 0 {
+		t.Errorf("Setup() = %v, expected no problems", problems)
+	}
+	if _, err := sm.Create("add login", CreateOptions{}); !errors.Is(err, ErrBranchExists) {
+		t.Errorf("Create() of an existing session = %v, expected ErrBranchExists", err)
+	}
+
+	found, err := sm.Find(created.Branch)
+	if err != nil || found.Path != created.Path {
+		t.Errorf("Find(%q) = %+v, %v, expected %s", created.Branch, found, err, created.Path)
+	}
+	if _, err := sm.Find("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Find(missing) = %v, expected ErrSessionNotFound", err)
+	}
+
+	cmd, err := sm.Command(context.Background(), created.Name, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(out)) != created.Branch {
+		t.Errorf("Command() ran on %q (%v), expected branch %s", out, err, created.Branch)
+	}
+
+	// Work is refused without a message to commit it with
+	writeFile(t, filepath.Join(created.Path, "login.go"), "package login\n")
+	if _, err := sm.Rebase(created.Name, ""); !errors.Is(err, ErrUncommittedChanges) {
+		t.Errorf("Rebase() with uncommitted changes = %v, expected ErrUncommittedChanges", err)
+	}
+	if _, err := sm.Rebase(created.Name, "Add login"); err != nil {
+		t.Fatalf("Rebase() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sm.Root(), "login.go")); err != nil {
+		t.Errorf("rebased work missing from main: %v", err)
+	}
+	if _, err := sm.Rebase("main", ""); !errors.Is(err, ErrAlreadyOnBranch) {
+		t.Errorf("Rebase(main) = %v, expected ErrAlreadyOnBranch", err)
+	}
+
+	status, err := sm.Status("")
+	if err != nil || status.Base != "main" || len(status.Sessions) != 2 {
+		t.Errorf("Status() = %+v, %v, expected the main repository and the session compared to main", status, err)
+	}
+	ws, err := sm.WorktreeStatus(created, "")
+	if err != nil || ws.Dirty || ws.Ahead != 0 || ws.Behind != 0 {
+		t.Errorf("WorktreeStatus() = %+v, %v, expected a clean worktree level with main", ws, err)
+	}
+
+	if _, err := sm.Remove("main", RemoveOptions{}); !errors.Is(err, ErrMainRepository) {
+		t.Errorf("Remove(main) = %v, expected ErrMainRepository", err)
+	}
+	writeFile(t, filepath.Join(created.Path, "scratch.txt"), "scratch\n")
+	if _, err := sm.Remove(created.Name, RemoveOptions{}); !errors.Is(err, ErrUncommittedChanges) {
+		t.Errorf("Remove() with uncommitted changes = %v, expected ErrUncommittedChanges", err)
+	}
+	if _, err := sm.Remove(created.Name, RemoveOptions{Force: true, DeleteBranch: true}); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if sessions, _ := sm.Sessions(); len(sessions) != 1 {
+		t.Errorf("Sessions() after Remove() = %+v, expected only the main repository", sessions)
+	}
+}
+
+func TestFanout(t *testing.T) {
+	sm := setupRepo(t)
+
+	created, err := sm.Create("add login", CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(sm.Root(), "main.txt"), "main\n")
+	runGit(t, sm.Root(), "add", ".")
+	runGit(t, sm.Root(), "commit", "-m", "Advance main")
+
+	f, err := sm.Fanout(FanoutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets, err := f.Targets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Branch != created.Branch {
+		t.Fatalf("Targets() = %+v, expected %s", targets, created.Branch)
+	}
+	if checks := f.Check(targets); !checks[0].Safe() {
+		t.Errorf("Check() = %+v, expected the clean session to be safe", checks)
+	}
+
+	results, err := f.Run(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != FanoutSucceeded {
+		t.Fatalf("Run() = %+v, expected the session rebased", results)
+	}
+	if _, err := os.Stat(filepath.Join(created.Path, "main.txt")); err != nil {
+		t.Errorf("session not rebased onto main: %v", err)
+	}
+}
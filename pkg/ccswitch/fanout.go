@@ -0,0 +1,143 @@
+package ccswitch
+
+import (
+	"fmt"
+
+	"github.com/ksred/ccswitch/internal/fanout"
+	"github.com/ksred/ccswitch/internal/git"
+	"github.com/ksred/ccswitch/internal/schema"
+)
+
+type (
+	// FanoutCheck is the state of a fanout target before it is rebased;
+	// Safe reports whether it can be rebased without losing work
+	FanoutCheck = fanout.Check
+	// FanoutResult is the outcome of rebasing one fanout target
+	FanoutResult = fanout.Result
+	// FanoutStatus is the outcome kind of a fanout target
+	FanoutStatus = fanout.Status
+	// FanoutObserver receives progress while a fanout runs
+	FanoutObserver = fanout.Observer
+)
+
+// Outcomes of fanout targets
+const (
+	FanoutPending      = fanout.StatusPending
+	FanoutSucceeded    = fanout.StatusSucceeded
+	FanoutConflicted   = fanout.StatusConflicted
+	FanoutFailed       = fanout.StatusFailed
+	FanoutSkipped      = fanout.StatusSkipped
+	FanoutInterrupted  = fanout.StatusInterrupted
+	FanoutVerifyFailed = fanout.StatusVerifyFailed
+)
+
+// FanoutOptions are the choices ccswitch fanout offers as flags
+type FanoutOptions struct {
+	// Source is the branch to rebase onto; empty is the current branch
+	Source string
+	// Stack rebases branches stacked on other targets onto their parent
+	// instead of onto the source
+	Stack bool
+	// Observer is told about each target as it is rebased; nil is silent
+	Observer FanoutObserver
+}
+
+// Fanout rebases the other worktrees of the repository onto a source branch
+// as ccswitch fanout does: get the Targets, Check them, and Run the ones
+// that are safe or that you accept the risk for. A conflict aborts the
+// target's rebase and stops the run. Commits are signed and verified as
+// git.sign_commits and git.verify_command say.
+type Fanout struct {
+	sm      *SessionManager
+	engine  *fanout.Engine
+	stack   bool
+	parents map[string]string
+}
+
+// Fanout prepares a fanout
+func (sm *SessionManager) Fanout(opts FanoutOptions) (*Fanout, error) {
+	m := sm.manager()
+	source := opts.Source
+	if source == "" {
+		var err error
+		if source, err = m.GetCurrentBranch(); err != nil {
+			return nil, fmt.Errorf("failed to get current branch: %w", err)
+		}
+	}
+
+	engine := fanout.New(source, opts.Observer)
+	engine.Sign(m.Config().Git.SignCommits)
+	engine.Verify(m.Config().Git.VerifyCommand)
+	return &Fanout{sm: sm, engine: engine, stack: opts.Stack}, nil
+}
+
+// Source returns the branch targets are rebased onto
+func (f *Fanout) Source() string {
+	return f.engine.Source()
+}
+
+// Targets returns the worktrees with a branch other than the source,
+// parents before the branches stacked on them
+func (f *Fanout) Targets() ([]Worktree, error) {
+	worktrees, err := git.NewWorktreeManager(f.sm.root).List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	targets := f.engine.Targets(worktrees, f.sm.root)
+
+	parents, err := f.engine.Parents(f.sm.root, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect stacked branches: %w", err)
+	}
+	if f.stack && f.parents == nil {
+		f.engine.Stack(parents)
+	}
+	f.parents = parents
+	return fanout.Order(targets, parents), nil
+}
+
+// Check returns the state of each target
+func (f *Fanout) Check(targets []Worktree) []FanoutCheck {
+	return f.engine.Check(targets)
+}
+
+// Run rebases targets in order, stopping at the first that fails. Targets
+// not reached are returned as FanoutPending. Protected branches are
+// refused before anything is rebased.
+func (f *Fanout) Run(targets []Worktree) ([]FanoutResult, error) {
+	m := f.sm.manager()
+	lock, err := f.sm.lock(m, "fanout")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	branches := make([]string, len(targets))
+	for i, wt := range targets {
+		if m.Config().IsProtectedBranch(wt.Branch) {
+			return nil, fmt.Errorf("%w: %s", ErrProtectedBranch, wt.Branch)
+		}
+		branches[i] = wt.Branch
+	}
+
+	results := f.engine.Run(targets)
+	for _, r := range results {
+		if r.Status != fanout.StatusPending {
+			m.Record(schema.OpRebase, "", []string{r.Worktree.Branch}, r.Err)
+		}
+	}
+	result := schema.ResultSucceeded
+	if f.engine.Interrupted() {
+		result = schema.ResultInterrupted
+	} else if fanout.Count(results, fanout.StatusSucceeded) < len(results) {
+		result = schema.ResultFailed
+	}
+	m.RecordOperation(schema.Operation{Op: schema.OpFanout, Branches: branches, Result: result})
+	return results, nil
+}
+
+// Interrupt stops a run before its next target; a rebase in progress is
+// aborted. It is safe to call from another goroutine while Run runs.
+func (f *Fanout) Interrupt() {
+	f.engine.Interrupt()
+}